@@ -8,6 +8,8 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"sort"
+	"time"
 
 	pb "github.com/google/go-tpm-tools/proto/tpm"
 	"github.com/google/go-tpm/tpm2"
@@ -20,14 +22,59 @@ const (
 	pcrTypeValue     uint8 = 1
 	_                uint8 = 2 // nvindex field is not supported yet
 	digestsTypeValue uint8 = 3
+	// The timestamp field is experimental: its type is not reserved in the CEL
+	// spec, so records are only timestamped if CEL.TimestampSource is set.
+	// TODO: the value needs to be reserved in the CEL spec
+	timestampTypeValue uint8 = 81
 
 	tlvTypeFieldLength   int = 1
 	tlvLengthFieldLength int = 4
 
 	recnumValueLength uint32 = 8 // support up to 2^64 records
 	pcrValueLength    uint32 = 1 // support up to 256 PCRs
+	// the source (1 byte) followed by the timestamp (8 bytes)
+	timestampValueLength uint32 = 9
 )
 
+// TimestampSource is the clock the timestamps of the records of a CEL are read
+// from.
+type TimestampSource uint8
+
+// TimestampSource values.
+const (
+	// NoTimestamps is the default, the records are not timestamped.
+	NoTimestamps TimestampSource = iota
+	// TPMClockTimestamps are the Clock of the TPM, in milliseconds. It is the
+	// time during which the TPM has been powered, the same as the clock of a
+	// quote, so the records of a boot are never after the quote attesting
+	// them.
+	TPMClockTimestamps
+	// MonotonicTimestamps are the nanoseconds since the first timestamped
+	// record of the CEL, read from the monotonic clock of the host. They can be
+	// used with TPMs whose clock is too coarse to order the records.
+	MonotonicTimestamps
+)
+
+func (s TimestampSource) String() string {
+	switch s {
+	case NoTimestamps:
+		return "none"
+	case TPMClockTimestamps:
+		return "tpm-clock"
+	case MonotonicTimestamps:
+		return "monotonic"
+	}
+	return fmt.Sprintf("TimestampSource(%d)", uint8(s))
+}
+
+// Timestamp is the time a record was appended to a CEL. It is measured along
+// with the content of the record, so it cannot be modified without failing
+// the replay of the CEL.
+type Timestamp struct {
+	Source TimestampSource
+	Value  uint64
+}
+
 // TLV definition according to CEL spec TCG_IWG_CEL_v1_r0p37, page 16.
 // Length is implicitly defined by len(Value), using uint32 big-endian
 // when encoding.
@@ -104,6 +151,8 @@ type Record struct {
 	PCR     uint8
 	Digests map[crypto.Hash][]byte
 	Content TLV
+	// Timestamp is the zero Timestamp if the record is not timestamped.
+	Timestamp Timestamp
 }
 
 // Content is a interface for the content in CELR.
@@ -115,17 +164,80 @@ type Content interface {
 // CEL represents a Canonical Eventlog, which contains a list of Records.
 type CEL struct {
 	Records []Record
+	// TimestampSource is the clock the timestamps of the appended records are
+	// read from, by default they are not timestamped. Timestamps are
+	// experimental: they are encoded in a field whose type is not reserved in
+	// the CEL spec, and verifiers reject them unless they opt in with
+	// server.VerifyOpts.AllowCELTimestamps.
+	TimestampSource TimestampSource
+
+	// monotonicStart is the time of the first record with a
+	// MonotonicTimestamps timestamp.
+	monotonicStart time.Time
+}
+
+// timestamp reads the timestamp of a new record from the TimestampSource.
+func (c *CEL) timestamp(tpm io.ReadWriter) (Timestamp, error) {
+	switch c.TimestampSource {
+	case NoTimestamps:
+		return Timestamp{}, nil
+	case TPMClockTimestamps:
+		_, clock, err := tpm2.ReadClock(tpm)
+		if err != nil {
+			return Timestamp{}, fmt.Errorf("failed to read the TPM clock: %v", err)
+		}
+		return Timestamp{TPMClockTimestamps, clock}, nil
+	case MonotonicTimestamps:
+		if c.monotonicStart.IsZero() {
+			c.monotonicStart = time.Now()
+		}
+		return Timestamp{MonotonicTimestamps, uint64(time.Since(c.monotonicStart))}, nil
+	}
+	return Timestamp{}, fmt.Errorf("unknown timestamp source %v", c.TimestampSource)
 }
 
-// AppendEvent appends a new record to the CEL.
+// timestampedContent is the content of a timestamped record, whose digests
+// cover both the content and the timestamp.
+type timestampedContent struct {
+	Content
+	timestamp Timestamp
+}
+
+// GenerateDigest hashes the digest of the content followed by the encoded
+// timestamp field.
+func (c timestampedContent) GenerateDigest(hashAlgo crypto.Hash) ([]byte, error) {
+	digest, err := c.Content.GenerateDigest(hashAlgo)
+	if err != nil {
+		return nil, err
+	}
+	timestampField, err := createTimestampField(c.timestamp).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	hash := hashAlgo.New()
+	hash.Write(digest)
+	hash.Write(timestampField)
+	return hash.Sum(nil), nil
+}
+
+// AppendEvent appends a new record to the CEL, timestamped from the
+// TimestampSource of the CEL.
 func (c *CEL) AppendEvent(tpm io.ReadWriteCloser, pcr int, hashAlgos []crypto.Hash, event Content) error {
 	if len(hashAlgos) == 0 {
 		return fmt.Errorf("need to specify at least one hash algorithm")
 	}
+	timestamp, err := c.timestamp(tpm)
+	if err != nil {
+		return err
+	}
+	measured := event
+	if timestamp.Source != NoTimestamps {
+		measured = timestampedContent{event, timestamp}
+	}
 	digestsMap := make(map[crypto.Hash][]byte)
 
 	for _, hashAlgo := range hashAlgos {
-		digest, err := event.GenerateDigest(hashAlgo)
+		digest, err := measured.GenerateDigest(hashAlgo)
 		if err != nil {
 			return err
 		}
@@ -146,10 +258,11 @@ func (c *CEL) AppendEvent(tpm io.ReadWriteCloser, pcr int, hashAlgos []crypto.Ha
 	}
 
 	celr := Record{
-		RecNum:  uint64(len(c.Records)),
-		PCR:     uint8(pcr),
-		Digests: digestsMap,
-		Content: eventTlv,
+		RecNum:    uint64(len(c.Records)),
+		PCR:       uint8(pcr),
+		Digests:   digestsMap,
+		Content:   eventTlv,
+		Timestamp: timestamp,
 	}
 
 	c.Records = append(c.Records, celr)
@@ -197,9 +310,45 @@ func unmarshalPCR(tlv TLV) (pcrNum uint8, err error) {
 	return tlv.Value[0], nil
 }
 
+func createTimestampField(timestamp Timestamp) TLV {
+	value := make([]byte, timestampValueLength)
+	value[0] = uint8(timestamp.Source)
+	binary.BigEndian.PutUint64(value[1:], timestamp.Value)
+	return TLV{timestampTypeValue, value}
+}
+
+// unmarshalTimestamp takes in a TLV with its type equals to the timestamp type
+// value (81), and return its timestamp.
+func unmarshalTimestamp(tlv TLV) (Timestamp, error) {
+	if tlv.Type != timestampTypeValue {
+		return Timestamp{}, fmt.Errorf("type of the TLV [%d] indicates it is not a timestamp field [%d]",
+			tlv.Type, timestampTypeValue)
+	}
+	if uint32(len(tlv.Value)) != timestampValueLength {
+		return Timestamp{}, fmt.Errorf(
+			"length of the value of the TLV [%d] doesn't match the defined length [%d] of value for a timestamp field",
+			len(tlv.Value), timestampValueLength)
+	}
+	timestamp := Timestamp{TimestampSource(tlv.Value[0]), binary.BigEndian.Uint64(tlv.Value[1:])}
+	switch timestamp.Source {
+	case TPMClockTimestamps, MonotonicTimestamps:
+		return timestamp, nil
+	}
+	return Timestamp{}, fmt.Errorf("unknown timestamp source %v", timestamp.Source)
+}
+
 func createDigestField(digestMap map[crypto.Hash][]byte) (TLV, error) {
+	// Encode the digests in a fixed order, so the same CEL always has the
+	// same encoding.
+	hashAlgos := make([]crypto.Hash, 0, len(digestMap))
+	for hashAlgo := range digestMap {
+		hashAlgos = append(hashAlgos, hashAlgo)
+	}
+	sort.Slice(hashAlgos, func(i, j int) bool { return hashAlgos[i] < hashAlgos[j] })
+
 	var buf bytes.Buffer
-	for hashAlgo, hash := range digestMap {
+	for _, hashAlgo := range hashAlgos {
+		hash := digestMap[hashAlgo]
 		if len(hash) != hashAlgo.Size() {
 			return TLV{}, fmt.Errorf("digest length [%d] doesn't match the expected length [%d] for the hash algorithm",
 				len(hash), hashAlgo.Size())
@@ -286,6 +435,17 @@ func (r *Record) EncodeCELR(buf *bytes.Buffer) error {
 	if err != nil {
 		return err
 	}
+	// The timestamp field is optional, and follows the content so that the
+	// records without timestamps are encoded as before.
+	if r.Timestamp.Source != NoTimestamps {
+		timestampField, err := createTimestampField(r.Timestamp).MarshalBinary()
+		if err != nil {
+			return err
+		}
+		if _, err := buf.Write(timestampField); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -300,6 +460,23 @@ func (c *CEL) EncodeCEL(buf *bytes.Buffer) error {
 	return nil
 }
 
+// Digest returns the digest of the whole CEL using hash, computed over its
+// encoding by EncodeCEL. The encoding of a CEL is canonical, so two logs with
+// the same records have the same digest, and can be compared without
+// transferring them.
+func (c *CEL) Digest(hash crypto.Hash) ([]byte, error) {
+	if !hash.Available() {
+		return nil, fmt.Errorf("hash algorithm %v is not available", hash)
+	}
+	var buf bytes.Buffer
+	if err := c.EncodeCEL(&buf); err != nil {
+		return nil, err
+	}
+	h := hash.New()
+	h.Write(buf.Bytes())
+	return h.Sum(nil), nil
+}
+
 // DecodeToCEL will read the buf for CEL, will return err if the buffer
 // is not complete.
 func DecodeToCEL(buf *bytes.Buffer) (CEL, error) {
@@ -351,6 +528,19 @@ func DecodeToCELR(buf *bytes.Buffer) (r Record, err error) {
 	if err != nil {
 		return Record{}, err
 	}
+
+	// The next record starts with a recnum field, anything else is the
+	// timestamp of this record.
+	if buf.Len() > 0 && buf.Bytes()[0] != recnumTypeValue {
+		timestamp, err := UnmarshalFirstTLV(buf)
+		if err != nil {
+			return Record{}, err
+		}
+		r.Timestamp, err = unmarshalTimestamp(timestamp)
+		if err != nil {
+			return Record{}, err
+		}
+	}
 	return r, nil
 }
 
@@ -411,3 +601,13 @@ func VerifyDigests(c Content, digestMap map[crypto.Hash][]byte) error {
 	}
 	return nil
 }
+
+// VerifyDigests checks the digest generated by the given content of the record
+// and its timestamp, if any, to make sure they are equal to the digests of the
+// record.
+func (r *Record) VerifyDigests(c Content) error {
+	if r.Timestamp.Source != NoTimestamps {
+		c = timestampedContent{c, r.Timestamp}
+	}
+	return VerifyDigests(c, r.Digests)
+}
@@ -8,7 +8,6 @@ import (
 	"reflect"
 	"testing"
 
-	"github.com/google/go-tpm-tools/client"
 	"github.com/google/go-tpm-tools/internal/test"
 	pb "github.com/google/go-tpm-tools/proto/tpm"
 	"github.com/google/go-tpm/tpm2"
@@ -19,7 +18,7 @@ var measuredHashes = []crypto.Hash{crypto.SHA1, crypto.SHA256}
 
 func TestCELEncodingDecoding(t *testing.T) {
 	tpm := test.GetTPM(t)
-	defer client.CheckedClose(t, tpm)
+	defer test.CheckedClose(t, tpm)
 
 	cel := &CEL{}
 
@@ -58,9 +57,47 @@ func TestCELEncodingDecoding(t *testing.T) {
 	}
 }
 
+func TestCELDigest(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer test.CheckedClose(t, tpm)
+
+	cel := &CEL{}
+	appendOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, CosTlv{ImageRefType, []byte("docker.io/bazel/experimental/test:latest")})
+	appendOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, CosTlv{LaunchSeparatorType, nil})
+
+	digest, err := cel.Digest(crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := cel.EncodeCEL(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decodedCEL, err := DecodeToCEL(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decodedDigest, err := decodedCEL.Digest(crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(digest, decodedDigest) {
+		t.Errorf("digest of the decoded CEL %x differs from the digest of the original CEL %x", decodedDigest, digest)
+	}
+
+	decodedCEL.Records = decodedCEL.Records[:1]
+	truncatedDigest, err := decodedCEL.Digest(crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(digest, truncatedDigest) {
+		t.Error("digest of a truncated CEL equals the digest of the original CEL")
+	}
+}
+
 func TestCELMeasureAndReplay(t *testing.T) {
 	tpm := test.GetTPM(t)
-	defer client.CheckedClose(t, tpm)
+	defer test.CheckedClose(t, tpm)
 
 	err := tpm2.PCRReset(tpm, tpmutil.Handle(test.DebugPCR))
 	if err != nil {
@@ -91,9 +128,68 @@ func TestCELMeasureAndReplay(t *testing.T) {
 		[]int{0, 13, 14, test.DebugPCR, 22, test.ApplicationPCR}, true /*shouldSucceed*/)
 }
 
+func TestCELTimestamps(t *testing.T) {
+	for _, source := range []TimestampSource{TPMClockTimestamps, MonotonicTimestamps} {
+		t.Run(source.String(), func(t *testing.T) {
+			tpm := test.GetTPM(t)
+			defer test.CheckedClose(t, tpm)
+
+			if err := tpm2.PCRReset(tpm, tpmutil.Handle(test.DebugPCR)); err != nil {
+				t.Fatal(err)
+			}
+			cel := &CEL{TimestampSource: source}
+			appendOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, CosTlv{ImageRefType, []byte("docker.io/bazel/experimental/test:latest")})
+			// Untimestamped records can be mixed with timestamped ones.
+			cel.TimestampSource = NoTimestamps
+			appendOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, CosTlv{ImageDigestType, []byte("sha256:781d8dfdd92118436bd914442c8339e653b83f6bf3c1a7a98efcfb7c4fed7483")})
+			cel.TimestampSource = source
+			appendOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, CosTlv{LaunchSeparatorType, nil})
+
+			if cel.Records[0].Timestamp.Source != source || cel.Records[2].Timestamp.Source != source {
+				t.Errorf("got timestamps %v and %v, want source %v", cel.Records[0].Timestamp, cel.Records[2].Timestamp, source)
+			}
+			if cel.Records[1].Timestamp != (Timestamp{}) {
+				t.Errorf("got timestamp %v for an untimestamped record", cel.Records[1].Timestamp)
+			}
+			if cel.Records[2].Timestamp.Value < cel.Records[0].Timestamp.Value {
+				t.Errorf("timestamp %v is before timestamp %v", cel.Records[2].Timestamp, cel.Records[0].Timestamp)
+			}
+
+			var buf bytes.Buffer
+			if err := cel.EncodeCEL(&buf); err != nil {
+				t.Fatal(err)
+			}
+			decodedCEL, err := DecodeToCEL(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(decodedCEL.Records, cel.Records) {
+				t.Errorf("decoded CEL doesn't equal to the original one")
+			}
+			replay(t, &decodedCEL, tpm, measuredHashes, []int{test.DebugPCR}, true /*shouldSucceed*/)
+
+			for i, record := range decodedCEL.Records {
+				content, err := record.Content.ParseToCosTlv()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := record.VerifyDigests(content); err != nil {
+					t.Errorf("failed to verify the digests of record %d: %v", i, err)
+				}
+			}
+			// The timestamp is measured.
+			tampered := decodedCEL.Records[2]
+			tampered.Timestamp.Value++
+			if err := tampered.VerifyDigests(CosTlv{LaunchSeparatorType, nil}); err == nil {
+				t.Error("VerifyDigests() succeeded with a modified timestamp")
+			}
+		})
+	}
+}
+
 func TestCELReplayFailTamperedDigest(t *testing.T) {
 	tpm := test.GetTPM(t)
-	defer client.CheckedClose(t, tpm)
+	defer test.CheckedClose(t, tpm)
 
 	cel := &CEL{}
 
@@ -121,7 +217,7 @@ func TestCELReplayFailTamperedDigest(t *testing.T) {
 
 func TestCELReplayEmpty(t *testing.T) {
 	tpm := test.GetTPM(t)
-	defer client.CheckedClose(t, tpm)
+	defer test.CheckedClose(t, tpm)
 
 	cel := &CEL{}
 	replay(t, cel, tpm, []crypto.Hash{crypto.SHA1, crypto.SHA256},
@@ -130,7 +226,7 @@ func TestCELReplayEmpty(t *testing.T) {
 
 func TestCELReplayFailMissingPCRsInBank(t *testing.T) {
 	tpm := test.GetTPM(t)
-	defer client.CheckedClose(t, tpm)
+	defer test.CheckedClose(t, tpm)
 
 	cel := &CEL{}
 
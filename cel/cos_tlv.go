@@ -2,10 +2,18 @@ package cel
 
 import (
 	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"path"
 	"regexp"
+	"strconv"
 	"strings"
 	"unicode/utf8"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
 )
 
 const (
@@ -14,6 +22,14 @@ const (
 	CosEventType uint8 = 80
 	// CosEventPCR is the PCR which should be used for CosEventType events.
 	CosEventPCR = 13
+	// CosImagePCR is extended, outside of the CEL, with only the
+	// ImageDigestType event of the workload. Unlike CosEventPCR, its value
+	// only depends on the image digest, so secrets can be sealed to it.
+	CosImagePCR = 12
+	// KernelModulePCR is the PCR of the KernelModuleType events, recorded in
+	// the CEL with the COS events. Unlike PCR 14, firmware and shim do not
+	// extend it.
+	KernelModulePCR = 15
 )
 
 // CosType represent a COS content type in a CEL record content.
@@ -31,8 +47,126 @@ const (
 	OverrideEnvType
 	// EventContent is empty on success, or contains an error message on failure.
 	LaunchSeparatorType
+	// EventContent is "true" or "false".
+	HostNetworkType
+	// EventContent is "true" or "false".
+	ReadOnlyRootfsType
+	// EventContent is a capability name (e.g. CAP_NET_ADMIN).
+	AddedCapabilityType
+	// EventContent is a mount formatted by FormatMount.
+	MountType
+	// EventContent is the host path of a device (e.g. /dev/nvidia0).
+	DeviceType
+	// EventContent is the digest of the ephemeral workload public key, as
+	// "sha256:" followed by the hex SHA-256 of its PKIX encoding.
+	WorkloadKeyType
+	// EventContent is the digest of an image layer from the image manifest
+	// (e.g. sha256:...), one event per layer in manifest order.
+	ImageLayerType
+	// EventContent is the digest of the operator's workload configuration, as
+	// "sha256:" followed by its hex SHA-256.
+	WorkloadConfigType
+	// EventContent is the user the container process runs as, "UID" or
+	// "UID:GID". It is only measured if the operator overrides the user of the
+	// image.
+	UserType
+	// EventContent is "true" or "false".
+	UserNamespaceType
+	// EventContent is "unconfined", "runtime-default", or "sha256:" followed
+	// by the hex SHA-256 of a custom JSON seccomp profile.
+	SeccompProfileType
+	// EventContent is "unconfined", "runtime-default", or the name of an
+	// AppArmor profile of the host.
+	AppArmorProfileType
+	// EventContent is the digest of the signed launch policy document
+	// enforced by the launcher, as "sha256:" followed by its hex SHA-256.
+	LaunchPolicyDocumentType
+	// EventContent is the digest of the DER SubjectPublicKeyInfo of the key
+	// which signed the launch policy document, as "sha256:" followed by its
+	// hex SHA-256.
+	LaunchPolicySignerType
+	// EventContent is the time the launcher started, in RFC 3339 format in
+	// UTC, as read from the clock of the VM.
+	BootTimeType
+	// EventContent is the clock the launcher checked the clock of the VM
+	// against at boot: "metadata", "registry", or "unverified".
+	ClockSourceType
+	// EventContent is a checkpoint of the output log of the workload,
+	// formatted by FormatWorkloadOutput. These events are measured while the
+	// workload runs, so they are the only events allowed after the
+	// LaunchSeparator event.
+	WorkloadOutputType
+	// EventContent is the hash algorithms of the digests of every record of
+	// the log, formatted by FormatHashAlgorithms. If present, it is the
+	// header of the log: its first record.
+	HashAlgorithmsType
+	// EventContent is the platform of the image from its config, formatted
+	// as "OS/ARCHITECTURE" with an optional "/VARIANT" (e.g. linux/arm64).
+	ImagePlatformType
+	// EventContent is the digest of the image manifest for the platform
+	// (e.g. sha256:...). It differs from the ImageDigest event when the image
+	// is a multi-arch index.
+	ImageManifestDigestType
+	// EventContent is the version of the COS event schema of the log, as a
+	// decimal number, see CosSchemaVersion. If present, it follows the
+	// HashAlgorithms event in the header of the log. Verifiers tolerate the
+	// event types they do not know in logs with a schema version, so newer
+	// launchers can add event types.
+	SchemaVersionType
+	// EventContent is a kernel module load recorded by IMA, formatted by
+	// FormatKernelModule. Unlike the other events, it is measured into
+	// KernelModulePCR, and can be measured at any time.
+	KernelModuleType
+	// EventContent is a CDI (Container Device Interface) device injected into
+	// the container, with the host paths of its device nodes, formatted by
+	// FormatCDIDevice.
+	CDIDeviceType
+	// EventContent is the name of a workload which is not a container run by
+	// the launcher, e.g. a systemd service, measured by an embedder of the
+	// attestation agent.
+	WorkloadNameType
+	// EventContent is an executable of such a workload, formatted by
+	// FormatWorkloadBinary.
+	WorkloadBinaryType
+	// EventContent is the launcher measuring the events, formatted by
+	// FormatLauncher: its version and the digest of its binary. It is
+	// measured first, after the header of the log.
+	LauncherType
+	// EventContent is the kernel log ring buffer (dmesg) at measurement
+	// time, formatted by FormatKernelLog: its digest and the taint flags of
+	// the kernel.
+	KernelLogType
+	// EventContent is "true" if the launcher only started the workload once
+	// it was attested. It is only measured in that mode.
+	AttestBeforeRunType
+	// EventContent is the URL of the relying party which approved the start
+	// of the workload. It is only measured if an approval was required.
+	ApprovalURLType
 )
 
+// maxCosType is the last CosType defined by this package. It must be updated
+// when adding a type.
+const maxCosType = ApprovalURLType
+
+// CosSchemaVersion is the version of the COS event schema of this package,
+// recorded in the SchemaVersion event. It is incremented when event types are
+// added, or the content of an event type changes.
+const CosSchemaVersion uint32 = 7
+
+// PCR returns the PCR which should be used for events of the COS event type.
+func (t CosType) PCR() int {
+	if t == KernelModuleType {
+		return KernelModulePCR
+	}
+	return CosEventPCR
+}
+
+// IsKnown reports whether the COS event type is defined by this package.
+// Unknown types are event types of a newer schema version.
+func (t CosType) IsKnown() bool {
+	return t <= maxCosType
+}
+
 // CosTlv is a specific event type created for the COS (Google Container-Optimized OS),
 // used as a CEL content.
 type CosTlv struct {
@@ -74,7 +208,9 @@ func (c CosTlv) GenerateDigest(hashAlgo crypto.Hash) ([]byte, error) {
 }
 
 // ParseToCosTlv constructs a CosTlv from a TLV. It will check for the correct COS event
-// type, and unmarshal the nested event.
+// type, and unmarshal the nested event. Events of unknown types (see
+// CosType.IsKnown) are returned with their raw content, so their digests can
+// still be verified.
 func (t TLV) ParseToCosTlv() (CosTlv, error) {
 	if !t.IsCosTlv() {
 		return CosTlv{}, fmt.Errorf("TLV type %v is not a COS event", t.Type)
@@ -124,3 +260,397 @@ func ParseEnvVar(envvar string) (string, string, error) {
 
 	return e[0], e[1], nil
 }
+
+// Mount is a filesystem mounted into the container.
+type Mount struct {
+	// Type is the mount type, either "bind", "tmpfs" or "gcs".
+	Type string
+	// Source is the host path for bind mounts, the bucket name for gcs mounts,
+	// and empty for tmpfs mounts.
+	Source      string
+	Destination string
+	ReadOnly    bool
+}
+
+// Mount type values.
+const (
+	BindMountType  = "bind"
+	TmpfsMountType = "tmpfs"
+	// GCSMountType mounts a Cloud Storage bucket read-only with gcsfuse, after
+	// the workload has been attested.
+	GCSMountType = "gcs"
+)
+
+// FormatMount checks a mount, and returns its representation as a comma
+// separated list of key=value pairs (type, source, destination, readonly).
+// Returns an error if the mount is invalid.
+func FormatMount(m Mount) (string, error) {
+	switch m.Type {
+	case BindMountType:
+		if !path.IsAbs(m.Source) {
+			return "", fmt.Errorf("malformed mount, bind source must be an absolute path: [%s]", m.Source)
+		}
+	case TmpfsMountType:
+		if m.Source != "" {
+			return "", fmt.Errorf("malformed mount, tmpfs cannot have a source: [%s]", m.Source)
+		}
+	case GCSMountType:
+		if m.Source == "" || strings.Contains(m.Source, "/") {
+			return "", fmt.Errorf("malformed mount, gcs source must be a bucket name: [%s]", m.Source)
+		}
+		if !m.ReadOnly {
+			return "", fmt.Errorf("malformed mount, gcs mounts must be read-only")
+		}
+	default:
+		return "", fmt.Errorf("malformed mount, unknown type: [%s]", m.Type)
+	}
+	if !path.IsAbs(m.Destination) {
+		return "", fmt.Errorf("malformed mount, destination must be an absolute path: [%s]", m.Destination)
+	}
+	for _, p := range []string{m.Source, m.Destination} {
+		if !utf8.ValidString(p) || strings.ContainsAny(p, ",=;") {
+			return "", fmt.Errorf("malformed mount, path contains an invalid character: [%s]", p)
+		}
+	}
+	return fmt.Sprintf("type=%s,source=%s,destination=%s,readonly=%t", m.Type, m.Source, m.Destination, m.ReadOnly), nil
+}
+
+// ParseMount takes in a mount as a comma separated list of key=value pairs
+// (type=bind,source=/a,destination=/b,readonly=true), parses it and returns the
+// Mount, or an error if it fails the validation check. The source and readonly
+// keys are optional, and gcs mounts default to read-only.
+func ParseMount(mount string) (Mount, error) {
+	var m Mount
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(mount, ",") {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) < 2 {
+			return Mount{}, fmt.Errorf("malformed mount, field doesn't contain '=': [%s]", field)
+		}
+		key, val := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		if seen[key] {
+			return Mount{}, fmt.Errorf("malformed mount, duplicate field: [%s]", key)
+		}
+		seen[key] = true
+		switch key {
+		case "type":
+			m.Type = val
+		case "source":
+			m.Source = val
+		case "destination":
+			m.Destination = val
+		case "readonly":
+			readOnly, err := strconv.ParseBool(val)
+			if err != nil {
+				return Mount{}, fmt.Errorf("malformed mount, readonly is not a boolean: [%s]", val)
+			}
+			m.ReadOnly = readOnly
+		default:
+			return Mount{}, fmt.Errorf("malformed mount, unknown field: [%s]", key)
+		}
+	}
+	if m.Type == GCSMountType && !seen["readonly"] {
+		m.ReadOnly = true
+	}
+	if _, err := FormatMount(m); err != nil {
+		return Mount{}, err
+	}
+	return m, nil
+}
+
+// WorkloadOutput is a checkpoint of the output log of the workload: the
+// SHA-256 digest of its first Size bytes.
+type WorkloadOutput struct {
+	Size   uint64
+	Digest []byte
+}
+
+// FormatWorkloadOutput checks a checkpoint of the output log, and returns its
+// representation as "size=N,digest=sha256:" followed by the hex digest.
+func FormatWorkloadOutput(o WorkloadOutput) (string, error) {
+	if len(o.Digest) != sha256.Size {
+		return "", fmt.Errorf("malformed workload output, digest length [%d] is not a SHA-256 digest", len(o.Digest))
+	}
+	return fmt.Sprintf("size=%d,digest=sha256:%x", o.Size, o.Digest), nil
+}
+
+// ParseWorkloadOutput takes in a checkpoint of the output log formatted by
+// FormatWorkloadOutput, parses it and returns the WorkloadOutput, or an error
+// if it is malformed.
+func ParseWorkloadOutput(output string) (WorkloadOutput, error) {
+	var o WorkloadOutput
+	var digest string
+	if _, err := fmt.Sscanf(output, "size=%d,digest=sha256:%s", &o.Size, &digest); err != nil {
+		return WorkloadOutput{}, fmt.Errorf("malformed workload output [%s]: %v", output, err)
+	}
+	var err error
+	if o.Digest, err = hex.DecodeString(digest); err != nil {
+		return WorkloadOutput{}, fmt.Errorf("malformed workload output [%s], digest is not hex: %v", output, err)
+	}
+	if formatted, err := FormatWorkloadOutput(o); err != nil {
+		return WorkloadOutput{}, err
+	} else if formatted != output {
+		return WorkloadOutput{}, fmt.Errorf("malformed workload output [%s], not in canonical form", output)
+	}
+	return o, nil
+}
+
+var hashAlgorithmNames = map[crypto.Hash]string{
+	crypto.SHA1:   "sha1",
+	crypto.SHA256: "sha256",
+	crypto.SHA384: "sha384",
+	crypto.SHA512: "sha512",
+}
+
+// FormatSchemaVersion returns the content of the SchemaVersion event of the
+// schema version.
+func FormatSchemaVersion(version uint32) string {
+	return strconv.FormatUint(uint64(version), 10)
+}
+
+// ParseSchemaVersion parses the content of a SchemaVersion event, formatted
+// by FormatSchemaVersion, or returns an error if it is malformed. Versions
+// start at 1.
+func ParseSchemaVersion(version string) (uint32, error) {
+	if version == "" || strings.TrimLeft(version, "0123456789") != "" {
+		return 0, fmt.Errorf("malformed schema version [%s]", version)
+	}
+	parsed, err := strconv.ParseUint(version, 10, 32)
+	if err != nil || parsed == 0 {
+		return 0, fmt.Errorf("malformed schema version [%s]", version)
+	}
+	return uint32(parsed), nil
+}
+
+// FormatKernelModule returns the content of the KernelModule event of a kernel
+// module load, from the file digest (e.g. sha256:...) and the file name
+// recorded by IMA.
+func FormatKernelModule(fileDigest string, fileName string) (string, error) {
+	if fileDigest == "" || strings.ContainsAny(fileDigest, " \n") {
+		return "", fmt.Errorf("malformed kernel module digest [%s]", fileDigest)
+	}
+	if fileName == "" || strings.Contains(fileName, "\n") {
+		return "", fmt.Errorf("malformed kernel module file name [%s]", fileName)
+	}
+	return fileDigest + " " + fileName, nil
+}
+
+// ParseKernelModule parses the content of a KernelModule event, formatted by
+// FormatKernelModule, into the file digest and file name of the module.
+func ParseKernelModule(content string) (fileDigest string, fileName string, err error) {
+	fileDigest, fileName, ok := strings.Cut(content, " ")
+	if !ok || fileDigest == "" || fileName == "" || strings.Contains(fileName, "\n") {
+		return "", "", fmt.Errorf("malformed kernel module event [%s]", content)
+	}
+	return fileDigest, fileName, nil
+}
+
+// FormatWorkloadBinary returns the content of the WorkloadBinary event of an
+// executable of the workload, from its file digest (e.g. sha256:...) and its
+// path, as the digest followed by a space and the path.
+func FormatWorkloadBinary(fileDigest string, path string) (string, error) {
+	if fileDigest == "" || strings.ContainsAny(fileDigest, " \n") {
+		return "", fmt.Errorf("malformed workload binary digest [%s]", fileDigest)
+	}
+	if path == "" || strings.Contains(path, "\n") {
+		return "", fmt.Errorf("malformed workload binary path [%s]", path)
+	}
+	return fileDigest + " " + path, nil
+}
+
+// ParseWorkloadBinary parses the content of a WorkloadBinary event, formatted
+// by FormatWorkloadBinary, into the file digest and path of the executable.
+func ParseWorkloadBinary(content string) (fileDigest string, path string, err error) {
+	fileDigest, path, ok := strings.Cut(content, " ")
+	if !ok || fileDigest == "" || path == "" || strings.Contains(path, "\n") {
+		return "", "", fmt.Errorf("malformed workload binary event [%s]", content)
+	}
+	return fileDigest, path, nil
+}
+
+// FormatLauncher returns the content of the Launcher event, from the
+// semantic version of the launcher ("MAJOR.MINOR.PATCH") and the digest of
+// its binary (e.g. sha256:...), as the version followed by a space and the
+// digest.
+func FormatLauncher(version string, binaryDigest string) (string, error) {
+	if _, _, _, err := ParseSemanticVersion(version); err != nil {
+		return "", err
+	}
+	if binaryDigest == "" || strings.ContainsAny(binaryDigest, " \n") {
+		return "", fmt.Errorf("malformed launcher binary digest [%s]", binaryDigest)
+	}
+	return version + " " + binaryDigest, nil
+}
+
+// ParseLauncher parses the content of a Launcher event, formatted by
+// FormatLauncher, into the version of the launcher and the digest of its
+// binary.
+func ParseLauncher(content string) (version string, binaryDigest string, err error) {
+	version, binaryDigest, ok := strings.Cut(content, " ")
+	if !ok || binaryDigest == "" || strings.ContainsAny(binaryDigest, " \n") {
+		return "", "", fmt.Errorf("malformed launcher event [%s]", content)
+	}
+	if _, _, _, err := ParseSemanticVersion(version); err != nil {
+		return "", "", err
+	}
+	return version, binaryDigest, nil
+}
+
+// FormatKernelLog returns the content of the KernelLog event, from the digest
+// of the kernel log (e.g. sha256:...) and the taint flags of the kernel (see
+// /proc/sys/kernel/tainted), as the digest followed by a space and the flags
+// as a decimal number.
+func FormatKernelLog(logDigest string, taint uint64) (string, error) {
+	if logDigest == "" || strings.ContainsAny(logDigest, " \n") {
+		return "", fmt.Errorf("malformed kernel log digest [%s]", logDigest)
+	}
+	return logDigest + " " + strconv.FormatUint(taint, 10), nil
+}
+
+// ParseKernelLog parses the content of a KernelLog event, formatted by
+// FormatKernelLog, into the digest of the kernel log and the taint flags.
+func ParseKernelLog(content string) (logDigest string, taint uint64, err error) {
+	logDigest, flags, ok := strings.Cut(content, " ")
+	if !ok || logDigest == "" || flags == "" || strings.TrimLeft(flags, "0123456789") != "" {
+		return "", 0, fmt.Errorf("malformed kernel log event [%s]", content)
+	}
+	taint, err = strconv.ParseUint(flags, 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("malformed kernel log event [%s]", content)
+	}
+	return logDigest, taint, nil
+}
+
+// ParseSemanticVersion parses a "MAJOR.MINOR.PATCH" version of decimal
+// numbers.
+func ParseSemanticVersion(version string) (major, minor, patch uint32, err error) {
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return 0, 0, 0, fmt.Errorf("malformed semantic version [%s]", version)
+	}
+	var numbers [3]uint32
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || (len(part) > 1 && part[0] == '0') {
+			return 0, 0, 0, fmt.Errorf("malformed semantic version [%s]", version)
+		}
+		numbers[i] = uint32(n)
+	}
+	return numbers[0], numbers[1], numbers[2], nil
+}
+
+// FormatCDIDevice returns the content of the CDIDevice event of a CDI device,
+// from its fully qualified name (e.g. nvidia.com/gpu=0) and the host paths of
+// its device nodes, as the name followed by a space and the paths separated
+// by ','.
+func FormatCDIDevice(name string, deviceNodes []string) (string, error) {
+	if name == "" || strings.ContainsAny(name, " ,\n") {
+		return "", fmt.Errorf("malformed CDI device name [%s]", name)
+	}
+	for _, node := range deviceNodes {
+		if node == "" || strings.ContainsAny(node, ",\n") {
+			return "", fmt.Errorf("malformed CDI device node [%s]", node)
+		}
+	}
+	return name + " " + strings.Join(deviceNodes, ","), nil
+}
+
+// ParseCDIDevice parses the content of a CDIDevice event, formatted by
+// FormatCDIDevice, into the name of the device and the host paths of its
+// device nodes.
+func ParseCDIDevice(content string) (name string, deviceNodes []string, err error) {
+	name, nodes, ok := strings.Cut(content, " ")
+	if !ok || name == "" || strings.ContainsAny(name, ",\n") || strings.Contains(nodes, "\n") {
+		return "", nil, fmt.Errorf("malformed CDI device event [%s]", content)
+	}
+	if nodes == "" {
+		return name, nil, nil
+	}
+	deviceNodes = strings.Split(nodes, ",")
+	for _, node := range deviceNodes {
+		if node == "" {
+			return "", nil, fmt.Errorf("malformed CDI device event [%s]", content)
+		}
+	}
+	return name, deviceNodes, nil
+}
+
+// FormatHashAlgorithms checks the hash algorithms of the digests of the
+// records, and returns their names separated by ',', e.g. "sha256,sha384".
+func FormatHashAlgorithms(hashAlgos []crypto.Hash) (string, error) {
+	if len(hashAlgos) == 0 {
+		return "", fmt.Errorf("need to specify at least one hash algorithm")
+	}
+	names := make([]string, 0, len(hashAlgos))
+	for i, hashAlgo := range hashAlgos {
+		name, ok := hashAlgorithmNames[hashAlgo]
+		if !ok {
+			return "", fmt.Errorf("unsupported hash algorithm %v", hashAlgo)
+		}
+		for _, previous := range hashAlgos[:i] {
+			if previous == hashAlgo {
+				return "", fmt.Errorf("duplicate hash algorithm %v", hashAlgo)
+			}
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ","), nil
+}
+
+// ParseHashAlgorithms takes in hash algorithms formatted by
+// FormatHashAlgorithms, and returns them in order, or an error if they are
+// malformed.
+func ParseHashAlgorithms(hashAlgos string) ([]crypto.Hash, error) {
+	var parsed []crypto.Hash
+	for _, name := range strings.Split(hashAlgos, ",") {
+		found := false
+		for hashAlgo, hashName := range hashAlgorithmNames {
+			if name == hashName {
+				parsed = append(parsed, hashAlgo)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("malformed hash algorithms [%s], unknown hash algorithm [%s]", hashAlgos, name)
+		}
+	}
+	if _, err := FormatHashAlgorithms(parsed); err != nil {
+		return nil, fmt.Errorf("malformed hash algorithms [%s]: %v", hashAlgos, err)
+	}
+	return parsed, nil
+}
+
+// ExtendImagePCR extends the ImageDigestType event of the image digest to the
+// hashAlgos banks of CosImagePCR. It must be called once, when the image of the
+// workload is measured.
+func ExtendImagePCR(tpm io.ReadWriter, hashAlgos []crypto.Hash, imageDigest string) error {
+	event := CosTlv{EventType: ImageDigestType, EventContent: []byte(imageDigest)}
+	for _, hashAlgo := range hashAlgos {
+		digest, err := event.GenerateDigest(hashAlgo)
+		if err != nil {
+			return err
+		}
+		tpm2Alg, err := tpm2.HashToAlgorithm(hashAlgo)
+		if err != nil {
+			return err
+		}
+		if err := tpm2.PCRExtend(tpm, tpmutil.Handle(CosImagePCR), tpm2Alg, digest, ""); err != nil {
+			return fmt.Errorf("failed to extend image digest to PCR%d: %v", CosImagePCR, err)
+		}
+	}
+	return nil
+}
+
+// ImagePCRValue returns the value of CosImagePCR in the hashAlgo bank once the
+// workload with the image digest was launched.
+func ImagePCRValue(hashAlgo crypto.Hash, imageDigest string) ([]byte, error) {
+	digest, err := CosTlv{EventType: ImageDigestType, EventContent: []byte(imageDigest)}.GenerateDigest(hashAlgo)
+	if err != nil {
+		return nil, err
+	}
+	hash := hashAlgo.New()
+	hash.Write(make([]byte, hashAlgo.Size()))
+	hash.Write(digest)
+	return hash.Sum(nil), nil
+}
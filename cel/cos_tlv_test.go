@@ -2,18 +2,20 @@ package cel
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/sha256"
 	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
-	"github.com/google/go-tpm-tools/client"
 	"github.com/google/go-tpm-tools/internal/test"
 	pb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm/tpm2"
 )
 
 func TestCosEventlog(t *testing.T) {
 	tpm := test.GetTPM(t)
-	defer client.CheckedClose(t, tpm)
+	defer test.CheckedClose(t, tpm)
 
 	cel := &CEL{}
 
@@ -73,6 +75,35 @@ func TestCosEventlog(t *testing.T) {
 	}
 }
 
+func TestImagePCR(t *testing.T) {
+	test.SkipForRealTPM(t)
+	tpm := test.GetTPM(t)
+	defer test.CheckedClose(t, tpm)
+
+	const imageDigest = "sha256:781d8dfdd92118436bd914442c8339e653b83f6bf3c1a7a98efcfb7c4fed7483"
+	hashAlgos := []crypto.Hash{crypto.SHA256, crypto.SHA1}
+	if err := ExtendImagePCR(tpm, hashAlgos, imageDigest); err != nil {
+		t.Fatal(err)
+	}
+	for _, hashAlgo := range hashAlgos {
+		tpm2Alg, err := tpm2.HashToAlgorithm(hashAlgo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := tpm2.ReadPCR(tpm, CosImagePCR, tpm2Alg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := ImagePCRValue(hashAlgo, imageDigest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%v image PCR is %x, want %x", hashAlgo, got, want)
+		}
+	}
+}
+
 func TestParseEnvVar(t *testing.T) {
 	tests := []struct {
 		testName             string
@@ -126,3 +157,312 @@ func TestParseEnvVar(t *testing.T) {
 		})
 	}
 }
+
+func TestParseMount(t *testing.T) {
+	tests := []struct {
+		testName             string
+		mount                string
+		want                 Mount
+		expectedErrSubstring string
+	}{
+		{"bind", "type=bind,source=/mnt/disks/data,destination=/data,readonly=true", Mount{"bind", "/mnt/disks/data", "/data", true}, ""},
+		{"tmpfs", "type=tmpfs,source=,destination=/tmp,readonly=false", Mount{"tmpfs", "", "/tmp", false}, ""},
+		{"optional fields", "type=tmpfs,destination=/tmp", Mount{"tmpfs", "", "/tmp", false}, ""},
+		{"gcs", "type=gcs,source=my-bucket,destination=/data", Mount{"gcs", "my-bucket", "/data", true}, ""},
+		{"gcs path source", "type=gcs,source=/mnt/disks/data,destination=/data", Mount{}, "gcs source must be a bucket name"},
+		{"gcs no source", "type=gcs,destination=/data", Mount{}, "gcs source must be a bucket name"},
+		{"gcs read-write", "type=gcs,source=my-bucket,destination=/data,readonly=false", Mount{}, "gcs mounts must be read-only"},
+		{"unknown type", "type=overlay,destination=/tmp", Mount{}, "unknown type"},
+		{"relative source", "type=bind,source=data,destination=/data", Mount{}, "bind source must be an absolute path"},
+		{"tmpfs source", "type=tmpfs,source=/a,destination=/data", Mount{}, "tmpfs cannot have a source"},
+		{"relative destination", "type=tmpfs,destination=tmp", Mount{}, "destination must be an absolute path"},
+		{"no =", "type", Mount{}, "doesn't contain '='"},
+		{"duplicate field", "type=tmpfs,destination=/a,destination=/b", Mount{}, "duplicate field"},
+		{"unknown field", "type=tmpfs,destination=/a,size=10m", Mount{}, "unknown field"},
+		{"bad readonly", "type=tmpfs,destination=/a,readonly=maybe", Mount{}, "readonly is not a boolean"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			m, err := ParseMount(test.mount)
+			if test.expectedErrSubstring != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectedErrSubstring) {
+					t.Errorf("expected error substring [%s], but got [%v]", test.expectedErrSubstring, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, but got [%s]", err)
+			}
+			if m != test.want {
+				t.Errorf("mount mismatch, want %+v, got %+v", test.want, m)
+			}
+			formatted, err := FormatMount(m)
+			if err != nil {
+				t.Fatalf("expected no error, but got [%s]", err)
+			}
+			if roundTrip, err := ParseMount(formatted); err != nil || roundTrip != m {
+				t.Errorf("formatted mount [%s] did not round trip: got %+v, %v", formatted, roundTrip, err)
+			}
+		})
+	}
+}
+
+func TestParseWorkloadOutput(t *testing.T) {
+	digest := strings.Repeat("ab", sha256.Size)
+	tests := []struct {
+		testName             string
+		output               string
+		expectedErrSubstring string
+	}{
+		{"valid", "size=1024,digest=sha256:" + digest, ""},
+		{"empty log", "size=0,digest=sha256:" + digest, ""},
+		{"no size", "digest=sha256:" + digest, "malformed workload output"},
+		{"negative size", "size=-1,digest=sha256:" + digest, "malformed workload output"},
+		{"not hex", "size=1,digest=sha256:" + strings.Repeat("zz", sha256.Size), "digest is not hex"},
+		{"short digest", "size=1,digest=sha256:abcd", "is not a SHA-256 digest"},
+		{"uppercase digest", "size=1,digest=sha256:" + strings.ToUpper(digest), "not in canonical form"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			o, err := ParseWorkloadOutput(test.output)
+			if test.expectedErrSubstring != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectedErrSubstring) {
+					t.Errorf("expected error substring [%s], but got [%v]", test.expectedErrSubstring, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, but got [%s]", err)
+			}
+			if formatted, err := FormatWorkloadOutput(o); err != nil || formatted != test.output {
+				t.Errorf("workload output [%s] did not round trip: got [%s], %v", test.output, formatted, err)
+			}
+		})
+	}
+}
+
+func TestParseHashAlgorithms(t *testing.T) {
+	tests := []struct {
+		testName             string
+		hashAlgos            string
+		want                 []crypto.Hash
+		expectedErrSubstring string
+	}{
+		{"single", "sha256", []crypto.Hash{crypto.SHA256}, ""},
+		{"multiple", "sha256,sha384,sha1", []crypto.Hash{crypto.SHA256, crypto.SHA384, crypto.SHA1}, ""},
+		{"empty", "", nil, "unknown hash algorithm"},
+		{"unknown", "sha256,md5", nil, "unknown hash algorithm [md5]"},
+		{"uppercase", "SHA256", nil, "unknown hash algorithm"},
+		{"spaces", "sha256, sha384", nil, "unknown hash algorithm"},
+		{"duplicate", "sha256,sha256", nil, "duplicate hash algorithm"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			got, err := ParseHashAlgorithms(test.hashAlgos)
+			if test.expectedErrSubstring != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectedErrSubstring) {
+					t.Errorf("expected error substring [%s], but got [%v]", test.expectedErrSubstring, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, but got [%s]", err)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("got hash algorithms %v, want %v", got, test.want)
+			}
+			if formatted, err := FormatHashAlgorithms(got); err != nil || formatted != test.hashAlgos {
+				t.Errorf("hash algorithms [%s] did not round trip: got [%s], %v", test.hashAlgos, formatted, err)
+			}
+		})
+	}
+}
+
+func TestParseSchemaVersion(t *testing.T) {
+	for _, test := range []struct {
+		version string
+		want    uint32
+		wantErr bool
+	}{
+		{"1", 1, false},
+		{"42", 42, false},
+		{"", 0, true},
+		{"0", 0, true},
+		{"+1", 0, true},
+		{"v1", 0, true},
+		{"1.0", 0, true},
+		{"4294967296", 0, true},
+	} {
+		t.Run(test.version, func(t *testing.T) {
+			got, err := ParseSchemaVersion(test.version)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("ParseSchemaVersion(%q) got error %v, want error %v", test.version, err, test.wantErr)
+			}
+			if err == nil && (got != test.want || FormatSchemaVersion(got) != test.version) {
+				t.Errorf("ParseSchemaVersion(%q) got %d, want %d", test.version, got, test.want)
+			}
+		})
+	}
+}
+
+func TestCosTypeIsKnown(t *testing.T) {
+	if !ApprovalURLType.IsKnown() || !ImageRefType.IsKnown() {
+		t.Error("defined COS types are not known")
+	}
+	if (ApprovalURLType + 1).IsKnown() {
+		t.Errorf("COS type %d is known", ApprovalURLType+1)
+	}
+}
+
+func TestCosTypePCR(t *testing.T) {
+	if pcr := KernelModuleType.PCR(); pcr != KernelModulePCR {
+		t.Errorf("got KernelModule PCR %d, want %d", pcr, KernelModulePCR)
+	}
+	if pcr := ImageRefType.PCR(); pcr != CosEventPCR {
+		t.Errorf("got ImageRef PCR %d, want %d", pcr, CosEventPCR)
+	}
+}
+
+func TestKernelModule(t *testing.T) {
+	content, err := FormatKernelModule("sha256:0123", "/lib/modules/6.1.0/kernel/fs/fuse/fuse.ko.xz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileDigest, fileName, err := ParseKernelModule(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fileDigest != "sha256:0123" || fileName != "/lib/modules/6.1.0/kernel/fs/fuse/fuse.ko.xz" {
+		t.Errorf("got digest %q and file name %q from %q", fileDigest, fileName, content)
+	}
+	// File names may contain spaces.
+	if _, fileName, err := ParseKernelModule("sha256:0123 /tmp/my module.ko"); err != nil || fileName != "/tmp/my module.ko" {
+		t.Errorf("got file name %q, %v, want /tmp/my module.ko", fileName, err)
+	}
+
+	for _, bad := range [][2]string{{"", "fuse.ko"}, {"sha256:01 23", "fuse.ko"}, {"sha256:0123", ""}, {"sha256:0123", "fuse\n.ko"}} {
+		if _, err := FormatKernelModule(bad[0], bad[1]); err == nil {
+			t.Errorf("FormatKernelModule(%q, %q) succeeded", bad[0], bad[1])
+		}
+	}
+	for _, bad := range []string{"", "sha256:0123", " fuse.ko", "sha256:0123 "} {
+		if _, _, err := ParseKernelModule(bad); err == nil {
+			t.Errorf("ParseKernelModule(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestCDIDevice(t *testing.T) {
+	content, err := FormatCDIDevice("nvidia.com/gpu=0", []string{"/dev/nvidia0", "/dev/nvidiactl"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, deviceNodes, err := ParseCDIDevice(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "nvidia.com/gpu=0" {
+		t.Errorf("got name %q from %q", name, content)
+	}
+	if diff := cmp.Diff([]string{"/dev/nvidia0", "/dev/nvidiactl"}, deviceNodes); diff != "" {
+		t.Errorf("unexpected device nodes (-want +got):\n%s", diff)
+	}
+	// Devices may only edit the environment of the container.
+	content, err = FormatCDIDevice("example.com/env=default", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, deviceNodes, err := ParseCDIDevice(content); err != nil || name != "example.com/env=default" || len(deviceNodes) != 0 {
+		t.Errorf("got %q, %q, %v from %q", name, deviceNodes, err, content)
+	}
+
+	if _, err := FormatCDIDevice("nvidia.com/gpu 0", nil); err == nil {
+		t.Error("FormatCDIDevice succeeded with a space in the name")
+	}
+	if _, err := FormatCDIDevice("nvidia.com/gpu=0", []string{"/dev/a,b"}); err == nil {
+		t.Error("FormatCDIDevice succeeded with a comma in a device node")
+	}
+	for _, bad := range []string{"", "nvidia.com/gpu=0", " /dev/nvidia0", "nvidia.com/gpu=0 /dev/nvidia0,"} {
+		if _, _, err := ParseCDIDevice(bad); err == nil {
+			t.Errorf("ParseCDIDevice(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestLauncher(t *testing.T) {
+	content, err := FormatLauncher("1.12.0", "sha256:abcd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	version, binaryDigest, err := ParseLauncher(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "1.12.0" || binaryDigest != "sha256:abcd" {
+		t.Errorf("got %q, %q from %q", version, binaryDigest, content)
+	}
+	major, minor, patch, err := ParseSemanticVersion(version)
+	if err != nil || major != 1 || minor != 12 || patch != 0 {
+		t.Errorf("ParseSemanticVersion(%q) = %d, %d, %d, %v", version, major, minor, patch, err)
+	}
+
+	for _, bad := range []string{"", "1.2", "1.2.3.4", "1.2.x", "1.02.3", "v1.2.3", "1.2.-3"} {
+		if _, err := FormatLauncher(bad, "sha256:abcd"); err == nil {
+			t.Errorf("FormatLauncher(%q) succeeded", bad)
+		}
+	}
+	for _, bad := range []string{"", "1.2.3", "1.2.3 ", "1.2 sha256:abcd", "1.2.3 sha256:ab cd"} {
+		if _, _, err := ParseLauncher(bad); err == nil {
+			t.Errorf("ParseLauncher(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestKernelLog(t *testing.T) {
+	content, err := FormatKernelLog("sha256:abcd", 4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logDigest, taint, err := ParseKernelLog(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logDigest != "sha256:abcd" || taint != 4096 {
+		t.Errorf("got %q, %d from %q", logDigest, taint, content)
+	}
+
+	if _, err := FormatKernelLog("sha256:ab cd", 0); err == nil {
+		t.Error("FormatKernelLog succeeded with a space in the digest")
+	}
+	for _, bad := range []string{"", "sha256:abcd", "sha256:abcd ", " 0", "sha256:abcd -1", "sha256:abcd 0x1000", "sha256:abcd 99999999999999999999"} {
+		if _, _, err := ParseKernelLog(bad); err == nil {
+			t.Errorf("ParseKernelLog(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestWorkloadBinary(t *testing.T) {
+	content, err := FormatWorkloadBinary("sha256:abcd", "/usr/local/bin/my service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileDigest, path, err := ParseWorkloadBinary(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fileDigest != "sha256:abcd" || path != "/usr/local/bin/my service" {
+		t.Errorf("got %q, %q from %q", fileDigest, path, content)
+	}
+
+	if _, err := FormatWorkloadBinary("sha256:ab cd", "/bin/true"); err == nil {
+		t.Error("FormatWorkloadBinary succeeded with a space in the digest")
+	}
+	for _, bad := range []string{"", "sha256:abcd", " /bin/true", "sha256:abcd "} {
+		if _, _, err := ParseWorkloadBinary(bad); err == nil {
+			t.Errorf("ParseWorkloadBinary(%q) succeeded", bad)
+		}
+	}
+}
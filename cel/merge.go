@@ -0,0 +1,181 @@
+package cel
+
+import (
+	"bytes"
+	"crypto"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Merge merges fragments of the same Canonical Event Log, e.g. the records
+// measured before and after the launch of a workload, retrieved from
+// different channels. The records are ordered by RecNum. Fragments can
+// overlap, but the records with the same RecNum must be identical, and the
+// merged records must be consecutive. The TimestampSource of the merged log
+// is the one of the last fragment.
+func Merge(fragments ...CEL) (CEL, error) {
+	var merged CEL
+	byRecNum := make(map[uint64]Record)
+	for i, fragment := range fragments {
+		for _, record := range fragment.Records {
+			if existing, ok := byRecNum[record.RecNum]; ok {
+				if fields := diffRecordFields(existing, record); len(fields) > 0 {
+					return CEL{}, fmt.Errorf("fragment %d: record %d conflicts with a previous fragment in its %s", i, record.RecNum, strings.Join(fields, ", "))
+				}
+				continue
+			}
+			byRecNum[record.RecNum] = record
+			merged.Records = append(merged.Records, record)
+		}
+		merged.TimestampSource = fragment.TimestampSource
+	}
+	sort.SliceStable(merged.Records, func(i, j int) bool {
+		return merged.Records[i].RecNum < merged.Records[j].RecNum
+	})
+	for i := 1; i < len(merged.Records); i++ {
+		if prev, next := merged.Records[i-1].RecNum, merged.Records[i].RecNum; next != prev+1 {
+			return CEL{}, fmt.Errorf("records %d to %d are missing from the fragments", prev+1, next-1)
+		}
+	}
+	return merged, nil
+}
+
+// RecordDiff is a difference between the records with the same RecNum of
+// two Canonical Event Logs.
+type RecordDiff struct {
+	RecNum uint64
+	// A and B are the records of the compared logs, nil if the log has no
+	// record with RecNum.
+	A, B *Record
+	// Fields are the different fields of the records: "pcr", "digests",
+	// "content" or "timestamp". It is empty if one of the records is nil.
+	Fields []string
+}
+
+// Compare compares two Canonical Event Logs structurally, record by record
+// in order of RecNum, and returns their differences. Unlike comparing their
+// Digest, it tells which records differ.
+func Compare(a, b CEL) []RecordDiff {
+	recordsA := recordsByRecNum(a)
+	recordsB := recordsByRecNum(b)
+	var recNums []uint64
+	for recNum := range recordsA {
+		recNums = append(recNums, recNum)
+	}
+	for recNum := range recordsB {
+		if _, ok := recordsA[recNum]; !ok {
+			recNums = append(recNums, recNum)
+		}
+	}
+	sort.Slice(recNums, func(i, j int) bool { return recNums[i] < recNums[j] })
+
+	var diffs []RecordDiff
+	for _, recNum := range recNums {
+		recordA, okA := recordsA[recNum]
+		recordB, okB := recordsB[recNum]
+		switch {
+		case !okA:
+			diffs = append(diffs, RecordDiff{RecNum: recNum, B: &recordB})
+		case !okB:
+			diffs = append(diffs, RecordDiff{RecNum: recNum, A: &recordA})
+		default:
+			if fields := diffRecordFields(recordA, recordB); len(fields) > 0 {
+				diffs = append(diffs, RecordDiff{RecNum: recNum, A: &recordA, B: &recordB, Fields: fields})
+			}
+		}
+	}
+	return diffs
+}
+
+// Diff returns a human-readable description of the differences between two
+// Canonical Event Logs, one line per different record, or "" if they have
+// the same records.
+func Diff(a, b CEL) string {
+	var sb strings.Builder
+	for _, d := range Compare(a, b) {
+		switch {
+		case d.A == nil:
+			fmt.Fprintf(&sb, "record %d: only in b: %s\n", d.RecNum, formatRecord(*d.B))
+		case d.B == nil:
+			fmt.Fprintf(&sb, "record %d: only in a: %s\n", d.RecNum, formatRecord(*d.A))
+		default:
+			fmt.Fprintf(&sb, "record %d: different %s:\n", d.RecNum, strings.Join(d.Fields, ", "))
+			fmt.Fprintf(&sb, "  a: %s\n", formatRecord(*d.A))
+			fmt.Fprintf(&sb, "  b: %s\n", formatRecord(*d.B))
+		}
+	}
+	return sb.String()
+}
+
+func recordsByRecNum(c CEL) map[uint64]Record {
+	records := make(map[uint64]Record, len(c.Records))
+	for _, record := range c.Records {
+		records[record.RecNum] = record
+	}
+	return records
+}
+
+// diffRecordFields returns the different fields of two records, see
+// RecordDiff.Fields.
+func diffRecordFields(a, b Record) []string {
+	var fields []string
+	if a.PCR != b.PCR {
+		fields = append(fields, "pcr")
+	}
+	if !equalDigests(a.Digests, b.Digests) {
+		fields = append(fields, "digests")
+	}
+	if a.Content.Type != b.Content.Type || !bytes.Equal(a.Content.Value, b.Content.Value) {
+		fields = append(fields, "content")
+	}
+	if a.Timestamp != b.Timestamp {
+		fields = append(fields, "timestamp")
+	}
+	return fields
+}
+
+func equalDigests(a, b map[crypto.Hash][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for hash, digest := range a {
+		if other, ok := b[hash]; !ok || !bytes.Equal(digest, other) {
+			return false
+		}
+	}
+	return true
+}
+
+// formatRecord formats a record on one line, with its content as a string
+// if it is a COS event with printable content.
+func formatRecord(r Record) string {
+	var content string
+	if cos, err := r.Content.ParseToCosTlv(); err == nil {
+		content = fmt.Sprintf("COS event type %d %s", cos.EventType, formatContent(cos.EventContent))
+	} else {
+		content = fmt.Sprintf("content type %d %s", r.Content.Type, formatContent(r.Content.Value))
+	}
+	hashes := make([]crypto.Hash, 0, len(r.Digests))
+	for hash := range r.Digests {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+	digests := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		digests = append(digests, fmt.Sprintf("%v:%x", hash, r.Digests[hash]))
+	}
+	s := fmt.Sprintf("PCR%d %s digests [%s]", r.PCR, content, strings.Join(digests, " "))
+	if r.Timestamp.Source != NoTimestamps {
+		s += fmt.Sprintf(" timestamp %v %d", r.Timestamp.Source, r.Timestamp.Value)
+	}
+	return s
+}
+
+func formatContent(content []byte) string {
+	if utf8.Valid(content) {
+		return fmt.Sprintf("%q", content)
+	}
+	return fmt.Sprintf("%x", content)
+}
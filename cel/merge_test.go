@@ -0,0 +1,88 @@
+package cel
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-tpm-tools/internal/test"
+)
+
+func testCEL(t *testing.T) *CEL {
+	t.Helper()
+	tpm := test.GetTPM(t)
+	defer test.CheckedClose(t, tpm)
+
+	cel := &CEL{}
+	appendOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, CosTlv{ImageRefType, []byte("docker.io/library/hello-world:latest")})
+	appendOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, CosTlv{ImageDigestType, []byte("sha256:781d8dfdd92118436bd914442c8339e653b83f6bf3c1a7a98efcfb7c4fed7483")})
+	appendOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, CosTlv{LaunchSeparatorType, nil})
+	appendOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, CosTlv{KernelModuleType, []byte("sha256:00 nvidia.ko")})
+	return cel
+}
+
+func TestMerge(t *testing.T) {
+	cel := testCEL(t)
+	preLaunch := CEL{Records: cel.Records[:3]}
+	postLaunch := CEL{Records: cel.Records[2:]}
+
+	for _, fragments := range [][]CEL{
+		{preLaunch, postLaunch},
+		{postLaunch, preLaunch},
+		{*cel},
+		{preLaunch, *cel},
+	} {
+		merged, err := Merge(fragments...)
+		if err != nil {
+			t.Fatalf("Merge() failed: %v", err)
+		}
+		if !reflect.DeepEqual(merged.Records, cel.Records) {
+			t.Errorf("Merge() = %v, want the records of the whole log", merged.Records)
+		}
+	}
+
+	if _, err := Merge(CEL{Records: cel.Records[:1]}, CEL{Records: cel.Records[2:]}); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Merge() of fragments with a gap = %v, want a missing records error", err)
+	}
+	conflicting := CEL{Records: append([]Record{}, cel.Records[2:]...)}
+	conflicting.Records[0].PCR++
+	if _, err := Merge(preLaunch, conflicting); err == nil || !strings.Contains(err.Error(), "pcr") {
+		t.Errorf("Merge() of conflicting fragments = %v, want a pcr conflict error", err)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	cel := testCEL(t)
+	if diffs := Compare(*cel, *cel); len(diffs) != 0 {
+		t.Errorf("Compare() of the same log = %v, want no differences", diffs)
+	}
+	if diff := Diff(*cel, *cel); diff != "" {
+		t.Errorf("Diff() of the same log = %q, want none", diff)
+	}
+
+	other := CEL{Records: append([]Record{}, cel.Records[:3]...)}
+	other.Records[1].Content = cel.Records[0].Content
+	diffs := Compare(*cel, other)
+	if len(diffs) != 2 {
+		t.Fatalf("Compare() = %v, want 2 differences", diffs)
+	}
+	if d := diffs[0]; d.RecNum != 1 || !reflect.DeepEqual(d.Fields, []string{"content"}) {
+		t.Errorf("got difference %+v, want the content of record 1", d)
+	}
+	if d := diffs[1]; d.RecNum != 3 || d.A == nil || d.B != nil {
+		t.Errorf("got difference %+v, want record 3 only in a", d)
+	}
+
+	diff := Diff(*cel, other)
+	for _, want := range []string{
+		"record 1: different content:",
+		"  b: PCR",
+		`"docker.io/library/hello-world:latest"`,
+		`record 3: only in a: PCR`,
+		`"sha256:00 nvidia.ko"`,
+	} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("Diff() = %q, want it to contain %q", diff, want)
+		}
+	}
+}
@@ -1,14 +1,22 @@
 package client
 
 import (
+	"bytes"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 
+	"github.com/google/go-attestation/attest"
 	sabi "github.com/google/go-sev-guest/abi"
 	sg "github.com/google/go-sev-guest/client"
+	"github.com/google/go-tpm-tools/cel"
 	pb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/tpm2"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
@@ -38,7 +46,10 @@ type AttestOpts struct {
 	// information on why this is an issue, see this paper on robust remote
 	// attestation protocols:
 	// https://citeseerx.ist.psu.edu/viewdoc/download?doi=10.1.1.70.4562&rep=rep1&type=pdf
-	Nonce []byte
+	//
+	// Nonces larger than the digests of the TPM are quoted as their SHA-256
+	// digest, see Nonce.
+	Nonce Nonce
 	// TCG Canonical Event Log to add to the attestation.
 	// Currently, we only support PCR replay for PCRs orthogonal to those in the
 	// firmware event log, where PCRs 0-9 and 14 are often measured. If the two
@@ -58,6 +69,26 @@ type AttestOpts struct {
 	// depending on the technology's size. Leaving this nil is not recommended. If
 	// nil, then TEEDevice must be nil.
 	TEENonce []byte
+	// IncludeIMALog adds the IMA runtime measurement list to the attestation,
+	// so a verifier can replay it against PCR 10 and check the files executed
+	// after boot. As the list is read after the quotes, it can have more
+	// measurements than the quotes cover.
+	IncludeIMALog bool
+	// If non-nil, will be used to fetch the identity documents of the GCE
+	// instance: the instance identity token, whose audience is the hex SHA-256
+	// of the Nonce, from the metadata server, and the Shielded VM identity
+	// from the Compute Engine API, using the default service account.
+	GCEIdentityFetcher *http.Client
+	// OmitEventLog skips collecting the TCG event log, and marks the
+	// attestation with EventLogOmitted, for minimal attestations such as
+	// frequent heartbeats whose boot state was already verified. The quotes
+	// and the CanonicalEventLog, if any, are still collected. Verifiers must
+	// allow such attestations, see server.VerifyOpts.AllowOmittedEventLog.
+	OmitEventLog bool
+	// MaxEventLogSize, if non-zero, limits the size of the TCG event log read
+	// into the attestation. Attest fails if the log is larger, as a truncated
+	// log cannot be replayed, see ReadEventLog.
+	MaxEventLogSize int64
 }
 
 // Given a certificate, iterates through its IssuingCertificateURLs and returns
@@ -230,18 +261,33 @@ func (k *Key) Attest(opts AttestOpts) (*pb.Attestation, error) {
 	}
 	attestation.AkCert = k.CertDERBytes()
 	for _, sel := range sels {
-		quote, err := k.Quote(sel, opts.Nonce)
+		quote, err := k.QuoteNonce(sel, opts.Nonce)
 		if err != nil {
 			return nil, err
 		}
 		attestation.Quotes = append(attestation.Quotes, quote)
 	}
-	if attestation.EventLog, err = GetEventLog(k.rw); err != nil {
+	if opts.OmitEventLog {
+		attestation.EventLogOmitted = true
+	} else if opts.MaxEventLogSize > 0 {
+		var truncated bool
+		if attestation.EventLog, truncated, err = ReadEventLog(k.rw, opts.MaxEventLogSize); err != nil {
+			return nil, fmt.Errorf("failed to retrieve TCG Event Log: %w", err)
+		}
+		if truncated {
+			return nil, fmt.Errorf("TCG Event Log is larger than MaxEventLogSize (%d bytes)", opts.MaxEventLogSize)
+		}
+	} else if attestation.EventLog, err = GetEventLog(k.rw); err != nil {
 		return nil, fmt.Errorf("failed to retrieve TCG Event Log: %w", err)
 	}
 	if len(opts.CanonicalEventLog) != 0 {
 		attestation.CanonicalEventLog = opts.CanonicalEventLog
 	}
+	if opts.IncludeIMALog {
+		if attestation.ImaLog, err = GetIMALog(k.rw); err != nil {
+			return nil, fmt.Errorf("failed to retrieve IMA log: %w", err)
+		}
+	}
 
 	// Attempt to construct certificate chain. fetchIssuingCertificate checks if
 	// AK cert is present and contains intermediate cert URLs.
@@ -252,9 +298,118 @@ func (k *Key) Attest(opts AttestOpts) (*pb.Attestation, error) {
 		}
 	}
 
+	if opts.GCEIdentityFetcher != nil {
+		if attestation.GceIdentity, err = getGCEIdentity(opts.GCEIdentityFetcher, opts.Nonce); err != nil {
+			return nil, fmt.Errorf("fetching GCE identity: %w", err)
+		}
+	}
+
 	if err := getTEEAttestationReport(&attestation, opts); err != nil {
 		return nil, fmt.Errorf("collecting TEE attestation report: %w", err)
 	}
 
 	return &attestation, nil
 }
+
+// AttestAndReplay is like Attest, but before returning it replays the
+// collected event logs against the quoted PCRs, and records the result in the
+// SelfCheck of the Attestation. This catches truncated or otherwise
+// inconsistent event logs when they are collected, rather than when they are
+// rejected by the verifier. A failed replay is not an error.
+func (k *Key) AttestAndReplay(opts AttestOpts) (*pb.Attestation, error) {
+	attestation, err := k.Attest(opts)
+	if err != nil {
+		return nil, err
+	}
+	attestation.SelfCheck = selfCheck(attestation)
+	return attestation, nil
+}
+
+// selfCheck replays the event logs of the attestation against the PCRs of
+// each quote in turn. Like a verifier, it succeeds if the logs replay against
+// any of the quotes. Otherwise it reports the quote with the fewest mismatched
+// PCRs, as the other quotes are usually of banks missing from the event log.
+func selfCheck(attestation *pb.Attestation) *pb.AttestationSelfCheck {
+	var best *pb.AttestationSelfCheck
+	for _, quote := range attestation.GetQuotes() {
+		err := replayEventLog(attestation.GetEventLog(), quote.GetPcrs())
+		if err == nil {
+			err = replayCanonicalEventLog(attestation.GetCanonicalEventLog(), quote.GetPcrs())
+		}
+		if err == nil {
+			return &pb.AttestationSelfCheck{Replayed: true}
+		}
+		check := &pb.AttestationSelfCheck{Error: err.Error()}
+		var replayErr attest.ReplayError
+		if errors.As(err, &replayErr) {
+			for _, pcr := range replayErr.InvalidPCRs {
+				check.MismatchedPcrs = append(check.MismatchedPcrs, uint32(pcr))
+			}
+			sort.Slice(check.MismatchedPcrs, func(i, j int) bool { return check.MismatchedPcrs[i] < check.MismatchedPcrs[j] })
+		}
+		if best == nil || (len(check.MismatchedPcrs) != 0 &&
+			(len(best.MismatchedPcrs) == 0 || len(check.MismatchedPcrs) < len(best.MismatchedPcrs))) {
+			best = check
+		}
+	}
+	if best == nil {
+		return &pb.AttestationSelfCheck{Error: "attestation has no quotes"}
+	}
+	return best
+}
+
+func replayEventLog(rawEventLog []byte, pcrs *tpmpb.PCRs) error {
+	if len(rawEventLog) == 0 {
+		return nil
+	}
+	hash, err := tpm2.Algorithm(pcrs.GetHash()).Hash()
+	if err != nil {
+		return err
+	}
+	var attestPCRs []attest.PCR
+	for index, digest := range pcrs.GetPcrs() {
+		attestPCRs = append(attestPCRs, attest.PCR{Index: int(index), Digest: digest, DigestAlg: hash})
+	}
+	eventLog, err := attest.ParseEventLog(rawEventLog)
+	if err != nil {
+		return fmt.Errorf("failed to parse event log: %w", err)
+	}
+	if _, err := eventLog.Verify(attestPCRs); err != nil {
+		return fmt.Errorf("failed to replay event log: %w", err)
+	}
+	return nil
+}
+
+func replayCanonicalEventLog(rawCEL []byte, pcrs *tpmpb.PCRs) error {
+	if len(rawCEL) == 0 {
+		return nil
+	}
+	decodedCEL, err := cel.DecodeToCEL(bytes.NewBuffer(rawCEL))
+	if err != nil {
+		return fmt.Errorf("failed to decode canonical event log: %w", err)
+	}
+	if err := decodedCEL.Replay(pcrs); err != nil {
+		return fmt.Errorf("failed to replay canonical event log: %w", err)
+	}
+	return nil
+}
+
+// NewAttestationEnvelope wraps an Attestation in a versioned
+// AttestationEnvelope. A TEE attestation report in the Attestation is moved to
+// the envelope's TEE evidence, and marked critical so verifiers which cannot
+// check it reject the envelope. The provided Attestation is not modified.
+func NewAttestationEnvelope(attestation *pb.Attestation) *pb.AttestationEnvelope {
+	attestation = proto.Clone(attestation).(*pb.Attestation)
+	envelope := &pb.AttestationEnvelope{
+		Version:     pb.EnvelopeVersion,
+		Attestation: attestation,
+	}
+	if report := attestation.GetSevSnpAttestation(); report != nil {
+		envelope.TeeEvidence = append(envelope.TeeEvidence, &pb.TeeEvidence{
+			Critical: true,
+			Evidence: &pb.TeeEvidence_SevSnpAttestation{SevSnpAttestation: report},
+		})
+		attestation.TeeAttestation = nil
+	}
+	return envelope
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"io"
 	"math/big"
 	"net/http"
 	"net/http/httptest"
@@ -16,6 +17,8 @@ import (
 	testclient "github.com/google/go-sev-guest/testing/client"
 	"github.com/google/go-tpm-tools/internal/test"
 	pb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
 )
 
 var localClient = http.DefaultClient
@@ -291,3 +294,166 @@ func TestSevSnpDevice(t *testing.T) {
 		})
 	}
 }
+
+func TestAttestAndReplay(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer CheckedClose(t, rwc)
+
+	ak, err := AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("Failed to generate test AK: %v", err)
+	}
+	defer ak.Close()
+
+	attestation, err := ak.AttestAndReplay(AttestOpts{Nonce: []byte("some nonce")})
+	if err != nil {
+		t.Fatalf("AttestAndReplay() failed: %v", err)
+	}
+	if check := attestation.GetSelfCheck(); !check.GetReplayed() {
+		t.Errorf("event log did not replay: %v", check.GetError())
+	}
+
+	// An event missing from the event log is caught by the self check.
+	if err := tpm2.PCREvent(rwc, tpmutil.Handle(0), []byte("unlogged event")); err != nil {
+		t.Fatal(err)
+	}
+	attestation, err = ak.AttestAndReplay(AttestOpts{Nonce: []byte("some nonce")})
+	if err != nil {
+		t.Fatalf("AttestAndReplay() failed: %v", err)
+	}
+	check := attestation.GetSelfCheck()
+	if check.GetReplayed() {
+		t.Fatal("event log replayed with an unlogged event")
+	}
+	if len(check.GetMismatchedPcrs()) != 1 || check.GetMismatchedPcrs()[0] != 0 {
+		t.Errorf("got mismatched PCRs %v, want [0]", check.GetMismatchedPcrs())
+	}
+}
+
+// imaLogTPM adds an IMA log to a test TPM.
+type imaLogTPM struct {
+	io.ReadWriteCloser
+	imaLog []byte
+}
+
+func (t imaLogTPM) EventLog() ([]byte, error) { return GetEventLog(t.ReadWriteCloser) }
+func (t imaLogTPM) IMALog() ([]byte, error)   { return t.imaLog, nil }
+
+func TestKeyAttestIMALog(t *testing.T) {
+	imaLog := []byte("10 0000000000000000000000000000000000000000 ima-ng sha256:00 boot_aggregate\n")
+	rwc := imaLogTPM{test.GetTPM(t), imaLog}
+	defer CheckedClose(t, rwc)
+
+	ak, err := AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("Failed to generate test AK: %v", err)
+	}
+	defer ak.Close()
+
+	attestation, err := ak.Attest(AttestOpts{Nonce: []byte("some nonce")})
+	if err != nil {
+		t.Fatalf("Attest() failed: %v", err)
+	}
+	if len(attestation.GetImaLog()) != 0 {
+		t.Errorf("Attest() included an IMA log without IncludeIMALog")
+	}
+	attestation, err = ak.Attest(AttestOpts{Nonce: []byte("some nonce"), IncludeIMALog: true})
+	if err != nil {
+		t.Fatalf("Attest() failed: %v", err)
+	}
+	if !bytes.Equal(attestation.GetImaLog(), imaLog) {
+		t.Errorf("got IMA log %q, want %q", attestation.GetImaLog(), imaLog)
+	}
+}
+
+func TestKeyAttestOmitEventLog(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer CheckedClose(t, rwc)
+
+	ak, err := AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("Failed to generate test AK: %v", err)
+	}
+	defer ak.Close()
+
+	cel := []byte("canonical event log")
+	attestation, err := ak.Attest(AttestOpts{Nonce: []byte("some nonce"), CanonicalEventLog: cel, OmitEventLog: true})
+	if err != nil {
+		t.Fatalf("Attest() failed: %v", err)
+	}
+	if len(attestation.GetEventLog()) != 0 || !attestation.GetEventLogOmitted() {
+		t.Errorf("Attest() with OmitEventLog got an event log of %d bytes, omitted %v", len(attestation.GetEventLog()), attestation.GetEventLogOmitted())
+	}
+	if !bytes.Equal(attestation.GetCanonicalEventLog(), cel) {
+		t.Errorf("Attest() with OmitEventLog did not keep the canonical event log")
+	}
+	if len(attestation.GetQuotes()) == 0 {
+		t.Errorf("Attest() with OmitEventLog has no quotes")
+	}
+}
+
+func TestKeyAttestMaxEventLogSize(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer CheckedClose(t, rwc)
+
+	ak, err := AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("Failed to generate test AK: %v", err)
+	}
+	defer ak.Close()
+
+	eventLog, err := GetEventLog(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attestation, err := ak.Attest(AttestOpts{Nonce: []byte("some nonce"), MaxEventLogSize: int64(len(eventLog))})
+	if err != nil {
+		t.Fatalf("Attest() failed: %v", err)
+	}
+	if !bytes.Equal(attestation.GetEventLog(), eventLog) {
+		t.Errorf("Attest() with MaxEventLogSize got an event log of %d bytes, want %d", len(attestation.GetEventLog()), len(eventLog))
+	}
+	if _, err := ak.Attest(AttestOpts{Nonce: []byte("some nonce"), MaxEventLogSize: int64(len(eventLog) - 1)}); err == nil {
+		t.Error("Attest() succeeded with an event log larger than MaxEventLogSize")
+	}
+}
+
+func TestReadEventLog(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer CheckedClose(t, rwc)
+
+	eventLog, err := GetEventLog(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenEventLog(rwc)
+	if err != nil {
+		t.Fatalf("OpenEventLog() failed: %v", err)
+	}
+	defer r.Close()
+	// Read the log in small chunks.
+	var streamed []byte
+	chunk := make([]byte, 100)
+	for {
+		n, err := r.Read(chunk)
+		streamed = append(streamed, chunk[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !bytes.Equal(streamed, eventLog) {
+		t.Errorf("OpenEventLog() streamed %d bytes, want the %d bytes of GetEventLog()", len(streamed), len(eventLog))
+	}
+
+	log, truncated, err := ReadEventLog(rwc, int64(len(eventLog)))
+	if err != nil || truncated || !bytes.Equal(log, eventLog) {
+		t.Errorf("ReadEventLog() of the log size got %d bytes, truncated %v, error %v", len(log), truncated, err)
+	}
+	log, truncated, err = ReadEventLog(rwc, 100)
+	if err != nil || !truncated || !bytes.Equal(log, eventLog[:100]) {
+		t.Errorf("ReadEventLog() of 100 bytes got %d bytes, truncated %v, error %v", len(log), truncated, err)
+	}
+}
@@ -0,0 +1,368 @@
+package client
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/go-tpm-tools/internal"
+	pb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+// AuthorityHashAlg is the hash algorithm of the signatures of an external
+// authority authorizing the use of sealed objects, see SealOpts.Authority.
+const AuthorityHashAlg = crypto.SHA256
+
+// AuthorityPublic returns the public area of the key of an external authority,
+// loaded in the TPM to check its signed authorizations with TPM2_PolicySigned.
+// RSA keys use the RSASSA scheme, and ECDSA keys (on the P-256 or P-384
+// curves) the ECDSA scheme, both with AuthorityHashAlg.
+func AuthorityPublic(pub crypto.PublicKey) (tpm2.Public, error) {
+	hashAlg, err := tpm2.HashToAlgorithm(AuthorityHashAlg)
+	if err != nil {
+		return tpm2.Public{}, err
+	}
+	public := tpm2.Public{
+		NameAlg:    SessionHashAlgTpm,
+		Attributes: tpm2.FlagSign | tpm2.FlagUserWithAuth,
+	}
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		public.Type = tpm2.AlgRSA
+		public.RSAParameters = &tpm2.RSAParams{
+			Sign:        &tpm2.SigScheme{Alg: tpm2.AlgRSASSA, Hash: hashAlg},
+			KeyBits:     uint16(key.N.BitLen()),
+			ExponentRaw: uint32(key.E),
+			ModulusRaw:  key.N.Bytes(),
+		}
+	case *ecdsa.PublicKey:
+		var curve tpm2.EllipticCurve
+		switch key.Curve {
+		case elliptic.P256():
+			curve = tpm2.CurveNISTP256
+		case elliptic.P384():
+			curve = tpm2.CurveNISTP384
+		default:
+			return tpm2.Public{}, fmt.Errorf("unsupported authority curve %s", key.Curve.Params().Name)
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		public.Type = tpm2.AlgECC
+		public.ECCParameters = &tpm2.ECCParams{
+			Sign:    &tpm2.SigScheme{Alg: tpm2.AlgECDSA, Hash: hashAlg},
+			CurveID: curve,
+			Point: tpm2.ECPoint{
+				XRaw: key.X.FillBytes(make([]byte, size)),
+				YRaw: key.Y.FillBytes(make([]byte, size)),
+			},
+		}
+	default:
+		return tpm2.Public{}, fmt.Errorf("unsupported authority key type %T", pub)
+	}
+	return public, nil
+}
+
+// Commands and structure tags not defined by go-tpm.
+const (
+	cmdPolicyAuthorize tpmutil.Command = 0x0000016A
+	cmdVerifySignature tpmutil.Command = 0x00000177
+	tagVerified        tpmutil.Tag     = 0x8022
+)
+
+// AuthorityPolicy returns the authPolicy of a key requiring a signed
+// authorization of the authority for each use, with TPM2_PolicySigned for
+// policyRef. See NewAuthorizedKey.
+func AuthorityPolicy(authority crypto.PublicKey, policyRef []byte) ([]byte, error) {
+	public, err := AuthorityPublic(authority)
+	if err != nil {
+		return nil, err
+	}
+	return policySignedDigest(make([]byte, SessionHashAlg.Size()), public, policyRef)
+}
+
+// policySignedDigest extends the policy digest with TPM2_PolicySigned for the
+// authority and policyRef.
+func policySignedDigest(digest []byte, authority tpm2.Public, policyRef []byte) ([]byte, error) {
+	return policyUpdate(digest, tpm2.CmdPolicySigned, authority, policyRef)
+}
+
+// policyAuthorizeDigest returns the policy digest of TPM2_PolicyAuthorize for
+// the authority and policyRef, which replaces the previous digest.
+func policyAuthorizeDigest(authority tpm2.Public, policyRef []byte) ([]byte, error) {
+	return policyUpdate(make([]byte, SessionHashAlg.Size()), cmdPolicyAuthorize, authority, policyRef)
+}
+
+// approvedPolicyDigest returns the digest signed by the authority approving
+// the PCR policy of pcrs for policyRef.
+func approvedPolicyDigest(pcrs *pb.PCRs, policyRef []byte) []byte {
+	hash := AuthorityHashAlg.New()
+	hash.Write(internal.PCRSessionAuth(pcrs, SessionHashAlg))
+	hash.Write(policyRef)
+	return hash.Sum(nil)
+}
+
+// policyUpdate extends the policy digest with the command for the authority
+// and policyRef, see PolicyUpdate in Part 3 of the spec.
+func policyUpdate(digest []byte, command tpmutil.Command, authority tpm2.Public, policyRef []byte) ([]byte, error) {
+	name, err := authority.Name()
+	if err != nil {
+		return nil, err
+	}
+	encodedName, err := name.Digest.Encode()
+	if err != nil {
+		return nil, err
+	}
+	cc, err := tpmutil.Pack(command)
+	if err != nil {
+		return nil, err
+	}
+	hash := SessionHashAlg.New()
+	hash.Write(digest)
+	hash.Write(cc)
+	hash.Write(encodedName)
+	digest = hash.Sum(nil)
+
+	hash.Reset()
+	hash.Write(digest)
+	hash.Write(policyRef)
+	return hash.Sum(nil), nil
+}
+
+// startPolicySession starts a session like startAuthSession, but returns its
+// nonceTPM, which signed authorizations are bound to.
+func startPolicySession(rw io.ReadWriter) (tpmutil.Handle, []byte, error) {
+	session, nonceTPM, err := tpm2.StartAuthSession(
+		rw,
+		/*tpmKey=*/ tpm2.HandleNull,
+		/*bindKey=*/ tpm2.HandleNull,
+		/*nonceCaller=*/ make([]byte, SessionHashAlg.Size()),
+		/*encryptedSalt=*/ nil,
+		/*sessionType=*/ tpm2.SessionPolicy,
+		/*symmetric=*/ tpm2.AlgNull,
+		/*authHash=*/ SessionHashAlgTpm)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return session, nonceTPM, nil
+}
+
+// policySigned satisfies TPM2_PolicySigned in the session with the
+// authorization of the authority for nonceTPM and policyRef.
+func policySigned(rw io.ReadWriter, session tpmutil.Handle, nonceTPM, authorityPub, policyRef []byte, authorize func(nonceTPM, policyRef []byte) (*pb.PolicyAuthorization, error)) error {
+	authority, err := tpm2.DecodePublic(authorityPub)
+	if err != nil {
+		return fmt.Errorf("failed to decode the authority public area: %w", err)
+	}
+	authorityHandle, _, err := tpm2.LoadExternal(rw, authority, tpm2.Private{Type: tpm2.AlgNull}, tpm2.HandleNull)
+	if err != nil {
+		return fmt.Errorf("failed to load the authority key: %w", err)
+	}
+	defer tpm2.FlushContext(rw, authorityHandle)
+
+	authz, err := authorize(nonceTPM, policyRef)
+	if err != nil {
+		return fmt.Errorf("failed to get the authorization of the authority: %w", err)
+	}
+	if _, _, err := tpm2.PolicySigned(rw, authorityHandle, session, nonceTPM, nil, policyRef, authz.GetExpiration(), authz.GetSignature()); err != nil {
+		return fmt.Errorf("the authority did not authorize the use of the object: %w", err)
+	}
+	return nil
+}
+
+// policyAuthorize satisfies the PCR policy of the approval, then
+// TPM2_PolicyAuthorize in the session with the approval of the authority.
+func policyAuthorize(rw io.ReadWriter, session tpmutil.Handle, authorityPub, policyRef []byte, approval *pb.PolicyApproval) error {
+	authority, err := tpm2.DecodePublic(authorityPub)
+	if err != nil {
+		return fmt.Errorf("failed to decode the policy authority public area: %w", err)
+	}
+	name, err := authority.Name()
+	if err != nil {
+		return err
+	}
+	encodedName, err := name.Digest.Encode()
+	if err != nil {
+		return err
+	}
+	// Tickets of the Null hierarchy are not accepted by TPM2_PolicyAuthorize.
+	authorityHandle, _, err := tpm2.LoadExternal(rw, authority, tpm2.Private{Type: tpm2.AlgNull}, tpm2.HandleOwner)
+	if err != nil {
+		return fmt.Errorf("failed to load the policy authority key: %w", err)
+	}
+	defer tpm2.FlushContext(rw, authorityHandle)
+
+	resp, err := runCommand(rw, cmdVerifySignature,
+		authorityHandle,
+		tpmutil.U16Bytes(approvedPolicyDigest(approval.GetPcrs(), policyRef)),
+		tpmutil.RawBytes(approval.GetSignature()))
+	if err != nil {
+		return fmt.Errorf("the policy authority did not approve the PCR policy: %w", err)
+	}
+	var ticket tpm2.Ticket
+	if _, err := tpmutil.Unpack(resp, &ticket.Type, &ticket.Hierarchy, &ticket.Digest); err != nil {
+		return fmt.Errorf("failed to decode the verification ticket: %w", err)
+	}
+	if ticket.Type != tagVerified {
+		return fmt.Errorf("got a ticket of type %#x, want a verification ticket", ticket.Type)
+	}
+
+	if err := tpm2.PolicyPCR(rw, session, nil, internal.PCRSelection(approval.GetPcrs())); err != nil {
+		return err
+	}
+	approvedPolicy := internal.PCRSessionAuth(approval.GetPcrs(), SessionHashAlg)
+	if _, err := runCommand(rw, cmdPolicyAuthorize,
+		session,
+		tpmutil.U16Bytes(approvedPolicy),
+		tpmutil.U16Bytes(policyRef),
+		tpmutil.U16Bytes(encodedName),
+		ticket.Type, ticket.Hierarchy, ticket.Digest); err != nil {
+		return fmt.Errorf("failed to authorize the approved PCR policy: %w", err)
+	}
+	return nil
+}
+
+// runCommand runs a command without sessions which go-tpm does not support.
+func runCommand(rw io.ReadWriter, command tpmutil.Command, in ...interface{}) ([]byte, error) {
+	resp, code, err := tpmutil.RunCommand(rw, tpm2.TagNoSessions, command, in...)
+	if err != nil {
+		return nil, err
+	}
+	if code != tpmutil.RCSuccess {
+		return nil, fmt.Errorf("command %#x failed with response code %#x", uint32(command), uint32(code))
+	}
+	return resp, nil
+}
+
+// selectApproval returns the first of the approvals for policyRef whose PCR
+// values are the current ones.
+func selectApproval(rw io.ReadWriter, approvals []*pb.PolicyApproval, policyRef []byte) (*pb.PolicyApproval, error) {
+	for _, approval := range approvals {
+		if !bytes.Equal(approval.GetPolicyRef(), policyRef) || len(approval.GetPcrs().GetPcrs()) == 0 {
+			continue
+		}
+		current, err := ReadPCRs(rw, internal.PCRSelection(approval.GetPcrs()))
+		if err != nil {
+			return nil, err
+		}
+		if internal.CheckSubset(approval.GetPcrs(), current) == nil {
+			return approval, nil
+		}
+	}
+	return nil, errors.New("none of UnsealOpts.PolicyApprovals approves the current PCR values")
+}
+
+// unsealAuthorized unseals an object whose policy requires the approval or
+// the signed authorization of an external authority. It uses its own policy
+// session, as the authorization is bound to the nonce of the session.
+func unsealAuthorized(rw io.ReadWriter, in *pb.SealedBytes, sealed tpmutil.Handle, sel tpm2.PCRSelection, opts UnsealOpts) ([]byte, error) {
+	var approval *pb.PolicyApproval
+	if len(in.GetPolicyAuthorityPub()) > 0 {
+		var err error
+		if approval, err = selectApproval(rw, opts.PolicyApprovals, in.GetPolicyAuthorityRef()); err != nil {
+			return nil, err
+		}
+	}
+	if len(in.GetAuthorityPub()) > 0 && opts.Authorize == nil {
+		return nil, errors.New("the sealed object requires a signed authorization of its authority, but UnsealOpts.Authorize is not set")
+	}
+
+	session, nonceTPM, err := startPolicySession(rw)
+	if err != nil {
+		return nil, err
+	}
+	defer tpm2.FlushContext(rw, session)
+
+	if approval != nil {
+		if err := policyAuthorize(rw, session, in.GetPolicyAuthorityPub(), in.GetPolicyAuthorityRef(), approval); err != nil {
+			return nil, err
+		}
+	} else if len(sel.PCRs) > 0 {
+		if err := tpm2.PolicyPCR(rw, session, nil, sel); err != nil {
+			return nil, err
+		}
+	}
+	if len(in.GetAuthorityPub()) > 0 {
+		if err := policySigned(rw, session, nonceTPM, in.GetAuthorityPub(), in.GetAuthorityPolicyRef(), opts.Authorize); err != nil {
+			return nil, err
+		}
+	}
+	return tpm2.UnsealWithSession(rw, session, sealed, "")
+}
+
+// authoritySession is the session of a key created by NewAuthorizedKey. Each
+// use of the key needs a new authorization of the authority, for the nonce of
+// a new policy session, so the session of the previous use is flushed.
+type authoritySession struct {
+	rw           io.ReadWriter
+	authorityPub []byte
+	policyRef    []byte
+	authorize    func(nonceTPM, policyRef []byte) (*pb.PolicyAuthorization, error)
+	session      tpmutil.Handle
+	hasSession   bool
+}
+
+func (s *authoritySession) Auth() (tpm2.AuthCommand, error) {
+	if err := s.Close(); err != nil {
+		return tpm2.AuthCommand{}, err
+	}
+	session, nonceTPM, err := startPolicySession(s.rw)
+	if err != nil {
+		return tpm2.AuthCommand{}, err
+	}
+	s.session, s.hasSession = session, true
+	if err := policySigned(s.rw, session, nonceTPM, s.authorityPub, s.policyRef, s.authorize); err != nil {
+		return tpm2.AuthCommand{}, err
+	}
+	return tpm2.AuthCommand{Session: session, Attributes: tpm2.AttrContinueSession}, nil
+}
+
+func (s *authoritySession) Close() error {
+	if !s.hasSession {
+		return nil
+	}
+	s.hasSession = false
+	return tpm2.FlushContext(s.rw, s.session)
+}
+
+// NewAuthorizedKey is like NewKey, for a template whose AuthPolicy is
+// AuthorityPolicy(authority, policyRef), and without tpm2.FlagUserWithAuth.
+// Each use of the key requires a signed authorization of the authority, which
+// authorize gets for the nonce of the session of the use. The authority can
+// revoke the use of the key by refusing to sign. See
+// server.SignPolicyAuthorization.
+func NewAuthorizedKey(rw io.ReadWriter, parent tpmutil.Handle, template tpm2.Public, authority crypto.PublicKey, policyRef []byte, authorize func(nonceTPM, policyRef []byte) (*pb.PolicyAuthorization, error)) (*Key, error) {
+	if authorize == nil {
+		return nil, errors.New("no authorize function")
+	}
+	public, err := AuthorityPublic(authority)
+	if err != nil {
+		return nil, err
+	}
+	authorityPub, err := public.Encode()
+	if err != nil {
+		return nil, err
+	}
+	policy, err := policySignedDigest(make([]byte, SessionHashAlg.Size()), public, policyRef)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(template.AuthPolicy, policy) {
+		return nil, errors.New("the template AuthPolicy is not the AuthorityPolicy of the authority")
+	}
+	// The empty auth value would otherwise authorize the use of the key.
+	if template.Attributes&tpm2.FlagUserWithAuth != 0 {
+		return nil, errors.New("the template must not have FlagUserWithAuth")
+	}
+	return newKey(rw, parent, template, &authoritySession{
+		rw:           rw,
+		authorityPub: authorityPub,
+		policyRef:    policyRef,
+		authorize:    authorize,
+	})
+}
@@ -0,0 +1,29 @@
+package client
+
+import (
+	"fmt"
+
+	pb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/tpm2"
+)
+
+// ActivateCredential recovers the secret of a credential protected by this
+// key (the EK), for the provided key (usually the AK). This succeeds only if
+// both keys are resident on the same TPM, and the credential was made for the
+// name of ak. The cred parameter should come from server.MakeCredential.
+func (k *Key) ActivateCredential(ak *Key, cred *pb.EncryptedCredential) ([]byte, error) {
+	akAuth, err := ak.session.Auth()
+	if err != nil {
+		return nil, err
+	}
+	ekAuth, err := k.session.Auth()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := tpm2.ActivateCredentialUsingAuth(k.rw, []tpm2.AuthCommand{akAuth, ekAuth},
+		ak.Handle(), k.Handle(), cred.GetCredentialBlob(), cred.GetEncryptedSecret())
+	if err != nil {
+		return nil, checkLockout(fmt.Errorf("activate credential failed: %w", err))
+	}
+	return secret, nil
+}
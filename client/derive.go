@@ -0,0 +1,112 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/go-tpm/tpm2"
+)
+
+// derivationDomain separates the unique values of derived keys from those of
+// other primary keys of the hierarchy.
+const derivationDomain = "go-tpm-tools derived key v1"
+
+// DerivedKeyTemplate returns a copy of template with its unique field set from
+// the labels, so each path of labels gives a different primary key. Labels
+// form a hierarchy: ("app", "tls") and ("app", "signing") are both keys of the
+// "app" namespace, and are different from ("app/tls").
+//
+// The template chooses the purpose of the key, e.g. AKTemplateECC for signing
+// or SRKTemplateECC for sealing. Its unique field must be empty or all zeros.
+func DerivedKeyTemplate(template tpm2.Public, labels ...string) (tpm2.Public, error) {
+	if len(labels) == 0 {
+		return tpm2.Public{}, errors.New("no label to derive the key from")
+	}
+	unique := deriveUnique(labels)
+
+	// Copy the parameters, as the template may share them with the caller.
+	switch template.Type {
+	case tpm2.AlgRSA:
+		if template.RSAParameters == nil {
+			return tpm2.Public{}, errors.New("RSA template has no RSAParameters")
+		}
+		params := *template.RSAParameters
+		if !isZero(params.ModulusRaw) {
+			return tpm2.Public{}, errors.New("template already has a unique value")
+		}
+		params.ModulusRaw = unique
+		template.RSAParameters = &params
+	case tpm2.AlgECC:
+		if template.ECCParameters == nil {
+			return tpm2.Public{}, errors.New("ECC template has no ECCParameters")
+		}
+		params := *template.ECCParameters
+		if !isZero(params.Point.XRaw) || !isZero(params.Point.YRaw) {
+			return tpm2.Public{}, errors.New("template already has a unique value")
+		}
+		params.Point = tpm2.ECPoint{XRaw: unique}
+		template.ECCParameters = &params
+	case tpm2.AlgKeyedHash:
+		if template.KeyedHashParameters == nil {
+			return tpm2.Public{}, errors.New("keyed hash template has no KeyedHashParameters")
+		}
+		params := *template.KeyedHashParameters
+		if !isZero(params.Unique) {
+			return tpm2.Public{}, errors.New("template already has a unique value")
+		}
+		params.Unique = unique
+		template.KeyedHashParameters = &params
+	case tpm2.AlgSymCipher:
+		if template.SymCipherParameters == nil {
+			return tpm2.Public{}, errors.New("symmetric template has no SymCipherParameters")
+		}
+		params := *template.SymCipherParameters
+		if !isZero(params.Unique) {
+			return tpm2.Public{}, errors.New("template already has a unique value")
+		}
+		params.Unique = unique
+		template.SymCipherParameters = &params
+	default:
+		return tpm2.Public{}, fmt.Errorf("unsupported template type: %v", template.Type)
+	}
+	return template, nil
+}
+
+// DeriveKey creates and loads the primary key of DerivedKeyTemplate in the
+// Owner hierarchy, like the SRK. As primary keys are derived from the seed of
+// the hierarchy, the same template and labels give the same key after a
+// reboot, without persisting it. The key changes if the TPM is cleared.
+func DeriveKey(rw io.ReadWriter, template tpm2.Public, labels ...string) (*Key, error) {
+	derived, err := DerivedKeyTemplate(template, labels...)
+	if err != nil {
+		return nil, err
+	}
+	return NewKey(rw, tpm2.HandleOwner, derived)
+}
+
+// deriveUnique chains a SHA-256 digest over the length-prefixed labels.
+func deriveUnique(labels []string) []byte {
+	unique := sha256.Sum256([]byte(derivationDomain))
+	for _, label := range labels {
+		h := sha256.New()
+		h.Write(unique[:])
+		var size [4]byte
+		binary.BigEndian.PutUint32(size[:], uint32(len(label)))
+		h.Write(size[:])
+		h.Write([]byte(label))
+		copy(unique[:], h.Sum(nil))
+	}
+	return unique[:]
+}
+
+func isZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package client_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm/tpm2"
+)
+
+func TestDeriveKey(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	for _, template := range []struct {
+		name     string
+		template func() tpm2.Public
+	}{
+		{"AKTemplateRSA", client.AKTemplateRSA},
+		{"AKTemplateECC", client.AKTemplateECC},
+		{"SRKTemplateECC", client.SRKTemplateECC},
+	} {
+		t.Run(template.name, func(t *testing.T) {
+			derive := func(labels ...string) []byte {
+				t.Helper()
+				key, err := client.DeriveKey(rwc, template.template(), labels...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer key.Close()
+				return key.Name().Digest.Value
+			}
+
+			name := derive("app", "tls")
+			if again := derive("app", "tls"); !bytes.Equal(again, name) {
+				t.Error("deriving the same labels twice gave different keys")
+			}
+			for _, labels := range [][]string{{"app", "signing"}, {"app"}, {"app/tls"}, {"apptls"}} {
+				if other := derive(labels...); bytes.Equal(other, name) {
+					t.Errorf("labels %q gave the same key as [app tls]", labels)
+				}
+			}
+		})
+	}
+}
+
+func TestDeriveKeySign(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	key, err := client.DeriveKey(rwc, client.AKTemplateECC(), "app", "signing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer key.Close()
+	data := []byte("data")
+	sig, err := key.SignData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256(data)
+	if !ecdsa.VerifyASN1(key.PublicKey().(*ecdsa.PublicKey), digest[:], sig) {
+		t.Error("signature of the derived key does not verify")
+	}
+}
+
+func TestDerivedKeyTemplateErrors(t *testing.T) {
+	if _, err := client.DerivedKeyTemplate(client.AKTemplateECC()); err == nil {
+		t.Error("DerivedKeyTemplate() succeeded without labels")
+	}
+	template, err := client.DerivedKeyTemplate(client.AKTemplateECC(), "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.DerivedKeyTemplate(template, "app"); err == nil {
+		t.Error("DerivedKeyTemplate() succeeded with a template with a unique value")
+	}
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// certPropID is the CERT_CERT_PROP_ID property of a serialized certificate,
+// containing the DER encoded certificate.
+const certPropID = 32
+
+// parseCertStoreBlob returns the DER certificate in a serialized certificate
+// from a Windows certificate store. The blob is a list of properties, each
+// with a 4 byte ID, 4 reserved bytes and a 4 byte length (all little-endian)
+// followed by the property value.
+func parseCertStoreBlob(blob []byte) ([]byte, error) {
+	for len(blob) > 0 {
+		if len(blob) < 12 {
+			return nil, errors.New("truncated certificate property header")
+		}
+		id := binary.LittleEndian.Uint32(blob[0:])
+		length := binary.LittleEndian.Uint32(blob[8:])
+		blob = blob[12:]
+		if uint64(length) > uint64(len(blob)) {
+			return nil, fmt.Errorf("certificate property %d of %d bytes is truncated", id, length)
+		}
+		if id == certPropID {
+			return blob[:length], nil
+		}
+		blob = blob[length:]
+	}
+	return nil, errors.New("no certificate in certificate store blob")
+}
+
+// trySetCertificateFromPlatform sets the certificate of the EK from the ones
+// stored by the OS, if one of them is for the EK. This is needed on Windows,
+// where the EK certificates of firmware TPMs are not stored in NV memory, but
+// downloaded by the OS.
+func (k *Key) trySetCertificateFromPlatform() {
+	certs, err := getPlatformEKCerts()
+	if err != nil {
+		return
+	}
+	for _, der := range certs {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			continue
+		}
+		// SetCert fails if the certificate is for another key.
+		if k.SetCert(cert) == nil {
+			return
+		}
+	}
+}
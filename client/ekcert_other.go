@@ -0,0 +1,9 @@
+//go:build !windows
+// +build !windows
+
+package client
+
+// Only Windows stores EK certificates outside of the TPM.
+func getPlatformEKCerts() ([][]byte, error) {
+	return nil, nil
+}
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func certStoreProperty(id uint32, value []byte) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, []uint32{id, 1, uint32(len(value))})
+	buf.Write(value)
+	return buf.Bytes()
+}
+
+func TestParseCertStoreBlob(t *testing.T) {
+	der := []byte{0x30, 0x03, 0x02, 0x01, 0x01}
+	sha1Hash := certStoreProperty(3, make([]byte, 20))
+	cert := certStoreProperty(certPropID, der)
+
+	got, err := parseCertStoreBlob(append(sha1Hash, cert...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, der) {
+		t.Errorf("parseCertStoreBlob() = %x, want %x", got, der)
+	}
+
+	for _, blob := range [][]byte{
+		nil,
+		sha1Hash,
+		sha1Hash[:8],
+		cert[:len(cert)-1],
+	} {
+		if _, err := parseCertStoreBlob(blob); err == nil {
+			t.Errorf("parseCertStoreBlob(%x) succeeded, want error", blob)
+		}
+	}
+}
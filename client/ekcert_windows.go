@@ -0,0 +1,40 @@
+package client
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// ekCertStoreKey is where Windows stores the EK certificates of the TPM, as
+// serialized certificates in the "Blob" value of each subkey.
+const ekCertStoreKey = `SYSTEM\CurrentControlSet\Services\TPM\WMI\Endorsement\EKCertStore\Certificates`
+
+func getPlatformEKCerts() ([][]byte, error) {
+	store, err := registry.OpenKey(registry.LOCAL_MACHINE, ekCertStoreKey, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open EK certificate store: %w", err)
+	}
+	defer store.Close()
+	names, err := store.ReadSubKeyNames(-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list EK certificates: %w", err)
+	}
+
+	var certs [][]byte
+	for _, name := range names {
+		key, err := registry.OpenKey(store, name, registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+		blob, _, err := key.GetBinaryValue("Blob")
+		key.Close()
+		if err != nil {
+			continue
+		}
+		if cert, err := parseCertStoreBlob(blob); err == nil {
+			certs = append(certs, cert)
+		}
+	}
+	return certs, nil
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+	"bytes"
+	"crypto/x509"
+	"embed"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/google/go-tpm-tools/internal"
+)
+
+// ekRootBundles are PEM files of the root and intermediate CAs of TPM vendors,
+// named after the vendor. Only the roots of the GCE vTPM are bundled; the roots
+// of hardware vendors (e.g. Infineon, Nuvoton, STMicro or the AMD fTPM) are
+// published on their PKI sites and must be pinned with AppendCertsFromFile.
+//
+//go:embed ek-roots/*.pem
+var ekRootBundles embed.FS
+
+// EKRootStore is a set of root and intermediate CA certificates used to verify
+// EK certificates. The zero value is an empty store, to which custom roots can
+// be pinned with AddRoots or AppendCertsFromPEM.
+type EKRootStore struct {
+	roots         []*x509.Certificate
+	intermediates []*x509.Certificate
+}
+
+// DefaultEKRootStore returns a store with the roots of all the vendors in
+// EKRootVendors. Verifying the EK certificates of on-prem hardware needs the
+// roots of its TPM vendor to be added to the store.
+func DefaultEKRootStore() (*EKRootStore, error) {
+	return VendorEKRootStore(EKRootVendors()...)
+}
+
+// VendorEKRootStore returns a store with the bundled roots of the vendors.
+func VendorEKRootStore(vendors ...string) (*EKRootStore, error) {
+	store := &EKRootStore{}
+	for _, vendor := range vendors {
+		bundle, err := ekRootBundles.ReadFile(path.Join("ek-roots", vendor+".pem"))
+		if err != nil {
+			return nil, fmt.Errorf("no EK roots for vendor %q", vendor)
+		}
+		if err := store.AppendCertsFromPEM(bundle); err != nil {
+			return nil, fmt.Errorf("invalid EK roots for vendor %q: %w", vendor, err)
+		}
+	}
+	return store, nil
+}
+
+// EKRootVendors returns the vendors with bundled EK roots, like "gce".
+func EKRootVendors() []string {
+	entries, err := ekRootBundles.ReadDir("ek-roots")
+	if err != nil {
+		return nil
+	}
+	var vendors []string
+	for _, entry := range entries {
+		vendors = append(vendors, strings.TrimSuffix(entry.Name(), ".pem"))
+	}
+	sort.Strings(vendors)
+	return vendors
+}
+
+// AddRoots adds trusted root CAs to the store.
+func (s *EKRootStore) AddRoots(certs ...*x509.Certificate) {
+	s.roots = append(s.roots, certs...)
+}
+
+// AddIntermediates adds intermediate CAs to the store. They are only used to
+// build chains to the roots, and are not trusted themselves.
+func (s *EKRootStore) AddIntermediates(certs ...*x509.Certificate) {
+	s.intermediates = append(s.intermediates, certs...)
+}
+
+// AppendCertsFromPEM adds the certificates in pemCerts to the store, the
+// self-signed ones as roots and the others as intermediates.
+func (s *EKRootStore) AppendCertsFromPEM(pemCerts []byte) error {
+	for len(pemCerts) > 0 {
+		var block *pem.Block
+		block, pemCerts = pem.Decode(pemCerts)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		if isSelfSigned(cert) {
+			s.AddRoots(cert)
+		} else {
+			s.AddIntermediates(cert)
+		}
+	}
+	return nil
+}
+
+// AppendCertsFromFile adds the PEM certificates in the file to the store, see
+// AppendCertsFromPEM.
+func (s *EKRootStore) AppendCertsFromFile(name string) error {
+	pemCerts, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	return s.AppendCertsFromPEM(pemCerts)
+}
+
+// Roots returns the root CAs in the store.
+func (s *EKRootStore) Roots() []*x509.Certificate {
+	return append([]*x509.Certificate(nil), s.roots...)
+}
+
+// Intermediates returns the intermediate CAs in the store.
+func (s *EKRootStore) Intermediates() []*x509.Certificate {
+	return append([]*x509.Certificate(nil), s.intermediates...)
+}
+
+func isSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignatureFrom(cert) == nil
+}
+
+// VerifyCert verifies that the certificate of the key chains to one of the
+// roots in the store, and returns the chain from the key's certificate to the
+// root. Intermediates missing from the store are fetched with fetcher from the
+// IssuingCertificateURLs of the certificates, unless fetcher is nil. This is
+// needed for most vendors, which only store the EK certificate in the TPM.
+func (k *Key) VerifyCert(store *EKRootStore, fetcher *http.Client) ([]*x509.Certificate, error) {
+	if k.cert == nil {
+		return nil, errors.New("key has no certificate")
+	}
+	if store == nil || len(store.roots) == 0 {
+		return nil, errors.New("no EK roots provided")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range store.intermediates {
+		intermediates.AddCert(cert)
+	}
+	if fetcher != nil {
+		chain, err := k.getCertificateChain(fetcher)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch the certificate chain: %w", err)
+		}
+		for _, der := range chain {
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return nil, err
+			}
+			intermediates.AddCert(cert)
+		}
+	}
+	roots := x509.NewCertPool()
+	for _, cert := range store.roots {
+		roots.AddCert(cert)
+	}
+
+	chains, err := internal.HandleTPMSubjectAltName(k.cert).Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		// EK certificates have the tcg-kp-EKCertificate ExtKeyUsage, if any.
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("certificate did not chain to a trusted root: %w", err)
+	}
+	chains[0][0] = k.cert
+	return chains[0], nil
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// getTestEKCert returns an EK certificate signed by parent, with a critical
+// SAN extension like the ones of TPM vendors.
+func getTestEKCert(t *testing.T, issuingURL []string, parentCert *x509.Certificate, parentKey *rsa.PrivateKey) *x509.Certificate {
+	t.Helper()
+	ekKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageKeyEncipherment,
+		IssuingCertificateURL: issuingURL,
+		ExtraExtensions: []pkix.Extension{{
+			Id:       asn1.ObjectIdentifier{2, 5, 29, 17},
+			Critical: true,
+			// A SEQUENCE with an empty directoryName.
+			Value: []byte{0x30, 0x04, 0xa4, 0x02, 0x30, 0x00},
+		}},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, ekKey.Public(), parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// getTestCA returns a CA certificate without path length constraint, signed
+// by parent, or self-signed if parent is nil.
+func getTestCA(t *testing.T, parentCert *x509.Certificate, parentKey *rsa.PrivateKey) (*x509.Certificate, *rsa.PrivateKey) {
+	t.Helper()
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	if parentCert == nil {
+		parentCert, parentKey = template, caKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, caKey.Public(), parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, caKey
+}
+
+func TestDefaultEKRootStore(t *testing.T) {
+	store, err := DefaultEKRootStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(store.Roots()) == 0 {
+		t.Error("DefaultEKRootStore() has no roots")
+	}
+	if _, err := VendorEKRootStore("unknown"); err == nil {
+		t.Error("VendorEKRootStore() succeeded with an unknown vendor")
+	}
+}
+
+func TestAppendCertsFromPEM(t *testing.T) {
+	rootCert, rootKey := getTestCert(t, nil, nil, nil)
+	intermediateCert, _ := getTestCert(t, nil, rootCert, rootKey)
+
+	var pemCerts []byte
+	for _, cert := range []*x509.Certificate{rootCert, intermediateCert} {
+		pemCerts = append(pemCerts, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+	var store EKRootStore
+	if err := store.AppendCertsFromPEM(pemCerts); err != nil {
+		t.Fatal(err)
+	}
+	if roots := store.Roots(); len(roots) != 1 || !roots[0].Equal(rootCert) {
+		t.Errorf("got roots %v, want the root certificate", roots)
+	}
+	if intermediates := store.Intermediates(); len(intermediates) != 1 || !intermediates[0].Equal(intermediateCert) {
+		t.Errorf("got intermediates %v, want the intermediate certificate", intermediates)
+	}
+}
+
+func TestVerifyCert(t *testing.T) {
+	rootCert, rootKey := getTestCA(t, nil, nil)
+	intermediateCert, intermediateKey := getTestCA(t, rootCert, rootKey)
+	intermediateServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+		rw.Write(intermediateCert.Raw)
+	}))
+	defer intermediateServer.Close()
+	key := &Key{cert: getTestEKCert(t, []string{intermediateServer.URL}, intermediateCert, intermediateKey)}
+
+	otherRoot, _ := getTestCA(t, nil, nil)
+
+	tests := []struct {
+		name    string
+		store   *EKRootStore
+		fetcher *http.Client
+		wantErr bool
+	}{
+		{"Intermediate in store", &EKRootStore{roots: []*x509.Certificate{rootCert}, intermediates: []*x509.Certificate{intermediateCert}}, nil, false},
+		{"Fetched intermediate", &EKRootStore{roots: []*x509.Certificate{rootCert}}, localClient, false},
+		{"Missing intermediate", &EKRootStore{roots: []*x509.Certificate{rootCert}}, nil, true},
+		{"Untrusted root", &EKRootStore{roots: []*x509.Certificate{otherRoot}}, localClient, true},
+		{"Empty store", &EKRootStore{}, localClient, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			chain, err := key.VerifyCert(tc.store, tc.fetcher)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("VerifyCert() got error %v, want error %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(chain) != 3 || chain[0] != key.cert || !chain[2].Equal(rootCert) {
+				t.Errorf("VerifyCert() returned unexpected chain %v", chain)
+			}
+		})
+	}
+}
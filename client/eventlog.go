@@ -1,6 +1,10 @@
 package client
 
-import "io"
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
 
 // GetEventLog grabs the crypto-agile TCG event log for the system. The TPM can
 // override this implementation by implementing EventLogGetter.
@@ -17,3 +21,65 @@ func GetEventLog(rw io.ReadWriter) ([]byte, error) {
 type EventLogGetter interface {
 	EventLog() ([]byte, error)
 }
+
+// OpenEventLog opens the crypto-agile TCG event log for the system, so it can
+// be read in chunks instead of being held in memory, e.g. to hash or forward a
+// log of several MB in a memory-constrained TEE. The TPM can override this
+// implementation by implementing EventLogOpener or EventLogGetter.
+func OpenEventLog(rw io.ReadWriter) (io.ReadCloser, error) {
+	if elo, ok := rw.(EventLogOpener); ok {
+		return elo.OpenEventLog()
+	}
+	if elg, ok := rw.(EventLogGetter); ok {
+		log, err := elg.EventLog()
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(log)), nil
+	}
+	return openRealEventLog()
+}
+
+// EventLogOpener allows a TPM (io.ReadWriter) to specify a particular
+// implementation for OpenEventLog().
+type EventLogOpener interface {
+	OpenEventLog() (io.ReadCloser, error)
+}
+
+// ReadEventLog is like GetEventLog, but reads at most maxSize bytes of the
+// event log, and reports whether the log was truncated to maxSize. A
+// truncated log cannot be replayed against the PCRs.
+func ReadEventLog(rw io.ReadWriter, maxSize int64) (log []byte, truncated bool, err error) {
+	if maxSize <= 0 {
+		return nil, false, fmt.Errorf("invalid maximum event log size %d", maxSize)
+	}
+	r, err := OpenEventLog(rw)
+	if err != nil {
+		return nil, false, err
+	}
+	defer r.Close()
+	// Read one more byte to tell whether the log is larger than maxSize.
+	if log, err = io.ReadAll(io.LimitReader(r, maxSize+1)); err != nil {
+		return nil, false, err
+	}
+	if int64(len(log)) > maxSize {
+		return log[:maxSize], true, nil
+	}
+	return log, false, nil
+}
+
+// GetIMALog grabs the IMA runtime measurement list of the system, in the
+// ascii_runtime_measurements format. The TPM can override this implementation
+// by implementing IMALogGetter.
+func GetIMALog(rw io.ReadWriter) ([]byte, error) {
+	if ilg, ok := rw.(IMALogGetter); ok {
+		return ilg.IMALog()
+	}
+	return getRealIMALog()
+}
+
+// IMALogGetter allows a TPM (io.ReadWriter) to specify a particular
+// implementation for GetIMALog(). This is useful for testing.
+type IMALogGetter interface {
+	IMALog() ([]byte, error)
+}
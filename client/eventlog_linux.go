@@ -1,7 +1,20 @@
 package client
 
-import "os"
+import (
+	"io"
+	"os"
+)
+
+const eventLogPath = "/sys/kernel/security/tpm0/binary_bios_measurements"
 
 func getRealEventLog() ([]byte, error) {
-	return os.ReadFile("/sys/kernel/security/tpm0/binary_bios_measurements")
+	return os.ReadFile(eventLogPath)
+}
+
+func openRealEventLog() (io.ReadCloser, error) {
+	return os.Open(eventLogPath)
+}
+
+func getRealIMALog() ([]byte, error) {
+	return os.ReadFile("/sys/kernel/security/ima/ascii_runtime_measurements")
 }
@@ -1,10 +1,21 @@
-//go:build !linux
-// +build !linux
+//go:build !linux && !windows
+// +build !linux,!windows
 
 package client
 
-import "errors"
+import (
+	"errors"
+	"io"
+)
 
 func getRealEventLog() ([]byte, error) {
-	return nil, errors.New("failed to get event log: only Linux supported")
+	return nil, errors.New("failed to get event log: only Linux and Windows supported")
+}
+
+func openRealEventLog() (io.ReadCloser, error) {
+	return nil, errors.New("failed to open event log: only Linux and Windows supported")
+}
+
+func getRealIMALog() ([]byte, error) {
+	return nil, errors.New("failed to get IMA log: only Linux supported")
 }
@@ -0,0 +1,43 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/go-tpm/tpmutil/tbs"
+)
+
+func getRealEventLog() ([]byte, error) {
+	context, err := tbs.CreateContext(tbs.TPMVersion20, tbs.IncludeTPM20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open TBS context: %w", err)
+	}
+	defer context.Close()
+
+	// Get the size of the log first, as it can be larger than any fixed buffer.
+	size, err := context.GetTCGLog(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event log size: %w", err)
+	}
+	log := make([]byte, size)
+	if size, err = context.GetTCGLog(log); err != nil {
+		return nil, fmt.Errorf("failed to get event log: %w", err)
+	}
+	return log[:size], nil
+}
+
+// openRealEventLog returns the event log read by getRealEventLog, as TBS
+// cannot read the log in chunks.
+func openRealEventLog() (io.ReadCloser, error) {
+	log, err := getRealEventLog()
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(log)), nil
+}
+
+func getRealIMALog() ([]byte, error) {
+	return nil, errors.New("failed to get IMA log: only Linux supported")
+}
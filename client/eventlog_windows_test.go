@@ -0,0 +1,17 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/google/go-attestation/attest"
+)
+
+func TestGetRealEventLog(t *testing.T) {
+	rawLog, err := getRealEventLog()
+	if err != nil {
+		t.Skipf("TBS event log is not available: %v", err)
+	}
+	if _, err := attest.ParseEventLog(rawLog); err != nil {
+		t.Errorf("failed to parse the TBS event log: %v", err)
+	}
+}
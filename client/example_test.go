@@ -58,6 +58,47 @@ func ExampleKey_Quote() {
 	}
 	// Output:
 }
+
+func ExampleKey_QuoteNonce() {
+	// On verifier, make the nonce.
+	nonce, err := client.NewRandomNonce(32)
+	if err != nil {
+		log.Fatalf("failed to create nonce: %v", err)
+	}
+
+	// On client machine, generate the TPM quote.
+	// TODO: use real TPM.
+	simulator, err := simulator.Get()
+	if err != nil {
+		log.Fatalf("failed to initialize simulator: %v", err)
+	}
+	defer simulator.Close()
+
+	ak, err := client.AttestationKeyECC(simulator)
+	if err != nil {
+		log.Fatalf("failed to create attestation key: %v", err)
+	}
+	defer ak.Close()
+
+	pcr7 := tpm2.PCRSelection{
+		Hash: tpm2.AlgSHA256,
+		PCRs: []int{7},
+	}
+
+	quote, err := ak.QuoteNonce(pcr7, nonce)
+	if err != nil {
+		log.Fatalf("failed to create quote: %v", err)
+	}
+
+	// On verifier, verify the quote against a stored public key/AK
+	// certificate's public part and the nonce passed, which may have been
+	// quoted as its digest.
+	if err := internal.VerifyQuoteNonce(quote, ak.PublicKey(), nonce); err != nil {
+		// TODO: handle verify error.
+		log.Fatalf("failed to verify quote: %v", err)
+	}
+	// Output:
+}
 func ExampleKey_Import_eK() {
 	// On client machine, EK should already exist.
 	// TODO: use real TPM.
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+)
+
+// The endpoints of the GCE identity documents, variables for testing.
+var (
+	gceMetadataURL = "http://metadata.google.internal/computeMetadata/v1/"
+	gceComputeURL  = "https://compute.googleapis.com/compute/v1/"
+)
+
+// maxGCEResponseSize bounds the size of the responses of the metadata server
+// and the Compute Engine API.
+const maxGCEResponseSize = 1 << 20
+
+// shieldedInstanceIdentity is the response of the getShieldedInstanceIdentity
+// method of the Compute Engine API.
+type shieldedInstanceIdentity struct {
+	EncryptionKey shieldedInstanceIdentityEntry `json:"encryptionKey"`
+	SigningKey    shieldedInstanceIdentityEntry `json:"signingKey"`
+}
+
+type shieldedInstanceIdentityEntry struct {
+	EkCert string `json:"ekCert"`
+	EkPub  string `json:"ekPub"`
+}
+
+// getGCEIdentity fetches the instance identity token, bound to the nonce, and
+// the Shielded VM identity of the GCE instance with client.
+func getGCEIdentity(client *http.Client, nonce []byte) (*pb.GceIdentity, error) {
+	audience := sha256.Sum256(nonce)
+	token, err := getGCEMetadata(client, "instance/service-accounts/default/identity?format=full&audience="+hex.EncodeToString(audience[:]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the instance identity token: %w", err)
+	}
+	shieldedIdentity, err := getShieldedVMIdentity(client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the Shielded VM identity: %w", err)
+	}
+	return &pb.GceIdentity{
+		InstanceIdentityToken: string(token),
+		ShieldedVmIdentity:    shieldedIdentity,
+	}, nil
+}
+
+// getShieldedVMIdentity calls the getShieldedInstanceIdentity method of the
+// Compute Engine API for the instance, authorized by its default service
+// account.
+func getShieldedVMIdentity(client *http.Client) (*pb.ShieldedVmIdentity, error) {
+	var names [3]string
+	for i, attribute := range []string{"project/project-id", "instance/zone", "instance/name"} {
+		value, err := getGCEMetadata(client, attribute)
+		if err != nil {
+			return nil, err
+		}
+		// The zone is returned as projects/NUMBER/zones/ZONE.
+		names[i] = path.Base(string(value))
+	}
+	tokenJSON, err := getGCEMetadata(client, "instance/service-accounts/default/token")
+	if err != nil {
+		return nil, err
+	}
+	var accessToken struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(tokenJSON, &accessToken); err != nil {
+		return nil, fmt.Errorf("failed to parse the access token: %w", err)
+	}
+
+	identityURL := fmt.Sprintf("%sprojects/%s/zones/%s/instances/%s/getShieldedInstanceIdentity",
+		gceComputeURL, url.PathEscape(names[0]), url.PathEscape(names[1]), url.PathEscape(names[2]))
+	req, err := http.NewRequest(http.MethodGet, identityURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken.AccessToken)
+	body, err := doGCERequest(client, req)
+	if err != nil {
+		return nil, err
+	}
+	var identity shieldedInstanceIdentity
+	if err := json.Unmarshal(body, &identity); err != nil {
+		return nil, fmt.Errorf("failed to parse the Shielded VM identity: %w", err)
+	}
+	return &pb.ShieldedVmIdentity{
+		EncryptionKeyCert: identity.EncryptionKey.EkCert,
+		EncryptionKeyPub:  identity.EncryptionKey.EkPub,
+		SigningKeyCert:    identity.SigningKey.EkCert,
+		SigningKeyPub:     identity.SigningKey.EkPub,
+	}, nil
+}
+
+// getGCEMetadata returns the value of a path of the metadata server.
+func getGCEMetadata(client *http.Client, suffix string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, gceMetadataURL+suffix, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	value, err := doGCERequest(client, req)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.TrimSpace(string(value))), nil
+}
+
+func doGCERequest(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxGCEResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned non-OK status: %v", req.URL.Path, resp.StatusCode)
+	}
+	return body, nil
+}
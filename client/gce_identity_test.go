@@ -0,0 +1,115 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/internal/test"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+// newFakeGCEServer serves the GCE identity documents of an instance, and
+// points the GCE endpoints to it for the duration of the test.
+func newFakeGCEServer(t *testing.T, nonce []byte, identityStatus int) *httptest.Server {
+	t.Helper()
+	audience := sha256.Sum256(nonce)
+	metadata := map[string]string{
+		"/computeMetadata/v1/instance/service-accounts/default/identity": "identity-token",
+		"/computeMetadata/v1/project/project-id":                         "test-project",
+		"/computeMetadata/v1/instance/zone":                              "projects/123/zones/us-central1-a",
+		"/computeMetadata/v1/instance/name":                              "test-instance",
+		"/computeMetadata/v1/instance/service-accounts/default/token":    `{"access_token":"access-token","expires_in":3599,"token_type":"Bearer"}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/compute/v1/projects/test-project/zones/us-central1-a/instances/test-instance/getShieldedInstanceIdentity" {
+			if r.Header.Get("Authorization") != "Bearer access-token" {
+				http.Error(w, "unauthenticated", http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(identityStatus)
+			fmt.Fprint(w, `{"kind":"compute#shieldedInstanceIdentity","encryptionKey":{"ekPub":"ek-pub"},"signingKey":{"ekCert":"ak-cert","ekPub":"ak-pub"}}`)
+			return
+		}
+		value, ok := metadata[r.URL.Path]
+		if !ok || r.Header.Get("Metadata-Flavor") != "Google" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Path == "/computeMetadata/v1/instance/service-accounts/default/identity" &&
+			(r.URL.Query().Get("audience") != hex.EncodeToString(audience[:]) || r.URL.Query().Get("format") != "full") {
+			http.Error(w, "unexpected token request", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintln(w, value)
+	}))
+	t.Cleanup(srv.Close)
+
+	oldMetadataURL, oldComputeURL := gceMetadataURL, gceComputeURL
+	gceMetadataURL, gceComputeURL = srv.URL+"/computeMetadata/v1/", srv.URL+"/compute/v1/"
+	t.Cleanup(func() { gceMetadataURL, gceComputeURL = oldMetadataURL, oldComputeURL })
+	return srv
+}
+
+func TestKeyAttestGCEIdentity(t *testing.T) {
+	nonce := []byte("some nonce")
+	srv := newFakeGCEServer(t, nonce, http.StatusOK)
+	rwc := test.GetTPM(t)
+	defer CheckedClose(t, rwc)
+
+	ak, err := AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("Failed to generate test AK: %v", err)
+	}
+	defer ak.Close()
+
+	attestation, err := ak.Attest(AttestOpts{Nonce: nonce, GCEIdentityFetcher: srv.Client()})
+	if err != nil {
+		t.Fatalf("Attest returned with error: %v", err)
+	}
+	want := &pb.GceIdentity{
+		InstanceIdentityToken: "identity-token",
+		ShieldedVmIdentity: &pb.ShieldedVmIdentity{
+			EncryptionKeyPub: "ek-pub",
+			SigningKeyCert:   "ak-cert",
+			SigningKeyPub:    "ak-pub",
+		},
+	}
+	if diff := cmp.Diff(attestation.GetGceIdentity(), want, protocmp.Transform()); diff != "" {
+		t.Errorf("unexpected GCE identity difference:\n%v", diff)
+	}
+
+	attestation, err = ak.Attest(AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatalf("Attest returned with error: %v", err)
+	}
+	if attestation.GetGceIdentity() != nil {
+		t.Error("Attest fetched the GCE identity without a GCEIdentityFetcher")
+	}
+}
+
+func TestKeyAttestGCEIdentityError(t *testing.T) {
+	nonce := []byte("some nonce")
+	srv := newFakeGCEServer(t, nonce, http.StatusForbidden)
+	rwc := test.GetTPM(t)
+	defer CheckedClose(t, rwc)
+
+	ak, err := AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("Failed to generate test AK: %v", err)
+	}
+	defer ak.Close()
+
+	if _, err := ak.Attest(AttestOpts{Nonce: nonce, GCEIdentityFetcher: srv.Client()}); err == nil {
+		t.Error("Attest succeeded when the Shielded VM identity could not be fetched")
+	}
+	// The token is bound to the nonce of the attestation.
+	if _, err := ak.Attest(AttestOpts{Nonce: []byte("other nonce"), GCEIdentityFetcher: srv.Client()}); err == nil {
+		t.Error("Attest succeeded with an identity token for another nonce")
+	}
+}
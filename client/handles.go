@@ -30,6 +30,13 @@ const (
 const (
 	DefaultAKECCHandle = tpmutil.Handle(0x81008F00)
 	DefaultAKRSAHandle = tpmutil.Handle(0x81008F01)
+	// AKs of AKTemplateECCP384 and AKTemplateRSA3072.
+	DefaultAKECCP384Handle = tpmutil.Handle(0x81008F02)
+	DefaultAKRSA3072Handle = tpmutil.Handle(0x81008F03)
+	// EKs of EKTemplateECCP384 and EKTemplateRSA3072. These templates are not
+	// the TCG ones, so the EK handles reserved by the TCG are not used.
+	DefaultEKECCP384Handle = tpmutil.Handle(0x81008F04)
+	DefaultEKRSA3072Handle = tpmutil.Handle(0x81008F05)
 )
 
 // GCE Attestation Key NV Indices
@@ -40,6 +47,8 @@ const (
 	// ECC P256 AK.
 	GceAKCertNVIndexECC     uint32 = 0x01c10002
 	GceAKTemplateNVIndexECC uint32 = 0x01c10003
+	// GCE does not provision P384 or RSA 3072 AKs, see AttestationKeyECCP384
+	// and AttestationKeyRSA3072 for AKs in the Owner hierarchy.
 )
 
 func isHierarchy(h tpmutil.Handle) bool {
@@ -16,7 +16,7 @@ func loadHandle(k *Key, blob *pb.ImportBlob) (tpmutil.Handle, error) {
 	}
 	private, err := tpm2.Import(k.rw, k.Handle(), auth, blob.PublicArea, blob.Duplicate, blob.EncryptedSeed, nil, nil)
 	if err != nil {
-		return tpm2.HandleNull, fmt.Errorf("import failed: %w", err)
+		return tpm2.HandleNull, checkLockout(fmt.Errorf("import failed: %w", err))
 	}
 
 	auth, err = k.session.Auth()
@@ -25,7 +25,7 @@ func loadHandle(k *Key, blob *pb.ImportBlob) (tpmutil.Handle, error) {
 	}
 	handle, _, err := tpm2.LoadUsingAuth(k.rw, k.Handle(), auth, blob.PublicArea, private)
 	if err != nil {
-		return tpm2.HandleNull, fmt.Errorf("load failed: %w", err)
+		return tpm2.HandleNull, checkLockout(fmt.Errorf("load failed: %w", err))
 	}
 	return handle, nil
 }
@@ -52,7 +52,7 @@ func (k *Key) Import(blob *pb.ImportBlob) ([]byte, error) {
 	}
 	out, err := tpm2.UnsealWithSession(k.rw, auth.Session, handle, "")
 	if err != nil {
-		return nil, fmt.Errorf("unseal failed: %w", err)
+		return nil, checkLockout(fmt.Errorf("unseal failed: %w", err))
 	}
 	return out, nil
 }
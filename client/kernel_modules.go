@@ -0,0 +1,80 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// KernelModuleIMAPCR is the PCR KernelModuleIMAPolicy measures the kernel
+// module loads into. The other IMA measurements use PCR 10, so the loads are
+// recognized by their PCR rather than their file name, which can be anything.
+const KernelModuleIMAPCR = 11
+
+// KernelModuleIMAPolicy is the IMA policy rule measuring the kernel module
+// loads, and only them, into KernelModuleIMAPCR.
+var KernelModuleIMAPolicy = fmt.Sprintf("measure func=MODULE_CHECK pcr=%d", KernelModuleIMAPCR)
+
+// KernelModuleLoad is a kernel module load recorded in the IMA runtime
+// measurement list, by KernelModuleIMAPolicy.
+type KernelModuleLoad struct {
+	// FileDigest is the digest of the module file, e.g. "sha256:..." with
+	// the ima-ng template.
+	FileDigest string
+	// FileName is the path of the module file.
+	FileName string
+}
+
+// imaTrailingFields are the numbers of fields following the file name in the
+// templates of the IMA runtime measurement list: the signature of ima-sig,
+// and the signature, digest and appended signature of ima-modsig. An empty
+// field is still preceded by its separator.
+var imaTrailingFields = map[string]int{
+	"ima-ng":     0,
+	"ima-sig":    1,
+	"ima-modsig": 3,
+}
+
+// KernelModuleLoads returns the kernel module loads recorded in an IMA
+// runtime measurement list, in the ascii_runtime_measurements format returned
+// by GetIMALog, in order. The loads are the measurements of
+// KernelModuleIMAPCR, see KernelModuleIMAPolicy.
+func KernelModuleLoads(imaLog []byte) ([]KernelModuleLoad, error) {
+	var loads []KernelModuleLoad
+	scanner := bufio.NewScanner(bytes.NewReader(imaLog))
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if scanner.Text() == "" {
+			continue
+		}
+		// "PCR template-hash template-name template-fields..."
+		fields := strings.Split(scanner.Text(), " ")
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("IMA log line %d: got %d fields, want at least 3", line, len(fields))
+		}
+		if pcr, err := strconv.Atoi(fields[0]); err != nil {
+			return nil, fmt.Errorf("IMA log line %d: invalid PCR %q", line, fields[0])
+		} else if pcr != KernelModuleIMAPCR {
+			continue
+		}
+		trailing, ok := imaTrailingFields[fields[2]]
+		if !ok {
+			return nil, fmt.Errorf("IMA log line %d: unsupported template %q", line, fields[2])
+		}
+		// The file name may contain spaces, so it is everything between the
+		// file digest and the trailing fields.
+		if len(fields) < 5+trailing {
+			return nil, fmt.Errorf("IMA log line %d: got %d fields, want at least %d for template %s", line, len(fields), 5+trailing, fields[2])
+		}
+		loads = append(loads, KernelModuleLoad{
+			FileDigest: fields[3],
+			FileName:   strings.Join(fields[4:len(fields)-trailing], " "),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read IMA log: %v", err)
+	}
+	return loads, nil
+}
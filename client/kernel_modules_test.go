@@ -0,0 +1,44 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestKernelModuleLoads(t *testing.T) {
+	imaLog := []byte(`10 91f34b5c671d73504b274a919661cf80dab1e127 ima-ng sha1:0000000000000000000000000000000000000000 boot_aggregate
+11 8b1683287f61f96e5448f40bdef6df32be86486a ima-ng sha256:efdd249edec97caf9328a4a01baa99b7d660d1afc2e118b69137081c9b689954 /lib/modules/6.1.0/kernel/fs/fuse/fuse.ko
+10 ed893b1a0bc54ea5cd57014ca0a0f087ce71e4af ima-ng sha256:1fc9a8c8e3be2d0e5fa8a1bf4c88fd2e4b7a3b5c2f2a8f7d6c1f0e3a9c8b7d6e /lib/modules/6.1.0/kernel/fs/read.ko
+
+11 0000000000000000000000000000000000000000 ima-sig sha256:0000000000000000000000000000000000000000000000000000000000000000 /tmp/my module 030204a3b6e0d5
+` +
+		// The empty signature of an unsigned file still has its separator.
+		"11 1111111111111111111111111111111111111111 ima-sig sha256:1111111111111111111111111111111111111111111111111111111111111111 /tmp/unsigned \n" +
+		`11 2222222222222222222222222222222222222222 ima-modsig sha256:2222222222222222222222222222222222222222222222222222222222222222 /lib/modules/6.1.0/kernel/drivers/block/nbd.ko.xz 030204a3 sha256:3333333333333333333333333333333333333333333333333333333333333333 308201
+`)
+	loads, err := KernelModuleLoads(imaLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []KernelModuleLoad{
+		{FileDigest: "sha256:efdd249edec97caf9328a4a01baa99b7d660d1afc2e118b69137081c9b689954", FileName: "/lib/modules/6.1.0/kernel/fs/fuse/fuse.ko"},
+		{FileDigest: "sha256:0000000000000000000000000000000000000000000000000000000000000000", FileName: "/tmp/my module"},
+		{FileDigest: "sha256:1111111111111111111111111111111111111111111111111111111111111111", FileName: "/tmp/unsigned"},
+		{FileDigest: "sha256:2222222222222222222222222222222222222222222222222222222222222222", FileName: "/lib/modules/6.1.0/kernel/drivers/block/nbd.ko.xz"},
+	}
+	if diff := cmp.Diff(want, loads); diff != "" {
+		t.Errorf("unexpected kernel module loads (-want +got):\n%s", diff)
+	}
+
+	for _, malformed := range []string{
+		"11 0000 ima-ng\n",
+		"11 0000 ima-sig sha256:00 /lib/modules/fuse.ko\n",
+		"11 0000 ima sha256:00 /lib/modules/fuse.ko\n",
+		"ten 0000 ima-ng sha256:00 /lib/modules/fuse.ko\n",
+	} {
+		if _, err := KernelModuleLoads([]byte(malformed)); err == nil {
+			t.Errorf("KernelModuleLoads(%q) succeeded with a malformed IMA log", malformed)
+		}
+	}
+}
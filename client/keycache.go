@@ -0,0 +1,147 @@
+package client
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+// KeyCache is an in-process cache of the keys of a TPM, so services which
+// attest frequently do not recreate the same primary keys for every
+// attestation. Keys are cached by their parent and the digest of their
+// template, and are shared by all the callers of the cache: callers must Close
+// every key they get exactly once, and must not Close a key while it is in
+// use by another goroutine. A cached key stays loaded once all its callers
+// closed it, until Invalidate is called.
+//
+// Concurrent calls to the methods of a KeyCache are safe, and concurrent
+// requests of a key which is not cached wait for a single creation of the key.
+// Other users of the TPM must synchronize with the cache, as for any use of
+// the io.ReadWriter.
+type KeyCache struct {
+	rw io.ReadWriter
+
+	mu      sync.Mutex
+	entries map[keyCacheID]*keyCacheEntry
+}
+
+type keyCacheID struct {
+	parent         tpmutil.Handle
+	templateDigest [sha256.Size]byte
+}
+
+type keyCacheEntry struct {
+	key  *Key
+	refs int
+	// invalidated entries are flushed once their last reference is released.
+	invalidated bool
+}
+
+// NewKeyCache returns an empty KeyCache of the keys of the TPM.
+func NewKeyCache(rw io.ReadWriter) *KeyCache {
+	return &KeyCache{rw: rw, entries: make(map[keyCacheID]*keyCacheEntry)}
+}
+
+// Key returns the key of the template under the parent, created by NewKey if
+// it is not cached.
+func (c *KeyCache) Key(parent tpmutil.Handle, template tpm2.Public) (*Key, error) {
+	return c.get(parent, template, func() (*Key, error) {
+		return NewKey(c.rw, parent, template)
+	})
+}
+
+// EndorsementKeyRSA returns the key of EndorsementKeyRSA.
+func (c *KeyCache) EndorsementKeyRSA() (*Key, error) {
+	return c.get(tpm2.HandleEndorsement, DefaultEKTemplateRSA(), func() (*Key, error) {
+		return EndorsementKeyRSA(c.rw)
+	})
+}
+
+// EndorsementKeyECC returns the key of EndorsementKeyECC.
+func (c *KeyCache) EndorsementKeyECC() (*Key, error) {
+	return c.get(tpm2.HandleEndorsement, DefaultEKTemplateECC(), func() (*Key, error) {
+		return EndorsementKeyECC(c.rw)
+	})
+}
+
+// GceAttestationKeyRSA returns the key of GceAttestationKeyRSA. Its template
+// is read from GceAKTemplateNVIndexRSA on every call, so a new template
+// provisioned in the TPM is a new key.
+func (c *KeyCache) GceAttestationKeyRSA() (*Key, error) {
+	return c.gceAttestationKey(GceAKTemplateNVIndexRSA, GceAttestationKeyRSA)
+}
+
+// GceAttestationKeyECC returns the key of GceAttestationKeyECC, see
+// GceAttestationKeyRSA.
+func (c *KeyCache) GceAttestationKeyECC() (*Key, error) {
+	return c.gceAttestationKey(GceAKTemplateNVIndexECC, GceAttestationKeyECC)
+}
+
+func (c *KeyCache) gceAttestationKey(templateIdx uint32, create func(io.ReadWriter) (*Key, error)) (*Key, error) {
+	c.mu.Lock()
+	template, err := templateFromNvIndex(c.rw, templateIdx)
+	c.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return c.get(tpm2.HandleEndorsement, template, func() (*Key, error) {
+		return create(c.rw)
+	})
+}
+
+// get returns the cached key of the template under the parent, calling
+// create if it is not cached.
+func (c *KeyCache) get(parent tpmutil.Handle, template tpm2.Public, create func() (*Key, error)) (*Key, error) {
+	encoded, err := template.Encode()
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode the key template: %w", err)
+	}
+	id := keyCacheID{parent: parent, templateDigest: sha256.Sum256(encoded)}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[id]
+	if !ok {
+		k, err := create()
+		if err != nil {
+			return nil, err
+		}
+		entry = &keyCacheEntry{key: k}
+		k.release = func() { c.release(entry) }
+		c.entries[id] = entry
+	}
+	entry.refs++
+	return entry.key, nil
+}
+
+func (c *KeyCache) release(entry *keyCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry.refs == 0 {
+		return
+	}
+	entry.refs--
+	if entry.refs == 0 && entry.invalidated {
+		entry.key.flush()
+	}
+}
+
+// Invalidate removes all the keys from the cache, e.g. after the TPM was reset
+// or a hierarchy was changed, so they are created again when next requested.
+// The keys which are not in use are flushed, and the keys in use are flushed
+// once they are closed. Invalidate must be called before the TPM is closed.
+func (c *KeyCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, entry := range c.entries {
+		entry.invalidated = true
+		if entry.refs == 0 {
+			entry.key.flush()
+		}
+		delete(c.entries, id)
+	}
+}
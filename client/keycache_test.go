@@ -0,0 +1,110 @@
+package client_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/google/go-tpm/tpm2"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+)
+
+func numTransientHandles(t *testing.T, rw io.ReadWriter) int {
+	t.Helper()
+	handles, err := client.Handles(rw, tpm2.HandleTypeTransient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(handles)
+}
+
+func TestKeyCache(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	cache := client.NewKeyCache(rwc)
+	defer cache.Invalidate()
+
+	ak1, err := cache.Key(tpm2.HandleOwner, client.AKTemplateECC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ak2, err := cache.Key(tpm2.HandleOwner, client.AKTemplateECC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ak1 != ak2 {
+		t.Error("got a new key for a cached template")
+	}
+	rsa, err := cache.Key(tpm2.HandleOwner, client.AKTemplateRSA())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rsa == ak1 {
+		t.Error("got the same key for different templates")
+	}
+	if got := numTransientHandles(t, rwc); got != 2 {
+		t.Errorf("got %d loaded keys, want 2", got)
+	}
+
+	// Closed keys stay loaded until the cache is invalidated.
+	ak1.Close()
+	ak2.Close()
+	rsa.Close()
+	if got := numTransientHandles(t, rwc); got != 2 {
+		t.Errorf("got %d loaded keys after closing them, want 2", got)
+	}
+	ak3, err := cache.Key(tpm2.HandleOwner, client.AKTemplateECC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ak3 != ak1 {
+		t.Error("got a new key for a cached template after closing it")
+	}
+
+	// Keys in use are flushed once they are closed.
+	cache.Invalidate()
+	if got := numTransientHandles(t, rwc); got != 1 {
+		t.Errorf("got %d loaded keys after invalidating the cache, want the key in use", got)
+	}
+	if _, err := ak3.Quote(tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{0}}, []byte("nonce")); err != nil {
+		t.Errorf("Quote() with an invalidated key in use failed: %v", err)
+	}
+	ak3.Close()
+	if got := numTransientHandles(t, rwc); got != 0 {
+		t.Errorf("got %d loaded keys after closing the invalidated key, want 0", got)
+	}
+
+	ak4, err := cache.Key(tpm2.HandleOwner, client.AKTemplateECC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak4.Close()
+	if ak4 == ak1 {
+		t.Error("got an invalidated key")
+	}
+}
+
+func TestKeyCacheEndorsementKey(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	cache := client.NewKeyCache(rwc)
+	defer cache.Invalidate()
+
+	ek1, err := cache.EndorsementKeyECC()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ek1.Close()
+	ek2, err := cache.EndorsementKeyECC()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ek2.Close()
+	if ek1 != ek2 {
+		t.Error("got a new EK for a cached template")
+	}
+	if ek1.Handle() != client.EKECCReservedHandle {
+		t.Errorf("got EK handle %x, want %x", ek1.Handle(), client.EKECCReservedHandle)
+	}
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+// KeyFilePEMType is the PEM block type of TSS2 key files.
+const KeyFilePEMType = "TSS2 PRIVATE KEY"
+
+// oidLoadableKey identifies a key file holding a key to load with
+// TPM2_Load, from "ASN.1 Specification for TPM 2.0 Key Files".
+var oidLoadableKey = asn1.ObjectIdentifier{2, 23, 133, 10, 1, 3}
+
+// KeyFile is a key in the TSS2 key file format, as used by the tpm2 OpenSSL
+// provider and the tpm2-tss-engine: the public area and the private area,
+// encrypted by its parent, of a key created under a storage key. Only
+// loadable keys with an empty authorization and no policy are supported.
+type KeyFile struct {
+	// Parent is the persistent handle of the parent of the key, or
+	// tpm2.HandleOwner if the parent is the primary storage key created in
+	// the Owner hierarchy from the standard template, see SRKTemplateECC.
+	Parent tpmutil.Handle
+	Public tpm2.Public
+	// Private is the TPM2B_PRIVATE of the key, without its size.
+	Private []byte
+}
+
+// tpmKey is the ASN.1 TPMKey structure of a key file.
+type tpmKey struct {
+	Type       asn1.ObjectIdentifier
+	EmptyAuth  bool          `asn1:"explicit,tag:0,optional"`
+	Policy     asn1.RawValue `asn1:"explicit,tag:1,optional"`
+	Secret     asn1.RawValue `asn1:"explicit,tag:2,optional"`
+	AuthPolicy asn1.RawValue `asn1:"explicit,tag:3,optional"`
+	Parent     int64
+	PubKey     []byte
+	PrivKey    []byte
+}
+
+// ParseKeyFile parses a PEM encoded TSS2 key file.
+func ParseKeyFile(pemBytes []byte) (*KeyFile, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil || block.Type != KeyFilePEMType {
+		return nil, fmt.Errorf("no %s PEM block found", KeyFilePEMType)
+	}
+	var key tpmKey
+	rest, err := asn1.Unmarshal(block.Bytes, &key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key file: %w", err)
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("trailing data after key file")
+	}
+	if !key.Type.Equal(oidLoadableKey) {
+		return nil, fmt.Errorf("unsupported key file type %v, expected a loadable key", key.Type)
+	}
+	if !key.EmptyAuth || len(key.Policy.FullBytes) != 0 || len(key.Secret.FullBytes) != 0 || len(key.AuthPolicy.FullBytes) != 0 {
+		return nil, errors.New("key files with an authorization value or a policy are not supported")
+	}
+	if key.Parent < 0 || key.Parent > 0xffffffff {
+		return nil, fmt.Errorf("invalid parent handle %d", key.Parent)
+	}
+
+	var pubArea, private tpmutil.U16Bytes
+	if err := unpackSized(key.PubKey, &pubArea); err != nil {
+		return nil, fmt.Errorf("invalid public area: %w", err)
+	}
+	if err := unpackSized(key.PrivKey, &private); err != nil {
+		return nil, fmt.Errorf("invalid private area: %w", err)
+	}
+	pub, err := tpm2.DecodePublic(pubArea)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public area: %w", err)
+	}
+	return &KeyFile{Parent: tpmutil.Handle(key.Parent), Public: pub, Private: private}, nil
+}
+
+// unpackSized decodes the TPM2B data into out, which must be all of it.
+func unpackSized(data []byte, out *tpmutil.U16Bytes) error {
+	read, err := tpmutil.Unpack(data, out)
+	if err != nil {
+		return err
+	}
+	if read != len(data) {
+		return errors.New("trailing data")
+	}
+	return nil
+}
+
+// PEM encodes the key file as PEM.
+func (f *KeyFile) PEM() ([]byte, error) {
+	pubArea, err := f.Public.Encode()
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := tpmutil.Pack(tpmutil.U16Bytes(pubArea))
+	if err != nil {
+		return nil, err
+	}
+	privKey, err := tpmutil.Pack(tpmutil.U16Bytes(f.Private))
+	if err != nil {
+		return nil, err
+	}
+	der, err := asn1.Marshal(tpmKey{
+		Type:      oidLoadableKey,
+		EmptyAuth: true,
+		Parent:    int64(f.Parent),
+		PubKey:    pubKey,
+		PrivKey:   privKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: KeyFilePEMType, Bytes: der}), nil
+}
+
+// CreateKeyFile creates a key from the template under the storage key k, and
+// returns it as a KeyFile to be loaded with LoadKeyFile. The key must be
+// usable with an empty authorization, and k must be a persistent key, e.g.
+// StorageRootKeyECC, whose handle is recorded as the parent.
+func (k *Key) CreateKeyFile(template tpm2.Public) (*KeyFile, error) {
+	if tpm2.HandleType(k.handle>>24) != tpm2.HandleTypePersistent {
+		return nil, fmt.Errorf("parent handle 0x%x is not persistent", k.handle)
+	}
+	auth, err := k.session.Auth()
+	if err != nil {
+		return nil, err
+	}
+	private, pubArea, _, _, _, err := tpm2.CreateKeyUsingAuth(k.rw, k.handle, tpm2.PCRSelection{}, auth, "", template)
+	if err != nil {
+		return nil, checkLockout(fmt.Errorf("create failed: %w", err))
+	}
+	pub, err := tpm2.DecodePublic(pubArea)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyFile{Parent: k.handle, Public: pub, Private: private}, nil
+}
+
+// LoadKeyFile loads the key of the key file under the storage key k, which
+// must be its parent: the key at its persistent handle, or the primary key of
+// SRKTemplateECC (see StorageRootKeyECC) if the parent is tpm2.HandleOwner.
+func (k *Key) LoadKeyFile(f *KeyFile) (key *Key, err error) {
+	if f.Parent != tpm2.HandleOwner && f.Parent != k.handle {
+		return nil, fmt.Errorf("key file parent 0x%x does not match the key 0x%x", f.Parent, k.handle)
+	}
+	pubArea, err := f.Public.Encode()
+	if err != nil {
+		return nil, err
+	}
+	auth, err := k.session.Auth()
+	if err != nil {
+		return nil, err
+	}
+	handle, _, err := tpm2.LoadUsingAuth(k.rw, k.handle, auth, pubArea, f.Private)
+	if err != nil {
+		return nil, checkLockout(fmt.Errorf("load failed: %w", err))
+	}
+	key = &Key{rw: k.rw, handle: handle, pubArea: f.Public}
+	defer func() {
+		if err != nil {
+			key.Close()
+		}
+	}()
+	return key, key.finish()
+}
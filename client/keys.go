@@ -29,6 +29,9 @@ type Key struct {
 	name    tpm2.Name
 	session session
 	cert    *x509.Certificate
+	// release is called by Close instead of flushing the key if the key is
+	// shared by a KeyCache.
+	release func()
 }
 
 // EndorsementKeyRSA generates and loads a key from DefaultEKTemplateRSA.
@@ -41,6 +44,9 @@ func EndorsementKeyRSA(rw io.ReadWriter) (*Key, error) {
 		ekRsa.Close()
 		return nil, err
 	}
+	if ekRsa.cert == nil {
+		ekRsa.trySetCertificateFromPlatform()
+	}
 	return ekRsa, nil
 }
 
@@ -54,9 +60,27 @@ func EndorsementKeyECC(rw io.ReadWriter) (*Key, error) {
 		ekEcc.Close()
 		return nil, err
 	}
+	if ekEcc.cert == nil {
+		ekEcc.trySetCertificateFromPlatform()
+	}
 	return ekEcc, nil
 }
 
+// EndorsementKeyRSA3072 generates and loads a key from EKTemplateRSA3072.
+// Unlike EndorsementKeyRSA, no EK certificate is looked up, as the TPM
+// manufacturers only certify the EK templates of the TCG EK Credential Profile.
+// For the same reason, the key is cached at DefaultEKRSA3072Handle rather than
+// at a TCG reserved EK handle, which may hold a provisioned EK.
+func EndorsementKeyRSA3072(rw io.ReadWriter) (*Key, error) {
+	return NewCachedKey(rw, tpm2.HandleEndorsement, EKTemplateRSA3072(), DefaultEKRSA3072Handle)
+}
+
+// EndorsementKeyECCP384 generates and loads a key from EKTemplateECCP384,
+// see EndorsementKeyRSA3072.
+func EndorsementKeyECCP384(rw io.ReadWriter) (*Key, error) {
+	return NewCachedKey(rw, tpm2.HandleEndorsement, EKTemplateECCP384(), DefaultEKECCP384Handle)
+}
+
 // StorageRootKeyRSA generates and loads a key from SRKTemplateRSA.
 func StorageRootKeyRSA(rw io.ReadWriter) (*Key, error) {
 	return NewCachedKey(rw, tpm2.HandleOwner, SRKTemplateRSA(), SRKReservedHandle)
@@ -77,6 +101,16 @@ func AttestationKeyECC(rw io.ReadWriter) (*Key, error) {
 	return NewCachedKey(rw, tpm2.HandleOwner, AKTemplateECC(), DefaultAKECCHandle)
 }
 
+// AttestationKeyRSA3072 generates and loads a key from AKTemplateRSA3072 in the Owner hierarchy.
+func AttestationKeyRSA3072(rw io.ReadWriter) (*Key, error) {
+	return NewCachedKey(rw, tpm2.HandleOwner, AKTemplateRSA3072(), DefaultAKRSA3072Handle)
+}
+
+// AttestationKeyECCP384 generates and loads a key from AKTemplateECCP384 in the Owner hierarchy.
+func AttestationKeyECCP384(rw io.ReadWriter) (*Key, error) {
+	return NewCachedKey(rw, tpm2.HandleOwner, AKTemplateECCP384(), DefaultAKECCP384Handle)
+}
+
 // EndorsementKeyFromNvIndex generates and loads an endorsement key using the
 // template stored at the provided nvdata index. This is useful for TPMs which
 // have a preinstalled AK template.
@@ -118,15 +152,23 @@ func GceAttestationKeyECC(rw io.ReadWriter) (*Key, error) {
 // (possibly a hierarchy root tpm2.Handle{Owner|Endorsement|Platform|Null})
 // using the template stored at the provided nvdata index.
 func KeyFromNvIndex(rw io.ReadWriter, parent tpmutil.Handle, idx uint32) (*Key, error) {
+	template, err := templateFromNvIndex(rw, idx)
+	if err != nil {
+		return nil, err
+	}
+	return NewKey(rw, parent, template)
+}
+
+func templateFromNvIndex(rw io.ReadWriter, idx uint32) (tpm2.Public, error) {
 	data, err := tpm2.NVReadEx(rw, tpmutil.Handle(idx), tpm2.HandleOwner, "", 0)
 	if err != nil {
-		return nil, fmt.Errorf("read error at index %d: %w", idx, err)
+		return tpm2.Public{}, fmt.Errorf("read error at index %d: %w", idx, err)
 	}
 	template, err := tpm2.DecodePublic(data)
 	if err != nil {
-		return nil, fmt.Errorf("index %d data was not a TPM key template: %w", idx, err)
+		return tpm2.Public{}, fmt.Errorf("index %d data was not a TPM key template: %w", idx, err)
 	}
-	return NewKey(rw, parent, template)
+	return template, nil
 }
 
 // NewCachedKey is almost identical to NewKey, except that it initially tries to
@@ -177,6 +219,12 @@ func NewCachedKey(rw io.ReadWriter, parent tpmutil.Handle, template tpm2.Public,
 //   - Does not have its usage locked to specific PCR values
 //   - Usable with empty authorization sessions (i.e. doesn't need a password)
 func NewKey(rw io.ReadWriter, parent tpmutil.Handle, template tpm2.Public) (k *Key, err error) {
+	return newKey(rw, parent, template, nil)
+}
+
+// newKey is NewKey for a key using the session s, or the session for its
+// authPolicy if nil.
+func newKey(rw io.ReadWriter, parent tpmutil.Handle, template tpm2.Public, s session) (k *Key, err error) {
 	if !isHierarchy(parent) {
 		// TODO add support for normal objects with Create() and Load()
 		return nil, fmt.Errorf("unsupported parent handle: %x", parent)
@@ -193,7 +241,7 @@ func NewKey(rw io.ReadWriter, parent tpmutil.Handle, template tpm2.Public) (k *K
 		}
 	}()
 
-	k = &Key{rw: rw, handle: handle}
+	k = &Key{rw: rw, handle: handle, session: s}
 	if k.pubArea, err = tpm2.DecodePublic(pubArea); err != nil {
 		return
 	}
@@ -211,7 +259,11 @@ func (k *Key) finish() error {
 	// We determine the right type of session based on the auth policy
 	if k.session == nil {
 		if bytes.Equal(k.pubArea.AuthPolicy, defaultEKAuthPolicy()) {
-			if k.session, err = newEKSession(k.rw); err != nil {
+			if k.session, err = newEKSession(k.rw, tpm2.AlgSHA256); err != nil {
+				return err
+			}
+		} else if bytes.Equal(k.pubArea.AuthPolicy, ekAuthPolicy(crypto.SHA384)) {
+			if k.session, err = newEKSession(k.rw, tpm2.AlgSHA384); err != nil {
 				return err
 			}
 		} else if len(k.pubArea.AuthPolicy) == 0 {
@@ -248,7 +300,16 @@ func (k *Key) PublicKey() crypto.PublicKey {
 
 // Close should be called when the key is no longer needed. This is important to
 // do as most TPMs can only have a small number of key simultaneously loaded.
+// Closing a key returned by a KeyCache releases it to the cache instead.
 func (k *Key) Close() {
+	if k.release != nil {
+		k.release()
+		return
+	}
+	k.flush()
+}
+
+func (k *Key) flush() {
 	if k.session != nil {
 		k.session.Close()
 	}
@@ -263,32 +324,104 @@ func (k *Key) Close() {
 // During the sealing process, certification data will be created allowing
 // Unseal() to validate the state of the TPM during the sealing process.
 func (k *Key) Seal(sensitive []byte, opts SealOpts) (*pb.SealedBytes, error) {
-	var pcrs *pb.PCRs
-	var err error
-	var auth []byte
+	sealed, err := k.SealBatch([][]byte{sensitive}, opts)
+	if err != nil {
+		return nil, err
+	}
+	return sealed[0], nil
+}
 
-	pcrs, err = mergePCRSelAndProto(k.rw, opts.Current, opts.Target)
+// SealBatch is like Seal() for many sensitive byte buffers sealed with the
+// same SealOpts. The sealing policy is computed and the certified PCRs are
+// read once for the whole batch, so sealing each buffer takes a single TPM
+// command. This matters for applications sealing hundreds of small secrets,
+// e.g. per-tenant keys.
+func (k *Key) SealBatch(sensitive [][]byte, opts SealOpts) ([]*pb.SealedBytes, error) {
+	var auth []byte
+	pcrs, err := mergePCRSelAndProto(k.rw, opts.Current, opts.Target)
 	if err != nil {
 		return nil, fmt.Errorf("invalid SealOpts: %v", err)
 	}
 	if len(pcrs.GetPcrs()) > 0 {
 		auth = internal.PCRSessionAuth(pcrs, SessionHashAlg)
 	}
+	var policyAuthorityPub []byte
+	if opts.PolicyAuthority != nil {
+		if len(pcrs.GetPcrs()) > 0 {
+			return nil, errors.New("invalid SealOpts: the PCRs of objects sealed with a PolicyAuthority are approved by the authority")
+		}
+		authority, err := AuthorityPublic(opts.PolicyAuthority)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SealOpts: %v", err)
+		}
+		if policyAuthorityPub, err = authority.Encode(); err != nil {
+			return nil, err
+		}
+		if auth, err = policyAuthorizeDigest(authority, opts.PolicyAuthorityRef); err != nil {
+			return nil, err
+		}
+	}
+	var authorityPub []byte
+	if opts.Authority != nil {
+		authority, err := AuthorityPublic(opts.Authority)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SealOpts: %v", err)
+		}
+		if authorityPub, err = authority.Encode(); err != nil {
+			return nil, err
+		}
+		if auth == nil {
+			auth = make([]byte, SessionHashAlg.Size())
+		}
+		if auth, err = policySignedDigest(auth, authority, opts.AuthorityPolicyRef); err != nil {
+			return nil, err
+		}
+	}
 	certifySel := FullPcrSel(CertifyHashAlgTpm)
-	sb, err := sealHelper(k.rw, k.Handle(), auth, sensitive, certifySel)
+
+	sealed := make([]*pb.SealedBytes, 0, len(sensitive))
+	for _, s := range sensitive {
+		sb, err := createSealed(k.rw, k.Handle(), auth, s, certifySel)
+		if err != nil {
+			return nil, err
+		}
+		for pcrNum := range pcrs.GetPcrs() {
+			sb.Pcrs = append(sb.Pcrs, pcrNum)
+		}
+		sb.Hash = pcrs.GetHash()
+		sb.Srk = pb.ObjectType(k.pubArea.Type)
+		sb.AuthorityPub = authorityPub
+		sb.AuthorityPolicyRef = opts.AuthorityPolicyRef
+		sb.PolicyAuthorityPub = policyAuthorityPub
+		sb.PolicyAuthorityRef = opts.PolicyAuthorityRef
+		sealed = append(sealed, sb)
+	}
+
+	// PCRs can only be extended, so if the creation data of every sealed
+	// object matches the PCRs read after the last one, none of them were
+	// altered during the batch.
+	certifiedPcr, err := ReadPCRs(k.rw, certifySel)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to read PCRs: %w", err)
 	}
-
-	for pcrNum := range pcrs.GetPcrs() {
-		sb.Pcrs = append(sb.Pcrs, pcrNum)
+	computedDigest := internal.PCRDigest(certifiedPcr, SessionHashAlg)
+	for _, sb := range sealed {
+		decodedCreationData, err := tpm2.DecodeCreationData(sb.GetCreationData())
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode creation data: %w", err)
+		}
+		// make sure PCRs haven't being altered after sealing
+		if subtle.ConstantTimeCompare(computedDigest, decodedCreationData.PCRDigest) == 0 {
+			return nil, fmt.Errorf("PCRs have been modified after sealing")
+		}
+		sb.CertifiedPcrs = certifiedPcr
 	}
-	sb.Hash = pcrs.GetHash()
-	sb.Srk = pb.ObjectType(k.pubArea.Type)
-	return sb, nil
+	return sealed, nil
 }
 
-func sealHelper(rw io.ReadWriter, parentHandle tpmutil.Handle, auth []byte, sensitive []byte, certifyPCRsSel tpm2.PCRSelection) (*pb.SealedBytes, error) {
+// createSealed creates a sealed object under the parent, without its
+// certified PCRs.
+func createSealed(rw io.ReadWriter, parentHandle tpmutil.Handle, auth []byte, sensitive []byte, certifyPCRsSel tpm2.PCRSelection) (*pb.SealedBytes, error) {
 	inPublic := tpm2.Public{
 		Type:       tpm2.AlgKeyedHash,
 		NameAlg:    SessionHashAlgTpm,
@@ -305,24 +438,8 @@ func sealHelper(rw io.ReadWriter, parentHandle tpmutil.Handle, auth []byte, sens
 	if err != nil {
 		return nil, fmt.Errorf("failed to create key: %w", err)
 	}
-	certifiedPcr, err := ReadPCRs(rw, certifyPCRsSel)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read PCRs: %w", err)
-	}
-	computedDigest := internal.PCRDigest(certifiedPcr, SessionHashAlg)
-
-	decodedCreationData, err := tpm2.DecodeCreationData(creationData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode creation data: %w", err)
-	}
-
-	// make sure PCRs haven't being altered after sealing
-	if subtle.ConstantTimeCompare(computedDigest, decodedCreationData.PCRDigest) == 0 {
-		return nil, fmt.Errorf("PCRs have been modified after sealing")
-	}
 
 	sb := &pb.SealedBytes{}
-	sb.CertifiedPcrs = certifiedPcr
 	sb.Priv = priv
 	sb.Pub = pub
 	sb.CreationData = creationData
@@ -337,6 +454,66 @@ func sealHelper(rw io.ReadWriter, parentHandle tpmutil.Handle, auth []byte, sens
 // be used to verify the state of the TPM when the data was sealed. The
 // zero-value UnsealOpts can be passed to skip certification.
 func (k *Key) Unseal(in *pb.SealedBytes, opts UnsealOpts) ([]byte, error) {
+	u, err := k.newUnsealer(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer u.Close()
+	return u.unseal(in)
+}
+
+// UnsealBatch is like Unseal() for many proto.SealedBytes certified with the
+// same UnsealOpts. A single policy session is used for the whole batch. It
+// fails on the first proto.SealedBytes which cannot be unsealed.
+func (k *Key) UnsealBatch(in []*pb.SealedBytes, opts UnsealOpts) ([][]byte, error) {
+	u, err := k.newUnsealer(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer u.Close()
+
+	sensitive := make([][]byte, 0, len(in))
+	for i, sb := range in {
+		s, err := u.unseal(sb)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unseal item %d: %w", i, err)
+		}
+		sensitive = append(sensitive, s)
+	}
+	return sensitive, nil
+}
+
+// unsealer unseals objects with the TPM resources they can share: a policy
+// session, and the fallback signing key of CertifyCreation. Both are created
+// on first use.
+type unsealer struct {
+	k           *Key
+	certifyPcrs *pb.PCRs
+	session     tpmutil.Handle
+	hasSession  bool
+	signer      *Key
+	opts        UnsealOpts
+}
+
+func (k *Key) newUnsealer(opts UnsealOpts) (*unsealer, error) {
+	pcrs, err := mergePCRSelAndProto(k.rw, opts.CertifyCurrent, opts.CertifyExpected)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UnsealOpts: %v", err)
+	}
+	return &unsealer{k: k, certifyPcrs: pcrs, opts: opts}, nil
+}
+
+func (u *unsealer) Close() {
+	if u.hasSession {
+		tpm2.FlushContext(u.k.rw, u.session)
+	}
+	if u.signer != nil {
+		u.signer.Close()
+	}
+}
+
+func (u *unsealer) unseal(in *pb.SealedBytes) ([]byte, error) {
+	k := u.k
 	if in.Srk != pb.ObjectType(k.pubArea.Type) {
 		return nil, fmt.Errorf("expected key of type %v, got %v", in.Srk, k.pubArea.Type)
 	}
@@ -351,80 +528,125 @@ func (k *Key) Unseal(in *pb.SealedBytes, opts UnsealOpts) ([]byte, error) {
 	}
 	defer tpm2.FlushContext(k.rw, sealed)
 
-	pcrs, err := mergePCRSelAndProto(k.rw, opts.CertifyCurrent, opts.CertifyExpected)
-	if err != nil {
-		return nil, fmt.Errorf("invalid UnsealOpts: %v", err)
-	}
-	if len(pcrs.GetPcrs()) > 0 {
-		if err := internal.CheckSubset(pcrs, in.GetCertifiedPcrs()); err != nil {
-			return nil, fmt.Errorf("failed to certify PCRs: %w", err)
+	if len(u.certifyPcrs.GetPcrs()) > 0 {
+		if err := u.certify(in, sealed); err != nil {
+			return nil, err
 		}
+	}
 
-		var ticket tpm2.Ticket
-		if _, err = tpmutil.Unpack(in.GetTicket(), &ticket); err != nil {
-			return nil, fmt.Errorf("ticket unpack failed: %w", err)
+	sel := tpm2.PCRSelection{Hash: tpm2.Algorithm(in.GetHash())}
+	for _, pcr := range in.GetPcrs() {
+		sel.PCRs = append(sel.PCRs, int(pcr))
+	}
+	if len(in.GetAuthorityPub()) > 0 || len(in.GetPolicyAuthorityPub()) > 0 {
+		sensitive, err := unsealAuthorized(k.rw, in, sealed, sel, u.opts)
+		if err != nil {
+			return nil, checkLockout(err)
 		}
-		creationHash := SessionHashAlg.New()
-		creationHash.Write(in.GetCreationData())
-
-		_, _, certErr := tpm2.CertifyCreation(k.rw, "", sealed, tpm2.HandleNull, nil, creationHash.Sum(nil), tpm2.SigScheme{}, ticket)
-		// There is a bug in some older TPMs, where they are unable to
-		// CertifyCreation when using a Null signing handle (despite this
-		// being allowed by all versions of the TPM spec). To work around
-		// this bug, we use a temporary signing key and ignore the signed
-		// result. To reduce the cost of this workaround, we use a cached
-		// ECC signing key.
-		// We can detect this bug, as it triggers a RCInsufficient
-		// Unmarshaling error.
-		if paramErr, ok := certErr.(tpm2.ParameterError); ok && paramErr.Code == tpm2.RCInsufficient {
-			signer, err := AttestationKeyECC(k.rw)
-			if err != nil {
-				return nil, fmt.Errorf("failed to create fallback signing key: %w", err)
+		return sensitive, nil
+	}
+
+	var s session = nullSession{}
+	if len(sel.PCRs) > 0 {
+		if !u.hasSession {
+			if u.session, err = startAuthSession(k.rw); err != nil {
+				return nil, fmt.Errorf("failed to create session: %w", err)
 			}
-			defer signer.Close()
-			_, _, certErr = tpm2.CertifyCreation(k.rw, "", sealed, signer.Handle(), nil, creationHash.Sum(nil), tpm2.SigScheme{}, ticket)
-		}
-		if certErr != nil {
-			return nil, fmt.Errorf("failed to certify creation: %w", certErr)
+			u.hasSession = true
 		}
+		// The policy digest of the session is reset after each
+		// authorization, so it can be reused with another selection.
+		s = pcrSession{k.rw, u.session, sel}
+	}
+
+	auth, err := s.Auth()
+	if err != nil {
+		return nil, err
+	}
+	sensitive, err := tpm2.UnsealWithSession(k.rw, auth.Session, sealed, "")
+	if err != nil {
+		return nil, checkLockout(err)
+	}
+	return sensitive, nil
+}
 
-		// verify certify PCRs haven't been modified
-		decodedCreationData, err := tpm2.DecodeCreationData(in.GetCreationData())
+// certify checks that the TPM had the certified PCRs of the unsealer when the
+// sealed object was created.
+func (u *unsealer) certify(in *pb.SealedBytes, sealed tpmutil.Handle) error {
+	k := u.k
+	if err := internal.CheckSubset(u.certifyPcrs, in.GetCertifiedPcrs()); err != nil {
+		return fmt.Errorf("failed to certify PCRs: %w", err)
+	}
+
+	var ticket tpm2.Ticket
+	if _, err := tpmutil.Unpack(in.GetTicket(), &ticket); err != nil {
+		return fmt.Errorf("ticket unpack failed: %w", err)
+	}
+	creationHash := SessionHashAlg.New()
+	creationHash.Write(in.GetCreationData())
+
+	signerHandle := tpm2.HandleNull
+	if u.signer != nil {
+		signerHandle = u.signer.Handle()
+	}
+	_, _, certErr := tpm2.CertifyCreation(k.rw, "", sealed, signerHandle, nil, creationHash.Sum(nil), tpm2.SigScheme{}, ticket)
+	// There is a bug in some older TPMs, where they are unable to
+	// CertifyCreation when using a Null signing handle (despite this
+	// being allowed by all versions of the TPM spec). To work around
+	// this bug, we use a temporary signing key and ignore the signed
+	// result. To reduce the cost of this workaround, we use a cached
+	// ECC signing key, shared by the whole batch.
+	// We can detect this bug, as it triggers a RCInsufficient
+	// Unmarshaling error.
+	if paramErr, ok := certErr.(tpm2.ParameterError); ok && paramErr.Code == tpm2.RCInsufficient && u.signer == nil {
+		signer, err := AttestationKeyECC(k.rw)
 		if err != nil {
-			return nil, fmt.Errorf("failed to decode creation data: %w", err)
-		}
-		if !internal.SamePCRSelection(in.GetCertifiedPcrs(), decodedCreationData.PCRSelection) {
-			return nil, fmt.Errorf("certify PCRs does not match the PCR selection in the creation data")
-		}
-		expectedDigest := internal.PCRDigest(in.GetCertifiedPcrs(), SessionHashAlg)
-		if subtle.ConstantTimeCompare(decodedCreationData.PCRDigest, expectedDigest) == 0 {
-			return nil, fmt.Errorf("certify PCRs digest does not match the digest in the creation data")
+			return fmt.Errorf("failed to create fallback signing key: %w", err)
 		}
+		u.signer = signer
+		_, _, certErr = tpm2.CertifyCreation(k.rw, "", sealed, signer.Handle(), nil, creationHash.Sum(nil), tpm2.SigScheme{}, ticket)
 	}
-
-	sel := tpm2.PCRSelection{Hash: tpm2.Algorithm(in.GetHash())}
-	for _, pcr := range in.GetPcrs() {
-		sel.PCRs = append(sel.PCRs, int(pcr))
+	if certErr != nil {
+		return fmt.Errorf("failed to certify creation: %w", certErr)
 	}
 
-	session, err := newPCRSession(k.rw, sel)
+	// verify certify PCRs haven't been modified
+	decodedCreationData, err := tpm2.DecodeCreationData(in.GetCreationData())
 	if err != nil {
-		return nil, fmt.Errorf("failed to create session: %w", err)
+		return fmt.Errorf("failed to decode creation data: %w", err)
 	}
-	defer session.Close()
-
-	auth, err := session.Auth()
-	if err != nil {
-		return nil, err
+	if !internal.SamePCRSelection(in.GetCertifiedPcrs(), decodedCreationData.PCRSelection) {
+		return fmt.Errorf("certify PCRs does not match the PCR selection in the creation data")
 	}
-	return tpm2.UnsealWithSession(k.rw, auth.Session, sealed, "")
+	expectedDigest := internal.PCRDigest(in.GetCertifiedPcrs(), SessionHashAlg)
+	if subtle.ConstantTimeCompare(decodedCreationData.PCRDigest, expectedDigest) == 0 {
+		return fmt.Errorf("certify PCRs digest does not match the digest in the creation data")
+	}
+	return nil
 }
 
 // Quote will tell TPM to compute a hash of a set of given PCR selection, together with
 // some extra data (typically a nonce), sign it with the given signing key, and return
 // the signature and the attestation data. This function will return an error if
 // the key is not a restricted signing key.
+//
+// Deprecated: Use QuoteNonce, which quotes nonces larger than the TPM allows
+// instead of failing, or QuoteData to bind arbitrary data to the quote.
 func (k *Key) Quote(selpcr tpm2.PCRSelection, extraData []byte) (*pb.Quote, error) {
+	return k.quote(selpcr, extraData)
+}
+
+// QuoteNonce is like Quote, with a nonce as the extraData. Nonces larger than
+// the digests of the TPM are quoted as their SHA-256 digest.
+func (k *Key) QuoteNonce(selpcr tpm2.PCRSelection, nonce Nonce) (*pb.Quote, error) {
+	extraData, err := nonce.extraData(k.rw)
+	if err != nil {
+		return nil, err
+	}
+	return k.quote(selpcr, extraData)
+}
+
+func (k *Key) quote(selpcr tpm2.PCRSelection, extraData []byte) (*pb.Quote, error) {
 	// Make sure that we have a valid signing key before trying quote
 	var err error
 	if _, err = internal.GetSigningHashAlg(k.pubArea); err != nil {
@@ -451,6 +673,14 @@ func (k *Key) Quote(selpcr tpm2.PCRSelection, extraData []byte) (*pb.Quote, erro
 	return quote, nil
 }
 
+// QuoteData is like Quote, but binds data of any size to the quote. The TPM
+// limits extraData to the size of its largest digest (at most 64 bytes), so
+// the SHA-256 digest of data is used as the extraData instead. The quote can be
+// verified against the original data with server.VerifyQuoteData.
+func (k *Key) QuoteData(selpcr tpm2.PCRSelection, data []byte) (*pb.Quote, error) {
+	return k.quote(selpcr, internal.QuoteDataDigest(data))
+}
+
 // Reseal is a shortcut to call Unseal() followed by Seal().
 // CertifyOpt(nillable) will be used in Unseal(), and SealOpt(nillable)
 // will be used in Seal()
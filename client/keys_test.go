@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"errors"
 	"io"
 	"math/big"
 	"reflect"
@@ -132,6 +133,38 @@ func TestKeyCreation(t *testing.T) {
 	}
 }
 
+func TestCNSAKeyCreation(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	keys := []struct {
+		name   string
+		getKey func(io.ReadWriter) (*client.Key, error)
+	}{
+		{"EK-ECC-P384", client.EndorsementKeyECCP384},
+		{"AK-ECC-P384", client.AttestationKeyECCP384},
+		{"EK-RSA-3072", client.EndorsementKeyRSA3072},
+		{"AK-RSA-3072", client.AttestationKeyRSA3072},
+	}
+
+	for _, k := range keys {
+		t.Run(k.name, func(t *testing.T) {
+			key, err := k.getKey(rwc)
+			var paramErr tpm2.ParameterError
+			if errors.As(err, &paramErr) && paramErr.Code == tpm2.RCValue {
+				t.Skipf("Key size is not supported by the TPM: %v", err)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer key.Close()
+			if key.PublicArea().NameAlg != tpm2.AlgSHA384 {
+				t.Errorf("got name algorithm %v, want SHA-384", key.PublicArea().NameAlg)
+			}
+		})
+	}
+}
+
 func BenchmarkKeyCreation(b *testing.B) {
 	rwc := test.GetTPM(b)
 	defer client.CheckedClose(b, rwc)
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/go-tpm/tpm2"
+)
+
+// inLockout is the bit of TPMA_PERMANENT set while the TPM is in dictionary
+// attack lockout, from Part 2 of the TPM 2.0 spec, Section 8.6.
+const inLockout = 1 << 9
+
+// LockoutInfo is the state of the dictionary attack (DA) protection of a TPM.
+// After MaxTries authorization failures, the TPM is in lockout: it rejects
+// the authorization values of DA-protected objects, like keys and sealed
+// objects without FlagNoDA.
+type LockoutInfo struct {
+	// InLockout is true if the TPM rejects DA-protected authorizations.
+	InLockout bool
+	// Counter is the number of authorization failures not yet recovered.
+	Counter uint32
+	// MaxTries is the number of failures which puts the TPM in lockout.
+	MaxTries uint32
+	// RecoveryTime is the time after which one failure is forgotten, or zero
+	// if failures are only forgotten when the lockout is reset.
+	RecoveryTime time.Duration
+	// LockoutRecovery is the time to wait after a failed authorization with
+	// the lockout auth, or zero if a reboot is needed.
+	LockoutRecovery time.Duration
+}
+
+// LockoutError is returned by the operations of this package which failed
+// because the TPM is in dictionary attack lockout. It wraps the TPM error.
+// Use ResetLockout, or wait for the RecoveryTime of LockoutInfo, to recover.
+type LockoutError struct {
+	Err error
+}
+
+func (e *LockoutError) Error() string {
+	return fmt.Sprintf("TPM is in dictionary attack lockout: %v", e.Err)
+}
+
+func (e *LockoutError) Unwrap() error {
+	return e.Err
+}
+
+// IsLockout reports whether err is caused by the TPM being in dictionary
+// attack lockout, either as a LockoutError or as the raw TPM warning.
+func IsLockout(err error) bool {
+	var warning tpm2.Warning
+	return errors.As(err, &warning) && warning.Code == tpm2.RCLockout
+}
+
+// checkLockout returns err as a LockoutError if the TPM is in lockout.
+func checkLockout(err error) error {
+	var lockoutErr *LockoutError
+	if IsLockout(err) && !errors.As(err, &lockoutErr) {
+		return &LockoutError{Err: err}
+	}
+	return err
+}
+
+// GetLockoutInfo returns the dictionary attack protection state of the TPM.
+// It needs no authorization.
+func GetLockoutInfo(rw io.ReadWriter) (*LockoutInfo, error) {
+	props, err := getTPMProperties(rw, tpm2.TPMAPermanent, tpm2.LockoutRecovery)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tag := range []tpm2.TPMProp{tpm2.TPMAPermanent, tpm2.LockoutCounter, tpm2.MaxAuthFail, tpm2.LockoutInterval, tpm2.LockoutRecovery} {
+		if _, ok := props[tag]; !ok {
+			return nil, fmt.Errorf("TPM did not return property 0x%x", uint32(tag))
+		}
+	}
+	return &LockoutInfo{
+		InLockout:       props[tpm2.TPMAPermanent]&inLockout != 0,
+		Counter:         props[tpm2.LockoutCounter],
+		MaxTries:        props[tpm2.MaxAuthFail],
+		RecoveryTime:    time.Duration(props[tpm2.LockoutInterval]) * time.Second,
+		LockoutRecovery: time.Duration(props[tpm2.LockoutRecovery]) * time.Second,
+	}, nil
+}
+
+// ResetLockout sets the failure counter to zero, taking the TPM out of
+// lockout. It needs the lockout auth, empty by default. Note that a failed
+// authorization with the lockout auth blocks it for the LockoutRecovery.
+func ResetLockout(rw io.ReadWriter, lockoutAuth string) error {
+	auth := tpm2.AuthCommand{Session: tpm2.HandlePasswordSession, Auth: []byte(lockoutAuth)}
+	if err := tpm2.DictionaryAttackLockReset(rw, auth); err != nil {
+		return fmt.Errorf("failed to reset the lockout: %w", err)
+	}
+	return nil
+}
+
+// SetLockoutParameters changes the MaxTries, RecoveryTime and LockoutRecovery
+// of the dictionary attack protection, with a resolution of one second. It
+// needs the lockout auth, like ResetLockout.
+func SetLockoutParameters(rw io.ReadWriter, lockoutAuth string, maxTries uint32, recoveryTime, lockoutRecovery time.Duration) error {
+	if recoveryTime < 0 || lockoutRecovery < 0 {
+		return errors.New("lockout recovery times must not be negative")
+	}
+	auth := tpm2.AuthCommand{Session: tpm2.HandlePasswordSession, Auth: []byte(lockoutAuth)}
+	err := tpm2.DictionaryAttackParameters(rw, auth, maxTries,
+		uint32(recoveryTime/time.Second), uint32(lockoutRecovery/time.Second))
+	if err != nil {
+		return fmt.Errorf("failed to set the lockout parameters: %w", err)
+	}
+	return nil
+}
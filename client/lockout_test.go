@@ -0,0 +1,91 @@
+package client_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm/tpm2"
+)
+
+func TestLockoutParameters(t *testing.T) {
+	test.SkipForRealTPM(t)
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	if err := client.SetLockoutParameters(rwc, "", 5, time.Hour, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	info, err := client.GetLockoutInfo(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := client.LockoutInfo{MaxTries: 5, RecoveryTime: time.Hour, LockoutRecovery: 10 * time.Second}
+	if *info != want {
+		t.Errorf("GetLockoutInfo() = %+v, want %+v", *info, want)
+	}
+
+	if err := client.SetLockoutParameters(rwc, "wrong", 5, time.Hour, 0); err == nil {
+		t.Error("SetLockoutParameters() succeeded with the wrong lockout auth")
+	}
+}
+
+func TestLockout(t *testing.T) {
+	test.SkipForRealTPM(t)
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	const maxTries = 2
+	if err := client.SetLockoutParameters(rwc, "", maxTries, time.Hour, 0); err != nil {
+		t.Fatal(err)
+	}
+	srk, err := client.StorageRootKeyECC(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srk.Close()
+	sealed, err := srk.Seal([]byte("secret"), client.SealOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Fail to authorize a DA-protected key until the TPM is in lockout.
+	handle, _, err := tpm2.CreatePrimary(rwc, tpm2.HandleOwner, tpm2.PCRSelection{}, "", "password", client.AKTemplateECC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpm2.FlushContext(rwc, handle)
+	for i := 0; i < maxTries; i++ {
+		if _, err := tpm2.Sign(rwc, handle, "wrong", make([]byte, 32), nil, nil); err == nil {
+			t.Fatal("Sign() succeeded with the wrong password")
+		}
+	}
+
+	info, err := client.GetLockoutInfo(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.InLockout || info.Counter != maxTries {
+		t.Errorf("GetLockoutInfo() = %+v, want in lockout after %d failures", *info, maxTries)
+	}
+	_, err = srk.Unseal(sealed, client.UnsealOpts{})
+	var lockoutErr *client.LockoutError
+	if !errors.As(err, &lockoutErr) || !client.IsLockout(err) {
+		t.Fatalf("Unseal() in lockout returned %v, want a LockoutError", err)
+	}
+
+	if err := client.ResetLockout(rwc, ""); err != nil {
+		t.Fatal(err)
+	}
+	if info, err = client.GetLockoutInfo(rwc); err != nil {
+		t.Fatal(err)
+	}
+	if info.InLockout || info.Counter != 0 {
+		t.Errorf("GetLockoutInfo() after ResetLockout() = %+v, want no failures", *info)
+	}
+	if _, err := srk.Unseal(sealed, client.UnsealOpts{}); err != nil {
+		t.Errorf("Unseal() after ResetLockout() failed: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"fmt"
+	"io"
+
+	"github.com/google/go-tpm-tools/internal"
+	"github.com/google/go-tpm/tpm2"
+)
+
+// MinNonceSize is the smallest nonce accepted by the Nonce constructors, so
+// that nonces cannot be guessed.
+const MinNonceSize = 16
+
+// tlsNonceSize is the size of the nonces exported from TLS connections.
+const tlsNonceSize = 32
+
+// Nonce is a nonce quoted by a TPM to guarantee the freshness of a quote or
+// an attestation, usually received from the verifier. Use its constructors
+// rather than converting arbitrary bytes, and see AttestOpts.Nonce on binding
+// nonces to the application.
+//
+// The TPM limits the extraData of a quote to the size of its largest digest,
+// so larger nonces are quoted as their SHA-256 digest, which verifiers using
+// server.VerifyAttestation accept.
+type Nonce []byte
+
+// NewRandomNonce returns a nonce of size random bytes, at least MinNonceSize.
+func NewRandomNonce(size int) (Nonce, error) {
+	if size < MinNonceSize {
+		return nil, fmt.Errorf("nonce size %d is smaller than %d bytes", size, MinNonceSize)
+	}
+	nonce := make(Nonce, size)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate a nonce: %w", err)
+	}
+	return nonce, nil
+}
+
+// NonceFromChallenge returns the nonce of a challenge of a verifier, like the
+// Challenge proto of the verifier package.
+func NonceFromChallenge(challenge interface{ GetNonce() []byte }) (Nonce, error) {
+	nonce := Nonce(challenge.GetNonce())
+	if err := nonce.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid challenge: %w", err)
+	}
+	return nonce, nil
+}
+
+// NonceFromTLS returns a nonce bound to a TLS connection, exported from its
+// keying material with label (see RFC 5705). Both ends of the connection
+// export the same nonce, so a verifier on the other end knows the attestation
+// was made for this connection. Unregistered labels should start with
+// "EXPERIMENTAL".
+func NonceFromTLS(state *tls.ConnectionState, label string) (Nonce, error) {
+	nonce, err := state.ExportKeyingMaterial(label, nil, tlsNonceSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export keying material: %w", err)
+	}
+	return nonce, nil
+}
+
+// Validate checks that the nonce is at least MinNonceSize bytes.
+func (n Nonce) Validate() error {
+	if len(n) < MinNonceSize {
+		return fmt.Errorf("nonce size %d is smaller than %d bytes", len(n), MinNonceSize)
+	}
+	return nil
+}
+
+// extraData returns the extraData quoting the nonce with the TPM: the nonce
+// itself, or its SHA-256 digest if it is larger than the digests of the TPM.
+func (n Nonce) extraData(rw io.ReadWriter) ([]byte, error) {
+	vals, _, err := tpm2.GetCapability(rw, tpm2.CapabilityTPMProperties, 1, uint32(tpm2.DigestMaxSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the TPM max digest size: %w", err)
+	}
+	if len(vals) == 0 {
+		return nil, fmt.Errorf("TPM did not return property 0x%x", uint32(tpm2.DigestMaxSize))
+	}
+	prop, ok := vals[0].(tpm2.TaggedProperty)
+	if !ok {
+		return nil, fmt.Errorf("unable to assert type tpm2.TaggedProperty of value %#v", vals[0])
+	}
+	if prop.Tag != tpm2.DigestMaxSize {
+		return nil, fmt.Errorf("TPM did not return property 0x%x", uint32(tpm2.DigestMaxSize))
+	}
+	return internal.NonceExtraData(n, int(prop.Value)), nil
+}
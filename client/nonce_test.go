@@ -0,0 +1,115 @@
+package client_test
+
+import (
+	"bytes"
+	"crypto"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm-tools/server"
+	"github.com/google/go-tpm/tpm2"
+)
+
+type testChallenge []byte
+
+func (c testChallenge) GetNonce() []byte { return c }
+
+func TestNewRandomNonce(t *testing.T) {
+	nonce, err := client.NewRandomNonce(32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nonce) != 32 {
+		t.Errorf("got a nonce of %d bytes, want 32", len(nonce))
+	}
+	if _, err := client.NewRandomNonce(client.MinNonceSize - 1); err == nil {
+		t.Error("NewRandomNonce() succeeded with a size smaller than MinNonceSize")
+	}
+}
+
+func TestNonceFromChallenge(t *testing.T) {
+	challenge := testChallenge(bytes.Repeat([]byte{1}, client.MinNonceSize))
+	nonce, err := client.NonceFromChallenge(challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(nonce, challenge) {
+		t.Errorf("got nonce %x, want %x", nonce, challenge)
+	}
+	if _, err := client.NonceFromChallenge(testChallenge("short")); err == nil {
+		t.Error("NonceFromChallenge() succeeded with a short nonce")
+	}
+}
+
+func TestNonceFromTLS(t *testing.T) {
+	const label = "EXPERIMENTAL go-tpm-tools test"
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce, err := client.NonceFromTLS(r.TLS, label)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(nonce)
+	}))
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	serverNonce, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("server failed to export the nonce: %s", serverNonce)
+	}
+	nonce, err := client.NonceFromTLS(resp.TLS, label)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nonce.Validate(); err != nil {
+		t.Error(err)
+	}
+	if !bytes.Equal(nonce, serverNonce) {
+		t.Errorf("client nonce %x differs from server nonce %x", nonce, serverNonce)
+	}
+}
+
+func TestQuoteNonceOversized(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	ak, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+
+	// Larger than the digests of any TPM.
+	nonce := client.Nonce(bytes.Repeat([]byte("nonce"), 20))
+	quote, err := ak.QuoteNonce(tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{test.DebugPCR}}, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := internal.VerifyQuote(quote, ak.PublicKey(), internal.QuoteDataDigest(nonce)); err != nil {
+		t.Errorf("oversized nonce was not quoted as its digest: %v", err)
+	}
+	if err := internal.VerifyQuoteNonce(quote, ak.PublicKey(), nonce); err != nil {
+		t.Error(err)
+	}
+
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.VerifyAttestation(attestation, server.VerifyOpts{Nonce: nonce, TrustedAKs: []crypto.PublicKey{ak.PublicKey()}}); err != nil {
+		t.Errorf("failed to verify an attestation with an oversized nonce: %v", err)
+	}
+}
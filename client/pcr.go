@@ -108,6 +108,23 @@ type SealOpts struct {
 	Current tpm2.PCRSelection
 	// Target predictively seals data to the given specified PCR values.
 	Target *pb.PCRs
+	// Authority additionally requires a signed authorization of the holder
+	// of this public key to unseal, checked with TPM2_PolicySigned. The
+	// authority can revoke access to the sealed data by refusing to sign. See
+	// AuthorityPublic for the supported keys.
+	Authority crypto.PublicKey
+	// AuthorityPolicyRef is signed by the authority along with the nonce of
+	// the unsealing session, to scope its authorizations.
+	AuthorityPolicyRef []byte
+	// PolicyAuthority replaces the PCRs of Current and Target (which must be
+	// empty) with the PCR values approved by the holder of this public key,
+	// checked with TPM2_PolicyAuthorize. The data can be unsealed with new
+	// PCR values, e.g. after an update, once the authority approved them.
+	// See AuthorityPublic for the supported keys.
+	PolicyAuthority crypto.PublicKey
+	// PolicyAuthorityRef is signed by the policy authority along with the
+	// PCR values it approves, to scope its approvals.
+	PolicyAuthorityRef []byte
 }
 
 // UnsealOpts specifies the options that should be used for Unseal().
@@ -121,6 +138,16 @@ type UnsealOpts struct {
 	CertifyCurrent tpm2.PCRSelection
 	// CertifyExpected certifies that the TPM had a specific set of PCR values when sealing.
 	CertifyExpected *pb.PCRs
+	// Authorize gets the signed authorization of the authority of objects
+	// sealed with SealOpts.Authority, for the nonce of the unsealing session
+	// and the policy reference of the object. See
+	// server.SignPolicyAuthorization.
+	Authorize func(nonceTPM, policyRef []byte) (*pb.PolicyAuthorization, error)
+	// PolicyApprovals are the PCR values approved by the policy authority of
+	// objects sealed with SealOpts.PolicyAuthority. The first approval for the
+	// policy reference of the object approving the current PCR values is
+	// used. See server.SignPolicyApproval.
+	PolicyApprovals []*pb.PolicyApproval
 }
 
 // FullPcrSel will return a full PCR selection based on the total PCR number
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm/tpm2"
+)
+
+// PCRPreset names a set of PCRs commonly used together, so callers need not
+// copy the PCR indexes around. Use Selection to select them in a PCR bank.
+type PCRPreset int
+
+// The PCR presets.
+const (
+	// SecureBootPCRs is PCR 7, measuring the UEFI Secure Boot policy and the
+	// authorities used to verify the boot components.
+	SecureBootPCRs PCRPreset = iota
+	// BootIntegrityPCRs are PCRs 0-9, measuring the firmware, its
+	// configuration, the boot loader, and the commands and files of the boot
+	// loader, including the kernel.
+	BootIntegrityPCRs
+	// COSWorkloadPCRs are the PCRs extended by the Confidential Space launcher
+	// with the workload: cel.CosImagePCR and cel.CosEventPCR.
+	COSWorkloadPCRs
+	// AllAvailable is all the PCRs allocated by the TPM in the bank.
+	AllAvailable
+)
+
+var presetPCRs = map[PCRPreset][]int{
+	SecureBootPCRs:    {7},
+	BootIntegrityPCRs: {0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+	COSWorkloadPCRs:   {cel.CosImagePCR, cel.CosEventPCR},
+}
+
+func (p PCRPreset) String() string {
+	switch p {
+	case SecureBootPCRs:
+		return "SecureBootPCRs"
+	case BootIntegrityPCRs:
+		return "BootIntegrityPCRs"
+	case COSWorkloadPCRs:
+		return "COSWorkloadPCRs"
+	case AllAvailable:
+		return "AllAvailable"
+	}
+	return fmt.Sprintf("PCRPreset(%d)", int(p))
+}
+
+// Selection returns the selection of the PCRs of the preset in the bank of
+// hash, checking that the TPM allocates the bank and all the PCRs.
+func (p PCRPreset) Selection(rw io.ReadWriter, hash tpm2.Algorithm) (tpm2.PCRSelection, error) {
+	if p == AllAvailable {
+		allocated, err := allocatedBank(rw, hash)
+		if err != nil {
+			return tpm2.PCRSelection{}, err
+		}
+		return allocated, nil
+	}
+	pcrs, ok := presetPCRs[p]
+	if !ok {
+		return tpm2.PCRSelection{}, fmt.Errorf("unknown PCR preset: %v", p)
+	}
+	sel := tpm2.PCRSelection{Hash: hash, PCRs: append([]int(nil), pcrs...)}
+	if err := ValidatePCRSelection(rw, sel); err != nil {
+		return tpm2.PCRSelection{}, fmt.Errorf("%v: %w", p, err)
+	}
+	return sel, nil
+}
+
+// ValidatePCRSelection checks that the TPM allocates the bank of sel, and all
+// the PCRs it selects in the bank.
+func ValidatePCRSelection(rw io.ReadWriter, sel tpm2.PCRSelection) error {
+	allocated, err := allocatedBank(rw, sel.Hash)
+	if err != nil {
+		return err
+	}
+	isAllocated := make(map[int]bool, len(allocated.PCRs))
+	for _, pcr := range allocated.PCRs {
+		isAllocated[pcr] = true
+	}
+	for _, pcr := range sel.PCRs {
+		if !isAllocated[pcr] {
+			return fmt.Errorf("PCR %d is not allocated in the %v bank", pcr, sel.Hash)
+		}
+	}
+	return nil
+}
+
+// allocatedBank returns the selection of the PCRs allocated by the TPM in the
+// bank of hash.
+func allocatedBank(rw io.ReadWriter, hash tpm2.Algorithm) (tpm2.PCRSelection, error) {
+	sels, err := allocatedPCRs(rw)
+	if err != nil {
+		return tpm2.PCRSelection{}, err
+	}
+	for _, sel := range sels {
+		if sel.Hash == hash {
+			return sel, nil
+		}
+	}
+	return tpm2.PCRSelection{}, fmt.Errorf("PCR bank %v is not allocated by the TPM", hash)
+}
@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"crypto/sha512"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/google/go-tpm-tools/client"
@@ -125,3 +126,56 @@ func TestCheckContainedPCRs(t *testing.T) {
 		t.Fatalf("empty pcrs is always validate")
 	}
 }
+
+func TestPCRPresetSelection(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	for _, tc := range []struct {
+		preset client.PCRPreset
+		want   []int
+	}{
+		{client.SecureBootPCRs, []int{7}},
+		{client.BootIntegrityPCRs, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{client.COSWorkloadPCRs, []int{12, 13}},
+	} {
+		t.Run(tc.preset.String(), func(t *testing.T) {
+			sel, err := tc.preset.Selection(rwc, tpm2.AlgSHA256)
+			if err != nil {
+				t.Fatalf("Selection() failed: %v", err)
+			}
+			if sel.Hash != tpm2.AlgSHA256 || !reflect.DeepEqual(sel.PCRs, tc.want) {
+				t.Errorf("Selection() = %v, want PCRs %v in the SHA256 bank", sel, tc.want)
+			}
+		})
+	}
+
+	all, err := client.AllAvailable.Selection(rwc, tpm2.AlgSHA256)
+	if err != nil {
+		t.Fatalf("Selection() failed: %v", err)
+	}
+	if len(all.PCRs) < client.NumPCRs {
+		t.Errorf("AllAvailable selected %d PCRs, want at least %d", len(all.PCRs), client.NumPCRs)
+	}
+	if _, err := client.SecureBootPCRs.Selection(rwc, tpm2.AlgSHA3_256); err == nil {
+		t.Error("Selection() succeeded for an unallocated bank")
+	}
+	if _, err := client.PCRPreset(42).Selection(rwc, tpm2.AlgSHA256); err == nil {
+		t.Error("Selection() succeeded for an unknown preset")
+	}
+}
+
+func TestValidatePCRSelection(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	if err := client.ValidatePCRSelection(rwc, client.FullPcrSel(tpm2.AlgSHA1)); err != nil {
+		t.Errorf("ValidatePCRSelection() failed for all the SHA1 PCRs: %v", err)
+	}
+	if err := client.ValidatePCRSelection(rwc, tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{7, 42}}); err == nil {
+		t.Error("ValidatePCRSelection() succeeded for an unimplemented PCR")
+	}
+	if err := client.ValidatePCRSelection(rwc, tpm2.PCRSelection{Hash: tpm2.AlgSHA3_256, PCRs: []int{7}}); err == nil {
+		t.Error("ValidatePCRSelection() succeeded for an unallocated bank")
+	}
+}
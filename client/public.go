@@ -0,0 +1,120 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+// PublicCache caches the public areas of the TPM objects read with
+// tpm2.ReadPublic, so their names can be computed without further TPM calls.
+// Handles of transient objects are reused by the TPM once flushed, and
+// persistent handles can be evicted, so callers must Invalidate a handle when
+// its object goes away. A PublicCache is safe for concurrent use.
+type PublicCache struct {
+	rw io.ReadWriter
+
+	mu      sync.Mutex
+	publics map[tpmutil.Handle]tpm2.Public
+}
+
+// NewPublicCache returns an empty PublicCache reading from the TPM rw.
+func NewPublicCache(rw io.ReadWriter) *PublicCache {
+	return &PublicCache{rw: rw, publics: make(map[tpmutil.Handle]tpm2.Public)}
+}
+
+// ReadPublic returns the public area of the object at handle, only reading it
+// from the TPM if it is not cached.
+func (c *PublicCache) ReadPublic(handle tpmutil.Handle) (tpm2.Public, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if pub, ok := c.publics[handle]; ok {
+		return pub, nil
+	}
+	pub, _, _, err := tpm2.ReadPublic(c.rw, handle)
+	if err != nil {
+		return tpm2.Public{}, fmt.Errorf("reading the public area of handle 0x%x: %w", handle, err)
+	}
+	c.publics[handle] = pub
+	return pub, nil
+}
+
+// Add caches pub as the public area of the object at handle, e.g. one just
+// returned by tpm2.CreatePrimary or tpm2.Load.
+func (c *PublicCache) Add(handle tpmutil.Handle, pub tpm2.Public) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.publics[handle] = pub
+}
+
+// Invalidate removes the public area of the object at handle from the cache.
+func (c *PublicCache) Invalidate(handle tpmutil.Handle) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.publics, handle)
+}
+
+// Name returns the TPM name of the entity at handle. The names of PCRs,
+// permanent handles and sessions are their handles, and need no TPM call. The
+// names of transient and persistent objects are computed from their public
+// areas, see ReadPublic. NV indexes are not supported.
+func (c *PublicCache) Name(handle tpmutil.Handle) (tpm2.Name, error) {
+	if name, ok := handleName(handle); ok {
+		return name, nil
+	}
+	switch tpm2.HandleType(handle >> 24) {
+	case tpm2.HandleTypeTransient, tpm2.HandleTypePersistent:
+	default:
+		return tpm2.Name{}, fmt.Errorf("cannot compute the name of handle 0x%x", handle)
+	}
+	pub, err := c.ReadPublic(handle)
+	if err != nil {
+		return tpm2.Name{}, err
+	}
+	return pub.Name()
+}
+
+// handleName returns the name of handle if it is the handle itself, which is
+// the case of PCRs, permanent handles and sessions (Part 1, Section 16).
+func handleName(handle tpmutil.Handle) (tpm2.Name, bool) {
+	switch tpm2.HandleType(handle >> 24) {
+	case tpm2.HandleTypePCR, tpm2.HandleTypePermanent, tpm2.HandleTypeHMACSession, tpm2.HandleTypePolicySession:
+		return tpm2.Name{Handle: &handle}, true
+	}
+	return tpm2.Name{}, false
+}
+
+// NamesEqual reports whether the names a and b are the same, comparing their
+// encodings.
+func NamesEqual(a, b tpm2.Name) bool {
+	aEncoded, err := a.Encode()
+	if err != nil {
+		return false
+	}
+	bEncoded, err := b.Encode()
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(aEncoded, bEncoded)
+}
+
+// CheckName returns an error if the name of the key is not expected, e.g. the
+// name a credential was made for, or a duplicated key was expected to have.
+func (k *Key) CheckName(expected tpm2.Name) error {
+	if NamesEqual(k.name, expected) {
+		return nil
+	}
+	got, err := k.name.Encode()
+	if err != nil {
+		return err
+	}
+	want, err := expected.Encode()
+	if err != nil {
+		return fmt.Errorf("invalid expected name: %w", err)
+	}
+	return fmt.Errorf("key name %x does not match the expected name %x", got, want)
+}
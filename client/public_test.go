@@ -0,0 +1,88 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+func TestPublicCache(t *testing.T) {
+	readPublics := 0
+	rwc := client.NewTransport(test.GetTPM(t), func(c *client.Command) {
+		if c.Code == tpm2.CmdReadPublic {
+			readPublics++
+		}
+	})
+	defer client.CheckedClose(t, rwc)
+
+	srk, err := client.StorageRootKeyECC(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srk.Close()
+
+	cache := client.NewPublicCache(rwc)
+	readPublics = 0
+	for i := 0; i < 3; i++ {
+		name, err := cache.Name(srk.Handle())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !client.NamesEqual(name, srk.Name()) {
+			t.Errorf("cached name %v does not match the key name %v", name, srk.Name())
+		}
+	}
+	if readPublics != 1 {
+		t.Errorf("got %d TPM2_ReadPublic commands, want 1", readPublics)
+	}
+
+	cache.Invalidate(srk.Handle())
+	if _, err := cache.ReadPublic(srk.Handle()); err != nil {
+		t.Fatal(err)
+	}
+	if readPublics != 2 {
+		t.Errorf("got %d TPM2_ReadPublic commands after Invalidate, want 2", readPublics)
+	}
+
+	// The names of permanent handles and PCRs are their handles.
+	for _, handle := range []tpmutil.Handle{tpm2.HandleOwner, tpmutil.Handle(7)} {
+		name, err := cache.Name(handle)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name.Handle == nil || *name.Handle != handle {
+			t.Errorf("got name %v for handle 0x%x, want the handle", name, handle)
+		}
+	}
+	if readPublics != 2 {
+		t.Errorf("got %d TPM2_ReadPublic commands for handle names, want none", readPublics-2)
+	}
+}
+
+func TestKeyCheckName(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ek, err := client.EndorsementKeyECC(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ek.Close()
+	ak, err := client.AttestationKeyECC(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+
+	if err := ek.CheckName(ek.Name()); err != nil {
+		t.Errorf("CheckName() failed for its own name: %v", err)
+	}
+	if err := ek.CheckName(ak.Name()); err == nil {
+		t.Error("CheckName() succeeded for the name of another key")
+	}
+	if client.NamesEqual(ek.Name(), tpm2.Name{}) {
+		t.Error("NamesEqual() reported a key name equal to the empty name")
+	}
+}
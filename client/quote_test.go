@@ -4,12 +4,14 @@ import (
 	"bytes"
 	"crypto/ecdsa"
 	"crypto/rsa"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"testing"
 
 	"github.com/google/go-attestation/attest"
 	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal"
 	"github.com/google/go-tpm-tools/internal/test"
 	"github.com/google/go-tpm/tpm2"
 )
@@ -104,6 +106,78 @@ func TestQuoteShouldFailWithNonSigningKey(t *testing.T) {
 	t.Log(err)
 }
 
+func TestQuoteNonce(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	keys := []struct {
+		name   string
+		getKey func(io.ReadWriter) (*client.Key, error)
+	}{
+		{"AK-ECC", client.AttestationKeyECC},
+		{"AK-RSA", client.AttestationKeyRSA},
+	}
+	nonceSizes := []int{client.MinNonceSize, sha256.Size, 100}
+	sel := tpm2.PCRSelection{
+		Hash: tpm2.AlgSHA256,
+		PCRs: []int{7},
+	}
+
+	for _, key := range keys {
+		for _, size := range nonceSizes {
+			t.Run(fmt.Sprintf("%s-%d", key.name, size), func(t *testing.T) {
+				ak, err := key.getKey(rwc)
+				if err != nil {
+					t.Fatalf("failed to generate AK: %v", err)
+				}
+				defer ak.Close()
+
+				nonce, err := client.NewRandomNonce(size)
+				if err != nil {
+					t.Fatal(err)
+				}
+				quoted, err := ak.QuoteNonce(sel, nonce)
+				if err != nil {
+					t.Fatalf("failed to quote: %v", err)
+				}
+				if err := internal.VerifyQuoteNonce(quoted, ak.PublicKey(), nonce); err != nil {
+					t.Errorf("failed to verify the quote: %v", err)
+				}
+				otherNonce, err := client.NewRandomNonce(size)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := internal.VerifyQuoteNonce(quoted, ak.PublicKey(), otherNonce); err == nil {
+					t.Error("verified the quote with a different nonce")
+				}
+			})
+		}
+	}
+}
+
+func TestQuoteNonceShouldFailWithNonSigningKey(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	srk, err := client.StorageRootKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate SRK: %v", err)
+	}
+	defer srk.Close()
+
+	selpcr := tpm2.PCRSelection{
+		Hash: tpm2.AlgSHA1,
+		PCRs: []int{7},
+	}
+	nonce, err := client.NewRandomNonce(client.MinNonceSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srk.QuoteNonce(selpcr, nonce); err == nil {
+		t.Errorf("QuoteNonce with a non-signing key should fail")
+	}
+}
+
 // Basic tests of Key.Attest, more advanced methods are in server package
 func TestAttest(t *testing.T) {
 	rwc := test.GetTPM(t)
@@ -2,8 +2,15 @@ package client_test
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/sha256"
+	"encoding/asn1"
 	"io"
+	"math/big"
 	"reflect"
 	"testing"
 
@@ -11,6 +18,7 @@ import (
 	"github.com/google/go-tpm/tpmutil"
 
 	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal"
 	"github.com/google/go-tpm-tools/internal/test"
 	pb "github.com/google/go-tpm-tools/proto/tpm"
 )
@@ -69,6 +77,67 @@ func TestSeal(t *testing.T) {
 	}
 }
 
+func TestSealBatch(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	srk, err := client.StorageRootKeyECC(rwc)
+	if err != nil {
+		t.Fatalf("can't create srk from template: %v", err)
+	}
+	defer srk.Close()
+
+	secrets := [][]byte{[]byte("tenant-1"), []byte("tenant-2"), []byte("tenant-3")}
+	pcrToChange := test.DebugPCR
+	sel := tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{7, pcrToChange}}
+	sealed, err := srk.SealBatch(secrets, client.SealOpts{Current: sel})
+	if err != nil {
+		t.Fatalf("failed to seal batch: %v", err)
+	}
+	if len(sealed) != len(secrets) {
+		t.Fatalf("SealBatch returned %d sealed secrets, want %d", len(sealed), len(secrets))
+	}
+	// Secrets sealed with other policies can be unsealed in the same batch.
+	unsealedPolicy, err := srk.Seal([]byte("no-policy"), client.SealOpts{})
+	if err != nil {
+		t.Fatalf("failed to seal: %v", err)
+	}
+	sealed = append(sealed, unsealedPolicy)
+	secrets = append(secrets, []byte("no-policy"))
+
+	opts := client.UnsealOpts{
+		CertifyCurrent: tpm2.PCRSelection{
+			Hash: tpm2.AlgSHA256,
+			PCRs: []int{7},
+		},
+	}
+	unsealed, err := srk.UnsealBatch(sealed, opts)
+	if err != nil {
+		t.Fatalf("failed to unseal batch: %v", err)
+	}
+	if !reflect.DeepEqual(unsealed, secrets) {
+		t.Fatalf("unsealed (%q) not equal to secrets (%q)", unsealed, secrets)
+	}
+	// Each secret of the batch can also be unsealed on its own.
+	unseal, err := srk.Unseal(sealed[1], opts)
+	if err != nil {
+		t.Fatalf("failed to unseal: %v", err)
+	}
+	if !bytes.Equal(unseal, secrets[1]) {
+		t.Fatalf("unsealed (%v) not equal to secret (%v)", unseal, secrets[1])
+	}
+
+	extension := bytes.Repeat([]byte{0xAA}, sha256.Size)
+	if err = tpm2.PCRExtend(rwc, tpmutil.Handle(pcrToChange), tpm2.AlgSHA256, extension, ""); err != nil {
+		t.Fatalf("failed to extend pcr: %v", err)
+	}
+
+	// unseal should not succeed.
+	if _, err = srk.UnsealBatch(sealed, opts); err == nil {
+		t.Fatalf("unseal batch should have caused an error")
+	}
+}
+
 func TestSelfReseal(t *testing.T) {
 	rwc := test.GetTPM(t)
 	defer client.CheckedClose(t, rwc)
@@ -339,6 +408,32 @@ func BenchmarkSeal(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkSealBatch(b *testing.B) {
+	rwc := test.GetTPM(b)
+	defer client.CheckedClose(b, rwc)
+
+	key, err := client.StorageRootKeyECC(rwc)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer key.Close()
+	pcrSel7 := tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{7}}
+	secrets := make([][]byte, 100)
+	for i := range secrets {
+		secrets[i] = []byte("test123")
+	}
+	for i := 0; i < b.N; i++ {
+		blobs, err := key.SealBatch(secrets, client.SealOpts{Current: pcrSel7})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err = key.UnsealBatch(blobs, client.UnsealOpts{CertifyCurrent: pcrSel7}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestSealOpts(t *testing.T) {
 	rwc := test.GetTPM(t)
 	defer client.CheckedClose(t, rwc)
@@ -458,3 +553,204 @@ func TestSealAndUnsealOptsFail(t *testing.T) {
 		})
 	}
 }
+
+// signTPM returns the TPMT_SIGNATURE of signer over a digest of
+// client.AuthorityHashAlg.
+func signTPM(signer crypto.Signer, digest []byte) ([]byte, error) {
+	sig, err := signer.Sign(rand.Reader, digest, client.AuthorityHashAlg)
+	if err != nil {
+		return nil, err
+	}
+	tpmSig := tpm2.Signature{Alg: tpm2.AlgRSASSA, RSA: &tpm2.SignatureRSA{HashAlg: tpm2.AlgSHA256, Signature: sig}}
+	if _, ok := signer.Public().(*ecdsa.PublicKey); ok {
+		var ecdsaSig struct{ R, S *big.Int }
+		if _, err := asn1.Unmarshal(sig, &ecdsaSig); err != nil {
+			return nil, err
+		}
+		tpmSig = tpm2.Signature{Alg: tpm2.AlgECDSA, ECC: &tpm2.SignatureECC{HashAlg: tpm2.AlgSHA256, R: ecdsaSig.R, S: ecdsaSig.S}}
+	}
+	return tpmSig.Encode()
+}
+
+// signAuthorization signs the TPM2_PolicySigned authorization of nonceTPM
+// and policyRef, without expiration.
+func signAuthorization(signer crypto.Signer, nonceTPM, policyRef []byte) (*pb.PolicyAuthorization, error) {
+	hash := client.AuthorityHashAlg.New()
+	hash.Write(nonceTPM)
+	hash.Write([]byte{0, 0, 0, 0})
+	hash.Write(policyRef)
+	sig, err := signTPM(signer, hash.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PolicyAuthorization{NonceTpm: nonceTPM, PolicyRef: policyRef, Signature: sig}, nil
+}
+
+// signApproval signs the TPM2_PolicyAuthorize approval of the PCR policy of
+// pcrs and policyRef.
+func signApproval(t *testing.T, signer crypto.Signer, pcrs *pb.PCRs, policyRef []byte) *pb.PolicyApproval {
+	t.Helper()
+	hash := client.AuthorityHashAlg.New()
+	hash.Write(internal.PCRSessionAuth(pcrs, client.SessionHashAlg))
+	hash.Write(policyRef)
+	sig, err := signTPM(signer, hash.Sum(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &pb.PolicyApproval{Pcrs: pcrs, PolicyRef: policyRef, Signature: sig}
+}
+
+func TestSealAuthority(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	srk, err := client.StorageRootKeyECC(rwc)
+	if err != nil {
+		t.Fatalf("can't create srk from template: %v", err)
+	}
+	defer srk.Close()
+
+	rsaAuthority, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaAuthority, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherAuthority, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorize := func(signer crypto.Signer) func(nonceTPM, policyRef []byte) (*pb.PolicyAuthorization, error) {
+		return func(nonceTPM, policyRef []byte) (*pb.PolicyAuthorization, error) {
+			return signAuthorization(signer, nonceTPM, policyRef)
+		}
+	}
+	sel := tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{7}}
+	policyRef := []byte("tenant-1")
+
+	for _, authority := range []crypto.Signer{rsaAuthority, ecdsaAuthority} {
+		for _, current := range []tpm2.PCRSelection{{}, sel} {
+			sealed, err := srk.Seal([]byte("secret"), client.SealOpts{Current: current, Authority: authority.Public(), AuthorityPolicyRef: policyRef})
+			if err != nil {
+				t.Fatalf("failed to seal: %v", err)
+			}
+			unsealed, err := srk.Unseal(sealed, client.UnsealOpts{Authorize: authorize(authority)})
+			if err != nil {
+				t.Fatalf("failed to unseal: %v", err)
+			}
+			if !bytes.Equal(unsealed, []byte("secret")) {
+				t.Fatalf("unsealed (%v) not equal to secret", unsealed)
+			}
+
+			failures := []struct {
+				name      string
+				authorize func(nonceTPM, policyRef []byte) (*pb.PolicyAuthorization, error)
+			}{
+				{"NoAuthorize", nil},
+				{"OtherAuthority", authorize(otherAuthority)},
+				{"OtherNonce", func(nonceTPM, policyRef []byte) (*pb.PolicyAuthorization, error) {
+					return signAuthorization(authority, make([]byte, len(nonceTPM)), policyRef)
+				}},
+				{"OtherPolicyRef", func(nonceTPM, _ []byte) (*pb.PolicyAuthorization, error) {
+					return signAuthorization(authority, nonceTPM, []byte("tenant-2"))
+				}},
+			}
+			for _, failure := range failures {
+				if _, err := srk.Unseal(sealed, client.UnsealOpts{Authorize: failure.authorize}); err == nil {
+					t.Errorf("%s: unseal should have failed", failure.name)
+				}
+			}
+		}
+	}
+}
+
+func TestSealPolicyAuthority(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	srk, err := client.StorageRootKeyECC(rwc)
+	if err != nil {
+		t.Fatalf("can't create srk from template: %v", err)
+	}
+	defer srk.Close()
+
+	authority, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherAuthority, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policyRef := []byte("release-channel")
+	sel := tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{test.DebugPCR}}
+
+	if _, err := srk.Seal([]byte("secret"), client.SealOpts{Current: sel, PolicyAuthority: authority.Public()}); err == nil {
+		t.Error("sealing with both PCRs and a PolicyAuthority succeeded")
+	}
+	sealed, err := srk.Seal([]byte("secret"), client.SealOpts{PolicyAuthority: authority.Public(), PolicyAuthorityRef: policyRef})
+	if err != nil {
+		t.Fatalf("failed to seal: %v", err)
+	}
+	before, err := client.ReadPCRs(rwc, sel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	approved := signApproval(t, authority, before, policyRef)
+	unsealed, err := srk.Unseal(sealed, client.UnsealOpts{PolicyApprovals: []*pb.PolicyApproval{approved}})
+	if err != nil {
+		t.Fatalf("failed to unseal: %v", err)
+	}
+	if !bytes.Equal(unsealed, []byte("secret")) {
+		t.Fatalf("unsealed (%v) not equal to secret", unsealed)
+	}
+
+	failures := []struct {
+		name      string
+		approvals []*pb.PolicyApproval
+	}{
+		{"NoApproval", nil},
+		{"OtherAuthority", []*pb.PolicyApproval{signApproval(t, otherAuthority, before, policyRef)}},
+		{"OtherPolicyRef", []*pb.PolicyApproval{signApproval(t, authority, before, []byte("other"))}},
+	}
+	for _, failure := range failures {
+		if _, err := srk.Unseal(sealed, client.UnsealOpts{PolicyApprovals: failure.approvals}); err == nil {
+			t.Errorf("%s: unseal should have failed", failure.name)
+		}
+	}
+
+	// After the PCRs change, the data is unsealed once the authority
+	// approved the new values, without resealing it.
+	extension := bytes.Repeat([]byte{0xAA}, sha256.Size)
+	if err = tpm2.PCRExtend(rwc, tpmutil.Handle(test.DebugPCR), tpm2.AlgSHA256, extension, ""); err != nil {
+		t.Fatalf("failed to extend pcr: %v", err)
+	}
+	if _, err := srk.Unseal(sealed, client.UnsealOpts{PolicyApprovals: []*pb.PolicyApproval{approved}}); err == nil {
+		t.Error("unseal with the approval of the previous PCR values should have failed")
+	}
+	after, err := client.ReadPCRs(rwc, sel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	approvals := []*pb.PolicyApproval{approved, signApproval(t, authority, after, policyRef)}
+	if _, err := srk.Unseal(sealed, client.UnsealOpts{PolicyApprovals: approvals}); err != nil {
+		t.Errorf("failed to unseal with the approval of the new PCR values: %v", err)
+	}
+
+	// The approved policy can also require a signed authorization.
+	sealed, err = srk.Seal([]byte("secret"), client.SealOpts{PolicyAuthority: authority.Public(), PolicyAuthorityRef: policyRef, Authority: otherAuthority.Public()})
+	if err != nil {
+		t.Fatalf("failed to seal: %v", err)
+	}
+	authorize := func(nonceTPM, policyRef []byte) (*pb.PolicyAuthorization, error) {
+		return signAuthorization(otherAuthority, nonceTPM, policyRef)
+	}
+	if _, err := srk.Unseal(sealed, client.UnsealOpts{PolicyApprovals: approvals}); err == nil {
+		t.Error("unseal without the authorization should have failed")
+	}
+	if _, err := srk.Unseal(sealed, client.UnsealOpts{PolicyApprovals: approvals, Authorize: authorize}); err != nil {
+		t.Errorf("failed to unseal: %v", err)
+	}
+}
@@ -13,6 +13,13 @@ type session interface {
 }
 
 func startAuthSession(rw io.ReadWriter) (session tpmutil.Handle, err error) {
+	return startAuthSessionWithHash(rw, SessionHashAlgTpm)
+}
+
+// startAuthSessionWithHash starts a session like startAuthSession, computing
+// its policy digest with authHash, which must be the name algorithm of the
+// objects whose authPolicy it satisfies.
+func startAuthSessionWithHash(rw io.ReadWriter, authHash tpm2.Algorithm) (session tpmutil.Handle, err error) {
 	// This session assumes the bus is trusted, so we:
 	// - use nil for tpmKey, encrypted salt, and symmetric
 	// - use and all-zeros caller nonce, and ignore the returned nonce
@@ -27,7 +34,7 @@ func startAuthSession(rw io.ReadWriter) (session tpmutil.Handle, err error) {
 		/*encryptedSalt=*/ nil,
 		/*sessionType=*/ tpm2.SessionPolicy,
 		/*symmetric=*/ tpm2.AlgNull,
-		/*authHash=*/ SessionHashAlgTpm)
+		/*authHash=*/ authHash)
 	return
 }
 
@@ -61,8 +68,10 @@ type ekSession struct {
 	session tpmutil.Handle
 }
 
-func newEKSession(rw io.ReadWriter) (session, error) {
-	session, err := startAuthSession(rw)
+// newEKSession returns the session of an EK whose authPolicy was computed with
+// the policy hash authHash, e.g. SHA-384 for EKTemplateECCP384.
+func newEKSession(rw io.ReadWriter, authHash tpm2.Algorithm) (session, error) {
+	session, err := startAuthSessionWithHash(rw, authHash)
 	return ekSession{rw, session}, err
 }
 
@@ -64,7 +64,7 @@ func (signer *tpmSigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts
 
 	sig, err := tpm2.SignWithSession(signer.Key.rw, auth.Session, signer.Key.handle, "", digest, nil, nil)
 	if err != nil {
-		return nil, err
+		return nil, checkLockout(err)
 	}
 	return getSignature(sig)
 }
@@ -126,7 +126,7 @@ func (k *Key) SignData(data []byte) ([]byte, error) {
 	}
 	sig, err := tpm2.SignWithSession(k.rw, auth.Session, k.handle, "", digest, ticket, nil)
 	if err != nil {
-		return nil, err
+		return nil, checkLockout(err)
 	}
 	return getSignature(sig)
 }
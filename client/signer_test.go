@@ -3,15 +3,19 @@ package client_test
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha1"
 	"crypto/sha256"
 	"encoding/asn1"
+	"errors"
 	"math/big"
 	"testing"
 
 	"github.com/google/go-tpm-tools/client"
 	"github.com/google/go-tpm-tools/internal/test"
+	pb "github.com/google/go-tpm-tools/proto/tpm"
 	"github.com/google/go-tpm/tpm2"
 )
 
@@ -315,3 +319,72 @@ func TestFailGetSignerNullScheme(t *testing.T) {
 		t.Error("expected failure when calling GetSigner")
 	}
 }
+
+func TestSignAuthorizedKey(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	authority, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policyRef := []byte("signing")
+	policy, err := client.AuthorityPolicy(authority.Public(), policyRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := templateECC(tpm2.AlgSHA256)
+	template.AuthPolicy = policy
+	template.Attributes &= ^tpm2.FlagUserWithAuth
+
+	revoked := false
+	authorizations := 0
+	authorize := func(nonceTPM, policyRef []byte) (*pb.PolicyAuthorization, error) {
+		if revoked {
+			return nil, errors.New("revoked")
+		}
+		authorizations++
+		return signAuthorization(authority, nonceTPM, policyRef)
+	}
+
+	withUserAuth := templateECC(tpm2.AlgSHA256)
+	withUserAuth.AuthPolicy = policy
+	if _, err := client.NewAuthorizedKey(rwc, tpm2.HandleOwner, withUserAuth, authority.Public(), policyRef, authorize); err == nil {
+		t.Error("NewAuthorizedKey() succeeded with a key usable with its auth value")
+	}
+	if _, err := client.NewAuthorizedKey(rwc, tpm2.HandleOwner, template, authority.Public(), []byte("other"), authorize); err == nil {
+		t.Error("NewAuthorizedKey() succeeded with the policy of another policyRef")
+	}
+
+	key, err := client.NewAuthorizedKey(rwc, tpm2.HandleOwner, template, authority.Public(), policyRef, authorize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer key.Close()
+	signer, err := key.GetSigner()
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte("authorized"))
+	for i := 0; i < 2; i++ {
+		sig, err := signer.Sign(nil, digest[:], crypto.SHA256)
+		if err != nil {
+			t.Fatalf("Sign() failed: %v", err)
+		}
+		if !verifyECC(key.PublicKey(), crypto.SHA256, digest[:], sig) {
+			t.Error("invalid signature")
+		}
+	}
+	if authorizations != 2 {
+		t.Errorf("got %d authorizations, want one per signature", authorizations)
+	}
+
+	// The authority revokes the use of the key by refusing to sign.
+	revoked = true
+	if _, err := signer.Sign(nil, digest[:], crypto.SHA256); err == nil {
+		t.Error("Sign() succeeded after the authority revoked the key")
+	}
+	if _, err := key.SignData([]byte("authorized")); err == nil {
+		t.Error("SignData() succeeded after the authority revoked the key")
+	}
+}
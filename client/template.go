@@ -1,7 +1,9 @@
 package client
 
 import (
-	"crypto/sha256"
+	"crypto"
+	_ "crypto/sha256" // Register the SHA-256 and SHA-384 policy hashes.
+	_ "crypto/sha512"
 
 	"github.com/google/go-tpm/tpm2"
 	"github.com/google/go-tpm/tpmutil"
@@ -9,15 +11,24 @@ import (
 
 // Calculations from Credential_Profile_EK_V2.0, section 2.1.5.3 - authPolicy
 func defaultEKAuthPolicy() []byte {
+	return ekAuthPolicy(crypto.SHA256)
+}
+
+// ekAuthPolicy computes the TPM2_PolicySecret(TPM_RH_ENDORSEMENT) policy of
+// defaultEKAuthPolicy with the policy hash h.
+func ekAuthPolicy(h crypto.Hash) []byte {
 	buf, err := tpmutil.Pack(tpm2.CmdPolicySecret, tpm2.HandleEndorsement)
 	if err != nil {
 		panic(err)
 	}
-	digest1 := sha256.Sum256(append(make([]byte, 32), buf...))
+	digest1 := h.New()
+	digest1.Write(make([]byte, h.Size()))
+	digest1.Write(buf)
 	// We would normally append the policy buffer to digest1, but the
 	// policy buffer is empty for the default Auth Policy.
-	digest2 := sha256.Sum256(digest1[:])
-	return digest2[:]
+	digest2 := h.New()
+	digest2.Write(digest1.Sum(nil))
+	return digest2.Sum(nil)
 }
 
 func defaultEKAttributes() tpm2.KeyProp {
@@ -58,6 +69,35 @@ func defaultECCParams() *tpm2.ECCParams {
 	}
 }
 
+// cnsaSymScheme is the AES-256 symmetric scheme of the CNSA-aligned storage
+// keys.
+func cnsaSymScheme() *tpm2.SymScheme {
+	return &tpm2.SymScheme{
+		Alg:     tpm2.AlgAES,
+		KeyBits: 256,
+		Mode:    tpm2.AlgCFB,
+	}
+}
+
+func rsa3072Params() *tpm2.RSAParams {
+	return &tpm2.RSAParams{
+		Symmetric:  cnsaSymScheme(),
+		KeyBits:    3072,
+		ModulusRaw: make([]byte, 384), // public.unique must be all zeros
+	}
+}
+
+func eccP384Params() *tpm2.ECCParams {
+	return &tpm2.ECCParams{
+		Symmetric: cnsaSymScheme(),
+		CurveID:   tpm2.CurveNISTP384,
+		Point: tpm2.ECPoint{
+			XRaw: make([]byte, 48),
+			YRaw: make([]byte, 48),
+		},
+	}
+}
+
 // DefaultEKTemplateRSA returns the default Endorsement Key (EK) template as
 // specified in Credential_Profile_EK_V2.0, section 2.1.5.1 - authPolicy.
 // https://trustedcomputinggroup.org/wp-content/uploads/Credential_Profile_EK_V2.0_R14_published.pdf
@@ -84,6 +124,35 @@ func DefaultEKTemplateECC() tpm2.Public {
 	}
 }
 
+// EKTemplateRSA3072 returns an RSA-3072 Endorsement Key (EK) template with a
+// SHA-384 name algorithm and AES-256 symmetric scheme, for deployments
+// mandating CNSA-aligned key sizes. It is built like DefaultEKTemplateRSA,
+// with its authPolicy computed with SHA-384. It is not the high-range RSA 3072
+// template of the TCG EK Credential Profile, whose authPolicy also allows
+// TPM2_PolicyAuthorizeNV, so EK certificates provisioned for that template do
+// not certify this key.
+func EKTemplateRSA3072() tpm2.Public {
+	return tpm2.Public{
+		Type:          tpm2.AlgRSA,
+		NameAlg:       tpm2.AlgSHA384,
+		Attributes:    defaultEKAttributes(),
+		AuthPolicy:    ekAuthPolicy(crypto.SHA384),
+		RSAParameters: rsa3072Params(),
+	}
+}
+
+// EKTemplateECCP384 returns an ECC NIST P-384 Endorsement Key (EK) template
+// with a SHA-384 name algorithm, see EKTemplateRSA3072.
+func EKTemplateECCP384() tpm2.Public {
+	return tpm2.Public{
+		Type:          tpm2.AlgECC,
+		NameAlg:       tpm2.AlgSHA384,
+		Attributes:    defaultEKAttributes(),
+		AuthPolicy:    ekAuthPolicy(crypto.SHA384),
+		ECCParameters: eccP384Params(),
+	}
+}
+
 // AKTemplateRSA returns a potential Attestation Key (AK) template.
 // This is very similar to DefaultEKTemplateRSA, except that this will be a
 // signing key instead of an encrypting key.
@@ -120,6 +189,42 @@ func AKTemplateECC() tpm2.Public {
 	}
 }
 
+// AKTemplateRSA3072 returns a restricted RSA-3072 Attestation Key (AK)
+// template signing RSASSA with SHA-384, with a SHA-384 name algorithm, for
+// deployments mandating CNSA-aligned key sizes.
+func AKTemplateRSA3072() tpm2.Public {
+	return tpm2.Public{
+		Type:       tpm2.AlgRSA,
+		NameAlg:    tpm2.AlgSHA384,
+		Attributes: tpm2.FlagSignerDefault,
+		RSAParameters: &tpm2.RSAParams{
+			Sign: &tpm2.SigScheme{
+				Alg:  tpm2.AlgRSASSA,
+				Hash: tpm2.AlgSHA384,
+			},
+			KeyBits: 3072,
+		},
+	}
+}
+
+// AKTemplateECCP384 returns a restricted ECC NIST P-384 Attestation Key (AK)
+// template signing ECDSA with SHA-384, with a SHA-384 name algorithm, see
+// AKTemplateRSA3072.
+func AKTemplateECCP384() tpm2.Public {
+	params := eccP384Params()
+	params.Symmetric = nil
+	params.Sign = &tpm2.SigScheme{
+		Alg:  tpm2.AlgECDSA,
+		Hash: tpm2.AlgSHA384,
+	}
+	return tpm2.Public{
+		Type:          tpm2.AlgECC,
+		NameAlg:       tpm2.AlgSHA384,
+		Attributes:    tpm2.FlagSignerDefault,
+		ECCParameters: params,
+	}
+}
+
 // SRKTemplateRSA returns a standard Storage Root Key (SRK) template.
 // This is based upon the advice in the TCG's TPM v2.0 Provisioning Guidance.
 func SRKTemplateRSA() tpm2.Public {
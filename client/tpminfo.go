@@ -0,0 +1,87 @@
+package client
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/go-tpm/tpm2"
+)
+
+// TPMInfo is the fixed information of a TPM, from its TPM_PT_FIXED
+// properties.
+type TPMInfo struct {
+	// Manufacturer is the vendor ID of the TPM manufacturer, e.g. "GOOG" for
+	// the vTPM of GCE, or "IBM" for the simulator.
+	Manufacturer string
+	// VendorString is the vendor-specific free-form description of the TPM.
+	VendorString string
+	// FirmwareVersion is the vendor-specific firmware version of the TPM,
+	// with TPM_PT_FIRMWARE_VERSION_1 in the high 32 bits. It is also signed
+	// in the quotes of AKs in the endorsement hierarchy, like the GCE AKs,
+	// so a verifier can enforce a minimum version, see
+	// attest.PlatformPolicy.minimum_tpm_firmware_version.
+	FirmwareVersion uint64
+	// SpecRevision is the revision of the TPM 2.0 spec the TPM implements,
+	// times 100 (e.g. 159 for revision 1.59).
+	SpecRevision uint32
+}
+
+// GetTPMInfo returns the fixed information of the TPM. It needs no
+// authorization.
+func GetTPMInfo(rw io.ReadWriter) (*TPMInfo, error) {
+	props, err := getTPMProperties(rw, tpm2.SpecRevision, tpm2.FirmwareVersion2)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range []tpm2.TPMProp{tpm2.SpecRevision, tpm2.Manufacturer, tpm2.FirmwareVersion1, tpm2.FirmwareVersion2} {
+		if _, ok := props[tag]; !ok {
+			return nil, fmt.Errorf("TPM did not return property 0x%x", uint32(tag))
+		}
+	}
+	var vendor []byte
+	for _, tag := range []tpm2.TPMProp{tpm2.VendorString1, tpm2.VendorString2, tpm2.VendorString3, tpm2.VendorString4} {
+		vendor = append(vendor, propertyString(props[tag])...)
+	}
+	return &TPMInfo{
+		Manufacturer:    strings.TrimRight(string(propertyString(props[tpm2.Manufacturer])), "\x00 "),
+		VendorString:    strings.TrimRight(string(vendor), "\x00 "),
+		FirmwareVersion: uint64(props[tpm2.FirmwareVersion1])<<32 | uint64(props[tpm2.FirmwareVersion2]),
+		SpecRevision:    props[tpm2.SpecRevision],
+	}, nil
+}
+
+// propertyString returns the 4 characters of a TPM property holding a string.
+func propertyString(value uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, value)
+	return b
+}
+
+// getTPMProperties returns the TPM properties from first to last, which the
+// TPM may return in several parts.
+func getTPMProperties(rw io.ReadWriter, first, last tpm2.TPMProp) (map[tpm2.TPMProp]uint32, error) {
+	props := make(map[tpm2.TPMProp]uint32)
+	for first <= last {
+		vals, moreData, err := tpm2.GetCapability(rw, tpm2.CapabilityTPMProperties, uint32(last-first+1), uint32(first))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get TPM properties: %w", err)
+		}
+		if len(vals) == 0 {
+			break
+		}
+		for _, v := range vals {
+			prop, ok := v.(tpm2.TaggedProperty)
+			if !ok {
+				return nil, fmt.Errorf("unable to assert type tpm2.TaggedProperty of value %#v", v)
+			}
+			props[prop.Tag] = prop.Value
+			first = prop.Tag + 1
+		}
+		if !moreData {
+			break
+		}
+	}
+	return props, nil
+}
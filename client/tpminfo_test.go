@@ -0,0 +1,41 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm/tpm2"
+)
+
+func TestGetTPMInfo(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	info, err := client.GetTPMInfo(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Manufacturer == "" || info.SpecRevision == 0 {
+		t.Errorf("GetTPMInfo() = %+v, want a manufacturer and a spec revision", info)
+	}
+
+	// The firmware version is the one signed in the quotes of AKs in the
+	// endorsement hierarchy.
+	ak, err := client.NewKey(rwc, tpm2.HandleEndorsement, client.AKTemplateRSA())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	quote, err := ak.Quote(client.FullPcrSel(tpm2.AlgSHA256), []byte("nonce"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	attestationData, err := tpm2.DecodeAttestationData(quote.GetQuote())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.FirmwareVersion != attestationData.FirmwareVersion {
+		t.Errorf("got firmware version %#x, want %#x from the quote", info.FirmwareVersion, attestationData.FirmwareVersion)
+	}
+}
@@ -0,0 +1,238 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+// Commands and responses start with a tag, their size, and a command or
+// response code.
+const headerSize = 10
+
+// maxResponseSize is the size of the buffer used to read responses, which is
+// the same as the one used by go-tpm.
+const maxResponseSize = 4096
+
+// Transport is a connection to a TPM. Each command is sent with a single call
+// to Write, and its response is then available to Read, so a Transport can be
+// used anywhere an io.ReadWriteCloser TPM is. Use NewTransport to wrap a TPM
+// device or simulator.
+type Transport interface {
+	io.ReadWriteCloser
+	// Send sends a command to the TPM and returns its response.
+	Send(command []byte) ([]byte, error)
+}
+
+// Command describes a command sent over a Transport returned by NewTransport.
+type Command struct {
+	// Code of the command, or zero if the command is too short to have one.
+	Code tpmutil.Command
+	// Request and Response are the raw command and response.
+	Request  []byte
+	Response []byte
+	// Err is the error returned by the underlying transport, if any. Errors
+	// returned by the TPM are in the response code.
+	Err error
+	// Duration is the time taken to send the command and receive its response.
+	Duration time.Duration
+}
+
+// ResponseCode returns the response code of the command, or an error if the
+// response is missing or too short.
+func (c *Command) ResponseCode() (tpmutil.ResponseCode, error) {
+	if c.Err != nil {
+		return 0, c.Err
+	}
+	if len(c.Response) < headerSize {
+		return 0, fmt.Errorf("response of %d bytes is too short", len(c.Response))
+	}
+	return tpmutil.ResponseCode(binary.BigEndian.Uint32(c.Response[6:])), nil
+}
+
+// CommandHook is called after each command sent over a Transport returned by
+// NewTransport.
+type CommandHook func(c *Command)
+
+type hookedTransport struct {
+	rwc   io.ReadWriteCloser
+	hooks []CommandHook
+	buf   bytes.Buffer
+}
+
+// NewTransport wraps rwc, calling the hooks after each command. Closing the
+// returned Transport closes rwc.
+func NewTransport(rwc io.ReadWriteCloser, hooks ...CommandHook) Transport {
+	return &hookedTransport{rwc: rwc, hooks: hooks}
+}
+
+// NewTracingTransport wraps rwc, writing a line to w for each command, with
+// the name of the command and the decoded response code. This can be used to
+// debug the TPM commands issued by this library.
+func NewTracingTransport(rwc io.ReadWriteCloser, w io.Writer) Transport {
+	return NewTransport(rwc, TraceCommands(w))
+}
+
+func (t *hookedTransport) Send(command []byte) ([]byte, error) {
+	c := &Command{Request: command}
+	if len(command) >= headerSize {
+		c.Code = tpmutil.Command(binary.BigEndian.Uint32(command[6:]))
+	}
+
+	start := time.Now()
+	c.Response, c.Err = t.roundTrip(command)
+	c.Duration = time.Since(start)
+
+	for _, hook := range t.hooks {
+		hook(c)
+	}
+	return c.Response, c.Err
+}
+
+func (t *hookedTransport) roundTrip(command []byte) ([]byte, error) {
+	if _, err := t.rwc.Write(command); err != nil {
+		return nil, err
+	}
+	resp := make([]byte, maxResponseSize)
+	n, err := t.rwc.Read(resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp[:n], nil
+}
+
+func (t *hookedTransport) Write(command []byte) (int, error) {
+	resp, err := t.Send(command)
+	if err != nil {
+		return 0, err
+	}
+	t.buf.Reset()
+	t.buf.Write(resp)
+	return len(command), nil
+}
+
+func (t *hookedTransport) Read(response []byte) (int, error) {
+	return t.buf.Read(response)
+}
+
+func (t *hookedTransport) Close() error {
+	return t.rwc.Close()
+}
+
+// TraceCommands returns a CommandHook writing a line to w for each command,
+// like:
+//
+//	TPM2_Quote: 0.512ms, 78 byte command, 208 byte response: TPM_RC_SUCCESS
+func TraceCommands(w io.Writer) CommandHook {
+	return func(c *Command) {
+		result := "TPM_RC_SUCCESS"
+		if rc, err := c.ResponseCode(); err != nil {
+			result = err.Error()
+		} else if err := DecodeResponseCode(rc); err != nil {
+			result = err.Error()
+		}
+		fmt.Fprintf(w, "%s: %.3fms, %d byte command, %d byte response: %s\n",
+			CommandName(c.Code), float64(c.Duration.Microseconds())/1000,
+			len(c.Request), len(c.Response), result)
+	}
+}
+
+// DecodeResponseCode returns nil for TPM_RC_SUCCESS, and otherwise the error
+// go-tpm returns for the response code, like a tpm2.ParameterError.
+func DecodeResponseCode(rc tpmutil.ResponseCode) error {
+	// Same logic as go-tpm, following the "Response Code Evaluation" chart in
+	// Part 1 of the TPM 2.0 spec.
+	switch {
+	case rc == tpmutil.RCSuccess:
+		return nil
+	case rc&0x180 == 0:
+		return fmt.Errorf("response status 0x%x", uint32(rc))
+	case rc&0x80 == 0 && rc&0x400 != 0:
+		return tpm2.VendorError{Code: uint32(rc)}
+	case rc&0x80 == 0 && rc&0x800 != 0:
+		return tpm2.Warning{Code: tpm2.RCWarn(rc & 0x7f)}
+	case rc&0x80 == 0:
+		return tpm2.Error{Code: tpm2.RCFmt0(rc & 0x7f)}
+	case rc&0x40 != 0:
+		return tpm2.ParameterError{Code: tpm2.RCFmt1(rc & 0x3f), Parameter: tpm2.RCIndex((rc & 0xf00) >> 8)}
+	case rc&0x800 == 0:
+		return tpm2.HandleError{Code: tpm2.RCFmt1(rc & 0x3f), Handle: tpm2.RCIndex((rc & 0x700) >> 8)}
+	default:
+		return tpm2.SessionError{Code: tpm2.RCFmt1(rc & 0x3f), Session: tpm2.RCIndex((rc & 0x700) >> 8)}
+	}
+}
+
+// CommandName returns the name of the command code from the TPM 2.0 spec, like
+// "TPM2_Quote".
+func CommandName(code tpmutil.Command) string {
+	if name, ok := commandNames[code]; ok {
+		return name
+	}
+	return fmt.Sprintf("TPM_CC(0x%x)", uint32(code))
+}
+
+var commandNames = map[tpmutil.Command]string{
+	tpm2.CmdNVUndefineSpaceSpecial:     "TPM2_NV_UndefineSpaceSpecial",
+	tpm2.CmdEvictControl:               "TPM2_EvictControl",
+	tpm2.CmdUndefineSpace:              "TPM2_NV_UndefineSpace",
+	tpm2.CmdClear:                      "TPM2_Clear",
+	tpm2.CmdHierarchyChangeAuth:        "TPM2_HierarchyChangeAuth",
+	tpm2.CmdDefineSpace:                "TPM2_NV_DefineSpace",
+	tpm2.CmdCreatePrimary:              "TPM2_CreatePrimary",
+	tpm2.CmdIncrementNVCounter:         "TPM2_NV_Increment",
+	tpm2.CmdWriteNV:                    "TPM2_NV_Write",
+	tpm2.CmdWriteLockNV:                "TPM2_NV_WriteLock",
+	tpm2.CmdDictionaryAttackLockReset:  "TPM2_DictionaryAttackLockReset",
+	tpm2.CmdDictionaryAttackParameters: "TPM2_DictionaryAttackParameters",
+	tpm2.CmdPCREvent:                   "TPM2_PCR_Event",
+	tpm2.CmdPCRReset:                   "TPM2_PCR_Reset",
+	tpm2.CmdSequenceComplete:           "TPM2_SequenceComplete",
+	tpm2.CmdStartup:                    "TPM2_Startup",
+	tpm2.CmdShutdown:                   "TPM2_Shutdown",
+	tpm2.CmdActivateCredential:         "TPM2_ActivateCredential",
+	tpm2.CmdCertify:                    "TPM2_Certify",
+	tpm2.CmdCertifyCreation:            "TPM2_CertifyCreation",
+	tpm2.CmdReadNV:                     "TPM2_NV_Read",
+	tpm2.CmdReadLockNV:                 "TPM2_NV_ReadLock",
+	tpm2.CmdPolicySecret:               "TPM2_PolicySecret",
+	tpm2.CmdCreate:                     "TPM2_Create",
+	tpm2.CmdECDHZGen:                   "TPM2_ECDH_ZGen",
+	tpm2.CmdImport:                     "TPM2_Import",
+	tpm2.CmdLoad:                       "TPM2_Load",
+	tpm2.CmdQuote:                      "TPM2_Quote",
+	tpm2.CmdRSADecrypt:                 "TPM2_RSA_Decrypt",
+	tpm2.CmdSequenceUpdate:             "TPM2_SequenceUpdate",
+	tpm2.CmdSign:                       "TPM2_Sign",
+	tpm2.CmdUnseal:                     "TPM2_Unseal",
+	tpm2.CmdPolicySigned:               "TPM2_PolicySigned",
+	tpm2.CmdContextLoad:                "TPM2_ContextLoad",
+	tpm2.CmdContextSave:                "TPM2_ContextSave",
+	tpm2.CmdECDHKeyGen:                 "TPM2_ECDH_KeyGen",
+	tpm2.CmdEncryptDecrypt:             "TPM2_EncryptDecrypt",
+	tpm2.CmdFlushContext:               "TPM2_FlushContext",
+	tpm2.CmdLoadExternal:               "TPM2_LoadExternal",
+	tpm2.CmdMakeCredential:             "TPM2_MakeCredential",
+	tpm2.CmdReadPublicNV:               "TPM2_NV_ReadPublic",
+	tpm2.CmdPolicyCommandCode:          "TPM2_PolicyCommandCode",
+	tpm2.CmdPolicyOr:                   "TPM2_PolicyOR",
+	tpm2.CmdReadPublic:                 "TPM2_ReadPublic",
+	tpm2.CmdRSAEncrypt:                 "TPM2_RSA_Encrypt",
+	tpm2.CmdStartAuthSession:           "TPM2_StartAuthSession",
+	tpm2.CmdGetCapability:              "TPM2_GetCapability",
+	tpm2.CmdGetRandom:                  "TPM2_GetRandom",
+	tpm2.CmdHash:                       "TPM2_Hash",
+	tpm2.CmdPCRRead:                    "TPM2_PCR_Read",
+	tpm2.CmdPolicyPCR:                  "TPM2_PolicyPCR",
+	tpm2.CmdReadClock:                  "TPM2_ReadClock",
+	tpm2.CmdPCRExtend:                  "TPM2_PCR_Extend",
+	tpm2.CmdEventSequenceComplete:      "TPM2_EventSequenceComplete",
+	tpm2.CmdHashSequenceStart:          "TPM2_HashSequenceStart",
+	tpm2.CmdPolicyGetDigest:            "TPM2_PolicyGetDigest",
+	tpm2.CmdPolicyPassword:             "TPM2_PolicyPassword",
+	tpm2.CmdEncryptDecrypt2:            "TPM2_EncryptDecrypt2",
+}
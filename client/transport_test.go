@@ -0,0 +1,90 @@
+package client_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+func TestTransportHooks(t *testing.T) {
+	var commands []*client.Command
+	rwc := client.NewTransport(test.GetTPM(t), func(c *client.Command) {
+		commands = append(commands, c)
+	})
+	defer client.CheckedClose(t, rwc)
+
+	ek, err := client.EndorsementKeyECC(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ek.Close()
+
+	created := false
+	for _, c := range commands {
+		if c.Code != tpm2.CmdCreatePrimary {
+			continue
+		}
+		if rc, err := c.ResponseCode(); err != nil || rc != tpmutil.RCSuccess {
+			t.Errorf("%s failed: rc 0x%x, %v", client.CommandName(c.Code), uint32(rc), err)
+		}
+		created = true
+	}
+	if !created {
+		t.Error("hook not called for TPM2_CreatePrimary")
+	}
+}
+
+func TestTracingTransport(t *testing.T) {
+	var trace bytes.Buffer
+	rwc := client.NewTracingTransport(test.GetTPM(t), &trace)
+	defer client.CheckedClose(t, rwc)
+
+	if _, err := tpm2.GetRandom(rwc, 16); err != nil {
+		t.Fatal(err)
+	}
+	// Flushing a handle which isn't loaded fails.
+	flushErr := tpm2.FlushContext(rwc, 0x80FFFFFF)
+	if flushErr == nil {
+		t.Fatal("FlushContext succeeded")
+	}
+
+	lines := strings.Split(strings.TrimSpace(trace.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got trace %q, want 2 lines", trace.String())
+	}
+	if !strings.HasPrefix(lines[0], "TPM2_GetRandom: ") || !strings.HasSuffix(lines[0], ": TPM_RC_SUCCESS") {
+		t.Errorf("got trace line %q", lines[0])
+	}
+	// The trace decodes the response code like go-tpm.
+	if !strings.HasPrefix(lines[1], "TPM2_FlushContext: ") || !strings.HasSuffix(lines[1], ": "+flushErr.Error()) {
+		t.Errorf("got trace line %q, want error %v", lines[1], flushErr)
+	}
+}
+
+func TestDecodeResponseCode(t *testing.T) {
+	tests := []struct {
+		rc   tpmutil.ResponseCode
+		want error
+	}{
+		{0x100, tpm2.Error{Code: tpm2.RCInitialize}},
+		{0x908, tpm2.Warning{Code: tpm2.RCYielded}},
+		{0x1c4, tpm2.ParameterError{Code: tpm2.RCValue, Parameter: tpm2.RC1}},
+		{0x18b, tpm2.HandleError{Code: tpm2.RCHandle, Handle: tpm2.RC1}},
+		{0x98e, tpm2.SessionError{Code: tpm2.RCAuthFail, Session: tpm2.RC1}},
+		{0x500, tpm2.VendorError{Code: 0x500}},
+	}
+	for _, tc := range tests {
+		if err := client.DecodeResponseCode(tc.rc); !errors.Is(err, tc.want) {
+			t.Errorf("DecodeResponseCode(0x%x) = %v, want %v", uint32(tc.rc), err, tc.want)
+		}
+	}
+	if err := client.DecodeResponseCode(tpmutil.RCSuccess); err != nil {
+		t.Errorf("DecodeResponseCode(TPM_RC_SUCCESS) = %v", err)
+	}
+}
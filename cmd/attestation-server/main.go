@@ -0,0 +1,130 @@
+// Package main is an attestation verifier server, see the server/httpservice
+// package.
+package main
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/server"
+	"github.com/google/go-tpm-tools/server/httpservice"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var (
+	addr           = flag.String("addr", ":8080", "address to listen on")
+	keyFile        = flag.String("key", "", "PEM encoded private key used to sign the tokens (required)")
+	keyID          = flag.String("key-id", "", "key ID of the signing key")
+	issuer         = flag.String("issuer", "", "URL the server is reachable at, used as the token issuer (required)")
+	audience       = flag.String("audience", "", "audience of the issued tokens")
+	tokenLifetime  = flag.Duration("token-lifetime", 0, "lifetime of the issued tokens (default 1h)")
+	policyFile     = flag.String("policy", "", "JSON encoded attest.Policy the attestations must comply with")
+	allowSHA1      = flag.Bool("allow-sha1", false, "allow verifying attestations using SHA-1 PCRs")
+	revocationList = flag.String("revocation-list", "", "JSON encoded server.RevocationList of revoked artifacts")
+	revocationFeed = flag.String("revocation-feed", "", "URL of a JSON encoded server.RevocationList, refetched hourly")
+	vtpmRootsFile  = flag.String("vtpm-roots", "", "PEM encoded root certificates of the AKs of virtual TPMs outside of GCE (e.g. swtpm), replacing the GCE roots")
+)
+
+// loadCerts reads the PEM encoded certificates of path.
+func loadCerts(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates in %s", path)
+	}
+	return certs, nil
+}
+
+func main() {
+	flag.Parse()
+	if *keyFile == "" || *issuer == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	signer, err := httpservice.LoadSigner(*keyFile)
+	if err != nil {
+		log.Fatalf("failed to load the signing key: %v", err)
+	}
+	var policy *pb.Policy
+	if *policyFile != "" {
+		data, err := os.ReadFile(*policyFile)
+		if err != nil {
+			log.Fatalf("failed to read the policy: %v", err)
+		}
+		policy = &pb.Policy{}
+		if err := protojson.Unmarshal(data, policy); err != nil {
+			log.Fatalf("failed to parse the policy: %v", err)
+		}
+	}
+
+	if *revocationList != "" && *revocationFeed != "" {
+		log.Fatal("-revocation-list and -revocation-feed are mutually exclusive")
+	}
+	var revocation server.RevocationChecker
+	if *revocationList != "" {
+		list, err := server.LoadRevocationList(*revocationList)
+		if err != nil {
+			log.Fatalf("failed to load the revocation list: %v", err)
+		}
+		revocation = list
+	}
+	if *revocationFeed != "" {
+		revocation = &server.RevocationFeed{URL: *revocationFeed}
+	}
+
+	verifyOpts := server.VerifyOpts{
+		TrustedRootCerts:  server.GceEKRoots,
+		IntermediateCerts: server.GceEKIntermediates,
+		AllowSHA1:         *allowSHA1,
+	}
+	if *vtpmRootsFile != "" {
+		roots, err := loadCerts(*vtpmRootsFile)
+		if err != nil {
+			log.Fatalf("failed to load the virtual TPM roots: %v", err)
+		}
+		verifyOpts.TrustedRootCerts = roots
+		verifyOpts.IntermediateCerts = nil
+		verifyOpts.TrustMode = server.VirtualTPMTrust
+	}
+
+	service, err := httpservice.New(httpservice.Config{
+		Signer:        signer,
+		KeyID:         *keyID,
+		Issuer:        *issuer,
+		Audience:      *audience,
+		TokenLifetime: *tokenLifetime,
+		VerifyOpts:    verifyOpts,
+		Policy:        policy,
+		Revocation:    revocation,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("attestation server listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, service))
+}
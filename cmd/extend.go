@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/go-tpm-tools/client"
+	pb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	extendHashAlgos []tpm2.Algorithm
+	extendDigest    string
+)
+
+var extendCmd = &cobra.Command{
+	Use:   "extend",
+	Short: "Extend PCRs on the TPM",
+	Long: `Extend one or more PCRs in one or more PCR banks
+
+Based on the --pcrs and --hash-algo flags, this extends each selected PCR in
+each selected bank. If --hash-algo is not provided, only the SHA256 bank is
+extended. Multiple banks can be extended by passing a comma separated list of
+hash algorithms.
+
+By default, the input data (from --input or stdin) is hashed with each bank's
+hash algorithm, and the resulting digest is extended into the PCRs. If --digest
+is provided, the hex-encoded digest is extended directly (without hashing), and
+its size must match the digest size of every selected bank.
+
+The new PCR values can be output with --verbose, or by using "gotpm read pcr".
+With --format=json, the new values of the extended PCRs are output in the JSON
+format of "gotpm read pcr".`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(pcrs) == 0 {
+			return errors.New("--pcrs must be provided")
+		}
+		hashAlgos := extendHashAlgos
+		if len(hashAlgos) == 0 {
+			hashAlgos = []tpm2.Algorithm{tpm2.AlgSHA256}
+		}
+
+		var data []byte
+		var err error
+		if extendDigest != "" {
+			if input != "" {
+				return errors.New("cannot specify both --digest and --input")
+			}
+			if data, err = hex.DecodeString(extendDigest); err != nil {
+				return fmt.Errorf("decoding --digest: %w", err)
+			}
+		} else {
+			fmt.Fprintln(debugOutput(), "Reading input data")
+			if data, err = io.ReadAll(dataInput()); err != nil {
+				return err
+			}
+		}
+
+		digests := make(map[tpm2.Algorithm][]byte, len(hashAlgos))
+		for _, hashAlgo := range hashAlgos {
+			hash, err := hashAlgo.Hash()
+			if err != nil {
+				return err
+			}
+			if extendDigest != "" {
+				if len(data) != hash.Size() {
+					return fmt.Errorf("--digest has size %d, but %v bank requires size %d",
+						len(data), hashAlgo, hash.Size())
+				}
+				digests[hashAlgo] = data
+				continue
+			}
+			h := hash.New()
+			h.Write(data)
+			digests[hashAlgo] = h.Sum(nil)
+		}
+
+		rwc, err := openTpm()
+		if err != nil {
+			return err
+		}
+		defer rwc.Close()
+
+		var banks []*pb.PCRs
+		for _, hashAlgo := range hashAlgos {
+			for _, pcr := range pcrs {
+				if err := tpm2.PCRExtend(rwc, tpmutil.Handle(pcr), hashAlgo, digests[hashAlgo], ""); err != nil {
+					return fmt.Errorf("extending %v PCR %d: %w", hashAlgo, pcr, err)
+				}
+				fmt.Fprintf(debugOutput(), "Extended %v PCR %d with 0x%X\n", hashAlgo, pcr, digests[hashAlgo])
+			}
+			vals, err := client.ReadPCRs(rwc, tpm2.PCRSelection{Hash: hashAlgo, PCRs: pcrs})
+			if err != nil {
+				return err
+			}
+			for _, pcr := range pcrs {
+				fmt.Fprintf(debugOutput(), "New %v PCR %d value: 0x%X\n", hashAlgo, pcr, vals.GetPcrs()[uint32(pcr)])
+			}
+			banks = append(banks, vals)
+		}
+
+		if outputFormat == formatJSON {
+			return writePCRs(messageOutput(), banks)
+		}
+		fmt.Fprintf(messageOutput(), "%d PCRs extended in %d banks\n", len(pcrs), len(hashAlgos))
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(extendCmd)
+	addInputFlag(extendCmd)
+	addPCRsFlag(extendCmd)
+	addHashAlgosFlag(extendCmd, &extendHashAlgos)
+	extendCmd.PersistentFlags().StringVar(&extendDigest, "digest", "",
+		"hex-encoded digest to extend directly, instead of hashing the input")
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm/tpm2"
+)
+
+// Expected value of a PCR starting at zero after a single extend of digest.
+func extendedPCR(t *testing.T, hashAlgo tpm2.Algorithm, digest []byte) []byte {
+	t.Helper()
+	hash, err := hashAlgo.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := hash.New()
+	h.Write(make([]byte, hash.Size()))
+	h.Write(digest)
+	return h.Sum(nil)
+}
+
+func TestExtendMultipleBanks(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ExternalTPM = rwc
+
+	data := []byte("measured data")
+	dataFile := makeTempFile(t, data)
+	defer os.Remove(dataFile)
+
+	pcr := test.DebugPCR
+	RootCmd.SetArgs([]string{"extend", "--quiet", "--input", dataFile,
+		"--pcrs", strconv.Itoa(pcr), "--hash-algo", "sha1,sha256"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	pcrs = []int{}
+	extendHashAlgos = nil
+	input = ""
+
+	sha1Digest := sha1.Sum(data)
+	sha256Digest := sha256.Sum256(data)
+	want := map[tpm2.Algorithm][]byte{
+		tpm2.AlgSHA1:   extendedPCR(t, tpm2.AlgSHA1, sha1Digest[:]),
+		tpm2.AlgSHA256: extendedPCR(t, tpm2.AlgSHA256, sha256Digest[:]),
+	}
+	for hashAlgo, wantVal := range want {
+		got, err := client.ReadPCRs(rwc, tpm2.PCRSelection{Hash: hashAlgo, PCRs: []int{pcr}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.GetPcrs()[uint32(pcr)], wantVal) {
+			t.Errorf("%v PCR %d: got 0x%X, want 0x%X", hashAlgo, pcr, got.GetPcrs()[uint32(pcr)], wantVal)
+		}
+	}
+}
+
+func TestExtendDigestAndReadJSON(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ExternalTPM = rwc
+
+	pcr := test.DebugPCR
+	digest := bytes.Repeat([]byte{0xAB}, sha256.Size)
+	RootCmd.SetArgs([]string{"extend", "--quiet", "--digest", hex.EncodeToString(digest),
+		"--pcrs", strconv.Itoa(pcr), "--hash-algo", "sha256"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	pcrs = []int{}
+	extendHashAlgos = nil
+	extendDigest = ""
+
+	outFile := makeTempFile(t, nil)
+	defer os.Remove(outFile)
+	RootCmd.SetArgs([]string{"read", "pcr", "--format", "json", "--output", outFile,
+		"--pcrs", strconv.Itoa(pcr), "--hash-algo", "sha1,sha256"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	pcrs = []int{}
+	pcrHashAlgos = nil
+	outputFormat = formatText
+	output = ""
+
+	contents, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]map[string]string
+	if err := json.Unmarshal(contents, &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, contents)
+	}
+	if len(got["sha1"]) != 1 {
+		t.Errorf("got %d sha1 PCRs, want 1", len(got["sha1"]))
+	}
+	wantVal := extendedPCR(t, tpm2.AlgSHA256, digest)
+	if gotVal := got["sha256"][strconv.Itoa(pcr)]; gotVal != hex.EncodeToString(wantVal) {
+		t.Errorf("sha256 PCR %d: got %s, want %x", pcr, gotVal, wantVal)
+	}
+}
+
+func TestExtendDigestWrongSize(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ExternalTPM = rwc
+
+	digest := bytes.Repeat([]byte{0xAB}, sha256.Size)
+	RootCmd.SetArgs([]string{"extend", "--quiet", "--digest", hex.EncodeToString(digest),
+		"--pcrs", strconv.Itoa(test.DebugPCR), "--hash-algo", "sha1"})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("extending a SHA1 bank with a SHA256 digest should fail")
+	}
+	pcrs = []int{}
+	extendHashAlgos = nil
+	extendDigest = ""
+}
@@ -14,13 +14,42 @@ import (
 )
 
 var (
-	output  string
-	input   string
-	nvIndex uint32
-	keyAlgo = tpm2.AlgRSA
-	pcrs    []int
+	output       string
+	input        string
+	nvIndex      uint32
+	keyAlgo      = tpm2.AlgRSA
+	pcrs         []int
+	outputFormat = formatText
 )
 
+// Supported values for the --format flag.
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+type formatFlag struct {
+	value *string
+}
+
+func (f *formatFlag) Set(val string) error {
+	switch val {
+	case formatText, formatJSON:
+		*f.value = val
+		return nil
+	default:
+		return errors.New("unknown format")
+	}
+}
+
+func (f *formatFlag) Type() string {
+	return "format"
+}
+
+func (f *formatFlag) String() string {
+	return *f.value
+}
+
 type pcrsFlag struct {
 	value *[]int
 }
@@ -94,11 +123,50 @@ func (f *algoFlag) String() string {
 
 // Allowed gives a string list of the permitted algorithm values for this flag.
 func (f *algoFlag) Allowed() string {
+	return strings.Join(f.names(), ", ")
+}
+
+// names returns the names of the permitted algorithm values for this flag.
+func (f *algoFlag) names() []string {
 	out := make([]string, len(f.allowed))
 	for i, a := range f.allowed {
 		out[i] = algos[a]
 	}
-	return strings.Join(out, ", ")
+	return out
+}
+
+type algosFlag struct {
+	value   *[]tpm2.Algorithm
+	allowed []tpm2.Algorithm
+}
+
+func (f *algosFlag) Set(val string) error {
+	for _, name := range strings.Split(val, ",") {
+		var algo tpm2.Algorithm
+		single := algoFlag{&algo, f.allowed}
+		if err := single.Set(name); err != nil {
+			return fmt.Errorf("%q: %w", name, err)
+		}
+		*f.value = append(*f.value, algo)
+	}
+	return nil
+}
+
+func (f *algosFlag) Type() string {
+	return "algos"
+}
+
+func (f *algosFlag) String() string {
+	out := make([]string, len(*f.value))
+	for i, a := range *f.value {
+		out[i] = algos[a]
+	}
+	return strings.Join(out, ",")
+}
+
+// Allowed gives a string list of the permitted algorithm values for this flag.
+func (f *algosFlag) Allowed() string {
+	return (&algoFlag{allowed: f.allowed}).Allowed()
 }
 
 // Disable the "help" subcommand (and just use the -h/--help flags).
@@ -126,6 +194,14 @@ func addIndexFlag(cmd *cobra.Command) {
 		"NVDATA index, cannot be 0")
 }
 
+// Lets this command and its subcommands specify the output format, for use
+// with outputFormat.
+func addFormatFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().Var(&formatFlag{&outputFormat}, "format",
+		"output format: "+formatText+", "+formatJSON)
+	cmd.RegisterFlagCompletionFunc("format", completeValues(formatText, formatJSON))
+}
+
 // Lets this command specify some number of PCR arguments, check if in range.
 func addPCRsFlag(cmd *cobra.Command) {
 	cmd.PersistentFlags().Var(&pcrsFlag{&pcrs}, "pcrs", "comma separated list of PCR numbers")
@@ -135,11 +211,29 @@ func addPCRsFlag(cmd *cobra.Command) {
 func addPublicKeyAlgoFlag(cmd *cobra.Command) {
 	f := algoFlag{&keyAlgo, []tpm2.Algorithm{tpm2.AlgRSA, tpm2.AlgECC}}
 	cmd.PersistentFlags().Var(&f, "algo", "public key algorithm: "+f.Allowed())
+	cmd.RegisterFlagCompletionFunc("algo", completeValues(f.names()...))
 }
 
 func addHashAlgoFlag(cmd *cobra.Command, hashAlgo *tpm2.Algorithm) {
 	f := algoFlag{hashAlgo, []tpm2.Algorithm{tpm2.AlgSHA1, tpm2.AlgSHA256, tpm2.AlgSHA384, tpm2.AlgSHA512}}
 	cmd.PersistentFlags().Var(&f, "hash-algo", "hash algorithm: "+f.Allowed())
+	cmd.RegisterFlagCompletionFunc("hash-algo", completeValues(f.names()...))
+}
+
+// Lets this command specify a comma separated list of hash algorithms (i.e. PCR
+// banks).
+func addHashAlgosFlag(cmd *cobra.Command, hashAlgos *[]tpm2.Algorithm) {
+	f := algosFlag{hashAlgos, []tpm2.Algorithm{tpm2.AlgSHA1, tpm2.AlgSHA256, tpm2.AlgSHA384, tpm2.AlgSHA512}}
+	cmd.PersistentFlags().Var(&f, "hash-algo", "comma separated list of hash algorithms: "+f.Allowed())
+	cmd.RegisterFlagCompletionFunc("hash-algo", completeValues((&algoFlag{allowed: f.allowed}).names()...))
+}
+
+// completeValues completes a flag with one of values, for
+// cobra.Command.RegisterFlagCompletionFunc.
+func completeValues(values ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
 }
 
 // alwaysError implements io.ReadWriter by always returning an error
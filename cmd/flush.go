@@ -2,9 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"sort"
 
 	"github.com/google/go-tpm-tools/client"
 	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
 	"github.com/spf13/cobra"
 )
 
@@ -38,13 +41,16 @@ Which handles are flushed depends on the argument passed:
 	saved      - only flush the saved session handles
 	transient  - only flush the transient handles
 	all        - flush all loaded, saved, and transient handles
-	persistent - only evict the persistent handles`,
+	persistent - only evict the persistent handles
+
+With --format=json, the flushed handles are output as a JSON object.`,
 	ValidArgs: func() []string {
 		// The keys from the handleNames map are our valid arguments
-		keys := make([]string, len(handleNames))
+		keys := make([]string, 0, len(handleNames))
 		for k := range handleNames {
 			keys = append(keys, k)
 		}
+		sort.Strings(keys)
 		return keys
 	}(),
 	Args: cobra.ExactValidArgs(1),
@@ -55,7 +61,7 @@ Which handles are flushed depends on the argument passed:
 		}
 		defer rwc.Close()
 
-		totalHandles := 0
+		var flushed []tpmutil.Handle
 		for _, handleType := range handleNames[args[0]] {
 			handles, err := client.Handles(rwc, handleType)
 			if err != nil {
@@ -73,15 +79,32 @@ Which handles are flushed depends on the argument passed:
 					}
 					fmt.Fprintf(debugOutput(), "Handle 0x%x flushed\n", handle)
 				}
-				totalHandles++
+				flushed = append(flushed, handle)
 			}
 		}
-
-		fmt.Fprintf(messageOutput(), "%d handles flushed\n", totalHandles)
-		return nil
+		return writeFlushed(messageOutput(), flushed)
 	},
 }
 
+// flushResult is the output of the flush commands with --format=json.
+type flushResult struct {
+	// Flushed are the flushed and evicted handles, in hex.
+	Flushed []string `json:"flushed"`
+}
+
+// writeFlushed outputs the flushed handles in the format given by --format.
+func writeFlushed(w io.Writer, handles []tpmutil.Handle) error {
+	if outputFormat == formatJSON {
+		result := flushResult{Flushed: []string{}}
+		for _, handle := range handles {
+			result.Flushed = append(result.Flushed, fmt.Sprintf("0x%08x", uint32(handle)))
+		}
+		return writeJSON(w, result)
+	}
+	_, err := fmt.Fprintf(w, "%d handles flushed\n", len(handles))
+	return err
+}
+
 func init() {
 	RootCmd.AddCommand(flushCmd)
 }
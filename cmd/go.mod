@@ -3,9 +3,14 @@ module github.com/google/go-tpm-tools/cmd
 go 1.17
 
 require (
+	cloud.google.com/go/compute v1.7.0
+	github.com/golang-jwt/jwt/v4 v4.4.1
 	github.com/google/go-tpm v0.3.3
 	github.com/google/go-tpm-tools v0.3.10
+	github.com/google/go-tpm-tools/launcher v0.0.0
 	github.com/spf13/cobra v1.3.0
+	golang.org/x/oauth2 v0.0.0-20220622183110-fd043fe589d2
+	google.golang.org/api v0.86.0
 	google.golang.org/protobuf v1.28.0
 )
 
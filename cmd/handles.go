@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+	"github.com/spf13/cobra"
+)
+
+var handlesCmd = &cobra.Command{
+	Use:   "handles",
+	Short: "List and flush handles on the TPM",
+	Long: `List and flush handles on the TPM
+
+When the TPM runs out of memory for objects or sessions (TPM_RC_OBJECT_MEMORY
+or TPM_RC_SESSION_MEMORY), use "list" to find the leaked handles, and "flush"
+to close them.`,
+	Args: cobra.NoArgs,
+}
+
+// listedHandleTypes are the types of handles listed by "handles list", in
+// order.
+var listedHandleTypes = []tpm2.HandleType{
+	tpm2.HandleTypeLoadedSession,
+	tpm2.HandleTypeSavedSession,
+	tpm2.HandleTypeTransient,
+	tpm2.HandleTypePersistent,
+}
+
+// handleInfo describes an active handle. The name and public area are only
+// set for transient and persistent objects.
+type handleInfo struct {
+	Handle  string `json:"handle"`
+	Type    string `json:"type"`
+	KeyType string `json:"keyType,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Public  string `json:"public,omitempty"`
+}
+
+var handlesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the active handles on the TPM",
+	Long: `List the active handles on the TPM
+
+Lists the loaded session, saved session, transient and persistent handles. For
+transient and persistent objects, the key type and the hex-encoded name are
+listed.
+
+With --format=json, the handles are output as a JSON array, which also
+contains the hex-encoded TPMT_PUBLIC area of the objects.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rwc, err := openTpm()
+		if err != nil {
+			return err
+		}
+		defer rwc.Close()
+
+		var infos []handleInfo
+		for _, handleType := range listedHandleTypes {
+			handles, err := client.Handles(rwc, handleType)
+			if err != nil {
+				return fmt.Errorf("getting handles: %w", err)
+			}
+			for _, handle := range handles {
+				info, err := readHandleInfo(rwc, handle)
+				if err != nil {
+					return err
+				}
+				infos = append(infos, info)
+			}
+		}
+		return writeHandleInfos(dataOutput(), infos)
+	},
+}
+
+func readHandleInfo(rw io.ReadWriter, handle tpmutil.Handle) (handleInfo, error) {
+	info := handleInfo{
+		Handle: fmt.Sprintf("0x%08x", uint32(handle)),
+		Type:   handleTypeName(handle),
+	}
+	if handleType(handle) != tpm2.HandleTypeTransient && handleType(handle) != tpm2.HandleTypePersistent {
+		return info, nil
+	}
+	pub, name, _, err := tpm2.ReadPublic(rw, handle)
+	if err != nil {
+		return info, fmt.Errorf("reading public area of handle %s: %w", info.Handle, err)
+	}
+	encoded, err := pub.Encode()
+	if err != nil {
+		return info, fmt.Errorf("encoding public area of handle %s: %w", info.Handle, err)
+	}
+	info.KeyType = algos[pub.Type]
+	if info.KeyType == "" {
+		info.KeyType = pub.Type.String()
+	}
+	info.Name = hex.EncodeToString(name)
+	info.Public = hex.EncodeToString(encoded)
+	return info, nil
+}
+
+func writeHandleInfos(w io.Writer, infos []handleInfo) error {
+	if outputFormat == formatJSON {
+		if infos == nil {
+			infos = []handleInfo{}
+		}
+		return writeJSON(w, infos)
+	}
+	for _, info := range infos {
+		line := info.Handle + " " + info.Type
+		if info.Name != "" {
+			line += fmt.Sprintf(" %s name=%s", info.KeyType, info.Name)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func handleType(handle tpmutil.Handle) tpm2.HandleType {
+	return tpm2.HandleType(handle >> 24)
+}
+
+func handleTypeName(handle tpmutil.Handle) string {
+	switch handleType(handle) {
+	case tpm2.HandleTypeLoadedSession:
+		return "loaded"
+	case tpm2.HandleTypeSavedSession:
+		return "saved"
+	case tpm2.HandleTypeTransient:
+		return "transient"
+	case tpm2.HandleTypePersistent:
+		return "persistent"
+	}
+	return "unknown"
+}
+
+var handlesFlushCmd = &cobra.Command{
+	Use:   "flush <handle>...",
+	Short: "Flush the selected handles on the TPM",
+	Long: `Flush the selected handles on the TPM
+
+Session and transient handles are flushed, persistent handles are evicted from
+NVRAM. Handles are given in hex (e.g. 0x80000000) or decimal.
+
+To flush all the handles of a type, use "gotpm flush". With --format=json, the
+flushed handles are output as a JSON object.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var handles []tpmutil.Handle
+		for _, arg := range args {
+			handle, err := strconv.ParseUint(arg, 0, 32)
+			if err != nil {
+				return fmt.Errorf("invalid handle %q: %w", arg, err)
+			}
+			if handleTypeName(tpmutil.Handle(handle)) == "unknown" {
+				return fmt.Errorf("handle 0x%08x is not a session, transient or persistent handle", handle)
+			}
+			handles = append(handles, tpmutil.Handle(handle))
+		}
+
+		rwc, err := openTpm()
+		if err != nil {
+			return err
+		}
+		defer rwc.Close()
+
+		for _, handle := range handles {
+			if handleType(handle) == tpm2.HandleTypePersistent {
+				if err = tpm2.EvictControl(rwc, "", tpm2.HandleOwner, handle, handle); err != nil {
+					return fmt.Errorf("evicting handle 0x%x: %w", handle, err)
+				}
+				fmt.Fprintf(debugOutput(), "Handle 0x%x evicted\n", handle)
+			} else {
+				if err = tpm2.FlushContext(rwc, handle); err != nil {
+					return fmt.Errorf("flushing handle 0x%x: %w", handle, err)
+				}
+				fmt.Fprintf(debugOutput(), "Handle 0x%x flushed\n", handle)
+			}
+		}
+		return writeFlushed(messageOutput(), handles)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(handlesCmd)
+	handlesCmd.AddCommand(handlesListCmd)
+	handlesCmd.AddCommand(handlesFlushCmd)
+	addOutputFlag(handlesListCmd)
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm/tpm2"
+)
+
+func TestHandlesListAndFlush(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ExternalTPM = rwc
+
+	handle := test.LoadRandomExternalKey(t, rwc)
+	_, wantName, _, err := tpm2.ReadPublic(rwc, handle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outFile := makeTempFile(t, nil)
+	defer os.Remove(outFile)
+	RootCmd.SetArgs([]string{"handles", "list", "--format", "json", "--output", outFile})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	outputFormat = formatText
+	output = ""
+
+	contents, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var infos []handleInfo
+	if err := json.Unmarshal(contents, &infos); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, contents)
+	}
+	wantHandle := fmt.Sprintf("0x%08x", uint32(handle))
+	found := false
+	for _, info := range infos {
+		if info.Handle != wantHandle {
+			continue
+		}
+		found = true
+		if info.Type != "transient" || info.KeyType != "rsa" {
+			t.Errorf("got handle type %q key type %q, want transient rsa", info.Type, info.KeyType)
+		}
+		if info.Name != hex.EncodeToString(wantName) {
+			t.Errorf("got name %s, want %x", info.Name, wantName)
+		}
+		if info.Public == "" {
+			t.Error("missing public area")
+		}
+	}
+	if !found {
+		t.Fatalf("handle %s not listed in %s", wantHandle, contents)
+	}
+
+	RootCmd.SetArgs([]string{"handles", "flush", "--quiet", wantHandle})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	handles, err := client.Handles(rwc, tpm2.HandleTypeTransient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range handles {
+		if h == handle {
+			t.Errorf("handle %s was not flushed", wantHandle)
+		}
+	}
+}
+
+func TestHandlesFlushInvalidHandle(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ExternalTPM = rwc
+
+	for _, arg := range []string{"foo", "0x40000001", "0x1000000000"} {
+		RootCmd.SetArgs([]string{"handles", "flush", "--quiet", arg})
+		if err := RootCmd.Execute(); err == nil {
+			t.Errorf("flushing handle %q succeeded, want error", arg)
+		}
+	}
+}
@@ -3,6 +3,11 @@ package cmd
 import (
 	"fmt"
 	"io"
+	"os"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/simulator"
+	"github.com/google/go-tpm-tools/simulator/remote"
 )
 
 // ExternalTPM can be set to run tests against an TPM initialized by an
@@ -12,6 +17,23 @@ import (
 // by the external package.
 var ExternalTPM io.ReadWriter
 
+var (
+	useSimulator   bool
+	remoteTPM      remote.Config
+	remoteProtocol = string(remote.MSSIM)
+)
+
+func init() {
+	RootCmd.PersistentFlags().BoolVar(&useSimulator, "simulator", false,
+		"use the in-process TPM simulator instead of a TPM device (state is not preserved between runs)")
+	RootCmd.PersistentFlags().StringVar(&remoteTPM.Address, "tpm-address", "",
+		"host:port of an external TPM simulator to use instead of a TPM device")
+	RootCmd.PersistentFlags().StringVar(&remoteTPM.ControlAddress, "tpm-control-address", "",
+		"host:port of the external TPM simulator's platform or control channel, used to power cycle it")
+	RootCmd.PersistentFlags().StringVar(&remoteProtocol, "tpm-protocol", remoteProtocol,
+		"protocol of the external TPM simulator: mssim or swtpm")
+}
+
 type ignoreClose struct {
 	io.ReadWriter
 }
@@ -20,10 +42,40 @@ func (ic ignoreClose) Close() error {
 	return nil
 }
 
+// EventLog returns the event log of the external TPM, so it is used when
+// attesting.
+func (ic ignoreClose) EventLog() ([]byte, error) {
+	return client.GetEventLog(ic.ReadWriter)
+}
+
 func openTpm() (io.ReadWriteCloser, error) {
+	rwc, err := openTpmImpl()
+	if err != nil || !trace {
+		return rwc, err
+	}
+	return client.NewTracingTransport(rwc, os.Stderr), nil
+}
+
+func openTpmImpl() (io.ReadWriteCloser, error) {
 	if ExternalTPM != nil {
 		return ignoreClose{ExternalTPM}, nil
 	}
+	if useSimulator {
+		sim, err := simulator.Get()
+		if err != nil {
+			return nil, fmt.Errorf("starting TPM simulator: %w", err)
+		}
+		return sim, nil
+	}
+	if remoteTPM.Address != "" {
+		config := remoteTPM
+		config.Protocol = remote.Protocol(remoteProtocol)
+		conn, err := remote.Open(config)
+		if err != nil {
+			return nil, fmt.Errorf("connecting to TPM simulator: %w", err)
+		}
+		return conn, nil
+	}
 	rwc, err := openImpl()
 	if err != nil {
 		return nil, fmt.Errorf("connecting to TPM: %w", err)
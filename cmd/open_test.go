@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSimulatorFlag(t *testing.T) {
+	ExternalTPM = nil
+	defer func() { useSimulator = false }()
+
+	outFile := makeTempFile(t, nil)
+	defer os.Remove(outFile)
+	RootCmd.SetArgs([]string{"read", "pcr", "--simulator", "--output", outFile})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	output = ""
+
+	contents, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contents) == 0 {
+		t.Error("expected PCRs to be read from the simulator")
+	}
+}
+
+func TestRemoteTPMFlags(t *testing.T) {
+	ExternalTPM = nil
+	defer func() {
+		remoteTPM.Address = ""
+		remoteProtocol = "mssim"
+	}()
+
+	RootCmd.SetArgs([]string{"read", "pcr", "--tpm-address", "127.0.0.1:2321", "--tpm-protocol", "tcti"})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("expected an unknown protocol to fail")
+	}
+}
@@ -1,11 +1,11 @@
 package cmd
 
 import (
-	"crypto"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
 	"io"
+	"sort"
 
 	"github.com/google/go-tpm-tools/client"
 	"github.com/google/go-tpm/tpmutil"
@@ -35,13 +35,17 @@ A TPM can create a primary asymmetric key in one of 4 hierarchies:
 Furthermore, this key is based on a template containing parameters like
 algorithms and key sizes. By default, this command uses a standard template
 defined in the TPM2 spec. If --index is provided, the template is read from
-NVDATA instead (and --algo is ignored).`,
+NVDATA instead (and --algo is ignored).
+
+With --format=json, the key type and the PEM-formatted key are output in a JSON
+object.`,
 	ValidArgs: func() []string {
 		// The keys from the hierarchyNames map are our valid arguments
-		keys := make([]string, len(hierarchyNames))
+		keys := make([]string, 0, len(hierarchyNames))
 		for k := range hierarchyNames {
 			keys = append(keys, k)
 		}
+		sort.Strings(keys)
 		return keys
 	}(),
 	Args: cobra.ExactValidArgs(1),
@@ -58,7 +62,7 @@ NVDATA instead (and --algo is ignored).`,
 		}
 		defer key.Close()
 
-		return writeKey(key.PublicKey())
+		return writeKey(key)
 	},
 }
 
@@ -86,15 +90,30 @@ func getKey(rw io.ReadWriter, hierarchy tpmutil.Handle, algo tpm2.Algorithm) (*c
 	}
 }
 
-func writeKey(pubKey crypto.PublicKey) error {
+// pubkeyResult is the output of "pubkey" with --format=json.
+type pubkeyResult struct {
+	KeyType string `json:"keyType"`
+	PEM     string `json:"pem"`
+}
+
+func writeKey(key *client.Key) error {
+	pubKey := key.PublicKey()
 	fmt.Fprintf(debugOutput(), "Got key: %+v\n", pubKey)
 	asn1Bytes, err := x509.MarshalPKIXPublicKey(pubKey)
 	if err != nil {
 		return err
 	}
-
-	return pem.Encode(dataOutput(), &pem.Block{
+	block := &pem.Block{
 		Type:  "PUBLIC KEY",
 		Bytes: asn1Bytes,
-	})
+	}
+
+	if outputFormat == formatJSON {
+		keyType := algos[key.PublicArea().Type]
+		if keyType == "" {
+			keyType = key.PublicArea().Type.String()
+		}
+		return writeJSON(dataOutput(), pubkeyResult{KeyType: keyType, PEM: string(pem.EncodeToMemory(block))})
+	}
+	return pem.Encode(dataOutput(), block)
 }
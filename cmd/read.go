@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"strconv"
 
 	"github.com/google/go-tpm-tools/client"
 	"github.com/google/go-tpm-tools/internal"
+	pb "github.com/google/go-tpm-tools/proto/tpm"
 	"github.com/google/go-tpm/tpm2"
 	"github.com/google/go-tpm/tpmutil"
 	"github.com/spf13/cobra"
@@ -18,7 +22,7 @@ var readCmd = &cobra.Command{
 	Args:  cobra.NoArgs,
 }
 
-var pcrHashAlgo = tpm2.AlgUnknown
+var pcrHashAlgos []tpm2.Algorithm
 
 var pcrCmd = &cobra.Command{
 	Use:   "pcr",
@@ -27,8 +31,12 @@ var pcrCmd = &cobra.Command{
 
 Based on --hash-algo and --pcrs flags, read the contents of the TPM's PCRs.
 
-If --hash-algo is not provided, all banks of PCRs will be read.
-If --pcrs is not provided, all PCRs are read for that hash algorithm.`,
+If --hash-algo is not provided, all banks of PCRs will be read. Multiple banks
+can be read by passing a comma separated list of hash algorithms.
+If --pcrs is not provided, all PCRs are read for the selected hash algorithms.
+
+With --format=json, the PCRs are output as a JSON object mapping each hash
+algorithm to the hex-encoded values of the selected PCRs.`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		rwc, err := openTpm()
@@ -37,36 +45,61 @@ If --pcrs is not provided, all PCRs are read for that hash algorithm.`,
 		}
 		defer rwc.Close()
 
-		if pcrHashAlgo != tpm2.AlgUnknown {
-			sel := tpm2.PCRSelection{Hash: pcrHashAlgo, PCRs: pcrs}
-			if len(sel.PCRs) == 0 {
-				sel = client.FullPcrSel(sel.Hash)
+		var banks []*pb.PCRs
+		if len(pcrHashAlgos) != 0 {
+			for _, hashAlgo := range pcrHashAlgos {
+				sel := tpm2.PCRSelection{Hash: hashAlgo, PCRs: pcrs}
+				if len(sel.PCRs) == 0 {
+					sel = client.FullPcrSel(sel.Hash)
+				}
+
+				fmt.Fprintf(debugOutput(), "Reading %v PCRs (%v)\n", sel.Hash, sel.PCRs)
+				bank, err := client.ReadPCRs(rwc, sel)
+				if err != nil {
+					return err
+				}
+				banks = append(banks, bank)
+			}
+		} else {
+			if len(pcrs) != 0 {
+				return errors.New("--hash-algo must be used with --pcrs")
 			}
 
-			fmt.Fprintf(debugOutput(), "Reading %v PCRs (%v)\n", sel.Hash, sel.PCRs)
-			pcrs, err := client.ReadPCRs(rwc, sel)
-			if err != nil {
+			fmt.Fprintln(debugOutput(), "Reading all PCRs")
+			if banks, err = client.ReadAllPCRs(rwc); err != nil {
 				return err
 			}
-			return internal.FormatPCRs(dataOutput(), pcrs)
 		}
-		if len(pcrs) != 0 {
-			return errors.New("--hash-algo must be used with --pcrs")
+		return writePCRs(dataOutput(), banks)
+	},
+}
+
+// writePCRs outputs the PCR banks in the format given by --format.
+func writePCRs(w io.Writer, banks []*pb.PCRs) error {
+	if outputFormat == formatJSON {
+		out := make(map[string]map[string]string, len(banks))
+		for _, bank := range banks {
+			values := make(map[string]string, len(bank.GetPcrs()))
+			for idx, val := range bank.GetPcrs() {
+				values[strconv.FormatUint(uint64(idx), 10)] = hex.EncodeToString(val)
+			}
+			out[algos[tpm2.Algorithm(bank.GetHash())]] = values
 		}
+		return writeJSON(w, out)
+	}
 
-		fmt.Fprintln(debugOutput(), "Reading all PCRs")
-		banks, err := client.ReadAllPCRs(rwc)
-		if err != nil {
+	for _, bank := range banks {
+		if err := internal.FormatPCRs(w, bank); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		for _, bank := range banks {
-			if err = internal.FormatPCRs(dataOutput(), bank); err != nil {
-				return err
-			}
-		}
-		return nil
-	},
+// nvDataResult is the output of "read nvdata" with --format=json.
+type nvDataResult struct {
+	Index uint32 `json:"index"`
+	Data  string `json:"data"`
 }
 
 var nvReadCmd = &cobra.Command{
@@ -75,7 +108,9 @@ var nvReadCmd = &cobra.Command{
 	Long: `Read NVData at a particular NVIndex
 
 Based on the --index flag, this reads all of the NVData present at that NVIndex.
-The read is authenticated with the owner hierarchy and an empty password.`,
+The read is authenticated with the owner hierarchy and an empty password.
+
+With --format=json, the NVData is output hex-encoded in a JSON object.`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		rwc, err := openTpm()
@@ -88,6 +123,9 @@ The read is authenticated with the owner hierarchy and an empty password.`,
 		if err != nil {
 			return err
 		}
+		if outputFormat == formatJSON {
+			return writeJSON(dataOutput(), nvDataResult{Index: nvIndex, Data: hex.EncodeToString(data)})
+		}
 		if _, err := dataOutput().Write(data); err != nil {
 			return fmt.Errorf("cannot output NVData: %w", err)
 		}
@@ -95,13 +133,58 @@ The read is authenticated with the owner hierarchy and an empty password.`,
 	},
 }
 
+// tpmInfoResult is the output of "read info" with --format=json.
+type tpmInfoResult struct {
+	Manufacturer    string `json:"manufacturer"`
+	VendorString    string `json:"vendor_string"`
+	FirmwareVersion string `json:"firmware_version"`
+	SpecRevision    uint32 `json:"spec_revision"`
+}
+
+var infoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Read the TPM manufacturer and firmware version",
+	Long: `Read the TPM manufacturer and firmware version
+
+The firmware version is vendor-specific, and output as a hex number with
+TPM_PT_FIRMWARE_VERSION_1 in the high 32 bits. A verifier can require a
+minimum version with attest.PlatformPolicy.minimum_tpm_firmware_version.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rwc, err := openTpm()
+		if err != nil {
+			return err
+		}
+		defer rwc.Close()
+
+		info, err := client.GetTPMInfo(rwc)
+		if err != nil {
+			return err
+		}
+		result := tpmInfoResult{
+			Manufacturer:    info.Manufacturer,
+			VendorString:    info.VendorString,
+			FirmwareVersion: fmt.Sprintf("%#016x", info.FirmwareVersion),
+			SpecRevision:    info.SpecRevision,
+		}
+		if outputFormat == formatJSON {
+			return writeJSON(dataOutput(), result)
+		}
+		fmt.Fprintf(dataOutput(), "Manufacturer: %s\nVendor: %s\nFirmware version: %s\nSpec revision: %d\n",
+			result.Manufacturer, result.VendorString, result.FirmwareVersion, result.SpecRevision)
+		return nil
+	},
+}
+
 func init() {
 	RootCmd.AddCommand(readCmd)
 	readCmd.AddCommand(pcrCmd)
 	readCmd.AddCommand(nvReadCmd)
+	readCmd.AddCommand(infoCmd)
+	addOutputFlag(infoCmd)
 	addOutputFlag(pcrCmd)
 	addPCRsFlag(pcrCmd)
-	addHashAlgoFlag(pcrCmd, &pcrHashAlgo)
+	addHashAlgosFlag(pcrCmd, &pcrHashAlgos)
 	addIndexFlag(nvReadCmd)
 	nvReadCmd.MarkPersistentFlagRequired("index")
 	addOutputFlag(nvReadCmd)
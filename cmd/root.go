@@ -2,11 +2,13 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 
 	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/encoding/prototext"
 )
 
@@ -16,11 +18,17 @@ var RootCmd = &cobra.Command{
 	Long: `Command line tool for the go-tpm TSS
 
 This tool allows performing TPM2 operations from the command line.
-See the per-command documentation for more information.`,
+See the per-command documentation for more information.
+
+With --format=json, every command outputs a single JSON value, so it can be
+consumed by scripts. Run "gotpm completion --help" to set up shell completion.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		if quiet && verbose {
 			return fmt.Errorf("cannot specify both --quiet and --verbose")
 		}
+		if useSimulator && cmd.Flags().Changed("tpm-path") {
+			return fmt.Errorf("cannot specify both --simulator and --tpm-path")
+		}
 		cmd.SilenceUsage = true
 		return nil
 	},
@@ -29,6 +37,7 @@ See the per-command documentation for more information.`,
 var (
 	quiet   bool
 	verbose bool
+	trace   bool
 )
 
 func init() {
@@ -36,6 +45,9 @@ func init() {
 		"print nothing if command is successful")
 	RootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false,
 		"print additional info to stdout")
+	RootCmd.PersistentFlags().BoolVar(&trace, "trace", false,
+		"print each TPM command and its response code to stderr")
+	addFormatFlag(RootCmd)
 	hideHelp(RootCmd)
 }
 
@@ -59,3 +71,16 @@ var marshalOptions = prototext.MarshalOptions{
 	EmitASCII: true,
 }
 var unmarshalOptions = prototext.UnmarshalOptions{}
+
+// JSON Marshalling options, for --format=json
+var jsonMarshalOptions = protojson.MarshalOptions{
+	Multiline: true,
+}
+var jsonUnmarshalOptions = protojson.UnmarshalOptions{}
+
+// writeJSON writes v as indented JSON, for --format=json.
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func complete(t *testing.T, args ...string) []string {
+	t.Helper()
+	var out bytes.Buffer
+	RootCmd.SetOut(&out)
+	defer RootCmd.SetOut(nil)
+	RootCmd.SetArgs(append([]string{"__complete"}, args...))
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	// The last line is the completion directive.
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	return lines[:len(lines)-1]
+}
+
+func TestCompletion(t *testing.T) {
+	testCases := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"Format", []string{"read", "pcr", "--format", ""}, "json"},
+		{"FormatOnAnyCommand", []string{"seal", "--format", ""}, "text"},
+		{"HashAlgo", []string{"extend", "--hash-algo", ""}, "sha384"},
+		{"PublicKeyAlgo", []string{"pubkey", "--algo", ""}, "ecc"},
+		{"TokenKey", []string{"token", "--key", ""}, "gce-ak"},
+		{"FlushArgs", []string{"flush", ""}, "persistent"},
+		{"PubkeyArgs", []string{"pubkey", ""}, "endorsement"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := complete(t, tc.args...)
+			found := false
+			for _, c := range got {
+				if c == "" {
+					t.Errorf("got an empty completion in %q", got)
+				}
+				if c == tc.want {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("got completions %q, want %q among them", got, tc.want)
+			}
+		})
+	}
+}
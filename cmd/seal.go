@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/hex"
 	"fmt"
 	"io"
 
@@ -25,7 +27,10 @@ the encryption.
 Optionally (using the --pcrs flag), this decryption can be furthur restricted to
 only work if certain Platform Control Registers (PCRs) are in the correct state.
 This allows a key (i.e. a disk encryption key) to be bound to specific machine
-state (like Secure Boot).`,
+state (like Secure Boot).
+
+The sealed data is output in the protobuf text format, or in the protobuf JSON
+format with --format=json. "gotpm unseal" accepts both.`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		rwc, err := openTpm()
@@ -58,7 +63,12 @@ state (like Secure Boot).`,
 
 		fmt.Fprintln(debugOutput(), "Writing sealed data")
 		var output []byte
-		if output, err = marshalOptions.Marshal(sealed); err != nil {
+		if outputFormat == formatJSON {
+			output, err = jsonMarshalOptions.Marshal(sealed)
+		} else {
+			output, err = marshalOptions.Marshal(sealed)
+		}
+		if err != nil {
 			return err
 		}
 		if _, err = dataOutput().Write(output); err != nil {
@@ -85,6 +95,8 @@ We do support an optional "certification" process. A list of PCRs may be
 provided with --pcrs, and the unwrapping will fail if the PCR values when
 sealing differ from the current PCR values. This allows for verification of the
 machine state when sealing took place.
+
+With --format=json, the secret is output hex-encoded in a JSON object.
 `,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -100,7 +112,7 @@ machine state when sealing took place.
 			return err
 		}
 		var sealed pb.SealedBytes
-		if err := unmarshalOptions.Unmarshal(data, &sealed); err != nil {
+		if err := unmarshalSealed(data, &sealed); err != nil {
 			return err
 		}
 
@@ -123,7 +135,12 @@ machine state when sealing took place.
 		}
 
 		fmt.Fprintln(debugOutput(), "Writing secret data")
-		if _, err := dataOutput().Write(secret); err != nil {
+		if outputFormat == formatJSON {
+			err = writeJSON(dataOutput(), unsealResult{Secret: hex.EncodeToString(secret)})
+		} else {
+			_, err = dataOutput().Write(secret)
+		}
+		if err != nil {
 			return fmt.Errorf("writing secret data: %w", err)
 		}
 		fmt.Fprintln(debugOutput(), "Unsealed data using TPM")
@@ -131,6 +148,20 @@ machine state when sealing took place.
 	},
 }
 
+// unsealResult is the output of "unseal" with --format=json.
+type unsealResult struct {
+	Secret string `json:"secret"`
+}
+
+// unmarshalSealed parses the output of "gotpm seal", in the protobuf JSON
+// format if it is a JSON object, in the protobuf text format otherwise.
+func unmarshalSealed(data []byte, sealed *pb.SealedBytes) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		return jsonUnmarshalOptions.Unmarshal(data, sealed)
+	}
+	return unmarshalOptions.Unmarshal(data, sealed)
+}
+
 func init() {
 	RootCmd.AddCommand(sealCmd)
 	RootCmd.AddCommand(unsealCmd)
@@ -3,6 +3,8 @@ package cmd
 import (
 	"bytes"
 	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"os"
 	"strconv"
 	"testing"
@@ -90,6 +92,50 @@ func TestSealPlain(t *testing.T) {
 	}
 }
 
+func TestSealUnsealJSON(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ExternalTPM = rwc
+
+	secretIn := []byte("Hello")
+	secretFile := makeTempFile(t, secretIn)
+	defer os.Remove(secretFile)
+	sealedFile := makeTempFile(t, nil)
+	defer os.Remove(sealedFile)
+	unsealedFile := makeTempFile(t, nil)
+	defer os.Remove(unsealedFile)
+
+	RootCmd.SetArgs([]string{"seal", "--quiet", "--format", "json", "--input", secretFile, "--output", sealedFile})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := os.ReadFile(sealedFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(sealed) {
+		t.Fatalf("sealed data is not valid JSON:\n%s", sealed)
+	}
+
+	// unseal reads the JSON sealed data.
+	RootCmd.SetArgs([]string{"unseal", "--quiet", "--format", "json", "--input", sealedFile, "--output", unsealedFile})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	outputFormat = formatText
+	unsealed, err := os.ReadFile(unsealedFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result unsealResult
+	if err := json.Unmarshal(unsealed, &result); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, unsealed)
+	}
+	if result.Secret != hex.EncodeToString(secretIn) {
+		t.Errorf("got secret %s, want %x", result.Secret, secretIn)
+	}
+}
+
 func TestUnsealFail(t *testing.T) {
 	rwc := test.GetTPM(t)
 	defer client.CheckedClose(t, rwc)
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+
+	"cloud.google.com/go/compute/metadata"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/launcher/agent"
+	"github.com/google/go-tpm-tools/launcher/verifier"
+	"github.com/google/go-tpm-tools/launcher/verifier/rest"
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/option"
+)
+
+// tokenConfig configures the Attestation Verifier used by the token command.
+// It is read from the --config file as JSON, and overridden by the flags.
+type tokenConfig struct {
+	// VerifierEndpoint defaults to https://confidentialcomputing.googleapis.com.
+	VerifierEndpoint string `json:"verifier_endpoint"`
+	// ProjectID and Region default to those of the instance on GCE.
+	ProjectID string `json:"project_id"`
+	Region    string `json:"region"`
+}
+
+var (
+	tokenConfigFile string
+	tokenFlags      tokenConfig
+	tokenKey        = "gce-ak"
+)
+
+// tokenKeys are the attestation keys the token command can attest with.
+var tokenKeys = map[string]func(io.ReadWriter) (*client.Key, error){
+	"gce-ak": client.GceAttestationKeyECC,
+	"ak":     client.AttestationKeyECC,
+}
+
+// newTokenVerifier returns the verifier.Client for the configuration, a
+// variable for testing.
+var newTokenVerifier = func(ctx context.Context, config tokenConfig) (verifier.Client, error) {
+	httpClient, err := google.DefaultClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("creating HTTP client: %w", err)
+	}
+	opts := []option.ClientOption{option.WithHTTPClient(httpClient)}
+	if config.VerifierEndpoint != "" {
+		opts = append(opts, option.WithEndpoint(config.VerifierEndpoint))
+	}
+	return rest.NewClient(ctx, config.ProjectID, config.Region, opts...)
+}
+
+// fetchPrincipalTokens returns the ID tokens of the default service account
+// of the instance for the audience, or none when not on GCE. It is a
+// variable for testing.
+var fetchPrincipalTokens = func(audience string) ([][]byte, error) {
+	if !metadata.OnGCE() {
+		return nil, nil
+	}
+	u := url.URL{
+		Path: "instance/service-accounts/default/identity",
+		RawQuery: url.Values{
+			"audience": {audience},
+			"format":   {"full"},
+		}.Encode(),
+	}
+	idToken, err := metadata.Get(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("getting principal token: %w", err)
+	}
+	return [][]byte{[]byte(idToken)}, nil
+}
+
+// tokenResult is the output of "token" with --format=json.
+type tokenResult struct {
+	Token string `json:"token"`
+}
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Fetch an OIDC token from the Attestation Verifier",
+	Long: `Attest this machine to the Attestation Verifier and print the OIDC token
+
+This runs the same attestation as the Confidential Space launcher: the TPM
+quotes the PCRs with an attestation key, and the quotes, event logs and
+Canonical Event Log (empty, as no workload is measured) are verified by the
+Attestation Verifier, which returns a signed OIDC token (a JWT).
+
+The verifier is configured with --config, a JSON file with the fields
+"verifier_endpoint", "project_id" and "region", and the flags of
+the same names, which take precedence. On GCE, the project and region default
+to those of the instance, and the identity token of the instance's default
+service account is sent to the verifier.
+
+With --format=json, the token is output in a JSON object.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := getTokenConfig(cmd)
+		if err != nil {
+			return err
+		}
+		akFetcher, ok := tokenKeys[tokenKey]
+		if !ok {
+			return fmt.Errorf("unknown key %q, expected gce-ak or ak", tokenKey)
+		}
+
+		ctx := context.Background()
+		verifierClient, err := newTokenVerifier(ctx, config)
+		if err != nil {
+			return fmt.Errorf("creating verifier client: %w", err)
+		}
+
+		rwc, err := openTpm()
+		if err != nil {
+			return err
+		}
+		defer rwc.Close()
+
+		fmt.Fprintf(debugOutput(), "Attesting with the %s to project %q, region %q\n", tokenKey, config.ProjectID, config.Region)
+		attestAgent := agent.CreateAttestationAgent(rwc, akFetcher, verifierClient, fetchPrincipalTokens)
+		token, err := attestAgent.Attest(ctx)
+		if err != nil {
+			return fmt.Errorf("attesting: %w", err)
+		}
+		if outputFormat == formatJSON {
+			err = writeJSON(dataOutput(), tokenResult{Token: string(token)})
+		} else {
+			_, err = fmt.Fprintln(dataOutput(), string(token))
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(debugOutput(), "Fetched OIDC token")
+		return nil
+	},
+}
+
+// getTokenConfig merges the --config file and the flags set on cmd, and fills
+// in the defaults from the metadata server on GCE.
+func getTokenConfig(cmd *cobra.Command) (tokenConfig, error) {
+	var config tokenConfig
+	if tokenConfigFile != "" {
+		data, err := os.ReadFile(tokenConfigFile)
+		if err != nil {
+			return tokenConfig{}, err
+		}
+		if err := json.Unmarshal(data, &config); err != nil {
+			return tokenConfig{}, fmt.Errorf("parsing %s: %w", tokenConfigFile, err)
+		}
+	}
+	for _, field := range []struct {
+		flag, value string
+		dst         *string
+	}{
+		{"verifier-endpoint", tokenFlags.VerifierEndpoint, &config.VerifierEndpoint},
+		{"project-id", tokenFlags.ProjectID, &config.ProjectID},
+		{"region", tokenFlags.Region, &config.Region},
+	} {
+		if cmd.Flags().Changed(field.flag) {
+			*field.dst = field.value
+		}
+	}
+
+	if (config.ProjectID == "" || config.Region == "") && metadata.OnGCE() {
+		if config.ProjectID == "" {
+			projectID, err := metadata.ProjectID()
+			if err != nil {
+				return tokenConfig{}, fmt.Errorf("getting the project ID: %w", err)
+			}
+			config.ProjectID = projectID
+		}
+		if config.Region == "" {
+			zone, err := metadata.Zone()
+			if err != nil {
+				return tokenConfig{}, fmt.Errorf("getting the zone: %w", err)
+			}
+			config.Region = zoneRegion(zone)
+		}
+	}
+	if config.ProjectID == "" || config.Region == "" {
+		return tokenConfig{}, fmt.Errorf("the project ID and region of the verifier must be set")
+	}
+	return config, nil
+}
+
+// zoneRegion returns the region of a GCE zone, e.g. us-central1 for
+// us-central1-a.
+func zoneRegion(zone string) string {
+	zone = path.Base(zone)
+	for i := len(zone) - 1; i >= 0; i-- {
+		if zone[i] == '-' {
+			return zone[:i]
+		}
+	}
+	return zone
+}
+
+func init() {
+	RootCmd.AddCommand(tokenCmd)
+	addOutputFlag(tokenCmd)
+	tokenCmd.PersistentFlags().StringVar(&tokenConfigFile, "config", "",
+		"JSON file configuring the verifier")
+	tokenCmd.PersistentFlags().StringVar(&tokenFlags.VerifierEndpoint, "verifier-endpoint", "",
+		"endpoint of the Attestation Verifier (defaults to https://confidentialcomputing.googleapis.com)")
+	tokenCmd.PersistentFlags().StringVar(&tokenFlags.ProjectID, "project-id", "",
+		"project of the verifier (defaults to the instance's project on GCE)")
+	tokenCmd.PersistentFlags().StringVar(&tokenFlags.Region, "region", "",
+		"region of the verifier (defaults to the instance's region on GCE)")
+	tokenCmd.PersistentFlags().StringVar(&tokenKey, "key", tokenKey,
+		"attestation key: gce-ak (the GCE AK, with its certificate) or ak (the TCG default ECC AK)")
+	tokenCmd.RegisterFlagCompletionFunc("key", completeValues("gce-ak", "ak"))
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm-tools/launcher/verifier"
+	"github.com/google/go-tpm-tools/launcher/verifier/fake"
+)
+
+// recordingVerifier records the requests of a fake verifier.
+type recordingVerifier struct {
+	verifier.Client
+	requests []verifier.VerifyAttestationRequest
+}
+
+func (v *recordingVerifier) VerifyAttestation(ctx context.Context, request verifier.VerifyAttestationRequest) (*verifier.VerifyAttestationResponse, error) {
+	v.requests = append(v.requests, request)
+	return v.Client.VerifyAttestation(ctx, request)
+}
+
+func TestToken(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ExternalTPM = rwc
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeVerifier := &recordingVerifier{Client: fake.NewClient(key)}
+	var gotConfig tokenConfig
+	oldVerifier, oldPrincipal := newTokenVerifier, fetchPrincipalTokens
+	newTokenVerifier = func(_ context.Context, config tokenConfig) (verifier.Client, error) {
+		gotConfig = config
+		return fakeVerifier, nil
+	}
+	fetchPrincipalTokens = func(string) ([][]byte, error) { return [][]byte{[]byte("id-token")}, nil }
+	defer func() { newTokenVerifier, fetchPrincipalTokens = oldVerifier, oldPrincipal }()
+
+	configFile := makeTempFile(t, []byte(`{"verifier_endpoint": "https://verifier.example.com", "project_id": "config-project", "region": "us-central1"}`))
+	defer os.Remove(configFile)
+	tokenFile := makeTempFile(t, nil)
+	defer os.Remove(tokenFile)
+
+	RootCmd.SetArgs([]string{"token", "--key", "ak", "--config", configFile,
+		"--project-id", "flag-project", "--output", tokenFile})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	output = ""
+	tokenConfigFile = ""
+	tokenKey = "gce-ak"
+	tokenFlags = tokenConfig{}
+
+	want := tokenConfig{
+		VerifierEndpoint: "https://verifier.example.com",
+		ProjectID:        "flag-project",
+		Region:           "us-central1",
+	}
+	if gotConfig != want {
+		t.Errorf("got config %+v, want %+v", gotConfig, want)
+	}
+	if len(fakeVerifier.requests) != 1 {
+		t.Fatalf("got %d verifier requests, want 1", len(fakeVerifier.requests))
+	}
+	request := fakeVerifier.requests[0]
+	if len(request.GcpCredentials) != 1 || string(request.GcpCredentials[0]) != "id-token" {
+		t.Errorf("got GCP credentials %q, want the principal token", request.GcpCredentials)
+	}
+
+	data, err := os.ReadFile(tokenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jwt.Parse(strings.TrimSpace(string(data)), func(*jwt.Token) (interface{}, error) {
+		return key.Public(), nil
+	}); err != nil {
+		t.Errorf("failed to parse the token: %v", err)
+	}
+}
+
+func TestTokenMissingRegion(t *testing.T) {
+	configFile := makeTempFile(t, []byte(`{"project_id": "config-project"}`))
+	defer os.Remove(configFile)
+
+	tokenConfigFile = configFile
+	defer func() { tokenConfigFile = "" }()
+	if _, err := getTokenConfig(tokenCmd); err == nil {
+		t.Error("getTokenConfig() succeeded without a region")
+	}
+}
+
+func TestZoneRegion(t *testing.T) {
+	for zone, want := range map[string]string{
+		"us-central1-a":                     "us-central1",
+		"projects/123/zones/europe-west4-b": "europe-west4",
+	} {
+		if got := zoneRegion(zone); got != want {
+			t.Errorf("zoneRegion(%q) = %q, want %q", zone, got, want)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package internal
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+)
+
+var oidExtensionSubjectAltName = asn1.ObjectIdentifier{2, 5, 29, 17}
+
+// HandleTPMSubjectAltName returns a copy of the EK or AK certificate, whose
+// critical SAN extension is no longer marked as unhandled, so the copy can be
+// verified with x509. These certificates have a critical SAN extension with
+// the TPM manufacturer, model and version in a directory name, which x509
+// marks as unhandled, as it does not parse any of its DNS names, email
+// addresses, IP addresses or URIs.
+func HandleTPMSubjectAltName(cert *x509.Certificate) *x509.Certificate {
+	handled := *cert
+	handled.UnhandledCriticalExtensions = nil
+	for _, ext := range cert.UnhandledCriticalExtensions {
+		if !ext.Equal(oidExtensionSubjectAltName) {
+			handled.UnhandledCriticalExtensions = append(handled.UnhandledCriticalExtensions, ext)
+		}
+	}
+	return &handled
+}
@@ -5,7 +5,9 @@ import (
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/subtle"
+	"errors"
 	"fmt"
 
 	pb "github.com/google/go-tpm-tools/proto/tpm"
@@ -16,6 +18,43 @@ import (
 // their preferred order of use.
 var SignatureHashAlgs = []tpm2.Algorithm{tpm2.AlgSHA512, tpm2.AlgSHA384, tpm2.AlgSHA256}
 
+// Errors returned by VerifyQuote, wrapped with details, so callers can tell the
+// reasons for a failure apart.
+var (
+	ErrQuoteSignature = errors.New("invalid quote signature")
+	ErrQuoteExtraData = errors.New("quote extraData mismatch")
+	ErrQuotePCRs      = errors.New("quote PCRs mismatch")
+)
+
+// QuoteDataDigest returns the extraData used to bind data to a Quote. The TPM
+// limits extraData to the size of its largest digest, so arbitrarily large data
+// (e.g. a CSR or a manifest) is bound by quoting its SHA-256 digest instead.
+func QuoteDataDigest(data []byte) []byte {
+	digest := sha256.Sum256(data)
+	return digest[:]
+}
+
+// NonceExtraData returns the extraData used to quote a nonce with a TPM whose
+// largest digest is maxDigestSize bytes: the nonce itself if it fits, or its
+// QuoteDataDigest otherwise.
+func NonceExtraData(nonce []byte, maxDigestSize int) []byte {
+	if len(nonce) <= maxDigestSize {
+		return nonce
+	}
+	return QuoteDataDigest(nonce)
+}
+
+// VerifyQuoteNonce is like VerifyQuote, with the extraData of a quote of the
+// nonce by NonceExtraData. Nonces larger than a SHA-256 digest may have been
+// hashed, depending on the TPM, so both forms are accepted for them.
+func VerifyQuoteNonce(q *pb.Quote, trustedPub crypto.PublicKey, nonce []byte) error {
+	err := VerifyQuote(q, trustedPub, nonce)
+	if errors.Is(err, ErrQuoteExtraData) && len(nonce) > sha256.Size {
+		return VerifyQuote(q, trustedPub, QuoteDataDigest(nonce))
+	}
+	return err
+}
+
 // VerifyQuote performs the following checks to validate a Quote:
 //   - the provided signature is generated by the trusted AK public key
 //   - the signature signs the provided quote data
@@ -31,46 +70,96 @@ var SignatureHashAlgs = []tpm2.Algorithm{tpm2.AlgSHA512, tpm2.AlgSHA384, tpm2.Al
 //
 // VerifyQuote supports ECDSA and RSASSA signature verification.
 func VerifyQuote(q *pb.Quote, trustedPub crypto.PublicKey, extraData []byte) error {
-	sig, err := tpm2.DecodeSignature(bytes.NewBuffer(q.GetRawSig()))
+	attestationData, sig, err := verifyAttestationData(q.GetQuote(), q.GetRawSig(), trustedPub)
 	if err != nil {
-		return fmt.Errorf("signature decoding failed: %v", err)
+		return err
 	}
-
 	hash, err := verifyHashAlg(sig)
 	if err != nil {
 		return err
 	}
+	if attestationData.Type != tpm2.TagAttestQuote {
+		return fmt.Errorf("expected quote tag, got: %v", attestationData.Type)
+	}
+	attestedQuoteInfo := attestationData.AttestedQuoteInfo
+	if attestedQuoteInfo == nil {
+		return fmt.Errorf("attestation data does not contain quote info")
+	}
+	if subtle.ConstantTimeCompare(attestationData.ExtraData, extraData) == 0 {
+		return fmt.Errorf("%w: quote extraData %v did not match expected extraData %v",
+			ErrQuoteExtraData, attestationData.ExtraData, extraData)
+	}
+	return validatePCRDigest(attestedQuoteInfo, q.GetPcrs(), hash)
+}
+
+// VerifyCertify performs the following checks to validate the TPMS_ATTEST
+// data of a TPM2_Certify, with its raw TPMT_SIGNATURE:
+//   - the provided signature is generated by the trusted AK public key
+//   - the signature signs the provided attestation data
+//   - the attestation data starts with TPM_GENERATED_VALUE
+//   - the attestation data is a valid TPMS_CERTIFY_INFO
+//   - the certified name is the name of the provided public area
+//   - the provided extraData matches that in the attestation data
+//   - the signature hash algorithm must be in SignatureHashAlgs
+//
+// It returns the signature, whose scheme and hash algorithm callers may
+// restrict further. As with VerifyQuote, the caller must have already
+// established trust in the provided public key.
+func VerifyCertify(attestation []byte, rawSig []byte, trustedPub crypto.PublicKey, certified tpm2.Public, extraData []byte) (*tpm2.Signature, error) {
+	attestationData, sig, err := verifyAttestationData(attestation, rawSig, trustedPub)
+	if err != nil {
+		return nil, err
+	}
+	if attestationData.Type != tpm2.TagAttestCertify {
+		return nil, fmt.Errorf("expected certify tag, got: %v", attestationData.Type)
+	}
+	certifyInfo := attestationData.AttestedCertifyInfo
+	if certifyInfo == nil {
+		return nil, fmt.Errorf("attestation data does not contain certify info")
+	}
+	if matches, err := certifyInfo.Name.MatchesPublic(certified); err != nil {
+		return nil, fmt.Errorf("comparing the certified name failed: %v", err)
+	} else if !matches {
+		return nil, fmt.Errorf("certified name does not match the public area")
+	}
+	if subtle.ConstantTimeCompare(attestationData.ExtraData, extraData) == 0 {
+		return nil, fmt.Errorf("certify extraData %v did not match expected extraData %v", attestationData.ExtraData, extraData)
+	}
+	return sig, nil
+}
+
+// verifyAttestationData checks that the raw signature of the TPMS_ATTEST data
+// was generated by the trusted public key, and decodes them.
+func verifyAttestationData(attestation []byte, rawSig []byte, trustedPub crypto.PublicKey) (*tpm2.AttestationData, *tpm2.Signature, error) {
+	sig, err := tpm2.DecodeSignature(bytes.NewBuffer(rawSig))
+	if err != nil {
+		return nil, nil, fmt.Errorf("signature decoding failed: %v", err)
+	}
+
+	hash, err := verifyHashAlg(sig)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	switch pub := trustedPub.(type) {
 	case *ecdsa.PublicKey:
-		if err = verifyECDSAQuoteSignature(pub, hash, q.GetQuote(), sig); err != nil {
-			return err
+		if err = verifyECDSAQuoteSignature(pub, hash, attestation, sig); err != nil {
+			return nil, nil, err
 		}
 	case *rsa.PublicKey:
-		if err = verifyRSASSAQuoteSignature(pub, hash, q.GetQuote(), sig); err != nil {
-			return err
+		if err = verifyRSASSAQuoteSignature(pub, hash, attestation, sig); err != nil {
+			return nil, nil, err
 		}
 	default:
-		return fmt.Errorf("only RSA and ECC public keys are currently supported, received type: %T", pub)
+		return nil, nil, fmt.Errorf("only RSA and ECC public keys are currently supported, received type: %T", pub)
 	}
 
 	// Decode and check for magic TPMS_GENERATED_VALUE.
-	attestationData, err := tpm2.DecodeAttestationData(q.GetQuote())
+	attestationData, err := tpm2.DecodeAttestationData(attestation)
 	if err != nil {
-		return fmt.Errorf("decoding attestation data failed: %v", err)
-	}
-	if attestationData.Type != tpm2.TagAttestQuote {
-		return fmt.Errorf("expected quote tag, got: %v", attestationData.Type)
-	}
-	attestedQuoteInfo := attestationData.AttestedQuoteInfo
-	if attestedQuoteInfo == nil {
-		return fmt.Errorf("attestation data does not contain quote info")
+		return nil, nil, fmt.Errorf("decoding attestation data failed: %v", err)
 	}
-	if subtle.ConstantTimeCompare(attestationData.ExtraData, extraData) == 0 {
-		return fmt.Errorf("quote extraData %v did not match expected extraData %v",
-			attestationData.ExtraData, extraData)
-	}
-	return validatePCRDigest(attestedQuoteInfo, q.GetPcrs(), hash)
+	return attestationData, sig, nil
 }
 
 // Get the cryptographic hash used for the signature and make sure we support it
@@ -105,7 +194,7 @@ func verifyECDSAQuoteSignature(ecdsaPub *ecdsa.PublicKey, hash crypto.Hash, quot
 	hashConstructor := hash.New()
 	hashConstructor.Write(quoted)
 	if !ecdsa.Verify(ecdsaPub, hashConstructor.Sum(nil), sig.ECC.R, sig.ECC.S) {
-		return fmt.Errorf("ECC signature verification failed")
+		return fmt.Errorf("%w: ECC signature verification failed", ErrQuoteSignature)
 	}
 	return nil
 }
@@ -118,18 +207,18 @@ func verifyRSASSAQuoteSignature(rsaPub *rsa.PublicKey, hash crypto.Hash, quoted
 	hashConstructor := hash.New()
 	hashConstructor.Write(quoted)
 	if err := rsa.VerifyPKCS1v15(rsaPub, hash, hashConstructor.Sum(nil), sig.RSA.Signature); err != nil {
-		return fmt.Errorf("RSASSA signature verification failed: %v", err)
+		return fmt.Errorf("%w: RSASSA signature verification failed: %v", ErrQuoteSignature, err)
 	}
 	return nil
 }
 
 func validatePCRDigest(quoteInfo *tpm2.QuoteInfo, pcrs *pb.PCRs, hash crypto.Hash) error {
 	if !SamePCRSelection(pcrs, quoteInfo.PCRSelection) {
-		return fmt.Errorf("given PCRs and Quote do not have the same PCR selection")
+		return fmt.Errorf("%w: given PCRs and Quote do not have the same PCR selection", ErrQuotePCRs)
 	}
 	pcrDigest := PCRDigest(pcrs, hash)
 	if subtle.ConstantTimeCompare(quoteInfo.PCRDigest, pcrDigest) == 0 {
-		return fmt.Errorf("given PCRs digest not matching")
+		return fmt.Errorf("%w: given PCRs digest not matching", ErrQuotePCRs)
 	}
 	return nil
 }
@@ -2,6 +2,7 @@ package test
 
 import (
 	"io"
+	"math"
 	"sync"
 	"testing"
 
@@ -93,6 +94,42 @@ func SkipForRealTPM(tb testing.TB) {
 	}
 }
 
+// InjectFault injects fault into the command cc of a TPM returned by GetTPM,
+// see simulator.Simulator.InjectFault. The test is skipped if we are not using
+// a test TPM.
+func InjectFault(tb testing.TB, rwc io.ReadWriter, cc tpmutil.Command, fault simulator.Fault) {
+	tb.Helper()
+	simulated, ok := rwc.(simulatedTpm)
+	if !ok {
+		tb.Skip("Running against a real TPM, Skipping Test")
+	}
+	simulated.ReadWriteCloser.(*simulator.Simulator).InjectFault(cc, fault)
+}
+
+// CheckedClose closes the TPM and asserts that there were no leaked handles.
+// It is equivalent to client.CheckedClose, for the tests of packages which
+// the client package imports.
+func CheckedClose(tb testing.TB, rwc io.ReadWriteCloser) {
+	tb.Helper()
+	for _, t := range []tpm2.HandleType{
+		tpm2.HandleTypeLoadedSession,
+		tpm2.HandleTypeSavedSession,
+		tpm2.HandleTypeTransient,
+	} {
+		handles, _, err := tpm2.GetCapability(rwc, tpm2.CapabilityHandles, math.MaxUint32, uint32(t)<<24)
+		if err != nil {
+			tb.Errorf("failed to fetch handles of type %v: %v", t, err)
+		}
+		if len(handles) != 0 {
+			tb.Errorf("tests leaked handles: %v", handles)
+		}
+	}
+
+	if err := rwc.Close(); err != nil {
+		tb.Errorf("when closing simulator: %v", err)
+	}
+}
+
 // GetSimulatorWithLog returns a simulated TPM with PCRs that match the events
 // of the passed in eventlog. This allows for testing attestation flows.
 func GetSimulatorWithLog(tb testing.TB, eventLog []byte) io.ReadWriteCloser {
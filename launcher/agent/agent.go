@@ -11,7 +11,7 @@ import (
 	"crypto"
 	"fmt"
 	"io"
-	"net/http"
+	"sync"
 
 	"github.com/google/go-tpm-tools/cel"
 	"github.com/google/go-tpm-tools/client"
@@ -32,12 +32,47 @@ type AttestationAgent interface {
 	Attest(context.Context) ([]byte, error)
 }
 
+// NonceAttester is implemented by AttestationAgents which can attest for a
+// claims token including nonces of the workload, see
+// verifier.VerifyAttestationRequest.TokenNonces.
+type NonceAttester interface {
+	// AttestWithNonces is like Attest, but the token includes the nonces.
+	// Concurrent callers do not share attestations.
+	AttestWithNonces(ctx context.Context, nonces []string) ([]byte, error)
+}
+
+// AudienceAttester is implemented by AttestationAgents which can attest for a
+// claims token with a custom audience, see
+// verifier.VerifyAttestationRequest.TokenAudiences.
+type AudienceAttester interface {
+	// AttestForAudience is like AttestWithNonces, but the token is issued
+	// for the audience instead of the audiences of the LaunchSpec.
+	AttestForAudience(ctx context.Context, audience string, nonces []string) ([]byte, error)
+}
+
 type agent struct {
+	// mu serializes the use of the TPM and the CEL, in the order they are
+	// requested.
+	mu               fifoMutex
 	tpm              io.ReadWriteCloser
 	akFetcher        tpmKeyFetcher
 	client           verifier.Client
 	principalFetcher principalIDTokenFetcher
 	cosCel           cel.CEL
+	collectors       []Collector
+	celHashAlgos     []crypto.Hash
+	// measureHeader is set if the header of the CEL is measured, see
+	// Opts.MeasureHeader.
+	measureHeader bool
+	// lastCELSignature is the digest of the last CELSignature.
+	lastCELSignature []byte
+	// checkpoints queues the checkpoints of the issued tokens, nil if they
+	// are not handled.
+	checkpoints chan checkpointRequest
+
+	// callMu guards call, the Attest in progress if any.
+	callMu sync.Mutex
+	call   *attestCall
 }
 
 // CreateAttestationAgent returns an agent capable of performing remote
@@ -45,66 +80,246 @@ type agent struct {
 // - tpm is a handle to the TPM on the instance
 // - akFetcher is a func to fetch an attestation key: see go-tpm-tools/client.
 // - principalFetcher is a func to fetch GCE principal tokens for a given audience.
-func CreateAttestationAgent(tpm io.ReadWriteCloser, akFetcher tpmKeyFetcher, verifierClient verifier.Client, principalFetcher principalIDTokenFetcher) AttestationAgent {
-	return &agent{
+// - middlewares optionally wrap the agent, see Chain.
+//
+// The evidence of the DefaultCollectors is collected.
+func CreateAttestationAgent(tpm io.ReadWriteCloser, akFetcher tpmKeyFetcher, verifierClient verifier.Client, principalFetcher principalIDTokenFetcher, middlewares ...Middleware) AttestationAgent {
+	collectors, err := LookupCollectors(DefaultCollectors)
+	if err != nil {
+		// The default collectors are built in.
+		panic(err)
+	}
+	return CreateAttestationAgentWithCollectors(tpm, akFetcher, verifierClient, principalFetcher, collectors, middlewares...)
+}
+
+// CreateAttestationAgentWithCollectors is like CreateAttestationAgent, but
+// collects the evidence of the collectors, see LookupCollectors.
+func CreateAttestationAgentWithCollectors(tpm io.ReadWriteCloser, akFetcher tpmKeyFetcher, verifierClient verifier.Client, principalFetcher principalIDTokenFetcher, collectors []Collector, middlewares ...Middleware) AttestationAgent {
+	return CreateAttestationAgentWithOpts(tpm, akFetcher, verifierClient, principalFetcher, Opts{Collectors: collectors}, middlewares...)
+}
+
+// Opts configures an agent created with CreateAttestationAgentWithOpts.
+type Opts struct {
+	// Collectors collect the evidence of the attestations, see
+	// LookupCollectors.
+	Collectors []Collector
+	// CELHashAlgos are the PCR banks the CEL events are extended into, in
+	// order. They are recorded in the header of the CEL. The SHA-256 and
+	// SHA-1 banks are used if empty.
+	CELHashAlgos []crypto.Hash
+	// MeasureHeader measures the header of the CEL, its hash algorithms and
+	// COS schema version, before the first event. Verifiers which do not
+	// know the header events reject the CEL, so it is only measured if
+	// MeasureHeader is set, or CELHashAlgos are not the default banks.
+	MeasureHeader bool
+	// Checkpoint is called with the encoded CEL and the Checkpoint of each
+	// token the agent obtains, in order. It is called in the background, so
+	// a slow disk does not delay the tokens.
+	Checkpoint func(encodedCEL []byte, checkpoint Checkpoint)
+}
+
+// CreateAttestationAgentWithOpts is like CreateAttestationAgent, configured
+// with opts.
+func CreateAttestationAgentWithOpts(tpm io.ReadWriteCloser, akFetcher tpmKeyFetcher, verifierClient verifier.Client, principalFetcher principalIDTokenFetcher, opts Opts, middlewares ...Middleware) AttestationAgent {
+	celHashAlgos := opts.CELHashAlgos
+	if len(celHashAlgos) == 0 {
+		celHashAlgos = defaultCELHashAlgo
+	}
+	measureHeader := opts.MeasureHeader || !equalHashes(celHashAlgos, defaultCELHashAlgo)
+	a := &agent{
 		tpm:              tpm,
 		client:           verifierClient,
 		akFetcher:        akFetcher,
 		principalFetcher: principalFetcher,
+		collectors:       opts.Collectors,
+		celHashAlgos:     celHashAlgos,
+		measureHeader:    measureHeader,
 	}
+	if opts.Checkpoint != nil {
+		a.checkpoints = make(chan checkpointRequest, checkpointQueueSize)
+		go handleCheckpoints(a.checkpoints, opts.Checkpoint)
+	}
+	return Chain(a, middlewares...)
 }
 
 // MeasureEvent takes in a cel.Content and appends it to the CEL eventlog
-// under the attestation agent.
+// under the attestation agent. If enabled, the first event is preceded by the
+// header of the CEL, recording its hash algorithms and COS schema version. The
+// ImageDigest event is also extended to cel.CosImagePCR.
 func (a *agent) MeasureEvent(event cel.Content) error {
-	return a.cosCel.AppendEvent(a.tpm, cel.CosEventPCR, defaultCELHashAlgo, event)
+	if err := a.mu.Lock(context.Background()); err != nil {
+		return err
+	}
+	defer a.mu.Unlock()
+	if a.measureHeader && len(a.cosCel.Records) == 0 {
+		hashAlgos, err := cel.FormatHashAlgorithms(a.celHashAlgos)
+		if err != nil {
+			return err
+		}
+		header := []cel.CosTlv{
+			{EventType: cel.HashAlgorithmsType, EventContent: []byte(hashAlgos)},
+			{EventType: cel.SchemaVersionType, EventContent: []byte(cel.FormatSchemaVersion(cel.CosSchemaVersion))},
+		}
+		for _, event := range header {
+			if err := a.cosCel.AppendEvent(a.tpm, cel.CosEventPCR, a.celHashAlgos, event); err != nil {
+				return err
+			}
+		}
+	}
+	pcr := cel.CosEventPCR
+	cosTlv, isCos := event.(cel.CosTlv)
+	if isCos {
+		pcr = cosTlv.EventType.PCR()
+	}
+	if err := a.cosCel.AppendEvent(a.tpm, pcr, a.celHashAlgos, event); err != nil {
+		return err
+	}
+	if isCos && cosTlv.EventType == cel.ImageDigestType {
+		return cel.ExtendImagePCR(a.tpm, a.celHashAlgos, string(cosTlv.EventContent))
+	}
+	return nil
 }
 
 // Attest fetches the nonce and connection ID from the Attestation Service,
 // creates an attestation message, and returns the resultant
 // principalIDTokens and Metadata Server-generated ID tokens for the instance.
+//
+// Attest is safe for concurrent use: concurrent callers share the result of a
+// single attestation. Attest returns when ctx is done, and the attestation is
+// canceled once no caller is waiting for it.
 func (a *agent) Attest(ctx context.Context) ([]byte, error) {
-	challenge, err := a.client.CreateChallenge(ctx)
-	if err != nil {
-		return nil, err
+	a.callMu.Lock()
+	c := a.call
+	if c == nil {
+		callCtx, cancel := context.WithCancel(detachedContext{ctx})
+		c = &attestCall{done: make(chan struct{}), cancel: cancel}
+		a.call = c
+		go func() {
+			c.token, c.err = a.attest(callCtx, nil, nil)
+			a.callMu.Lock()
+			if a.call == c {
+				a.call = nil
+			}
+			a.callMu.Unlock()
+			cancel()
+			close(c.done)
+		}()
 	}
+	c.waiters++
+	a.callMu.Unlock()
 
-	principalTokens, err := a.principalFetcher(challenge.Name)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get principal tokens: %w", err)
+	select {
+	case <-c.done:
+		return c.token, c.err
+	case <-ctx.Done():
+		a.callMu.Lock()
+		if c.waiters--; c.waiters == 0 {
+			// Later callers start a new attestation rather than joining the
+			// canceled one.
+			if a.call == c {
+				a.call = nil
+			}
+			c.cancel()
+		}
+		a.callMu.Unlock()
+		return nil, ctx.Err()
 	}
+}
 
-	attestation, err := a.getAttestation(challenge.Nonce)
-	if err != nil {
-		return nil, err
-	}
+// AttestWithNonces implements NonceAttester.
+func (a *agent) AttestWithNonces(ctx context.Context, nonces []string) ([]byte, error) {
+	return a.attest(ctx, nil, nonces)
+}
 
-	resp, err := a.client.VerifyAttestation(ctx, verifier.VerifyAttestationRequest{
-		Challenge:      challenge,
-		GcpCredentials: principalTokens,
-		Attestation:    attestation,
+// AttestForAudience implements AudienceAttester.
+func (a *agent) AttestForAudience(ctx context.Context, audience string, nonces []string) ([]byte, error) {
+	return a.attest(ctx, []string{audience}, nonces)
+}
+
+func (a *agent) attest(ctx context.Context, audiences []string, nonces []string) ([]byte, error) {
+	// Each challenge, e.g. of the verifier of another region when hedging,
+	// needs its own attestation.
+	resp, request, err := verifier.VerifyHedged(ctx, a.client, func(ctx context.Context, challenge *verifier.Challenge) (verifier.VerifyAttestationRequest, error) {
+		principalTokens, err := a.principalFetcher(challenge.Name)
+		if err != nil {
+			return verifier.VerifyAttestationRequest{}, fmt.Errorf("failed to get principal tokens: %w", err)
+		}
+		attestation, celDigest, err := a.getAttestation(ctx, challenge.Nonce)
+		if err != nil {
+			return verifier.VerifyAttestationRequest{}, err
+		}
+		return verifier.VerifyAttestationRequest{
+			Challenge:               challenge,
+			GcpCredentials:          principalTokens,
+			Attestation:             attestation,
+			CanonicalEventLogDigest: celDigest,
+			TokenAudiences:          audiences,
+			TokenNonces:             nonces,
+		}, nil
 	})
 	if err != nil {
 		return nil, err
 	}
+	if err := a.checkpoint(resp.ClaimsToken, request.Attestation); err != nil {
+		return nil, err
+	}
 	return resp.ClaimsToken, nil
 }
 
-func (a *agent) getAttestation(nonce []byte) (*pb.Attestation, error) {
+// getAttestation returns an attestation over nonce, and the SHA-256 digest of
+// the CEL it contains.
+func (a *agent) getAttestation(ctx context.Context, nonce []byte) (*pb.Attestation, []byte, error) {
+	if err := a.mu.Lock(ctx); err != nil {
+		return nil, nil, err
+	}
+	defer a.mu.Unlock()
+
 	ak, err := a.akFetcher(a.tpm)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get AK: %v", err)
+		return nil, nil, fmt.Errorf("failed to get AK: %v", err)
 	}
 	defer ak.Close()
+	// TPM commands cannot be interrupted, but the quote is skipped if the
+	// attestation was canceled while the AK was loaded.
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
 
 	var buf bytes.Buffer
 	if err := a.cosCel.EncodeCEL(&buf); err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	celDigest, err := a.cosCel.Digest(crypto.SHA256)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce, CanonicalEventLog: buf.Bytes(), CertChainFetcher: http.DefaultClient})
+	opts := client.AttestOpts{Nonce: nonce, CanonicalEventLog: buf.Bytes(), TEEDevice: noTEEDevice{}}
+	for _, c := range a.collectors {
+		if err := c.PrepareQuote(&opts); err != nil {
+			return nil, nil, fmt.Errorf("failed to prepare the quote for collector %q: %v", c.Name(), err)
+		}
+	}
+	attestation, err := ak.Attest(opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to attest: %v", err)
+		return nil, nil, fmt.Errorf("failed to attest: %v", err)
+	}
+	for _, c := range a.collectors {
+		if err := c.Collect(ctx, a.tpm, attestation); err != nil {
+			return nil, nil, fmt.Errorf("failed to collect the evidence of collector %q: %v", c.Name(), err)
+		}
+	}
+	return attestation, celDigest, nil
+}
+
+func equalHashes(a, b []crypto.Hash) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
 	}
-	return attestation, nil
+	return true
 }
@@ -1,16 +1,28 @@
 package agent
 
 import (
+	"bytes"
 	"context"
+	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/go-tpm-tools/cel"
 	"github.com/google/go-tpm-tools/client"
 	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm-tools/launcher/verifier"
 	"github.com/google/go-tpm-tools/launcher/verifier/fake"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm-tools/simulator"
+	"github.com/google/go-tpm/tpm2"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestAttest(t *testing.T) {
@@ -55,6 +67,392 @@ func TestAttest(t *testing.T) {
 	fmt.Printf("token.Claims: %v\n", token.Claims)
 }
 
+func TestAttestCheckpoint(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	fakeSigner, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type checkpointed struct {
+		encodedCEL []byte
+		checkpoint Checkpoint
+	}
+	checkpoints := make(chan checkpointed, 1)
+	opts := Opts{Checkpoint: func(encodedCEL []byte, checkpoint Checkpoint) {
+		checkpoints <- checkpointed{encodedCEL, checkpoint}
+	}}
+	attestAgent := CreateAttestationAgentWithOpts(tpm, client.AttestationKeyECC, fake.NewClient(fakeSigner), placeholderFetcher, opts)
+	if err := attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.ImageRefType, EventContent: []byte("docker.io/bazel/experimental/test:latest")}); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := attestAgent.Attest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := <-checkpoints
+	tokenDigest := sha256.Sum256(token)
+	celDigest := sha256.Sum256(got.encodedCEL)
+	if !bytes.Equal(got.checkpoint.TokenDigest, tokenDigest[:]) || !bytes.Equal(got.checkpoint.CELDigest, celDigest[:]) {
+		t.Errorf("got checkpoint %+v, want the digests of the token and the CEL", got.checkpoint)
+	}
+	if len(got.encodedCEL) == 0 {
+		t.Error("got an empty CEL, want the CEL with the measured event")
+	}
+
+	quote := &tpmpb.Quote{}
+	if err := proto.Unmarshal(got.checkpoint.Quote, quote); err != nil {
+		t.Fatal(err)
+	}
+	attested, err := tpm2.DecodeAttestationData(quote.GetQuote())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.checkpoint.PCRDigest, attested.AttestedQuoteInfo.PCRDigest) || len(got.checkpoint.PCRDigest) == 0 {
+		t.Errorf("got PCR digest %x, want the PCR digest of the quote %x", got.checkpoint.PCRDigest, attested.AttestedQuoteInfo.PCRDigest)
+	}
+}
+
 func placeholderFetcher(audience string) ([][]byte, error) {
 	return [][]byte{}, nil
 }
+
+// blockingClient counts the challenges created, and blocks VerifyAttestation
+// until release is closed.
+type blockingClient struct {
+	verifier.Client
+	challenges int32
+	release    chan struct{}
+}
+
+func (c *blockingClient) CreateChallenge(ctx context.Context) (*verifier.Challenge, error) {
+	atomic.AddInt32(&c.challenges, 1)
+	return c.Client.CreateChallenge(ctx)
+}
+
+func (c *blockingClient) VerifyAttestation(ctx context.Context, request verifier.VerifyAttestationRequest) (*verifier.VerifyAttestationResponse, error) {
+	select {
+	case <-c.release:
+		return c.Client.VerifyAttestation(ctx, request)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestAttestConcurrent(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	fakeSigner, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifierClient := &blockingClient{Client: fake.NewClient(fakeSigner), release: make(chan struct{})}
+	agent := CreateAttestationAgent(tpm, client.AttestationKeyECC, verifierClient, placeholderFetcher)
+
+	const callers = 5
+	tokens := make([][]byte, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			token, err := agent.Attest(context.Background())
+			if err != nil {
+				t.Errorf("Attest() failed: %v", err)
+			}
+			tokens[i] = token
+		}(i)
+	}
+	// Let the callers join the attestation in progress.
+	time.Sleep(100 * time.Millisecond)
+	close(verifierClient.release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&verifierClient.challenges); n != 1 {
+		t.Errorf("concurrent Attest() created %d challenges, want 1", n)
+	}
+	for i := 1; i < callers; i++ {
+		if string(tokens[i]) != string(tokens[0]) {
+			t.Errorf("concurrent Attest() returned different tokens")
+		}
+	}
+}
+
+func TestAttestCanceled(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	fakeSigner, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifierClient := &blockingClient{Client: fake.NewClient(fakeSigner), release: make(chan struct{})}
+	agent := CreateAttestationAgent(tpm, client.AttestationKeyECC, verifierClient, placeholderFetcher)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := agent.Attest(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Attest() got error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// The canceled attestation must not be shared with later callers.
+	close(verifierClient.release)
+	if _, err := agent.Attest(context.Background()); err != nil {
+		t.Errorf("Attest() after a canceled Attest() failed: %v", err)
+	}
+}
+
+func TestAttestTPMFaults(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	fakeSigner, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent := CreateAttestationAgent(tpm, client.AttestationKeyECC, fake.NewClient(fakeSigner), placeholderFetcher)
+
+	// A busy TPM is retried.
+	test.InjectFault(t, tpm, tpm2.CmdQuote, simulator.FaultTimes(3, simulator.FaultRetry()))
+	if _, err := agent.Attest(context.Background()); err != nil {
+		t.Errorf("Attest() with a busy TPM failed: %v", err)
+	}
+
+	// A failing TPM fails the attestation, without leaking the AK handle.
+	test.InjectFault(t, tpm, tpm2.CmdQuote, simulator.FaultResponseCode(0x101))
+	if _, err := agent.Attest(context.Background()); err == nil {
+		t.Error("Attest() with a failing TPM succeeded")
+	}
+
+	// A TPM slower than the context fails the attestation.
+	test.InjectFault(t, tpm, tpm2.CmdQuote, simulator.FaultLatency(100*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := agent.Attest(ctx); err == nil {
+		t.Error("Attest() with a slow TPM succeeded after its deadline")
+	}
+
+	test.InjectFault(t, tpm, tpm2.CmdQuote, nil)
+	if _, err := agent.Attest(context.Background()); err != nil {
+		t.Errorf("Attest() after the TPM recovered failed: %v", err)
+	}
+}
+
+func TestSignCEL(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+
+	attestAgent := CreateAttestationAgent(tpm, client.AttestationKeyECC, nil, placeholderFetcher)
+	signer, ok := attestAgent.(CELSigner)
+	if !ok {
+		t.Fatal("agent does not implement CELSigner")
+	}
+
+	var sigs []CELSignature
+	for _, event := range []cel.CosTlv{
+		{EventType: cel.ImageRefType, EventContent: []byte("docker.io/bazel/experimental/test:latest")},
+		{EventType: cel.LaunchSeparatorType},
+	} {
+		if err := attestAgent.MeasureEvent(event); err != nil {
+			t.Fatal(err)
+		}
+		_, sig, err := signer.SignCEL()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs = append(sigs, sig)
+	}
+	encodedCEL, sig, err := signer.SignCEL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs = append(sigs, sig)
+
+	if err := VerifyCELSignatures(encodedCEL, sigs, ak.PublicKey()); err != nil {
+		t.Errorf("VerifyCELSignatures failed: %v", err)
+	}
+	if err := VerifyCELSignatures(encodedCEL, sigs[1:], ak.PublicKey()); err == nil {
+		t.Error("VerifyCELSignatures succeeded with a broken chain")
+	}
+	if err := VerifyCELSignatures(encodedCEL, sigs[:2], ak.PublicKey()); err != nil {
+		t.Errorf("VerifyCELSignatures failed with the whole CEL signed by an earlier signature: %v", err)
+	}
+	if err := VerifyCELSignatures(encodedCEL, sigs[:1], ak.PublicKey()); err == nil {
+		t.Error("VerifyCELSignatures succeeded when the CEL was not fully signed")
+	}
+	tampered := append([]byte{}, encodedCEL...)
+	tampered[len(tampered)-1] ^= 0xff
+	if err := VerifyCELSignatures(tampered, sigs, ak.PublicKey()); err == nil {
+		t.Error("VerifyCELSignatures succeeded with a tampered CEL")
+	}
+}
+
+func TestMeasureImagePCR(t *testing.T) {
+	test.SkipForRealTPM(t)
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	const imageDigest = "sha256:781d8dfdd92118436bd914442c8339e653b83f6bf3c1a7a98efcfb7c4fed7483"
+	attestAgent := CreateAttestationAgent(tpm, client.AttestationKeyECC, nil, placeholderFetcher)
+	for _, event := range []cel.CosTlv{
+		{EventType: cel.ImageRefType, EventContent: []byte("docker.io/bazel/experimental/test:latest")},
+		{EventType: cel.ImageDigestType, EventContent: []byte(imageDigest)},
+		{EventType: cel.LaunchSeparatorType},
+	} {
+		if err := attestAgent.MeasureEvent(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := tpm2.ReadPCR(tpm, cel.CosImagePCR, tpm2.AlgSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := cel.ImagePCRValue(crypto.SHA256, imageDigest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("image PCR is %x, want %x", got, want)
+	}
+}
+
+func TestMeasureEventCELHashAlgos(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	hashAlgos := []crypto.Hash{crypto.SHA256, crypto.SHA384}
+	attestAgent := CreateAttestationAgentWithOpts(tpm, client.AttestationKeyECC, nil, placeholderFetcher, Opts{CELHashAlgos: hashAlgos})
+	for _, event := range []cel.CosTlv{
+		{EventType: cel.ImageRefType, EventContent: []byte("docker.io/bazel/experimental/test:latest")},
+		{EventType: cel.LaunchSeparatorType},
+	} {
+		if err := attestAgent.MeasureEvent(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	encodedCEL, _, err := attestAgent.(CELSigner).SignCEL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := cel.DecodeToCEL(bytes.NewBuffer(encodedCEL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Records) != 4 {
+		t.Fatalf("got %d records, want the 2 header events and 2 events", len(decoded.Records))
+	}
+	header, err := decoded.Records[0].Content.ParseToCosTlv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.EventType != cel.HashAlgorithmsType || string(header.EventContent) != "sha256,sha384" {
+		t.Errorf("got header %v, want the HashAlgorithms event of sha256,sha384", header)
+	}
+	schemaVersion, err := decoded.Records[1].Content.ParseToCosTlv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schemaVersion.EventType != cel.SchemaVersionType || string(schemaVersion.EventContent) != cel.FormatSchemaVersion(cel.CosSchemaVersion) {
+		t.Errorf("got header %v, want the SchemaVersion event of version %d", schemaVersion, cel.CosSchemaVersion)
+	}
+	for _, record := range decoded.Records {
+		if len(record.Digests) != len(hashAlgos) {
+			t.Errorf("record %d has %d digests, want %d", record.RecNum, len(record.Digests), len(hashAlgos))
+		}
+	}
+	for _, tpm2Alg := range []tpm2.Algorithm{tpm2.AlgSHA256, tpm2.AlgSHA384} {
+		pcrs, err := client.ReadPCRs(tpm, tpm2.PCRSelection{Hash: tpm2Alg, PCRs: []int{cel.CosEventPCR}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := decoded.Replay(pcrs); err != nil {
+			t.Errorf("failed to replay the CEL against the %v bank: %v", tpm2Alg, err)
+		}
+	}
+}
+
+func TestMeasureEventHeader(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		opts        Opts
+		wantRecords int
+	}{
+		{"Default", Opts{}, 1},
+		{"DefaultBanks", Opts{CELHashAlgos: defaultCELHashAlgo}, 1},
+		{"MeasureHeader", Opts{MeasureHeader: true}, 3},
+		{"OtherBanks", Opts{CELHashAlgos: []crypto.Hash{crypto.SHA256}}, 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tpm := test.GetTPM(t)
+			defer client.CheckedClose(t, tpm)
+
+			attestAgent := CreateAttestationAgentWithOpts(tpm, client.AttestationKeyECC, nil, placeholderFetcher, tc.opts)
+			if err := attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.ImageRefType, EventContent: []byte("docker.io/bazel/experimental/test:latest")}); err != nil {
+				t.Fatal(err)
+			}
+			encodedCEL, _, err := attestAgent.(CELSigner).SignCEL()
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := cel.DecodeToCEL(bytes.NewBuffer(encodedCEL))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(decoded.Records) != tc.wantRecords {
+				t.Errorf("got %d records, want %d", len(decoded.Records), tc.wantRecords)
+			}
+		})
+	}
+}
+
+func TestMeasureKernelModuleEvent(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	attestAgent := CreateAttestationAgent(tpm, client.AttestationKeyECC, nil, placeholderFetcher)
+	for _, event := range []cel.CosTlv{
+		{EventType: cel.ImageRefType, EventContent: []byte("docker.io/bazel/experimental/test:latest")},
+		{EventType: cel.LaunchSeparatorType},
+		{EventType: cel.KernelModuleType, EventContent: []byte("sha256:0123 /lib/modules/6.1.0/kernel/fs/fuse/fuse.ko")},
+	} {
+		if err := attestAgent.MeasureEvent(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	encodedCEL, _, err := attestAgent.(CELSigner).SignCEL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := cel.DecodeToCEL(bytes.NewBuffer(encodedCEL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range decoded.Records {
+		event, err := record.Content.ParseToCosTlv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int(record.PCR) != event.EventType.PCR() {
+			t.Errorf("event %v was measured into PCR %d, want %d", event.EventType, record.PCR, event.EventType.PCR())
+		}
+	}
+	pcrs, err := client.ReadPCRs(tpm, celPCRSelection())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.Replay(pcrs); err != nil {
+		t.Errorf("failed to replay the CEL: %v", err)
+	}
+}
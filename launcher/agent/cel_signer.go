@@ -0,0 +1,212 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/tpm2"
+	"google.golang.org/protobuf/proto"
+)
+
+// CELSigner is implemented by AttestationAgents which can sign their CEL with
+// the attestation key, so logs retrieved out-of-band can be checked for
+// tampering.
+type CELSigner interface {
+	// SignCEL returns the encoded CEL and a signature over it, chained to
+	// the previous signature returned by the signer.
+	SignCEL() ([]byte, CELSignature, error)
+	// QuoteCEL calls bind with the encoded CEL and the values of the CEL PCR,
+	// and returns a quote of the CEL PCR binding the data bind returns (see
+	// client.Key.QuoteData). The CEL does not change until QuoteCEL returns.
+	QuoteCEL(bind func(encodedCEL []byte, pcrs *tpmpb.PCRs) ([]byte, error)) (*tpmpb.Quote, error)
+	// AKCertificate returns the DER certificate of the attestation key, or
+	// nil if it has none.
+	AKCertificate() ([]byte, error)
+}
+
+// CELSignature is a quote of the CEL PCR by the attestation key, binding the
+// digest of the encoded CEL at the time of signing and the previous signature.
+type CELSignature struct {
+	// CELDigest is the SHA-256 digest of the encoded CEL.
+	CELDigest []byte `json:"cel_digest"`
+	// Previous is the Digest of the previous signature, empty for the first.
+	Previous []byte `json:"previous,omitempty"`
+	// Quote is a serialized tpm.Quote over the CEL PCR, with extraData of
+	// SHA-256(CELDigest || Previous).
+	Quote []byte `json:"quote"`
+}
+
+// Digest returns the digest of the signature, used to chain the next one.
+func (s CELSignature) Digest() []byte {
+	h := sha256.New()
+	h.Write(s.CELDigest)
+	h.Write(s.Previous)
+	h.Write(s.Quote)
+	return h.Sum(nil)
+}
+
+func (s CELSignature) extraData() []byte {
+	h := sha256.New()
+	h.Write(s.CELDigest)
+	h.Write(s.Previous)
+	return h.Sum(nil)
+}
+
+// celPCRSelection selects the SHA-256 PCRs the records of the CEL are
+// measured into.
+func celPCRSelection() tpm2.PCRSelection {
+	return tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{cel.CosEventPCR, cel.KernelModulePCR}}
+}
+
+// SignCEL quotes the CEL PCRs with the attestation key over the digest of the
+// current CEL.
+func (a *agent) SignCEL() ([]byte, CELSignature, error) {
+	if err := a.mu.Lock(context.Background()); err != nil {
+		return nil, CELSignature{}, err
+	}
+	defer a.mu.Unlock()
+
+	var buf bytes.Buffer
+	if err := a.cosCel.EncodeCEL(&buf); err != nil {
+		return nil, CELSignature{}, err
+	}
+	celDigest := sha256.Sum256(buf.Bytes())
+	sig := CELSignature{CELDigest: celDigest[:], Previous: a.lastCELSignature}
+
+	ak, err := a.akFetcher(a.tpm)
+	if err != nil {
+		return nil, CELSignature{}, fmt.Errorf("failed to get AK: %v", err)
+	}
+	defer ak.Close()
+
+	sel := celPCRSelection()
+	quote, err := ak.QuoteNonce(sel, sig.extraData())
+	if err != nil {
+		return nil, CELSignature{}, fmt.Errorf("failed to quote CEL PCR: %v", err)
+	}
+	if sig.Quote, err = proto.Marshal(quote); err != nil {
+		return nil, CELSignature{}, err
+	}
+
+	a.lastCELSignature = sig.Digest()
+	return buf.Bytes(), sig, nil
+}
+
+// QuoteCEL quotes the CEL PCRs with the attestation key over data derived from
+// the current CEL.
+func (a *agent) QuoteCEL(bind func(encodedCEL []byte, pcrs *tpmpb.PCRs) ([]byte, error)) (*tpmpb.Quote, error) {
+	if err := a.mu.Lock(context.Background()); err != nil {
+		return nil, err
+	}
+	defer a.mu.Unlock()
+
+	var buf bytes.Buffer
+	if err := a.cosCel.EncodeCEL(&buf); err != nil {
+		return nil, err
+	}
+	sel := celPCRSelection()
+	pcrs, err := client.ReadPCRs(a.tpm, sel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CEL PCR: %v", err)
+	}
+	data, err := bind(buf.Bytes(), pcrs)
+	if err != nil {
+		return nil, err
+	}
+
+	ak, err := a.akFetcher(a.tpm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AK: %v", err)
+	}
+	defer ak.Close()
+
+	quote, err := ak.QuoteData(sel, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to quote CEL PCR: %v", err)
+	}
+	return quote, nil
+}
+
+// AKCertificate returns the DER certificate of the attestation key, or nil if
+// it has none.
+func (a *agent) AKCertificate() ([]byte, error) {
+	if err := a.mu.Lock(context.Background()); err != nil {
+		return nil, err
+	}
+	defer a.mu.Unlock()
+
+	ak, err := a.akFetcher(a.tpm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AK: %v", err)
+	}
+	defer ak.Close()
+	return ak.CertDERBytes(), nil
+}
+
+// VerifyCELSignatures checks a chain of CEL signatures, in signing order,
+// against the encoded CEL and the trusted attestation key:
+//   - every quote is signed by the attestation key over its CEL digest and
+//     the previous signature
+//   - every signature covers a prefix of the CEL, growing over the chain
+//   - the last signature covers the whole CEL, which replays to its quote
+//
+// Note that the caller must have already established trust in the provided
+// public key.
+func VerifyCELSignatures(encodedCEL []byte, sigs []CELSignature, trustedPub crypto.PublicKey) error {
+	if len(sigs) == 0 {
+		return errors.New("no CEL signatures")
+	}
+
+	// The CEL is append only, so each signed CEL must be one of its prefixes.
+	prefixes := map[[sha256.Size]byte]int{sha256.Sum256(nil): 0}
+	buf := bytes.NewBuffer(encodedCEL)
+	for i := 1; buf.Len() > 0; i++ {
+		if _, err := cel.DecodeToCELR(buf); err != nil {
+			return fmt.Errorf("failed to decode CEL: %v", err)
+		}
+		prefixes[sha256.Sum256(encodedCEL[:len(encodedCEL)-buf.Len()])] = i
+	}
+
+	var previous []byte
+	lastRecords := -1
+	quote := &tpmpb.Quote{}
+	for i, sig := range sigs {
+		if !bytes.Equal(sig.Previous, previous) {
+			return fmt.Errorf("CEL signature %d is not chained to the previous signature", i)
+		}
+		var celDigest [sha256.Size]byte
+		copy(celDigest[:], sig.CELDigest)
+		records, ok := prefixes[celDigest]
+		if !ok || len(sig.CELDigest) != sha256.Size {
+			return fmt.Errorf("CEL signature %d does not match the CEL", i)
+		}
+		if records < lastRecords {
+			return fmt.Errorf("CEL signature %d covers fewer records than the previous signature", i)
+		}
+		if err := proto.Unmarshal(sig.Quote, quote); err != nil {
+			return fmt.Errorf("failed to decode the quote of CEL signature %d: %v", i, err)
+		}
+		if err := internal.VerifyQuote(quote, trustedPub, sig.extraData()); err != nil {
+			return fmt.Errorf("failed to verify the quote of CEL signature %d: %v", i, err)
+		}
+		previous = sig.Digest()
+		lastRecords = records
+	}
+
+	if lastRecords != len(prefixes)-1 {
+		return errors.New("the last CEL signature does not cover the whole CEL")
+	}
+	decodedCEL, err := cel.DecodeToCEL(bytes.NewBuffer(encodedCEL))
+	if err != nil {
+		return err
+	}
+	return decodedCEL.Replay(quote.GetPcrs())
+}
@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm/tpm2"
+	"google.golang.org/protobuf/proto"
+)
+
+// checkpointQueueSize bounds the checkpoints waiting to be handled, before
+// Attest blocks.
+const checkpointQueueSize = 16
+
+// Checkpoint records the state of the CEL attested for a token, so the CEL
+// retrieved after an incident can be aligned with every token issued.
+type Checkpoint struct {
+	// Time is when the token was issued to the agent.
+	Time time.Time `json:"time"`
+	// TokenDigest is the SHA-256 digest of the token.
+	TokenDigest []byte `json:"token_digest"`
+	// CELDigest is the SHA-256 digest of the encoded CEL attested, see
+	// CELSignature.CELDigest.
+	CELDigest []byte `json:"cel_digest"`
+	// PCRDigest is the digest of the PCR values quoted, in the hash algorithm
+	// of the quote.
+	PCRDigest []byte `json:"pcr_digest"`
+	// Quote is the serialized tpm.Quote signed by the attestation key for the
+	// token, over the nonce of the verifier.
+	Quote []byte `json:"quote"`
+}
+
+type checkpointRequest struct {
+	encodedCEL []byte
+	checkpoint Checkpoint
+}
+
+// newCheckpoint returns the Checkpoint of the token issued for the
+// attestation, with its first quote in a SHA-256 or stronger bank.
+func newCheckpoint(now time.Time, token []byte, attestation *pb.Attestation) (Checkpoint, error) {
+	for _, quote := range attestation.GetQuotes() {
+		if tpm2.Algorithm(quote.GetPcrs().GetHash()) == tpm2.AlgSHA1 {
+			continue
+		}
+		attested, err := tpm2.DecodeAttestationData(quote.GetQuote())
+		if err != nil || attested.AttestedQuoteInfo == nil {
+			return Checkpoint{}, fmt.Errorf("failed to decode the quote: %v", err)
+		}
+		encodedQuote, err := proto.Marshal(quote)
+		if err != nil {
+			return Checkpoint{}, err
+		}
+		tokenDigest := sha256.Sum256(token)
+		celDigest := sha256.Sum256(attestation.GetCanonicalEventLog())
+		return Checkpoint{
+			Time:        now,
+			TokenDigest: tokenDigest[:],
+			CELDigest:   celDigest[:],
+			PCRDigest:   attested.AttestedQuoteInfo.PCRDigest,
+			Quote:       encodedQuote,
+		}, nil
+	}
+	return Checkpoint{}, fmt.Errorf("no quote in a SHA-256 or stronger bank")
+}
+
+// checkpoint queues the checkpoint of the token issued for the attestation
+// to the Checkpoint func of the agent, if any.
+func (a *agent) checkpoint(token []byte, attestation *pb.Attestation) error {
+	if a.checkpoints == nil {
+		return nil
+	}
+	checkpoint, err := newCheckpoint(time.Now(), token, attestation)
+	if err != nil {
+		return fmt.Errorf("failed to checkpoint the CEL: %v", err)
+	}
+	a.checkpoints <- checkpointRequest{attestation.GetCanonicalEventLog(), checkpoint}
+	return nil
+}
+
+// handleCheckpoints calls handle with the queued checkpoints, in order. It
+// runs for the lifetime of the agent.
+func handleCheckpoints(requests <-chan checkpointRequest, handle func(encodedCEL []byte, checkpoint Checkpoint)) {
+	for request := range requests {
+		handle(request.encodedCEL, request.checkpoint)
+	}
+}
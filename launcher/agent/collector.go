@@ -0,0 +1,143 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/google/go-tpm-tools/client"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+)
+
+// Collector collects evidence for the attestations of the agent. The quote of
+// all the PCR banks, with the TPM event log and the CEL, is always collected,
+// as verifiers need it. Collectors add to it, e.g. the AK certificate chain or
+// a TEE attestation report.
+//
+// Collectors are called in order while the agent holds the TPM, so they must
+// not use the agent.
+type Collector interface {
+	// Name identifies the collector in the LaunchSpec, e.g. "tee".
+	Name() string
+	// PrepareQuote sets the options of the quote for the evidence, e.g. to
+	// include a log in the attestation.
+	PrepareQuote(opts *client.AttestOpts) error
+	// Collect adds the evidence to the quoted attestation.
+	Collect(ctx context.Context, tpm io.ReadWriter, attestation *pb.Attestation) error
+}
+
+// Names of the built-in collectors.
+const (
+	// AKCertChainCollector adds the intermediate certificates of the AK
+	// certificate, fetched from its issuing certificate URLs.
+	AKCertChainCollector = "ak_cert_chain"
+	// TEECollector adds the attestation report of the confidential computing
+	// technology of the VM, e.g. AMD SEV-SNP. Without it, no report is
+	// collected.
+	TEECollector = "tee"
+	// IMACollector adds the IMA runtime measurement list.
+	IMACollector = "ima"
+	// GCEIdentityCollector adds the instance identity token and the Shielded
+	// VM identity of the GCE instance. The default service account needs the
+	// compute.instances.getShieldedInstanceIdentity permission.
+	GCEIdentityCollector = "gce_identity"
+)
+
+// DefaultCollectors are the collectors enabled if the LaunchSpec does not set
+// any.
+var DefaultCollectors = []string{AKCertChainCollector, TEECollector}
+
+var (
+	collectorsMu sync.Mutex
+	collectors   = map[string]Collector{}
+)
+
+func init() {
+	RegisterCollector(quoteOptionCollector{AKCertChainCollector, func(opts *client.AttestOpts) {
+		opts.CertChainFetcher = http.DefaultClient
+	}})
+	RegisterCollector(quoteOptionCollector{TEECollector, func(opts *client.AttestOpts) {
+		opts.TEEDevice = nil
+	}})
+	RegisterCollector(quoteOptionCollector{IMACollector, func(opts *client.AttestOpts) {
+		opts.IncludeIMALog = true
+	}})
+	RegisterCollector(quoteOptionCollector{GCEIdentityCollector, func(opts *client.AttestOpts) {
+		opts.GCEIdentityFetcher = http.DefaultClient
+	}})
+}
+
+// RegisterCollector makes a collector available by its name, so embedders can
+// add custom evidence. It panics if a collector with the same name is already
+// registered, like database/sql.Register.
+func RegisterCollector(c Collector) {
+	collectorsMu.Lock()
+	defer collectorsMu.Unlock()
+	if _, ok := collectors[c.Name()]; ok {
+		panic(fmt.Sprintf("agent: collector %q registered twice", c.Name()))
+	}
+	collectors[c.Name()] = c
+}
+
+// RegisteredCollectors returns the sorted names of the registered collectors.
+func RegisteredCollectors() []string {
+	collectorsMu.Lock()
+	defer collectorsMu.Unlock()
+	names := make([]string, 0, len(collectors))
+	for name := range collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupCollectors returns the registered collectors with the names, in
+// order, or DefaultCollectors if names is empty.
+func LookupCollectors(names []string) ([]Collector, error) {
+	if len(names) == 0 {
+		names = DefaultCollectors
+	}
+	collectorsMu.Lock()
+	defer collectorsMu.Unlock()
+	var found []Collector
+	seen := make(map[string]bool)
+	for _, name := range names {
+		c, ok := collectors[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown evidence collector %q", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("evidence collector %q enabled twice", name)
+		}
+		seen[name] = true
+		found = append(found, c)
+	}
+	return found, nil
+}
+
+// quoteOptionCollector is a built-in collector, setting an option of the quote.
+type quoteOptionCollector struct {
+	name   string
+	option func(*client.AttestOpts)
+}
+
+func (c quoteOptionCollector) Name() string { return c.name }
+
+func (c quoteOptionCollector) PrepareQuote(opts *client.AttestOpts) error {
+	c.option(opts)
+	return nil
+}
+
+func (quoteOptionCollector) Collect(context.Context, io.ReadWriter, *pb.Attestation) error {
+	return nil
+}
+
+// noTEEDevice stops client.Attest from collecting a TEE attestation report
+// when the TEECollector is not enabled.
+type noTEEDevice struct{}
+
+func (noTEEDevice) AddAttestation(*pb.Attestation, client.AttestOpts) error { return nil }
+func (noTEEDevice) Close() error                                            { return nil }
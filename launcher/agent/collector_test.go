@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+)
+
+// fakeCollector adds its name as the AK certificate, which the AKs of the
+// simulator do not have.
+type fakeCollector struct {
+	name       string
+	collectErr error
+}
+
+func (c fakeCollector) Name() string { return c.name }
+
+func (c fakeCollector) PrepareQuote(*client.AttestOpts) error { return nil }
+
+func (c fakeCollector) Collect(_ context.Context, _ io.ReadWriter, attestation *pb.Attestation) error {
+	if c.collectErr != nil {
+		return c.collectErr
+	}
+	attestation.AkCert = []byte(c.name)
+	return nil
+}
+
+func init() {
+	RegisterCollector(fakeCollector{name: "fake"})
+	RegisterCollector(fakeCollector{name: "failing", collectErr: errors.New("no evidence")})
+}
+
+func collectorNames(collectors []Collector) []string {
+	var names []string
+	for _, c := range collectors {
+		names = append(names, c.Name())
+	}
+	return names
+}
+
+func TestLookupCollectors(t *testing.T) {
+	collectors, err := LookupCollectors(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := collectorNames(collectors); !reflect.DeepEqual(got, DefaultCollectors) {
+		t.Errorf("LookupCollectors(nil) = %v, want %v", got, DefaultCollectors)
+	}
+	collectors, err = LookupCollectors([]string{IMACollector, "fake"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := collectorNames(collectors); !reflect.DeepEqual(got, []string{IMACollector, "fake"}) {
+		t.Errorf("LookupCollectors() = %v, want [ima fake]", got)
+	}
+	if _, err := LookupCollectors([]string{"unknown"}); err == nil {
+		t.Error("LookupCollectors() succeeded with an unknown collector")
+	}
+	if _, err := LookupCollectors([]string{TEECollector, TEECollector}); err == nil {
+		t.Error("LookupCollectors() succeeded with a duplicate collector")
+	}
+	want := []string{AKCertChainCollector, "failing", "fake", GCEIdentityCollector, IMACollector, TEECollector}
+	if got := RegisteredCollectors(); !reflect.DeepEqual(got, want) {
+		t.Errorf("RegisteredCollectors() = %v, want %v", got, want)
+	}
+}
+
+func TestRegisterCollectorTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterCollector() did not panic for a registered name")
+		}
+	}()
+	RegisterCollector(fakeCollector{name: TEECollector})
+}
+
+func TestBuiltinCollectorsPrepareQuote(t *testing.T) {
+	opts := client.AttestOpts{TEEDevice: noTEEDevice{}}
+	collectors, err := LookupCollectors([]string{AKCertChainCollector, TEECollector, IMACollector, GCEIdentityCollector})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range collectors {
+		if err := c.PrepareQuote(&opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if opts.CertChainFetcher != http.DefaultClient {
+		t.Error("ak_cert_chain collector did not set the CertChainFetcher")
+	}
+	if opts.TEEDevice != nil {
+		t.Error("tee collector did not enable the TEE devices")
+	}
+	if !opts.IncludeIMALog {
+		t.Error("ima collector did not include the IMA log")
+	}
+	if opts.GCEIdentityFetcher != http.DefaultClient {
+		t.Error("gce_identity collector did not set the GCEIdentityFetcher")
+	}
+}
+
+func TestGetAttestationCollectors(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	collectors, err := LookupCollectors([]string{"fake"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := CreateAttestationAgentWithCollectors(tpm, client.AttestationKeyECC, nil, placeholderFetcher, collectors).(*agent)
+	attestation, _, err := a.getAttestation(context.Background(), []byte("nonce"))
+	if err != nil {
+		t.Fatalf("getAttestation() failed: %v", err)
+	}
+	if string(attestation.GetAkCert()) != "fake" {
+		t.Errorf("got AK cert %q, want the evidence of the fake collector", attestation.GetAkCert())
+	}
+	if len(attestation.GetQuotes()) == 0 {
+		t.Error("attestation has no quotes")
+	}
+
+	collectors, err = LookupCollectors([]string{"fake", "failing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a = CreateAttestationAgentWithCollectors(tpm, client.AttestationKeyECC, nil, placeholderFetcher, collectors).(*agent)
+	if _, _, err := a.getAttestation(context.Background(), []byte("nonce")); err == nil {
+		t.Error("getAttestation() succeeded with a failing collector")
+	}
+}
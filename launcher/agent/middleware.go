@@ -0,0 +1,135 @@
+package agent
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/go-tpm-tools/cel"
+)
+
+// Middleware wraps an AttestationAgent, for example to add audit logging,
+// tracing, or extra claims around its operations.
+type Middleware func(AttestationAgent) AttestationAgent
+
+// Chain wraps the agent with the middlewares. The first middleware is the
+// outermost one, so it sees every call first.
+func Chain(a AttestationAgent, middlewares ...Middleware) AttestationAgent {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		a = middlewares[i](a)
+	}
+	return a
+}
+
+// Hooks are called around the operations of an AttestationAgent. Nil hooks
+// are skipped.
+type Hooks struct {
+	// PreMeasure is called before an event is measured. Returning an error
+	// aborts the measurement.
+	PreMeasure func(event cel.Content) error
+	// PostMeasure is called after an event is measured, with the result.
+	PostMeasure func(event cel.Content, err error)
+	// PreAttest is called before attesting. It can return a derived context
+	// for the attestation, e.g. with a tracing span. Returning an error aborts
+	// the attestation.
+	PreAttest func(ctx context.Context) (context.Context, error)
+	// PostAttest is called after attesting, with the resulting token and
+	// error. Its results are returned from Attest instead.
+	PostAttest func(ctx context.Context, token []byte, err error) ([]byte, error)
+}
+
+// WithHooks returns a Middleware calling the hooks around each operation.
+// The wrapped agent still implements CELSigner if the inner agent does. It
+// implements NonceAttester and AudienceAttester, failing with
+// ErrNoncesUnsupported and ErrAudienceUnsupported unless the inner agent does,
+// with the attest hooks also called around AttestWithNonces and
+// AttestForAudience.
+func WithHooks(hooks Hooks) Middleware {
+	return func(inner AttestationAgent) AttestationAgent {
+		hooked := &hookedAgent{inner: inner, hooks: hooks}
+		if signer, ok := inner.(CELSigner); ok {
+			return &hookedCELSigner{hooked, signer}
+		}
+		return hooked
+	}
+}
+
+type hookedAgent struct {
+	inner AttestationAgent
+	hooks Hooks
+}
+
+func (h *hookedAgent) MeasureEvent(event cel.Content) error {
+	if h.hooks.PreMeasure != nil {
+		if err := h.hooks.PreMeasure(event); err != nil {
+			return err
+		}
+	}
+	err := h.inner.MeasureEvent(event)
+	if h.hooks.PostMeasure != nil {
+		h.hooks.PostMeasure(event, err)
+	}
+	return err
+}
+
+func (h *hookedAgent) Attest(ctx context.Context) ([]byte, error) {
+	if h.hooks.PreAttest != nil {
+		var err error
+		if ctx, err = h.hooks.PreAttest(ctx); err != nil {
+			return nil, err
+		}
+	}
+	token, err := h.inner.Attest(ctx)
+	if h.hooks.PostAttest != nil {
+		return h.hooks.PostAttest(ctx, token, err)
+	}
+	return token, err
+}
+
+// ErrNoncesUnsupported is returned by AttestWithNonces of agents wrapping an
+// agent which does not implement NonceAttester.
+var ErrNoncesUnsupported = errors.New("agent does not support custom token nonces")
+
+func (h *hookedAgent) AttestWithNonces(ctx context.Context, nonces []string) ([]byte, error) {
+	inner, ok := h.inner.(NonceAttester)
+	if !ok {
+		return nil, ErrNoncesUnsupported
+	}
+	if h.hooks.PreAttest != nil {
+		var err error
+		if ctx, err = h.hooks.PreAttest(ctx); err != nil {
+			return nil, err
+		}
+	}
+	token, err := inner.AttestWithNonces(ctx, nonces)
+	if h.hooks.PostAttest != nil {
+		return h.hooks.PostAttest(ctx, token, err)
+	}
+	return token, err
+}
+
+// ErrAudienceUnsupported is returned by AttestForAudience of agents wrapping
+// an agent which does not implement AudienceAttester.
+var ErrAudienceUnsupported = errors.New("agent does not support custom token audiences")
+
+func (h *hookedAgent) AttestForAudience(ctx context.Context, audience string, nonces []string) ([]byte, error) {
+	inner, ok := h.inner.(AudienceAttester)
+	if !ok {
+		return nil, ErrAudienceUnsupported
+	}
+	if h.hooks.PreAttest != nil {
+		var err error
+		if ctx, err = h.hooks.PreAttest(ctx); err != nil {
+			return nil, err
+		}
+	}
+	token, err := inner.AttestForAudience(ctx, audience, nonces)
+	if h.hooks.PostAttest != nil {
+		return h.hooks.PostAttest(ctx, token, err)
+	}
+	return token, err
+}
+
+type hookedCELSigner struct {
+	*hookedAgent
+	CELSigner
+}
@@ -0,0 +1,115 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/cel"
+)
+
+type fakeAgent struct {
+	calls *[]string
+}
+
+func (f fakeAgent) MeasureEvent(cel.Content) error {
+	*f.calls = append(*f.calls, "measure")
+	return nil
+}
+
+func (f fakeAgent) Attest(ctx context.Context) ([]byte, error) {
+	*f.calls = append(*f.calls, "attest:"+ctx.Value(ctxKey{}).(string))
+	return []byte("token"), nil
+}
+
+type ctxKey struct{}
+
+func recordingHooks(name string, calls *[]string) Hooks {
+	return Hooks{
+		PreMeasure: func(cel.Content) error {
+			*calls = append(*calls, name+":pre-measure")
+			return nil
+		},
+		PostMeasure: func(_ cel.Content, err error) {
+			*calls = append(*calls, name+":post-measure")
+		},
+		PreAttest: func(ctx context.Context) (context.Context, error) {
+			*calls = append(*calls, name+":pre-attest")
+			return context.WithValue(ctx, ctxKey{}, name), nil
+		},
+		PostAttest: func(_ context.Context, token []byte, err error) ([]byte, error) {
+			*calls = append(*calls, name+":post-attest")
+			return append(token, "+"+name...), err
+		},
+	}
+}
+
+func TestChainOrder(t *testing.T) {
+	var calls []string
+	a := Chain(fakeAgent{&calls}, WithHooks(recordingHooks("outer", &calls)), WithHooks(recordingHooks("inner", &calls)))
+
+	if err := a.MeasureEvent(cel.CosTlv{}); err != nil {
+		t.Fatal(err)
+	}
+	token, err := a.Attest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "token+inner+outer"; string(token) != want {
+		t.Errorf("got token %q, want %q", token, want)
+	}
+	want := []string{
+		"outer:pre-measure", "inner:pre-measure", "measure", "inner:post-measure", "outer:post-measure",
+		"outer:pre-attest", "inner:pre-attest", "attest:inner", "inner:post-attest", "outer:post-attest",
+	}
+	if !cmp.Equal(calls, want) {
+		t.Errorf("got calls %v, want %v", calls, want)
+	}
+}
+
+func TestHooksAbort(t *testing.T) {
+	var calls []string
+	hookErr := errors.New("denied")
+	a := Chain(fakeAgent{&calls}, WithHooks(Hooks{
+		PreMeasure: func(cel.Content) error { return hookErr },
+		PreAttest:  func(ctx context.Context) (context.Context, error) { return ctx, hookErr },
+	}))
+
+	if err := a.MeasureEvent(cel.CosTlv{}); !errors.Is(err, hookErr) {
+		t.Errorf("MeasureEvent got err %v, want %v", err, hookErr)
+	}
+	if _, err := a.Attest(context.Background()); !errors.Is(err, hookErr) {
+		t.Errorf("Attest got err %v, want %v", err, hookErr)
+	}
+	if len(calls) != 0 {
+		t.Errorf("inner agent was called: %v", calls)
+	}
+}
+
+func TestWithHooksKeepsCELSigner(t *testing.T) {
+	var calls []string
+	if _, ok := WithHooks(Hooks{})(fakeAgent{&calls}).(CELSigner); ok {
+		t.Error("hooked agent implements CELSigner, but the inner agent does not")
+	}
+	a := CreateAttestationAgent(nil, nil, nil, nil, WithHooks(Hooks{}))
+	if _, ok := a.(CELSigner); !ok {
+		t.Error("hooked agent does not implement CELSigner")
+	}
+}
+
+func TestHooksNoncesUnsupported(t *testing.T) {
+	var calls []string
+	a := Chain(fakeAgent{&calls}, WithHooks(recordingHooks("outer", &calls)))
+	attester, ok := a.(NonceAttester)
+	if !ok {
+		t.Fatal("hooked agent is not a NonceAttester")
+	}
+	if _, err := attester.AttestWithNonces(context.Background(), []string{"nonce-0001"}); !errors.Is(err, ErrNoncesUnsupported) {
+		t.Errorf("got error %v, want ErrNoncesUnsupported", err)
+	}
+	if _, err := a.(AudienceAttester).AttestForAudience(context.Background(), "https://rp.example.com", nil); !errors.Is(err, ErrAudienceUnsupported) {
+		t.Errorf("got error %v, want ErrAudienceUnsupported", err)
+	}
+}
@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// fifoMutex is a mutual exclusion lock granted in the order it is requested,
+// so a steady stream of short TPM commands cannot starve a quote. Unlike
+// sync.Mutex, waiting for the lock can be canceled.
+type fifoMutex struct {
+	mu      sync.Mutex
+	locked  bool
+	waiters list.List // of chan struct{}, closed when the lock is handed over
+}
+
+// Lock waits for the lock, or returns the error of ctx if it is done first.
+func (m *fifoMutex) Lock(ctx context.Context) error {
+	m.mu.Lock()
+	if !m.locked && m.waiters.Len() == 0 {
+		m.locked = true
+		m.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	elem := m.waiters.PushBack(ready)
+	m.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		m.mu.Lock()
+		select {
+		case <-ready:
+			// The lock was handed over concurrently, pass it on.
+			m.mu.Unlock()
+			m.Unlock()
+		default:
+			m.waiters.Remove(elem)
+			m.mu.Unlock()
+		}
+		return ctx.Err()
+	}
+}
+
+// Unlock hands the lock over to the longest waiting caller of Lock, if any.
+func (m *fifoMutex) Unlock() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if front := m.waiters.Front(); front != nil {
+		m.waiters.Remove(front)
+		close(front.Value.(chan struct{}))
+		return
+	}
+	m.locked = false
+}
+
+// attestCall is an Attest in progress, whose result is shared by all the
+// concurrent callers of Attest.
+type attestCall struct {
+	done  chan struct{}
+	token []byte
+	err   error
+	// waiters is the number of callers waiting for the result. The call is
+	// canceled when all of them are gone.
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// detachedContext has the values of its parent, but is never canceled, so a
+// shared attestCall outlives the context of the caller which started it.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}               { return nil }
+func (detachedContext) Err() error                          { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
@@ -0,0 +1,67 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFIFOMutexOrder(t *testing.T) {
+	var m fifoMutex
+	if err := m.Lock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	order := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		i := i
+		go func() {
+			if err := m.Lock(context.Background()); err != nil {
+				t.Error(err)
+				return
+			}
+			order <- i
+			m.Unlock()
+		}()
+		// Wait for the goroutine to queue up before starting the next one.
+		for waiters(&m) != i+1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	m.Unlock()
+
+	for want := 0; want < 3; want++ {
+		if got := <-order; got != want {
+			t.Errorf("waiter %d got the lock, want waiter %d", got, want)
+		}
+	}
+}
+
+func TestFIFOMutexCancel(t *testing.T) {
+	var m fifoMutex
+	if err := m.Lock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := m.Lock(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Lock() of a locked mutex got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if n := waiters(&m); n != 0 {
+		t.Errorf("got %d waiters after the Lock was canceled, want 0", n)
+	}
+	m.Unlock()
+
+	// The mutex must be usable after a canceled Lock.
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := m.Lock(ctx); err != nil {
+		t.Errorf("Lock() of an unlocked mutex failed: %v", err)
+	}
+}
+
+func waiters(m *fifoMutex) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.waiters.Len()
+}
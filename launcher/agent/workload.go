@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/go-tpm-tools/cel"
+)
+
+// WorkloadClaims are the claims of a workload which is not a container run
+// by the launcher, e.g. a systemd service, for the embedders of the agent.
+type WorkloadClaims struct {
+	// Name identifies the workload, e.g. the name of its systemd unit.
+	Name string
+	// Binaries are the paths of the executables of the workload. Their
+	// SHA-256 digests are measured with their paths, in order.
+	Binaries []string
+}
+
+// MeasureWorkloadClaims measures the claims into the CEL of the agent,
+// followed by the LaunchSeparator event, so no claims can be measured once
+// the workload runs. It is called once, before the workload starts and the
+// first Attest.
+func MeasureWorkloadClaims(a AttestationAgent, claims WorkloadClaims) error {
+	if claims.Name == "" || strings.Contains(claims.Name, "\n") {
+		return fmt.Errorf("malformed workload name [%s]", claims.Name)
+	}
+	events := []cel.CosTlv{{EventType: cel.WorkloadNameType, EventContent: []byte(claims.Name)}}
+	for _, path := range claims.Binaries {
+		digest, err := fileDigest(path)
+		if err != nil {
+			return fmt.Errorf("failed to hash the workload binary: %v", err)
+		}
+		content, err := cel.FormatWorkloadBinary(digest, path)
+		if err != nil {
+			return err
+		}
+		events = append(events, cel.CosTlv{EventType: cel.WorkloadBinaryType, EventContent: []byte(content)})
+	}
+	events = append(events, cel.CosTlv{EventType: cel.LaunchSeparatorType})
+	for _, event := range events {
+		if err := a.MeasureEvent(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileDigest returns the digest of a file, as "sha256:" followed by its hex
+// SHA-256.
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err != nil {
+		return "", err
+	} else if !info.Mode().IsRegular() {
+		return "", errors.New(path + " is not a regular file")
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/cel"
+)
+
+type recordingAgent struct {
+	events []cel.Content
+}
+
+func (a *recordingAgent) MeasureEvent(event cel.Content) error {
+	a.events = append(a.events, event)
+	return nil
+}
+
+func (a *recordingAgent) Attest(context.Context) ([]byte, error) {
+	return nil, nil
+}
+
+func TestMeasureWorkloadClaims(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "my-service")
+	if err := os.WriteFile(binary, []byte("foo"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	a := &recordingAgent{}
+	if err := MeasureWorkloadClaims(a, WorkloadClaims{Name: "my-service.service", Binaries: []string{binary}}); err != nil {
+		t.Fatalf("MeasureWorkloadClaims() failed: %v", err)
+	}
+	want := []cel.Content{
+		cel.CosTlv{EventType: cel.WorkloadNameType, EventContent: []byte("my-service.service")},
+		// The SHA-256 of "foo".
+		cel.CosTlv{EventType: cel.WorkloadBinaryType, EventContent: []byte("sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae " + binary)},
+		cel.CosTlv{EventType: cel.LaunchSeparatorType},
+	}
+	if diff := cmp.Diff(want, a.events); diff != "" {
+		t.Errorf("unexpected events (-want +got):\n%s", diff)
+	}
+
+	for _, claims := range []WorkloadClaims{
+		{},
+		{Name: "my-service.service", Binaries: []string{filepath.Dir(binary)}},
+		{Name: "my-service.service", Binaries: []string{binary + ".missing"}},
+	} {
+		if err := MeasureWorkloadClaims(&recordingAgent{}, claims); err == nil {
+			t.Errorf("MeasureWorkloadClaims(%+v) succeeded", claims)
+		}
+	}
+}
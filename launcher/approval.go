@@ -0,0 +1,98 @@
+package launcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/google/go-tpm-tools/launcher/agent"
+)
+
+// attestBeforeRun gates the start of the workload in the AttestBeforeRun
+// mode. It fetches the first attestation token, retrying with a policy from
+// newRetry until it succeeds or ctx is done, then requests the approval of
+// the start from the ApprovalURL of the LaunchSpec, if any, with client.
+func (r *ContainerRunner) attestBeforeRun(ctx context.Context, client *http.Client, newRetry func() backoff.BackOff) error {
+	notify := func(err error, t time.Duration) {
+		r.logger.Printf("workload not started, retrying in %v: %v", t, err)
+	}
+	err := backoff.RetryNotify(func() error {
+		return r.fetchAndWriteToken(ctx)
+	}, backoff.WithContext(newRetry(), ctx), notify)
+	if err != nil {
+		return &AttestationError{fmt.Errorf("failed to fetch and write OIDC token: %v", err)}
+	}
+	r.logger.Println("attestation token fetched")
+
+	if r.launchSpec.ApprovalURL == "" {
+		return nil
+	}
+	attester, ok := r.attestAgent.(agent.AudienceAttester)
+	if !ok {
+		return &AttestationError{agent.ErrAudienceUnsupported}
+	}
+	err = backoff.RetryNotify(func() error {
+		return requestApproval(ctx, client, r.launchSpec.ApprovalURL, attester)
+	}, backoff.WithContext(newRetry(), ctx), notify)
+	if err != nil {
+		return err
+	}
+	r.logger.Printf("workload start approved by %s", r.launchSpec.ApprovalURL)
+	return nil
+}
+
+// requestApproval requests the approval of the start of the workload from the
+// relying party at approvalURL, with a token of attester bound to it. It GETs
+// a nonce from approvalURL, then POSTs a token for the approvalURL audience
+// including the nonce as a bearer token, so the relying party cannot replay
+// the token elsewhere. It approves the start of the workload with a 200 OK
+// response. A 4xx response denies it, and is returned as a permanent
+// *PolicyError; other failures can be retried.
+func requestApproval(ctx context.Context, client *http.Client, approvalURL string, attester agent.AudienceAttester) error {
+	nonce, err := doApprovalRequest(ctx, client, http.MethodGet, approvalURL, nil)
+	if err != nil {
+		return err
+	}
+	nonce = bytes.TrimSpace(nonce)
+	if len(nonce) < minTokenNonceSize || len(nonce) > maxTokenNonceSize {
+		return fmt.Errorf("approval nonce is not between %d and %d bytes", minTokenNonceSize, maxTokenNonceSize)
+	}
+	token, err := attester.AttestForAudience(ctx, approvalURL, []string{string(nonce)})
+	if err != nil {
+		return fmt.Errorf("failed to fetch the approval token: %w", err)
+	}
+	_, err = doApprovalRequest(ctx, client, http.MethodPost, approvalURL, token)
+	return err
+}
+
+// doApprovalRequest sends a request to approvalURL, with token as a bearer
+// token if any, and returns the response body.
+func doApprovalRequest(ctx context.Context, client *http.Client, method, approvalURL string, token []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, approvalURL, nil)
+	if err != nil {
+		return nil, backoff.Permanent(err)
+	}
+	if token != nil {
+		req.Header.Set("Authorization", "Bearer "+string(token))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request approval: %w", err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxTokenNonceSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to request approval: %w", err)
+		}
+		return body, nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return nil, backoff.Permanent(&PolicyError{fmt.Errorf("workload start denied by %s: %s", approvalURL, resp.Status)})
+	}
+	return nil, fmt.Errorf("failed to request approval: %s", resp.Status)
+}
@@ -0,0 +1,142 @@
+package launcher
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/google/go-tpm-tools/launcher/spec"
+)
+
+const approvalNonce = "approval-nonce-0001"
+
+// newApprovalServer returns a relying party serving approvalNonce and
+// responding status to the approval request.
+func newApprovalServer(t *testing.T, status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(approvalNonce + "\n"))
+		case http.MethodPost:
+			if got := r.Header.Get("Authorization"); got != "Bearer approval token" {
+				t.Errorf("got authorization %q, want the approval token", got)
+			}
+			w.WriteHeader(status)
+		default:
+			t.Errorf("got %s request", r.Method)
+		}
+	}))
+}
+
+// approvalAttester returns an attester issuing the approval token for server.
+func approvalAttester(t *testing.T, server *httptest.Server) *fakeAttestationAgent {
+	return &fakeAttestationAgent{
+		attestForAudienceFunc: func(_ context.Context, audience string, nonces []string) ([]byte, error) {
+			if audience != server.URL || len(nonces) != 1 || nonces[0] != approvalNonce {
+				t.Errorf("got token request for audience %q and nonces %q, want %q and [%q]", audience, nonces, server.URL, approvalNonce)
+			}
+			return []byte("approval token"), nil
+		},
+	}
+}
+
+func TestRequestApproval(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		status        int
+		wantErr       bool
+		wantPermanent bool
+	}{
+		{"Approved", http.StatusOK, false, false},
+		{"Denied", http.StatusForbidden, true, true},
+		{"Unavailable", http.StatusServiceUnavailable, true, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newApprovalServer(t, tc.status)
+			defer server.Close()
+
+			err := requestApproval(context.Background(), server.Client(), server.URL, approvalAttester(t, server))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("requestApproval() = %v, want error %v", err, tc.wantErr)
+			}
+			var permanent *backoff.PermanentError
+			if errors.As(err, &permanent) != tc.wantPermanent {
+				t.Errorf("requestApproval() = %v, want permanent %v", err, tc.wantPermanent)
+			}
+		})
+	}
+}
+
+func TestRequestApprovalBadNonce(t *testing.T) {
+	for _, nonce := range []string{"", "short", string(make([]byte, maxTokenNonceSize+1))} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				t.Errorf("got %s request, want no approval request with a bad nonce", r.Method)
+			}
+			w.Write([]byte(nonce))
+		}))
+		attester := &fakeAttestationAgent{}
+		if err := requestApproval(context.Background(), server.Client(), server.URL, attester); err == nil {
+			t.Errorf("requestApproval() with nonce %q succeeded, want error", nonce)
+		}
+		server.Close()
+	}
+}
+
+func TestAttestBeforeRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	approvals := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Write([]byte(approvalNonce))
+			return
+		}
+		if approvals++; approvals < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	attempts := 0
+	fakeAgent := approvalAttester(t, server)
+	fakeAgent.attestFunc = func(context.Context) ([]byte, error) {
+		if attempts++; attempts < 3 {
+			return nil, errors.New("verifier unavailable")
+		}
+		return createJWT(t, time.Hour), nil
+	}
+	runner := ContainerRunner{attestAgent: fakeAgent, logger: log.Default()}
+	newRetry := func() backoff.BackOff { return backoff.NewConstantBackOff(time.Millisecond) }
+
+	if err := runner.attestBeforeRun(ctx, server.Client(), newRetry); err != nil {
+		t.Fatalf("attestBeforeRun() failed: %v", err)
+	}
+	if attempts != 3 || approvals != 0 {
+		t.Errorf("got %d attestations and %d approval requests, want 3 and none", attempts, approvals)
+	}
+
+	runner.launchSpec = spec.LaunchSpec{AttestBeforeRun: true, ApprovalURL: server.URL}
+	if err := runner.attestBeforeRun(ctx, server.Client(), newRetry); err != nil {
+		t.Fatalf("attestBeforeRun() failed: %v", err)
+	}
+	if approvals != 2 {
+		t.Errorf("got %d approval requests, want 2", approvals)
+	}
+
+	denied := newApprovalServer(t, http.StatusForbidden)
+	defer denied.Close()
+	runner.attestAgent = approvalAttester(t, denied)
+	runner.attestAgent.(*fakeAttestationAgent).attestFunc = fakeAgent.attestFunc
+	runner.launchSpec.ApprovalURL = denied.URL
+	var policyErr *PolicyError
+	if err := runner.attestBeforeRun(ctx, denied.Client(), newRetry); !errors.As(err, &policyErr) {
+		t.Errorf("attestBeforeRun() = %v, want a PolicyError", err)
+	}
+}
@@ -0,0 +1,152 @@
+package launcher
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"log"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/launcher/agent"
+)
+
+// The attestation artifacts in hostTokenPath, next to the token and the CEL.
+// The directory is a tmpfs on COS, and is mounted read-only in the container
+// at containerTokenMountPath.
+const (
+	// akCertFile stores the PEM certificate of the attestation key, if it has
+	// one.
+	akCertFile = "ak_cert.pem"
+	// metadataFile stores the claims measured in the CEL as JSON, see
+	// measuredClaims.
+	metadataFile = "metadata.json"
+)
+
+// writeArtifact atomically replaces the file name in dir with data, so the
+// workload never reads a partially written artifact. The directory, rather
+// than the files, is bind mounted, so the container sees the renamed file.
+func writeArtifact(dir, name string, data []byte) error {
+	tmp, err := os.CreateTemp(dir, "."+name+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path.Join(dir, name))
+}
+
+// appendArtifactLine atomically replaces the file name in dir with its
+// content followed by line and a newline, see writeArtifact.
+func appendArtifactLine(dir, name string, line []byte) error {
+	lines, err := os.ReadFile(path.Join(dir, name))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return writeArtifact(dir, name, append(append(lines, line...), '\n'))
+}
+
+// celMu serializes the writes of celFile and of the files appended to with
+// it, so the CEL written matches the last lines appended.
+var celMu sync.Mutex
+
+// flushCEL writes the encoded CEL to celFile in dir, unless the file holds a
+// longer, so newer, CEL: the CEL is append only. celMu must be held.
+func flushCEL(dir string, encodedCEL []byte) error {
+	if info, err := os.Stat(path.Join(dir, celFile)); err == nil && info.Size() > int64(len(encodedCEL)) {
+		return nil
+	}
+	return writeArtifact(dir, celFile, encodedCEL)
+}
+
+// writeCELCheckpoint returns an agent Checkpoint func flushing the CEL
+// attested for each token to dir, and appending its checkpoint to
+// celCheckpointsFile.
+func writeCELCheckpoint(dir string, logger *log.Logger) func(encodedCEL []byte, checkpoint agent.Checkpoint) {
+	return func(encodedCEL []byte, checkpoint agent.Checkpoint) {
+		line, err := json.Marshal(checkpoint)
+		if err == nil {
+			celMu.Lock()
+			if err = flushCEL(dir, encodedCEL); err == nil {
+				err = appendArtifactLine(dir, celCheckpointsFile, line)
+			}
+			celMu.Unlock()
+		}
+		if err != nil {
+			logger.Printf("failed to checkpoint the CEL: %v", err)
+		}
+	}
+}
+
+// writeAKCertificate writes the certificate of the attestation key of the
+// signer to dir, if it has one.
+func writeAKCertificate(signer agent.CELSigner, dir string) error {
+	der, err := signer.AKCertificate()
+	if err != nil || der == nil {
+		return err
+	}
+	return writeArtifact(dir, akCertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+// measuredClaim is a container claim measured in the CEL.
+type measuredClaim struct {
+	Type    string `json:"type"`
+	Content string `json:"content"`
+}
+
+// measuredClaims are the container claims measured in the CEL, in order, as
+// written to metadataFile. Only the last checkpoint of the workload output is
+// kept.
+type measuredClaims struct {
+	Claims []measuredClaim `json:"claims"`
+}
+
+func (c *measuredClaims) add(event cel.CosTlv) {
+	claim := measuredClaim{Type: cosTypeNames[event.EventType], Content: string(event.EventContent)}
+	if event.EventType == cel.WorkloadOutputType {
+		for i := range c.Claims {
+			if c.Claims[i].Type == claim.Type {
+				c.Claims[i] = claim
+				return
+			}
+		}
+	}
+	c.Claims = append(c.Claims, claim)
+}
+
+// writeMeasuredClaims returns a Middleware rewriting metadataFile in dir
+// after every successfully measured event.
+func writeMeasuredClaims(dir string, logger *log.Logger) agent.Middleware {
+	var mu sync.Mutex
+	claims := &measuredClaims{Claims: []measuredClaim{}}
+	return agent.WithHooks(agent.Hooks{
+		PostMeasure: func(event cel.Content, err error) {
+			cos, ok := event.(cel.CosTlv)
+			if !ok || err != nil {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			claims.add(cos)
+			data, err := json.MarshalIndent(claims, "", "  ")
+			if err == nil {
+				err = writeArtifact(dir, metadataFile, data)
+			}
+			if err != nil {
+				logger.Printf("failed to write the measured claims: %v", err)
+			}
+		},
+	})
+}
@@ -0,0 +1,82 @@
+package launcher
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/cel"
+)
+
+func TestWriteArtifact(t *testing.T) {
+	dir := t.TempDir()
+	for _, data := range []string{"first", "second"} {
+		if err := writeArtifact(dir, "artifact", []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := os.ReadFile(path.Join(dir, "artifact"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "second" {
+		t.Errorf("got artifact %q, want %q", got, "second")
+	}
+	info, err := os.Stat(path.Join(dir, "artifact"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("got artifact mode %v, want -rw-r--r--", info.Mode().Perm())
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d files, want only the artifact", len(entries))
+	}
+}
+
+func TestWriteMeasuredClaims(t *testing.T) {
+	dir := t.TempDir()
+	inner := &fakeAttestationAgent{measureEventFunc: func(event cel.Content) error {
+		if event.(cel.CosTlv).EventType == cel.ArgType {
+			return errors.New("measurement failed")
+		}
+		return nil
+	}}
+	attestAgent := writeMeasuredClaims(dir, log.Default())(inner)
+
+	for _, event := range []cel.CosTlv{
+		{EventType: cel.ImageRefType, EventContent: []byte("docker.io/library/hello-world:latest")},
+		{EventType: cel.ArgType, EventContent: []byte("--not-measured")},
+		{EventType: cel.LaunchSeparatorType},
+		{EventType: cel.WorkloadOutputType, EventContent: []byte("first")},
+		{EventType: cel.WorkloadOutputType, EventContent: []byte("second")},
+	} {
+		attestAgent.MeasureEvent(event)
+	}
+
+	data, err := os.ReadFile(path.Join(dir, metadataFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got measuredClaims
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := measuredClaims{Claims: []measuredClaim{
+		{Type: "ImageRef", Content: "docker.io/library/hello-world:latest"},
+		{Type: "LaunchSeparator", Content: ""},
+		{Type: "WorkloadOutput", Content: "second"},
+	}}
+	if !cmp.Equal(got, want) {
+		t.Errorf("got measured claims %v, want %v", got, want)
+	}
+}
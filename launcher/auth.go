@@ -2,6 +2,7 @@ package launcher
 
 import (
 	"encoding/json"
+	"net/http"
 	"strings"
 
 	"cloud.google.com/go/compute/metadata"
@@ -27,18 +28,19 @@ func RetrieveAuthToken(client *metadata.Client) (oauth2.Token, error) {
 }
 
 // Resolver returns a custom resolver that can use the token to authenticate with
-// the repo.
-func Resolver(token string) remotes.Resolver {
-	options := docker.ResolverOptions{}
+// the repo. If the token is empty, only public images can be pulled. The client
+// is used for all the requests to the registries, or http.DefaultClient if nil.
+func Resolver(token string, client *http.Client) remotes.Resolver {
+	options := docker.ResolverOptions{Client: client}
 
 	credentials := func(host string) (string, string, error) {
 		// append the token if is talking to Artifact Registry or GCR Registry
-		if strings.HasSuffix(host, "docker.pkg.dev") || strings.HasSuffix(host, "gcr.io") {
+		if token != "" && (strings.HasSuffix(host, "docker.pkg.dev") || strings.HasSuffix(host, "gcr.io")) {
 			return "_token", token, nil
 		}
 		return "", "", nil
 	}
-	authOpts := []docker.AuthorizerOpt{docker.WithAuthCreds(credentials)}
+	authOpts := []docker.AuthorizerOpt{docker.WithAuthCreds(credentials), docker.WithAuthClient(client)}
 	options.Authorizer = docker.NewDockerAuthorizer(authOpts...)
 
 	return docker.NewResolver(options)
@@ -0,0 +1,246 @@
+package launcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/containerd/containerd/containers"
+	"github.com/containerd/containerd/oci"
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/launcher/spec"
+	"gopkg.in/yaml.v3"
+)
+
+// cdiSpecDirs are the directories of the CDI (Container Device Interface)
+// specs of the host, a variable for testing.
+var cdiSpecDirs = []string{"/etc/cdi", "/var/run/cdi"}
+
+// cdiSpec is a CDI spec file, see
+// https://github.com/cncf-tags/container-device-interface/blob/main/SPEC.md.
+// JSON specs are parsed as YAML.
+type cdiSpec struct {
+	Version        string            `yaml:"cdiVersion"`
+	Kind           string            `yaml:"kind"`
+	Devices        []cdiSpecDevice   `yaml:"devices"`
+	ContainerEdits cdiContainerEdits `yaml:"containerEdits"`
+}
+
+type cdiSpecDevice struct {
+	Name           string            `yaml:"name"`
+	ContainerEdits cdiContainerEdits `yaml:"containerEdits"`
+}
+
+// cdiContainerEdits are the edits of the OCI spec of the container for a CDI
+// device. Only the environment and the device nodes are supported: the
+// other edits are rejected, as the launcher does not measure them.
+type cdiContainerEdits struct {
+	Env            []string        `yaml:"env"`
+	DeviceNodes    []cdiDeviceNode `yaml:"deviceNodes"`
+	Mounts         []interface{}   `yaml:"mounts"`
+	Hooks          []interface{}   `yaml:"hooks"`
+	AdditionalGIDs []interface{}   `yaml:"additionalGids"`
+	IntelRdt       interface{}     `yaml:"intelRdt"`
+}
+
+// cdiDeviceNode is a device node of a CDI device. Its type and numbers are
+// read from the device of the host.
+type cdiDeviceNode struct {
+	// Path is the path of the device in the container.
+	Path string `yaml:"path"`
+	// HostPath is the path of the device on the host, Path by default.
+	HostPath string `yaml:"hostPath"`
+	// Permissions are the cgroup permissions of the device, "rwm" by
+	// default.
+	Permissions string `yaml:"permissions"`
+}
+
+// cdiDevice is a CDI device resolved from the CDI specs of the host, with
+// the edits of its spec applying to all its devices.
+type cdiDevice struct {
+	name        string
+	env         []string
+	deviceNodes []cdiDeviceNode
+}
+
+// check checks that the edits are supported, and sets the defaults of the
+// device nodes.
+func (e *cdiContainerEdits) check() error {
+	if len(e.Mounts) > 0 || len(e.Hooks) > 0 || len(e.AdditionalGIDs) > 0 || e.IntelRdt != nil {
+		return fmt.Errorf("only the env and deviceNodes container edits are supported")
+	}
+	for _, env := range e.Env {
+		if !strings.Contains(env, "=") {
+			return fmt.Errorf("env %q is not NAME=VALUE", env)
+		}
+	}
+	for i := range e.DeviceNodes {
+		node := &e.DeviceNodes[i]
+		if node.HostPath == "" {
+			node.HostPath = node.Path
+		}
+		if node.Permissions == "" {
+			node.Permissions = "rwm"
+		}
+		if !path.IsAbs(node.Path) || path.Clean(node.Path) != node.Path {
+			return fmt.Errorf("device node path %q is not a clean absolute path", node.Path)
+		}
+		if path.Clean(node.HostPath) != node.HostPath || !strings.HasPrefix(node.HostPath, "/dev/") {
+			return fmt.Errorf("device node host path %q must be a path under /dev/", node.HostPath)
+		}
+		if strings.Trim(node.Permissions, "rwm") != "" {
+			return fmt.Errorf("device node %s has invalid permissions %q", node.Path, node.Permissions)
+		}
+	}
+	return nil
+}
+
+// loadCDISpecs loads the devices of the CDI specs (*.json, *.yaml and *.yml
+// files) of dirs, by their fully qualified names. Missing directories are
+// skipped. A device defined by more than one spec is an error, as it is
+// ambiguous which one is measured.
+func loadCDISpecs(dirs []string) (map[string]cdiDevice, error) {
+	devices := make(map[string]cdiDevice)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			switch filepath.Ext(entry.Name()) {
+			case ".json", ".yaml", ".yml":
+			default:
+				continue
+			}
+			if entry.IsDir() {
+				continue
+			}
+			file := filepath.Join(dir, entry.Name())
+			if err := loadCDISpec(file, devices); err != nil {
+				return nil, fmt.Errorf("invalid CDI spec %s: %v", file, err)
+			}
+		}
+	}
+	return devices, nil
+}
+
+// loadCDISpec adds the devices of the CDI spec file to devices.
+func loadCDISpec(file string, devices map[string]cdiDevice) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var s cdiSpec
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s.Version == "" {
+		return fmt.Errorf("cdiVersion is missing")
+	}
+	if err := s.ContainerEdits.check(); err != nil {
+		return err
+	}
+	for _, d := range s.Devices {
+		name := s.Kind + "=" + d.Name
+		if _, _, err := spec.ParseCDIDeviceName(name); err != nil {
+			return err
+		}
+		if err := d.ContainerEdits.check(); err != nil {
+			return fmt.Errorf("device %s: %v", name, err)
+		}
+		if _, ok := devices[name]; ok {
+			return fmt.Errorf("device %s is defined more than once", name)
+		}
+		devices[name] = cdiDevice{
+			name:        name,
+			env:         append(append([]string{}, s.ContainerEdits.Env...), d.ContainerEdits.Env...),
+			deviceNodes: append(append([]cdiDeviceNode{}, s.ContainerEdits.DeviceNodes...), d.ContainerEdits.DeviceNodes...),
+		}
+	}
+	return nil
+}
+
+// resolveCDIDevices resolves the CDI devices of names, in order, from the CDI
+// specs of dirs.
+func resolveCDIDevices(dirs []string, names []string) ([]cdiDevice, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	devices, err := loadCDISpecs(dirs)
+	if err != nil {
+		return nil, err
+	}
+	resolved := make([]cdiDevice, 0, len(names))
+	for _, name := range names {
+		device, ok := devices[name]
+		if !ok {
+			known := make([]string, 0, len(devices))
+			for name := range devices {
+				known = append(known, name)
+			}
+			sort.Strings(known)
+			return nil, fmt.Errorf("CDI device %s not found in %v; known devices: %v", name, dirs, known)
+		}
+		resolved = append(resolved, device)
+	}
+	return resolved, nil
+}
+
+// cdiSpecOpts returns the options injecting the CDI devices into the OCI spec
+// of the container. The device nodes shared by the devices of a spec are only
+// injected once.
+func cdiSpecOpts(devices []cdiDevice) []oci.SpecOpts {
+	var opts []oci.SpecOpts
+	injected := make(map[string]bool)
+	for _, device := range devices {
+		if len(device.env) > 0 {
+			opts = append(opts, oci.WithEnv(device.env))
+		}
+		for _, node := range device.deviceNodes {
+			if injected[node.Path] {
+				continue
+			}
+			injected[node.Path] = true
+			opts = append(opts, withCDIDeviceNode(node))
+		}
+	}
+	return opts
+}
+
+// withCDIDeviceNode adds the device node of the host to the container, at its
+// path in the container, like oci.WithLinuxDevice.
+func withCDIDeviceNode(node cdiDeviceNode) oci.SpecOpts {
+	return func(ctx context.Context, client oci.Client, c *containers.Container, s *oci.Spec) error {
+		if err := oci.WithLinuxDevice(node.HostPath, node.Permissions)(ctx, client, c, s); err != nil {
+			return fmt.Errorf("CDI device node %s: %v", node.HostPath, err)
+		}
+		// WithLinuxDevice adds the device at its host path.
+		s.Linux.Devices[len(s.Linux.Devices)-1].Path = node.Path
+		return nil
+	}
+}
+
+// cdiDeviceClaims returns the CDIDevice events of the CDI devices, measured
+// before the LaunchSeparator.
+func cdiDeviceClaims(devices []cdiDevice) ([]cel.CosTlv, error) {
+	var events []cel.CosTlv
+	for _, device := range devices {
+		hostPaths := make([]string, 0, len(device.deviceNodes))
+		for _, node := range device.deviceNodes {
+			hostPaths = append(hostPaths, node.HostPath)
+		}
+		content, err := cel.FormatCDIDevice(device.name, hostPaths)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, cel.CosTlv{EventType: cel.CDIDeviceType, EventContent: []byte(content)})
+	}
+	return events, nil
+}
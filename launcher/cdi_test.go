@@ -0,0 +1,127 @@
+package launcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/containerd/containerd/oci"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+const nvidiaCDISpec = `
+cdiVersion: 0.5.0
+kind: nvidia.com/gpu
+containerEdits:
+  deviceNodes:
+  - path: /dev/nvidiactl
+devices:
+- name: "0"
+  containerEdits:
+    env: [NVIDIA_VISIBLE_DEVICES=0]
+    deviceNodes:
+    - path: /dev/nvidia0
+- name: "1"
+  containerEdits:
+    deviceNodes:
+    - path: /dev/nvidia1
+      hostPath: /dev/nvidia9
+      permissions: rw
+`
+
+func writeCDISpec(t *testing.T, dir string, name string, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveCDIDevices(t *testing.T) {
+	dir := t.TempDir()
+	writeCDISpec(t, dir, "nvidia.yaml", nvidiaCDISpec)
+	writeCDISpec(t, dir, "kvm.json", `{"cdiVersion": "0.5.0", "kind": "example.com/kvm", "devices": [{"name": "kvm", "containerEdits": {"deviceNodes": [{"path": "/dev/kvm"}]}}]}`)
+	writeCDISpec(t, dir, "README", "not a spec")
+
+	devices, err := resolveCDIDevices([]string{dir, filepath.Join(dir, "missing")}, []string{"nvidia.com/gpu=1", "example.com/kvm=kvm", "nvidia.com/gpu=0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := cdiDeviceClaims(devices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []cel.CosTlv{
+		{EventType: cel.CDIDeviceType, EventContent: []byte("nvidia.com/gpu=1 /dev/nvidiactl,/dev/nvidia9")},
+		{EventType: cel.CDIDeviceType, EventContent: []byte("example.com/kvm=kvm /dev/kvm")},
+		{EventType: cel.CDIDeviceType, EventContent: []byte("nvidia.com/gpu=0 /dev/nvidiactl,/dev/nvidia0")},
+	}
+	if diff := cmp.Diff(want, events); diff != "" {
+		t.Errorf("unexpected CDI device events (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"NVIDIA_VISIBLE_DEVICES=0"}, devices[2].env); diff != "" {
+		t.Errorf("unexpected env of nvidia.com/gpu=0 (-want +got):\n%s", diff)
+	}
+}
+
+func TestResolveCDIDevicesErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		specs   []string
+		devices []string
+		wantErr string
+	}{
+		{"NotFound", []string{nvidiaCDISpec}, []string{"nvidia.com/gpu=2"}, "not found"},
+		{"Duplicate", []string{nvidiaCDISpec, nvidiaCDISpec}, []string{"nvidia.com/gpu=0"}, "defined more than once"},
+		{"Mounts", []string{"cdiVersion: 0.5.0\nkind: example.com/lib\ndevices:\n- name: lib\n  containerEdits:\n    mounts:\n    - hostPath: /lib\n      containerPath: /lib\n"}, []string{"example.com/lib=lib"}, "container edits are supported"},
+		{"Hooks", []string{"cdiVersion: 0.5.0\nkind: example.com/hook\ncontainerEdits:\n  hooks:\n  - hookName: createContainer\n    path: /bin/true\ndevices:\n- name: hook\n"}, []string{"example.com/hook=hook"}, "container edits are supported"},
+		{"HostPathOutsideDev", []string{"cdiVersion: 0.5.0\nkind: example.com/disk\ndevices:\n- name: disk\n  containerEdits:\n    deviceNodes:\n    - path: /dev/disk\n      hostPath: /etc/shadow\n"}, []string{"example.com/disk=disk"}, "under /dev/"},
+		{"BadPermissions", []string{"cdiVersion: 0.5.0\nkind: example.com/disk\ndevices:\n- name: disk\n  containerEdits:\n    deviceNodes:\n    - path: /dev/disk\n      permissions: rwx\n"}, []string{"example.com/disk=disk"}, "invalid permissions"},
+		{"BadName", []string{"cdiVersion: 0.5.0\nkind: example.com/disk\ndevices:\n- name: disk/0\n"}, []string{"example.com/disk=disk"}, "invalid vendor"},
+		{"NoVersion", []string{"kind: example.com/disk\ndevices:\n- name: disk\n"}, []string{"example.com/disk=disk"}, "cdiVersion"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for i, content := range tc.specs {
+				writeCDISpec(t, dir, string(rune('a'+i))+".yaml", content)
+			}
+			if _, err := resolveCDIDevices([]string{dir}, tc.devices); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("got error %v, want an error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCDISpecOpts(t *testing.T) {
+	devices := []cdiDevice{
+		{name: "example.com/null=a", env: []string{"NULL=a"}, deviceNodes: []cdiDeviceNode{{Path: "/dev/null", HostPath: "/dev/null", Permissions: "rwm"}}},
+		{name: "example.com/null=b", deviceNodes: []cdiDeviceNode{
+			{Path: "/dev/null", HostPath: "/dev/null", Permissions: "rwm"},
+			{Path: "/dev/zero-in-container", HostPath: "/dev/zero", Permissions: "r"},
+		}},
+	}
+	s := &oci.Spec{Process: &specs.Process{}}
+	for _, opt := range cdiSpecOpts(devices) {
+		if err := opt(context.Background(), nil, nil, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var paths []string
+	for _, device := range s.Linux.Devices {
+		paths = append(paths, device.Path)
+	}
+	// The shared device node is only injected once.
+	if diff := cmp.Diff([]string{"/dev/null", "/dev/zero-in-container"}, paths); diff != "" {
+		t.Errorf("unexpected devices (-want +got):\n%s", diff)
+	}
+	if got := s.Linux.Resources.Devices[1].Access; got != "r" {
+		t.Errorf("got access %q for /dev/zero, want r", got)
+	}
+	if diff := cmp.Diff([]string{"NULL=a"}, s.Process.Env); diff != "" {
+		t.Errorf("unexpected env (-want +got):\n%s", diff)
+	}
+}
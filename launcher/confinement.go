@@ -0,0 +1,78 @@
+package launcher
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/containerd/containerd/containers"
+	"github.com/containerd/containerd/contrib/apparmor"
+	"github.com/containerd/containerd/contrib/seccomp"
+	"github.com/containerd/containerd/oci"
+	"github.com/google/go-tpm-tools/launcher/spec"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// defaultAppArmorProfile is the name the default AppArmor profile of
+// containerd is loaded with.
+const defaultAppArmorProfile = "tee-default"
+
+// confinementSpecOpts returns the options applying the seccomp and AppArmor
+// profiles of the LaunchSpec to the container.
+func confinementSpecOpts(launchSpec spec.LaunchSpec) ([]oci.SpecOpts, error) {
+	var opts []oci.SpecOpts
+	switch launchSpec.SeccompProfile {
+	case "", spec.UnconfinedProfile:
+	case spec.RuntimeDefaultProfile:
+		opts = append(opts, seccomp.WithDefaultProfile())
+	default:
+		var profile specs.LinuxSeccomp
+		if err := json.Unmarshal([]byte(launchSpec.SeccompProfile), &profile); err != nil {
+			return nil, fmt.Errorf("invalid seccomp profile: %v", err)
+		}
+		opts = append(opts, withSeccomp(&profile))
+	}
+	switch launchSpec.AppArmorProfile {
+	case "", spec.UnconfinedProfile:
+	case spec.RuntimeDefaultProfile:
+		opts = append(opts, apparmor.WithDefaultProfile(defaultAppArmorProfile))
+	default:
+		opts = append(opts, apparmor.WithProfile(launchSpec.AppArmorProfile))
+	}
+	return opts, nil
+}
+
+// withSeccomp sets the seccomp profile of the container.
+func withSeccomp(profile *specs.LinuxSeccomp) oci.SpecOpts {
+	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
+		if s.Linux == nil {
+			s.Linux = &specs.Linux{}
+		}
+		s.Linux.Seccomp = profile
+		return nil
+	}
+}
+
+// seccompProfileClaim returns the measured seccomp profile, see
+// cel.SeccompProfileType.
+func seccompProfileClaim(profile string) string {
+	switch profile {
+	case "", spec.UnconfinedProfile:
+		return spec.UnconfinedProfile
+	case spec.RuntimeDefaultProfile:
+		return spec.RuntimeDefaultProfile
+	}
+	digest := sha256.Sum256([]byte(profile))
+	return "sha256:" + hex.EncodeToString(digest[:])
+}
+
+// appArmorProfileClaim returns the measured AppArmor profile, see
+// cel.AppArmorProfileType.
+func appArmorProfileClaim(profile string) string {
+	if profile == "" {
+		return spec.UnconfinedProfile
+	}
+	return profile
+}
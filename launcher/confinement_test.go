@@ -0,0 +1,62 @@
+package launcher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/containerd/containerd/oci"
+	"github.com/google/go-tpm-tools/launcher/spec"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func TestConfinementSpecOpts(t *testing.T) {
+	launchSpec := spec.LaunchSpec{
+		SeccompProfile:  `{"defaultAction":"SCMP_ACT_ERRNO","syscalls":[{"names":["read","write"],"action":"SCMP_ACT_ALLOW"}]}`,
+		AppArmorProfile: spec.UnconfinedProfile,
+	}
+	opts, err := confinementSpecOpts(launchSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &oci.Spec{}
+	if err := oci.ApplyOpts(context.Background(), nil, nil, s, opts...); err != nil {
+		t.Fatal(err)
+	}
+	if s.Linux.Seccomp == nil || s.Linux.Seccomp.DefaultAction != specs.ActErrno {
+		t.Fatalf("got seccomp profile %+v, want the custom profile", s.Linux.Seccomp)
+	}
+	if len(s.Linux.Seccomp.Syscalls) != 1 || len(s.Linux.Seccomp.Syscalls[0].Names) != 2 {
+		t.Errorf("got seccomp syscalls %+v, want read and write allowed", s.Linux.Seccomp.Syscalls)
+	}
+	if s.Process != nil && s.Process.ApparmorProfile != "" {
+		t.Errorf("got AppArmor profile %q for an unconfined container", s.Process.ApparmorProfile)
+	}
+
+	if opts, err := confinementSpecOpts(spec.LaunchSpec{}); err != nil || len(opts) != 0 {
+		t.Errorf("confinementSpecOpts() = %d options, %v for an unconfined container, want none", len(opts), err)
+	}
+}
+
+func TestProfileClaims(t *testing.T) {
+	testCases := []struct {
+		name     string
+		profile  string
+		seccomp  string
+		apparmor string
+	}{
+		{"empty", "", "unconfined", "unconfined"},
+		{"unconfined", "unconfined", "unconfined", "unconfined"},
+		{"runtime default", "runtime-default", "runtime-default", "runtime-default"},
+		{"custom", `{}`, "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a", `{}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := seccompProfileClaim(tc.profile); got != tc.seccomp {
+				t.Errorf("seccompProfileClaim(%q) = %q, want %q", tc.profile, got, tc.seccomp)
+			}
+			if got := appArmorProfileClaim(tc.profile); got != tc.apparmor {
+				t.Errorf("appArmorProfileClaim(%q) = %q, want %q", tc.profile, got, tc.apparmor)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package launcher
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"strconv"
 	"time"
 
 	"cloud.google.com/go/compute/metadata"
@@ -25,6 +27,7 @@ import (
 	"github.com/google/go-tpm-tools/cel"
 	"github.com/google/go-tpm-tools/client"
 	"github.com/google/go-tpm-tools/launcher/agent"
+	"github.com/google/go-tpm-tools/launcher/intoto"
 	"github.com/google/go-tpm-tools/launcher/spec"
 	"github.com/google/go-tpm-tools/launcher/verifier"
 	"github.com/google/go-tpm-tools/launcher/verifier/rest"
@@ -42,22 +45,59 @@ type ContainerRunner struct {
 	launchSpec  spec.LaunchSpec
 	attestAgent agent.AttestationAgent
 	logger      *log.Logger
+	// workloadKey is the ephemeral workload key, if enabled in the LaunchSpec.
+	workloadKey *ecdsa.PrivateKey
+	// metricsExporter exports the workload stats to Cloud Monitoring, if
+	// enabled in the LaunchSpec.
+	metricsExporter *cloudMonitoringExporter
+	// policyDocument is the signed launch policy document, if set in the
+	// LaunchSpec.
+	policyDocument *spec.PolicyDocument
+	// clock is the state of the clock at boot, checked by Preflight.
+	clock ClockStatus
+	// tokenFiles serializes the writes of the token files with their
+	// revocation by the token watchdog.
+	tokenFiles tokenFiles
+	// cdiDevices are the CDI devices of the LaunchSpec injected into the
+	// container.
+	cdiDevices []cdiDevice
 }
 
 const (
-	// hostTokenPath defined the directory in the host that will store attestation tokens
+	// hostTokenPath defined the directory in the host that will store attestation tokens,
+	// on the tmpfs of MountTokenTmpfs
 	hostTokenPath = "/tmp/container_launcher/"
 	// containerTokenMountPath defined the directory in the container stores attestation tokens
 	containerTokenMountPath      = "/run/container_launcher/"
 	attestationVerifierTokenFile = "attestation_verifier_claims_token"
+	// celFile and celSignaturesFile store the CEL and the chain of its
+	// signatures (one JSON agent.CELSignature per line) next to the tokens.
+	celFile           = "cel"
+	celSignaturesFile = "cel_signatures"
+	// celCheckpointsFile stores the checkpoints of the CEL attested for every
+	// token issued (one JSON agent.Checkpoint per line).
+	celCheckpointsFile = "cel_checkpoints"
+	// provenanceFile stores the in-toto statement about the container, in a
+	// DSSE envelope signed with the attestation key.
+	provenanceFile = "container.intoto.json"
 )
 
+// celSigningInterval is how often the CEL is signed with the attestation key.
+const celSigningInterval = 10 * time.Minute
+
 // Since we only allow one container on a VM, using a deterministic id is probably fine
 const (
 	containerID = "tee-container"
 	snapshotID  = "tee-snapshot"
 )
 
+// The users and groups of a container in a user namespace are mapped to the
+// unprivileged host IDs [userNamespaceHostID, userNamespaceHostID+userNamespaceSize).
+const (
+	userNamespaceHostID = 100000
+	userNamespaceSize   = 65536
+)
+
 const (
 	// defaultRefreshMultiplier is a multiplier on the current token expiration
 	// time, at which the refresher goroutine will collect a new token.
@@ -69,6 +109,10 @@ const (
 	defaultRefreshJitter = 0.1
 )
 
+// iamcredentialsScope is the scope of the credentials impersonating the
+// ImpersonateServiceAccounts.
+const iamcredentialsScope = "https://www.googleapis.com/auth/cloud-platform"
+
 func fetchImpersonatedToken(ctx context.Context, serviceAccount string, audience string, opts ...option.ClientOption) ([]byte, error) {
 	config := impersonate.IDTokenConfig{
 		Audience:        audience,
@@ -89,15 +133,14 @@ func fetchImpersonatedToken(ctx context.Context, serviceAccount string, audience
 	return []byte(token.AccessToken), nil
 }
 
-// NewRunner returns a runner.
-func NewRunner(ctx context.Context, cdClient *containerd.Client, token oauth2.Token, launchSpec spec.LaunchSpec, mdsClient *metadata.Client, tpm io.ReadWriteCloser, logger *log.Logger) (*ContainerRunner, error) {
+// NewRunner returns a runner. The clock checked by Preflight is measured with
+// the container claims.
+func NewRunner(ctx context.Context, cdClient *containerd.Client, token oauth2.Token, launchSpec spec.LaunchSpec, mdsClient *metadata.Client, tpm io.ReadWriteCloser, logger *log.Logger, clock ClockStatus) (*ContainerRunner, error) {
 	image, err := initImage(ctx, cdClient, launchSpec, token, logger)
 	if err != nil {
-		return nil, err
+		return nil, &ImagePullError{err}
 	}
 
-	mounts := make([]specs.Mount, 0)
-	mounts = appendTokenMounts(mounts)
 	envs, err := formatEnvVars(launchSpec.Envs)
 	if err != nil {
 		return nil, err
@@ -114,18 +157,20 @@ func NewRunner(ctx context.Context, cdClient *containerd.Client, token oauth2.To
 	logger.Printf("Operator Override Env Vars : %v\n", envs)
 	logger.Printf("Operator Override Cmd      : %v\n", launchSpec.Cmd)
 
-	imageLabels, err := getImageLabels(ctx, image)
+	setup, err := setupRunner(ctx, image, launchSpec, logger)
 	if err != nil {
-		logger.Printf("Failed to get image OCI labels %v\n", err)
+		return nil, err
 	}
+	launchSpec = setup.launchSpec
 
-	logger.Printf("Image Labels               : %v\n", imageLabels)
-	launchPolicy, err := spec.GetLaunchPolicy(imageLabels)
-	if err != nil {
-		return nil, err
+	mounts := make([]specs.Mount, 0)
+	mounts = appendTokenMounts(mounts)
+	mounts = appendLaunchSpecMounts(mounts, containerMounts(launchSpec))
+	if setup.workloadKey != nil {
+		mounts = appendWorkloadKeyMounts(mounts)
 	}
-	if err := launchPolicy.Verify(launchSpec); err != nil {
-		return nil, err
+	if launchSpec.WorkloadConfig != "" {
+		mounts = appendWorkloadConfigMounts(mounts)
 	}
 
 	if imageConfig, err := image.Config(ctx); err != nil {
@@ -140,22 +185,66 @@ func NewRunner(ctx context.Context, cdClient *containerd.Client, token oauth2.To
 		return nil, &RetryableError{fmt.Errorf("cannot get hostname: [%w]", err)}
 	}
 
+	specOpts := []oci.SpecOpts{
+		oci.WithImageConfigArgs(image, launchSpec.Cmd),
+		oci.WithEnv(envs),
+		oci.WithMounts(mounts),
+		oci.WithEnv([]string{fmt.Sprintf("HOSTNAME=%s", hostname)}),
+		oci.WithEnv(gcpCredentialsEnv(launchSpec)),
+	}
+	if launchSpec.HostNetwork {
+		// following 3 options are here to allow the container to have
+		// the host network (same effect as --net-host in ctr command)
+		specOpts = append(specOpts,
+			oci.WithHostHostsFile,
+			oci.WithHostResolvconf,
+			oci.WithHostNamespace(specs.NetworkNamespace),
+		)
+	}
+	if launchSpec.ReadOnlyRootfs {
+		specOpts = append(specOpts, oci.WithRootFSReadonly())
+	}
+	if len(launchSpec.AddedCapabilities) > 0 {
+		specOpts = append(specOpts, oci.WithAddedCapabilities(launchSpec.AddedCapabilities))
+	}
+	for _, device := range launchSpec.Devices {
+		specOpts = append(specOpts, oci.WithLinuxDevice(device, "rwm"))
+	}
+	cdiDevices, err := resolveCDIDevices(cdiSpecDirs, launchSpec.CDIDevices)
+	if err != nil {
+		return nil, err
+	}
+	specOpts = append(specOpts, cdiSpecOpts(cdiDevices)...)
+	if launchSpec.User != "" {
+		uid, gid, hasGID, err := spec.ParseUser(launchSpec.User)
+		if err != nil {
+			return nil, err
+		}
+		if hasGID {
+			specOpts = append(specOpts, oci.WithUIDGID(uid, gid))
+		} else {
+			specOpts = append(specOpts, oci.WithUserID(uid))
+		}
+	}
+	confinementOpts, err := confinementSpecOpts(launchSpec)
+	if err != nil {
+		return nil, err
+	}
+	specOpts = append(specOpts, confinementOpts...)
+	snapshotOpt := containerd.WithNewSnapshot(snapshotID, image)
+	if launchSpec.UserNamespace {
+		idMap := []specs.LinuxIDMapping{{ContainerID: 0, HostID: userNamespaceHostID, Size: userNamespaceSize}}
+		specOpts = append(specOpts, oci.WithUserNamespace(idMap, idMap))
+		// The files of the image must be owned by the mapped users.
+		snapshotOpt = containerd.WithRemappedSnapshot(snapshotID, image, userNamespaceHostID, userNamespaceHostID)
+	}
+
 	container, err = cdClient.NewContainer(
 		ctx,
 		containerID,
 		containerd.WithImage(image),
-		containerd.WithNewSnapshot(snapshotID, image),
-		containerd.WithNewSpec(
-			oci.WithImageConfigArgs(image, launchSpec.Cmd),
-			oci.WithEnv(envs),
-			oci.WithMounts(mounts),
-			// following 4 options are here to allow the container to have
-			// the host network (same effect as --net-host in ctr command)
-			oci.WithHostHostsFile,
-			oci.WithHostResolvconf,
-			oci.WithHostNamespace(specs.NetworkNamespace),
-			oci.WithEnv([]string{fmt.Sprintf("HOSTNAME=%s", hostname)}),
-		),
+		snapshotOpt,
+		containerd.WithNewSpec(specOpts...),
 	)
 	if err != nil {
 		if container != nil {
@@ -178,6 +267,13 @@ func NewRunner(ctx context.Context, cdClient *containerd.Client, token oauth2.To
 				len(containerSpec.Process.Args), len(launchSpec.Cmd))
 	}
 
+	// All the egress uses the proxies and CA bundle of the LaunchSpec.
+	egressCtx := egressContext(ctx, newEgressClient(launchSpec))
+	impersonationClient, err := google.DefaultClient(egressCtx, iamcredentialsScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %v", err)
+	}
+
 	// Fetch ID token with specific audience.
 	// See https://cloud.google.com/functions/docs/securing/authenticating#functions-bearer-token-example-go.
 	principalFetcher := func(audience string) ([][]byte, error) {
@@ -197,7 +293,7 @@ func NewRunner(ctx context.Context, cdClient *containerd.Client, token oauth2.To
 
 		// Fetch impersonated ID tokens.
 		for _, sa := range launchSpec.ImpersonateServiceAccounts {
-			idToken, err := fetchImpersonatedToken(ctx, sa, audience)
+			idToken, err := fetchImpersonatedToken(ctx, sa, audience, option.WithHTTPClient(impersonationClient))
 			if err != nil {
 				return nil, fmt.Errorf("failed to get impersonated token for %v: %w", sa, err)
 			}
@@ -209,22 +305,230 @@ func NewRunner(ctx context.Context, cdClient *containerd.Client, token oauth2.To
 
 	asAddr := launchSpec.AttestationServiceAddr
 
-	verifierClient, err := getRESTClient(ctx, asAddr, launchSpec)
+	verifierClient, err := getRESTClient(egressCtx, asAddr, launchSpec)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create REST verifier client: %v", err)
 	}
 
+	var metricsExporter *cloudMonitoringExporter
+	if launchSpec.CloudMonitoring {
+		if metricsExporter, err = newCloudMonitoringExporter(egressCtx, mdsClient, launchSpec.ProjectID); err != nil {
+			return nil, fmt.Errorf("failed to create Cloud Monitoring exporter: %v", err)
+		}
+	}
+
+	return setup.newRunner(RunnerDeps{
+		Container:        container,
+		TPM:              tpm,
+		AKFetcher:        client.GceAttestationKeyECC,
+		Verifier:         verifierClient,
+		PrincipalFetcher: principalFetcher,
+		Logger:           logger,
+		Clock:            clock,
+	}, metricsExporter, cdiDevices), nil
+}
+
+// RunnerDeps are the dependencies of a ContainerRunner created with
+// NewRunnerWithDeps.
+type RunnerDeps struct {
+	// Container is the container to run, created by the caller.
+	Container containerd.Container
+	TPM       io.ReadWriteCloser
+	// AKFetcher returns the attestation key, client.GceAttestationKeyECC by
+	// default.
+	AKFetcher func(io.ReadWriter) (*client.Key, error)
+	Verifier  verifier.Client
+	// PrincipalFetcher returns the ID tokens sent to the verifier for the
+	// audience. No tokens are sent by default.
+	PrincipalFetcher func(audience string) ([][]byte, error)
+	// Logger defaults to log.Default().
+	Logger *log.Logger
+	Clock  ClockStatus
+}
+
+// NewRunnerWithDeps returns a ContainerRunner for a container created by the
+// caller. Unlike NewRunner, it does not pull the image, nor use the metadata
+// server or Google APIs, so the runner can be tested end to end with test
+// doubles of containerd and the verifier, like those of the launchertest
+// package. The launch policy of the image of the container is verified like
+// in NewRunner, but as the container already exists, the policy must not
+// change its confinement. Cloud Monitoring and CDI devices are not supported.
+func NewRunnerWithDeps(ctx context.Context, launchSpec spec.LaunchSpec, deps RunnerDeps) (*ContainerRunner, error) {
+	if deps.Container == nil || deps.TPM == nil || deps.Verifier == nil {
+		return nil, errors.New("the container, TPM and verifier are required")
+	}
+	if len(launchSpec.CDIDevices) > 0 {
+		return nil, errors.New("CDI devices are not supported for a container created by the caller")
+	}
+	if deps.AKFetcher == nil {
+		deps.AKFetcher = client.GceAttestationKeyECC
+	}
+	if deps.PrincipalFetcher == nil {
+		deps.PrincipalFetcher = func(string) ([][]byte, error) { return nil, nil }
+	}
+	if deps.Logger == nil {
+		deps.Logger = log.Default()
+	}
+	image, err := deps.Container.Image(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the image of the container: %v", err)
+	}
+	setup, err := setupRunner(ctx, image, launchSpec, deps.Logger)
+	if err != nil {
+		return nil, err
+	}
+	if setup.launchSpec.SeccompProfile != launchSpec.SeccompProfile || setup.launchSpec.AppArmorProfile != launchSpec.AppArmorProfile {
+		return nil, &PolicyError{errors.New("the launch policy confines a container created by the caller without the confinement")}
+	}
+	return setup.newRunner(deps, nil, nil), nil
+}
+
+// runnerSetup is the state shared by the constructors of ContainerRunner.
+type runnerSetup struct {
+	// launchSpec is the LaunchSpec with the launch policy of the image applied.
+	launchSpec     spec.LaunchSpec
+	collectors     []agent.Collector
+	policyDocument *spec.PolicyDocument
+	workloadKey    *ecdsa.PrivateKey
+}
+
+// setupRunner verifies the LaunchSpec against the launch policy of the image
+// and the launch policy document, then writes the workload key and config.
+func setupRunner(ctx context.Context, image containerd.Image, launchSpec spec.LaunchSpec, logger *log.Logger) (*runnerSetup, error) {
+	collectors, err := agent.LookupCollectors(launchSpec.EvidenceCollectors)
+	if err != nil {
+		return nil, err
+	}
+
+	imageLabels, err := getImageLabels(ctx, image)
+	if err != nil {
+		logger.Printf("Failed to get image OCI labels %v\n", err)
+	}
+
+	logger.Printf("Image Labels               : %v\n", imageLabels)
+	launchPolicy, err := spec.GetLaunchPolicy(imageLabels)
+	if err != nil {
+		return nil, &PolicyError{err}
+	}
+	if err := launchPolicy.Verify(launchSpec); err != nil {
+		return nil, &PolicyError{err}
+	}
+	launchSpec = launchPolicy.Apply(launchSpec)
+
+	var policyDocument *spec.PolicyDocument
+	if launchSpec.LaunchPolicyURL != "" {
+		if policyDocument, err = fetchPolicyDocument(ctx, newEgressClient(launchSpec), launchSpec); err != nil {
+			return nil, err
+		}
+		logger.Printf("Launch Policy Document     : %s signed by %s\n", policyDocument.Digest, policyDocument.Signer)
+		if err := verifyPolicyDocument(policyDocument, image.Name(), image.Target().Digest.String(), launchSpec); err != nil {
+			return nil, &PolicyError{err}
+		}
+	}
+
+	var workloadKey *ecdsa.PrivateKey
+	if launchSpec.WorkloadKey {
+		if workloadKey, err = newWorkloadKey(); err != nil {
+			return nil, fmt.Errorf("failed to generate the workload key: %v", err)
+		}
+		if err := writeWorkloadKey(workloadKey, hostWorkloadKeyPath); err != nil {
+			return nil, fmt.Errorf("failed to write the workload key: %v", err)
+		}
+	}
+	if launchSpec.WorkloadConfig != "" {
+		if err := writeWorkloadConfig(launchSpec.WorkloadConfig, hostWorkloadConfigPath); err != nil {
+			return nil, fmt.Errorf("failed to write the workload config: %v", err)
+		}
+	}
+	return &runnerSetup{launchSpec, collectors, policyDocument, workloadKey}, nil
+}
+
+// newRunner returns a ContainerRunner of the container of deps, attesting
+// with its TPM and verifier.
+func (s *runnerSetup) newRunner(deps RunnerDeps, metricsExporter *cloudMonitoringExporter, cdiDevices []cdiDevice) *ContainerRunner {
+	verifierClient := deps.Verifier
+	if len(s.launchSpec.TokenAudiences) > 0 {
+		verifierClient = verifier.WithTokenAudiences(verifierClient, s.launchSpec.TokenAudiences)
+	}
+	middlewares := []agent.Middleware{writeMeasuredClaims(hostTokenPath, deps.Logger)}
+	if s.launchSpec.LogVerbosity == spec.Debug {
+		middlewares = append(middlewares, logMeasuredEvents(deps.Logger))
+	}
+	opts := agent.Opts{
+		Collectors:    s.collectors,
+		CELHashAlgos:  s.launchSpec.CELHashAlgorithms,
+		MeasureHeader: s.launchSpec.MeasureCELHeader,
+		Checkpoint:    writeCELCheckpoint(hostTokenPath, deps.Logger),
+	}
+
 	return &ContainerRunner{
-		container,
-		launchSpec,
-		agent.CreateAttestationAgent(tpm, client.GceAttestationKeyECC, verifierClient, principalFetcher),
-		logger,
-	}, nil
+		deps.Container,
+		s.launchSpec,
+		agent.CreateAttestationAgentWithOpts(deps.TPM, deps.AKFetcher, verifierClient, deps.PrincipalFetcher, opts, middlewares...),
+		deps.Logger,
+		s.workloadKey,
+		metricsExporter,
+		s.policyDocument,
+		deps.Clock,
+		tokenFiles{},
+		cdiDevices,
+	}
+}
+
+// workloadOwner returns the host UID and GID of the container process, which
+// own the files only the workload may read.
+func workloadOwner(ctx context.Context, container containerd.Container) (int, int, error) {
+	s, err := container.Spec(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	if s.Process == nil {
+		return 0, 0, errors.New("the container has no process")
+	}
+	var uidMappings, gidMappings []specs.LinuxIDMapping
+	if s.Linux != nil {
+		uidMappings, gidMappings = s.Linux.UIDMappings, s.Linux.GIDMappings
+	}
+	uid, err := hostID(s.Process.User.UID, uidMappings)
+	if err != nil {
+		return 0, 0, fmt.Errorf("UID: %v", err)
+	}
+	gid, err := hostID(s.Process.User.GID, gidMappings)
+	if err != nil {
+		return 0, 0, fmt.Errorf("GID: %v", err)
+	}
+	return uid, gid, nil
+}
+
+// hostID maps the ID of the container to the host with the mappings of its
+// user namespace, if any.
+func hostID(id uint32, mappings []specs.LinuxIDMapping) (int, error) {
+	if len(mappings) == 0 {
+		return int(id), nil
+	}
+	for _, m := range mappings {
+		if id >= m.ContainerID && id-m.ContainerID < m.Size {
+			return int(m.HostID + id - m.ContainerID), nil
+		}
+	}
+	return 0, fmt.Errorf("%d is not mapped to the host", id)
+}
+
+// logMeasuredEvents returns a Middleware logging every measured event.
+func logMeasuredEvents(logger *log.Logger) agent.Middleware {
+	return agent.WithHooks(agent.Hooks{
+		PostMeasure: func(event cel.Content, err error) {
+			if cos, ok := event.(cel.CosTlv); ok {
+				logger.Printf("measured %s event %q: %v\n", cosTypeNames[cos.EventType], cos.EventContent, err)
+			}
+		},
+	})
 }
 
 // getRESTClient returns a REST verifier.Client that points to the given address.
 // It defaults to the Attestation Verifier instance at
-// https://confidentialcomputing.googleapis.com.
+// https://confidentialcomputing.googleapis.com. With AttestationServiceRegions,
+// it fails over between the regions.
 func getRESTClient(ctx context.Context, asAddr string, spec spec.LaunchSpec) (verifier.Client, error) {
 	httpClient, err := google.DefaultClient(ctx)
 	if err != nil {
@@ -236,6 +540,11 @@ func getRESTClient(ctx context.Context, asAddr string, spec spec.LaunchSpec) (ve
 		opts = append(opts, option.WithEndpoint(asAddr))
 	}
 
+	if len(spec.AttestationServiceRegions) > 0 {
+		return rest.NewRegionalClient(ctx, spec.ProjectID, spec.AttestationServiceRegions, verifier.FailoverOptions{
+			HedgeDelay: spec.AttestationServiceHedgeDelay,
+		}, opts...)
+	}
 	restClient, err := rest.NewClient(ctx, spec.ProjectID, spec.Region, opts...)
 	if err != nil {
 		return nil, err
@@ -267,9 +576,123 @@ func appendTokenMounts(mounts []specs.Mount) []specs.Mount {
 	return append(mounts, m)
 }
 
+// appendWorkloadKeyMounts appends the mount spec for the private workload key
+func appendWorkloadKeyMounts(mounts []specs.Mount) []specs.Mount {
+	return append(mounts, specs.Mount{
+		Destination: containerWorkloadKeyMountPath,
+		Type:        "bind",
+		Source:      hostWorkloadKeyPath,
+		Options:     []string{"rbind", "ro"},
+	})
+}
+
+// appendWorkloadConfigMounts appends the mount spec for the workload
+// configuration
+func appendWorkloadConfigMounts(mounts []specs.Mount) []specs.Mount {
+	return append(mounts, specs.Mount{
+		Destination: containerWorkloadConfigMountPath,
+		Type:        "bind",
+		Source:      hostWorkloadConfigPath,
+		Options:     []string{"rbind", "ro"},
+	})
+}
+
+// appendLaunchSpecMounts appends the mount specs for the operator's mounts
+func appendLaunchSpecMounts(mounts []specs.Mount, specMounts []cel.Mount) []specs.Mount {
+	for i, sm := range specMounts {
+		m := specs.Mount{Destination: sm.Destination, Type: sm.Type}
+		switch sm.Type {
+		case cel.BindMountType:
+			m.Source = sm.Source
+			m.Options = []string{"rbind"}
+		case cel.TmpfsMountType:
+			m.Source = cel.TmpfsMountType
+			m.Options = []string{"nosuid", "nodev"}
+		case cel.GCSMountType:
+			// The bucket is mounted on the host by mountGCSBuckets before
+			// the task starts.
+			m.Type = cel.BindMountType
+			m.Source = gcsMountPoint(i)
+			m.Options = []string{"rbind"}
+		}
+		if sm.ReadOnly {
+			m.Options = append(m.Options, "ro")
+		} else {
+			m.Options = append(m.Options, "rw")
+		}
+		mounts = append(mounts, m)
+	}
+	return mounts
+}
+
+// containerMounts returns the operator's mounts, followed by the tmpfs
+// overlays of the read-only root filesystem for the destinations which are
+// not already mounted.
+func containerMounts(launchSpec spec.LaunchSpec) []cel.Mount {
+	if !launchSpec.ReadOnlyRootfs {
+		return launchSpec.Mounts
+	}
+	mounts := append([]cel.Mount(nil), launchSpec.Mounts...)
+	for _, dest := range launchSpec.ReadOnlyRootfsTmpfs {
+		mounted := false
+		for _, m := range launchSpec.Mounts {
+			if path.Clean(m.Destination) == dest {
+				mounted = true
+				break
+			}
+		}
+		if !mounted {
+			mounts = append(mounts, cel.Mount{Type: cel.TmpfsMountType, Destination: dest})
+		}
+	}
+	return mounts
+}
+
+// hardeningClaims returns the COS events for the hardening settings in the
+// LaunchSpec, which are measured before the LaunchSeparator.
+func hardeningClaims(launchSpec spec.LaunchSpec) ([]cel.CosTlv, error) {
+	events := []cel.CosTlv{
+		{EventType: cel.HostNetworkType, EventContent: []byte(strconv.FormatBool(launchSpec.HostNetwork))},
+		{EventType: cel.ReadOnlyRootfsType, EventContent: []byte(strconv.FormatBool(launchSpec.ReadOnlyRootfs))},
+		{EventType: cel.UserNamespaceType, EventContent: []byte(strconv.FormatBool(launchSpec.UserNamespace))},
+		{EventType: cel.SeccompProfileType, EventContent: []byte(seccompProfileClaim(launchSpec.SeccompProfile))},
+		{EventType: cel.AppArmorProfileType, EventContent: []byte(appArmorProfileClaim(launchSpec.AppArmorProfile))},
+	}
+	if launchSpec.User != "" {
+		events = append(events, cel.CosTlv{EventType: cel.UserType, EventContent: []byte(launchSpec.User)})
+	}
+	for _, c := range launchSpec.AddedCapabilities {
+		events = append(events, cel.CosTlv{EventType: cel.AddedCapabilityType, EventContent: []byte(c)})
+	}
+	for _, m := range containerMounts(launchSpec) {
+		mount, err := cel.FormatMount(m)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, cel.CosTlv{EventType: cel.MountType, EventContent: []byte(mount)})
+	}
+	for _, d := range launchSpec.Devices {
+		events = append(events, cel.CosTlv{EventType: cel.DeviceType, EventContent: []byte(d)})
+	}
+	if launchSpec.AttestBeforeRun {
+		events = append(events, cel.CosTlv{EventType: cel.AttestBeforeRunType, EventContent: []byte("true")})
+	}
+	if launchSpec.ApprovalURL != "" {
+		events = append(events, cel.CosTlv{EventType: cel.ApprovalURLType, EventContent: []byte(launchSpec.ApprovalURL)})
+	}
+	return events, nil
+}
+
 // measureContainerClaims will measure various container claims into the COS
 // eventlog in the AttestationAgent.
 func (r *ContainerRunner) measureContainerClaims(ctx context.Context) error {
+	launcher, err := launcherEvent()
+	if err != nil {
+		return fmt.Errorf("failed to measure the launcher: %v", err)
+	}
+	if err := r.attestAgent.MeasureEvent(launcher); err != nil {
+		return err
+	}
 	image, err := r.container.Image(ctx)
 	if err != nil {
 		return err
@@ -288,6 +711,25 @@ func (r *ContainerRunner) measureContainerClaims(ctx context.Context) error {
 			return err
 		}
 	}
+	readJSON := readContentJSON(image.ContentStore())
+	manifestDesc, manifest, err := resolveManifest(ctx, readJSON, image.Target(), image.Platform())
+	if err != nil {
+		return fmt.Errorf("failed to read the image manifest: %v", err)
+	}
+	var imageConfig v1.Image
+	if err := readJSON(ctx, manifest.Config, &imageConfig); err != nil {
+		return fmt.Errorf("failed to read the image config: %v", err)
+	}
+	for _, event := range imagePlatformClaims(r.launchSpec, image.Target(), manifestDesc, imageConfig) {
+		if err := r.attestAgent.MeasureEvent(event); err != nil {
+			return err
+		}
+	}
+	for _, layer := range manifest.Layers {
+		if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.ImageLayerType, EventContent: []byte(layer.Digest)}); err != nil {
+			return err
+		}
+	}
 
 	containerSpec, err := r.container.Spec(ctx)
 	if err != nil {
@@ -320,6 +762,63 @@ func (r *ContainerRunner) measureContainerClaims(ctx context.Context) error {
 		}
 	}
 
+	hardening, err := hardeningClaims(r.launchSpec)
+	if err != nil {
+		return err
+	}
+	for _, event := range hardening {
+		if err := r.attestAgent.MeasureEvent(event); err != nil {
+			return err
+		}
+	}
+	cdiEvents, err := cdiDeviceClaims(r.cdiDevices)
+	if err != nil {
+		return err
+	}
+	for _, event := range cdiEvents {
+		if err := r.attestAgent.MeasureEvent(event); err != nil {
+			return err
+		}
+	}
+
+	if r.workloadKey != nil {
+		digest, err := workloadKeyDigest(r.workloadKey.Public())
+		if err != nil {
+			return err
+		}
+		if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.WorkloadKeyType, EventContent: []byte(digest)}); err != nil {
+			return err
+		}
+	}
+	if r.launchSpec.WorkloadConfig != "" {
+		digest := workloadConfigDigest(r.launchSpec.WorkloadConfig)
+		if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.WorkloadConfigType, EventContent: []byte(digest)}); err != nil {
+			return err
+		}
+	}
+	if r.policyDocument != nil {
+		for _, event := range policyDocumentEvents(r.policyDocument) {
+			if err := r.attestAgent.MeasureEvent(event); err != nil {
+				return err
+			}
+		}
+	}
+	for _, event := range clockEvents(r.clock) {
+		if err := r.attestAgent.MeasureEvent(event); err != nil {
+			return err
+		}
+	}
+	if r.launchSpec.MeasureKernelLog {
+		event, err := kernelLogEvent()
+		if err != nil {
+			return err
+		}
+		r.logger.Printf("measured the kernel log: %s\n", event.EventContent)
+		if err := r.attestAgent.MeasureEvent(event); err != nil {
+			return err
+		}
+	}
+
 	separator := cel.CosTlv{
 		EventType:    cel.LaunchSeparatorType,
 		EventContent: nil, // Success
@@ -327,9 +826,13 @@ func (r *ContainerRunner) measureContainerClaims(ctx context.Context) error {
 	return r.attestAgent.MeasureEvent(separator)
 }
 
-// Retrieves an OIDC token from the attestation service, and returns how long
+// Retrieves an OIDC token from the attestation service, writes it to the token
+// file unless it is only delivered on the token socket, and returns how long
 // to wait before attemping to refresh it.
 func (r *ContainerRunner) refreshToken(ctx context.Context) (time.Duration, error) {
+	if r.isTokenRevoked() {
+		return 0, backoff.Permanent(errTokenRevoked)
+	}
 	r.logger.Print("refreshing attestation verifier OIDC token")
 	token, err := r.attestAgent.Attest(ctx)
 	if err != nil {
@@ -348,9 +851,8 @@ func (r *ContainerRunner) refreshToken(ctx context.Context) (time.Duration, erro
 		return 0, errors.New("token is expired")
 	}
 
-	filepath := path.Join(hostTokenPath, attestationVerifierTokenFile)
-	if err = os.WriteFile(filepath, token, 0644); err != nil {
-		return 0, fmt.Errorf("failed to write token to container mount source point: %v", err)
+	if err := r.writeTokenFiles(ctx, token); err != nil {
+		return 0, err
 	}
 
 	// Print out the claims in the jwt payload
@@ -368,6 +870,28 @@ func (r *ContainerRunner) refreshToken(ctx context.Context) (time.Duration, erro
 	return getNextRefreshFromExpiration(time.Until(claims.ExpiresAt.Time), rand.Float64()), nil
 }
 
+// writeTokenFiles writes the token, and the GCP access token exchanged for
+// it, to the token files, unless the token watchdog revoked the token: it
+// may have been attested before the PCRs changed.
+func (r *ContainerRunner) writeTokenFiles(ctx context.Context, token []byte) error {
+	r.tokenFiles.mu.Lock()
+	defer r.tokenFiles.mu.Unlock()
+	if r.tokenFiles.revoked {
+		return backoff.Permanent(errTokenRevoked)
+	}
+	if r.launchSpec.TokenDelivery.File() {
+		if err := writeArtifact(hostTokenPath, attestationVerifierTokenFile, token); err != nil {
+			return fmt.Errorf("failed to write token to container mount source point: %v", err)
+		}
+	}
+	if r.launchSpec.GCPWorkloadIdentityProvider != "" {
+		if err := exchangeGCPToken(ctx, newEgressClient(r.launchSpec), r.launchSpec, hostTokenPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ctx must be a cancellable context.
 func (r *ContainerRunner) fetchAndWriteToken(ctx context.Context) error {
 	return r.fetchAndWriteTokenWithRetry(ctx, defaultRetryPolicy())
@@ -384,6 +908,10 @@ func (r *ContainerRunner) fetchAndWriteTokenWithRetry(ctx context.Context,
 	if err != nil {
 		return err
 	}
+	// The workload requests its own tokens on the token socket.
+	if !r.launchSpec.TokenDelivery.File() {
+		return nil
+	}
 
 	// Set a timer to refresh the token before it expires.
 	timer := time.NewTimer(duration)
@@ -463,27 +991,162 @@ func defaultRetryPolicy() *backoff.ExponentialBackOff {
 	return expBack
 }
 
+// writeSignedCEL signs the CEL, then writes it and appends the signature to
+// the signatures file in dir.
+func writeSignedCEL(signer agent.CELSigner, dir string) error {
+	encodedCEL, sig, err := signer.SignCEL()
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(sig)
+	if err != nil {
+		return err
+	}
+
+	celMu.Lock()
+	defer celMu.Unlock()
+	if err := flushCEL(dir, encodedCEL); err != nil {
+		return err
+	}
+	return appendArtifactLine(dir, celSignaturesFile, line)
+}
+
+// writeProvenance writes the signed in-toto statement about the container
+// measured in the CEL to dir.
+func writeProvenance(signer agent.CELSigner, dir string) error {
+	envelope, err := intoto.Sign(signer)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+	return writeArtifact(dir, provenanceFile, data)
+}
+
+// signCELPeriodically re-signs the CEL every celSigningInterval until ctx is
+// cancelled.
+func (r *ContainerRunner) signCELPeriodically(ctx context.Context, signer agent.CELSigner) {
+	ticker := time.NewTicker(celSigningInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Println("CEL signing stopped")
+			return
+		case <-ticker.C:
+			if err := writeSignedCEL(signer, hostTokenPath); err != nil {
+				r.logger.Printf("failed to sign CEL: %v", err)
+			}
+		}
+	}
+}
+
 // Run the container
 // Container output will always be redirected to logger writer for now
 func (r *ContainerRunner) Run(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	if err := os.MkdirAll(hostTokenPath, 0744); err != nil {
+		return err
+	}
 	if err := r.measureContainerClaims(ctx); err != nil {
-		return fmt.Errorf("failed to measure container claims: %v", err)
+		return &AttestationError{fmt.Errorf("failed to measure container claims: %v", err)}
+	}
+	if r.launchSpec.MeasureKernelModules {
+		if err := r.startKernelModuleMeasurement(ctx); err != nil {
+			return &AttestationError{fmt.Errorf("failed to measure kernel modules: %v", err)}
+		}
+	}
+	if r.launchSpec.AttestBeforeRun {
+		r.logger.Println("attest-before-run: the workload will only start once attested")
+		newRetry := func() backoff.BackOff { return defaultRetryPolicy() }
+		if err := r.attestBeforeRun(ctx, newEgressClient(r.launchSpec), newRetry); err != nil {
+			return err
+		}
+	} else if err := r.fetchAndWriteToken(ctx); err != nil {
+		return &AttestationError{fmt.Errorf("failed to fetch and write OIDC token: %v", err)}
 	}
-	if err := r.fetchAndWriteToken(ctx); err != nil {
-		return fmt.Errorf("failed to fetch and write OIDC token: %v", err)
+	if r.launchSpec.GCPWorkloadIdentityProvider != "" {
+		if err := writeGCPCredentials(r.launchSpec, hostTokenPath); err != nil {
+			return fmt.Errorf("failed to write the GCP credentials: %v", err)
+		}
 	}
+	if signer, ok := r.attestAgent.(agent.CELSigner); ok {
+		if err := writeSignedCEL(signer, hostTokenPath); err != nil {
+			return &AttestationError{fmt.Errorf("failed to sign CEL: %v", err)}
+		}
+		if err := writeProvenance(signer, hostTokenPath); err != nil {
+			return fmt.Errorf("failed to write in-toto statement: %v", err)
+		}
+		if err := writeAKCertificate(signer, hostTokenPath); err != nil {
+			return fmt.Errorf("failed to write the AK certificate: %v", err)
+		}
+		if r.workloadKey != nil {
+			if err := certifyWorkloadKey(signer, r.workloadKey.Public(), hostTokenPath); err != nil {
+				return fmt.Errorf("failed to certify the workload key: %v", err)
+			}
+		}
+		go r.signCELPeriodically(ctx, signer)
+	}
+	if r.launchSpec.TokenWatchdog != "" {
+		if err := r.startTokenWatchdog(ctx); err != nil {
+			return fmt.Errorf("failed to start the token watchdog: %v", err)
+		}
+	}
+	uid, gid, err := workloadOwner(ctx, r.container)
+	if err != nil {
+		return fmt.Errorf("failed to get the user of the workload: %v", err)
+	}
+	if r.workloadKey != nil {
+		if err := chownWorkloadKey(hostWorkloadKeyPath, uid, gid); err != nil {
+			return fmt.Errorf("failed to give the workload key to the user of the workload: %v", err)
+		}
+	}
+	if r.launchSpec.TokenDelivery.Socket() {
+		attester, ok := r.attestAgent.(agent.NonceAttester)
+		if !ok {
+			return fmt.Errorf("the attestation agent cannot serve the token socket")
+		}
+		if err := serveTokenSocket(ctx, hostTokenPath, attester, uid, gid, r.logger); err != nil {
+			return fmt.Errorf("failed to serve the token socket: %v", err)
+		}
+	}
+	if err := r.mountGCSBuckets(ctx); err != nil {
+		return fmt.Errorf("failed to mount GCS buckets: %v", err)
+	}
+	defer r.unmountGCSBuckets(context.Background())
 
-	var streamOpt cio.Opt
+	var outputs []io.Writer
 	if r.launchSpec.LogRedirect {
-		streamOpt = cio.WithStreams(nil, r.logger.Writer(), r.logger.Writer())
+		outputs = append(outputs, r.logger.Writer())
 		r.logger.Println("container stdout/stderr will be redirected")
 	} else {
-		streamOpt = cio.WithStreams(nil, nil, nil)
 		r.logger.Println("container stdout/stderr will not be redirected")
 	}
+	if r.launchSpec.WorkloadOutputLog {
+		outputLog, err := openOutputLog(hostOutputLogPath, r.attestAgent.MeasureEvent)
+		if err != nil {
+			return fmt.Errorf("failed to open the workload output log: %v", err)
+		}
+		// Deferred before deleting the task, so the last checkpoint covers
+		// all the output.
+		defer func() {
+			if err := outputLog.Close(); err != nil {
+				r.logger.Printf("failed to close the workload output log: %v", err)
+			}
+		}()
+		go outputLog.checkpointPeriodically(ctx, r.logger)
+		outputs = append(outputs, outputLog)
+		r.logger.Println("container stdout/stderr will be captured to the workload output log")
+	}
+	streamOpt := cio.WithStreams(nil, nil, nil)
+	if len(outputs) > 0 {
+		output := io.MultiWriter(outputs...)
+		streamOpt = cio.WithStreams(nil, output, output)
+	}
 
 	task, err := r.container.NewTask(ctx, cio.NewCreator(streamOpt))
 	if err != nil {
@@ -500,6 +1163,9 @@ func (r *ContainerRunner) Run(ctx context.Context) error {
 	if err := task.Start(ctx); err != nil {
 		return &RetryableError{err}
 	}
+	if r.launchSpec.MetricsAddress != "" || r.metricsExporter != nil {
+		r.startMetrics(ctx, task.Pid())
+	}
 	status := <-exitStatusC
 
 	code, _, err := status.Result()
@@ -515,19 +1181,31 @@ func (r *ContainerRunner) Run(ctx context.Context) error {
 	return nil
 }
 
+// initImage pulls the image of the LaunchSpec, or the first of its fallback
+// images which can be pulled.
 func initImage(ctx context.Context, cdClient *containerd.Client, launchSpec spec.LaunchSpec, token oauth2.Token, logger *log.Logger) (containerd.Image, error) {
-	if token.Valid() {
-		remoteOpt := containerd.WithResolver(Resolver(token.AccessToken))
-
-		image, err := cdClient.Pull(ctx, launchSpec.ImageRef, containerd.WithPullUnpack, remoteOpt)
-		if err != nil {
-			return nil, fmt.Errorf("cannot pull the image: %w", err)
+	httpClient := newEgressClient(launchSpec)
+	var image containerd.Image
+	var err error
+	for _, ref := range append([]string{launchSpec.ImageRef}, launchSpec.FallbackImageRefs...) {
+		if image, err = pullImage(ctx, cdClient, ref, launchSpec.Platform, token, httpClient, logger); err == nil {
+			break
 		}
-		return image, nil
+		logger.Println(err)
 	}
-	image, err := cdClient.Pull(ctx, launchSpec.ImageRef, containerd.WithPullUnpack)
 	if err != nil {
-		return nil, fmt.Errorf("cannot pull the image (no token, only works for a public image): %w", err)
+		return nil, err
+	}
+	ic, err := image.Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var imageConfig v1.Image
+	if err := readContentJSON(image.ContentStore())(ctx, ic, &imageConfig); err != nil {
+		return nil, fmt.Errorf("failed to read the image config: %v", err)
+	}
+	if err := checkImagePlatform(launchSpec, imageConfig); err != nil {
+		return nil, err
 	}
 	return image, nil
 }
@@ -558,4 +1236,10 @@ func (r *ContainerRunner) Close(ctx context.Context) {
 	// Exit gracefully:
 	// Delete container and close connection to attestation service.
 	r.container.Delete(ctx, containerd.WithSnapshotCleanup)
+	if r.workloadKey != nil {
+		os.RemoveAll(hostWorkloadKeyPath)
+	}
+	if r.launchSpec.WorkloadConfig != "" {
+		os.RemoveAll(hostWorkloadConfigPath)
+	}
 }
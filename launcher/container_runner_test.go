@@ -22,8 +22,12 @@ import (
 	"github.com/containerd/containerd/defaults"
 	"github.com/containerd/containerd/namespaces"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/launcher/agent"
 	"github.com/google/go-tpm-tools/launcher/spec"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
 	"golang.org/x/oauth2"
 	"google.golang.org/api/option"
 )
@@ -36,6 +40,8 @@ const (
 type fakeAttestationAgent struct {
 	measureEventFunc func(cel.Content) error
 	attestFunc       func(context.Context) ([]byte, error)
+	// attestForAudienceFunc is called by AttestForAudience, if set.
+	attestForAudienceFunc func(ctx context.Context, audience string, nonces []string) ([]byte, error)
 }
 
 func (f *fakeAttestationAgent) MeasureEvent(event cel.Content) error {
@@ -54,6 +60,14 @@ func (f *fakeAttestationAgent) Attest(ctx context.Context) ([]byte, error) {
 	return nil, fmt.Errorf("unimplemented")
 }
 
+func (f *fakeAttestationAgent) AttestForAudience(ctx context.Context, audience string, nonces []string) ([]byte, error) {
+	if f.attestForAudienceFunc != nil {
+		return f.attestForAudienceFunc(ctx, audience, nonces)
+	}
+
+	return nil, fmt.Errorf("unimplemented")
+}
+
 func createJWT(t *testing.T, ttl time.Duration) []byte {
 	return createJWTWithID(t, "test token", ttl)
 }
@@ -497,3 +511,171 @@ func TestInitImageDockerPublic(t *testing.T) {
 		}
 	}
 }
+
+func TestHardeningClaims(t *testing.T) {
+	launchSpec := spec.LaunchSpec{
+		HostNetwork:       false,
+		ReadOnlyRootfs:    true,
+		AddedCapabilities: []string{"CAP_NET_ADMIN"},
+		Mounts:            []cel.Mount{{Type: cel.BindMountType, Source: "/mnt/disks/data", Destination: "/data", ReadOnly: true}},
+		Devices:           []string{"/dev/nvidia0"},
+		User:              "1000:1000",
+		UserNamespace:     true,
+		SeccompProfile:    spec.RuntimeDefaultProfile,
+		AttestBeforeRun:   true,
+		ApprovalURL:       "https://approver.example.com",
+	}
+	want := []cel.CosTlv{
+		{EventType: cel.HostNetworkType, EventContent: []byte("false")},
+		{EventType: cel.ReadOnlyRootfsType, EventContent: []byte("true")},
+		{EventType: cel.UserNamespaceType, EventContent: []byte("true")},
+		{EventType: cel.SeccompProfileType, EventContent: []byte("runtime-default")},
+		{EventType: cel.AppArmorProfileType, EventContent: []byte("unconfined")},
+		{EventType: cel.UserType, EventContent: []byte("1000:1000")},
+		{EventType: cel.AddedCapabilityType, EventContent: []byte("CAP_NET_ADMIN")},
+		{EventType: cel.MountType, EventContent: []byte("type=bind,source=/mnt/disks/data,destination=/data,readonly=true")},
+		{EventType: cel.DeviceType, EventContent: []byte("/dev/nvidia0")},
+		{EventType: cel.AttestBeforeRunType, EventContent: []byte("true")},
+		{EventType: cel.ApprovalURLType, EventContent: []byte("https://approver.example.com")},
+	}
+
+	got, err := hardeningClaims(launchSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("hardeningClaims got %v, want %v", got, want)
+	}
+}
+
+func TestContainerMounts(t *testing.T) {
+	data := cel.Mount{Type: cel.BindMountType, Source: "/mnt/disks/data", Destination: "/data", ReadOnly: true}
+	tmp := cel.Mount{Type: cel.TmpfsMountType, Destination: "/tmp/"}
+	launchSpec := spec.LaunchSpec{
+		ReadOnlyRootfsTmpfs: spec.DefaultReadOnlyRootfsTmpfs,
+		Mounts:              []cel.Mount{data, tmp},
+	}
+	if got := containerMounts(launchSpec); !cmp.Equal(got, launchSpec.Mounts) {
+		t.Errorf("containerMounts got %v with a writable root filesystem, want %v", got, launchSpec.Mounts)
+	}
+
+	// The operator's tmpfs on /tmp is not overlaid.
+	launchSpec.ReadOnlyRootfs = true
+	want := []cel.Mount{data, tmp, {Type: cel.TmpfsMountType, Destination: "/var/run"}}
+	if got := containerMounts(launchSpec); !cmp.Equal(got, want) {
+		t.Errorf("containerMounts got %v, want %v", got, want)
+	}
+
+	claims, err := hardeningClaims(launchSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlay := cel.CosTlv{EventType: cel.MountType, EventContent: []byte("type=tmpfs,source=,destination=/var/run,readonly=false")}
+	if last := claims[len(claims)-1]; !cmp.Equal(last, overlay) {
+		t.Errorf("hardeningClaims got last claim %v, want the overlay %v", last, overlay)
+	}
+}
+
+func TestAppendLaunchSpecMounts(t *testing.T) {
+	specMounts := []cel.Mount{
+		{Type: cel.BindMountType, Source: "/mnt/disks/data", Destination: "/data", ReadOnly: true},
+		{Type: cel.TmpfsMountType, Destination: "/tmp"},
+		{Type: cel.GCSMountType, Source: "my-bucket", Destination: "/bucket", ReadOnly: true},
+	}
+	want := []specs.Mount{
+		{Destination: "/data", Type: "bind", Source: "/mnt/disks/data", Options: []string{"rbind", "ro"}},
+		{Destination: "/tmp", Type: "tmpfs", Source: "tmpfs", Options: []string{"nosuid", "nodev", "rw"}},
+		{Destination: "/bucket", Type: "bind", Source: "/tmp/container_launcher_gcs/mounts/2", Options: []string{"rbind", "ro"}},
+	}
+
+	if got := appendLaunchSpecMounts(nil, specMounts); !cmp.Equal(got, want) {
+		t.Errorf("appendLaunchSpecMounts got %v, want %v", got, want)
+	}
+}
+
+// Fake CEL signer returning an increasing number of records.
+type fakeCELSigner struct {
+	calls int
+}
+
+func (f *fakeCELSigner) SignCEL() ([]byte, agent.CELSignature, error) {
+	f.calls++
+	return bytes.Repeat([]byte{0xab}, f.calls), agent.CELSignature{CELDigest: []byte{byte(f.calls)}}, nil
+}
+
+func (f *fakeCELSigner) QuoteCEL(bind func([]byte, *tpmpb.PCRs) ([]byte, error)) (*tpmpb.Quote, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeCELSigner) AKCertificate() ([]byte, error) {
+	return nil, nil
+}
+
+func TestWriteSignedCEL(t *testing.T) {
+	dir := t.TempDir()
+	signer := &fakeCELSigner{}
+	for i := 0; i < 2; i++ {
+		if err := writeSignedCEL(signer, dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	encodedCEL, err := os.ReadFile(path.Join(dir, celFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encodedCEL, []byte{0xab, 0xab}) {
+		t.Errorf("got CEL %x, want the latest CEL", encodedCEL)
+	}
+
+	sigsFile, err := os.ReadFile(path.Join(dir, celSignaturesFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []agent.CELSignature
+	decoder := json.NewDecoder(bytes.NewReader(sigsFile))
+	for decoder.More() {
+		var sig agent.CELSignature
+		if err := decoder.Decode(&sig); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, sig)
+	}
+	want := []agent.CELSignature{{CELDigest: []byte{1}}, {CELDigest: []byte{2}}}
+	if !cmp.Equal(got, want) {
+		t.Errorf("got signatures %v, want %v", got, want)
+	}
+}
+
+func TestWriteCELCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	writeCheckpoint := writeCELCheckpoint(dir, log.Default())
+	writeCheckpoint([]byte{0xab, 0xab}, agent.Checkpoint{TokenDigest: []byte{1}})
+	// A checkpoint of an older CEL is recorded, but keeps the newer CEL.
+	writeCheckpoint([]byte{0xab}, agent.Checkpoint{TokenDigest: []byte{2}})
+
+	encodedCEL, err := os.ReadFile(path.Join(dir, celFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encodedCEL, []byte{0xab, 0xab}) {
+		t.Errorf("got CEL %x, want the newest CEL", encodedCEL)
+	}
+
+	checkpointsFile, err := os.ReadFile(path.Join(dir, celCheckpointsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got [][]byte
+	decoder := json.NewDecoder(bytes.NewReader(checkpointsFile))
+	for decoder.More() {
+		var checkpoint agent.Checkpoint
+		if err := decoder.Decode(&checkpoint); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, checkpoint.TokenDigest)
+	}
+	if want := [][]byte{{1}, {2}}; !cmp.Equal(got, want) {
+		t.Errorf("got checkpoints of tokens %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,272 @@
+package launcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/containerd/containerd/platforms"
+	"github.com/containerd/containerd/remotes"
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/launcher/agent"
+	"github.com/google/go-tpm-tools/launcher/spec"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/oauth2"
+)
+
+// maxManifestSize bounds the size of image manifests and configs read during
+// a dry run.
+const maxManifestSize = 4 << 20
+
+var cosTypeNames = map[cel.CosType]string{
+	cel.ImageRefType:             "ImageRef",
+	cel.ImageDigestType:          "ImageDigest",
+	cel.RestartPolicyType:        "RestartPolicy",
+	cel.ImageIDType:              "ImageID",
+	cel.ArgType:                  "Arg",
+	cel.EnvVarType:               "EnvVar",
+	cel.OverrideArgType:          "OverrideArg",
+	cel.OverrideEnvType:          "OverrideEnv",
+	cel.LaunchSeparatorType:      "LaunchSeparator",
+	cel.HostNetworkType:          "HostNetwork",
+	cel.ReadOnlyRootfsType:       "ReadOnlyRootfs",
+	cel.AddedCapabilityType:      "AddedCapability",
+	cel.MountType:                "Mount",
+	cel.DeviceType:               "Device",
+	cel.WorkloadKeyType:          "WorkloadKey",
+	cel.ImageLayerType:           "ImageLayer",
+	cel.WorkloadConfigType:       "WorkloadConfig",
+	cel.UserType:                 "User",
+	cel.UserNamespaceType:        "UserNamespace",
+	cel.SeccompProfileType:       "SeccompProfile",
+	cel.AppArmorProfileType:      "AppArmorProfile",
+	cel.LaunchPolicyDocumentType: "LaunchPolicyDocument",
+	cel.LaunchPolicySignerType:   "LaunchPolicySigner",
+	cel.BootTimeType:             "BootTime",
+	cel.ClockSourceType:          "ClockSource",
+	cel.WorkloadOutputType:       "WorkloadOutput",
+	cel.HashAlgorithmsType:       "HashAlgorithms",
+	cel.ImagePlatformType:        "ImagePlatform",
+	cel.ImageManifestDigestType:  "ImageManifestDigest",
+	cel.SchemaVersionType:        "SchemaVersion",
+	cel.KernelModuleType:         "KernelModule",
+	cel.CDIDeviceType:            "CDIDevice",
+	cel.WorkloadNameType:         "WorkloadName",
+	cel.WorkloadBinaryType:       "WorkloadBinary",
+	cel.LauncherType:             "Launcher",
+	cel.KernelLogType:            "KernelLog",
+	cel.AttestBeforeRunType:      "AttestBeforeRun",
+	cel.ApprovalURLType:          "ApprovalURL",
+}
+
+// DryRunResult contains the decisions the launcher would make for a
+// LaunchSpec, without pulling or starting the workload.
+type DryRunResult struct {
+	LaunchSpec   spec.LaunchSpec
+	ImageDigest  string
+	ImageID      string
+	ImageLabels  map[string]string
+	LaunchPolicy spec.LaunchPolicy
+	// PolicyErr is the result of verifying the LaunchSpec against the
+	// LaunchPolicy, nil if the LaunchSpec is allowed.
+	PolicyErr error
+	// Args and Env are the process arguments and environment the workload
+	// would be started with.
+	Args []string
+	Env  []string
+	// Events are the container claims that would be measured into the COS
+	// event log, in order.
+	Events []cel.CosTlv
+}
+
+// DryRun resolves the image in the LaunchSpec from its registry (only fetching
+// the manifest and config), evaluates the image's launch policy, and computes
+// the container claims that would be measured. Policy violations are reported
+// in the result rather than returned as an error.
+func DryRun(ctx context.Context, token oauth2.Token, launchSpec spec.LaunchSpec) (*DryRunResult, error) {
+	var accessToken string
+	if token.Valid() {
+		accessToken = token.AccessToken
+	}
+	if _, err := agent.LookupCollectors(launchSpec.EvidenceCollectors); err != nil {
+		return nil, err
+	}
+	resolver := Resolver(accessToken, newEgressClient(launchSpec))
+
+	// Like initImage, use the first image which can be resolved.
+	var name string
+	var desc v1.Descriptor
+	var err error
+	for _, ref := range append([]string{launchSpec.ImageRef}, launchSpec.FallbackImageRefs...) {
+		if name, desc, err = resolver.Resolve(ctx, ref); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve the image: %w", err)
+	}
+	fetcher, err := resolver.Fetcher(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	matcher, err := platformMatcher(launchSpec)
+	if err != nil {
+		return nil, err
+	}
+	manifestDesc, manifest, err := fetchManifest(ctx, fetcher, desc, matcher)
+	if err != nil {
+		return nil, err
+	}
+	configDesc := manifest.Config
+	var imageConfig v1.Image
+	if err := fetchJSON(ctx, fetcher, configDesc, &imageConfig); err != nil {
+		return nil, fmt.Errorf("cannot fetch the image config: %w", err)
+	}
+	if err := checkImagePlatform(launchSpec, imageConfig); err != nil {
+		return nil, err
+	}
+
+	result := &DryRunResult{
+		LaunchSpec:  launchSpec,
+		ImageDigest: desc.Digest.String(),
+		ImageID:     configDesc.Digest.String(),
+		ImageLabels: imageConfig.Config.Labels,
+	}
+	result.LaunchPolicy, err = spec.GetLaunchPolicy(result.ImageLabels)
+	if err != nil {
+		return nil, err
+	}
+	result.PolicyErr = result.LaunchPolicy.Verify(launchSpec)
+	launchSpec = result.LaunchPolicy.Apply(launchSpec)
+
+	overrideEnvs, err := formatEnvVars(launchSpec.Envs)
+	if err != nil {
+		return nil, err
+	}
+	// This mirrors oci.WithImageConfigArgs and oci.WithEnv in NewRunner.
+	result.Args = append(result.Args, imageConfig.Config.Entrypoint...)
+	if len(launchSpec.Cmd) > 0 {
+		result.Args = append(result.Args, launchSpec.Cmd...)
+	} else {
+		result.Args = append(result.Args, imageConfig.Config.Cmd...)
+	}
+	result.Env = append(result.Env, imageConfig.Config.Env...)
+	result.Env = append(result.Env, overrideEnvs...)
+	if hostname, err := os.Hostname(); err == nil {
+		result.Env = append(result.Env, "HOSTNAME="+hostname)
+	}
+
+	launcher, err := launcherEvent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure the launcher: %w", err)
+	}
+	result.Events = []cel.CosTlv{
+		launcher,
+		{EventType: cel.ImageRefType, EventContent: []byte(name)},
+		{EventType: cel.ImageDigestType, EventContent: []byte(result.ImageDigest)},
+		{EventType: cel.RestartPolicyType, EventContent: []byte(launchSpec.RestartPolicy)},
+		{EventType: cel.ImageIDType, EventContent: []byte(result.ImageID)},
+	}
+	result.Events = append(result.Events, imagePlatformClaims(launchSpec, desc, manifestDesc, imageConfig)...)
+	for _, layer := range manifest.Layers {
+		result.Events = append(result.Events, cel.CosTlv{EventType: cel.ImageLayerType, EventContent: []byte(layer.Digest)})
+	}
+	for _, arg := range result.Args {
+		result.Events = append(result.Events, cel.CosTlv{EventType: cel.ArgType, EventContent: []byte(arg)})
+	}
+	for _, env := range result.Env {
+		result.Events = append(result.Events, cel.CosTlv{EventType: cel.EnvVarType, EventContent: []byte(env)})
+	}
+	for _, env := range overrideEnvs {
+		result.Events = append(result.Events, cel.CosTlv{EventType: cel.OverrideEnvType, EventContent: []byte(env)})
+	}
+	for _, arg := range launchSpec.Cmd {
+		result.Events = append(result.Events, cel.CosTlv{EventType: cel.OverrideArgType, EventContent: []byte(arg)})
+	}
+	hardening, err := hardeningClaims(launchSpec)
+	if err != nil {
+		return nil, err
+	}
+	result.Events = append(result.Events, hardening...)
+	cdiDevices, err := resolveCDIDevices(cdiSpecDirs, launchSpec.CDIDevices)
+	if err != nil {
+		return nil, err
+	}
+	cdiEvents, err := cdiDeviceClaims(cdiDevices)
+	if err != nil {
+		return nil, err
+	}
+	result.Events = append(result.Events, cdiEvents...)
+	// The workload key is generated at every boot, so its event cannot be
+	// predicted.
+	if launchSpec.WorkloadConfig != "" {
+		result.Events = append(result.Events, cel.CosTlv{EventType: cel.WorkloadConfigType, EventContent: []byte(workloadConfigDigest(launchSpec.WorkloadConfig))})
+	}
+	if launchSpec.LaunchPolicyURL != "" {
+		doc, err := fetchPolicyDocument(ctx, newEgressClient(launchSpec), launchSpec)
+		if err != nil {
+			return nil, err
+		}
+		if result.PolicyErr == nil {
+			result.PolicyErr = verifyPolicyDocument(doc, name, result.ImageDigest, launchSpec)
+		}
+		result.Events = append(result.Events, policyDocumentEvents(doc)...)
+	}
+	result.Events = append(result.Events, cel.CosTlv{EventType: cel.LaunchSeparatorType})
+
+	return result, nil
+}
+
+// Print writes a human readable description of the dry run result to w.
+func (r *DryRunResult) Print(w io.Writer) {
+	fmt.Fprintf(w, "Launch Spec                : %+v\n", r.LaunchSpec)
+	fmt.Fprintf(w, "Image Digest               : %v\n", r.ImageDigest)
+	fmt.Fprintf(w, "Image ID                   : %v\n", r.ImageID)
+	fmt.Fprintf(w, "Image Labels               : %v\n", r.ImageLabels)
+	fmt.Fprintf(w, "Launch Policy              : %+v\n", r.LaunchPolicy)
+	if r.PolicyErr != nil {
+		fmt.Fprintf(w, "Launch Policy Verification : FAILED: %v\n", r.PolicyErr)
+	} else {
+		fmt.Fprintf(w, "Launch Policy Verification : PASSED\n")
+	}
+	fmt.Fprintf(w, "Workload Args              : %q\n", r.Args)
+	fmt.Fprintf(w, "Workload Env               : %q\n", r.Env)
+	fmt.Fprintf(w, "Measured Events (PCR %d):\n", cel.CosEventPCR)
+	for i, event := range r.Events {
+		fmt.Fprintf(w, "  %2d: %-15s %q\n", i, cosTypeNames[event.EventType], event.EventContent)
+	}
+}
+
+// fetchManifest follows an image index (choosing the manifest for the
+// platform) to the image manifest, and returns the manifest and its
+// descriptor.
+func fetchManifest(ctx context.Context, fetcher remotes.Fetcher, desc v1.Descriptor, matcher platforms.MatchComparer) (v1.Descriptor, v1.Manifest, error) {
+	readJSON := func(ctx context.Context, desc v1.Descriptor, v interface{}) error {
+		return fetchJSON(ctx, fetcher, desc, v)
+	}
+	return resolveManifest(ctx, readJSON, desc, matcher)
+}
+
+func fetchJSON(ctx context.Context, fetcher remotes.Fetcher, desc v1.Descriptor, v interface{}) error {
+	if desc.Size > maxManifestSize {
+		return fmt.Errorf("%s is too large (%d bytes)", desc.Digest, desc.Size)
+	}
+	rc, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxManifestSize))
+	if err != nil {
+		return err
+	}
+	if err := desc.Digest.Validate(); err != nil {
+		return err
+	}
+	if desc.Digest.Algorithm().FromBytes(data) != desc.Digest {
+		return fmt.Errorf("digest mismatch for %s", desc.Digest)
+	}
+	return json.Unmarshal(data, v)
+}
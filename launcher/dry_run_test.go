@@ -0,0 +1,79 @@
+package launcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/containerd/containerd/platforms"
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Fake remotes.Fetcher serving blobs by digest.
+type fakeFetcher map[digest.Digest][]byte
+
+func (f fakeFetcher) Fetch(ctx context.Context, desc v1.Descriptor) (io.ReadCloser, error) {
+	blob, ok := f[desc.Digest]
+	if !ok {
+		return nil, fmt.Errorf("blob %s not found", desc.Digest)
+	}
+	return io.NopCloser(bytes.NewReader(blob)), nil
+}
+
+func (f fakeFetcher) add(t *testing.T, mediaType string, v interface{}) v1.Descriptor {
+	t.Helper()
+	blob, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc := v1.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(blob), Size: int64(len(blob))}
+	f[desc.Digest] = blob
+	return desc
+}
+
+func TestFetchManifest(t *testing.T) {
+	fetcher := fakeFetcher{}
+	configDesc := fetcher.add(t, v1.MediaTypeImageConfig, v1.Image{})
+	layers := []v1.Descriptor{
+		{MediaType: v1.MediaTypeImageLayerGzip, Digest: "sha256:1111111111111111111111111111111111111111111111111111111111111111"},
+		{MediaType: v1.MediaTypeImageLayerGzip, Digest: "sha256:2222222222222222222222222222222222222222222222222222222222222222"},
+	}
+	manifestDesc := fetcher.add(t, v1.MediaTypeImageManifest, v1.Manifest{Config: configDesc, Layers: layers})
+	otherPlatform := v1.Platform{OS: "plan9", Architecture: "mips"}
+	defaultPlatform := platforms.DefaultSpec()
+	indexDesc := fetcher.add(t, v1.MediaTypeImageIndex, v1.Index{Manifests: []v1.Descriptor{
+		{MediaType: v1.MediaTypeImageManifest, Digest: "sha256:0000000000000000000000000000000000000000000000000000000000000000", Platform: &otherPlatform},
+		{MediaType: manifestDesc.MediaType, Digest: manifestDesc.Digest, Size: manifestDesc.Size, Platform: &defaultPlatform},
+	}})
+
+	for _, desc := range []v1.Descriptor{manifestDesc, indexDesc} {
+		gotDesc, got, err := fetchManifest(context.Background(), fetcher, desc, platforms.Default())
+		if err != nil {
+			t.Fatalf("fetchManifest(%v) failed: %v", desc.MediaType, err)
+		}
+		if gotDesc.Digest != manifestDesc.Digest {
+			t.Errorf("fetchManifest(%v) returned manifest %v, want %v", desc.MediaType, gotDesc.Digest, manifestDesc.Digest)
+		}
+		if got.Config.Digest != configDesc.Digest {
+			t.Errorf("fetchManifest(%v) has config %v, want %v", desc.MediaType, got.Config.Digest, configDesc.Digest)
+		}
+		if len(got.Layers) != len(layers) || got.Layers[0].Digest != layers[0].Digest || got.Layers[1].Digest != layers[1].Digest {
+			t.Errorf("fetchManifest(%v) has layers %v, want %v", desc.MediaType, got.Layers, layers)
+		}
+	}
+}
+
+func TestFetchJSONDigestMismatch(t *testing.T) {
+	fetcher := fakeFetcher{}
+	desc := fetcher.add(t, v1.MediaTypeImageConfig, v1.Image{})
+	fetcher[desc.Digest] = []byte(`{"tampered":true}`)
+
+	var config v1.Image
+	if err := fetchJSON(context.Background(), fetcher, desc, &config); err == nil {
+		t.Error("fetchJSON should fail when the blob does not match its digest")
+	}
+}
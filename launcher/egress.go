@@ -0,0 +1,97 @@
+package launcher
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/google/go-tpm-tools/launcher/spec"
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// egressCADir is the directory of the CA bundle for the processes started
+	// by the launcher, like gcsfuse, passed as SSL_CERT_DIR.
+	egressCADir  = "/tmp/container_launcher_ca/"
+	egressCAFile = "ca-bundle.pem"
+)
+
+// metadataServerHosts are never proxied, as the metadata server is only
+// reachable from the VM.
+var metadataServerHosts = []string{"metadata.google.internal", "169.254.169.254"}
+
+// newEgressClient returns the HTTP client for all the egress of the launcher,
+// using the proxies and the CA bundle of the LaunchSpec.
+func newEgressClient(launchSpec spec.LaunchSpec) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if launchSpec.HTTPProxy != "" || launchSpec.HTTPSProxy != "" {
+		proxy := egressProxy(launchSpec)
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxy(req.URL)
+		}
+	}
+	if launchSpec.CABundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pool.AppendCertsFromPEM([]byte(launchSpec.CABundle))
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	return &http.Client{Transport: transport}
+}
+
+// egressProxy returns the proxy function of the LaunchSpec proxies.
+func egressProxy(launchSpec spec.LaunchSpec) func(*url.URL) (*url.URL, error) {
+	return (&httpproxy.Config{
+		HTTPProxy:  launchSpec.HTTPProxy,
+		HTTPSProxy: launchSpec.HTTPSProxy,
+		NoProxy:    egressNoProxy(launchSpec),
+	}).ProxyFunc()
+}
+
+func egressNoProxy(launchSpec spec.LaunchSpec) string {
+	noProxy := metadataServerHosts
+	if launchSpec.NoProxy != "" {
+		noProxy = append([]string{launchSpec.NoProxy}, noProxy...)
+	}
+	return strings.Join(noProxy, ",")
+}
+
+// egressContext returns a context whose oauth2 and Google API clients use
+// the egress client as their base transport.
+func egressContext(ctx context.Context, client *http.Client) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, client)
+}
+
+// egressEnv returns the environment variables configuring the proxies and the
+// CA bundle of the LaunchSpec for the processes started by the launcher. The
+// CA bundle is written to egressCADir.
+func egressEnv(launchSpec spec.LaunchSpec) ([]string, error) {
+	var env []string
+	if launchSpec.HTTPProxy != "" || launchSpec.HTTPSProxy != "" {
+		env = append(env,
+			"HTTP_PROXY="+launchSpec.HTTPProxy,
+			"HTTPS_PROXY="+launchSpec.HTTPSProxy,
+			"NO_PROXY="+egressNoProxy(launchSpec),
+		)
+	}
+	if launchSpec.CABundle != "" {
+		if err := os.MkdirAll(egressCADir, 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path.Join(egressCADir, egressCAFile), []byte(launchSpec.CABundle), 0644); err != nil {
+			return nil, err
+		}
+		// SSL_CERT_DIR replaces the system certificate directories, but the
+		// system certificate files are still trusted.
+		env = append(env, "SSL_CERT_DIR="+egressCADir)
+	}
+	return env, nil
+}
@@ -0,0 +1,86 @@
+package launcher
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-tpm-tools/launcher/spec"
+)
+
+func TestEgressProxy(t *testing.T) {
+	launchSpec := spec.LaunchSpec{
+		HTTPProxy:  "http://proxy.internal:3128",
+		HTTPSProxy: "http://proxy.internal:3129",
+		NoProxy:    "registry.internal",
+	}
+	proxy := egressProxy(launchSpec)
+	testCases := []struct {
+		url  string
+		want string
+	}{
+		{"https://confidentialcomputing.googleapis.com/v1", "http://proxy.internal:3129"},
+		{"http://example.com", "http://proxy.internal:3128"},
+		{"https://registry.internal/v2/", ""},
+		{"http://metadata.google.internal/computeMetadata/v1/", ""},
+		{"http://169.254.169.254/computeMetadata/v1/", ""},
+	}
+	for _, tc := range testCases {
+		u, err := url.Parse(tc.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := proxy(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (got == nil && tc.want != "") || (got != nil && got.String() != tc.want) {
+			t.Errorf("proxy for %s is %v, want %q", tc.url, got, tc.want)
+		}
+	}
+}
+
+func TestEgressClientCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	if _, err := newEgressClient(spec.LaunchSpec{}).Get(server.URL); err == nil {
+		t.Error("request to a server with an untrusted certificate succeeded")
+	}
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	resp, err := newEgressClient(spec.LaunchSpec{CABundle: string(caBundle)}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("request with the CA bundle failed: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestEgressEnv(t *testing.T) {
+	env, err := egressEnv(spec.LaunchSpec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(env) != 0 {
+		t.Errorf("egressEnv() = %v for a LaunchSpec without proxies or CA bundle, want none", env)
+	}
+
+	env, err = egressEnv(spec.LaunchSpec{HTTPSProxy: "http://proxy.internal:3129", NoProxy: "registry.internal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"HTTP_PROXY=",
+		"HTTPS_PROXY=http://proxy.internal:3129",
+		"NO_PROXY=registry.internal,metadata.google.internal,169.254.169.254",
+	}
+	if len(env) != len(want) {
+		t.Fatalf("egressEnv() = %v, want %v", env, want)
+	}
+	for i := range want {
+		if env[i] != want[i] {
+			t.Errorf("egressEnv()[%d] = %q, want %q", i, env[i], want[i])
+		}
+	}
+}
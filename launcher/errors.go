@@ -12,6 +12,23 @@ type WorkloadError struct {
 	ReturnCode uint32
 }
 
+// PolicyError means the launch spec violates the launch policy of the image,
+// or the launch policy document.
+type PolicyError struct {
+	Err error
+}
+
+// ImagePullError means none of the images of the launch spec could be pulled.
+type ImagePullError struct {
+	Err error
+}
+
+// AttestationError means the workload could not be attested: its claims could
+// not be measured, or no attestation token could be fetched.
+type AttestationError struct {
+	Err error
+}
+
 func (e *RetryableError) Error() string {
 	return fmt.Sprintf("failed with retryable error: %v", e.Err.Error())
 }
@@ -19,3 +36,27 @@ func (e *RetryableError) Error() string {
 func (e *WorkloadError) Error() string {
 	return "workload finished with a non-zero return code"
 }
+
+func (e *PolicyError) Error() string {
+	return fmt.Sprintf("launch policy violation: %v", e.Err)
+}
+
+func (e *PolicyError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ImagePullError) Error() string {
+	return fmt.Sprintf("failed to pull the image: %v", e.Err)
+}
+
+func (e *ImagePullError) Unwrap() error {
+	return e.Err
+}
+
+func (e *AttestationError) Error() string {
+	return fmt.Sprintf("failed to attest the workload: %v", e.Err)
+}
+
+func (e *AttestationError) Unwrap() error {
+	return e.Err
+}
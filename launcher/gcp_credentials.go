@@ -0,0 +1,80 @@
+package launcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/google/go-tpm-tools/launcher/spec"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	// gcpCredentialsFile is the Application Default Credentials file of the
+	// workload, next to the tokens. It exchanges the attestation token of the
+	// container with the GCPWorkloadIdentityProvider of the LaunchSpec.
+	gcpCredentialsFile = "gcp_credentials.json"
+	// gcpAccessTokenFile is the GCP access token (a JSON gcpAccessToken)
+	// exchanged by the launcher for the last attestation token, scoped to
+	// the GCPScopes of the LaunchSpec, for the clients without ADC support.
+	gcpAccessTokenFile = "gcp_access_token"
+)
+
+// gcpAccessToken is the format of gcpAccessTokenFile.
+type gcpAccessToken struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	Expiry      time.Time `json:"expiry"`
+}
+
+// gcpCredentialsEnv returns the environment variable pointing the Application
+// Default Credentials of the workload to gcpCredentialsFile, if the LaunchSpec
+// has a GCPWorkloadIdentityProvider.
+func gcpCredentialsEnv(launchSpec spec.LaunchSpec) []string {
+	if launchSpec.GCPWorkloadIdentityProvider == "" {
+		return nil
+	}
+	return []string{"GOOGLE_APPLICATION_CREDENTIALS=" + path.Join(containerTokenMountPath, gcpCredentialsFile)}
+}
+
+// writeGCPCredentials writes gcpCredentialsFile to dir. Its subject token is
+// the attestation token at its path in the container, so the client
+// libraries of the workload exchange the current token whenever they refresh
+// their credentials.
+func writeGCPCredentials(launchSpec spec.LaunchSpec, dir string) error {
+	creds, err := workloadIdentityCredentials(launchSpec.GCPWorkloadIdentityProvider, launchSpec.GCPServiceAccount,
+		path.Join(containerTokenMountPath, attestationVerifierTokenFile))
+	if err != nil {
+		return err
+	}
+	return writeArtifact(dir, gcpCredentialsFile, creds)
+}
+
+// exchangeGCPToken exchanges the attestation token in dir with the
+// GCPWorkloadIdentityProvider of the LaunchSpec, through the STS and the
+// impersonation of the GCPServiceAccount if set, and writes the access token
+// to gcpAccessTokenFile in dir. A provider which does not accept the
+// attestation token fails the exchange.
+func exchangeGCPToken(ctx context.Context, client *http.Client, launchSpec spec.LaunchSpec, dir string) error {
+	creds, err := workloadIdentityCredentials(launchSpec.GCPWorkloadIdentityProvider, launchSpec.GCPServiceAccount,
+		path.Join(dir, attestationVerifierTokenFile))
+	if err != nil {
+		return err
+	}
+	credentials, err := google.CredentialsFromJSON(egressContext(ctx, client), creds, launchSpec.GCPScopes...)
+	if err != nil {
+		return err
+	}
+	token, err := credentials.TokenSource.Token()
+	if err != nil {
+		return fmt.Errorf("failed to exchange the attestation token with %s: %v", launchSpec.GCPWorkloadIdentityProvider, err)
+	}
+	data, err := json.Marshal(gcpAccessToken{AccessToken: token.AccessToken, TokenType: token.Type(), Expiry: token.Expiry})
+	if err != nil {
+		return err
+	}
+	return writeArtifact(dir, gcpAccessTokenFile, data)
+}
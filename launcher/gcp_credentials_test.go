@@ -0,0 +1,139 @@
+package launcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/launcher/spec"
+)
+
+// roundTripFunc fakes the Google APIs in the transport of an http.Client, as
+// the token exchange only accepts their URLs.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func jsonResponse(t *testing.T, v interface{}) *http.Response {
+	t.Helper()
+	body, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}
+}
+
+func TestExchangeGCPToken(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(path.Join(dir, attestationVerifierTokenFile), []byte("attestation-token"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	launchSpec := spec.LaunchSpec{
+		GCPWorkloadIdentityProvider: "projects/123/locations/global/workloadIdentityPools/pool/providers/attestation-verifier",
+		GCPServiceAccount:           "workload@project.iam.gserviceaccount.com",
+		GCPScopes:                   []string{"https://www.googleapis.com/auth/cloudkms"},
+	}
+	expiry := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	var impersonationScopes []string
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Host {
+		case "sts.googleapis.com":
+			if err := req.ParseForm(); err != nil {
+				t.Fatal(err)
+			}
+			if got := req.PostForm.Get("subject_token"); got != "attestation-token" {
+				t.Errorf("got subject token %q, want the attestation token", got)
+			}
+			if got, want := req.PostForm.Get("audience"), "//iam.googleapis.com/"+launchSpec.GCPWorkloadIdentityProvider; got != want {
+				t.Errorf("got audience %q, want %q", got, want)
+			}
+			return jsonResponse(t, map[string]interface{}{
+				"access_token":      "federated-token",
+				"issued_token_type": "urn:ietf:params:oauth:token-type:access_token",
+				"token_type":        "Bearer",
+				"expires_in":        3600,
+			}), nil
+		case "iamcredentials.googleapis.com":
+			if got := req.Header.Get("Authorization"); got != "Bearer federated-token" {
+				t.Errorf("got impersonation authorization %q, want the federated token", got)
+			}
+			var body struct {
+				Scope []string `json:"scope"`
+			}
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			impersonationScopes = body.Scope
+			return jsonResponse(t, map[string]interface{}{
+				"accessToken": "service-account-token",
+				"expireTime":  expiry.Format(time.RFC3339),
+			}), nil
+		}
+		t.Errorf("unexpected request to %s", req.URL)
+		return nil, http.ErrNotSupported
+	})}
+
+	if err := exchangeGCPToken(context.Background(), client, launchSpec, dir); err != nil {
+		t.Fatalf("exchangeGCPToken() failed: %v", err)
+	}
+	if diff := cmp.Diff(launchSpec.GCPScopes, impersonationScopes); diff != "" {
+		t.Errorf("unexpected impersonation scopes (-want +got):\n%s", diff)
+	}
+	data, err := os.ReadFile(path.Join(dir, gcpAccessTokenFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got gcpAccessToken
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := gcpAccessToken{AccessToken: "service-account-token", TokenType: "Bearer", Expiry: expiry}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected access token (-want +got):\n%s", diff)
+	}
+
+	rejecting := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusUnauthorized, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+	})}
+	if err := exchangeGCPToken(context.Background(), rejecting, launchSpec, dir); err == nil {
+		t.Error("exchangeGCPToken() succeeded with a provider rejecting the attestation token")
+	}
+}
+
+func TestWriteGCPCredentials(t *testing.T) {
+	dir := t.TempDir()
+	launchSpec := spec.LaunchSpec{GCPWorkloadIdentityProvider: "projects/123/locations/global/workloadIdentityPools/pool/providers/attestation-verifier"}
+	if err := writeGCPCredentials(launchSpec, dir); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path.Join(dir, gcpCredentialsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got externalAccount
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := path.Join(containerTokenMountPath, attestationVerifierTokenFile); got.CredentialSource.File != want {
+		t.Errorf("got subject token file %q, want the token in the container %q", got.CredentialSource.File, want)
+	}
+	if diff := cmp.Diff([]string{"GOOGLE_APPLICATION_CREDENTIALS=/run/container_launcher/gcp_credentials.json"}, gcpCredentialsEnv(launchSpec)); diff != "" {
+		t.Errorf("unexpected env (-want +got):\n%s", diff)
+	}
+	if env := gcpCredentialsEnv(spec.LaunchSpec{}); env != nil {
+		t.Errorf("got env %v without a provider, want none", env)
+	}
+}
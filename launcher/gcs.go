@@ -0,0 +1,144 @@
+package launcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/launcher/spec"
+)
+
+const (
+	// hostGCSPath is the directory in the host where gcs mounts are mounted
+	// with gcsfuse, before being bind mounted into the container.
+	hostGCSPath = "/tmp/container_launcher_gcs/"
+	// gcsCredentialsFile configures gcsfuse to exchange the attestation token
+	// for Google credentials with Workload Identity Federation.
+	gcsCredentialsFile = "credentials.json"
+	gcsfuseBinary      = "gcsfuse"
+	fusermountBinary   = "fusermount"
+)
+
+// externalAccount is the credential configuration file format of Workload
+// Identity Federation, see https://google.aip.dev/auth/4117.
+type externalAccount struct {
+	Type                           string           `json:"type"`
+	Audience                       string           `json:"audience"`
+	SubjectTokenType               string           `json:"subject_token_type"`
+	TokenURL                       string           `json:"token_url"`
+	CredentialSource               credentialSource `json:"credential_source"`
+	ServiceAccountImpersonationURL string           `json:"service_account_impersonation_url,omitempty"`
+}
+
+type credentialSource struct {
+	File string `json:"file"`
+}
+
+// gcsCredentials returns the credential configuration which uses the
+// attestation token in tokenPath as the subject token. The token is re-read
+// whenever the credentials are refreshed, so the token refresher keeps the
+// mounts working.
+func gcsCredentials(launchSpec spec.LaunchSpec, tokenPath string) ([]byte, error) {
+	return workloadIdentityCredentials(launchSpec.GCSWorkloadIdentityProvider, launchSpec.GCSServiceAccount, tokenPath)
+}
+
+// workloadIdentityCredentials returns the credential configuration exchanging
+// the attestation token in tokenPath with the Workload Identity Federation
+// provider, impersonating serviceAccount if set.
+func workloadIdentityCredentials(provider, serviceAccount, tokenPath string) ([]byte, error) {
+	account := externalAccount{
+		Type:             "external_account",
+		Audience:         "//iam.googleapis.com/" + provider,
+		SubjectTokenType: "urn:ietf:params:oauth:token-type:jwt",
+		TokenURL:         "https://sts.googleapis.com/v1/token",
+		CredentialSource: credentialSource{File: tokenPath},
+	}
+	if serviceAccount != "" {
+		account.ServiceAccountImpersonationURL = fmt.Sprintf(
+			"https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/%s:generateAccessToken",
+			serviceAccount)
+	}
+	return json.MarshalIndent(account, "", "  ")
+}
+
+// gcsMountPoint returns the host directory of the i-th mount in the
+// LaunchSpec, if it is a gcs mount.
+func gcsMountPoint(i int) string {
+	return path.Join(hostGCSPath, "mounts", strconv.Itoa(i))
+}
+
+// gcsfuseArgs returns the arguments to mount the bucket read-only at
+// mountPoint, accessible to the container user.
+func gcsfuseArgs(bucket, mountPoint, keyFile string) []string {
+	return []string{
+		"--key-file", keyFile,
+		"--implicit-dirs",
+		"-o", "ro",
+		"-o", "allow_other",
+		bucket, mountPoint,
+	}
+}
+
+// mountGCSBuckets mounts the buckets of the gcs mounts in the LaunchSpec. It
+// must only be called once the attestation token has been written, as it is
+// used to access the buckets.
+func (r *ContainerRunner) mountGCSBuckets(ctx context.Context) error {
+	var keyFile string
+	var env []string
+	for i, m := range r.launchSpec.Mounts {
+		if m.Type != cel.GCSMountType {
+			continue
+		}
+		if keyFile == "" {
+			egress, err := egressEnv(r.launchSpec)
+			if err != nil {
+				return err
+			}
+			env = append(os.Environ(), egress...)
+
+			creds, err := gcsCredentials(r.launchSpec, path.Join(hostTokenPath, attestationVerifierTokenFile))
+			if err != nil {
+				return err
+			}
+			keyFile = path.Join(hostGCSPath, gcsCredentialsFile)
+			if err := os.MkdirAll(hostGCSPath, 0744); err != nil {
+				return err
+			}
+			if err := os.WriteFile(keyFile, creds, 0600); err != nil {
+				return err
+			}
+		}
+
+		mountPoint := gcsMountPoint(i)
+		if err := os.MkdirAll(mountPoint, 0755); err != nil {
+			return err
+		}
+		// gcsfuse returns once the bucket is mounted, and keeps running in
+		// the background.
+		cmd := exec.CommandContext(ctx, gcsfuseBinary, gcsfuseArgs(m.Source, mountPoint, keyFile)...)
+		cmd.Env = env
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to mount bucket %s: %v: %s", m.Source, err, out)
+		}
+		r.logger.Printf("mounted bucket %s at %s\n", m.Source, m.Destination)
+	}
+	return nil
+}
+
+// unmountGCSBuckets unmounts the buckets mounted by mountGCSBuckets.
+func (r *ContainerRunner) unmountGCSBuckets(ctx context.Context) {
+	for i, m := range r.launchSpec.Mounts {
+		if m.Type != cel.GCSMountType {
+			continue
+		}
+		if out, err := exec.CommandContext(ctx, fusermountBinary, "-u", gcsMountPoint(i)).CombinedOutput(); err != nil {
+			r.logger.Printf("failed to unmount bucket %s: %v: %s", m.Source, err, out)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package launcher
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/launcher/spec"
+)
+
+func TestGCSCredentials(t *testing.T) {
+	launchSpec := spec.LaunchSpec{
+		GCSWorkloadIdentityProvider: "projects/123/locations/global/workloadIdentityPools/pool/providers/attestation-verifier",
+		GCSServiceAccount:           "reader@project.iam.gserviceaccount.com",
+	}
+	creds, err := gcsCredentials(launchSpec, "/tmp/container_launcher/attestation_verifier_claims_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(creds, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"type":               "external_account",
+		"audience":           "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/pool/providers/attestation-verifier",
+		"subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+		"token_url":          "https://sts.googleapis.com/v1/token",
+		"credential_source": map[string]interface{}{
+			"file": "/tmp/container_launcher/attestation_verifier_claims_token",
+		},
+		"service_account_impersonation_url": "https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/reader@project.iam.gserviceaccount.com:generateAccessToken",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("gcsCredentials mismatch (-want +got):\n%s", diff)
+	}
+
+	launchSpec.GCSServiceAccount = ""
+	if creds, err = gcsCredentials(launchSpec, "/token"); err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	if err := json.Unmarshal(creds, &got); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["service_account_impersonation_url"]; ok {
+		t.Error("gcsCredentials without a service account set service_account_impersonation_url")
+	}
+}
@@ -6,7 +6,8 @@ then
 	shutdown --reboot +2
 fi
 
-if [[ $EXIT_STATUS -eq 0 ]] || [[ $EXIT_STATUS -eq 1 ]] || [[ $EXIT_STATUS -eq 2 ]]
+# 5 to 8 are failures with a known cause, see the launcher's exit codes
+if [[ $EXIT_STATUS -eq 0 ]] || [[ $EXIT_STATUS -eq 1 ]] || [[ $EXIT_STATUS -eq 2 ]] || { [[ $EXIT_STATUS -ge 5 ]] && [[ $EXIT_STATUS -le 8 ]]; }
 then
 	# poweroff after 2 min
 	shutdown --poweroff +2
@@ -0,0 +1,128 @@
+package launcher
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/leases"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// pullProgressInterval is how often the progress of an image pull is
+	// logged.
+	pullProgressInterval = 10 * time.Second
+	// pullLeaseExpiration is how long the content of a failed pull is kept in
+	// the content store, so a retry resumes its partially downloaded layers
+	// instead of starting over.
+	pullLeaseExpiration = time.Hour
+	// pullAttempts is how many times the pull of an image reference is
+	// attempted.
+	pullAttempts = 3
+)
+
+// pullImage pulls and unpacks the image for the platform, or for the
+// platform of the VM if empty. The progress of the pull is logged, and failed
+// pulls are retried, resuming from the content already downloaded, until ctx
+// is done.
+func pullImage(ctx context.Context, cdClient *containerd.Client, ref string, platform string, token oauth2.Token, httpClient *http.Client, logger *log.Logger) (containerd.Image, error) {
+	pullOpts := []containerd.RemoteOpt{containerd.WithPullUnpack}
+	if platform != "" {
+		pullOpts = append(pullOpts, containerd.WithPlatform(platform))
+	}
+	accessToken := ""
+	if token.Valid() {
+		accessToken = token.AccessToken
+	}
+	pullOpts = append(pullOpts, containerd.WithResolver(Resolver(accessToken, httpClient)))
+
+	// Pull deletes its own lease when it fails, letting the garbage collector
+	// remove the partially downloaded content. Ours expires instead.
+	ctx, deleteLease, err := cdClient.WithLease(ctx, leases.WithRandomID(), leases.WithExpiration(pullLeaseExpiration))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create a lease to pull the image %s: %w", ref, err)
+	}
+	stopProgress := logPullProgress(ctx, cdClient.ContentStore(), ref, logger)
+	defer stopProgress()
+
+	var image containerd.Image
+	retry := backoff.WithContext(backoff.WithMaxRetries(backoff.NewExponentialBackOff(), pullAttempts-1), ctx)
+	err = backoff.RetryNotify(func() error {
+		var err error
+		image, err = cdClient.Pull(ctx, ref, pullOpts...)
+		if errdefs.IsNotFound(err) || errdefs.IsInvalidArgument(err) {
+			return backoff.Permanent(err)
+		}
+		return err
+	}, retry, func(err error, t time.Duration) {
+		logger.Printf("failed to pull the image %s, resuming in %v: %v", ref, t, err)
+	})
+	if err != nil {
+		if !token.Valid() {
+			return nil, fmt.Errorf("cannot pull the image %s (no token, only works for a public image): %w", ref, err)
+		}
+		return nil, fmt.Errorf("cannot pull the image %s: %w", ref, err)
+	}
+	// The pulled image now references its content.
+	if err := deleteLease(ctx); err != nil {
+		logger.Printf("failed to delete the lease of the image %s: %v", ref, err)
+	}
+	return image, nil
+}
+
+// logPullProgress logs the progress of the downloads in store every
+// pullProgressInterval, until ctx is done or the returned function is called.
+func logPullProgress(ctx context.Context, store content.Store, ref string, logger *log.Logger) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(pullProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			statuses, err := store.ListStatuses(ctx)
+			if err != nil {
+				continue
+			}
+			for _, progress := range pullProgress(statuses) {
+				logger.Printf("pulling %s: %s", ref, progress)
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// pullProgress describes the progress of the downloads with statuses, e.g.
+// "layer-sha256:...: 12.0/40.0 MiB (30%)", sorted by their refs.
+func pullProgress(statuses []content.Status) []string {
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Ref < statuses[j].Ref })
+	progress := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		if status.Total <= 0 {
+			progress = append(progress, fmt.Sprintf("%s: %.1f MiB", status.Ref, mib(status.Offset)))
+			continue
+		}
+		progress = append(progress, fmt.Sprintf("%s: %.1f/%.1f MiB (%d%%)", status.Ref, mib(status.Offset), mib(status.Total), status.Offset*100/status.Total))
+	}
+	return progress
+}
+
+func mib(bytes int64) float64 {
+	return float64(bytes) / (1 << 20)
+}
@@ -0,0 +1,24 @@
+package launcher
+
+import (
+	"testing"
+
+	"github.com/containerd/containerd/content"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPullProgress(t *testing.T) {
+	statuses := []content.Status{
+		{Ref: "layer-sha256:bb", Offset: 30 << 20, Total: 40 << 20},
+		{Ref: "layer-sha256:aa", Offset: 1 << 19, Total: 2 << 20},
+		{Ref: "manifest-sha256:cc", Offset: 3 << 10},
+	}
+	want := []string{
+		"layer-sha256:aa: 0.5/2.0 MiB (25%)",
+		"layer-sha256:bb: 30.0/40.0 MiB (75%)",
+		"manifest-sha256:cc: 0.0 MiB",
+	}
+	if diff := cmp.Diff(want, pullProgress(statuses)); diff != "" {
+		t.Errorf("pullProgress() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,112 @@
+package intoto
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/go-tpm-tools/launcher/agent"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm-tools/server"
+	"google.golang.org/protobuf/proto"
+)
+
+// PayloadType is the DSSE payload type of in-toto statements.
+const PayloadType = "application/vnd.in-toto+json"
+
+// Envelope is a DSSE envelope, see
+// https://github.com/secure-systems-lab/dsse/blob/master/envelope.md.
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     []byte      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is a DSSE signature. Sig is a serialized tpm.Quote of the CEL PCR
+// by the attestation key, made with client.Key.QuoteData over the DSSE
+// pre-authentication encoding of the payload.
+type Signature struct {
+	KeyID string `json:"keyid,omitempty"`
+	Sig   []byte `json:"sig"`
+}
+
+// pae is the DSSE pre-authentication encoding.
+func pae(payloadType string, payload []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "DSSEv1 %d %s %d ", len(payloadType), payloadType, len(payload))
+	buf.Write(payload)
+	return buf.Bytes()
+}
+
+// Sign creates the statement about the container in the CEL of the signer,
+// and signs it with a quote of the attestation key.
+func Sign(signer agent.CELSigner) (*Envelope, error) {
+	var payload []byte
+	quote, err := signer.QuoteCEL(func(encodedCEL []byte, pcrs *tpmpb.PCRs) ([]byte, error) {
+		statement, err := NewStatement(encodedCEL, pcrs)
+		if err != nil {
+			return nil, err
+		}
+		if payload, err = json.Marshal(statement); err != nil {
+			return nil, err
+		}
+		return pae(PayloadType, payload), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sig, err := proto.Marshal(quote)
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{
+		PayloadType: PayloadType,
+		Payload:     payload,
+		Signatures:  []Signature{{Sig: sig}},
+	}, nil
+}
+
+// Verify checks that the envelope is signed by the trusted attestation key,
+// and that the statement matches the CEL it contains, which must replay to the
+// quoted CEL PCR. It returns the verified statement.
+//
+// Note that the caller must have already established trust in the provided
+// public key, e.g. by verifying an attestation from the same VM.
+func Verify(envelope *Envelope, trustedPub crypto.PublicKey) (*Statement, error) {
+	if envelope.PayloadType != PayloadType {
+		return nil, fmt.Errorf("unexpected payload type %q", envelope.PayloadType)
+	}
+	if len(envelope.Signatures) != 1 {
+		return nil, fmt.Errorf("got %d signatures, expected 1", len(envelope.Signatures))
+	}
+	quote := &tpmpb.Quote{}
+	if err := proto.Unmarshal(envelope.Signatures[0].Sig, quote); err != nil {
+		return nil, fmt.Errorf("failed to decode quote: %w", err)
+	}
+	if err := server.VerifyQuoteData(quote, trustedPub, pae(envelope.PayloadType, envelope.Payload)); err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+
+	statement := &Statement{}
+	if err := json.Unmarshal(envelope.Payload, statement); err != nil {
+		return nil, fmt.Errorf("failed to decode statement: %w", err)
+	}
+	if statement.Predicate == nil {
+		return nil, errors.New("statement has no predicate")
+	}
+	// The claims must be the ones recorded in the quoted CEL.
+	want, err := NewStatement(statement.Predicate.CanonicalEventLog, quote.GetPcrs())
+	if err != nil {
+		return nil, err
+	}
+	wantPayload, err := json.Marshal(want)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(envelope.Payload, wantPayload) {
+		return nil, errors.New("statement does not match the CEL")
+	}
+	return statement, nil
+}
@@ -0,0 +1,105 @@
+package intoto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm-tools/launcher/agent"
+)
+
+const (
+	testImageRef    = "docker.io/library/hello-world:latest"
+	testImageDigest = "sha256:aa0cc8055b82dc2509bed2e19b275c8f463506616377219d9642221ab53cf9fe"
+)
+
+func placeholderFetcher(audience string) ([][]byte, error) {
+	return nil, nil
+}
+
+func TestSignVerify(t *testing.T) {
+	test.SkipForRealTPM(t)
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+
+	attestAgent := agent.CreateAttestationAgent(tpm, client.AttestationKeyECC, nil, placeholderFetcher)
+	for _, event := range []cel.CosTlv{
+		{EventType: cel.ImageRefType, EventContent: []byte(testImageRef)},
+		{EventType: cel.ImageDigestType, EventContent: []byte(testImageDigest)},
+		{EventType: cel.RestartPolicyType, EventContent: []byte("Never")},
+		{EventType: cel.ArgType, EventContent: []byte("/hello")},
+		{EventType: cel.LaunchSeparatorType},
+	} {
+		if err := attestAgent.MeasureEvent(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	envelope, err := Sign(attestAgent.(agent.CELSigner))
+	if err != nil {
+		t.Fatalf("failed to sign statement: %v", err)
+	}
+	statement, err := Verify(envelope, ak.PublicKey())
+	if err != nil {
+		t.Fatalf("failed to verify statement: %v", err)
+	}
+	wantSubject := []Subject{{
+		Name:   testImageRef,
+		Digest: map[string]string{"sha256": "aa0cc8055b82dc2509bed2e19b275c8f463506616377219d9642221ab53cf9fe"},
+	}}
+	if diff := cmp.Diff(wantSubject, statement.Subject); diff != "" {
+		t.Errorf("statement subject (-want +got):\n%s", diff)
+	}
+	wantContainer := &Container{
+		ImageReference: testImageRef,
+		ImageDigest:    testImageDigest,
+		RestartPolicy:  "Never",
+		Args:           []string{"/hello"},
+	}
+	if diff := cmp.Diff(wantContainer, statement.Predicate.Container); diff != "" {
+		t.Errorf("statement container (-want +got):\n%s", diff)
+	}
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(envelope, otherKey.Public()); err == nil {
+		t.Error("Verify succeeded with the wrong key")
+	}
+
+	// Claims that are not in the CEL are rejected, even if signed.
+	statement.Predicate.Container.Args = []string{"/bin/sh"}
+	tampered := *envelope
+	if tampered.Payload, err = json.Marshal(statement); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(&tampered, ak.PublicKey()); err == nil {
+		t.Error("Verify succeeded with a modified statement")
+	}
+}
+
+func TestNewStatementRequiresImageDigest(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	attestAgent := agent.CreateAttestationAgent(tpm, client.AttestationKeyECC, nil, placeholderFetcher)
+	if err := attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.ImageRefType, EventContent: []byte(testImageRef)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Sign(attestAgent.(agent.CELSigner)); err == nil {
+		t.Error("Sign succeeded without an image digest")
+	}
+}
@@ -0,0 +1,93 @@
+// Package intoto turns the container claims measured by the launcher into an
+// in-toto attestation, so supply chain tooling can consume "this image ran in
+// this confidential VM" as a standard statement. Statements are wrapped in a
+// DSSE envelope signed by a quote of the attestation key over the CEL PCR.
+package intoto
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm-tools/server"
+)
+
+const (
+	// StatementType is the type of in-toto v1 statements.
+	StatementType = "https://in-toto.io/Statement/v1"
+	// PredicateType is the type of the predicates produced by the launcher.
+	PredicateType = "https://github.com/google/go-tpm-tools/launcher/ContainerRun/v1"
+)
+
+// Statement is an in-toto v1 statement about the container images run.
+type Statement struct {
+	Type          string     `json:"_type"`
+	Subject       []Subject  `json:"subject"`
+	PredicateType string     `json:"predicateType"`
+	Predicate     *Predicate `json:"predicate"`
+}
+
+// Subject is an artifact the statement is about, identified by its digests.
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Predicate describes how the subject container image was run by the
+// launcher.
+type Predicate struct {
+	Container *Container `json:"container"`
+	// CanonicalEventLog is the COS Canonical Event Log the container claims
+	// were measured into. The quote signing the statement is over the PCR
+	// it replays to.
+	CanonicalEventLog []byte `json:"canonicalEventLog"`
+}
+
+// Container holds the claims measured by the launcher about the container.
+type Container struct {
+	ImageReference string            `json:"imageReference"`
+	ImageDigest    string            `json:"imageDigest"`
+	ImageID        string            `json:"imageId,omitempty"`
+	RestartPolicy  string            `json:"restartPolicy,omitempty"`
+	Args           []string          `json:"args,omitempty"`
+	EnvVars        map[string]string `json:"envVars,omitempty"`
+}
+
+// NewStatement creates the statement about the container recorded in the
+// encoded CEL, which must replay to the values of the CEL PCR.
+func NewStatement(encodedCEL []byte, pcrs *tpmpb.PCRs) (*Statement, error) {
+	cosState, err := server.VerifyCanonicalEventLog(encodedCEL, pcrs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify CEL: %w", err)
+	}
+	container := cosState.GetContainer()
+	if container.GetImageReference() == "" {
+		return nil, errors.New("no container image in CEL")
+	}
+	// Image digests look like "sha256:<hex>".
+	alg, digest, ok := strings.Cut(container.GetImageDigest(), ":")
+	if !ok {
+		return nil, fmt.Errorf("malformed image digest %q", container.GetImageDigest())
+	}
+
+	return &Statement{
+		Type: StatementType,
+		Subject: []Subject{{
+			Name:   container.GetImageReference(),
+			Digest: map[string]string{alg: digest},
+		}},
+		PredicateType: PredicateType,
+		Predicate: &Predicate{
+			Container: &Container{
+				ImageReference: container.GetImageReference(),
+				ImageDigest:    container.GetImageDigest(),
+				ImageID:        container.GetImageId(),
+				RestartPolicy:  container.GetRestartPolicy().String(),
+				Args:           container.GetArgs(),
+				EnvVars:        container.GetEnvVars(),
+			},
+			CanonicalEventLog: encodedCEL,
+		},
+	}, nil
+}
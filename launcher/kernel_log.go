@@ -0,0 +1,66 @@
+package launcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/google/go-tpm-tools/cel"
+)
+
+// The actions of the syslog system call, see syslog(2).
+const (
+	syslogActionReadAll    = 3
+	syslogActionSizeBuffer = 10
+)
+
+// kernelTaintPath holds the taint flags of the kernel, as a decimal number.
+const kernelTaintPath = "/proc/sys/kernel/tainted"
+
+// readKernelLog reads the kernel log ring buffer of the host, as dmesg does, a
+// variable for testing.
+var readKernelLog = func() ([]byte, error) {
+	size, err := syscall.Klogctl(syslogActionSizeBuffer, nil)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	n, err := syscall.Klogctl(syslogActionReadAll, buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// readKernelTaint reads the taint flags of the kernel of the host, a variable
+// for testing.
+var readKernelTaint = func() (uint64, error) {
+	data, err := os.ReadFile(kernelTaintPath)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// kernelLogEvent returns the KernelLog event of the kernel log at the time of
+// the call, with the SHA-256 digest of the log and the taint flags.
+func kernelLogEvent() (cel.CosTlv, error) {
+	log, err := readKernelLog()
+	if err != nil {
+		return cel.CosTlv{}, fmt.Errorf("failed to read the kernel log: %v", err)
+	}
+	taint, err := readKernelTaint()
+	if err != nil {
+		return cel.CosTlv{}, fmt.Errorf("failed to read the kernel taint flags: %v", err)
+	}
+	digest := sha256.Sum256(log)
+	content, err := cel.FormatKernelLog("sha256:"+hex.EncodeToString(digest[:]), taint)
+	if err != nil {
+		return cel.CosTlv{}, err
+	}
+	return cel.CosTlv{EventType: cel.KernelLogType, EventContent: []byte(content)}, nil
+}
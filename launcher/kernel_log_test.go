@@ -0,0 +1,37 @@
+package launcher
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-tpm-tools/cel"
+)
+
+func TestKernelLogEvent(t *testing.T) {
+	defer func(old func() ([]byte, error)) { readKernelLog = old }(readKernelLog)
+	defer func(old func() (uint64, error)) { readKernelTaint = old }(readKernelTaint)
+	readKernelLog = func() ([]byte, error) { return []byte("foo"), nil }
+	readKernelTaint = func() (uint64, error) { return 4096, nil }
+
+	event, err := kernelLogEvent()
+	if err != nil {
+		t.Fatalf("kernelLogEvent() failed: %v", err)
+	}
+	if event.EventType != cel.KernelLogType {
+		t.Errorf("got event type %v, want %v", event.EventType, cel.KernelLogType)
+	}
+	// The SHA-256 of "foo".
+	want := "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae 4096"
+	if got := string(event.EventContent); got != want {
+		t.Errorf("got event content %q, want %q", got, want)
+	}
+
+	readKernelTaint = func() (uint64, error) { return 0, errors.New("no procfs") }
+	if _, err := kernelLogEvent(); err == nil {
+		t.Error("kernelLogEvent() succeeded without the taint flags")
+	}
+	readKernelLog = func() ([]byte, error) { return nil, errors.New("operation not permitted") }
+	if _, err := kernelLogEvent(); err == nil {
+		t.Error("kernelLogEvent() succeeded without the kernel log")
+	}
+}
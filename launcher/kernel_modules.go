@@ -0,0 +1,117 @@
+package launcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/client"
+)
+
+// kernelModuleInterval is how often the kernel modules loaded since the last
+// check are measured.
+const kernelModuleInterval = 10 * time.Second
+
+// imaPolicyPath is the IMA policy of the host, a variable for testing.
+var imaPolicyPath = "/sys/kernel/security/ima/policy"
+
+// installKernelModulePolicy appends client.KernelModuleIMAPolicy to the IMA
+// policy of the host, so IMA measures the kernel module loads, and only them,
+// into client.KernelModuleIMAPCR. The policy must be writable, and the modules
+// loaded before are only measured if the boot policy had the rule.
+func installKernelModulePolicy() error {
+	f, err := os.OpenFile(imaPolicyPath, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open the IMA policy: %v", err)
+	}
+	if _, err := f.WriteString(client.KernelModuleIMAPolicy + "\n"); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to add %q to the IMA policy: %v", client.KernelModuleIMAPolicy, err)
+	}
+	return f.Close()
+}
+
+// readIMALog reads the IMA runtime measurement list of the host, a variable
+// for testing.
+var readIMALog = func() ([]byte, error) {
+	return client.GetIMALog(nil)
+}
+
+// kernelModuleMeasurer measures the kernel module loads recorded by IMA as
+// cel.KernelModuleType events.
+type kernelModuleMeasurer struct {
+	measure func(cel.Content) error
+	// measured is the number of module loads of the IMA log already
+	// measured. The log is append-only, so the next loads follow them.
+	measured int
+}
+
+// measureNew measures the module loads recorded since the last call, and
+// returns how many were measured.
+func (m *kernelModuleMeasurer) measureNew() (int, error) {
+	imaLog, err := readIMALog()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the IMA log: %v", err)
+	}
+	loads, err := client.KernelModuleLoads(imaLog)
+	if err != nil {
+		return 0, err
+	}
+	if len(loads) < m.measured {
+		return 0, fmt.Errorf("the IMA log has %d kernel module loads, fewer than the %d already measured", len(loads), m.measured)
+	}
+	n := 0
+	for _, load := range loads[m.measured:] {
+		content, err := cel.FormatKernelModule(load.FileDigest, load.FileName)
+		if err != nil {
+			return n, err
+		}
+		if err := m.measure(cel.CosTlv{EventType: cel.KernelModuleType, EventContent: []byte(content)}); err != nil {
+			return n, err
+		}
+		m.measured++
+		n++
+	}
+	return n, nil
+}
+
+// startKernelModuleMeasurement installs the IMA policy measuring the kernel
+// module loads, measures the modules already loaded, then the modules loaded
+// every kernelModuleInterval until ctx is cancelled.
+func (r *ContainerRunner) startKernelModuleMeasurement(ctx context.Context) error {
+	if err := installKernelModulePolicy(); err != nil {
+		return err
+	}
+	measurer := &kernelModuleMeasurer{measure: r.attestAgent.MeasureEvent}
+	n, err := measurer.measureNew()
+	if err != nil {
+		return err
+	}
+	r.logger.Printf("measured %d loaded kernel modules\n", n)
+	go r.measureKernelModules(ctx, measurer, kernelModuleInterval)
+	return nil
+}
+
+// measureKernelModules measures the modules loaded every interval until ctx
+// is cancelled.
+func (r *ContainerRunner) measureKernelModules(ctx context.Context, measurer *kernelModuleMeasurer, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Println("kernel module measurement stopped")
+			return
+		case <-ticker.C:
+		}
+		n, err := measurer.measureNew()
+		if n > 0 {
+			r.logger.Printf("measured %d newly loaded kernel modules\n", n)
+		}
+		if err != nil {
+			r.logger.Printf("failed to measure the loaded kernel modules: %v", err)
+		}
+	}
+}
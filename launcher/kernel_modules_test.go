@@ -0,0 +1,89 @@
+package launcher
+
+import (
+	"errors"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/client"
+)
+
+func TestKernelModuleMeasurer(t *testing.T) {
+	imaLog := "10 0000000000000000000000000000000000000000 ima-ng sha256:00 boot_aggregate\n" +
+		"11 0000000000000000000000000000000000000000 ima-ng sha256:01 /lib/modules/6.1.0/kernel/fs/fuse/fuse.ko\n"
+	defer func(old func() ([]byte, error)) { readIMALog = old }(readIMALog)
+	readIMALog = func() ([]byte, error) { return []byte(imaLog), nil }
+
+	var measured []string
+	measurer := &kernelModuleMeasurer{measure: func(event cel.Content) error {
+		cos, ok := event.(cel.CosTlv)
+		if !ok || cos.EventType != cel.KernelModuleType {
+			t.Errorf("measured event %v, want a KernelModule event", event)
+		}
+		measured = append(measured, string(cos.EventContent))
+		return nil
+	}}
+	if n, err := measurer.measureNew(); err != nil || n != 1 {
+		t.Fatalf("measureNew() = %d, %v, want 1 module", n, err)
+	}
+
+	// Only the measurements of the kernel module PCR are module loads.
+	imaLog += "10 0000000000000000000000000000000000000000 ima-ng sha256:02 /lib/modules/6.1.0/kernel/fs/read.ko\n" +
+		"11 0000000000000000000000000000000000000000 ima-ng sha256:03 /lib/modules/6.1.0/kernel/drivers/block/nbd.ko.xz\n"
+	if n, err := measurer.measureNew(); err != nil || n != 1 {
+		t.Fatalf("measureNew() = %d, %v, want 1 new module", n, err)
+	}
+	if n, err := measurer.measureNew(); err != nil || n != 0 {
+		t.Fatalf("measureNew() = %d, %v, want no new module", n, err)
+	}
+	want := []string{
+		"sha256:01 /lib/modules/6.1.0/kernel/fs/fuse/fuse.ko",
+		"sha256:03 /lib/modules/6.1.0/kernel/drivers/block/nbd.ko.xz",
+	}
+	if len(measured) != len(want) || measured[0] != want[0] || measured[1] != want[1] {
+		t.Errorf("got measured modules %q, want %q", measured, want)
+	}
+
+	// A module failing to be measured is measured again on the next call.
+	failing := &kernelModuleMeasurer{measure: func(cel.Content) error { return errors.New("TPM failure") }}
+	if _, err := failing.measureNew(); err == nil {
+		t.Error("measureNew() succeeded when measuring failed")
+	}
+	if failing.measured != 0 {
+		t.Errorf("got %d measured modules after a failure, want 0", failing.measured)
+	}
+}
+
+func TestInstallKernelModulePolicy(t *testing.T) {
+	defer func(old string) { imaPolicyPath = old }(imaPolicyPath)
+	imaPolicyPath = path.Join(t.TempDir(), "policy")
+	if err := installKernelModulePolicy(); err == nil {
+		t.Error("installKernelModulePolicy() succeeded without an IMA policy file")
+	}
+
+	if err := os.WriteFile(imaPolicyPath, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := installKernelModulePolicy(); err != nil {
+		t.Fatalf("installKernelModulePolicy() failed: %v", err)
+	}
+	policy, err := os.ReadFile(imaPolicyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := client.KernelModuleIMAPolicy + "\n"; string(policy) != want {
+		t.Errorf("got IMA policy %q, want %q", policy, want)
+	}
+}
+
+func TestKernelModuleMeasurerIMAUnavailable(t *testing.T) {
+	defer func(old func() ([]byte, error)) { readIMALog = old }(readIMALog)
+	readIMALog = func() ([]byte, error) { return nil, errors.New("no IMA") }
+
+	measurer := &kernelModuleMeasurer{measure: func(cel.Content) error { return nil }}
+	if _, err := measurer.measureNew(); err == nil {
+		t.Error("measureNew() succeeded without an IMA log")
+	}
+}
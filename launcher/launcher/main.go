@@ -4,9 +4,15 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
+	"fmt"
 	"io"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"cloud.google.com/go/compute/metadata"
 	"cloud.google.com/go/logging"
@@ -29,21 +35,53 @@ const (
 	// panic() returns 2
 	rebootRC = 3 // reboot
 	holdRC   = 4 // hold
+	// Failures with a known cause, instead of failRC (no reboot)
+	policyViolationRC    = 5 // launch policy violation
+	imagePullFailureRC   = 6 // none of the images could be pulled
+	attestationFailureRC = 7 // workload attestation failed
+	workloadFailureRC    = 8 // workload returned non-zero
 )
 
 var rcMessage = map[int]string{
-	successRC: "workload finished successfully, shutting down the VM",
-	failRC:    "workload or launcher error, shutting down the VM",
-	rebootRC:  "rebooting VM",
-	holdRC:    "VM remains running",
+	successRC:            "workload finished successfully, shutting down the VM",
+	failRC:               "workload or launcher error, shutting down the VM",
+	rebootRC:             "rebooting VM",
+	holdRC:               "VM remains running",
+	policyViolationRC:    "launch policy violation, shutting down the VM",
+	imagePullFailureRC:   "image pull failure, shutting down the VM",
+	attestationFailureRC: "attestation failure, shutting down the VM",
+	workloadFailureRC:    "workload returned a non-zero return code, shutting down the VM",
 }
 
+// reasonRC is the exit code of each failure reason of a launch with a known
+// cause.
+var reasonRC = map[string]int{
+	launcher.ReasonPolicyViolation:    policyViolationRC,
+	launcher.ReasonImagePullFailure:   imagePullFailureRC,
+	launcher.ReasonAttestationFailure: attestationFailureRC,
+	launcher.ReasonWorkloadFailure:    workloadFailureRC,
+}
+
+// statusTimeout bounds writing the terminal status guest attribute, which
+// fails if guest attributes are not enabled on the instance.
+const statusTimeout = 5 * time.Second
+
+var dryRun = flag.Bool("dry-run", false,
+	"resolve the launch spec, image labels, and launch policy, and print what would be measured and executed without starting the workload")
+
+var launchSpecFile = flag.String("launch-spec-file", "",
+	"read the launch spec from a YAML or JSON file instead of the instance metadata")
+
 var logger *log.Logger
 var mdsClient *metadata.Client
 var launchSpec spec.LaunchSpec
 
 func main() {
 	var exitCode int
+	// runErr is the error the launcher exits with, recorded in the terminal
+	// status.
+	var runErr error
+	flag.Parse()
 
 	logger = log.Default()
 	// log.Default() outputs to stderr; change to stdout.
@@ -57,21 +95,44 @@ func main() {
 		if r := recover(); r != nil {
 			logger.Println("Panic:", r)
 			exitCode = 2
+			runErr = fmt.Errorf("panic: %v", r)
+		}
+		if !*dryRun {
+			status := launcher.NewTerminalStatus(exitCode, runErr)
+			if err := launcher.WriteTerminalStatus(status, &http.Client{Timeout: statusTimeout}); err != nil {
+				logger.Printf("failed to write the terminal status: %v", err)
+			}
 		}
 		os.Exit(exitCode)
 	}()
 
 	mdsClient = metadata.NewClient(nil)
-	projectID, err := mdsClient.ProjectID()
-	if err != nil {
-		logger.Printf("cannot get projectID, not in GCE? %v", err)
-		// cannot get projectID from MDS, exit directly
-		exitCode = failRC
-		return
+	var projectID string
+	var err error
+	if *launchSpecFile != "" {
+		// get the launch spec from a local file, MDS may not be available
+		launchSpec, err = spec.GetLaunchSpecFromFile(*launchSpecFile)
+		if err != nil {
+			logger.Println(err)
+			exitCode = failRC
+			runErr = err
+			return
+		}
+		projectID = launchSpec.ProjectID
+	} else {
+		projectID, err = mdsClient.ProjectID()
+		if err != nil {
+			logger.Printf("cannot get projectID, not in GCE? %v", err)
+			// cannot get projectID from MDS, exit directly
+			exitCode = failRC
+			runErr = err
+			return
+		}
 	}
 
-	logClient, err := logging.NewClient(context.Background(), projectID)
-	if err != nil {
+	if projectID == "" {
+		logger.Println("no projectID in the launch spec file, using the default stdout logger")
+	} else if logClient, err := logging.NewClient(context.Background(), projectID); err != nil {
 		logger.Printf("cannot setup Cloud Logging, using the default stdout logger %v", err)
 	} else {
 		defer logClient.Close()
@@ -81,12 +142,20 @@ func main() {
 		logger.SetOutput(loggerAndStdout)
 	}
 
-	// get restart policy and ishardened from spec
-	launchSpec, err = spec.GetLaunchSpec(mdsClient)
-	if err != nil {
-		logger.Println(err)
-		// if cannot get launchSpec, exit directly
-		exitCode = failRC
+	if *launchSpecFile == "" {
+		// get restart policy and ishardened from spec
+		launchSpec, err = spec.GetLaunchSpec(mdsClient)
+		if err != nil {
+			logger.Println(err)
+			// if cannot get launchSpec, exit directly
+			exitCode = failRC
+			runErr = err
+			return
+		}
+	}
+
+	if *dryRun {
+		exitCode = runDryRun()
 		return
 	}
 
@@ -95,6 +164,7 @@ func main() {
 		if r := recover(); r != nil {
 			logger.Println("Panic:", r)
 			exitCode = 2
+			runErr = fmt.Errorf("panic: %v", r)
 		}
 		msg, ok := rcMessage[exitCode]
 		if ok {
@@ -103,11 +173,11 @@ func main() {
 			logger.Printf("TEE container launcher exiting with exit code: %d\n", exitCode)
 		}
 	}()
-	if err = startLauncher(); err != nil {
-		logger.Println(err)
+	if runErr = startLauncher(); runErr != nil {
+		logger.Println(runErr)
 	}
 
-	exitCode = getExitCode(launchSpec.Hardened, launchSpec.RestartPolicy, err)
+	exitCode = getExitCode(launchSpec.Hardened, launchSpec.RestartPolicy, runErr)
 }
 
 func getExitCode(isHardened bool, restartPolicy spec.RestartPolicy, err error) int {
@@ -122,12 +192,12 @@ func getExitCode(isHardened bool, restartPolicy spec.RestartPolicy, err error) i
 		switch err.(type) {
 		default:
 			// non-retryable error
-			exitCode = failRC
+			exitCode = failureExitCode(err)
 		case *launcher.RetryableError, *launcher.WorkloadError:
 			if restartPolicy == spec.Always || restartPolicy == spec.OnFailure {
 				exitCode = rebootRC
 			} else {
-				exitCode = failRC
+				exitCode = failureExitCode(err)
 			}
 		}
 	} else {
@@ -142,6 +212,36 @@ func getExitCode(isHardened bool, restartPolicy spec.RestartPolicy, err error) i
 	return exitCode
 }
 
+// failureExitCode returns the exit code of a launch failing with err without
+// a reboot: the code of its cause if known, or failRC.
+func failureExitCode(err error) int {
+	if rc, ok := reasonRC[launcher.ErrorReason(err)]; ok {
+		return rc
+	}
+	return failRC
+}
+
+// runDryRun prints the launcher's decisions for the launch spec, and returns
+// failRC if the launch spec would be rejected.
+func runDryRun() int {
+	logger.Println("Dry run: the workload will not be started")
+	token, err := launcher.RetrieveAuthToken(mdsClient)
+	if err != nil {
+		logger.Printf("failed to retrieve auth token: %v, using empty auth for image resolution\n", err)
+	}
+
+	result, err := launcher.DryRun(context.Background(), token, launchSpec)
+	if err != nil {
+		logger.Println(err)
+		return failRC
+	}
+	result.Print(logger.Writer())
+	if result.PolicyErr != nil {
+		return failRC
+	}
+	return successRC
+}
+
 func startLauncher() error {
 	logger.Println("Launch Spec: ", launchSpec)
 	containerdClient, err := containerd.New(defaults.DefaultAddress)
@@ -156,6 +256,12 @@ func startLauncher() error {
 	}
 	defer tpm.Close()
 
+	report := launcher.Preflight(context.Background(), launchSpec, launcher.PreflightEnv{TPM: tpm, Containerd: containerdClient})
+	logger.Printf("Preflight report: %v\n", report)
+	if err := report.Err(); err != nil {
+		return err
+	}
+
 	// check AK (EK signing) cert
 	gceAk, err := client.GceAttestationKeyECC(tpm)
 	if err != nil {
@@ -171,8 +277,21 @@ func startLauncher() error {
 		logger.Printf("failed to retrieve auth token: %v, using empty auth for image pulling\n", err)
 	}
 
+	unmountTokenTmpfs, err := launcher.MountTokenTmpfs(launchSpec)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := unmountTokenTmpfs(); err != nil {
+			logger.Println(err)
+		}
+	}()
+
 	ctx := namespaces.WithNamespace(context.Background(), namespaces.Default)
-	r, err := launcher.NewRunner(ctx, containerdClient, token, launchSpec, mdsClient, tpm, logger)
+	// Stopping the launcher cancels the image pull of NewRunner.
+	newRunnerCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	r, err := launcher.NewRunner(newRunnerCtx, containerdClient, token, launchSpec, mdsClient, tpm, logger, report.Clock)
+	stop()
 	if err != nil {
 		return err
 	}
@@ -88,7 +88,7 @@ func TestGetExitCode(t *testing.T) {
 		},
 		{
 			"hardened, never restart, workload error",
-			true, spec.Never, &launcher.WorkloadError{}, failRC,
+			true, spec.Never, &launcher.WorkloadError{}, workloadFailureRC,
 		},
 		{
 			"hardened, onfailure restart, workload error",
@@ -120,6 +120,23 @@ func TestGetExitCode(t *testing.T) {
 			"hardened, onfailure restart, non-retryable error",
 			true, spec.OnFailure, errors.New(""), failRC,
 		},
+		// non-retryable errors with a known cause, hardened image
+		{
+			"hardened, always restart, policy error",
+			true, spec.Always, &launcher.PolicyError{Err: errors.New("")}, policyViolationRC,
+		},
+		{
+			"hardened, onfailure restart, image pull error",
+			true, spec.OnFailure, &launcher.ImagePullError{Err: errors.New("")}, imagePullFailureRC,
+		},
+		{
+			"hardened, never restart, attestation error",
+			true, spec.Never, &launcher.AttestationError{Err: errors.New("")}, attestationFailureRC,
+		},
+		{
+			"debug, never restart, policy error",
+			false, spec.Never, &launcher.PolicyError{Err: errors.New("")}, holdRC,
+		},
 	}
 
 	for _, tc := range testcases {
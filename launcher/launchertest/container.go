@@ -0,0 +1,247 @@
+package launchertest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/cio"
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/content/local"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/oci"
+	"github.com/containerd/containerd/platforms"
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/launcher/spec"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// DefaultImageRef is the reference of the images without an ImageConfig.Ref.
+const DefaultImageRef = "docker.io/library/workload:latest"
+
+// ImageConfig describes the image of a Container.
+type ImageConfig struct {
+	// Ref defaults to DefaultImageRef.
+	Ref string
+	// Entrypoint defaults to "/workload".
+	Entrypoint []string
+	Cmd        []string
+	Env        []string
+	// Labels are the OCI labels of the image, e.g. its launch policy.
+	Labels map[string]string
+	// Layers are the contents of the layers of the image, which are never
+	// unpacked. A single layer is used by default.
+	Layers [][]byte
+}
+
+// Workload runs in place of the process of a Container, and returns its exit
+// code. The spec has the args and environment variables of the process.
+type Workload func(ctx context.Context, spec *oci.Spec) uint32
+
+// Succeed is a Workload exiting with 0.
+func Succeed(context.Context, *oci.Spec) uint32 { return 0 }
+
+// Container is a containerd.Container test double, running a Workload in
+// place of the container process. Only the methods used by the launcher are
+// implemented, the others panic.
+type Container struct {
+	// containerd.Container is nil, it only provides the other methods.
+	containerd.Container
+	image    *image
+	spec     *oci.Spec
+	workload Workload
+
+	mu      sync.Mutex
+	started bool
+	deleted bool
+}
+
+// NewContainer returns a Container of the image built from config, with the
+// args and environment variables of the LaunchSpec. The content of the image
+// is stored in a temporary directory of tb.
+func NewContainer(tb testing.TB, config ImageConfig, launchSpec spec.LaunchSpec, workload Workload) *Container {
+	tb.Helper()
+	img := newImage(tb, config)
+
+	args := append([]string{}, img.config.Entrypoint...)
+	if len(launchSpec.Cmd) > 0 {
+		args = append(args, launchSpec.Cmd...)
+	} else {
+		args = append(args, img.config.Cmd...)
+	}
+	env := append([]string{}, img.config.Env...)
+	for _, envVar := range launchSpec.Envs {
+		formatted, err := cel.FormatEnvVar(envVar.Name, envVar.Value)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		env = append(env, formatted)
+	}
+	if workload == nil {
+		workload = Succeed
+	}
+	return &Container{
+		image:    img,
+		spec:     &oci.Spec{Process: &specs.Process{Args: args, Env: env}},
+		workload: workload,
+	}
+}
+
+// Started returns whether the Workload was started.
+func (c *Container) Started() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.started
+}
+
+// Deleted returns whether the container was deleted.
+func (c *Container) Deleted() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.deleted
+}
+
+// ID implements containerd.Container.
+func (c *Container) ID() string { return "tee-container" }
+
+// Image implements containerd.Container.
+func (c *Container) Image(context.Context) (containerd.Image, error) { return c.image, nil }
+
+// Spec implements containerd.Container.
+func (c *Container) Spec(context.Context) (*oci.Spec, error) { return c.spec, nil }
+
+// Delete implements containerd.Container.
+func (c *Container) Delete(context.Context, ...containerd.DeleteOpts) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deleted = true
+	return nil
+}
+
+// NewTask implements containerd.Container. The IO of the task is ignored.
+func (c *Container) NewTask(context.Context, cio.Creator, ...containerd.NewTaskOpts) (containerd.Task, error) {
+	return &task{c: c, exit: make(chan containerd.ExitStatus, 1)}, nil
+}
+
+// task runs the Workload of its container when started.
+type task struct {
+	containerd.Task
+	c    *Container
+	exit chan containerd.ExitStatus
+}
+
+func (t *task) ID() string { return t.c.ID() }
+
+func (t *task) Pid() uint32 { return 0 }
+
+func (t *task) Wait(context.Context) (<-chan containerd.ExitStatus, error) {
+	return t.exit, nil
+}
+
+func (t *task) Start(ctx context.Context) error {
+	t.c.mu.Lock()
+	t.c.started = true
+	t.c.mu.Unlock()
+	go func() {
+		code := t.c.workload(ctx, t.c.spec)
+		t.exit <- *containerd.NewExitStatus(code, time.Now(), nil)
+	}()
+	return nil
+}
+
+func (t *task) Delete(context.Context, ...containerd.ProcessDeleteOpts) (*containerd.ExitStatus, error) {
+	return containerd.NewExitStatus(0, time.Now(), nil), nil
+}
+
+// image is a containerd.Image test double, with its manifest and config in a
+// local content store.
+type image struct {
+	containerd.Image
+	name     string
+	config   ImageConfig
+	store    content.Store
+	manifest ocispec.Descriptor
+	configD  ocispec.Descriptor
+}
+
+func newImage(tb testing.TB, config ImageConfig) *image {
+	tb.Helper()
+	if config.Ref == "" {
+		config.Ref = DefaultImageRef
+	}
+	if len(config.Entrypoint) == 0 {
+		config.Entrypoint = []string{"/workload"}
+	}
+	if len(config.Layers) == 0 {
+		config.Layers = [][]byte{[]byte("workload")}
+	}
+	store, err := local.NewStore(tb.TempDir())
+	if err != nil {
+		tb.Fatal(err)
+	}
+	ctx := context.Background()
+	write := func(mediaType string, data []byte) ocispec.Descriptor {
+		desc := ocispec.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(data), Size: int64(len(data))}
+		if err := content.WriteBlob(ctx, store, desc.Digest.String(), bytes.NewReader(data), desc); err != nil {
+			tb.Fatal(err)
+		}
+		return desc
+	}
+	marshal := func(v interface{}) []byte {
+		data, err := json.Marshal(v)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		return data
+	}
+
+	manifest := ocispec.Manifest{MediaType: ocispec.MediaTypeImageManifest}
+	manifest.SchemaVersion = 2
+	var diffIDs []digest.Digest
+	for _, layer := range config.Layers {
+		desc := write(ocispec.MediaTypeImageLayer, layer)
+		manifest.Layers = append(manifest.Layers, desc)
+		diffIDs = append(diffIDs, desc.Digest)
+	}
+	platform := platforms.DefaultSpec()
+	manifest.Config = write(ocispec.MediaTypeImageConfig, marshal(ocispec.Image{
+		Architecture: platform.Architecture,
+		OS:           platform.OS,
+		Config: ocispec.ImageConfig{
+			Entrypoint: config.Entrypoint,
+			Cmd:        config.Cmd,
+			Env:        config.Env,
+			Labels:     config.Labels,
+		},
+		RootFS: ocispec.RootFS{Type: "layers", DiffIDs: diffIDs},
+	}))
+	return &image{
+		name:     config.Ref,
+		config:   config,
+		store:    store,
+		manifest: write(ocispec.MediaTypeImageManifest, marshal(manifest)),
+		configD:  manifest.Config,
+	}
+}
+
+func (i *image) Name() string { return i.name }
+
+func (i *image) Target() ocispec.Descriptor { return i.manifest }
+
+func (i *image) Labels() map[string]string { return nil }
+
+func (i *image) Config(context.Context) (ocispec.Descriptor, error) { return i.configD, nil }
+
+func (i *image) ContentStore() content.Store { return i.store }
+
+func (i *image) Platform() platforms.MatchComparer { return platforms.Default() }
+
+func (i *image) Metadata() images.Image {
+	return images.Image{Name: i.name, Target: i.manifest}
+}
@@ -0,0 +1,120 @@
+// Package launchertest composes a launcher.ContainerRunner with test doubles,
+// so the launcher can be tested end to end without containerd, a GCE VM or
+// the Attestation Verifier: a simulated TPM, a fake verifier and a Container
+// running a Workload function.
+package launchertest
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm-tools/launcher"
+	"github.com/google/go-tpm-tools/launcher/spec"
+	"github.com/google/go-tpm-tools/launcher/verifier"
+	"github.com/google/go-tpm-tools/launcher/verifier/fake"
+)
+
+// LaunchSpec returns a valid LaunchSpec of the DefaultImageRef, which tests
+// can modify.
+func LaunchSpec() spec.LaunchSpec {
+	return spec.LaunchSpec{
+		ImageRef:        DefaultImageRef,
+		RestartPolicy:   spec.Never,
+		ProjectID:       "test-project",
+		Region:          "us-central1",
+		LogVerbosity:    spec.Info,
+		ClockSkewPolicy: spec.ClockSkewEnforce,
+	}
+}
+
+// Verifier is a fake verifier.Client recording the requests it verifies. Its
+// claims tokens are signed with Key.
+type Verifier struct {
+	verifier.Client
+	Key *rsa.PrivateKey
+
+	mu       sync.Mutex
+	requests []verifier.VerifyAttestationRequest
+}
+
+// NewVerifier returns a Verifier with a new signing key.
+func NewVerifier(tb testing.TB) *Verifier {
+	tb.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return &Verifier{Client: fake.NewClient(key), Key: key}
+}
+
+// VerifyAttestation implements verifier.Client.
+func (v *Verifier) VerifyAttestation(ctx context.Context, request verifier.VerifyAttestationRequest) (*verifier.VerifyAttestationResponse, error) {
+	v.mu.Lock()
+	v.requests = append(v.requests, request)
+	v.mu.Unlock()
+	return v.Client.VerifyAttestation(ctx, request)
+}
+
+// Requests returns the requests verified so far.
+func (v *Verifier) Requests() []verifier.VerifyAttestationRequest {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return append([]verifier.VerifyAttestationRequest{}, v.requests...)
+}
+
+// Options configure NewHarness.
+type Options struct {
+	Image    ImageConfig
+	Workload Workload
+	// Clock is measured as the clock checked by the preflight checks.
+	Clock launcher.ClockStatus
+}
+
+// Harness is a launcher.ContainerRunner with its test doubles.
+type Harness struct {
+	Runner    *launcher.ContainerRunner
+	TPM       io.ReadWriteCloser
+	Verifier  *Verifier
+	Container *Container
+}
+
+// NewHarness returns a Harness running the LaunchSpec. The runner attests
+// with the ECC AK of the simulated TPM, whose PCRs match a test event log.
+// The runner and the TPM are closed when the test ends.
+func NewHarness(tb testing.TB, launchSpec spec.LaunchSpec, opts Options) *Harness {
+	tb.Helper()
+	tpm := test.GetTPM(tb)
+	h := &Harness{
+		TPM:       tpm,
+		Verifier:  NewVerifier(tb),
+		Container: NewContainer(tb, opts.Image, launchSpec, opts.Workload),
+	}
+	var err error
+	h.Runner, err = launcher.NewRunnerWithDeps(context.Background(), launchSpec, launcher.RunnerDeps{
+		Container: h.Container,
+		TPM:       tpm,
+		AKFetcher: client.AttestationKeyECC,
+		Verifier:  h.Verifier,
+		Clock:     opts.Clock,
+	})
+	if err != nil {
+		tpm.Close()
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		h.Runner.Close(context.Background())
+		tpm.Close()
+	})
+	return h
+}
+
+// Run runs the container until the Workload returns.
+func (h *Harness) Run(ctx context.Context) error {
+	return h.Runner.Run(ctx)
+}
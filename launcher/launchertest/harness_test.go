@@ -0,0 +1,121 @@
+package launchertest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/containerd/containerd/oci"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm-tools/launcher"
+	"github.com/google/go-tpm-tools/launcher/spec"
+)
+
+func TestHarnessRun(t *testing.T) {
+	launchSpec := LaunchSpec()
+	launchSpec.Cmd = []string{"--flag"}
+	launchSpec.Envs = []spec.EnvVar{{Name: "NAME", Value: "value"}}
+
+	var gotArgs, gotEnv []string
+	h := NewHarness(t, launchSpec, Options{
+		Image: ImageConfig{Labels: map[string]string{
+			"tee.launch_policy.allow_cmd_override": "true",
+			"tee.launch_policy.allow_env_override": "NAME",
+		}},
+		Workload: func(ctx context.Context, spec *oci.Spec) uint32 {
+			gotArgs, gotEnv = spec.Process.Args, spec.Process.Env
+			return 0
+		},
+	})
+	if err := h.Run(context.Background()); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if !h.Container.Started() {
+		t.Error("the workload was not started")
+	}
+	if diff := cmp.Diff([]string{"/workload", "--flag"}, gotArgs); diff != "" {
+		t.Errorf("workload args (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"NAME=value"}, gotEnv); diff != "" {
+		t.Errorf("workload env (-want +got):\n%s", diff)
+	}
+
+	requests := h.Verifier.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("got %d VerifyAttestation requests, want 1", len(requests))
+	}
+	celLog, err := cel.DecodeToCEL(bytes.NewBuffer(requests[0].Attestation.GetCanonicalEventLog()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(map[cel.CosType][]string)
+	for _, record := range celLog.Records {
+		event, err := record.Content.ParseToCosTlv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		events[event.EventType] = append(events[event.EventType], string(event.EventContent))
+	}
+	if diff := cmp.Diff([]string{DefaultImageRef}, events[cel.ImageRefType]); diff != "" {
+		t.Errorf("measured image ref (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"--flag"}, events[cel.OverrideArgType]); diff != "" {
+		t.Errorf("measured override args (-want +got):\n%s", diff)
+	}
+	if len(events[cel.ImageLayerType]) != 1 {
+		t.Errorf("got %d measured layers, want 1", len(events[cel.ImageLayerType]))
+	}
+	if len(events[cel.LaunchSeparatorType]) != 1 {
+		t.Error("the launch separator was not measured")
+	}
+}
+
+func TestHarnessRunWorkloadError(t *testing.T) {
+	h := NewHarness(t, LaunchSpec(), Options{
+		Workload: func(context.Context, *oci.Spec) uint32 { return 3 },
+	})
+	err := h.Run(context.Background())
+	var workloadErr *launcher.WorkloadError
+	if !errors.As(err, &workloadErr) || workloadErr.ReturnCode != 3 {
+		t.Errorf("Run() = %v, want a WorkloadError with code 3", err)
+	}
+}
+
+func TestHarnessClose(t *testing.T) {
+	h := NewHarness(t, LaunchSpec(), Options{})
+	h.Runner.Close(context.Background())
+	if !h.Container.Deleted() {
+		t.Error("Close() did not delete the container")
+	}
+}
+
+func TestNewRunnerWithDepsLaunchPolicy(t *testing.T) {
+	overrideEnv := LaunchSpec()
+	overrideEnv.Envs = []spec.EnvVar{{Name: "NAME", Value: "value"}}
+
+	for _, tc := range []struct {
+		name       string
+		launchSpec spec.LaunchSpec
+		labels     map[string]string
+	}{
+		{"EnvOverrideNotAllowed", overrideEnv, nil},
+		{"ConfinementNotApplied", LaunchSpec(), map[string]string{"tee.launch_policy.seccomp_profile": spec.RuntimeDefaultProfile}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tpm := test.GetTPM(t)
+			defer tpm.Close()
+			_, err := launcher.NewRunnerWithDeps(context.Background(), tc.launchSpec, launcher.RunnerDeps{
+				Container: NewContainer(t, ImageConfig{Labels: tc.labels}, tc.launchSpec, Succeed),
+				TPM:       tpm,
+				Verifier:  NewVerifier(t),
+			})
+			var policyErr *launcher.PolicyError
+			if !errors.As(err, &policyErr) {
+				t.Errorf("NewRunnerWithDeps() = %v, want a PolicyError", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,371 @@
+package launcher
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/compute/metadata"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/monitoring/v3"
+	"google.golang.org/api/option"
+)
+
+// metricsInterval is how often the cgroup stats of the workload are
+// collected.
+const metricsInterval = time.Minute
+
+// cloudMonitoringMetricPrefix is the prefix of the custom metric types
+// exported to Cloud Monitoring.
+const cloudMonitoringMetricPrefix = "custom.googleapis.com/confidential_space/workload/"
+
+// workloadStats are the resource usage stats of the workload cgroup. CPU and
+// IO are cumulative since the workload started.
+type workloadStats struct {
+	Time         time.Time
+	CPUUsage     time.Duration
+	MemoryUsage  uint64
+	IOReadBytes  uint64
+	IOWriteBytes uint64
+}
+
+// cgroupStatsReader reads workloadStats from the cgroup filesystem. Both
+// cgroup v1 and the unified cgroup v2 hierarchy are supported.
+type cgroupStatsReader struct {
+	procRoot   string
+	cgroupRoot string
+}
+
+var defaultCgroupStatsReader = cgroupStatsReader{procRoot: "/proc", cgroupRoot: "/sys/fs/cgroup"}
+
+// cgroupPath is the cgroup of a process in a hierarchy.
+type cgroupPath struct {
+	// hierarchy is the directory of the cgroup v1 hierarchy under the cgroup
+	// root, named after its controllers, e.g. "cpu,cpuacct". It is empty for
+	// the unified hierarchy.
+	hierarchy string
+	path      string
+}
+
+func (p cgroupPath) file(root, name string) string {
+	return path.Join(root, p.hierarchy, p.path, name)
+}
+
+// read returns the stats of the cgroup of the process pid.
+func (r cgroupStatsReader) read(pid uint32) (workloadStats, error) {
+	cgroups, err := r.cgroupPaths(pid)
+	if err != nil {
+		return workloadStats{}, err
+	}
+	stats := workloadStats{Time: time.Now()}
+	// Hybrid hosts also have a unified hierarchy, but without controllers.
+	if _, v1 := cgroups["cpuacct"]; v1 {
+		err = r.readV1(cgroups, &stats)
+	} else if unified, ok := cgroups[""]; ok {
+		err = r.readV2(unified, &stats)
+	} else {
+		err = errors.New("no cgroup found for the workload")
+	}
+	return stats, err
+}
+
+// cgroupPaths returns the cgroup of the process pid for each controller, the
+// unified hierarchy having no controller.
+func (r cgroupStatsReader) cgroupPaths(pid uint32) (map[string]cgroupPath, error) {
+	f, err := os.Open(path.Join(r.procRoot, strconv.FormatUint(uint64(pid), 10), "cgroup"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	paths := make(map[string]cgroupPath)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Lines are "hierarchy-ID:controller-list:cgroup-path".
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		for _, controller := range strings.Split(fields[1], ",") {
+			paths[controller] = cgroupPath{hierarchy: fields[1], path: fields[2]}
+		}
+	}
+	return paths, scanner.Err()
+}
+
+func (r cgroupStatsReader) readV2(cgroup cgroupPath, stats *workloadStats) error {
+	cpuStat, err := readKeyedValues(cgroup.file(r.cgroupRoot, "cpu.stat"))
+	if err != nil {
+		return err
+	}
+	stats.CPUUsage = time.Duration(cpuStat["usage_usec"]) * time.Microsecond
+	if stats.MemoryUsage, err = readUint(cgroup.file(r.cgroupRoot, "memory.current")); err != nil {
+		return err
+	}
+
+	ioStat, err := os.ReadFile(cgroup.file(r.cgroupRoot, "io.stat"))
+	if err != nil {
+		return err
+	}
+	// Lines are "major:minor rbytes=N wbytes=N rios=N wios=N ...".
+	for _, line := range strings.Split(string(ioStat), "\n") {
+		for _, field := range strings.Fields(line) {
+			key, value, _ := strings.Cut(field, "=")
+			n, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			switch key {
+			case "rbytes":
+				stats.IOReadBytes += n
+			case "wbytes":
+				stats.IOWriteBytes += n
+			}
+		}
+	}
+	return nil
+}
+
+func (r cgroupStatsReader) readV1(cgroups map[string]cgroupPath, stats *workloadStats) error {
+	cpuacct := cgroups["cpuacct"]
+	memory, ok := cgroups["memory"]
+	blkio, ok2 := cgroups["blkio"]
+	if !ok || !ok2 {
+		return errors.New("the memory and blkio cgroup controllers are required")
+	}
+	cpuUsage, err := readUint(cpuacct.file(r.cgroupRoot, "cpuacct.usage"))
+	if err != nil {
+		return err
+	}
+	stats.CPUUsage = time.Duration(cpuUsage)
+	if stats.MemoryUsage, err = readUint(memory.file(r.cgroupRoot, "memory.usage_in_bytes")); err != nil {
+		return err
+	}
+
+	ioServiceBytes, err := os.ReadFile(blkio.file(r.cgroupRoot, "blkio.throttle.io_service_bytes"))
+	if err != nil {
+		return err
+	}
+	// Lines are "major:minor operation N", and a last "Total N".
+	for _, line := range strings.Split(string(ioServiceBytes), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		n, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[1] {
+		case "Read":
+			stats.IOReadBytes += n
+		case "Write":
+			stats.IOWriteBytes += n
+		}
+	}
+	return nil
+}
+
+func readUint(file string) (uint64, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// readKeyedValues reads a file of "key value" lines.
+func readKeyedValues(file string) (map[string]uint64, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]uint64)
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if n, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			values[fields[0]] = n
+		}
+	}
+	return values, nil
+}
+
+// metricsHandler serves the latest workloadStats in the Prometheus text
+// format.
+type metricsHandler struct {
+	mu     sync.Mutex
+	latest *workloadStats
+}
+
+func (h *metricsHandler) update(stats workloadStats) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.latest = &stats
+}
+
+func (h *metricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	latest := h.latest
+	h.mu.Unlock()
+	if latest == nil {
+		http.Error(w, "no workload stats collected yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	for _, metric := range []struct {
+		name, help, kind string
+		value            string
+	}{
+		{"tee_workload_cpu_usage_seconds_total", "CPU time consumed by the workload.", "counter", strconv.FormatFloat(latest.CPUUsage.Seconds(), 'f', -1, 64)},
+		{"tee_workload_memory_usage_bytes", "Memory used by the workload.", "gauge", strconv.FormatUint(latest.MemoryUsage, 10)},
+		{"tee_workload_io_read_bytes_total", "Bytes read from block devices by the workload.", "counter", strconv.FormatUint(latest.IOReadBytes, 10)},
+		{"tee_workload_io_write_bytes_total", "Bytes written to block devices by the workload.", "counter", strconv.FormatUint(latest.IOWriteBytes, 10)},
+	} {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", metric.name, metric.help, metric.name, metric.kind, metric.name, metric.value)
+	}
+}
+
+// cloudMonitoringExporter writes workloadStats to Cloud Monitoring as custom
+// metrics of the gce_instance.
+type cloudMonitoringExporter struct {
+	service    *monitoring.Service
+	projectID  string
+	instanceID string
+	zone       string
+	// start is the start of the cumulative metrics.
+	start time.Time
+}
+
+func (e *cloudMonitoringExporter) export(ctx context.Context, stats workloadStats) error {
+	start := e.start.UTC().Format(time.RFC3339Nano)
+	end := stats.Time.UTC().Format(time.RFC3339Nano)
+	resource := &monitoring.MonitoredResource{
+		Type: "gce_instance",
+		Labels: map[string]string{
+			"project_id":  e.projectID,
+			"instance_id": e.instanceID,
+			"zone":        e.zone,
+		},
+	}
+	cpuSeconds := stats.CPUUsage.Seconds()
+	memory := int64(stats.MemoryUsage)
+	ioRead := int64(stats.IOReadBytes)
+	ioWrite := int64(stats.IOWriteBytes)
+	series := func(name, kind, unit string, value *monitoring.TypedValue) *monitoring.TimeSeries {
+		interval := &monitoring.TimeInterval{EndTime: end}
+		if kind == "CUMULATIVE" {
+			interval.StartTime = start
+		}
+		valueType := "INT64"
+		if value.DoubleValue != nil {
+			valueType = "DOUBLE"
+		}
+		return &monitoring.TimeSeries{
+			Metric:     &monitoring.Metric{Type: cloudMonitoringMetricPrefix + name},
+			Resource:   resource,
+			MetricKind: kind,
+			ValueType:  valueType,
+			Unit:       unit,
+			Points:     []*monitoring.Point{{Interval: interval, Value: value}},
+		}
+	}
+	request := &monitoring.CreateTimeSeriesRequest{
+		TimeSeries: []*monitoring.TimeSeries{
+			series("cpu_usage_time", "CUMULATIVE", "s", &monitoring.TypedValue{DoubleValue: &cpuSeconds}),
+			series("memory_usage", "GAUGE", "By", &monitoring.TypedValue{Int64Value: &memory}),
+			series("io_read_bytes", "CUMULATIVE", "By", &monitoring.TypedValue{Int64Value: &ioRead}),
+			series("io_write_bytes", "CUMULATIVE", "By", &monitoring.TypedValue{Int64Value: &ioWrite}),
+		},
+	}
+	_, err := e.service.Projects.TimeSeries.Create("projects/"+e.projectID, request).Context(ctx).Do()
+	return err
+}
+
+// newCloudMonitoringExporter returns an exporter to the Cloud Monitoring of
+// projectID, for the instance of the metadata server.
+func newCloudMonitoringExporter(ctx context.Context, mdsClient *metadata.Client, projectID string) (*cloudMonitoringExporter, error) {
+	httpClient, err := google.DefaultClient(ctx, monitoring.MonitoringWriteScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %v", err)
+	}
+	service, err := monitoring.NewService(ctx, option.WithHTTPClient(httpClient))
+	if err != nil {
+		return nil, err
+	}
+	instanceID, err := mdsClient.InstanceID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve instance ID from MDS: %v", err)
+	}
+	zone, err := mdsClient.Zone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve zone from MDS: %v", err)
+	}
+	return &cloudMonitoringExporter{service: service, projectID: projectID, instanceID: instanceID, zone: zone}, nil
+}
+
+// startMetrics serves and exports the stats of the workload process pid, as
+// enabled in the LaunchSpec, until ctx is cancelled.
+func (r *ContainerRunner) startMetrics(ctx context.Context, pid uint32) {
+	var handler *metricsHandler
+	if r.launchSpec.MetricsAddress != "" {
+		handler = &metricsHandler{}
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", handler)
+		server := &http.Server{Addr: r.launchSpec.MetricsAddress, Handler: mux}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				r.logger.Printf("failed to serve workload metrics: %v", err)
+			}
+		}()
+		go func() {
+			<-ctx.Done()
+			server.Close()
+		}()
+		r.logger.Printf("serving workload metrics on %s/metrics\n", r.launchSpec.MetricsAddress)
+	}
+	if r.metricsExporter != nil {
+		r.metricsExporter.start = time.Now()
+	}
+	go collectMetrics(ctx, pid, handler, r.metricsExporter, r.logger)
+}
+
+// collectMetrics collects the stats of the workload process pid every
+// metricsInterval until ctx is cancelled, and reports them to the handler and
+// the exporter if not nil. It only reads the cgroup filesystem, so the
+// measurements and the attestation are not affected.
+func collectMetrics(ctx context.Context, pid uint32, handler *metricsHandler, exporter *cloudMonitoringExporter, logger *log.Logger) {
+	ticker := time.NewTicker(metricsInterval)
+	defer ticker.Stop()
+	for {
+		if stats, err := defaultCgroupStatsReader.read(pid); err != nil {
+			logger.Printf("failed to read workload cgroup stats: %v", err)
+		} else {
+			if handler != nil {
+				handler.update(stats)
+			}
+			if exporter != nil {
+				if err := exporter.export(ctx, stats); err != nil {
+					logger.Printf("failed to export workload metrics to Cloud Monitoring: %v", err)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
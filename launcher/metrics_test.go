@@ -0,0 +1,159 @@
+package launcher
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/monitoring/v3"
+	"google.golang.org/api/option"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		file := path.Join(root, name)
+		if err := os.MkdirAll(path.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCgroupStatsReader(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+	}{
+		{
+			name: "CgroupV2",
+			files: map[string]string{
+				"proc/42/cgroup":                              "0::/default/tee-container\n",
+				"cgroup/default/tee-container/cpu.stat":       "usage_usec 1500000\nuser_usec 1000000\nsystem_usec 500000\n",
+				"cgroup/default/tee-container/memory.current": "4096\n",
+				"cgroup/default/tee-container/io.stat":        "8:0 rbytes=100 wbytes=200 rios=1 wios=2 dbytes=0 dios=0\n8:16 rbytes=1 wbytes=2 rios=1 wios=1 dbytes=0 dios=0\n",
+			},
+		},
+		{
+			name: "CgroupV1",
+			files: map[string]string{
+				"proc/42/cgroup": "12:memory:/default/tee-container\n" +
+					"4:cpu,cpuacct:/default/tee-container\n" +
+					"3:blkio:/default/tee-container\n" +
+					"0::/system.slice/containerd.service\n",
+				"cgroup/cpu,cpuacct/default/tee-container/cpuacct.usage":             "1500000000\n",
+				"cgroup/memory/default/tee-container/memory.usage_in_bytes":          "4096\n",
+				"cgroup/blkio/default/tee-container/blkio.throttle.io_service_bytes": "8:0 Read 100\n8:0 Write 200\n8:0 Sync 300\n8:0 Async 0\n8:0 Total 300\n8:16 Read 1\n8:16 Write 2\nTotal 303\n",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tc.files)
+			reader := cgroupStatsReader{procRoot: path.Join(root, "proc"), cgroupRoot: path.Join(root, "cgroup")}
+
+			stats, err := reader.read(42)
+			if err != nil {
+				t.Fatalf("read() failed: %v", err)
+			}
+			if stats.CPUUsage != 1500*time.Millisecond {
+				t.Errorf("got CPU usage %v, want 1.5s", stats.CPUUsage)
+			}
+			if stats.MemoryUsage != 4096 {
+				t.Errorf("got memory usage %d, want 4096", stats.MemoryUsage)
+			}
+			if stats.IOReadBytes != 101 || stats.IOWriteBytes != 202 {
+				t.Errorf("got IO read/write bytes %d/%d, want 101/202", stats.IOReadBytes, stats.IOWriteBytes)
+			}
+		})
+	}
+}
+
+func TestCgroupStatsReaderMissingProcess(t *testing.T) {
+	root := t.TempDir()
+	reader := cgroupStatsReader{procRoot: path.Join(root, "proc"), cgroupRoot: path.Join(root, "cgroup")}
+	if _, err := reader.read(42); err == nil {
+		t.Error("read() succeeded for a missing process")
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	handler := &metricsHandler{}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d before stats were collected, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	handler.update(workloadStats{CPUUsage: 1500 * time.Millisecond, MemoryUsage: 4096, IOReadBytes: 101, IOWriteBytes: 202})
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+	for _, want := range []string{
+		"# TYPE tee_workload_cpu_usage_seconds_total counter\ntee_workload_cpu_usage_seconds_total 1.5\n",
+		"# TYPE tee_workload_memory_usage_bytes gauge\ntee_workload_memory_usage_bytes 4096\n",
+		"tee_workload_io_read_bytes_total 101\n",
+		"tee_workload_io_write_bytes_total 202\n",
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("metrics %q do not contain %q", rec.Body.String(), want)
+		}
+	}
+}
+
+func TestCloudMonitoringExporter(t *testing.T) {
+	var gotPath string
+	var got monitoring.CreateTimeSeriesRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	service, err := monitoring.NewService(ctx, option.WithEndpoint(server.URL), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+	exporter := &cloudMonitoringExporter{service: service, projectID: "test-project", instanceID: "1234", zone: "us-central1-a", start: start}
+	stats := workloadStats{Time: start.Add(time.Minute), CPUUsage: 1500 * time.Millisecond, MemoryUsage: 4096, IOReadBytes: 101, IOWriteBytes: 202}
+	if err := exporter.export(ctx, stats); err != nil {
+		t.Fatalf("export() failed: %v", err)
+	}
+
+	if gotPath != "/v3/projects/test-project/timeSeries" {
+		t.Errorf("got request to %s, want /v3/projects/test-project/timeSeries", gotPath)
+	}
+	if len(got.TimeSeries) != 4 {
+		t.Fatalf("got %d time series, want 4", len(got.TimeSeries))
+	}
+	cpu := got.TimeSeries[0]
+	if cpu.Metric.Type != cloudMonitoringMetricPrefix+"cpu_usage_time" || cpu.MetricKind != "CUMULATIVE" || *cpu.Points[0].Value.DoubleValue != 1.5 {
+		t.Errorf("got CPU time series %+v", cpu)
+	}
+	if cpu.Points[0].Interval.StartTime != "2022-07-01T00:00:00Z" {
+		t.Errorf("got CPU interval start %s, want the start of the workload", cpu.Points[0].Interval.StartTime)
+	}
+	if cpu.Resource.Type != "gce_instance" || cpu.Resource.Labels["instance_id"] != "1234" {
+		t.Errorf("got monitored resource %+v", cpu.Resource)
+	}
+	memory := got.TimeSeries[1]
+	if memory.MetricKind != "GAUGE" || *memory.Points[0].Value.Int64Value != 4096 || memory.Points[0].Interval.StartTime != "" {
+		t.Errorf("got memory time series %+v", memory)
+	}
+}
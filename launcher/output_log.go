@@ -0,0 +1,117 @@
+package launcher
+
+import (
+	"context"
+	"crypto/sha256"
+	"hash"
+	"log"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/google/go-tpm-tools/cel"
+)
+
+const (
+	// hostOutputLogPath is the directory in the host storing the output log
+	// of the workload.
+	hostOutputLogPath = "/var/log/container_launcher/"
+	outputLogFile     = "workload_output.log"
+)
+
+// outputLogCheckpointInterval is how often the output log is checkpointed, if
+// the workload wrote to it since the last checkpoint.
+const outputLogCheckpointInterval = time.Minute
+
+// outputLog is an append-only log of the stdout and stderr of the workload.
+// Its checkpoints, the SHA-256 digest of the bytes written so far, are
+// measured as cel.WorkloadOutputType events, so an auditor can check a copy
+// of the log against an attested CEL.
+type outputLog struct {
+	measure func(cel.Content) error
+
+	mu   sync.Mutex
+	file *os.File
+	// hash is the running SHA-256 of the size bytes written to the log.
+	hash hash.Hash
+	size uint64
+	// checkpointed is the size of the log at the last checkpoint, and is -1
+	// before the first one.
+	checkpointed int64
+}
+
+// openOutputLog creates the output log in dir, replacing the log of a
+// previous boot, and measures its first, empty, checkpoint with measure.
+func openOutputLog(dir string, measure func(cel.Content) error) (*outputLog, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path.Join(dir, outputLogFile), os.O_WRONLY|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	l := &outputLog{measure: measure, file: file, hash: sha256.New(), checkpointed: -1}
+	if err := l.checkpoint(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// Write appends p to the log.
+func (l *outputLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n, err := l.file.Write(p)
+	l.hash.Write(p[:n])
+	l.size += uint64(n)
+	return n, err
+}
+
+// checkpoint measures the digest of the log, unless nothing was written to it
+// since the last checkpoint.
+func (l *outputLog) checkpoint() error {
+	// Writes wait for the measurement, so that the checkpoints are measured in
+	// the order of the log.
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if int64(l.size) == l.checkpointed {
+		return nil
+	}
+	content, err := cel.FormatWorkloadOutput(cel.WorkloadOutput{Size: l.size, Digest: l.hash.Sum(nil)})
+	if err != nil {
+		return err
+	}
+	if err := l.measure(cel.CosTlv{EventType: cel.WorkloadOutputType, EventContent: []byte(content)}); err != nil {
+		return err
+	}
+	l.checkpointed = int64(l.size)
+	return nil
+}
+
+// checkpointPeriodically checkpoints the log every
+// outputLogCheckpointInterval until ctx is cancelled.
+func (l *outputLog) checkpointPeriodically(ctx context.Context, logger *log.Logger) {
+	ticker := time.NewTicker(outputLogCheckpointInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := l.checkpoint(); err != nil {
+				logger.Printf("failed to checkpoint the workload output log: %v", err)
+			}
+		}
+	}
+}
+
+// Close checkpoints the log a last time and closes it.
+func (l *outputLog) Close() error {
+	err := l.checkpoint()
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+package launcher
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/google/go-tpm-tools/cel"
+)
+
+func TestOutputLog(t *testing.T) {
+	dir := t.TempDir()
+	var checkpoints []cel.WorkloadOutput
+	measure := func(content cel.Content) error {
+		event := content.(cel.CosTlv)
+		if event.EventType != cel.WorkloadOutputType {
+			t.Errorf("measured event type %v, want WorkloadOutputType", event.EventType)
+		}
+		checkpoint, err := cel.ParseWorkloadOutput(string(event.EventContent))
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkpoints = append(checkpoints, checkpoint)
+		return nil
+	}
+	// The log of a previous boot is replaced.
+	if err := os.WriteFile(path.Join(dir, outputLogFile), []byte("previous boot\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := openOutputLog(dir, measure)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.checkpoint(); err != nil {
+		t.Fatal(err)
+	}
+	// Nothing was written since the last checkpoint.
+	if err := l.checkpoint(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Write([]byte(" world")); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path.Join(dir, outputLogFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello world" {
+		t.Errorf("output log is %q, want %q", data, "hello world")
+	}
+	wantSizes := []uint64{0, 5, 11}
+	if len(checkpoints) != len(wantSizes) {
+		t.Fatalf("got %d checkpoints, want %d", len(checkpoints), len(wantSizes))
+	}
+	for i, checkpoint := range checkpoints {
+		if checkpoint.Size != wantSizes[i] {
+			t.Errorf("checkpoint %d covers %d bytes, want %d", i, checkpoint.Size, wantSizes[i])
+		}
+		if digest := sha256.Sum256(data[:wantSizes[i]]); !bytes.Equal(checkpoint.Digest, digest[:]) {
+			t.Errorf("checkpoint %d has digest %x, want %x", i, checkpoint.Digest, digest)
+		}
+	}
+}
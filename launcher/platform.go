@@ -0,0 +1,135 @@
+package launcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/platforms"
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/launcher/spec"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// readJSONFunc reads the JSON blob of desc into v.
+type readJSONFunc func(ctx context.Context, desc v1.Descriptor, v interface{}) error
+
+// platformMatcher returns the matcher of the platform the LaunchSpec pins the
+// image to, or of the platform of the VM if it is not pinned.
+func platformMatcher(launchSpec spec.LaunchSpec) (platforms.MatchComparer, error) {
+	if launchSpec.Platform == "" {
+		return platforms.Default(), nil
+	}
+	platform, err := platforms.Parse(launchSpec.Platform)
+	if err != nil {
+		return nil, err
+	}
+	return platforms.Only(platform), nil
+}
+
+// resolveManifest follows an image index (choosing the manifest for the
+// platform) to the image manifest, and returns the manifest and its
+// descriptor.
+func resolveManifest(ctx context.Context, readJSON readJSONFunc, desc v1.Descriptor, matcher platforms.MatchComparer) (v1.Descriptor, v1.Manifest, error) {
+	switch desc.MediaType {
+	case v1.MediaTypeImageIndex, images.MediaTypeDockerSchema2ManifestList:
+		var index v1.Index
+		if err := readJSON(ctx, desc, &index); err != nil {
+			return v1.Descriptor{}, v1.Manifest{}, fmt.Errorf("cannot read the image index: %w", err)
+		}
+		manifestDesc, err := selectManifest(index, matcher)
+		if err != nil {
+			return v1.Descriptor{}, v1.Manifest{}, err
+		}
+		return resolveManifest(ctx, readJSON, manifestDesc, matcher)
+	case v1.MediaTypeImageManifest, images.MediaTypeDockerSchema2Manifest:
+		var manifest v1.Manifest
+		if err := readJSON(ctx, desc, &manifest); err != nil {
+			return v1.Descriptor{}, v1.Manifest{}, fmt.Errorf("cannot read the image manifest: %w", err)
+		}
+		return desc, manifest, nil
+	}
+	return v1.Descriptor{}, v1.Manifest{}, fmt.Errorf("unknown image media type %s", desc.MediaType)
+}
+
+// selectManifest returns the manifest of the index for the platform of the
+// matcher. Like containerd, it prefers the platforms ordered first by the
+// matcher, and manifests without a platform last.
+func selectManifest(index v1.Index, matcher platforms.MatchComparer) (v1.Descriptor, error) {
+	var descs []v1.Descriptor
+	for _, desc := range index.Manifests {
+		if desc.Platform == nil || matcher.Match(*desc.Platform) {
+			descs = append(descs, desc)
+		}
+	}
+	if len(descs) == 0 {
+		return v1.Descriptor{}, fmt.Errorf("image index has no manifest for the platform")
+	}
+	sort.SliceStable(descs, func(i, j int) bool {
+		if descs[i].Platform == nil {
+			return false
+		}
+		if descs[j].Platform == nil {
+			return true
+		}
+		return matcher.Less(*descs[i].Platform, *descs[j].Platform)
+	})
+	return descs[0], nil
+}
+
+// imagePlatform returns the platform of an image config, e.g. linux/arm64.
+func imagePlatform(config v1.Image) string {
+	return platforms.Format(platforms.Normalize(v1.Platform{
+		OS:           config.OS,
+		Architecture: config.Architecture,
+		Variant:      config.Variant,
+	}))
+}
+
+// imagePlatformClaims returns the ImagePlatform and ImageManifestDigest events
+// of the image, whose manifest for the platform is manifestDesc. Verifiers
+// which do not know them reject the CEL, so they are only measured when they
+// add to the ImageDigest event: for an image index, or an image pinned to a
+// platform by the LaunchSpec.
+func imagePlatformClaims(launchSpec spec.LaunchSpec, target v1.Descriptor, manifestDesc v1.Descriptor, config v1.Image) []cel.CosTlv {
+	if launchSpec.Platform == "" && manifestDesc.Digest == target.Digest {
+		return nil
+	}
+	return []cel.CosTlv{
+		{EventType: cel.ImagePlatformType, EventContent: []byte(imagePlatform(config))},
+		{EventType: cel.ImageManifestDigestType, EventContent: []byte(manifestDesc.Digest)},
+	}
+}
+
+// checkImagePlatform returns an error if the LaunchSpec pins the image to a
+// platform the image config is not built for.
+func checkImagePlatform(launchSpec spec.LaunchSpec, config v1.Image) error {
+	if launchSpec.Platform == "" {
+		return nil
+	}
+	pinned, err := platforms.Parse(launchSpec.Platform)
+	if err != nil {
+		return err
+	}
+	// Unlike platformMatcher, only the exact platform is allowed, not the
+	// platforms it can run (e.g. linux/arm/v7 for linux/arm64).
+	platform := platforms.Normalize(v1.Platform{OS: config.OS, Architecture: config.Architecture, Variant: config.Variant})
+	if !platforms.NewMatcher(pinned).Match(platform) {
+		return fmt.Errorf("image is built for %s, not the pinned platform %s", platforms.Format(platform), launchSpec.Platform)
+	}
+	return nil
+}
+
+// readContentJSON returns a readJSONFunc reading blobs from the provider.
+func readContentJSON(provider content.Provider) readJSONFunc {
+	return func(ctx context.Context, desc v1.Descriptor, v interface{}) error {
+		data, err := content.ReadBlob(ctx, provider, desc)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, v)
+	}
+}
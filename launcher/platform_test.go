@@ -0,0 +1,105 @@
+package launcher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/containerd/containerd/platforms"
+	"github.com/google/go-tpm-tools/launcher/spec"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestResolveManifestPlatform(t *testing.T) {
+	fetcher := fakeFetcher{}
+	manifests := map[string]v1.Descriptor{}
+	index := v1.Index{}
+	for _, platform := range []v1.Platform{
+		{OS: "linux", Architecture: "amd64"},
+		{OS: "linux", Architecture: "arm", Variant: "v7"},
+		{OS: "linux", Architecture: "arm64", Variant: "v8"},
+	} {
+		platform := platform
+		configDesc := fetcher.add(t, v1.MediaTypeImageConfig, v1.Image{OS: platform.OS, Architecture: platform.Architecture, Variant: platform.Variant})
+		desc := fetcher.add(t, v1.MediaTypeImageManifest, v1.Manifest{Config: configDesc})
+		manifests[platforms.Format(platform)] = desc
+		desc.Platform = &platform
+		index.Manifests = append(index.Manifests, desc)
+	}
+	indexDesc := fetcher.add(t, v1.MediaTypeImageIndex, index)
+
+	for _, platform := range spec.SupportedPlatforms {
+		matcher, err := platformMatcher(spec.LaunchSpec{Platform: platform})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _, err := fetchManifest(context.Background(), fetcher, indexDesc, matcher)
+		if err != nil {
+			t.Fatalf("fetchManifest(%v) failed: %v", platform, err)
+		}
+		want := manifests[platform]
+		if platform == "linux/arm64" {
+			want = manifests["linux/arm64/v8"]
+		}
+		if got.Digest != want.Digest {
+			t.Errorf("fetchManifest(%v) returned manifest %v, want %v", platform, got.Digest, want.Digest)
+		}
+	}
+
+	matcher := platforms.Only(v1.Platform{OS: "windows", Architecture: "amd64"})
+	if _, _, err := fetchManifest(context.Background(), fetcher, indexDesc, matcher); err == nil {
+		t.Error("fetchManifest() succeeded for a platform missing from the index")
+	}
+}
+
+func TestCheckImagePlatform(t *testing.T) {
+	arm64 := v1.Image{OS: "linux", Architecture: "arm64"}
+	armv7 := v1.Image{OS: "linux", Architecture: "arm", Variant: "v7"}
+	amd64 := v1.Image{OS: "linux", Architecture: "amd64"}
+	tests := []struct {
+		name     string
+		platform string
+		config   v1.Image
+		wantErr  bool
+	}{
+		{"NotPinned", "", armv7, false},
+		{"Arm64", "linux/arm64", arm64, false},
+		{"Amd64", "linux/amd64", amd64, false},
+		{"Amd64ForArm64", "linux/arm64", amd64, true},
+		{"ArmV7ForArm64", "linux/arm64", armv7, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkImagePlatform(spec.LaunchSpec{Platform: test.platform}, test.config)
+			if (err != nil) != test.wantErr {
+				t.Errorf("checkImagePlatform(%q, %v) = %v, want error %v", test.platform, imagePlatform(test.config), err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestImagePlatformClaims(t *testing.T) {
+	manifest := v1.Descriptor{Digest: "sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945"}
+	index := v1.Descriptor{Digest: "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"}
+	config := v1.Image{OS: "linux", Architecture: "arm64"}
+	tests := []struct {
+		name       string
+		platform   string
+		target     v1.Descriptor
+		wantClaims bool
+	}{
+		{"Manifest", "", manifest, false},
+		{"Index", "", index, true},
+		{"PinnedManifest", "linux/arm64", manifest, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claims := imagePlatformClaims(spec.LaunchSpec{Platform: test.platform}, test.target, manifest, config)
+			if gotClaims := len(claims) > 0; gotClaims != test.wantClaims {
+				t.Fatalf("imagePlatformClaims() = %v, want claims %v", claims, test.wantClaims)
+			}
+			if test.wantClaims && (string(claims[0].EventContent) != "linux/arm64" || string(claims[1].EventContent) != string(manifest.Digest)) {
+				t.Errorf("imagePlatformClaims() = %v, want the platform and the manifest digest", claims)
+			}
+		})
+	}
+}
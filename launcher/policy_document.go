@@ -0,0 +1,102 @@
+package launcher
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/launcher/spec"
+)
+
+// maxPolicyDocumentSize is the maximum size of a signed launch policy
+// document.
+const maxPolicyDocumentSize = 1 << 20
+
+// fetchPolicyDocument fetches the signed launch policy document of the
+// LaunchSpec, and verifies it with the pinned keys.
+func fetchPolicyDocument(ctx context.Context, client *http.Client, launchSpec spec.LaunchSpec) (*spec.PolicyDocument, error) {
+	keys, err := spec.ParsePolicyKeys(launchSpec.LaunchPolicyKeys)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, launchSpec.LaunchPolicyURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, &RetryableError{fmt.Errorf("failed to fetch the launch policy document: %w", err)}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &RetryableError{fmt.Errorf("failed to fetch the launch policy document: %s", resp.Status)}
+	}
+	envelope, err := io.ReadAll(io.LimitReader(resp.Body, maxPolicyDocumentSize))
+	if err != nil {
+		return nil, &RetryableError{fmt.Errorf("failed to read the launch policy document: %w", err)}
+	}
+	return spec.VerifyPolicyDocument(envelope, keys)
+}
+
+// verifyPolicyDocument checks that the image and the container claims which
+// will be measured for the LaunchSpec satisfy the launch policy document.
+func verifyPolicyDocument(doc *spec.PolicyDocument, imageRef string, imageDigest string, launchSpec spec.LaunchSpec) error {
+	if !doc.AllowsImage(imageRef, imageDigest) {
+		return fmt.Errorf("image %s (%s) is not allowed by the launch policy document; allowed images: %v", imageRef, imageDigest, doc.AllowedImages)
+	}
+	claims, err := policyDocumentClaims(launchSpec)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(doc.RequiredClaims))
+	for name := range doc.RequiredClaims {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		want := doc.RequiredClaims[name]
+		got, ok := claims[name]
+		if !ok {
+			return fmt.Errorf("claim %s is not measured, the launch policy document requires %q", name, want)
+		}
+		if got != want {
+			return fmt.Errorf("claim %s is %q, the launch policy document requires %q", name, got, want)
+		}
+	}
+	return nil
+}
+
+// policyDocumentClaims returns the single-valued container claims of the
+// LaunchSpec, by the name of their COS event type.
+func policyDocumentClaims(launchSpec spec.LaunchSpec) (map[string]string, error) {
+	events, err := hardeningClaims(launchSpec)
+	if err != nil {
+		return nil, err
+	}
+	events = append(events, cel.CosTlv{EventType: cel.RestartPolicyType, EventContent: []byte(launchSpec.RestartPolicy)})
+	if launchSpec.WorkloadConfig != "" {
+		events = append(events, cel.CosTlv{EventType: cel.WorkloadConfigType, EventContent: []byte(workloadConfigDigest(launchSpec.WorkloadConfig))})
+	}
+	claims := make(map[string]string)
+	for _, event := range events {
+		switch event.EventType {
+		case cel.AddedCapabilityType, cel.MountType, cel.DeviceType:
+			// Can be measured more than once.
+			continue
+		}
+		claims[cosTypeNames[event.EventType]] = string(event.EventContent)
+	}
+	return claims, nil
+}
+
+// policyDocumentEvents returns the COS events identifying the launch policy
+// document.
+func policyDocumentEvents(doc *spec.PolicyDocument) []cel.CosTlv {
+	return []cel.CosTlv{
+		{EventType: cel.LaunchPolicyDocumentType, EventContent: []byte(doc.Digest)},
+		{EventType: cel.LaunchPolicySignerType, EventContent: []byte(doc.Signer)},
+	}
+}
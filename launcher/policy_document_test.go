@@ -0,0 +1,111 @@
+package launcher
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-tpm-tools/launcher/spec"
+)
+
+// signPolicyDocument returns the document in a DSSE envelope signed by key.
+func signPolicyDocument(t *testing.T, key *ecdsa.PrivateKey, doc spec.PolicyDocument) []byte {
+	t.Helper()
+	payload, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pae := fmt.Sprintf("DSSEv1 %d %s %d %s", len(spec.PolicyDocumentPayloadType), spec.PolicyDocumentPayloadType, len(payload), payload)
+	digest := sha256.Sum256([]byte(pae))
+	sig, err := key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := json.Marshal(map[string]interface{}{
+		"payloadType": spec.PolicyDocumentPayloadType,
+		"payload":     payload,
+		"signatures":  []map[string]interface{}{{"sig": sig}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return envelope
+}
+
+func TestFetchPolicyDocument(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope := signPolicyDocument(t, key, spec.PolicyDocument{AllowedImages: []string{"docker.io/library/hello-world"}})
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/policy.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(envelope)
+	}))
+	defer server.Close()
+
+	launchSpec := spec.LaunchSpec{
+		LaunchPolicyURL:  server.URL + "/policy.json",
+		LaunchPolicyKeys: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+	}
+	doc, err := fetchPolicyDocument(context.Background(), server.Client(), launchSpec)
+	if err != nil {
+		t.Fatalf("fetchPolicyDocument() failed: %v", err)
+	}
+	if len(doc.AllowedImages) != 1 || !strings.HasPrefix(doc.Digest, "sha256:") || !strings.HasPrefix(doc.Signer, "sha256:") {
+		t.Errorf("fetchPolicyDocument() = %+v, want the signed document", doc)
+	}
+
+	launchSpec.LaunchPolicyURL = server.URL + "/missing.json"
+	_, err = fetchPolicyDocument(context.Background(), server.Client(), launchSpec)
+	if _, ok := err.(*RetryableError); !ok {
+		t.Errorf("fetchPolicyDocument() of a missing document returned %v, want a RetryableError", err)
+	}
+}
+
+func TestVerifyPolicyDocumentClaims(t *testing.T) {
+	doc := &spec.PolicyDocument{
+		AllowedImages: []string{"docker.io/library/hello-world"},
+		RequiredClaims: map[string]string{
+			"ReadOnlyRootfs": "true",
+			"SeccompProfile": "runtime-default",
+		},
+	}
+	digest := "sha256:" + strings.Repeat("00", 32)
+	launchSpec := spec.LaunchSpec{
+		ReadOnlyRootfs: true,
+		SeccompProfile: spec.RuntimeDefaultProfile,
+	}
+	if err := verifyPolicyDocument(doc, "docker.io/library/hello-world:latest", digest, launchSpec); err != nil {
+		t.Errorf("verifyPolicyDocument() failed: %v", err)
+	}
+	if err := verifyPolicyDocument(doc, "docker.io/library/busybox:latest", digest, launchSpec); err == nil {
+		t.Error("verifyPolicyDocument() succeeded for an image which is not allowed")
+	}
+	launchSpec.ReadOnlyRootfs = false
+	if err := verifyPolicyDocument(doc, "docker.io/library/hello-world:latest", digest, launchSpec); err == nil {
+		t.Error("verifyPolicyDocument() succeeded for a LaunchSpec without a required claim")
+	}
+	doc.RequiredClaims = map[string]string{"User": "1000"}
+	if err := verifyPolicyDocument(doc, "docker.io/library/hello-world:latest", digest, launchSpec); err == nil {
+		t.Error("verifyPolicyDocument() succeeded for a required claim which is not measured")
+	}
+}
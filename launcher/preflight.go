@@ -0,0 +1,322 @@
+package launcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/containerd/containerd/reference/docker"
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/launcher/spec"
+	"github.com/google/go-tpm/tpm2"
+)
+
+const (
+	// defaultVerifierAddr is the Attestation Verifier used if the LaunchSpec
+	// has no AttestationServiceAddr.
+	defaultVerifierAddr = "https://confidentialcomputing.googleapis.com/"
+	// metadataServerURL is the metadata server, whose responses have the Date
+	// of its clock, synchronized by Google.
+	metadataServerURL = "http://metadata.google.internal/computeMetadata/v1/"
+	// containerdRoot is where containerd stores the images and snapshots.
+	containerdRoot = "/var/lib/containerd"
+	// minFreeDiskBytes is the free disk space needed to pull and unpack the
+	// image.
+	minFreeDiskBytes = 1 << 30
+	// maxClockSkew is the clock difference with the metadata server or the
+	// registry above which tokens and certificates may be rejected.
+	maxClockSkew = 5 * time.Minute
+	// preflightTimeout bounds each network check.
+	preflightTimeout = 30 * time.Second
+)
+
+// ContainerdChecker is implemented by *containerd.Client.
+type ContainerdChecker interface {
+	IsServing(ctx context.Context) (bool, error)
+}
+
+// PreflightEnv is the environment checked by Preflight.
+type PreflightEnv struct {
+	TPM        io.ReadWriter
+	Containerd ContainerdChecker
+	// HTTPClient is used to check the registry and verifier are reachable. It
+	// defaults to the egress client of the LaunchSpec.
+	HTTPClient *http.Client
+	// DiskPath is the path whose file system needs free space, containerd's
+	// root by default.
+	DiskPath string
+	// MetadataURL is checked for the reference clock, the metadata server by
+	// default.
+	MetadataURL string
+	// Now returns the local time, time.Now by default.
+	Now func() time.Time
+}
+
+// Values of ClockStatus.Source.
+const (
+	clockSourceMetadata   = "metadata"
+	clockSourceRegistry   = "registry"
+	clockSourceUnverified = "unverified"
+)
+
+// ClockStatus is the state of the clock of the VM at boot, measured into the
+// CEL so verifiers can reason about the freshness of the workload.
+type ClockStatus struct {
+	// BootTime is the local time when the preflight checks started.
+	BootTime time.Time `json:"bootTime"`
+	// Source is the clock the local clock was checked against: "metadata" for
+	// the metadata server, "registry" for the image registry, or "unverified"
+	// if neither sent its time, or the local clock is off (with
+	// spec.ClockSkewWarn).
+	Source string `json:"source"`
+	// Skew is the local time minus the time of the Source.
+	Skew time.Duration `json:"skew"`
+}
+
+// PreflightCheck is the result of one check of the preflight stage.
+type PreflightCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Error  string `json:"error,omitempty"`
+	// Warning is set instead of Error if the check failed, but the LaunchSpec
+	// allows to start the workload anyway. The check is not Passed, but does
+	// not fail the preflight stage.
+	Warning string `json:"warning,omitempty"`
+	// Remedy is what to do if the check failed.
+	Remedy string `json:"remedy,omitempty"`
+	// retryable checks can pass after a reboot.
+	retryable bool
+}
+
+// PreflightReport is the result of all the checks of the preflight stage.
+type PreflightReport struct {
+	Checks []PreflightCheck `json:"checks"`
+	Clock  ClockStatus      `json:"clock"`
+}
+
+// Err returns an error describing the failed checks and how to fix them, or
+// nil if all the checks passed. It is a RetryableError if all the failed
+// checks can pass after a reboot, e.g. transient network errors.
+func (r *PreflightReport) Err() error {
+	var msgs []string
+	retryable := true
+	for _, check := range r.Checks {
+		if check.Passed || check.Warning != "" {
+			continue
+		}
+		msgs = append(msgs, fmt.Sprintf("%s: %s (%s)", check.Name, check.Error, check.Remedy))
+		retryable = retryable && check.retryable
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	err := fmt.Errorf("preflight checks failed:\n%s", strings.Join(msgs, "\n"))
+	if retryable {
+		return &RetryableError{Err: err}
+	}
+	return err
+}
+
+// String returns the report as a single line of JSON, for logging.
+func (r *PreflightReport) String() string {
+	out, err := json.Marshal(r)
+	if err != nil {
+		return err.Error()
+	}
+	return string(out)
+}
+
+// Preflight validates the environment before any workload action: the TPM is
+// accessible, containerd is serving, the image registry and the verifier are
+// reachable, there is enough disk space and the clock is in sync. All the
+// checks run, so the report covers every problem at once.
+func Preflight(ctx context.Context, launchSpec spec.LaunchSpec, env PreflightEnv) *PreflightReport {
+	if env.HTTPClient == nil {
+		env.HTTPClient = newEgressClient(launchSpec)
+	}
+	if env.DiskPath == "" {
+		env.DiskPath = containerdRoot
+	}
+	if env.MetadataURL == "" {
+		env.MetadataURL = metadataServerURL
+	}
+	if env.Now == nil {
+		env.Now = time.Now
+	}
+
+	report := &PreflightReport{Clock: ClockStatus{BootTime: env.Now().UTC()}}
+	add := func(name string, err error, remedy string, retryable bool) {
+		check := PreflightCheck{Name: name, Passed: err == nil, retryable: retryable}
+		if err != nil {
+			check.Error = err.Error()
+			check.Remedy = remedy
+		}
+		report.Checks = append(report.Checks, check)
+	}
+
+	add("tpm", checkTPM(env.TPM),
+		"use a Shielded VM with the vTPM enabled", false)
+	add("containerd", checkContainerd(ctx, env.Containerd),
+		"check that the containerd service is running", true)
+
+	registryDate, err := checkRegistryReachable(ctx, env.HTTPClient, append([]string{launchSpec.ImageRef}, launchSpec.FallbackImageRefs...))
+	add("registry", err,
+		"check the firewall rules, proxy and DNS settings allow egress to the image registry", true)
+	_, err = checkReachable(ctx, env.HTTPClient, verifierURL(launchSpec))
+	add("verifier", err,
+		"check the firewall rules, proxy and DNS settings allow egress to the attestation verifier", true)
+
+	add("disk_space", checkDiskSpace(env.DiskPath, minFreeDiskBytes),
+		"use a larger boot disk, or a smaller image", false)
+
+	// The metadata server is reachable from all VMs, the registry clock is
+	// the fallback.
+	metadataDate, _ := checkReachable(ctx, env.HTTPClient, env.MetadataURL)
+	report.Clock.Source, err = checkClock(env.Now(), metadataDate, registryDate, maxClockSkew, &report.Clock.Skew)
+	add("time_sync", err,
+		"check that the VM synchronizes its clock with NTP", true)
+	if err != nil {
+		// The clock is measured as not validated against any source.
+		report.Clock.Source = clockSourceUnverified
+		if launchSpec.ClockSkewPolicy == spec.ClockSkewWarn {
+			check := &report.Checks[len(report.Checks)-1]
+			check.Warning, check.Error = check.Error, ""
+		}
+	}
+	return report
+}
+
+func checkTPM(rw io.ReadWriter) error {
+	if rw == nil {
+		return errors.New("no TPM")
+	}
+	if _, _, err := tpm2.GetCapability(rw, tpm2.CapabilityTPMProperties, 1, uint32(tpm2.Manufacturer)); err != nil {
+		return fmt.Errorf("failed to query the TPM: %v", err)
+	}
+	return nil
+}
+
+func checkContainerd(ctx context.Context, containerd ContainerdChecker) error {
+	if containerd == nil {
+		return errors.New("no containerd client")
+	}
+	serving, err := containerd.IsServing(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check containerd: %v", err)
+	}
+	if !serving {
+		return errors.New("containerd is not serving")
+	}
+	return nil
+}
+
+// checkReachable checks that the URL sends an HTTP response. Any response,
+// including an error status, shows the network path works. It returns the
+// Date of the response, if any.
+func checkReachable(ctx context.Context, client *http.Client, url string) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, preflightTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s is not reachable: %v", url, err)
+	}
+	resp.Body.Close()
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return time.Time{}, nil
+	}
+	return date, nil
+}
+
+// checkRegistryReachable checks that the registry of one of the images is
+// reachable, in order, as the image is pulled from the first registry which
+// serves it. It returns the Date of the response of that registry, if any.
+func checkRegistryReachable(ctx context.Context, client *http.Client, imageRefs []string) (time.Time, error) {
+	var errs []string
+	checked := make(map[string]bool)
+	for _, ref := range imageRefs {
+		url := registryURL(ref)
+		if checked[url] {
+			continue
+		}
+		checked[url] = true
+		date, err := checkReachable(ctx, client, url)
+		if err == nil {
+			return date, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return time.Time{}, errors.New(strings.Join(errs, "; "))
+}
+
+// registryURL returns the URL of the registry API of the image.
+func registryURL(imageRef string) string {
+	domain := "registry-1.docker.io"
+	if named, err := docker.ParseDockerRef(imageRef); err == nil && docker.Domain(named) != "docker.io" {
+		domain = docker.Domain(named)
+	}
+	return "https://" + domain + "/v2/"
+}
+
+func verifierURL(launchSpec spec.LaunchSpec) string {
+	if launchSpec.AttestationServiceAddr != "" {
+		return launchSpec.AttestationServiceAddr
+	}
+	return defaultVerifierAddr
+}
+
+func checkDiskSpace(path string, minFree uint64) error {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return fmt.Errorf("failed to get the free space of %s: %v", path, err)
+	}
+	if free := stat.Bavail * uint64(stat.Bsize); free < minFree {
+		return fmt.Errorf("%s has %d MiB free, want at least %d MiB", path, free>>20, minFree>>20)
+	}
+	return nil
+}
+
+// checkClock compares the local time with the Date of the metadata server
+// response, or else of the registry response, and sets skew. It returns the
+// source of the date compared with, and passes if there is none.
+func checkClock(now, metadataDate, registryDate time.Time, maxSkew time.Duration, skew *time.Duration) (string, error) {
+	source, serverDate := clockSourceMetadata, metadataDate
+	if serverDate.IsZero() {
+		source, serverDate = clockSourceRegistry, registryDate
+	}
+	if serverDate.IsZero() {
+		return clockSourceUnverified, nil
+	}
+	*skew = now.Sub(serverDate)
+	abs := *skew
+	if abs < 0 {
+		abs = -abs
+	}
+	// The Date header has a resolution of one second.
+	if abs > maxSkew+time.Second {
+		return source, fmt.Errorf("local clock is %v off the %s clock, want at most %v", skew.Round(time.Second), source, maxSkew)
+	}
+	return source, nil
+}
+
+// clockEvents returns the events measuring the boot time and clock source.
+func clockEvents(clock ClockStatus) []cel.CosTlv {
+	source := clock.Source
+	if source == "" {
+		source = clockSourceUnverified
+	}
+	return []cel.CosTlv{
+		{EventType: cel.BootTimeType, EventContent: []byte(clock.BootTime.UTC().Format(time.RFC3339))},
+		{EventType: cel.ClockSourceType, EventContent: []byte(source)},
+	}
+}
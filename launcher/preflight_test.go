@@ -0,0 +1,196 @@
+package launcher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm-tools/launcher/spec"
+)
+
+type fakeContainerd struct {
+	serving bool
+	err     error
+}
+
+func (c fakeContainerd) IsServing(context.Context) (bool, error) {
+	return c.serving, c.err
+}
+
+func TestPreflight(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	serverTime := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", serverTime.Format(http.TimeFormat))
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	launchSpec := spec.LaunchSpec{
+		ImageRef:               strings.TrimPrefix(server.URL, "https://") + "/workload:latest",
+		AttestationServiceAddr: server.URL,
+	}
+	env := PreflightEnv{
+		TPM:         tpm,
+		Containerd:  fakeContainerd{serving: true},
+		HTTPClient:  server.Client(),
+		DiskPath:    t.TempDir(),
+		MetadataURL: server.URL,
+		Now:         func() time.Time { return serverTime.Add(time.Minute) },
+	}
+
+	testCases := []struct {
+		name          string
+		update        func(*PreflightEnv, *spec.LaunchSpec)
+		wantFailed    []string
+		wantWarned    []string
+		wantRetryable bool
+	}{
+		{"AllPassed", func(*PreflightEnv, *spec.LaunchSpec) {}, nil, nil, false},
+		{"NoTPM", func(env *PreflightEnv, _ *spec.LaunchSpec) {
+			env.TPM = nil
+		}, []string{"tpm"}, nil, false},
+		{"ContainerdNotServing", func(env *PreflightEnv, _ *spec.LaunchSpec) {
+			env.Containerd = fakeContainerd{serving: false}
+		}, []string{"containerd"}, nil, true},
+		{"ContainerdError", func(env *PreflightEnv, _ *spec.LaunchSpec) {
+			env.Containerd = fakeContainerd{err: errors.New("connection refused")}
+		}, []string{"containerd"}, nil, true},
+		{"VerifierUnreachable", func(_ *PreflightEnv, launchSpec *spec.LaunchSpec) {
+			launchSpec.AttestationServiceAddr = "https://127.0.0.1:0/"
+		}, []string{"verifier"}, nil, true},
+		{"RegistryUnreachable", func(_ *PreflightEnv, launchSpec *spec.LaunchSpec) {
+			launchSpec.ImageRef = "127.0.0.1:0/workload:latest"
+		}, []string{"registry"}, nil, true},
+		{"FallbackRegistryReachable", func(_ *PreflightEnv, launchSpec *spec.LaunchSpec) {
+			launchSpec.FallbackImageRefs = []string{launchSpec.ImageRef}
+			launchSpec.ImageRef = "127.0.0.1:0/workload:latest"
+		}, nil, nil, false},
+		{"MissingDisk", func(env *PreflightEnv, _ *spec.LaunchSpec) {
+			env.DiskPath = "/nonexistent"
+		}, []string{"disk_space"}, nil, false},
+		{"ClockSkew", func(env *PreflightEnv, _ *spec.LaunchSpec) {
+			env.Now = func() time.Time { return serverTime.Add(-time.Hour) }
+		}, []string{"time_sync"}, nil, true},
+		{"ClockSkewWarn", func(env *PreflightEnv, launchSpec *spec.LaunchSpec) {
+			env.Now = func() time.Time { return serverTime.Add(-time.Hour) }
+			launchSpec.ClockSkewPolicy = spec.ClockSkewWarn
+		}, nil, []string{"time_sync"}, false},
+		{"RetryableAndFatal", func(env *PreflightEnv, _ *spec.LaunchSpec) {
+			env.TPM = nil
+			env.Containerd = fakeContainerd{serving: false}
+		}, []string{"tpm", "containerd"}, nil, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env, launchSpec := env, launchSpec
+			tc.update(&env, &launchSpec)
+			report := Preflight(context.Background(), launchSpec, env)
+
+			var failed, warned []string
+			for _, check := range report.Checks {
+				if check.Passed {
+					continue
+				}
+				if check.Warning != "" {
+					warned = append(warned, check.Name)
+				} else {
+					failed = append(failed, check.Name)
+				}
+				if check.Remedy == "" {
+					t.Errorf("check %s failed without a remedy", check.Name)
+				}
+			}
+			wantSource := clockSourceMetadata
+			if strings.HasPrefix(tc.name, "ClockSkew") {
+				wantSource = clockSourceUnverified
+			}
+			if report.Clock.Source != wantSource {
+				t.Errorf("got clock source %q, want %q", report.Clock.Source, wantSource)
+			}
+			if strings.Join(failed, ",") != strings.Join(tc.wantFailed, ",") {
+				t.Errorf("got failed checks %v, want %v: %v", failed, tc.wantFailed, report)
+			}
+			if strings.Join(warned, ",") != strings.Join(tc.wantWarned, ",") {
+				t.Errorf("got checks with warnings %v, want %v: %v", warned, tc.wantWarned, report)
+			}
+			err := report.Err()
+			if (err != nil) != (len(tc.wantFailed) > 0) {
+				t.Fatalf("Err() = %v, want error %v", err, len(tc.wantFailed) > 0)
+			}
+			var retryableErr *RetryableError
+			if gotRetryable := errors.As(err, &retryableErr); gotRetryable != tc.wantRetryable {
+				t.Errorf("Err() = %v, want retryable %v", err, tc.wantRetryable)
+			}
+		})
+	}
+}
+
+func TestRegistryURL(t *testing.T) {
+	testCases := []struct {
+		imageRef string
+		want     string
+	}{
+		{"alpine", "https://registry-1.docker.io/v2/"},
+		{"docker.io/library/alpine:latest", "https://registry-1.docker.io/v2/"},
+		{"us-docker.pkg.dev/project/repo/image@sha256:781d8dfdd92118436bd914442c8339e653b83f6bf3c1a7a98efcfb7c4fed7483", "https://us-docker.pkg.dev/v2/"},
+		{"localhost:5000/image", "https://localhost:5000/v2/"},
+	}
+	for _, tc := range testCases {
+		if got := registryURL(tc.imageRef); got != tc.want {
+			t.Errorf("registryURL(%q) = %q, want %q", tc.imageRef, got, tc.want)
+		}
+	}
+}
+
+func TestCheckClock(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name         string
+		metadataDate time.Time
+		registryDate time.Time
+		wantSource   string
+		wantSkew     time.Duration
+		wantErr      bool
+	}{
+		{"Metadata", now.Add(-time.Minute), now.Add(-time.Hour), clockSourceMetadata, time.Minute, false},
+		{"MetadataSkew", now.Add(time.Hour), time.Time{}, clockSourceMetadata, -time.Hour, true},
+		{"Registry", time.Time{}, now.Add(time.Minute), clockSourceRegistry, -time.Minute, false},
+		{"RegistrySkew", time.Time{}, now.Add(-time.Hour), clockSourceRegistry, time.Hour, true},
+		{"Unverified", time.Time{}, time.Time{}, clockSourceUnverified, 0, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var skew time.Duration
+			source, err := checkClock(now, tc.metadataDate, tc.registryDate, maxClockSkew, &skew)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("checkClock() got error %v, want error %v", err, tc.wantErr)
+			}
+			if source != tc.wantSource || skew != tc.wantSkew {
+				t.Errorf("checkClock() got source %q skew %v, want %q %v", source, skew, tc.wantSource, tc.wantSkew)
+			}
+		})
+	}
+}
+
+func TestClockEvents(t *testing.T) {
+	bootTime := time.Date(2022, 10, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	events := clockEvents(ClockStatus{BootTime: bootTime})
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	if got := string(events[0].EventContent); events[0].EventType != cel.BootTimeType || got != "2022-10-01T10:00:00Z" {
+		t.Errorf("got boot time event %v %q, want 2022-10-01T10:00:00Z", events[0].EventType, got)
+	}
+	if got := string(events[1].EventContent); events[1].EventType != cel.ClockSourceType || got != clockSourceUnverified {
+		t.Errorf("got clock source event %v %q, want %q", events[1].EventType, got, clockSourceUnverified)
+	}
+}
@@ -2,6 +2,7 @@ package spec
 
 import (
 	"fmt"
+	"path"
 	"strconv"
 	"strings"
 )
@@ -9,9 +10,31 @@ import (
 // LaunchPolicy contains policies on starting the container.
 // The policy comes from the labels of the image.
 type LaunchPolicy struct {
-	AllowedEnvOverride []string
-	AllowedCmdOverride bool
-	AllowedLogRedirect logRedirectPolicy
+	AllowedEnvOverride       []string
+	AllowedCmdOverride       bool
+	AllowedLogRedirect       logRedirectPolicy
+	DenyHostNetwork          bool
+	RequireReadOnlyRootfs    bool
+	AllowedCapabilities      []string
+	AllowedMountDestinations []string
+	AllowedDevices           []string
+	// AllowedCDIDevices are the fully qualified names (vendor.com/class=name)
+	// of the CDI devices the operator can inject into the container.
+	AllowedCDIDevices []string
+	// RequireNonRoot requires the operator to run the container as a non-root
+	// user, or in a user namespace.
+	RequireNonRoot bool
+	// SeccompProfile and AppArmorProfile are the profiles of the container,
+	// with the values of the LaunchSpec. The operator can only set the same
+	// profiles.
+	SeccompProfile  string
+	AppArmorProfile string
+	// RequireAttestBeforeRun requires the operator to start the workload only
+	// once it is attested.
+	RequireAttestBeforeRun bool
+	// AllowedApprovalURLs are the relying parties the operator can require
+	// an approval of the start of the workload from.
+	AllowedApprovalURLs []string
 }
 
 type logRedirectPolicy int
@@ -38,9 +61,20 @@ func toLogRedirectPolicy(s string) (logRedirectPolicy, error) {
 }
 
 const (
-	envOverride = "tee.launch_policy.allow_env_override"
-	cmdOverride = "tee.launch_policy.allow_cmd_override"
-	logRedirect = "tee.launch_policy.log_redirect"
+	envOverride              = "tee.launch_policy.allow_env_override"
+	cmdOverride              = "tee.launch_policy.allow_cmd_override"
+	logRedirect              = "tee.launch_policy.log_redirect"
+	denyHostNetwork          = "tee.launch_policy.deny_host_network"
+	requireReadOnlyRootfs    = "tee.launch_policy.require_read_only_rootfs"
+	allowedCapabilities      = "tee.launch_policy.allowed_capabilities"
+	allowedMountDestinations = "tee.launch_policy.allowed_mount_destinations"
+	allowedDevices           = "tee.launch_policy.allowed_devices"
+	allowedCDIDevices        = "tee.launch_policy.allowed_cdi_devices"
+	requireNonRoot           = "tee.launch_policy.require_non_root"
+	seccompProfile           = "tee.launch_policy.seccomp_profile"
+	appArmorProfile          = "tee.launch_policy.apparmor_profile"
+	requireAttestBeforeRun   = "tee.launch_policy.require_attest_before_run"
+	allowedApprovalURLs      = "tee.launch_policy.allowed_approval_urls"
 )
 
 // GetLaunchPolicy takes in a map[string] string which should come from image labels,
@@ -72,6 +106,86 @@ func GetLaunchPolicy(imageLabels map[string]string) (LaunchPolicy, error) {
 		}
 	}
 
+	if v, ok := imageLabels[denyHostNetwork]; ok {
+		if launchPolicy.DenyHostNetwork, err = strconv.ParseBool(v); err != nil {
+			return LaunchPolicy{}, fmt.Errorf("invalid image LABEL '%s' (not a boolean); contact the image author", denyHostNetwork)
+		}
+	}
+
+	if v, ok := imageLabels[requireReadOnlyRootfs]; ok {
+		if launchPolicy.RequireReadOnlyRootfs, err = strconv.ParseBool(v); err != nil {
+			return LaunchPolicy{}, fmt.Errorf("invalid image LABEL '%s' (not a boolean); contact the image author", requireReadOnlyRootfs)
+		}
+	}
+
+	if v, ok := imageLabels[allowedCapabilities]; ok {
+		for _, capability := range strings.Split(v, ",") {
+			if capability = strings.TrimSpace(capability); capability != "" {
+				launchPolicy.AllowedCapabilities = append(launchPolicy.AllowedCapabilities, strings.ToUpper(capability))
+			}
+		}
+	}
+
+	if v, ok := imageLabels[allowedMountDestinations]; ok {
+		for _, dest := range strings.Split(v, ",") {
+			if dest = strings.TrimSpace(dest); dest == "" {
+				continue
+			}
+			if !path.IsAbs(dest) {
+				return LaunchPolicy{}, fmt.Errorf("invalid image LABEL '%s' (%s is not an absolute path); contact the image author", allowedMountDestinations, dest)
+			}
+			launchPolicy.AllowedMountDestinations = append(launchPolicy.AllowedMountDestinations, path.Clean(dest))
+		}
+	}
+
+	if v, ok := imageLabels[allowedDevices]; ok {
+		for _, device := range strings.Split(v, ",") {
+			if device = strings.TrimSpace(device); device != "" {
+				launchPolicy.AllowedDevices = append(launchPolicy.AllowedDevices, device)
+			}
+		}
+	}
+
+	if v, ok := imageLabels[allowedCDIDevices]; ok {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			if _, _, err := ParseCDIDeviceName(name); err != nil {
+				return LaunchPolicy{}, fmt.Errorf("invalid image LABEL '%s' (%v); contact the image author", allowedCDIDevices, err)
+			}
+			launchPolicy.AllowedCDIDevices = append(launchPolicy.AllowedCDIDevices, name)
+		}
+	}
+
+	if v, ok := imageLabels[requireNonRoot]; ok {
+		if launchPolicy.RequireNonRoot, err = strconv.ParseBool(v); err != nil {
+			return LaunchPolicy{}, fmt.Errorf("invalid image LABEL '%s' (not a boolean); contact the image author", requireNonRoot)
+		}
+	}
+
+	if v, ok := imageLabels[seccompProfile]; ok {
+		if err := validateSeccompProfile(v); err != nil {
+			return LaunchPolicy{}, fmt.Errorf("invalid image LABEL '%s' (%v); contact the image author", seccompProfile, err)
+		}
+		launchPolicy.SeccompProfile = v
+	}
+	launchPolicy.AppArmorProfile = imageLabels[appArmorProfile]
+
+	if v, ok := imageLabels[requireAttestBeforeRun]; ok {
+		if launchPolicy.RequireAttestBeforeRun, err = strconv.ParseBool(v); err != nil {
+			return LaunchPolicy{}, fmt.Errorf("invalid image LABEL '%s' (not a boolean); contact the image author", requireAttestBeforeRun)
+		}
+	}
+
+	if v, ok := imageLabels[allowedApprovalURLs]; ok {
+		for _, approvalURL := range strings.Split(v, ",") {
+			if approvalURL = strings.TrimSpace(approvalURL); approvalURL != "" {
+				launchPolicy.AllowedApprovalURLs = append(launchPolicy.AllowedApprovalURLs, approvalURL)
+			}
+		}
+	}
+
 	return launchPolicy, nil
 }
 
@@ -95,9 +209,92 @@ func (p LaunchPolicy) Verify(ls LaunchSpec) error {
 		return fmt.Errorf("logging redirection only allowed on debug environment by image")
 	}
 
+	if p.DenyHostNetwork && ls.HostNetwork {
+		return fmt.Errorf("host network is not allowed by image")
+	}
+
+	if p.RequireReadOnlyRootfs && !ls.ReadOnlyRootfs {
+		return fmt.Errorf("read-only root filesystem is required by image")
+	}
+
+	for _, c := range ls.AddedCapabilities {
+		if !contains(p.AllowedCapabilities, c) {
+			return fmt.Errorf("capability %s is not allowed to be added on this image; allowed capabilities: %v", c, p.AllowedCapabilities)
+		}
+	}
+
+	for _, m := range ls.Mounts {
+		if !isUnderAny(p.AllowedMountDestinations, m.Destination) {
+			return fmt.Errorf("mount destination %s is not allowed on this image; allowed mount destinations: %v", m.Destination, p.AllowedMountDestinations)
+		}
+	}
+	// The tmpfs overlays of a read-only root filesystem are mounts too, but
+	// the default ones are always allowed.
+	for _, dest := range ls.ReadOnlyRootfsTmpfs {
+		if !contains(DefaultReadOnlyRootfsTmpfs, dest) && !isUnderAny(p.AllowedMountDestinations, dest) {
+			return fmt.Errorf("read-only root filesystem tmpfs %s is not allowed on this image; allowed mount destinations: %v", dest, p.AllowedMountDestinations)
+		}
+	}
+
+	for _, d := range ls.Devices {
+		if !contains(p.AllowedDevices, d) {
+			return fmt.Errorf("device %s is not allowed on this image; allowed devices: %v", d, p.AllowedDevices)
+		}
+	}
+
+	for _, d := range ls.CDIDevices {
+		if !contains(p.AllowedCDIDevices, d) {
+			return fmt.Errorf("CDI device %s is not allowed on this image; allowed CDI devices: %v", d, p.AllowedCDIDevices)
+		}
+	}
+
+	if p.RequireNonRoot && !ls.UserNamespace {
+		// The user was validated when parsing the LaunchSpec.
+		if uid, _, _, err := ParseUser(ls.User); ls.User == "" || err != nil || uid == 0 {
+			return fmt.Errorf("a non-root user or a user namespace is required by image")
+		}
+	}
+
+	if p.SeccompProfile != "" && ls.SeccompProfile != "" && ls.SeccompProfile != p.SeccompProfile {
+		return fmt.Errorf("seccomp profile is set by image and cannot be overridden")
+	}
+	if p.AppArmorProfile != "" && ls.AppArmorProfile != "" && ls.AppArmorProfile != p.AppArmorProfile {
+		return fmt.Errorf("AppArmor profile %s is set by image and cannot be overridden", p.AppArmorProfile)
+	}
+
+	if p.RequireAttestBeforeRun && !ls.AttestBeforeRun {
+		return fmt.Errorf("attest before run is required by image")
+	}
+	if ls.ApprovalURL != "" && !contains(p.AllowedApprovalURLs, ls.ApprovalURL) {
+		return fmt.Errorf("approval URL %s is not allowed on this image; allowed approval URLs: %v", ls.ApprovalURL, p.AllowedApprovalURLs)
+	}
+
 	return nil
 }
 
+// Apply returns the LaunchSpec with the settings of the image it leaves
+// unset, which should be verified first.
+func (p LaunchPolicy) Apply(ls LaunchSpec) LaunchSpec {
+	if ls.SeccompProfile == "" {
+		ls.SeccompProfile = p.SeccompProfile
+	}
+	if ls.AppArmorProfile == "" {
+		ls.AppArmorProfile = p.AppArmorProfile
+	}
+	return ls
+}
+
+// isUnderAny checks if target is one of the dirs, or inside one of them.
+func isUnderAny(dirs []string, target string) bool {
+	target = path.Clean(target)
+	for _, dir := range dirs {
+		if target == dir || strings.HasPrefix(target, strings.TrimSuffix(dir, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 func contains(strs []string, target string) bool {
 	for _, s := range strs {
 		if s == target {
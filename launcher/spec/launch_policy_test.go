@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/cel"
 )
 
 func TestLaunchPolicy(t *testing.T) {
@@ -52,6 +53,35 @@ func TestLaunchPolicy(t *testing.T) {
 				AllowedCmdOverride: false,
 			},
 		},
+		{
+			"hardening policies",
+			map[string]string{
+				denyHostNetwork:          "true",
+				requireReadOnlyRootfs:    "true",
+				allowedCapabilities:      "cap_net_admin, CAP_SYS_TIME,",
+				allowedMountDestinations: "/data/,/tmp",
+				allowedDevices:           "/dev/nvidia0, /dev/nvidiactl",
+				allowedCDIDevices:        "nvidia.com/gpu=0, nvidia.com/gpu=1",
+				requireNonRoot:           "true",
+				seccompProfile:           "runtime-default",
+				appArmorProfile:          "tee-workload",
+				requireAttestBeforeRun:   "true",
+				allowedApprovalURLs:      "https://approver.example.com, https://approver.example.org",
+			},
+			LaunchPolicy{
+				DenyHostNetwork:          true,
+				RequireReadOnlyRootfs:    true,
+				AllowedCapabilities:      []string{"CAP_NET_ADMIN", "CAP_SYS_TIME"},
+				AllowedMountDestinations: []string{"/data", "/tmp"},
+				AllowedDevices:           []string{"/dev/nvidia0", "/dev/nvidiactl"},
+				AllowedCDIDevices:        []string{"nvidia.com/gpu=0", "nvidia.com/gpu=1"},
+				RequireNonRoot:           true,
+				SeccompProfile:           RuntimeDefaultProfile,
+				AppArmorProfile:          "tee-workload",
+				RequireAttestBeforeRun:   true,
+				AllowedApprovalURLs:      []string{"https://approver.example.com", "https://approver.example.org"},
+			},
+		},
 	}
 
 	for _, testcase := range testCases {
@@ -247,6 +277,238 @@ func TestVerify(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"host network denied",
+			LaunchPolicy{
+				DenyHostNetwork: true,
+			},
+			LaunchSpec{
+				HostNetwork: true,
+			},
+			true,
+		},
+		{
+			"read-only rootfs required",
+			LaunchPolicy{
+				RequireReadOnlyRootfs: true,
+			},
+			LaunchSpec{
+				ReadOnlyRootfs: false,
+			},
+			true,
+		},
+		{
+			"hardening policies satisfied",
+			LaunchPolicy{
+				DenyHostNetwork:          true,
+				RequireReadOnlyRootfs:    true,
+				AllowedCapabilities:      []string{"CAP_NET_ADMIN"},
+				AllowedMountDestinations: []string{"/data"},
+				AllowedDevices:           []string{"/dev/nvidia0"},
+			},
+			LaunchSpec{
+				HostNetwork:       false,
+				ReadOnlyRootfs:    true,
+				AddedCapabilities: []string{"CAP_NET_ADMIN"},
+				Mounts: []cel.Mount{
+					{Type: cel.TmpfsMountType, Destination: "/data"},
+					{Type: cel.BindMountType, Source: "/mnt/disks/foo", Destination: "/data/foo"},
+				},
+				Devices: []string{"/dev/nvidia0"},
+			},
+			false,
+		},
+		{
+			"capability violation",
+			LaunchPolicy{
+				AllowedCapabilities: []string{"CAP_NET_ADMIN"},
+			},
+			LaunchSpec{
+				AddedCapabilities: []string{"CAP_SYS_ADMIN"},
+			},
+			true,
+		},
+		{
+			"mount destination violation",
+			LaunchPolicy{
+				AllowedMountDestinations: []string{"/data"},
+			},
+			LaunchSpec{
+				Mounts: []cel.Mount{{Type: cel.TmpfsMountType, Destination: "/database"}},
+			},
+			true,
+		},
+		{
+			"mount destination escape",
+			LaunchPolicy{
+				AllowedMountDestinations: []string{"/data"},
+			},
+			LaunchSpec{
+				Mounts: []cel.Mount{{Type: cel.TmpfsMountType, Destination: "/data/../etc"}},
+			},
+			true,
+		},
+		{
+			"non-root required",
+			LaunchPolicy{
+				RequireNonRoot: true,
+			},
+			LaunchSpec{},
+			true,
+		},
+		{
+			"root user with non-root required",
+			LaunchPolicy{
+				RequireNonRoot: true,
+			},
+			LaunchSpec{
+				User: "0:1000",
+			},
+			true,
+		},
+		{
+			"non-root user",
+			LaunchPolicy{
+				RequireNonRoot: true,
+			},
+			LaunchSpec{
+				User: "1000",
+			},
+			false,
+		},
+		{
+			"root user in user namespace",
+			LaunchPolicy{
+				RequireNonRoot: true,
+			},
+			LaunchSpec{
+				User:          "0",
+				UserNamespace: true,
+			},
+			false,
+		},
+		{
+			"seccomp profile of image",
+			LaunchPolicy{
+				SeccompProfile: RuntimeDefaultProfile,
+			},
+			LaunchSpec{
+				SeccompProfile: RuntimeDefaultProfile,
+			},
+			false,
+		},
+		{
+			"seccomp profile override",
+			LaunchPolicy{
+				SeccompProfile: RuntimeDefaultProfile,
+			},
+			LaunchSpec{
+				SeccompProfile: UnconfinedProfile,
+			},
+			true,
+		},
+		{
+			"AppArmor profile override",
+			LaunchPolicy{
+				AppArmorProfile: "tee-workload",
+			},
+			LaunchSpec{
+				AppArmorProfile: RuntimeDefaultProfile,
+			},
+			true,
+		},
+		{
+			"device violation",
+			LaunchPolicy{
+				AllowedDevices: []string{"/dev/nvidia0"},
+			},
+			LaunchSpec{
+				Devices: []string{"/dev/sda"},
+			},
+			true,
+		},
+		{
+			"default read-only rootfs tmpfs",
+			LaunchPolicy{
+				RequireReadOnlyRootfs: true,
+			},
+			LaunchSpec{
+				ReadOnlyRootfs:      true,
+				ReadOnlyRootfsTmpfs: DefaultReadOnlyRootfsTmpfs,
+			},
+			false,
+		},
+		{
+			"read-only rootfs tmpfs allowed",
+			LaunchPolicy{
+				AllowedMountDestinations: []string{"/var"},
+			},
+			LaunchSpec{
+				ReadOnlyRootfs:      true,
+				ReadOnlyRootfsTmpfs: []string{"/tmp", "/var/cache"},
+			},
+			false,
+		},
+		{
+			"read-only rootfs tmpfs violation",
+			LaunchPolicy{
+				AllowedMountDestinations: []string{"/data"},
+			},
+			LaunchSpec{
+				ReadOnlyRootfs:      true,
+				ReadOnlyRootfsTmpfs: []string{"/etc"},
+			},
+			true,
+		},
+		{
+			"CDI device allowed",
+			LaunchPolicy{
+				AllowedCDIDevices: []string{"nvidia.com/gpu=0"},
+			},
+			LaunchSpec{
+				CDIDevices: []string{"nvidia.com/gpu=0"},
+			},
+			false,
+		},
+		{
+			"CDI device violation",
+			LaunchPolicy{
+				AllowedDevices: []string{"/dev/nvidia1"},
+			},
+			LaunchSpec{
+				CDIDevices: []string{"nvidia.com/gpu=1"},
+			},
+			true,
+		},
+		{
+			"attest before run required",
+			LaunchPolicy{
+				RequireAttestBeforeRun: true,
+			},
+			LaunchSpec{},
+			true,
+		},
+		{
+			"approval URL allowed",
+			LaunchPolicy{
+				RequireAttestBeforeRun: true,
+				AllowedApprovalURLs:    []string{"https://approver.example.com"},
+			},
+			LaunchSpec{
+				AttestBeforeRun: true,
+				ApprovalURL:     "https://approver.example.com",
+			},
+			false,
+		},
+		{
+			"approval URL violation",
+			LaunchPolicy{},
+			LaunchSpec{
+				AttestBeforeRun: true,
+				ApprovalURL:     "https://approver.example.com",
+			},
+			true,
+		},
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.testName, func(t *testing.T) {
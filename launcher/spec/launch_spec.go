@@ -3,13 +3,22 @@
 package spec
 
 import (
+	"crypto"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"path"
 	"strconv"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/compute/metadata"
+	"github.com/containerd/containerd/reference/docker"
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/opencontainers/runtime-spec/specs-go"
 )
 
 // RestartPolicy is the enum for the container restart policy.
@@ -30,6 +39,100 @@ const (
 	Never     RestartPolicy = "Never"
 )
 
+// LogVerbosity is the enum for the verbosity of the launcher logs.
+type LogVerbosity string
+
+func (v LogVerbosity) isValid() error {
+	switch v {
+	case Info, Debug:
+		return nil
+	}
+	return fmt.Errorf("invalid log verbosity: %s", v)
+}
+
+// LogVerbosity enum values.
+const (
+	Info LogVerbosity = "info"
+	// Debug additionally logs every event measured by the launcher.
+	Debug LogVerbosity = "debug"
+)
+
+// ClockSkewPolicy is the enum for what the launcher does when the clock of
+// the VM is off.
+type ClockSkewPolicy string
+
+func (p ClockSkewPolicy) isValid() error {
+	switch p {
+	case ClockSkewEnforce, ClockSkewWarn:
+		return nil
+	}
+	return fmt.Errorf("invalid clock skew policy: %s", p)
+}
+
+// ClockSkewPolicy enum values.
+const (
+	// ClockSkewEnforce refuses to start the workload.
+	ClockSkewEnforce ClockSkewPolicy = "enforce"
+	// ClockSkewWarn logs a warning and starts the workload.
+	ClockSkewWarn ClockSkewPolicy = "warn"
+)
+
+// TokenDelivery is the enum for how the attestation tokens are delivered to
+// the workload. The empty value is TokenDeliveryFile.
+type TokenDelivery string
+
+func (d TokenDelivery) isValid() error {
+	switch d {
+	case "", TokenDeliveryFile, TokenDeliverySocket, TokenDeliveryFileAndSocket:
+		return nil
+	}
+	return fmt.Errorf("invalid token delivery: %s", d)
+}
+
+// File reports whether the launcher writes a refreshed token to a file of the
+// token mount.
+func (d TokenDelivery) File() bool {
+	return d != TokenDeliverySocket
+}
+
+// Socket reports whether the launcher serves tokens with the nonces of the
+// workload on a socket of the token mount.
+func (d TokenDelivery) Socket() bool {
+	return d == TokenDeliverySocket || d == TokenDeliveryFileAndSocket
+}
+
+// TokenDelivery enum values.
+const (
+	TokenDeliveryFile TokenDelivery = "file"
+	// TokenDeliverySocket never writes the tokens to the disk: the workload
+	// requests them on the socket, and receives them in the response.
+	TokenDeliverySocket        TokenDelivery = "socket"
+	TokenDeliveryFileAndSocket TokenDelivery = "file-and-socket"
+)
+
+// TokenWatchdog is the enum for what the launcher does when the PCRs change
+// after the workload started, invalidating the issued tokens. The empty value
+// does nothing.
+type TokenWatchdog string
+
+func (w TokenWatchdog) isValid() error {
+	switch w {
+	case "", WatchdogReattest, WatchdogRevoke:
+		return nil
+	}
+	return fmt.Errorf("invalid token watchdog: %s", w)
+}
+
+// TokenWatchdog enum values.
+const (
+	// WatchdogReattest replaces the token file with a token attesting the
+	// new PCRs.
+	WatchdogReattest TokenWatchdog = "reattest"
+	// WatchdogRevoke deletes the token file and the GCP access token, and no
+	// longer writes tokens.
+	WatchdogRevoke TokenWatchdog = "revoke"
+)
+
 // Metadata variable names.
 const (
 	imageRefKey                = "tee-image-reference"
@@ -39,6 +142,73 @@ const (
 	impersonateServiceAccounts = "tee-impersonate-service-accounts"
 	attestationServiceAddrKey  = "tee-attestation-service-endpoint"
 	logRedirectKey             = "tee-container-log-redirect"
+	hostNetworkKey             = "tee-host-network"
+	readOnlyRootfsKey          = "tee-read-only-rootfs"
+	addedCapabilitiesKey       = "tee-added-capabilities"
+	mountsKey                  = "tee-mounts"
+	gcsWorkloadIdentityKey     = "tee-gcs-workload-identity-provider"
+	gcsServiceAccountKey       = "tee-gcs-service-account"
+	devicesKey                 = "tee-devices"
+	fallbackImageRefsKey       = "tee-fallback-image-references"
+	tokenAudiencesKey          = "tee-token-audiences"
+	logVerbosityKey            = "tee-log-verbosity"
+	workloadKeyKey             = "tee-workload-key"
+	caBundleKey                = "tee-ca-bundle"
+	httpProxyKey               = "tee-http-proxy"
+	httpsProxyKey              = "tee-https-proxy"
+	noProxyKey                 = "tee-no-proxy"
+	workloadConfigKey          = "tee-workload-config"
+	metricsAddressKey          = "tee-metrics-address"
+	cloudMonitoringKey         = "tee-metrics-cloud-monitoring"
+	userKey                    = "tee-user"
+	userNamespaceKey           = "tee-user-namespace"
+	seccompProfileKey          = "tee-seccomp-profile"
+	appArmorProfileKey         = "tee-apparmor-profile"
+	launchPolicyURLKey         = "tee-launch-policy-url"
+	launchPolicyKeysKey        = "tee-launch-policy-keys"
+	clockSkewPolicyKey         = "tee-clock-skew-policy"
+	evidenceCollectorsKey      = "tee-evidence-collectors"
+	attestationRegionsKey      = "tee-attestation-service-regions"
+	attestationHedgeDelayKey   = "tee-attestation-service-hedge-delay"
+	workloadOutputLogKey       = "tee-workload-output-log"
+	celHashAlgorithmsKey       = "tee-cel-hash-algorithms"
+	measureCELHeaderKey        = "tee-measure-cel-header"
+	imagePlatformKey           = "tee-image-platform"
+	attestBeforeRunKey         = "tee-attest-before-run"
+	approvalURLKey             = "tee-attest-approval-url"
+	tokenTmpfsSizeKey          = "tee-token-tmpfs-size-mib"
+	tokenTmpfsModeKey          = "tee-token-tmpfs-mode"
+	tokenDeliveryKey           = "tee-token-delivery"
+	tokenWatchdogKey           = "tee-token-watchdog"
+	measureKernelModulesKey    = "tee-measure-kernel-modules"
+	measureKernelLogKey        = "tee-measure-kernel-log"
+	cdiDevicesKey              = "tee-cdi-devices"
+	gcpWorkloadIdentityKey     = "tee-gcp-workload-identity-provider"
+	gcpServiceAccountKey       = "tee-gcp-service-account"
+	gcpScopesKey               = "tee-gcp-scopes"
+	readOnlyRootfsTmpfsKey     = "tee-read-only-rootfs-tmpfs"
+)
+
+// DefaultGCPScope is the scope of the GCP access tokens of the workload if the
+// LaunchSpec sets no GCPScopes.
+const DefaultGCPScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// DefaultReadOnlyRootfsTmpfs are the writable tmpfs overlays of a read-only
+// root filesystem if the LaunchSpec sets no ReadOnlyRootfsTmpfs.
+var DefaultReadOnlyRootfsTmpfs = []string{"/tmp", "/var/run"}
+
+// noReadOnlyRootfsTmpfs is the value of readOnlyRootfsTmpfsKey disabling the
+// tmpfs overlays.
+const noReadOnlyRootfsTmpfs = "none"
+
+// Values of the SeccompProfile and AppArmorProfile of a LaunchSpec, besides
+// a custom seccomp profile and the name of an AppArmor profile of the host.
+const (
+	// UnconfinedProfile is the default, the container runs without a
+	// profile.
+	UnconfinedProfile = "unconfined"
+	// RuntimeDefaultProfile is the default profile of containerd.
+	RuntimeDefaultProfile = "runtime-default"
 )
 
 const (
@@ -67,8 +237,173 @@ type LaunchSpec struct {
 	Region                     string
 	Hardened                   bool
 	LogRedirect                bool
+	HostNetwork                bool
+	ReadOnlyRootfs             bool
+	AddedCapabilities          []string
+	Mounts                     []cel.Mount
+	// GCSWorkloadIdentityProvider is the Workload Identity Federation provider
+	// (projects/*/locations/global/workloadIdentityPools/*/providers/*) which
+	// exchanges the attestation token for credentials to read the buckets of
+	// gcs mounts. GCSServiceAccount is impersonated with these credentials if
+	// set.
+	GCSWorkloadIdentityProvider string
+	GCSServiceAccount           string
+	// Devices are the host paths of the devices exposed to the container.
+	Devices []string
+	// FallbackImageRefs are pulled in order if ImageRef cannot be pulled, e.g.
+	// mirrors of the image in other registries.
+	FallbackImageRefs []string
+	// TokenAudiences are the audiences requested for the attestation token,
+	// instead of the default audience of the attestation service. The
+	// attestation service issues tokens for a single audience.
+	TokenAudiences []string
+	LogVerbosity   LogVerbosity
+	// WorkloadKey generates an ephemeral key pair for the workload at every
+	// boot, measured and certified by the attestation key.
+	WorkloadKey bool
+	// CABundle are PEM certificates trusted in addition to the system roots
+	// for all the egress of the launcher: image pulls, verifier calls and
+	// token fetches. This is needed by networks intercepting TLS traffic,
+	// which also lets them read the attestation tokens.
+	CABundle string
+	// HTTPProxy, HTTPSProxy and NoProxy have the meaning of the HTTP_PROXY,
+	// HTTPS_PROXY and NO_PROXY environment variables for all the egress of the
+	// launcher. The metadata server is never proxied.
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+	// WorkloadConfig is an opaque configuration for the workload, mounted into
+	// the container. Its digest is measured, so tokens are bound to it.
+	WorkloadConfig string
+	// MetricsAddress is the host address serving the CPU, memory and IO stats
+	// of the workload in the Prometheus text format, e.g. ":9100". The stats
+	// are not served if empty.
+	MetricsAddress string
+	// CloudMonitoring exports the workload stats to Cloud Monitoring as
+	// custom metrics of the instance.
+	CloudMonitoring bool
+	// User overrides the user of the image the container process runs as,
+	// "UID" or "UID:GID". Names are not supported, as resolving them needs
+	// the /etc/passwd of the image.
+	User string
+	// UserNamespace runs the container in a user namespace, mapping its users
+	// to unprivileged users of the host, so that root in the container is not
+	// root on the host.
+	UserNamespace bool
+	// SeccompProfile is UnconfinedProfile, RuntimeDefaultProfile or a custom
+	// seccomp profile in the JSON format of the OCI runtime spec. If empty,
+	// the profile of the image launch policy is used.
+	SeccompProfile string
+	// AppArmorProfile is UnconfinedProfile, RuntimeDefaultProfile or the
+	// name of a profile loaded on the host. If empty, the profile of the
+	// image launch policy is used.
+	AppArmorProfile string
+	// LaunchPolicyURL is the https:// URL of a launch policy document in a
+	// DSSE envelope, signed by one of the PEM public keys of
+	// LaunchPolicyKeys. It is fetched at boot and enforced before starting
+	// the workload.
+	LaunchPolicyURL  string
+	LaunchPolicyKeys string
+	// ClockSkewPolicy is what to do when the clock of the VM is off from the
+	// metadata server or registry clock at boot. Tokens with a skewed expiry
+	// may be rejected, or accepted for too long.
+	ClockSkewPolicy ClockSkewPolicy
+	// EvidenceCollectors are the names of the agent collectors adding
+	// evidence to the attestations, e.g. "ak_cert_chain", "tee", "ima" or
+	// "gce_identity".
+	// The default collectors of the agent are used if empty.
+	EvidenceCollectors []string
+	// AttestationServiceRegions are the regions of the attestation service
+	// to use instead of Region, in order of preference. A region is skipped
+	// for a while after it failed a request.
+	AttestationServiceRegions []string
+	// AttestationServiceHedgeDelay, if non-zero, attests again with the next
+	// of the AttestationServiceRegions when an attestation has no response
+	// after the delay.
+	AttestationServiceHedgeDelay time.Duration
+	// WorkloadOutputLog captures the stdout and stderr of the container to an
+	// append-only log on the host, whose digest is periodically measured
+	// into the CEL. The checkpoints are measured after the launch separator,
+	// so the attestation verifier must accept cel.WorkloadOutputType events.
+	WorkloadOutputLog bool
+	// CELHashAlgorithms are the PCR banks the agent extends the CEL events
+	// into, e.g. SHA-256 and SHA-384 for verifiers only trusting SHA-384
+	// quotes. The default banks of the agent are used if empty.
+	CELHashAlgorithms []crypto.Hash
+	// MeasureCELHeader measures the header of the CEL, the
+	// cel.HashAlgorithmsType and cel.SchemaVersionType events, so verifiers
+	// knowing it accept the event types of newer schemas. It is always
+	// measured with non-default CELHashAlgorithms.
+	MeasureCELHeader bool
+	// Platform pins the platform of the image, one of SupportedPlatforms. It
+	// selects the manifest of a multi-arch image, and the launcher fails if
+	// the image is built for another platform. The platform of the VM is
+	// used if empty.
+	Platform string
+	// AttestBeforeRun only starts the workload once an attestation token is
+	// fetched, retrying until one is, instead of failing the launch. If
+	// ApprovalURL is set, the relying party must also approve the start.
+	AttestBeforeRun bool
+	// ApprovalURL is the https:// URL of a relying party approving the start
+	// of the workload in the AttestBeforeRun mode. The launcher GETs a nonce
+	// from it, then POSTs a token for the ApprovalURL audience including the
+	// nonce, and it must respond 200 OK. The tokens of the workload are never
+	// sent to it.
+	ApprovalURL string
+	// TokenTmpfsSizeMiB is the size limit, in MiB, of the tmpfs the launcher
+	// mounts on the host directory of the attestation token and artifacts.
+	// The launcher default is used if zero.
+	TokenTmpfsSizeMiB uint64
+	// TokenTmpfsMode is the permission mode of the root of the token tmpfs.
+	// It cannot be writable by group or others. The launcher default is used
+	// if zero.
+	TokenTmpfsMode os.FileMode
+	// TokenDelivery is how the attestation tokens are delivered to the
+	// workload. Workloads receiving them only on the socket cannot mount gcs
+	// buckets, which use the token file.
+	TokenDelivery TokenDelivery
+	// TokenWatchdog watches the PCRs once the workload started, e.g. for
+	// kernel modules loaded and measured by IMA, and reattests or revokes the
+	// token file when they change, instead of waiting for its expiry.
+	TokenWatchdog TokenWatchdog
+	// MeasureKernelModules measures the kernel modules loaded while the
+	// launcher runs into the CEL, as cel.KernelModuleType events in
+	// cel.KernelModulePCR. The launcher adds client.KernelModuleIMAPolicy to
+	// the IMA policy of the host, which must be writable, and reads the
+	// modules from the IMA runtime measurement list. The CEL events are only
+	// backed by the IMA measurements in client.KernelModuleIMAPCR when the
+	// attestation also has the IMA log, which the verifier replays.
+	MeasureKernelModules bool
+	// MeasureKernelLog measures the digest of the kernel log ring buffer
+	// (dmesg) and the taint flags of the kernel before the workload starts,
+	// as a cel.KernelLogType event, so verifiers can detect kernel warnings.
+	MeasureKernelLog bool
+	// CDIDevices are the fully qualified names (vendor.com/class=name) of
+	// Container Device Interface devices injected into the container. They
+	// are resolved from the CDI specs of the host, and the host paths of
+	// their device nodes are measured with their names. The launch policy of
+	// the image must allow them.
+	CDIDevices []string
+	// GCPWorkloadIdentityProvider is the Workload Identity Federation
+	// provider which exchanges the attestation token for the GCP credentials
+	// of the workload, like GCSWorkloadIdentityProvider. GCPServiceAccount is
+	// impersonated with these credentials if set. The access tokens are
+	// scoped to GCPScopes, DefaultGCPScope if unset.
+	GCPWorkloadIdentityProvider string
+	GCPServiceAccount           string
+	GCPScopes                   []string
+	// ReadOnlyRootfsTmpfs are the destinations of the tmpfs overlays mounted
+	// over a read-only root filesystem, for the well-known paths the
+	// workload writes to, DefaultReadOnlyRootfsTmpfs if unset. They are
+	// measured like tmpfs Mounts, and the destinations of the Mounts are not
+	// overlaid. The launch policy of the image must allow the destinations
+	// other than the default ones, like those of the Mounts.
+	ReadOnlyRootfsTmpfs []string
 }
 
+// SupportedPlatforms are the platforms a LaunchSpec can pin its image to.
+var SupportedPlatforms = []string{"linux/amd64", "linux/arm64"}
+
 // UnmarshalJSON unmarshals an instance attributes list in JSON format from the metadata
 // server set by an operator to a LaunchSpec.
 func (s *LaunchSpec) UnmarshalJSON(b []byte) error {
@@ -81,6 +416,19 @@ func (s *LaunchSpec) UnmarshalJSON(b []byte) error {
 	if s.ImageRef == "" {
 		return errImageRefNotSpecified
 	}
+	if err := validateImageRef(s.ImageRef); err != nil {
+		return err
+	}
+
+	if val, ok := unmarshaledMap[fallbackImageRefsKey]; ok && val != "" {
+		for _, ref := range strings.Split(val, ",") {
+			ref = strings.TrimSpace(ref)
+			if err := validateImageRef(ref); err != nil {
+				return err
+			}
+			s.FallbackImageRefs = append(s.FallbackImageRefs, ref)
+		}
+	}
 
 	s.RestartPolicy = RestartPolicy(unmarshaledMap[restartPolicyKey])
 	// set the default restart policy to "Never" for now
@@ -119,8 +467,425 @@ func (s *LaunchSpec) UnmarshalJSON(b []byte) error {
 		s.LogRedirect = logRedirect
 	}
 
+	// by default the container uses the host network
+	s.HostNetwork = true
+	if val, ok := unmarshaledMap[hostNetworkKey]; ok && val != "" {
+		hostNetwork, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		s.HostNetwork = hostNetwork
+	}
+
+	if val, ok := unmarshaledMap[readOnlyRootfsKey]; ok && val != "" {
+		readOnlyRootfs, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		s.ReadOnlyRootfs = readOnlyRootfs
+	}
+
+	if val, ok := unmarshaledMap[readOnlyRootfsTmpfsKey]; ok && val != "" {
+		if !s.ReadOnlyRootfs {
+			return fmt.Errorf("%s requires %s", readOnlyRootfsTmpfsKey, readOnlyRootfsKey)
+		}
+		if val != noReadOnlyRootfsTmpfs {
+			for _, dest := range strings.Split(val, ",") {
+				dest = strings.TrimSpace(dest)
+				if _, err := cel.FormatMount(cel.Mount{Type: cel.TmpfsMountType, Destination: dest}); err != nil {
+					return fmt.Errorf("invalid %s: %v", readOnlyRootfsTmpfsKey, err)
+				}
+				s.ReadOnlyRootfsTmpfs = append(s.ReadOnlyRootfsTmpfs, path.Clean(dest))
+			}
+		}
+	} else if s.ReadOnlyRootfs {
+		s.ReadOnlyRootfsTmpfs = DefaultReadOnlyRootfsTmpfs
+	}
+
+	if val, ok := unmarshaledMap[addedCapabilitiesKey]; ok && val != "" {
+		for _, capability := range strings.Split(val, ",") {
+			s.AddedCapabilities = append(s.AddedCapabilities, strings.ToUpper(strings.TrimSpace(capability)))
+		}
+	}
+
+	// mounts are separated by ';', see cel.ParseMount for the mount format
+	if val, ok := unmarshaledMap[mountsKey]; ok && val != "" {
+		for _, m := range strings.Split(val, ";") {
+			mount, err := cel.ParseMount(m)
+			if err != nil {
+				return err
+			}
+			s.Mounts = append(s.Mounts, mount)
+		}
+	}
+
+	s.GCSWorkloadIdentityProvider = unmarshaledMap[gcsWorkloadIdentityKey]
+	s.GCSServiceAccount = unmarshaledMap[gcsServiceAccountKey]
+	for _, m := range s.Mounts {
+		if m.Type == cel.GCSMountType && s.GCSWorkloadIdentityProvider == "" {
+			return fmt.Errorf("%s is required to mount bucket %s", gcsWorkloadIdentityKey, m.Source)
+		}
+	}
+
+	if val, ok := unmarshaledMap[devicesKey]; ok && val != "" {
+		for _, device := range strings.Split(val, ",") {
+			device = strings.TrimSpace(device)
+			if path.Clean(device) != device || !strings.HasPrefix(device, "/dev/") {
+				return fmt.Errorf("invalid device %q in %s, must be a path under /dev/", device, devicesKey)
+			}
+			s.Devices = append(s.Devices, device)
+		}
+	}
+
+	if val, ok := unmarshaledMap[tokenAudiencesKey]; ok && val != "" {
+		for _, audience := range strings.Split(val, ",") {
+			if audience = strings.TrimSpace(audience); audience == "" {
+				return fmt.Errorf("empty audience in %s", tokenAudiencesKey)
+			}
+			s.TokenAudiences = append(s.TokenAudiences, audience)
+		}
+		if len(s.TokenAudiences) > 1 {
+			return fmt.Errorf("got %d audiences in %s, the attestation service issues tokens for a single audience", len(s.TokenAudiences), tokenAudiencesKey)
+		}
+	}
+
+	if val, ok := unmarshaledMap[evidenceCollectorsKey]; ok && val != "" {
+		for _, collector := range strings.Split(val, ",") {
+			if collector = strings.TrimSpace(collector); collector == "" {
+				return fmt.Errorf("empty collector in %s", evidenceCollectorsKey)
+			}
+			s.EvidenceCollectors = append(s.EvidenceCollectors, collector)
+		}
+	}
+
+	if val, ok := unmarshaledMap[workloadKeyKey]; ok && val != "" {
+		workloadKey, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		s.WorkloadKey = workloadKey
+	}
+
+	s.CABundle = unmarshaledMap[caBundleKey]
+	if s.CABundle != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(s.CABundle)) {
+		return fmt.Errorf("%s has no valid PEM certificate", caBundleKey)
+	}
+
+	s.HTTPProxy = unmarshaledMap[httpProxyKey]
+	s.HTTPSProxy = unmarshaledMap[httpsProxyKey]
+	for key, proxy := range map[string]string{httpProxyKey: s.HTTPProxy, httpsProxyKey: s.HTTPSProxy} {
+		if err := validateProxy(proxy); err != nil {
+			return fmt.Errorf("invalid %s: %v", key, err)
+		}
+	}
+	s.NoProxy = unmarshaledMap[noProxyKey]
+
+	s.WorkloadConfig = unmarshaledMap[workloadConfigKey]
+
+	s.MetricsAddress = unmarshaledMap[metricsAddressKey]
+	if s.MetricsAddress != "" {
+		if _, _, err := net.SplitHostPort(s.MetricsAddress); err != nil {
+			return fmt.Errorf("invalid %s: %v", metricsAddressKey, err)
+		}
+	}
+	if val, ok := unmarshaledMap[cloudMonitoringKey]; ok && val != "" {
+		cloudMonitoring, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		s.CloudMonitoring = cloudMonitoring
+	}
+
+	s.User = unmarshaledMap[userKey]
+	if s.User != "" {
+		if _, _, _, err := ParseUser(s.User); err != nil {
+			return fmt.Errorf("invalid %s: %v", userKey, err)
+		}
+	}
+	if val, ok := unmarshaledMap[userNamespaceKey]; ok && val != "" {
+		userNamespace, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		s.UserNamespace = userNamespace
+	}
+
+	s.SeccompProfile = unmarshaledMap[seccompProfileKey]
+	if err := validateSeccompProfile(s.SeccompProfile); err != nil {
+		return fmt.Errorf("invalid %s: %v", seccompProfileKey, err)
+	}
+	s.AppArmorProfile = unmarshaledMap[appArmorProfileKey]
+
+	s.LaunchPolicyURL = unmarshaledMap[launchPolicyURLKey]
+	s.LaunchPolicyKeys = unmarshaledMap[launchPolicyKeysKey]
+	if s.LaunchPolicyURL != "" {
+		if u, err := url.Parse(s.LaunchPolicyURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("invalid %s: %q is not an https:// URL", launchPolicyURLKey, s.LaunchPolicyURL)
+		}
+		if _, err := ParsePolicyKeys(s.LaunchPolicyKeys); err != nil {
+			return fmt.Errorf("invalid %s: %v", launchPolicyKeysKey, err)
+		}
+	}
+
+	s.LogVerbosity = LogVerbosity(strings.ToLower(unmarshaledMap[logVerbosityKey]))
+	if s.LogVerbosity == "" {
+		s.LogVerbosity = Info
+	}
+	if err := s.LogVerbosity.isValid(); err != nil {
+		return err
+	}
+
+	s.ClockSkewPolicy = ClockSkewPolicy(strings.ToLower(unmarshaledMap[clockSkewPolicyKey]))
+	if s.ClockSkewPolicy == "" {
+		s.ClockSkewPolicy = ClockSkewEnforce
+	}
+	if err := s.ClockSkewPolicy.isValid(); err != nil {
+		return err
+	}
+
 	s.AttestationServiceAddr = unmarshaledMap[attestationServiceAddrKey]
 
+	if val, ok := unmarshaledMap[attestationRegionsKey]; ok && val != "" {
+		for _, region := range strings.Split(val, ",") {
+			if region = strings.TrimSpace(region); region == "" {
+				return fmt.Errorf("empty region in %s", attestationRegionsKey)
+			}
+			s.AttestationServiceRegions = append(s.AttestationServiceRegions, region)
+		}
+	}
+
+	if val, ok := unmarshaledMap[attestationHedgeDelayKey]; ok && val != "" {
+		delay, err := time.ParseDuration(val)
+		if err != nil || delay < 0 {
+			return fmt.Errorf("invalid %s: %q is not a non-negative duration", attestationHedgeDelayKey, val)
+		}
+		s.AttestationServiceHedgeDelay = delay
+	}
+
+	if val, ok := unmarshaledMap[workloadOutputLogKey]; ok && val != "" {
+		workloadOutputLog, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		s.WorkloadOutputLog = workloadOutputLog
+	}
+
+	if val, ok := unmarshaledMap[measureKernelModulesKey]; ok && val != "" {
+		measureKernelModules, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		s.MeasureKernelModules = measureKernelModules
+	}
+
+	if val, ok := unmarshaledMap[measureKernelLogKey]; ok && val != "" {
+		measureKernelLog, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		s.MeasureKernelLog = measureKernelLog
+	}
+
+	if val, ok := unmarshaledMap[cdiDevicesKey]; ok && val != "" {
+		for _, name := range strings.Split(val, ",") {
+			name = strings.TrimSpace(name)
+			if _, _, err := ParseCDIDeviceName(name); err != nil {
+				return fmt.Errorf("invalid device in %s: %v", cdiDevicesKey, err)
+			}
+			s.CDIDevices = append(s.CDIDevices, name)
+		}
+	}
+
+	s.GCPWorkloadIdentityProvider = unmarshaledMap[gcpWorkloadIdentityKey]
+	s.GCPServiceAccount = unmarshaledMap[gcpServiceAccountKey]
+	if val, ok := unmarshaledMap[gcpScopesKey]; ok && val != "" {
+		for _, scope := range strings.Split(val, ",") {
+			s.GCPScopes = append(s.GCPScopes, strings.TrimSpace(scope))
+		}
+	}
+	if s.GCPWorkloadIdentityProvider == "" && (s.GCPServiceAccount != "" || len(s.GCPScopes) > 0) {
+		return fmt.Errorf("%s and %s require %s", gcpServiceAccountKey, gcpScopesKey, gcpWorkloadIdentityKey)
+	}
+	if s.GCPWorkloadIdentityProvider != "" && len(s.GCPScopes) == 0 {
+		s.GCPScopes = []string{DefaultGCPScope}
+	}
+
+	if val, ok := unmarshaledMap[celHashAlgorithmsKey]; ok && val != "" {
+		hashAlgos, err := cel.ParseHashAlgorithms(val)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", celHashAlgorithmsKey, err)
+		}
+		s.CELHashAlgorithms = hashAlgos
+	}
+
+	if val, ok := unmarshaledMap[measureCELHeaderKey]; ok && val != "" {
+		measureCELHeader, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		s.MeasureCELHeader = measureCELHeader
+	}
+
+	if val, ok := unmarshaledMap[imagePlatformKey]; ok && val != "" {
+		supported := false
+		for _, platform := range SupportedPlatforms {
+			supported = supported || val == platform
+		}
+		if !supported {
+			return fmt.Errorf("invalid %s %q, expected one of %v", imagePlatformKey, val, SupportedPlatforms)
+		}
+		s.Platform = val
+	}
+
+	if val, ok := unmarshaledMap[attestBeforeRunKey]; ok && val != "" {
+		attestBeforeRun, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		s.AttestBeforeRun = attestBeforeRun
+	}
+	s.ApprovalURL = unmarshaledMap[approvalURLKey]
+	if s.ApprovalURL != "" {
+		if u, err := url.Parse(s.ApprovalURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("invalid %s: %q is not an https:// URL", approvalURLKey, s.ApprovalURL)
+		}
+		if !s.AttestBeforeRun {
+			return fmt.Errorf("%s requires %s", approvalURLKey, attestBeforeRunKey)
+		}
+	}
+
+	if val, ok := unmarshaledMap[tokenTmpfsSizeKey]; ok && val != "" {
+		size, err := strconv.ParseUint(val, 10, 32)
+		if err != nil || size == 0 {
+			return fmt.Errorf("invalid %s: %q is not a positive number of MiB", tokenTmpfsSizeKey, val)
+		}
+		s.TokenTmpfsSizeMiB = size
+	}
+
+	if val, ok := unmarshaledMap[tokenTmpfsModeKey]; ok && val != "" {
+		mode, err := strconv.ParseUint(val, 8, 32)
+		if err != nil || mode&^0777 != 0 {
+			return fmt.Errorf("invalid %s: %q is not an octal permission mode", tokenTmpfsModeKey, val)
+		}
+		if mode&0022 != 0 {
+			return fmt.Errorf("invalid %s: %q is writable by group or others", tokenTmpfsModeKey, val)
+		}
+		s.TokenTmpfsMode = os.FileMode(mode)
+	}
+
+	s.TokenDelivery = TokenDelivery(strings.ToLower(unmarshaledMap[tokenDeliveryKey]))
+	if err := s.TokenDelivery.isValid(); err != nil {
+		return err
+	}
+	s.TokenWatchdog = TokenWatchdog(strings.ToLower(unmarshaledMap[tokenWatchdogKey]))
+	if err := s.TokenWatchdog.isValid(); err != nil {
+		return err
+	}
+
+	if !s.TokenDelivery.File() {
+		if s.TokenWatchdog != "" {
+			return fmt.Errorf("%s requires the token file, not %s %s", tokenWatchdogKey, tokenDeliveryKey, s.TokenDelivery)
+		}
+		if s.GCPWorkloadIdentityProvider != "" {
+			return fmt.Errorf("%s requires the token file, not %s %s", gcpWorkloadIdentityKey, tokenDeliveryKey, s.TokenDelivery)
+		}
+		for _, m := range s.Mounts {
+			if m.Type == cel.GCSMountType {
+				return fmt.Errorf("mounting bucket %s requires the token file, not %s %s", m.Source, tokenDeliveryKey, s.TokenDelivery)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ParseUser parses a "UID" or "UID:GID" user. hasGID is false if the user has
+// no GID, in which case the GID of the image is kept.
+func ParseUser(user string) (uid uint32, gid uint32, hasGID bool, err error) {
+	uidStr, gidStr, hasGID := strings.Cut(user, ":")
+	uid64, err := strconv.ParseUint(uidStr, 10, 32)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("user %q: UID is not a number", user)
+	}
+	if !hasGID {
+		return uint32(uid64), 0, false, nil
+	}
+	gid64, err := strconv.ParseUint(gidStr, 10, 32)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("user %q: GID is not a number", user)
+	}
+	return uint32(uid64), uint32(gid64), true, nil
+}
+
+// ParseCDIDeviceName parses the fully qualified name of a CDI device,
+// "vendor.com/class=name", into its kind ("vendor.com/class") and its name,
+// following the naming rules of the Container Device Interface.
+func ParseCDIDeviceName(qualified string) (kind string, name string, err error) {
+	kind, name, ok := strings.Cut(qualified, "=")
+	if !ok {
+		return "", "", fmt.Errorf("CDI device %q is not vendor.com/class=name", qualified)
+	}
+	vendor, class, ok := strings.Cut(kind, "/")
+	if !ok {
+		return "", "", fmt.Errorf("CDI device %q is not vendor.com/class=name", qualified)
+	}
+	if !isCDIName(vendor, "-_.") || !isCDIName(class, "-_") || !isCDIName(name, "-_.:") {
+		return "", "", fmt.Errorf("CDI device %q has an invalid vendor, class or name", qualified)
+	}
+	return kind, name, nil
+}
+
+// isCDIName reports whether s is made of letters, digits and the extra
+// characters, and starts and ends with a letter or a digit.
+func isCDIName(s string, extra string) bool {
+	isAlnum := func(c byte) bool {
+		return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+	}
+	if s == "" || !isAlnum(s[0]) || !isAlnum(s[len(s)-1]) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isAlnum(s[i]) && !strings.ContainsRune(extra, rune(s[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateSeccompProfile checks that profile is empty, UnconfinedProfile,
+// RuntimeDefaultProfile or a valid custom seccomp profile.
+func validateSeccompProfile(profile string) error {
+	if profile == "" || profile == UnconfinedProfile || profile == RuntimeDefaultProfile {
+		return nil
+	}
+	var seccomp specs.LinuxSeccomp
+	if err := json.Unmarshal([]byte(profile), &seccomp); err != nil {
+		return fmt.Errorf("seccomp profile is not %s, %s or a JSON profile: %v", UnconfinedProfile, RuntimeDefaultProfile, err)
+	}
+	return nil
+}
+
+// validateImageRef checks that ref is a valid image reference, so that typos
+// are reported before pulling the image.
+func validateImageRef(ref string) error {
+	if _, err := docker.ParseDockerRef(ref); err != nil {
+		return fmt.Errorf("invalid image reference %q: %v", ref, err)
+	}
+	return nil
+}
+
+// validateProxy checks that proxy is empty or the URL of an HTTP(S) proxy.
+func validateProxy(proxy string) error {
+	if proxy == "" {
+		return nil
+	}
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an http:// or https:// URL", proxy)
+	}
 	return nil
 }
 
@@ -0,0 +1,194 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Launch spec file only variable names. On GCE, these values come from the
+// metadata server instead.
+const (
+	projectIDKey = "tee-project-id"
+	regionKey    = "tee-region"
+)
+
+// envOverridePrefix is the prefix of environment variables which override
+// values in a launch spec file. For example, TEE_RESTART_POLICY overrides
+// tee-restart-policy and TEE_ENV_foo overrides tee-env-foo.
+const envOverridePrefix = "TEE_"
+
+// fileSpecKeys are the fields allowed in a launch spec file, in addition to
+// fields starting with envKeyPrefix.
+var fileSpecKeys = map[string]bool{
+	imageRefKey:                true,
+	restartPolicyKey:           true,
+	cmdKey:                     true,
+	impersonateServiceAccounts: true,
+	attestationServiceAddrKey:  true,
+	logRedirectKey:             true,
+	hostNetworkKey:             true,
+	readOnlyRootfsKey:          true,
+	addedCapabilitiesKey:       true,
+	mountsKey:                  true,
+	gcsWorkloadIdentityKey:     true,
+	gcsServiceAccountKey:       true,
+	devicesKey:                 true,
+	fallbackImageRefsKey:       true,
+	tokenAudiencesKey:          true,
+	logVerbosityKey:            true,
+	workloadKeyKey:             true,
+	caBundleKey:                true,
+	httpProxyKey:               true,
+	httpsProxyKey:              true,
+	noProxyKey:                 true,
+	workloadConfigKey:          true,
+	metricsAddressKey:          true,
+	cloudMonitoringKey:         true,
+	userKey:                    true,
+	userNamespaceKey:           true,
+	seccompProfileKey:          true,
+	appArmorProfileKey:         true,
+	launchPolicyURLKey:         true,
+	launchPolicyKeysKey:        true,
+	clockSkewPolicyKey:         true,
+	evidenceCollectorsKey:      true,
+	attestationRegionsKey:      true,
+	attestationHedgeDelayKey:   true,
+	workloadOutputLogKey:       true,
+	celHashAlgorithmsKey:       true,
+	measureCELHeaderKey:        true,
+	imagePlatformKey:           true,
+	attestBeforeRunKey:         true,
+	approvalURLKey:             true,
+	tokenTmpfsSizeKey:          true,
+	tokenTmpfsModeKey:          true,
+	tokenDeliveryKey:           true,
+	tokenWatchdogKey:           true,
+	measureKernelModulesKey:    true,
+	measureKernelLogKey:        true,
+	cdiDevicesKey:              true,
+	gcpWorkloadIdentityKey:     true,
+	gcpServiceAccountKey:       true,
+	gcpScopesKey:               true,
+	readOnlyRootfsTmpfsKey:     true,
+	projectIDKey:               true,
+	regionKey:                  true,
+}
+
+// GetLaunchSpecFromFile reads and parses a launch spec file, for running the
+// launcher outside of GCE. The file is a YAML (or JSON) object using the same
+// field names as the GCE instance custom metadata, plus tee-project-id and
+// tee-region. tee-cmd, tee-impersonate-service-accounts,
+// tee-added-capabilities, tee-mounts, tee-devices, tee-cdi-devices,
+// tee-fallback-image-references and tee-token-audiences can also be given as
+// lists. Unknown
+// fields are rejected. Any field can be overridden by an environment variable
+// (see envOverridePrefix).
+func GetLaunchSpecFromFile(path string) (LaunchSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return LaunchSpec{}, err
+	}
+	spec, err := parseLaunchSpecFile(data, os.Environ())
+	if err != nil {
+		return LaunchSpec{}, fmt.Errorf("invalid launch spec file %s: %w", path, err)
+	}
+
+	kernelCmd, err := readCmdline()
+	if err != nil {
+		return LaunchSpec{}, err
+	}
+	spec.Hardened = isHardened(kernelCmd)
+
+	return spec, nil
+}
+
+func parseLaunchSpecFile(data []byte, environ []string) (LaunchSpec, error) {
+	var fields map[string]interface{}
+	if err := yaml.Unmarshal(data, &fields); err != nil {
+		return LaunchSpec{}, err
+	}
+
+	attributes := make(map[string]string, len(fields))
+	for k, v := range fields {
+		if !fileSpecKeys[k] && !strings.HasPrefix(k, envKeyPrefix) {
+			return LaunchSpec{}, fmt.Errorf("unknown field %s", k)
+		}
+		value, err := attributeValue(k, v)
+		if err != nil {
+			return LaunchSpec{}, err
+		}
+		attributes[k] = value
+	}
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, envOverridePrefix) {
+			continue
+		}
+		if key, ok := overriddenKey(name); ok {
+			attributes[key] = value
+		}
+	}
+
+	// Reuse the metadata parsing, so the file has the same semantics.
+	b, err := json.Marshal(attributes)
+	if err != nil {
+		return LaunchSpec{}, err
+	}
+	spec := LaunchSpec{}
+	if err := spec.UnmarshalJSON(b); err != nil {
+		return LaunchSpec{}, err
+	}
+	// Make the env var order deterministic, as it is measured.
+	sort.SliceStable(spec.Envs, func(i, j int) bool { return spec.Envs[i].Name < spec.Envs[j].Name })
+
+	spec.ProjectID = attributes[projectIDKey]
+	spec.Region = attributes[regionKey]
+	return spec, nil
+}
+
+// attributeValue converts a launch spec file value into its metadata string
+// representation.
+func attributeValue(key string, v interface{}) (string, error) {
+	switch val := v.(type) {
+	case string:
+		return val, nil
+	case bool, int, float64:
+		return fmt.Sprint(val), nil
+	case []interface{}:
+		strs := make([]string, len(val))
+		for i, elem := range val {
+			s, ok := elem.(string)
+			if !ok {
+				return "", fmt.Errorf("field %s must be a list of strings", key)
+			}
+			strs[i] = s
+		}
+		switch key {
+		case cmdKey:
+			b, err := json.Marshal(strs)
+			return string(b), err
+		case impersonateServiceAccounts, addedCapabilitiesKey, devicesKey, cdiDevicesKey, gcpScopesKey, readOnlyRootfsTmpfsKey, fallbackImageRefsKey, tokenAudiencesKey, evidenceCollectorsKey, attestationRegionsKey, celHashAlgorithmsKey:
+			return strings.Join(strs, ","), nil
+		case mountsKey:
+			return strings.Join(strs, ";"), nil
+		}
+	}
+	return "", fmt.Errorf("field %s has an unsupported type %T", key, v)
+}
+
+// overriddenKey returns the launch spec field overridden by the environment
+// variable name, if any.
+func overriddenKey(name string) (string, bool) {
+	envPrefix := strings.ToUpper(strings.ReplaceAll(envKeyPrefix, "-", "_"))
+	if strings.HasPrefix(name, envPrefix) && len(name) > len(envPrefix) {
+		return envKeyPrefix + strings.TrimPrefix(name, envPrefix), true
+	}
+	key := strings.ToLower(strings.ReplaceAll(name, "_", "-"))
+	return key, fileSpecKeys[key]
+}
@@ -0,0 +1,225 @@
+package spec
+
+import (
+	"crypto"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/cel"
+)
+
+func TestParseLaunchSpecFile(t *testing.T) {
+	var testCases = []struct {
+		testName string
+		file     string
+	}{
+		{
+			"YAML",
+			`
+tee-image-reference: docker.io/library/hello-world:latest
+tee-restart-policy: Always
+tee-cmd: ["--foo", "--bar"]
+tee-env-foo: bar
+tee-impersonate-service-accounts:
+  - sv1@developer.gserviceaccount.com
+  - sv2@developer.gserviceaccount.com
+tee-container-log-redirect: true
+tee-read-only-rootfs: true
+tee-read-only-rootfs-tmpfs: [/tmp, /var/run, /var/cache/app]
+tee-added-capabilities: [cap_net_admin]
+tee-mounts:
+  - type=tmpfs,destination=/tmp
+  - type=bind,source=/mnt/data,destination=/data,readonly=true
+tee-devices: [/dev/nvidia0]
+tee-fallback-image-references: [mirror.gcr.io/library/hello-world:latest]
+tee-token-audiences: [https://example.com]
+tee-log-verbosity: debug
+tee-clock-skew-policy: warn
+tee-evidence-collectors: [tee, ima]
+tee-attestation-service-regions: [us-central1, europe-west4]
+tee-attestation-service-hedge-delay: 2s
+tee-workload-key: true
+tee-workload-output-log: true
+tee-cel-hash-algorithms: [sha256, sha384]
+tee-measure-cel-header: true
+tee-image-platform: linux/arm64
+tee-attest-before-run: true
+tee-attest-approval-url: https://approver.example.com/approve
+tee-token-tmpfs-size-mib: 8
+tee-token-tmpfs-mode: "0750"
+tee-token-delivery: file-and-socket
+tee-token-watchdog: reattest
+tee-measure-kernel-modules: true
+tee-measure-kernel-log: true
+tee-cdi-devices: [nvidia.com/gpu=0, nvidia.com/gpu=1]
+tee-gcp-workload-identity-provider: projects/123/locations/global/workloadIdentityPools/pool/providers/attestation-verifier
+tee-gcp-service-account: workload@project.iam.gserviceaccount.com
+tee-gcp-scopes: [https://www.googleapis.com/auth/devstorage.read_only, https://www.googleapis.com/auth/cloudkms]
+tee-project-id: test-project
+tee-region: us-central1
+`,
+		},
+		{
+			"JSON",
+			`{
+				"tee-image-reference": "docker.io/library/hello-world:latest",
+				"tee-restart-policy": "Always",
+				"tee-cmd": "[\"--foo\",\"--bar\"]",
+				"tee-env-foo": "bar",
+				"tee-impersonate-service-accounts": "sv1@developer.gserviceaccount.com,sv2@developer.gserviceaccount.com",
+				"tee-container-log-redirect": "true",
+				"tee-read-only-rootfs": "true",
+				"tee-read-only-rootfs-tmpfs": "/tmp,/var/run,/var/cache/app",
+				"tee-added-capabilities": "CAP_NET_ADMIN",
+				"tee-mounts": "type=tmpfs,destination=/tmp;type=bind,source=/mnt/data,destination=/data,readonly=true",
+				"tee-devices": "/dev/nvidia0",
+				"tee-fallback-image-references": "mirror.gcr.io/library/hello-world:latest",
+				"tee-token-audiences": "https://example.com",
+				"tee-log-verbosity": "debug",
+				"tee-clock-skew-policy": "WARN",
+				"tee-evidence-collectors": "tee, ima",
+				"tee-attestation-service-regions": "us-central1,europe-west4",
+				"tee-attestation-service-hedge-delay": "2s",
+				"tee-workload-key": "true",
+				"tee-workload-output-log": "true",
+				"tee-cel-hash-algorithms": "sha256,sha384",
+				"tee-measure-cel-header": "true",
+				"tee-image-platform": "linux/arm64",
+				"tee-attest-before-run": "true",
+				"tee-attest-approval-url": "https://approver.example.com/approve",
+				"tee-token-tmpfs-size-mib": "8",
+				"tee-token-tmpfs-mode": "0750",
+				"tee-token-delivery": "file-and-socket",
+				"tee-token-watchdog": "reattest",
+				"tee-measure-kernel-modules": "true",
+				"tee-measure-kernel-log": "true",
+				"tee-cdi-devices": "nvidia.com/gpu=0,nvidia.com/gpu=1",
+				"tee-gcp-workload-identity-provider": "projects/123/locations/global/workloadIdentityPools/pool/providers/attestation-verifier",
+				"tee-gcp-service-account": "workload@project.iam.gserviceaccount.com",
+				"tee-gcp-scopes": "https://www.googleapis.com/auth/devstorage.read_only,https://www.googleapis.com/auth/cloudkms",
+				"tee-project-id": "test-project",
+				"tee-region": "us-central1"
+			}`,
+		},
+	}
+
+	want := LaunchSpec{
+		ImageRef:                     "docker.io/library/hello-world:latest",
+		RestartPolicy:                Always,
+		Cmd:                          []string{"--foo", "--bar"},
+		Envs:                         []EnvVar{{"foo", "bar"}},
+		ImpersonateServiceAccounts:   []string{"sv1@developer.gserviceaccount.com", "sv2@developer.gserviceaccount.com"},
+		LogRedirect:                  true,
+		HostNetwork:                  true,
+		ReadOnlyRootfs:               true,
+		ReadOnlyRootfsTmpfs:          []string{"/tmp", "/var/run", "/var/cache/app"},
+		AddedCapabilities:            []string{"CAP_NET_ADMIN"},
+		Mounts:                       []cel.Mount{{Type: "tmpfs", Destination: "/tmp"}, {Type: "bind", Source: "/mnt/data", Destination: "/data", ReadOnly: true}},
+		Devices:                      []string{"/dev/nvidia0"},
+		FallbackImageRefs:            []string{"mirror.gcr.io/library/hello-world:latest"},
+		TokenAudiences:               []string{"https://example.com"},
+		LogVerbosity:                 Debug,
+		ClockSkewPolicy:              ClockSkewWarn,
+		EvidenceCollectors:           []string{"tee", "ima"},
+		AttestationServiceRegions:    []string{"us-central1", "europe-west4"},
+		AttestationServiceHedgeDelay: 2 * time.Second,
+		WorkloadKey:                  true,
+		WorkloadOutputLog:            true,
+		CELHashAlgorithms:            []crypto.Hash{crypto.SHA256, crypto.SHA384},
+		MeasureCELHeader:             true,
+		Platform:                     "linux/arm64",
+		AttestBeforeRun:              true,
+		ApprovalURL:                  "https://approver.example.com/approve",
+		TokenTmpfsSizeMiB:            8,
+		TokenTmpfsMode:               0750,
+		TokenDelivery:                TokenDeliveryFileAndSocket,
+		TokenWatchdog:                WatchdogReattest,
+		MeasureKernelModules:         true,
+		MeasureKernelLog:             true,
+		CDIDevices:                   []string{"nvidia.com/gpu=0", "nvidia.com/gpu=1"},
+		GCPWorkloadIdentityProvider:  "projects/123/locations/global/workloadIdentityPools/pool/providers/attestation-verifier",
+		GCPServiceAccount:            "workload@project.iam.gserviceaccount.com",
+		GCPScopes:                    []string{"https://www.googleapis.com/auth/devstorage.read_only", "https://www.googleapis.com/auth/cloudkms"},
+		ProjectID:                    "test-project",
+		Region:                       "us-central1",
+	}
+
+	for _, testcase := range testCases {
+		t.Run(testcase.testName, func(t *testing.T) {
+			spec, err := parseLaunchSpecFile([]byte(testcase.file), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(spec, want) {
+				t.Errorf("parseLaunchSpecFile got %+v, want %+v", spec, want)
+			}
+		})
+	}
+}
+
+func TestParseLaunchSpecFileEnvOverrides(t *testing.T) {
+	file := `
+tee-image-reference: docker.io/library/hello-world:latest
+tee-env-foo: bar
+`
+	environ := []string{
+		"TEE_IMAGE_REFERENCE=docker.io/library/busybox:latest",
+		"TEE_RESTART_POLICY=OnFailure",
+		"TEE_ENV_foo=baz",
+		"TEE_ENV_Other=value",
+		"TEE_UNKNOWN=ignored",
+		"HOME=/root",
+	}
+	want := LaunchSpec{
+		ImageRef:        "docker.io/library/busybox:latest",
+		RestartPolicy:   OnFailure,
+		Envs:            []EnvVar{{"Other", "value"}, {"foo", "baz"}},
+		HostNetwork:     true,
+		LogVerbosity:    Info,
+		ClockSkewPolicy: ClockSkewEnforce,
+	}
+
+	spec, err := parseLaunchSpecFile([]byte(file), environ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(spec, want) {
+		t.Errorf("parseLaunchSpecFile got %+v, want %+v", spec, want)
+	}
+}
+
+func TestParseLaunchSpecFileBadInput(t *testing.T) {
+	var testCases = []struct {
+		testName string
+		file     string
+	}{
+		{"BadYAML", "tee-image-reference: [unclosed"},
+		{"NoImageRef", "tee-restart-policy: Always"},
+		{"UnknownField", "tee-image-reference: foo\ntee-unknown: bar"},
+		{"BadRestartPolicy", "tee-image-reference: foo\ntee-restart-policy: Sometimes"},
+		{"NonStringCmd", "tee-image-reference: foo\ntee-cmd: [1, 2]"},
+		{"NestedObject", "tee-image-reference: foo\ntee-env-foo: {bar: baz}"},
+		{"BadMount", "tee-image-reference: foo\ntee-mounts: [type=overlay]"},
+		{"BadImageRef", "tee-image-reference: Foo:bar:baz"},
+		{"BadFallbackImageRef", "tee-image-reference: foo\ntee-fallback-image-references: [UPPER/case]"},
+		{"DeviceNotInDev", "tee-image-reference: foo\ntee-devices: [/etc/passwd]"},
+		{"DeviceEscapingDev", "tee-image-reference: foo\ntee-devices: [/dev/../etc/passwd]"},
+		{"EmptyAudience", "tee-image-reference: foo\ntee-token-audiences: \"a,,b\""},
+		{"SeveralAudiences", "tee-image-reference: foo\ntee-token-audiences: [a, b]"},
+		{"BadLogVerbosity", "tee-image-reference: foo\ntee-log-verbosity: loud"},
+		{"BadClockSkewPolicy", "tee-image-reference: foo\ntee-clock-skew-policy: ignore"},
+		{"EmptyEvidenceCollector", "tee-image-reference: foo\ntee-evidence-collectors: tee,,ima"},
+		{"EmptyAttestationRegion", "tee-image-reference: foo\ntee-attestation-service-regions: us-central1,"},
+		{"BadHedgeDelay", "tee-image-reference: foo\ntee-attestation-service-hedge-delay: 2"},
+		{"NegativeHedgeDelay", "tee-image-reference: foo\ntee-attestation-service-hedge-delay: -1s"},
+	}
+
+	for _, testcase := range testCases {
+		t.Run(testcase.testName, func(t *testing.T) {
+			if _, err := parseLaunchSpecFile([]byte(testcase.file), nil); err == nil {
+				t.Error("expected parseLaunchSpecFile to fail")
+			}
+		})
+	}
+}
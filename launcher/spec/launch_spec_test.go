@@ -1,9 +1,17 @@
 package spec
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/cel"
 )
 
 func TestLaunchSpecUnmarshalJSONHappyCases(t *testing.T) {
@@ -44,6 +52,9 @@ func TestLaunchSpecUnmarshalJSONHappyCases(t *testing.T) {
 		Envs:                       []EnvVar{{"foo", "bar"}},
 		ImpersonateServiceAccounts: []string{"sv1@developer.gserviceaccount.com", "sv2@developer.gserviceaccount.com"},
 		LogRedirect:                true,
+		HostNetwork:                true,
+		LogVerbosity:               Info,
+		ClockSkewPolicy:            ClockSkewEnforce,
 	}
 
 	for _, testcase := range testCases {
@@ -91,6 +102,202 @@ func TestLaunchSpecUnmarshalJSONBadInput(t *testing.T) {
 				"tee-restart-policy":"noway",
 			}`,
 		},
+		{
+			"BadCABundle",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-ca-bundle":"not a certificate"
+			}`,
+		},
+		{
+			"BadProxy",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-https-proxy":"proxy.internal:3128"
+			}`,
+		},
+		{
+			"BadMetricsAddress",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-metrics-address":"9100"
+			}`,
+		},
+		{
+			"BadUser",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-user":"nobody"
+			}`,
+		},
+		{
+			"BadSeccompProfile",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-seccomp-profile":"default"
+			}`,
+		},
+		{
+			"BadLaunchPolicyURL",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-launch-policy-url":"http://policy.internal/policy.json",
+				"tee-launch-policy-keys":"-----BEGIN PUBLIC KEY-----\nMCowBQYDK2VwAyEAGb9ECWmEzf6FQbrBZ9w7lshQhqowtrbLDFw4rXAxZuE=\n-----END PUBLIC KEY-----\n"
+			}`,
+		},
+		{
+			"LaunchPolicyURLWithoutKeys",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-launch-policy-url":"https://policy.internal/policy.json"
+			}`,
+		},
+		{
+			"BadUserGID",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-user":"1000:"
+			}`,
+		},
+		{
+			"UnknownCELHashAlgorithm",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-cel-hash-algorithms":"sha256,md5"
+			}`,
+		},
+		{
+			"UnsupportedPlatform",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-image-platform":"windows/amd64"
+			}`,
+		},
+		{
+			"BadApprovalURL",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-attest-before-run":"true",
+				"tee-attest-approval-url":"http://approver.example.com"
+			}`,
+		},
+		{
+			"ApprovalURLWithoutAttestBeforeRun",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-attest-approval-url":"https://approver.example.com"
+			}`,
+		},
+		{
+			"ZeroTokenTmpfsSize",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-token-tmpfs-size-mib":"0"
+			}`,
+		},
+		{
+			"WorldWritableTokenTmpfsMode",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-token-tmpfs-mode":"0777"
+			}`,
+		},
+		{
+			"BadTokenDelivery",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-token-delivery":"pipe"
+			}`,
+		},
+		{
+			"BadTokenWatchdog",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-token-watchdog":"reboot"
+			}`,
+		},
+		{
+			"BadMeasureKernelModules",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-measure-kernel-modules":"sometimes"
+			}`,
+		},
+		{
+			"BadMeasureCELHeader",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-measure-cel-header":"sometimes"
+			}`,
+		},
+		{
+			"BadMeasureKernelLog",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-measure-kernel-log":"sometimes"
+			}`,
+		},
+		{
+			"BadCDIDeviceName",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-cdi-devices":"nvidia.com/gpu"
+			}`,
+		},
+		{
+			"BadCDIDeviceVendor",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-cdi-devices":"-nvidia.com/gpu=0"
+			}`,
+		},
+		{
+			"ReadOnlyRootfsTmpfsWithoutReadOnlyRootfs",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-read-only-rootfs-tmpfs":"/tmp"
+			}`,
+		},
+		{
+			"ReadOnlyRootfsTmpfsRelativePath",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-read-only-rootfs":"true",
+				"tee-read-only-rootfs-tmpfs":"tmp"
+			}`,
+		},
+		{
+			"GCPScopesWithoutProvider",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-gcp-scopes":"https://www.googleapis.com/auth/cloudkms"
+			}`,
+		},
+		{
+			"GCPProviderWithTokenSocket",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-gcp-workload-identity-provider":"projects/123/locations/global/workloadIdentityPools/pool/providers/attestation-verifier",
+				"tee-token-delivery":"socket"
+			}`,
+		},
+		{
+			"TokenWatchdogWithTokenSocket",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-token-watchdog":"revoke",
+				"tee-token-delivery":"socket"
+			}`,
+		},
+		{
+			"GCSMountWithTokenSocket",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-mounts":"type=gcs,source=my-bucket,destination=/data",
+				"tee-gcs-workload-identity-provider":"projects/123/locations/global/workloadIdentityPools/pool/providers/attestation-verifier",
+				"tee-token-delivery":"socket"
+			}`,
+		},
 	}
 
 	for _, testcase := range testCases {
@@ -115,8 +322,11 @@ func TestLaunchSpecUnmarshalJSONWithDefaultValue(t *testing.T) {
 	}
 
 	want := &LaunchSpec{
-		ImageRef:      "docker.io/library/hello-world:latest",
-		RestartPolicy: Never,
+		ImageRef:        "docker.io/library/hello-world:latest",
+		RestartPolicy:   Never,
+		HostNetwork:     true,
+		LogVerbosity:    Info,
+		ClockSkewPolicy: ClockSkewEnforce,
 	}
 
 	if !cmp.Equal(spec, want) {
@@ -136,3 +346,262 @@ func TestLaunchSpecUnmarshalJSONWithoutImageReference(t *testing.T) {
 		t.Fatalf("got %v error, but expected %v error", err, errImageRefNotSpecified)
 	}
 }
+
+func TestLaunchSpecUnmarshalJSONGCSMounts(t *testing.T) {
+	mdsJSON := `{
+		"tee-image-reference":"docker.io/library/hello-world:latest",
+		"tee-mounts":"type=gcs,source=my-bucket,destination=/data",
+		"tee-gcs-workload-identity-provider":"projects/123/locations/global/workloadIdentityPools/pool/providers/attestation-verifier",
+		"tee-gcs-service-account":"reader@project.iam.gserviceaccount.com"
+		}`
+
+	spec := &LaunchSpec{}
+	if err := spec.UnmarshalJSON([]byte(mdsJSON)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &LaunchSpec{
+		ImageRef:                    "docker.io/library/hello-world:latest",
+		RestartPolicy:               Never,
+		HostNetwork:                 true,
+		LogVerbosity:                Info,
+		ClockSkewPolicy:             ClockSkewEnforce,
+		Mounts:                      []cel.Mount{{Type: cel.GCSMountType, Source: "my-bucket", Destination: "/data", ReadOnly: true}},
+		GCSWorkloadIdentityProvider: "projects/123/locations/global/workloadIdentityPools/pool/providers/attestation-verifier",
+		GCSServiceAccount:           "reader@project.iam.gserviceaccount.com",
+	}
+	if !cmp.Equal(spec, want) {
+		t.Errorf("LaunchSpec UnmarshalJSON got %+v, want %+v", spec, want)
+	}
+}
+
+func TestLaunchSpecUnmarshalJSONReadOnlyRootfsTmpfs(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mdsJSON string
+		want    []string
+	}{
+		{
+			"Default",
+			`{"tee-image-reference":"docker.io/library/hello-world:latest","tee-read-only-rootfs":"true"}`,
+			DefaultReadOnlyRootfsTmpfs,
+		},
+		{
+			"None",
+			`{"tee-image-reference":"docker.io/library/hello-world:latest","tee-read-only-rootfs":"true","tee-read-only-rootfs-tmpfs":"none"}`,
+			nil,
+		},
+		{
+			"Custom",
+			`{"tee-image-reference":"docker.io/library/hello-world:latest","tee-read-only-rootfs":"true","tee-read-only-rootfs-tmpfs":"/run/app/, /tmp"}`,
+			[]string{"/run/app", "/tmp"},
+		},
+		{
+			"WritableRootfs",
+			`{"tee-image-reference":"docker.io/library/hello-world:latest"}`,
+			nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := &LaunchSpec{}
+			if err := spec.UnmarshalJSON([]byte(tc.mdsJSON)); err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(spec.ReadOnlyRootfsTmpfs, tc.want) {
+				t.Errorf("ReadOnlyRootfsTmpfs got %v, want %v", spec.ReadOnlyRootfsTmpfs, tc.want)
+			}
+		})
+	}
+}
+
+func TestLaunchSpecUnmarshalJSONGCSMountsWithoutProvider(t *testing.T) {
+	mdsJSON := `{
+		"tee-image-reference":"docker.io/library/hello-world:latest",
+		"tee-mounts":"type=gcs,source=my-bucket,destination=/data"
+		}`
+
+	spec := &LaunchSpec{}
+	if err := spec.UnmarshalJSON([]byte(mdsJSON)); err == nil {
+		t.Fatal("expected an error for a gcs mount without a workload identity provider")
+	}
+}
+
+func TestLaunchSpecUnmarshalJSONEgressAndWorkloadConfig(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), IsCA: true, BasicConstraintsValid: true}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caBundle := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	mdsJSON, err := json.Marshal(map[string]string{
+		"tee-image-reference": "docker.io/library/hello-world:latest",
+		"tee-ca-bundle":       caBundle,
+		"tee-http-proxy":      "http://proxy.internal:3128",
+		"tee-https-proxy":     "http://proxy.internal:3129",
+		"tee-no-proxy":        "registry.internal",
+		"tee-workload-config": `{"replicas": 3}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := &LaunchSpec{}
+	if err := spec.UnmarshalJSON(mdsJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &LaunchSpec{
+		ImageRef:        "docker.io/library/hello-world:latest",
+		RestartPolicy:   Never,
+		HostNetwork:     true,
+		LogVerbosity:    Info,
+		ClockSkewPolicy: ClockSkewEnforce,
+		CABundle:        caBundle,
+		HTTPProxy:       "http://proxy.internal:3128",
+		HTTPSProxy:      "http://proxy.internal:3129",
+		NoProxy:         "registry.internal",
+		WorkloadConfig:  `{"replicas": 3}`,
+	}
+	if !cmp.Equal(spec, want) {
+		t.Errorf("LaunchSpec UnmarshalJSON got %+v, want %+v", spec, want)
+	}
+}
+
+func TestLaunchSpecUnmarshalJSONMetrics(t *testing.T) {
+	mdsJSON := `{
+		"tee-image-reference":"docker.io/library/hello-world:latest",
+		"tee-metrics-address":":9100",
+		"tee-metrics-cloud-monitoring":"true"
+	}`
+
+	spec := &LaunchSpec{}
+	if err := spec.UnmarshalJSON([]byte(mdsJSON)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &LaunchSpec{
+		ImageRef:        "docker.io/library/hello-world:latest",
+		RestartPolicy:   Never,
+		HostNetwork:     true,
+		LogVerbosity:    Info,
+		ClockSkewPolicy: ClockSkewEnforce,
+		MetricsAddress:  ":9100",
+		CloudMonitoring: true,
+	}
+	if !cmp.Equal(spec, want) {
+		t.Errorf("LaunchSpec UnmarshalJSON got %+v, want %+v", spec, want)
+	}
+}
+
+func TestLaunchSpecUnmarshalJSONUser(t *testing.T) {
+	mdsJSON := `{
+		"tee-image-reference":"docker.io/library/hello-world:latest",
+		"tee-user":"1000:1000",
+		"tee-user-namespace":"true"
+	}`
+
+	spec := &LaunchSpec{}
+	if err := spec.UnmarshalJSON([]byte(mdsJSON)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &LaunchSpec{
+		ImageRef:        "docker.io/library/hello-world:latest",
+		RestartPolicy:   Never,
+		HostNetwork:     true,
+		LogVerbosity:    Info,
+		ClockSkewPolicy: ClockSkewEnforce,
+		User:            "1000:1000",
+		UserNamespace:   true,
+	}
+	if !cmp.Equal(spec, want) {
+		t.Errorf("LaunchSpec UnmarshalJSON got %+v, want %+v", spec, want)
+	}
+}
+
+func TestParseUser(t *testing.T) {
+	testCases := []struct {
+		user    string
+		uid     uint32
+		gid     uint32
+		hasGID  bool
+		wantErr bool
+	}{
+		{user: "1000", uid: 1000},
+		{user: "1000:2000", uid: 1000, gid: 2000, hasGID: true},
+		{user: "0:0", hasGID: true},
+		{user: "nobody", wantErr: true},
+		{user: "1000:nogroup", wantErr: true},
+		{user: "-1", wantErr: true},
+		{user: "4294967296", wantErr: true},
+	}
+	for _, tc := range testCases {
+		uid, gid, hasGID, err := ParseUser(tc.user)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ParseUser(%q) error = %v, want error %v", tc.user, err, tc.wantErr)
+			continue
+		}
+		if uid != tc.uid || gid != tc.gid || hasGID != tc.hasGID {
+			t.Errorf("ParseUser(%q) = %d, %d, %v, want %d, %d, %v", tc.user, uid, gid, hasGID, tc.uid, tc.gid, tc.hasGID)
+		}
+	}
+}
+
+func TestParseCDIDeviceName(t *testing.T) {
+	testCases := []struct {
+		qualified string
+		kind      string
+		name      string
+		wantErr   bool
+	}{
+		{qualified: "nvidia.com/gpu=0", kind: "nvidia.com/gpu", name: "0"},
+		{qualified: "vendor.example/my_class=dev-1.a:b", kind: "vendor.example/my_class", name: "dev-1.a:b"},
+		{qualified: "nvidia.com/gpu", wantErr: true},
+		{qualified: "gpu=0", wantErr: true},
+		{qualified: "nvidia.com/gpu=", wantErr: true},
+		{qualified: "nvidia.com/gpu.x=0", wantErr: true},
+		{qualified: "nvidia.com/gpu=0/1", wantErr: true},
+		{qualified: "nvidia.com./gpu=0", wantErr: true},
+	}
+	for _, tc := range testCases {
+		kind, name, err := ParseCDIDeviceName(tc.qualified)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ParseCDIDeviceName(%q) error = %v, want error %v", tc.qualified, err, tc.wantErr)
+			continue
+		}
+		if kind != tc.kind || name != tc.name {
+			t.Errorf("ParseCDIDeviceName(%q) = %q, %q, want %q, %q", tc.qualified, kind, name, tc.kind, tc.name)
+		}
+	}
+}
+
+func TestLaunchSpecUnmarshalJSONConfinement(t *testing.T) {
+	mdsJSON := `{
+		"tee-image-reference":"docker.io/library/hello-world:latest",
+		"tee-seccomp-profile":"{\"defaultAction\":\"SCMP_ACT_ERRNO\"}",
+		"tee-apparmor-profile":"runtime-default"
+	}`
+
+	spec := &LaunchSpec{}
+	if err := spec.UnmarshalJSON([]byte(mdsJSON)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &LaunchSpec{
+		ImageRef:        "docker.io/library/hello-world:latest",
+		RestartPolicy:   Never,
+		HostNetwork:     true,
+		LogVerbosity:    Info,
+		ClockSkewPolicy: ClockSkewEnforce,
+		SeccompProfile:  `{"defaultAction":"SCMP_ACT_ERRNO"}`,
+		AppArmorProfile: RuntimeDefaultProfile,
+	}
+	if !cmp.Equal(spec, want) {
+		t.Errorf("LaunchSpec UnmarshalJSON got %+v, want %+v", spec, want)
+	}
+}
@@ -0,0 +1,202 @@
+package spec
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/containerd/containerd/reference/docker"
+)
+
+// PolicyDocumentPayloadType is the DSSE payload type of signed launch policy
+// documents.
+const PolicyDocumentPayloadType = "application/vnd.confidential-space.launch-policy+json"
+
+// PolicyDocument is a launch policy signed by the workload author, fetched
+// from a policy server at boot. It is enforced in addition to the launch
+// policy of the image labels.
+type PolicyDocument struct {
+	// AllowedImages are the images the workload can be started from:
+	// repositories (e.g. "us-docker.pkg.dev/project/repo/image"), references
+	// with a tag, or image digests ("sha256:..."). Any image is allowed if
+	// empty.
+	AllowedImages []string `json:"allowedImages,omitempty"`
+	// RequiredClaims are the values the container claims measured into the
+	// CEL must have, by the name of their COS event type, e.g.
+	// {"ReadOnlyRootfs": "true", "SeccompProfile": "runtime-default"}.
+	RequiredClaims map[string]string `json:"requiredClaims,omitempty"`
+
+	// Digest is "sha256:" followed by the hex SHA-256 of the signed payload.
+	Digest string `json:"-"`
+	// Signer is "sha256:" followed by the hex SHA-256 of the DER
+	// SubjectPublicKeyInfo of the key which signed the document.
+	Signer string `json:"-"`
+}
+
+// dsseEnvelope is a DSSE envelope, see
+// https://github.com/secure-systems-lab/dsse/blob/master/envelope.md.
+type dsseEnvelope struct {
+	PayloadType string `json:"payloadType"`
+	Payload     []byte `json:"payload"`
+	Signatures  []struct {
+		KeyID string `json:"keyid,omitempty"`
+		Sig   []byte `json:"sig"`
+	} `json:"signatures"`
+}
+
+// dssePAE is the DSSE pre-authentication encoding.
+func dssePAE(payloadType string, payload []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "DSSEv1 %d %s %d ", len(payloadType), payloadType, len(payload))
+	buf.Write(payload)
+	return buf.Bytes()
+}
+
+// ParsePolicyKeys parses the PEM public keys pinned to verify launch policy
+// documents. ECDSA P-256 and P-384, Ed25519 and RSA keys are supported.
+func ParsePolicyKeys(pemKeys string) ([]crypto.PublicKey, error) {
+	var keys []crypto.PublicKey
+	rest := []byte(pemKeys)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			return nil, fmt.Errorf("unexpected PEM block %q, want PUBLIC KEY", block.Type)
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch key.(type) {
+		case *ecdsa.PublicKey, ed25519.PublicKey, *rsa.PublicKey:
+		default:
+			return nil, fmt.Errorf("unsupported public key type %T", key)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no PEM public key")
+	}
+	return keys, nil
+}
+
+// VerifyPolicyDocument checks that the DSSE envelope is signed by one of the
+// pinned keys, and returns the launch policy document it contains.
+func VerifyPolicyDocument(envelope []byte, keys []crypto.PublicKey) (*PolicyDocument, error) {
+	var env dsseEnvelope
+	if err := json.Unmarshal(envelope, &env); err != nil {
+		return nil, fmt.Errorf("failed to decode DSSE envelope: %v", err)
+	}
+	if env.PayloadType != PolicyDocumentPayloadType {
+		return nil, fmt.Errorf("unexpected payload type %q", env.PayloadType)
+	}
+	message := dssePAE(env.PayloadType, env.Payload)
+	var signer crypto.PublicKey
+	for _, sig := range env.Signatures {
+		for _, key := range keys {
+			if verifySignature(key, message, sig.Sig) == nil {
+				signer = key
+				break
+			}
+		}
+		if signer != nil {
+			break
+		}
+	}
+	if signer == nil {
+		return nil, errors.New("launch policy document is not signed by a pinned key")
+	}
+
+	doc := &PolicyDocument{}
+	if err := json.Unmarshal(env.Payload, doc); err != nil {
+		return nil, fmt.Errorf("failed to decode launch policy document: %v", err)
+	}
+	digest := sha256.Sum256(env.Payload)
+	doc.Digest = "sha256:" + hex.EncodeToString(digest[:])
+	der, err := x509.MarshalPKIXPublicKey(signer)
+	if err != nil {
+		return nil, err
+	}
+	signerDigest := sha256.Sum256(der)
+	doc.Signer = "sha256:" + hex.EncodeToString(signerDigest[:])
+	return doc, nil
+}
+
+// verifySignature verifies sig over message: ASN.1 ECDSA with the hash of the
+// curve size, Ed25519, or RSA PKCS #1 v1.5 with SHA-256.
+func verifySignature(key crypto.PublicKey, message, sig []byte) error {
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		var digest []byte
+		switch key.Curve {
+		case elliptic.P256():
+			d := sha256.Sum256(message)
+			digest = d[:]
+		case elliptic.P384():
+			d := sha512.Sum384(message)
+			digest = d[:]
+		default:
+			return fmt.Errorf("unsupported curve %s", key.Curve.Params().Name)
+		}
+		if !ecdsa.VerifyASN1(key, digest, sig) {
+			return errors.New("invalid ECDSA signature")
+		}
+		return nil
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, message, sig) {
+			return errors.New("invalid Ed25519 signature")
+		}
+		return nil
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(message)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig)
+	}
+	return fmt.Errorf("unsupported public key type %T", key)
+}
+
+// AllowsImage checks if the image with the reference and digest is one of the
+// AllowedImages.
+func (d *PolicyDocument) AllowsImage(ref string, digest string) bool {
+	if len(d.AllowedImages) == 0 {
+		return true
+	}
+	named, err := docker.ParseDockerRef(ref)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range d.AllowedImages {
+		if strings.HasPrefix(allowed, "sha256:") {
+			if allowed == digest {
+				return true
+			}
+			continue
+		}
+		allowedNamed, err := docker.ParseNormalizedNamed(allowed)
+		if err != nil {
+			continue
+		}
+		if docker.IsNameOnly(allowedNamed) {
+			if allowedNamed.Name() == named.Name() {
+				return true
+			}
+		} else if docker.TagNameOnly(allowedNamed).String() == named.String() {
+			return true
+		}
+	}
+	return false
+}
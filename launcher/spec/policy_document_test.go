@@ -0,0 +1,170 @@
+package spec
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// signPolicyDocument returns payload in a DSSE envelope signed by signer.
+func signPolicyDocument(t *testing.T, signer crypto.Signer, payloadType string, payload []byte) []byte {
+	t.Helper()
+	message := dssePAE(payloadType, payload)
+	var sig []byte
+	var err error
+	switch signer.(type) {
+	case ed25519.PrivateKey:
+		sig, err = signer.Sign(rand.Reader, message, crypto.Hash(0))
+	default:
+		digest := sha256.Sum256(message)
+		sig, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := json.Marshal(map[string]interface{}{
+		"payloadType": payloadType,
+		"payload":     payload,
+		"signatures":  []map[string]interface{}{{"sig": sig}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return envelope
+}
+
+func pemPublicKey(t *testing.T, pub crypto.PublicKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func TestVerifyPolicyDocument(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := ParsePolicyKeys(pemPublicKey(t, ecKey.Public()) + pemPublicKey(t, edKey.Public()) + pemPublicKey(t, rsaKey.Public()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := []byte(`{"allowedImages":["us-docker.pkg.dev/project/repo/image"],"requiredClaims":{"ReadOnlyRootfs":"true"}}`)
+	payloadDigest := sha256.Sum256(payload)
+	want := PolicyDocument{
+		AllowedImages:  []string{"us-docker.pkg.dev/project/repo/image"},
+		RequiredClaims: map[string]string{"ReadOnlyRootfs": "true"},
+		Digest:         "sha256:" + hex.EncodeToString(payloadDigest[:]),
+	}
+	for _, signer := range []crypto.Signer{ecKey, edKey, rsaKey} {
+		doc, err := VerifyPolicyDocument(signPolicyDocument(t, signer, PolicyDocumentPayloadType, payload), keys)
+		if err != nil {
+			t.Fatalf("failed to verify a document signed by a %T: %v", signer, err)
+		}
+		der, err := x509.MarshalPKIXPublicKey(signer.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		signerDigest := sha256.Sum256(der)
+		want.Signer = "sha256:" + hex.EncodeToString(signerDigest[:])
+		if !cmp.Equal(*doc, want) {
+			t.Errorf("VerifyPolicyDocument() = %+v, want %+v", *doc, want)
+		}
+	}
+
+	if _, err := VerifyPolicyDocument(signPolicyDocument(t, otherKey, PolicyDocumentPayloadType, payload), keys); err == nil {
+		t.Error("verified a document signed by a key which is not pinned")
+	}
+	if _, err := VerifyPolicyDocument(signPolicyDocument(t, ecKey, "application/json", payload), keys); err == nil {
+		t.Error("verified a document with another payload type")
+	}
+	envelope := signPolicyDocument(t, ecKey, PolicyDocumentPayloadType, payload)
+	var tampered map[string]interface{}
+	if err := json.Unmarshal(envelope, &tampered); err != nil {
+		t.Fatal(err)
+	}
+	tampered["payload"] = []byte(`{}`)
+	envelope, err = json.Marshal(tampered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyPolicyDocument(envelope, keys); err == nil {
+		t.Error("verified a document with a tampered payload")
+	}
+}
+
+func TestParsePolicyKeysBadInput(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, keys := range map[string]string{
+		"Empty":      "",
+		"NotPEM":     "not a key",
+		"PrivateKey": string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})),
+	} {
+		if _, err := ParsePolicyKeys(keys); err == nil {
+			t.Errorf("%s: ParsePolicyKeys() succeeded, want error", name)
+		}
+	}
+}
+
+func TestPolicyDocumentAllowsImage(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	doc := PolicyDocument{AllowedImages: []string{
+		"us-docker.pkg.dev/project/repo/image",
+		"ubuntu:22.04",
+		digest,
+	}}
+	testCases := []struct {
+		ref    string
+		digest string
+		want   bool
+	}{
+		{"us-docker.pkg.dev/project/repo/image:latest", "sha256:" + strings.Repeat("00", 32), true},
+		{"us-docker.pkg.dev/project/repo/image@" + digest, digest, true},
+		{"docker.io/library/ubuntu:22.04", "sha256:" + strings.Repeat("00", 32), true},
+		{"docker.io/library/ubuntu:20.04", "sha256:" + strings.Repeat("00", 32), false},
+		{"us-docker.pkg.dev/project/repo/other:latest", digest, true},
+		{"us-docker.pkg.dev/project/repo/other:latest", "sha256:" + strings.Repeat("00", 32), false},
+	}
+	for _, tc := range testCases {
+		if got := doc.AllowsImage(tc.ref, tc.digest); got != tc.want {
+			t.Errorf("AllowsImage(%q, %q) = %v, want %v", tc.ref, tc.digest, got, tc.want)
+		}
+	}
+	if !(&PolicyDocument{}).AllowsImage("docker.io/library/ubuntu:20.04", digest) {
+		t.Error("a document without allowed images does not allow an image")
+	}
+}
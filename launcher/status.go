@@ -0,0 +1,141 @@
+package launcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// The reasons of a TerminalStatus, classifying the error the launcher exited
+// with. They are stable, so orchestration layers can match on them.
+const (
+	ReasonSuccess            = "SUCCESS"
+	ReasonPolicyViolation    = "POLICY_VIOLATION"
+	ReasonImagePullFailure   = "IMAGE_PULL_FAILURE"
+	ReasonAttestationFailure = "ATTESTATION_FAILURE"
+	ReasonWorkloadFailure    = "WORKLOAD_FAILURE"
+	ReasonRetryableFailure   = "RETRYABLE_FAILURE"
+	ReasonLauncherFailure    = "LAUNCHER_FAILURE"
+)
+
+const (
+	// hostStatusPath is the directory in the host storing statusFile. Unlike
+	// hostTokenPath, it is not mounted in the container.
+	hostStatusPath = "/run/container_launcher_status/"
+	// statusFile stores the TerminalStatus of the launcher as JSON.
+	statusFile = "status.json"
+	// statusGuestAttribute is the guest attribute storing the TerminalStatus of
+	// the launcher as JSON, if guest attributes are enabled on the instance.
+	statusGuestAttribute = "confidential-space/launcher-status"
+)
+
+// guestAttributesURL is the guest attributes endpoint of the metadata server,
+// a variable for testing.
+var guestAttributesURL = "http://metadata.google.internal/computeMetadata/v1/instance/guest-attributes/"
+
+// TerminalStatus is the machine-readable status of the launcher when it exits.
+type TerminalStatus struct {
+	// ExitCode is the exit code of the launcher.
+	ExitCode int `json:"exit_code"`
+	// Reason classifies Error, see ErrorReason.
+	Reason string `json:"reason"`
+	// Error is the error the launcher exited with, if any.
+	Error string `json:"error,omitempty"`
+	// WorkloadReturnCode is the return code of the workload, if it ran to
+	// completion.
+	WorkloadReturnCode *uint32   `json:"workload_return_code,omitempty"`
+	Time               time.Time `json:"time"`
+}
+
+// ErrorReason returns the reason of a TerminalStatus for err, the error
+// returned by a launch.
+func ErrorReason(err error) string {
+	var policyErr *PolicyError
+	var pullErr *ImagePullError
+	var attestationErr *AttestationError
+	var workloadErr *WorkloadError
+	var retryableErr *RetryableError
+	switch {
+	case err == nil:
+		return ReasonSuccess
+	case errors.As(err, &policyErr):
+		return ReasonPolicyViolation
+	case errors.As(err, &pullErr):
+		return ReasonImagePullFailure
+	case errors.As(err, &attestationErr):
+		return ReasonAttestationFailure
+	case errors.As(err, &workloadErr):
+		return ReasonWorkloadFailure
+	case errors.As(err, &retryableErr):
+		return ReasonRetryableFailure
+	}
+	return ReasonLauncherFailure
+}
+
+// NewTerminalStatus returns the TerminalStatus of a launcher exiting with
+// exitCode after a launch returned err.
+func NewTerminalStatus(exitCode int, err error) TerminalStatus {
+	status := TerminalStatus{
+		ExitCode: exitCode,
+		Reason:   ErrorReason(err),
+		Time:     time.Now().UTC(),
+	}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	var workloadErr *WorkloadError
+	if errors.As(err, &workloadErr) {
+		status.WorkloadReturnCode = &workloadErr.ReturnCode
+	} else if err == nil {
+		status.WorkloadReturnCode = new(uint32)
+	}
+	return status
+}
+
+// WriteTerminalStatus writes the status to the status file in the host, then
+// to the launcher status guest attribute with httpClient. Guest attributes
+// must be enabled on the instance, so writing the attribute may fail even when
+// the file was written.
+func WriteTerminalStatus(status TerminalStatus, httpClient *http.Client) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	if err := writeTerminalStatusFile(hostStatusPath, data); err != nil {
+		return fmt.Errorf("failed to write the status file: %v", err)
+	}
+	if err := putGuestAttribute(httpClient, statusGuestAttribute, data); err != nil {
+		return fmt.Errorf("failed to write the status guest attribute: %v", err)
+	}
+	return nil
+}
+
+func writeTerminalStatusFile(dir string, data []byte) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return writeArtifact(dir, statusFile, data)
+}
+
+// putGuestAttribute sets the guest attribute key, of the form
+// "NAMESPACE/KEY", to value.
+func putGuestAttribute(httpClient *http.Client, key string, value []byte) error {
+	req, err := http.NewRequest(http.MethodPut, guestAttributesURL+key, bytes.NewReader(value))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("metadata server returned non-OK status: %v", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package launcher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestErrorReason(t *testing.T) {
+	testcases := []struct {
+		err  error
+		want string
+	}{
+		{nil, ReasonSuccess},
+		{&PolicyError{errors.New("bad env")}, ReasonPolicyViolation},
+		{&ImagePullError{errors.New("not found")}, ReasonImagePullFailure},
+		{&AttestationError{errors.New("no token")}, ReasonAttestationFailure},
+		{fmt.Errorf("wrapped: %w", &AttestationError{errors.New("no token")}), ReasonAttestationFailure},
+		{&WorkloadError{ReturnCode: 1}, ReasonWorkloadFailure},
+		{&RetryableError{errors.New("no containerd")}, ReasonRetryableFailure},
+		{errors.New("no AK certificate"), ReasonLauncherFailure},
+	}
+	for _, tc := range testcases {
+		if got := ErrorReason(tc.err); got != tc.want {
+			t.Errorf("ErrorReason(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestNewTerminalStatus(t *testing.T) {
+	status := NewTerminalStatus(8, &WorkloadError{ReturnCode: 42})
+	if status.Reason != ReasonWorkloadFailure || status.WorkloadReturnCode == nil || *status.WorkloadReturnCode != 42 {
+		t.Errorf("got status %+v, want a workload failure with return code 42", status)
+	}
+	status = NewTerminalStatus(0, nil)
+	if status.Reason != ReasonSuccess || status.Error != "" || status.WorkloadReturnCode == nil || *status.WorkloadReturnCode != 0 {
+		t.Errorf("got status %+v, want a success with return code 0", status)
+	}
+	status = NewTerminalStatus(6, &ImagePullError{errors.New("not found")})
+	if status.WorkloadReturnCode != nil || status.Error == "" {
+		t.Errorf("got status %+v, want an error without a workload return code", status)
+	}
+}
+
+func TestWriteTerminalStatusFile(t *testing.T) {
+	dir := path.Join(t.TempDir(), "status")
+	data, err := json.Marshal(NewTerminalStatus(5, &PolicyError{errors.New("bad env")}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeTerminalStatusFile(dir, data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path.Join(dir, statusFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var status TerminalStatus
+	if err := json.Unmarshal(got, &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.ExitCode != 5 || status.Reason != ReasonPolicyViolation {
+		t.Errorf("got status %+v, want exit code 5 for a policy violation", status)
+	}
+}
+
+func TestPutGuestAttribute(t *testing.T) {
+	var gotPath, gotValue string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get("Metadata-Flavor") != "Google" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		gotPath, gotValue = r.URL.Path, string(body)
+	}))
+	defer srv.Close()
+	oldURL := guestAttributesURL
+	guestAttributesURL = srv.URL + "/computeMetadata/v1/instance/guest-attributes/"
+	defer func() { guestAttributesURL = oldURL }()
+
+	if err := putGuestAttribute(srv.Client(), statusGuestAttribute, []byte(`{"exit_code":0}`)); err != nil {
+		t.Fatal(err)
+	}
+	if want := "/computeMetadata/v1/instance/guest-attributes/" + statusGuestAttribute; gotPath != want {
+		t.Errorf("got path %q, want %q", gotPath, want)
+	}
+	if gotValue != `{"exit_code":0}` {
+		t.Errorf("got value %q, want the status", gotValue)
+	}
+
+	guestAttributesURL = srv.URL + "/not-found/"
+	srv.Config.Handler = http.NotFoundHandler()
+	if err := putGuestAttribute(srv.Client(), statusGuestAttribute, nil); err == nil {
+		t.Error("putGuestAttribute() succeeded with guest attributes disabled")
+	}
+}
@@ -0,0 +1,186 @@
+package launcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/go-tpm-tools/launcher/agent"
+	"github.com/google/go-tpm-tools/launcher/verifier"
+)
+
+const (
+	// tokenSocketFile is the unix socket in the token directory serving
+	// tokens with the nonces of the workload, when enabled by the
+	// TokenDelivery of the LaunchSpec.
+	tokenSocketFile = "teeserver.sock"
+	// tokenSocketPath is the path of the token requests on the socket.
+	tokenSocketPath = "/v1/token"
+	// maxTokenRequestSize bounds the JSON body of a token request.
+	maxTokenRequestSize = 4096
+)
+
+// tokenRequestBurst token requests can be made at once, then one every
+// tokenRequestInterval, as each is a TPM quote and a call to the attestation
+// service.
+var (
+	tokenRequestBurst    = 5
+	tokenRequestInterval = 2 * time.Second
+)
+
+// The nonces of a token request are bound like the eat_nonce claim of the
+// attestation service.
+const (
+	maxTokenNonces    = 6
+	minTokenNonceSize = 8
+	maxTokenNonceSize = 88
+)
+
+// tokenRequest is the JSON body of a POST request to tokenSocketPath. The
+// response is a fresh attestation token including the nonces, as
+// application/jwt.
+type tokenRequest struct {
+	Nonces []string `json:"nonces"`
+}
+
+func (req tokenRequest) validate() error {
+	if len(req.Nonces) > maxTokenNonces {
+		return fmt.Errorf("got %d nonces, at most %d are allowed", len(req.Nonces), maxTokenNonces)
+	}
+	for _, nonce := range req.Nonces {
+		if len(nonce) < minTokenNonceSize || len(nonce) > maxTokenNonceSize {
+			return fmt.Errorf("nonce %q is not between %d and %d bytes", nonce, minTokenNonceSize, maxTokenNonceSize)
+		}
+	}
+	return nil
+}
+
+// rateLimiter is a token bucket of burst requests, refilled with a request
+// every interval.
+type rateLimiter struct {
+	burst    int
+	interval time.Duration
+	now      func() time.Time
+
+	mu     sync.Mutex
+	tokens int
+	last   time.Time
+}
+
+func newRateLimiter(burst int, interval time.Duration) *rateLimiter {
+	return &rateLimiter{burst: burst, interval: interval, now: time.Now, tokens: burst}
+}
+
+// allow takes a request from the bucket, and returns false if it is empty.
+func (l *rateLimiter) allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if refills := int(now.Sub(l.last) / l.interval); refills > 0 {
+		l.tokens += refills
+		l.last = l.last.Add(time.Duration(refills) * l.interval)
+		if l.tokens >= l.burst {
+			l.tokens = l.burst
+			l.last = now
+		}
+	}
+	if l.tokens == 0 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// tokenHandler serves the token requests of the workload. The tokens are
+// only returned in the responses, never written to the disk.
+type tokenHandler struct {
+	attester agent.NonceAttester
+	limiter  *rateLimiter
+	logger   *log.Logger
+}
+
+func (h *tokenHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "token requests must be POST", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.limiter.allow() {
+		w.Header().Set("Retry-After", strconv.Itoa(int(tokenRequestInterval/time.Second)))
+		http.Error(w, "too many token requests", http.StatusTooManyRequests)
+		return
+	}
+	var req tokenRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTokenRequestSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid token request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := req.validate(); err != nil {
+		http.Error(w, fmt.Sprintf("invalid token request: %v", err), http.StatusBadRequest)
+		return
+	}
+	token, err := h.attester.AttestWithNonces(r.Context(), req.Nonces)
+	if errors.Is(err, verifier.ErrTokenNoncesUnsupported) || errors.Is(err, agent.ErrNoncesUnsupported) {
+		http.Error(w, "the attestation verifier does not support nonces", http.StatusNotImplemented)
+		return
+	}
+	if err != nil {
+		h.logger.Printf("failed to attest for a token request of the workload: %v", err)
+		http.Error(w, "failed to retrieve an attestation token", http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/jwt")
+	w.Write(token)
+}
+
+// serveTokenSocket serves the token requests of the workload on the
+// tokenSocketFile in dir until ctx is cancelled. Only the host user uid, that
+// of the workload, can connect to the socket.
+func serveTokenSocket(ctx context.Context, dir string, attester agent.NonceAttester, uid, gid int, logger *log.Logger) error {
+	socketPath := path.Join(dir, tokenSocketFile)
+	if err := os.Remove(socketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return err
+	}
+	if err := os.Chown(socketPath, uid, gid); err != nil {
+		listener.Close()
+		return err
+	}
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		listener.Close()
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(tokenSocketPath, &tokenHandler{
+		attester: attester,
+		limiter:  newRateLimiter(tokenRequestBurst, tokenRequestInterval),
+		logger:   logger,
+	})
+	server := &http.Server{Handler: mux}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Printf("failed to serve the token socket: %v", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	logger.Printf("serving attestation tokens on %s%s\n", path.Join(containerTokenMountPath, tokenSocketFile), tokenSocketPath)
+	return nil
+}
@@ -0,0 +1,134 @@
+package launcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-tpm-tools/launcher/verifier"
+)
+
+type fakeNonceAttester func(ctx context.Context, nonces []string) ([]byte, error)
+
+func (f fakeNonceAttester) AttestWithNonces(ctx context.Context, nonces []string) ([]byte, error) {
+	return f(ctx, nonces)
+}
+
+func TestServeTokenSocket(t *testing.T) {
+	dir := t.TempDir()
+	attester := fakeNonceAttester(func(ctx context.Context, nonces []string) ([]byte, error) {
+		if len(nonces) > 0 && nonces[0] == "fail-attestation" {
+			return nil, errors.New("attestation failed")
+		}
+		if len(nonces) > 0 && nonces[0] == "unsupported" {
+			return nil, fmt.Errorf("failed to verify: %w", verifier.ErrTokenNoncesUnsupported)
+		}
+		return []byte("token for " + strings.Join(nonces, ",")), nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer func(burst int) { tokenRequestBurst = burst }(tokenRequestBurst)
+	tokenRequestBurst = 9
+	if err := serveTokenSocket(ctx, dir, attester, os.Getuid(), os.Getgid(), log.Default()); err != nil {
+		t.Fatal(err)
+	}
+	socketPath := path.Join(dir, tokenSocketFile)
+	info, err := os.Stat(socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode()&os.ModeSocket == 0 || info.Mode().Perm() != 0600 {
+		t.Errorf("got socket mode %v, want a socket with permissions 0600", info.Mode())
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+		},
+	}}
+	testCases := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantToken  string
+	}{
+		{"Nonces", http.MethodPost, `{"nonces":["nonce-0001","nonce-0002"]}`, http.StatusOK, "token for nonce-0001,nonce-0002"},
+		{"NoNonces", http.MethodPost, `{}`, http.StatusOK, "token for "},
+		{"Get", http.MethodGet, "", http.StatusMethodNotAllowed, ""},
+		{"NotJSON", http.MethodPost, "nonce-0001", http.StatusBadRequest, ""},
+		{"UnknownField", http.MethodPost, `{"audience":"https://example.com"}`, http.StatusBadRequest, ""},
+		{"ShortNonce", http.MethodPost, `{"nonces":["short"]}`, http.StatusBadRequest, ""},
+		{"LongNonce", http.MethodPost, `{"nonces":["` + strings.Repeat("n", maxTokenNonceSize+1) + `"]}`, http.StatusBadRequest, ""},
+		{"TooManyNonces", http.MethodPost, `{"nonces":["nonce-01","nonce-02","nonce-03","nonce-04","nonce-05","nonce-06","nonce-07"]}`, http.StatusBadRequest, ""},
+		{"AttestationFailure", http.MethodPost, `{"nonces":["fail-attestation"]}`, http.StatusBadGateway, ""},
+		{"NoncesUnsupported", http.MethodPost, `{"nonces":["unsupported"]}`, http.StatusNotImplemented, ""},
+		{"RateLimited", http.MethodPost, `{}`, http.StatusTooManyRequests, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, "http://teeserver"+tokenSocketPath, strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tc.wantStatus {
+				t.Fatalf("got status %d (%s), want %d", resp.StatusCode, body, tc.wantStatus)
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			if got := resp.Header.Get("Content-Type"); got != "application/jwt" {
+				t.Errorf("got Content-Type %q, want application/jwt", got)
+			}
+			if string(body) != tc.wantToken {
+				t.Errorf("got token %q, want %q", body, tc.wantToken)
+			}
+		})
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Now()
+	limiter := newRateLimiter(2, time.Second)
+	limiter.now = func() time.Time { return now }
+	for i, want := range []bool{true, true, false} {
+		if got := limiter.allow(); got != want {
+			t.Errorf("request %d: got allow() %v, want %v", i, got, want)
+		}
+	}
+	now = now.Add(1500 * time.Millisecond)
+	if !limiter.allow() {
+		t.Error("request after an interval was not allowed")
+	}
+	if limiter.allow() {
+		t.Error("second request after an interval was allowed")
+	}
+	now = now.Add(500 * time.Millisecond)
+	if !limiter.allow() {
+		t.Error("request after the rest of the interval was not allowed")
+	}
+	now = now.Add(time.Hour)
+	for i, want := range []bool{true, true, false} {
+		if got := limiter.allow(); got != want {
+			t.Errorf("request %d after an hour: got allow() %v, want %v", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package launcher
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/google/go-tpm-tools/launcher/spec"
+)
+
+const (
+	// defaultTokenTmpfsSizeMiB leaves room for the token, the signed CEL and
+	// the other attestation artifacts, which grow with the measured events.
+	defaultTokenTmpfsSizeMiB = 16
+	// defaultTokenTmpfsMode lets a non-root workload traverse the directory
+	// bind-mounted into its container, but only the launcher write to it.
+	defaultTokenTmpfsMode os.FileMode = 0755
+	tokenTmpfsFlags                   = syscall.MS_NOSUID | syscall.MS_NODEV | syscall.MS_NOEXEC
+)
+
+// tokenTmpfsOptions returns the mount options of the token tmpfs of the
+// launchSpec.
+func tokenTmpfsOptions(launchSpec spec.LaunchSpec) string {
+	size := launchSpec.TokenTmpfsSizeMiB
+	if size == 0 {
+		size = defaultTokenTmpfsSizeMiB
+	}
+	mode := launchSpec.TokenTmpfsMode
+	if mode == 0 {
+		mode = defaultTokenTmpfsMode
+	}
+	return fmt.Sprintf("size=%dm,mode=%04o", size, mode.Perm())
+}
+
+// MountTokenTmpfs mounts a size-limited tmpfs on the host directory of the
+// attestation token and artifacts, so bearer tokens never reach a persistent
+// disk. It must be called before the ContainerRunner writes to the directory,
+// and returns a function unmounting the tmpfs once the container is deleted.
+func MountTokenTmpfs(launchSpec spec.LaunchSpec) (func() error, error) {
+	if err := os.MkdirAll(hostTokenPath, 0700); err != nil {
+		return nil, err
+	}
+	options := tokenTmpfsOptions(launchSpec)
+	if err := syscall.Mount("tmpfs", hostTokenPath, "tmpfs", tokenTmpfsFlags, options); err != nil {
+		return nil, fmt.Errorf("failed to mount a tmpfs with %s on %s: %v", options, hostTokenPath, err)
+	}
+	return func() error {
+		if err := syscall.Unmount(hostTokenPath, 0); err != nil {
+			return fmt.Errorf("failed to unmount the tmpfs on %s: %v", hostTokenPath, err)
+		}
+		return nil
+	}, nil
+}
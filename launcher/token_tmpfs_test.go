@@ -0,0 +1,25 @@
+package launcher
+
+import (
+	"testing"
+
+	"github.com/google/go-tpm-tools/launcher/spec"
+)
+
+func TestTokenTmpfsOptions(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		launchSpec spec.LaunchSpec
+		want       string
+	}{
+		{"Default", spec.LaunchSpec{}, "size=16m,mode=0755"},
+		{"Size", spec.LaunchSpec{TokenTmpfsSizeMiB: 4}, "size=4m,mode=0755"},
+		{"Mode", spec.LaunchSpec{TokenTmpfsMode: 0750}, "size=16m,mode=0750"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tokenTmpfsOptions(tc.launchSpec); got != tc.want {
+				t.Errorf("tokenTmpfsOptions() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+// Package aws contains a verifier.Client for running the launcher on EC2
+// instances with NitroTPM. The attestation is sent, along with the signed
+// EC2 instance identity document, to an attestation verifier deployed behind
+// IAM authorization (e.g. an API Gateway endpoint backed by a Lambda), which
+// returns a signed token.
+package aws
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/go-tpm-tools/launcher/verifier"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	// defaultService is the service name used to sign requests to an API
+	// Gateway endpoint.
+	defaultService = "execute-api"
+	nonceSize      = 32
+	// maxResponseSize limits the size of the responses read from the
+	// verifier and the instance metadata service.
+	maxResponseSize = 1 << 20
+)
+
+// NewClient creates a new client for the verifier at endpoint, in the given
+// AWS region. Requests are signed with the instance role credentials from
+// the instance metadata service. If httpClient is nil, http.DefaultClient is
+// used.
+func NewClient(endpoint string, region string, httpClient *http.Client) (verifier.Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid verifier endpoint %q: %w", endpoint, err)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" || u.Host == "" {
+		return nil, fmt.Errorf("invalid verifier endpoint %q: must be an http(s) URL", endpoint)
+	}
+	if region == "" {
+		return nil, errors.New("region must be specified")
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &awsClient{
+		endpoint:   u.String(),
+		region:     region,
+		service:    defaultService,
+		httpClient: httpClient,
+		imds:       &imdsClient{endpoint: defaultIMDSEndpoint, httpClient: httpClient},
+		now:        time.Now,
+	}, nil
+}
+
+type awsClient struct {
+	endpoint   string
+	region     string
+	service    string
+	httpClient *http.Client
+	imds       *imdsClient
+	now        func() time.Time
+}
+
+// CreateChallenge implements verifier.Client. The verifier is stateless, so a
+// random nonce is generated locally; the verifier binds it into the token.
+func (c *awsClient) CreateChallenge(ctx context.Context) (*verifier.Challenge, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return &verifier.Challenge{
+		Name:  c.endpoint,
+		Nonce: nonce,
+	}, nil
+}
+
+// verifyRequest is the body sent to the verifier.
+type verifyRequest struct {
+	Nonce []byte `json:"nonce"`
+	// Attestation is the protojson encoded attest.Attestation.
+	Attestation json.RawMessage `json:"attestation"`
+	// IdentityDocument and IdentitySignature are the EC2 instance identity
+	// document and its PKCS#7 signature by AWS.
+	IdentityDocument  []byte `json:"instance_identity_document"`
+	IdentitySignature []byte `json:"instance_identity_signature"`
+	// Audience optionally replaces the default audience of the token.
+	Audience string `json:"audience,omitempty"`
+	// Nonces are optional nonces of the workload to include in the token.
+	Nonces []string `json:"nonces,omitempty"`
+}
+
+type verifyResponse struct {
+	Token string `json:"token"`
+}
+
+// VerifyAttestation implements verifier.Client
+func (c *awsClient) VerifyAttestation(ctx context.Context, request verifier.VerifyAttestationRequest) (*verifier.VerifyAttestationResponse, error) {
+	if request.Challenge == nil || request.Attestation == nil {
+		return nil, fmt.Errorf("nil value provided in challenge")
+	}
+	if len(request.TokenAudiences) > 1 {
+		return nil, fmt.Errorf("%w: got %d audiences, at most 1 is allowed", verifier.ErrTokenAudiencesUnsupported, len(request.TokenAudiences))
+	}
+	attestation, err := protojson.Marshal(request.Attestation)
+	if err != nil {
+		return nil, err
+	}
+
+	imdsToken, err := c.imds.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := c.imds.get(ctx, imdsToken, "dynamic/instance-identity/document")
+	if err != nil {
+		return nil, err
+	}
+	sig, err := c.imds.get(ctx, imdsToken, "dynamic/instance-identity/pkcs7")
+	if err != nil {
+		return nil, err
+	}
+	creds, err := c.imds.credentials(ctx, imdsToken)
+	if err != nil {
+		return nil, err
+	}
+
+	verifyReq := verifyRequest{
+		Nonce:             request.Challenge.Nonce,
+		Attestation:       attestation,
+		IdentityDocument:  doc,
+		IdentitySignature: sig,
+		Nonces:            request.TokenNonces,
+	}
+	if len(request.TokenAudiences) > 0 {
+		verifyReq.Audience = request.TokenAudiences[0]
+	}
+	body, err := json.Marshal(verifyReq)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	signRequest(req, body, creds, c.region, c.service, c.now())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calling verifier: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("reading verifier response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("calling verifier: %s: %s", resp.Status, respBody)
+	}
+
+	var verifyResp verifyResponse
+	if err := json.Unmarshal(respBody, &verifyResp); err != nil {
+		return nil, fmt.Errorf("failed to decode verifier response: %w", err)
+	}
+	if verifyResp.Token == "" {
+		return nil, errors.New("verifier response does not contain a token")
+	}
+	return &verifier.VerifyAttestationResponse{
+		ClaimsToken: []byte(verifyResp.Token),
+	}, nil
+}
@@ -0,0 +1,134 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-tpm-tools/launcher/verifier"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const testIMDSToken = "imds-token"
+
+// newFakeServer serves both the instance metadata service and the verifier.
+func newFakeServer(t *testing.T, got *verifyRequest) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/latest/api/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get("X-aws-ec2-metadata-token-ttl-seconds") == "" {
+			http.Error(w, "bad token request", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(testIMDSToken))
+	})
+	imds := map[string]string{
+		"/latest/dynamic/instance-identity/document":           `{"instanceId":"i-1234"}`,
+		"/latest/dynamic/instance-identity/pkcs7":              "pkcs7",
+		"/latest/meta-data/iam/security-credentials/":          "test-role\n",
+		"/latest/meta-data/iam/security-credentials/test-role": `{"AccessKeyId":"AKID","SecretAccessKey":"secret","Token":"session"}`,
+	}
+	for path, value := range imds {
+		value := value
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-aws-ec2-metadata-token") != testIMDSToken {
+				http.Error(w, "missing IMDS token", http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(value))
+		})
+	}
+	mux.HandleFunc("/verify", func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || r.Header.Get(amzTokenHeader) != "session" {
+			http.Error(w, "unauthorized", http.StatusForbidden)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+			t.Fatal(err)
+		}
+		json.NewEncoder(w).Encode(verifyResponse{Token: "token"})
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestVerifyAttestation(t *testing.T) {
+	var got verifyRequest
+	server := newFakeServer(t, &got)
+	defer server.Close()
+
+	c, err := NewClient(server.URL+"/verify", "us-east-1", server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.(*awsClient).imds.endpoint = server.URL
+	c.(*awsClient).now = func() time.Time { return time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC) }
+
+	ctx := context.Background()
+	challenge, err := c.CreateChallenge(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attestation := &attestpb.Attestation{AkPub: []byte("akpub"), CanonicalEventLog: []byte("cel")}
+	resp, err := c.VerifyAttestation(ctx, verifier.VerifyAttestationRequest{Challenge: challenge, Attestation: attestation, TokenAudiences: []string{"https://rp.example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.ClaimsToken) != "token" {
+		t.Errorf("got token %q, want %q", resp.ClaimsToken, "token")
+	}
+
+	if string(got.Nonce) != string(challenge.Nonce) {
+		t.Errorf("got nonce %x, want %x", got.Nonce, challenge.Nonce)
+	}
+	if got.Audience != "https://rp.example.com" {
+		t.Errorf("got audience %q, want %q", got.Audience, "https://rp.example.com")
+	}
+	if string(got.IdentityDocument) != `{"instanceId":"i-1234"}` || string(got.IdentitySignature) != "pkcs7" {
+		t.Errorf("got identity document %q and signature %q", got.IdentityDocument, got.IdentitySignature)
+	}
+	gotAttestation := &attestpb.Attestation{}
+	if err := protojson.Unmarshal(got.Attestation, gotAttestation); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(gotAttestation, attestation) {
+		t.Errorf("got attestation %v, want %v", gotAttestation, attestation)
+	}
+}
+
+func TestVerifyAttestationUnauthorized(t *testing.T) {
+	var got verifyRequest
+	server := newFakeServer(t, &got)
+	defer server.Close()
+
+	c, err := NewClient(server.URL+"/verify", "us-east-1", server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without IMDS, there are no credentials to sign the request.
+	c.(*awsClient).imds.endpoint = server.URL + "/missing"
+	request := verifier.VerifyAttestationRequest{Challenge: &verifier.Challenge{}, Attestation: &attestpb.Attestation{}}
+	if _, err := c.VerifyAttestation(context.Background(), request); err == nil {
+		t.Error("expected VerifyAttestation to fail without IMDS")
+	}
+}
+
+func TestNewClientBadConfig(t *testing.T) {
+	for _, testcase := range []struct {
+		endpoint string
+		region   string
+	}{
+		{"", "us-east-1"},
+		{"example.com/verify", "us-east-1"},
+		{"https://example.com/verify", ""},
+	} {
+		if _, err := NewClient(testcase.endpoint, testcase.region, nil); err == nil {
+			t.Errorf("NewClient(%q, %q) should fail", testcase.endpoint, testcase.region)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultIMDSEndpoint = "http://169.254.169.254"
+	imdsTokenTTLSeconds = "60"
+)
+
+// imdsClient is a client of the EC2 instance metadata service, using IMDSv2
+// session tokens.
+type imdsClient struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+func (c *imdsClient) token(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.endpoint+"/latest/api/token", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", imdsTokenTTLSeconds)
+	token, err := c.do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get IMDS token: %w", err)
+	}
+	return string(token), nil
+}
+
+func (c *imdsClient) get(ctx context.Context, token string, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+"/latest/"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token", token)
+	data, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s from IMDS: %w", path, err)
+	}
+	return data, nil
+}
+
+// credentials returns the credentials of the instance role.
+func (c *imdsClient) credentials(ctx context.Context, token string) (credentials, error) {
+	const credsPath = "meta-data/iam/security-credentials/"
+	roles, err := c.get(ctx, token, credsPath)
+	if err != nil {
+		return credentials{}, err
+	}
+	role := strings.TrimSpace(strings.SplitN(string(roles), "\n", 2)[0])
+	if role == "" {
+		return credentials{}, fmt.Errorf("no IAM role attached to the instance")
+	}
+	data, err := c.get(ctx, token, credsPath+role)
+	if err != nil {
+		return credentials{}, err
+	}
+	var creds credentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return credentials{}, fmt.Errorf("failed to decode credentials of role %s: %w", role, err)
+	}
+	return creds, nil
+}
+
+func (c *imdsClient) do(req *http.Request) ([]byte, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", resp.Status, data)
+	}
+	return data, nil
+}
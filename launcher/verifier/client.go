@@ -4,6 +4,7 @@ package verifier
 
 import (
 	"context"
+	"errors"
 
 	attestpb "github.com/google/go-tpm-tools/proto/attest"
 )
@@ -30,6 +31,94 @@ type VerifyAttestationRequest struct {
 	Challenge      *Challenge
 	GcpCredentials [][]byte
 	Attestation    *attestpb.Attestation
+	// TokenAudiences optionally replace the default audience of the claims
+	// token. Clients not supporting them, or not as many, return
+	// ErrTokenAudiencesUnsupported: the Google API issues tokens for a
+	// single audience, and MAA for its own.
+	TokenAudiences []string
+	// TokenNonces are optional nonces of the workload to include in the claims
+	// token, binding it to a request of a relying party. Clients not
+	// supporting them, like that of MAA, return ErrTokenNoncesUnsupported.
+	TokenNonces []string
+	// CanonicalEventLogDigest is the SHA-256 cel.Digest of the Canonical
+	// Event Log of the Attestation. Verifiers supporting it check that it
+	// matches the log and include it in the claims, others ignore it.
+	CanonicalEventLogDigest []byte
+}
+
+// ErrTokenAudiencesUnsupported is returned by clients which cannot issue
+// claims tokens with the requested TokenAudiences.
+var ErrTokenAudiencesUnsupported = errors.New("verifier does not support custom token audiences")
+
+// ErrTokenNoncesUnsupported is returned by clients which cannot issue claims
+// tokens with the requested TokenNonces.
+var ErrTokenNoncesUnsupported = errors.New("verifier does not support custom token nonces")
+
+// WithTokenAudiences returns a Client requesting claims tokens for the
+// audiences from client, unless a request sets its own TokenAudiences.
+func WithTokenAudiences(client Client, audiences []string) Client {
+	return &audienceClient{client, audiences}
+}
+
+type audienceClient struct {
+	Client
+	audiences []string
+}
+
+func (c *audienceClient) VerifyAttestation(ctx context.Context, request VerifyAttestationRequest) (*VerifyAttestationResponse, error) {
+	if len(request.TokenAudiences) == 0 {
+		request.TokenAudiences = c.audiences
+	}
+	return c.Client.VerifyAttestation(ctx, request)
+}
+
+// VerifyHedged implements HedgingClient.
+func (c *audienceClient) VerifyHedged(ctx context.Context, newRequest RequestFunc) (*VerifyAttestationResponse, *VerifyAttestationRequest, error) {
+	return VerifyHedged(ctx, c.Client, func(ctx context.Context, challenge *Challenge) (VerifyAttestationRequest, error) {
+		request, err := newRequest(ctx, challenge)
+		if err == nil && len(request.TokenAudiences) == 0 {
+			request.TokenAudiences = c.audiences
+		}
+		return request, err
+	})
+}
+
+// RequestFunc returns the request verifying a new attestation for the
+// challenge.
+type RequestFunc func(ctx context.Context, challenge *Challenge) (VerifyAttestationRequest, error)
+
+// HedgingClient is a Client which can verify attestations with several
+// verifiers concurrently. As a challenge is only valid once, and in the
+// verifier which issued it, each verifier needs its own challenge, and so its
+// own attestation.
+type HedgingClient interface {
+	Client
+	// VerifyHedged creates a challenge, and verifies the request newRequest
+	// returns for it. It may do so with several verifiers concurrently, and
+	// returns the first successful response and its request.
+	VerifyHedged(ctx context.Context, newRequest RequestFunc) (*VerifyAttestationResponse, *VerifyAttestationRequest, error)
+}
+
+// VerifyHedged verifies the request newRequest returns for a challenge of
+// client, hedged if client is a HedgingClient. It returns the response and
+// its request.
+func VerifyHedged(ctx context.Context, client Client, newRequest RequestFunc) (*VerifyAttestationResponse, *VerifyAttestationRequest, error) {
+	if hedging, ok := client.(HedgingClient); ok {
+		return hedging.VerifyHedged(ctx, newRequest)
+	}
+	challenge, err := client.CreateChallenge(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	request, err := newRequest(ctx, challenge)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := client.VerifyAttestation(ctx, request)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, &request, nil
 }
 
 // VerifyAttestationResponse is the response from a successful
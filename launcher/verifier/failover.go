@@ -0,0 +1,214 @@
+package verifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultCooldown is how long a failed client is skipped by default.
+	defaultCooldown = time.Minute
+	// maxPendingChallenges bounds the challenges remembered for
+	// VerifyAttestation, as some are never verified, e.g. when fetching the
+	// principal tokens fails.
+	maxPendingChallenges = 64
+)
+
+// FailoverOptions configure a client created by NewFailoverClient.
+type FailoverOptions struct {
+	// Cooldown is how long a client which failed a call is skipped, one
+	// minute by default. Clients are still used if all of them failed.
+	Cooldown time.Duration
+	// HedgeDelay, if non-zero, hedges VerifyHedged: when an attestation has
+	// no response after HedgeDelay, another one is verified by the next
+	// client, with a challenge of its own. The first successful response is
+	// used, and the other attestations are canceled.
+	HedgeDelay time.Duration
+}
+
+// NewFailoverClient returns a Client using the first healthy client of
+// clients, e.g. the verifiers of several regions in order of preference. A
+// client is unhealthy for the Cooldown after it failed a call.
+//
+// Challenges are verified by the client which created them, as they are only
+// valid in the verifier which issued them. The returned Client is a
+// HedgingClient, whose VerifyHedged also fails over to the next client as
+// soon as an attestation fails.
+func NewFailoverClient(clients []Client, opts FailoverOptions) Client {
+	if opts.Cooldown == 0 {
+		opts.Cooldown = defaultCooldown
+	}
+	return &failoverClient{
+		clients:        clients,
+		opts:           opts,
+		unhealthyUntil: make([]time.Time, len(clients)),
+		challenges:     make(map[string]int),
+		now:            time.Now,
+	}
+}
+
+type failoverClient struct {
+	clients []Client
+	opts    FailoverOptions
+	now     func() time.Time
+
+	mu             sync.Mutex
+	unhealthyUntil []time.Time
+	// challenges maps the names of the challenges created, and not yet
+	// verified, to the index of their client.
+	challenges map[string]int
+}
+
+// order returns the indexes of the clients to try: the healthy ones first,
+// then the unhealthy ones, both in order of preference.
+func (c *failoverClient) order() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	var healthy, unhealthy []int
+	for i, until := range c.unhealthyUntil {
+		if now.Before(until) {
+			unhealthy = append(unhealthy, i)
+		} else {
+			healthy = append(healthy, i)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+func (c *failoverClient) markUnhealthy(i int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.unhealthyUntil[i] = c.now().Add(c.opts.Cooldown)
+}
+
+// CreateChallenge implements Client.
+func (c *failoverClient) CreateChallenge(ctx context.Context) (*Challenge, error) {
+	if len(c.clients) == 0 {
+		return nil, errors.New("no verifier client")
+	}
+	var errs []error
+	for _, i := range c.order() {
+		challenge, err := c.clients[i].CreateChallenge(ctx)
+		if err == nil {
+			c.mu.Lock()
+			if len(c.challenges) >= maxPendingChallenges {
+				c.challenges = make(map[string]int)
+			}
+			c.challenges[challenge.Name] = i
+			c.mu.Unlock()
+			return challenge, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		c.markUnhealthy(i)
+		errs = append(errs, fmt.Errorf("verifier %d: %w", i, err))
+	}
+	return nil, fmt.Errorf("all verifiers failed to create a challenge: %v", errs)
+}
+
+// VerifyAttestation implements Client.
+func (c *failoverClient) VerifyAttestation(ctx context.Context, request VerifyAttestationRequest) (*VerifyAttestationResponse, error) {
+	if request.Challenge == nil {
+		return nil, errors.New("nil challenge")
+	}
+	c.mu.Lock()
+	i, ok := c.challenges[request.Challenge.Name]
+	delete(c.challenges, request.Challenge.Name)
+	c.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("challenge %q was not created by this client", request.Challenge.Name)
+	}
+
+	resp, err := c.clients[i].VerifyAttestation(ctx, request)
+	if err != nil && ctx.Err() == nil {
+		c.markUnhealthy(i)
+	}
+	return resp, err
+}
+
+type hedgedResult struct {
+	client  int
+	resp    *VerifyAttestationResponse
+	request *VerifyAttestationRequest
+	err     error
+	// requestErr is set if newRequest failed, rather than the client.
+	requestErr bool
+}
+
+// VerifyHedged implements HedgingClient. The attestations are verified by the
+// clients in the order of CreateChallenge, the next one starting after the
+// HedgeDelay, or as soon as one failed.
+func (c *failoverClient) VerifyHedged(ctx context.Context, newRequest RequestFunc) (*VerifyAttestationResponse, *VerifyAttestationRequest, error) {
+	if len(c.clients) == 0 {
+		return nil, nil, errors.New("no verifier client")
+	}
+	attemptCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	order := c.order()
+	results := make(chan hedgedResult, len(order))
+	next := 0
+	start := func() {
+		i := order[next]
+		next++
+		go func() {
+			results <- c.attempt(attemptCtx, i, newRequest)
+		}()
+	}
+
+	start()
+	pending := 1
+	// Without a HedgeDelay, the nil channel never fires.
+	var hedge <-chan time.Time
+	var timer *time.Timer
+	if c.opts.HedgeDelay > 0 {
+		timer = time.NewTimer(c.opts.HedgeDelay)
+		defer timer.Stop()
+		hedge = timer.C
+	}
+	var errs []error
+	for {
+		select {
+		case <-hedge:
+			if next < len(order) {
+				start()
+				pending++
+				timer.Reset(c.opts.HedgeDelay)
+			}
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				return result.resp, result.request, nil
+			}
+			if result.requestErr || ctx.Err() != nil {
+				return nil, nil, result.err
+			}
+			c.markUnhealthy(result.client)
+			errs = append(errs, fmt.Errorf("verifier %d: %w", result.client, result.err))
+			if next < len(order) {
+				start()
+				pending++
+			} else if pending == 0 {
+				return nil, nil, fmt.Errorf("all verifiers failed to verify the attestation: %v", errs)
+			}
+		}
+	}
+}
+
+// attempt verifies the request of newRequest for a challenge of client i.
+func (c *failoverClient) attempt(ctx context.Context, i int, newRequest RequestFunc) hedgedResult {
+	challenge, err := c.clients[i].CreateChallenge(ctx)
+	if err != nil {
+		return hedgedResult{client: i, err: err}
+	}
+	request, err := newRequest(ctx, challenge)
+	if err != nil {
+		return hedgedResult{client: i, err: err, requestErr: true}
+	}
+	resp, err := c.clients[i].VerifyAttestation(ctx, request)
+	return hedgedResult{client: i, resp: resp, request: &request, err: err}
+}
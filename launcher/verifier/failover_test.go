@@ -0,0 +1,189 @@
+package verifier
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type stubClient struct {
+	mu        sync.Mutex
+	name      string
+	createErr error
+	verifyErr error
+	delay     time.Duration
+	creates   int
+	verifies  int
+}
+
+func (c *stubClient) CreateChallenge(context.Context) (*Challenge, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.creates++
+	if c.createErr != nil {
+		return nil, c.createErr
+	}
+	return &Challenge{Name: c.name + "/challenge"}, nil
+}
+
+func (c *stubClient) VerifyAttestation(ctx context.Context, _ VerifyAttestationRequest) (*VerifyAttestationResponse, error) {
+	c.mu.Lock()
+	c.verifies++
+	delay := c.delay
+	// Only the first call is slow, so hedging gets a fast response.
+	c.delay = 0
+	c.mu.Unlock()
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if c.verifyErr != nil {
+		return nil, c.verifyErr
+	}
+	return &VerifyAttestationResponse{ClaimsToken: []byte(c.name)}, nil
+}
+
+func TestFailoverClient(t *testing.T) {
+	ctx := context.Background()
+	primary := &stubClient{name: "primary", createErr: errors.New("unavailable")}
+	secondary := &stubClient{name: "secondary"}
+	now := time.Unix(0, 0)
+	c := NewFailoverClient([]Client{primary, secondary}, FailoverOptions{}).(*failoverClient)
+	c.now = func() time.Time { return now }
+
+	challenge, err := c.CreateChallenge(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.VerifyAttestation(ctx, VerifyAttestationRequest{Challenge: challenge})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(resp.ClaimsToken); got != "secondary" {
+		t.Errorf("got token from %q, want secondary", got)
+	}
+
+	// The primary is skipped during the cooldown.
+	primary.createErr = nil
+	if _, err := c.CreateChallenge(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if primary.creates != 1 {
+		t.Errorf("primary got %d CreateChallenge calls during the cooldown, want 1", primary.creates)
+	}
+
+	now = now.Add(defaultCooldown)
+	challenge, err = c.CreateChallenge(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if challenge.Name != "primary/challenge" {
+		t.Errorf("got challenge %q after the cooldown, want primary/challenge", challenge.Name)
+	}
+}
+
+func TestFailoverClientAllFail(t *testing.T) {
+	c := NewFailoverClient([]Client{
+		&stubClient{createErr: errors.New("unavailable")},
+		&stubClient{createErr: errors.New("unavailable")},
+	}, FailoverOptions{})
+	if _, err := c.CreateChallenge(context.Background()); err == nil {
+		t.Error("CreateChallenge() succeeded, want an error")
+	}
+}
+
+func TestFailoverClientUnknownChallenge(t *testing.T) {
+	c := NewFailoverClient([]Client{&stubClient{name: "primary"}}, FailoverOptions{})
+	_, err := c.VerifyAttestation(context.Background(), VerifyAttestationRequest{Challenge: &Challenge{Name: "other"}})
+	if err == nil {
+		t.Error("VerifyAttestation() succeeded with an unknown challenge, want an error")
+	}
+}
+
+// requestRecorder returns a RequestFunc recording the challenges of the
+// requests.
+func requestRecorder() (RequestFunc, func() []string) {
+	var mu sync.Mutex
+	var challenges []string
+	newRequest := func(_ context.Context, challenge *Challenge) (VerifyAttestationRequest, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		challenges = append(challenges, challenge.Name)
+		return VerifyAttestationRequest{Challenge: challenge}, nil
+	}
+	return newRequest, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, challenges...)
+	}
+}
+
+func TestFailoverClientHedging(t *testing.T) {
+	ctx := context.Background()
+	slow := &stubClient{name: "primary", delay: time.Minute}
+	fast := &stubClient{name: "secondary"}
+	c := NewFailoverClient([]Client{slow, fast}, FailoverOptions{HedgeDelay: 10 * time.Millisecond}).(HedgingClient)
+	newRequest, challenges := requestRecorder()
+	start := time.Now()
+	resp, request, err := c.VerifyHedged(ctx, newRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("VerifyHedged() took %v, want the hedged response", elapsed)
+	}
+	// The hedged attestation is for a challenge of the other verifier.
+	if got := string(resp.ClaimsToken); got != "secondary" || request.Challenge.Name != "secondary/challenge" {
+		t.Errorf("got token from %q for challenge %q, want secondary", got, request.Challenge.Name)
+	}
+	if got := challenges(); len(got) != 2 || got[0] != "primary/challenge" || got[1] != "secondary/challenge" {
+		t.Errorf("got requests for challenges %q, want one per verifier", got)
+	}
+}
+
+func TestFailoverClientVerifyHedgedFailover(t *testing.T) {
+	ctx := context.Background()
+	primary := &stubClient{name: "primary", verifyErr: errors.New("unavailable")}
+	secondary := &stubClient{name: "secondary"}
+	c := NewFailoverClient([]Client{primary, secondary}, FailoverOptions{}).(*failoverClient)
+	newRequest, challenges := requestRecorder()
+	resp, _, err := c.VerifyHedged(ctx, newRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(resp.ClaimsToken); got != "secondary" || len(challenges()) != 2 {
+		t.Errorf("got token from %q after %d requests, want secondary after 2", got, len(challenges()))
+	}
+	if order := c.order(); order[0] != 1 {
+		t.Errorf("got order %v, want the failed primary last", order)
+	}
+
+	secondary.verifyErr = errors.New("unavailable")
+	if _, _, err := c.VerifyHedged(ctx, newRequest); err == nil {
+		t.Error("VerifyHedged() succeeded with all verifiers failing, want an error")
+	}
+
+	// A request which cannot be created is not a verifier failure.
+	creates := primary.creates + secondary.creates
+	requestErr := errors.New("no TPM")
+	_, _, err = c.VerifyHedged(ctx, func(context.Context, *Challenge) (VerifyAttestationRequest, error) {
+		return VerifyAttestationRequest{}, requestErr
+	})
+	if !errors.Is(err, requestErr) || primary.creates+secondary.creates != creates+1 {
+		t.Errorf("VerifyHedged() = %v after %d challenges, want %v after one", err, primary.creates+secondary.creates-creates, requestErr)
+	}
+}
+
+func TestVerifyHedgedNotHedging(t *testing.T) {
+	newRequest, challenges := requestRecorder()
+	resp, _, err := VerifyHedged(context.Background(), &stubClient{name: "single"}, newRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(resp.ClaimsToken); got != "single" || len(challenges()) != 1 {
+		t.Errorf("got token from %q after %d requests, want single after one", got, len(challenges()))
+	}
+}
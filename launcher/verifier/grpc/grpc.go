@@ -0,0 +1,123 @@
+// Package grpc contains a client of the AttestationVerifier gRPC service, like
+// the reference server of the server/grpcservice package.
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-tpm-tools/launcher/verifier"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	vpb "github.com/google/go-tpm-tools/proto/verifier"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxMessageSize is the size of the requests above which the attestation is
+// streamed, well below the default 4 MiB limit of gRPC servers. It is a
+// variable for tests.
+var maxMessageSize = 1 << 20
+
+// chunkSize is the size of the event log chunks of streamed attestations.
+const chunkSize = 512 << 10
+
+type grpcClient struct {
+	client vpb.AttestationVerifierClient
+	parent string
+}
+
+// NewClient creates a client performing attestations in a particular project
+// and region, with a client of the gRPC service, e.g.:
+//
+//	conn, err := grpc.Dial(addr, opts...)
+//	...
+//	client := NewClient(vpb.NewAttestationVerifierClient(conn), projectID, region)
+func NewClient(client vpb.AttestationVerifierClient, projectID string, region string) verifier.Client {
+	return &grpcClient{client, fmt.Sprintf("projects/%s/locations/%s", projectID, region)}
+}
+
+// CreateChallenge implements verifier.Client.
+func (c *grpcClient) CreateChallenge(ctx context.Context) (*verifier.Challenge, error) {
+	chal, err := c.client.CreateChallenge(ctx, &vpb.CreateChallengeRequest{Parent: c.parent})
+	if err != nil {
+		return nil, fmt.Errorf("calling CreateChallenge: %w", err)
+	}
+	return &verifier.Challenge{Name: chal.GetName(), Nonce: chal.GetNonce()}, nil
+}
+
+// VerifyAttestation implements verifier.Client. Attestations whose event logs
+// make the request too large are streamed.
+func (c *grpcClient) VerifyAttestation(ctx context.Context, request verifier.VerifyAttestationRequest) (*verifier.VerifyAttestationResponse, error) {
+	if request.Challenge == nil || request.Attestation == nil {
+		return nil, fmt.Errorf("nil value provided in challenge")
+	}
+	// The API issues tokens for a single audience.
+	if len(request.TokenAudiences) > 1 {
+		return nil, fmt.Errorf("%w: got %d audiences, at most 1 is allowed", verifier.ErrTokenAudiencesUnsupported, len(request.TokenAudiences))
+	}
+	req := &vpb.VerifyAttestationRequest{
+		Challenge:   request.Challenge.Name,
+		Attestation: request.Attestation,
+		IdTokens:    request.GcpCredentials,
+	}
+	if len(request.TokenAudiences) > 0 || len(request.TokenNonces) > 0 {
+		req.TokenOptions = &vpb.TokenOptions{Nonce: request.TokenNonces}
+		if len(request.TokenAudiences) > 0 {
+			req.TokenOptions.Audience = request.TokenAudiences[0]
+		}
+	}
+
+	var resp *vpb.VerifyAttestationResponse
+	var err error
+	if proto.Size(req) <= maxMessageSize {
+		resp, err = c.client.VerifyAttestation(ctx, req)
+	} else {
+		resp, err = c.verifyStream(ctx, req)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("calling VerifyAttestation: %w", err)
+	}
+	return &verifier.VerifyAttestationResponse{ClaimsToken: []byte(resp.GetClaimsToken())}, nil
+}
+
+// verifyStream sends the request without the event logs, then their chunks.
+func (c *grpcClient) verifyStream(ctx context.Context, req *vpb.VerifyAttestationRequest) (*vpb.VerifyAttestationResponse, error) {
+	attestation := proto.Clone(req.Attestation).(*pb.Attestation)
+	eventLog, celLog, imaLog := attestation.EventLog, attestation.CanonicalEventLog, attestation.ImaLog
+	attestation.EventLog, attestation.CanonicalEventLog, attestation.ImaLog = nil, nil, nil
+
+	stream, err := c.client.VerifyAttestationStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	first := &vpb.VerifyAttestationRequest{Challenge: req.Challenge, Attestation: attestation, IdTokens: req.IdTokens, TokenOptions: req.TokenOptions}
+	if err := stream.Send(&vpb.VerifyAttestationChunk{Chunk: &vpb.VerifyAttestationChunk_Request{Request: first}}); err != nil {
+		return nil, err
+	}
+	chunks := []struct {
+		log   []byte
+		chunk func([]byte) *vpb.VerifyAttestationChunk
+	}{
+		{eventLog, func(b []byte) *vpb.VerifyAttestationChunk {
+			return &vpb.VerifyAttestationChunk{Chunk: &vpb.VerifyAttestationChunk_EventLog{EventLog: b}}
+		}},
+		{celLog, func(b []byte) *vpb.VerifyAttestationChunk {
+			return &vpb.VerifyAttestationChunk{Chunk: &vpb.VerifyAttestationChunk_CanonicalEventLog{CanonicalEventLog: b}}
+		}},
+		{imaLog, func(b []byte) *vpb.VerifyAttestationChunk {
+			return &vpb.VerifyAttestationChunk{Chunk: &vpb.VerifyAttestationChunk_ImaLog{ImaLog: b}}
+		}},
+	}
+	for _, l := range chunks {
+		for log := l.log; len(log) > 0; {
+			n := chunkSize
+			if n > len(log) {
+				n = len(log)
+			}
+			if err := stream.Send(l.chunk(log[:n])); err != nil {
+				return nil, err
+			}
+			log = log[n:]
+		}
+	}
+	return stream.CloseAndRecv()
+}
@@ -0,0 +1,118 @@
+package grpc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm-tools/launcher/agent"
+	"github.com/google/go-tpm-tools/launcher/verifier"
+	vpb "github.com/google/go-tpm-tools/proto/verifier"
+	"github.com/google/go-tpm-tools/server"
+	"github.com/google/go-tpm-tools/server/grpcservice"
+	"github.com/google/go-tpm-tools/server/httpservice"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Make sure the client works with the reference gRPC verifier, with and
+// without streaming the event logs.
+func TestClientWithGRPCService(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		maxMessageSize int
+	}{
+		{"Unary", maxMessageSize},
+		{"Stream", 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func(size int) { maxMessageSize = size }(maxMessageSize)
+			maxMessageSize = tc.maxMessageSize
+			testClientWithGRPCService(t)
+		})
+	}
+}
+
+func testClientWithGRPCService(t *testing.T) {
+	test.SkipForRealTPM(t)
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	akPub := ak.PublicKey()
+	ak.Close()
+
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service, err := httpservice.New(httpservice.Config{
+		Signer:     signer,
+		Issuer:     "https://verifier.example.com",
+		VerifyOpts: server.VerifyOpts{TrustedAKs: []crypto.PublicKey{akPub}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis := bufconn.Listen(1 << 20)
+	srv := grpclib.NewServer()
+	vpb.RegisterAttestationVerifierServer(srv, grpcservice.New(service))
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	ctx := context.Background()
+	conn, err := grpclib.DialContext(ctx, "bufnet",
+		grpclib.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpclib.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	grpcClient := NewClient(vpb.NewAttestationVerifierClient(conn), "test-project", "us-central1")
+	noPrincipals := func(string) ([][]byte, error) { return nil, nil }
+	token, err := agent.CreateAttestationAgent(tpm, client.AttestationKeyECC, grpcClient, noPrincipals).Attest(ctx)
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+
+	keyFunc := func(token *jwt.Token) (interface{}, error) { return signer.Public(), nil }
+	claims := &jwt.RegisteredClaims{}
+	if _, err := jwt.ParseWithClaims(string(token), claims, keyFunc); err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+	if !claims.VerifyIssuer("https://verifier.example.com", true) {
+		t.Errorf("got iss %q", claims.Issuer)
+	}
+
+	audienceClient := verifier.WithTokenAudiences(grpcClient, []string{"https://rp.example.com"})
+	attester := agent.CreateAttestationAgent(tpm, client.AttestationKeyECC, audienceClient, noPrincipals).(agent.NonceAttester)
+	token, err = attester.AttestWithNonces(ctx, []string{"nonce-0001", "nonce-0002"})
+	if err != nil {
+		t.Fatalf("failed to attest with an audience and nonces: %v", err)
+	}
+	nonceClaims := &struct {
+		jwt.RegisteredClaims
+		Nonces []string `json:"eat_nonce"`
+	}{}
+	if _, err := jwt.ParseWithClaims(string(token), nonceClaims, keyFunc); err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+	if !nonceClaims.VerifyAudience("https://rp.example.com", true) {
+		t.Errorf("got aud %q, want the requested audience", nonceClaims.Audience)
+	}
+	if len(nonceClaims.Nonces) != 2 || nonceClaims.Nonces[0] != "nonce-0001" || nonceClaims.Nonces[1] != "nonce-0002" {
+		t.Errorf("got eat_nonce %q, want the requested nonces", nonceClaims.Nonces)
+	}
+}
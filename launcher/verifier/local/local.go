@@ -0,0 +1,203 @@
+// Package local contains a verifier.Client which verifies attestations
+// in-process with the server package and signs its own claims tokens. It is
+// meant for development, testing, and private deployments without a hosted
+// attestation service.
+package local
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/launcher/verifier"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/server"
+)
+
+const (
+	// DefaultIssuer is the issuer of tokens signed by the local verifier.
+	DefaultIssuer = "https://localhost/go-tpm-tools/verifier"
+	// DefaultAudience is the audience of tokens signed by the local verifier.
+	DefaultAudience = "https://localhost/go-tpm-tools/workload"
+	// DefaultTokenLifetime is how long tokens signed by the local verifier
+	// are valid for.
+	DefaultTokenLifetime = time.Hour
+	// challengeLifetime is how long a challenge can be used for.
+	challengeLifetime = 5 * time.Minute
+	nonceSize         = 32
+)
+
+// Claims are the claims of the tokens signed by the local verifier.
+type Claims struct {
+	jwt.RegisteredClaims
+	SecureBoot bool                    `json:"secboot"`
+	HWModel    string                  `json:"hwmodel"`
+	Container  *server.ContainerClaims `json:"container,omitempty"`
+	// CELDigest is the SHA-256 cel.Digest of the Canonical Event Log of the
+	// attestation, "sha256:" followed by its hex encoding.
+	CELDigest string `json:"cel_digest,omitempty"`
+	// Nonces are the TokenNonces of the request, as in EAT.
+	Nonces []string `json:"eat_nonce,omitempty"`
+}
+
+// NewClient creates a client which verifies attestations with
+// server.VerifyAttestation using opts, and signs claims tokens with signer.
+// The nonce of opts is replaced with the nonce of each challenge. Only RSA and
+// ECDSA P-256 signers are supported. GcpCredentials in the requests are
+// ignored, TokenAudiences replace DefaultAudience, and TokenNonces are
+// included as the eat_nonce claim.
+func NewClient(signer crypto.Signer, opts server.VerifyOpts) (verifier.Client, error) {
+	var method jwt.SigningMethod
+	switch key := signer.(type) {
+	case *rsa.PrivateKey:
+		method = jwt.SigningMethodRS256
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported ECDSA curve %s", key.Curve.Params().Name)
+		}
+		method = jwt.SigningMethodES256
+	default:
+		return nil, fmt.Errorf("unsupported signer type %T", signer)
+	}
+	return &localClient{
+		signer:     signer,
+		method:     method,
+		opts:       opts,
+		challenges: make(map[string]pendingChallenge),
+		now:        time.Now,
+	}, nil
+}
+
+type pendingChallenge struct {
+	nonce   []byte
+	expires time.Time
+}
+
+type localClient struct {
+	signer crypto.Signer
+	method jwt.SigningMethod
+	opts   server.VerifyOpts
+	now    func() time.Time
+
+	mu         sync.Mutex
+	challenges map[string]pendingChallenge
+}
+
+// CreateChallenge implements verifier.Client. Each challenge can be used once.
+func (c *localClient) CreateChallenge(ctx context.Context) (*verifier.Challenge, error) {
+	id := make([]byte, 16)
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	name := "challenges/" + hex.EncodeToString(id)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for n, chal := range c.challenges {
+		if now.After(chal.expires) {
+			delete(c.challenges, n)
+		}
+	}
+	c.challenges[name] = pendingChallenge{nonce, now.Add(challengeLifetime)}
+	return &verifier.Challenge{Name: name, Nonce: nonce}, nil
+}
+
+// VerifyAttestation implements verifier.Client
+func (c *localClient) VerifyAttestation(ctx context.Context, request verifier.VerifyAttestationRequest) (*verifier.VerifyAttestationResponse, error) {
+	if request.Challenge == nil || request.Attestation == nil {
+		return nil, fmt.Errorf("nil value provided in challenge")
+	}
+	nonce, err := c.consumeChallenge(request.Challenge.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := c.opts
+	opts.Nonce = nonce
+	state, err := server.VerifyAttestation(request.Attestation, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify attestation: %w", err)
+	}
+	celDigest, err := canonicalEventLogDigest(request.Attestation.GetCanonicalEventLog())
+	if err != nil {
+		return nil, err
+	}
+	if request.CanonicalEventLogDigest != nil && !bytes.Equal(request.CanonicalEventLogDigest, celDigest) {
+		return nil, errors.New("canonical event log digest does not match the canonical event log")
+	}
+
+	audience := []string{DefaultAudience}
+	if len(request.TokenAudiences) > 0 {
+		audience = request.TokenAudiences
+	}
+	now := c.now()
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    DefaultIssuer,
+			Audience:  audience,
+			Subject:   subject(state),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(DefaultTokenLifetime)),
+		},
+		SecureBoot: state.GetSecureBoot().GetEnabled(),
+		HWModel:    state.GetPlatform().GetTechnology().String(),
+		Container:  server.NewContainerClaims(state.GetCos().GetContainer()),
+		CELDigest:  "sha256:" + hex.EncodeToString(celDigest),
+		Nonces:     request.TokenNonces,
+	}
+	token, err := jwt.NewWithClaims(c.method, claims).SignedString(c.signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign token: %w", err)
+	}
+	return &verifier.VerifyAttestationResponse{ClaimsToken: []byte(token)}, nil
+}
+
+// canonicalEventLogDigest returns the SHA-256 cel.Digest of the encoded CEL.
+func canonicalEventLogDigest(rawCEL []byte) ([]byte, error) {
+	decodedCEL, err := cel.DecodeToCEL(bytes.NewBuffer(rawCEL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode canonical event log: %w", err)
+	}
+	return decodedCEL.Digest(crypto.SHA256)
+}
+
+// consumeChallenge returns the nonce of the named challenge, which can no
+// longer be used afterwards.
+func (c *localClient) consumeChallenge(name string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	chal, ok := c.challenges[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown or already used challenge %q", name)
+	}
+	delete(c.challenges, name)
+	if c.now().After(chal.expires) {
+		return nil, errors.New("challenge expired")
+	}
+	return chal.nonce, nil
+}
+
+// subject returns the GCE instance URL if known.
+func subject(state *pb.MachineState) string {
+	if info := state.GetPlatform().GetInstanceInfo(); info != nil {
+		return server.GCEInstanceURL(info)
+	}
+	return ""
+}
@@ -0,0 +1,247 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm-tools/launcher/agent"
+	"github.com/google/go-tpm-tools/launcher/verifier"
+	"github.com/google/go-tpm-tools/server"
+)
+
+func noPrincipals(audience string) ([][]byte, error) {
+	return nil, nil
+}
+
+func TestLocalVerifier(t *testing.T) {
+	test.SkipForRealTPM(t)
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifierClient, err := NewClient(signer, server.VerifyOpts{TrustedAKs: []crypto.PublicKey{ak.PublicKey()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attestAgent := agent.CreateAttestationAgent(tpm, client.AttestationKeyECC, verifierClient, noPrincipals)
+	imageRef := "docker.io/bazel/experimental/test:latest"
+	if err := attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.ImageRefType, EventContent: []byte(imageRef)}); err != nil {
+		t.Fatal(err)
+	}
+	tokenBytes, err := attestAgent.Attest(context.Background())
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+
+	claims := &Claims{}
+	keyFunc := func(token *jwt.Token) (interface{}, error) { return signer.Public(), nil }
+	if _, err := jwt.ParseWithClaims(string(tokenBytes), claims, keyFunc); err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+	if !claims.VerifyIssuer(DefaultIssuer, true) || !claims.VerifyAudience(DefaultAudience, true) {
+		t.Errorf("got iss %q and aud %v", claims.Issuer, claims.Audience)
+	}
+	if claims.Container == nil || claims.Container.ImageReference != imageRef {
+		t.Errorf("got container claims %+v, want image reference %q", claims.Container, imageRef)
+	}
+	if !strings.HasPrefix(claims.CELDigest, "sha256:") {
+		t.Errorf("got CEL digest claim %q, want a SHA-256 digest", claims.CELDigest)
+	}
+}
+
+func TestCELDigestMismatch(t *testing.T) {
+	test.SkipForRealTPM(t)
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifierClient, err := NewClient(signer, server.VerifyOpts{TrustedAKs: []crypto.PublicKey{ak.PublicKey()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	celog := &cel.CEL{}
+	event := cel.CosTlv{EventType: cel.ImageRefType, EventContent: []byte("docker.io/bazel/experimental/test:latest")}
+	if err := celog.AppendEvent(tpm, cel.CosEventPCR, []crypto.Hash{crypto.SHA256, crypto.SHA1}, event); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := celog.EncodeCEL(&buf); err != nil {
+		t.Fatal(err)
+	}
+	challenge, err := verifierClient.CreateChallenge(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: challenge.Nonce, CanonicalEventLog: buf.Bytes()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := verifier.VerifyAttestationRequest{
+		Challenge:               challenge,
+		Attestation:             attestation,
+		CanonicalEventLogDigest: make([]byte, 32),
+	}
+	if _, err := verifierClient.VerifyAttestation(ctx, request); err == nil {
+		t.Error("VerifyAttestation succeeded with a CEL digest not matching the CEL")
+	}
+}
+
+func TestChallengeCanOnlyBeUsedOnce(t *testing.T) {
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifierClient, err := NewClient(signer, server.VerifyOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := verifierClient.(*localClient)
+	ctx := context.Background()
+
+	challenge, err := c.CreateChallenge(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.consumeChallenge(challenge.Name); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.consumeChallenge(challenge.Name); err == nil {
+		t.Error("challenge was used twice")
+	}
+
+	expired, err := c.CreateChallenge(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.now = func() time.Time { return time.Now().Add(challengeLifetime + time.Minute) }
+	if _, err := c.consumeChallenge(expired.Name); err == nil {
+		t.Error("expired challenge was accepted")
+	}
+
+	request := verifier.VerifyAttestationRequest{Challenge: &verifier.Challenge{Name: "unknown"}}
+	if _, err := c.VerifyAttestation(ctx, request); err == nil {
+		t.Error("VerifyAttestation succeeded with a nil attestation")
+	}
+}
+
+func TestNewClientUnsupportedSigner(t *testing.T) {
+	signer, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewClient(signer, server.VerifyOpts{}); err == nil {
+		t.Error("NewClient succeeded with a P-384 signer")
+	}
+}
+
+func TestTokenAudiences(t *testing.T) {
+	test.SkipForRealTPM(t)
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifierClient, err := NewClient(signer, server.VerifyOpts{TrustedAKs: []crypto.PublicKey{ak.PublicKey()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	audiences := []string{"https://example.com", "https://example.org"}
+	verifierClient = verifier.WithTokenAudiences(verifierClient, audiences)
+
+	tokenBytes, err := agent.CreateAttestationAgent(tpm, client.AttestationKeyECC, verifierClient, noPrincipals).Attest(context.Background())
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+	claims := &Claims{}
+	keyFunc := func(token *jwt.Token) (interface{}, error) { return signer.Public(), nil }
+	if _, err := jwt.ParseWithClaims(string(tokenBytes), claims, keyFunc); err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+	for _, audience := range audiences {
+		if !claims.VerifyAudience(audience, true) {
+			t.Errorf("got aud %v, want %v", claims.Audience, audiences)
+		}
+	}
+	if claims.VerifyAudience(DefaultAudience, true) {
+		t.Errorf("got aud %v, which includes the default audience", claims.Audience)
+	}
+}
+
+func TestTokenNonces(t *testing.T) {
+	test.SkipForRealTPM(t)
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifierClient, err := NewClient(signer, server.VerifyOpts{TrustedAKs: []crypto.PublicKey{ak.PublicKey()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The hooks of the middleware keep the agent a NonceAttester.
+	attester, ok := agent.CreateAttestationAgent(tpm, client.AttestationKeyECC, verifierClient, noPrincipals, agent.WithHooks(agent.Hooks{})).(agent.NonceAttester)
+	if !ok {
+		t.Fatal("agent is not a NonceAttester")
+	}
+	nonces := []string{"nonce-0001", "nonce-0002"}
+	tokenBytes, err := attester.AttestWithNonces(context.Background(), nonces)
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+	claims := &Claims{}
+	keyFunc := func(token *jwt.Token) (interface{}, error) { return signer.Public(), nil }
+	if _, err := jwt.ParseWithClaims(string(tokenBytes), claims, keyFunc); err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+	if strings.Join(claims.Nonces, ",") != strings.Join(nonces, ",") {
+		t.Errorf("got eat_nonce %v, want %v", claims.Nonces, nonces)
+	}
+}
@@ -0,0 +1,216 @@
+// Package maa contains a verifier.Client for Microsoft Azure Attestation (MAA),
+// for running the launcher in Azure confidential VMs with a vTPM.
+package maa
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/google/go-tpm-tools/launcher/verifier"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+)
+
+const (
+	apiVersion = "2020-10-01"
+	nonceSize  = 32
+	// maxResponseSize limits the size of the responses read from MAA.
+	maxResponseSize = 1 << 20
+)
+
+// NewClient creates a new MAA client for the attestation provider at endpoint,
+// e.g. https://sharedeus.eus.attest.azure.net. If httpClient is nil,
+// http.DefaultClient is used.
+func NewClient(endpoint string, httpClient *http.Client) (verifier.Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAA endpoint %q: %w", endpoint, err)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" || u.Host == "" {
+		return nil, fmt.Errorf("invalid MAA endpoint %q: must be an http(s) URL", endpoint)
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &maaClient{strings.TrimSuffix(u.String(), "/"), httpClient}, nil
+}
+
+type maaClient struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+// CreateChallenge implements verifier.Client. MAA does not issue challenges,
+// so a random nonce is generated locally and echoed back in the client
+// payload of the token.
+func (c *maaClient) CreateChallenge(ctx context.Context) (*verifier.Challenge, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return &verifier.Challenge{
+		Name:  c.endpoint,
+		Nonce: nonce,
+	}, nil
+}
+
+// VerifyAttestation implements verifier.Client
+func (c *maaClient) VerifyAttestation(ctx context.Context, request verifier.VerifyAttestationRequest) (*verifier.VerifyAttestationResponse, error) {
+	if request.Challenge == nil || request.Attestation == nil {
+		return nil, fmt.Errorf("nil value provided in challenge")
+	}
+	if len(request.TokenAudiences) > 0 {
+		return nil, verifier.ErrTokenAudiencesUnsupported
+	}
+	if len(request.TokenNonces) > 0 {
+		return nil, verifier.ErrTokenNoncesUnsupported
+	}
+	info, err := convertRequestToMAA(request)
+	if err != nil {
+		return nil, err
+	}
+	infoJSON, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(attestRequest{AttestationInfo: encoding.EncodeToString(infoJSON)})
+	if err != nil {
+		return nil, err
+	}
+
+	attestURL := fmt.Sprintf("%s/attest/AzureGuest?api-version=%s", c.endpoint, apiVersion)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, attestURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calling MAA attest: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("reading MAA response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("calling MAA attest: %s: %s", resp.Status, respBody)
+	}
+	return convertResponseFromMAA(respBody)
+}
+
+// encoding is the base64 encoding used by MAA.
+var encoding = base64.RawURLEncoding
+
+type attestRequest struct {
+	AttestationInfo string `json:"AttestationInfo"`
+}
+
+type attestResponse struct {
+	Token string `json:"token"`
+}
+
+// attestationInfo is the Azure guest attestation evidence.
+type attestationInfo struct {
+	AttestationProtocolVersion string  `json:"AttestationProtocolVersion"`
+	ClientPayload              string  `json:"ClientPayload"`
+	OSType                     string  `json:"OSType"`
+	TcgLogs                    string  `json:"TcgLogs"`
+	TpmInfo                    tpmInfo `json:"TpmInfo"`
+}
+
+type tpmInfo struct {
+	AikCert      string   `json:"AikCert"`
+	AikPub       string   `json:"AikPub"`
+	CertChain    []string `json:"CertChain,omitempty"`
+	PcrQuote     string   `json:"PcrQuote"`
+	PcrSignature string   `json:"PcrSignature"`
+	PcrSet       []uint32 `json:"PcrSet"`
+	PcrValues    []string `json:"PcrValues"`
+}
+
+type clientPayload struct {
+	Nonce             string `json:"nonce"`
+	CanonicalEventLog string `json:"canonical_event_log,omitempty"`
+}
+
+func convertRequestToMAA(request verifier.VerifyAttestationRequest) (*attestationInfo, error) {
+	quote := sha256Quote(request.Attestation)
+	if quote == nil {
+		return nil, errors.New("attestation does not contain a SHA-256 quote")
+	}
+
+	pcrSet := make([]uint32, 0, len(quote.GetPcrs().GetPcrs()))
+	for idx := range quote.GetPcrs().GetPcrs() {
+		pcrSet = append(pcrSet, idx)
+	}
+	sort.Slice(pcrSet, func(i, j int) bool { return pcrSet[i] < pcrSet[j] })
+	pcrValues := make([]string, len(pcrSet))
+	for i, idx := range pcrSet {
+		pcrValues[i] = encoding.EncodeToString(quote.GetPcrs().GetPcrs()[idx])
+	}
+
+	certs := make([]string, len(request.Attestation.GetIntermediateCerts()))
+	for i, cert := range request.Attestation.GetIntermediateCerts() {
+		certs[i] = encoding.EncodeToString(cert)
+	}
+
+	payload, err := json.Marshal(clientPayload{
+		Nonce:             encoding.EncodeToString(request.Challenge.Nonce),
+		CanonicalEventLog: encoding.EncodeToString(request.Attestation.GetCanonicalEventLog()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &attestationInfo{
+		AttestationProtocolVersion: "2.0",
+		ClientPayload:              encoding.EncodeToString(payload),
+		OSType:                     "Linux",
+		TcgLogs:                    encoding.EncodeToString(request.Attestation.GetEventLog()),
+		TpmInfo: tpmInfo{
+			AikCert:      encoding.EncodeToString(request.Attestation.GetAkCert()),
+			AikPub:       encoding.EncodeToString(request.Attestation.GetAkPub()),
+			CertChain:    certs,
+			PcrQuote:     encoding.EncodeToString(quote.GetQuote()),
+			PcrSignature: encoding.EncodeToString(quote.GetRawSig()),
+			PcrSet:       pcrSet,
+			PcrValues:    pcrValues,
+		},
+	}, nil
+}
+
+// sha256Quote returns the quote over the SHA-256 PCR bank, the bank MAA
+// verifies.
+func sha256Quote(attestation *attestpb.Attestation) *tpmpb.Quote {
+	for _, quote := range attestation.GetQuotes() {
+		if quote.GetPcrs().GetHash() == tpmpb.HashAlgo_SHA256 {
+			return quote
+		}
+	}
+	return nil
+}
+
+func convertResponseFromMAA(body []byte) (*verifier.VerifyAttestationResponse, error) {
+	var resp attestResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode MAA response: %w", err)
+	}
+	if resp.Token == "" {
+		return nil, errors.New("MAA response does not contain a token")
+	}
+	return &verifier.VerifyAttestationResponse{
+		ClaimsToken: []byte(resp.Token),
+	}, nil
+}
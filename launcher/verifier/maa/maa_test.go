@@ -0,0 +1,133 @@
+package maa
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/launcher/verifier"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+)
+
+func TestVerifyAttestation(t *testing.T) {
+	attestation := &attestpb.Attestation{
+		AkPub:  []byte("akpub"),
+		AkCert: []byte("akcert"),
+		Quotes: []*tpmpb.Quote{
+			{Pcrs: &tpmpb.PCRs{Hash: tpmpb.HashAlgo_SHA1, Pcrs: map[uint32][]byte{0: {1}}}},
+			{
+				Pcrs:   &tpmpb.PCRs{Hash: tpmpb.HashAlgo_SHA256, Pcrs: map[uint32][]byte{4: {4}, 0: {0}}},
+				Quote:  []byte("quote"),
+				RawSig: []byte("sig"),
+			},
+		},
+		EventLog:          []byte("eventlog"),
+		CanonicalEventLog: []byte("cel"),
+	}
+
+	var got attestationInfo
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/attest/AzureGuest" || r.URL.Query().Get("api-version") != apiVersion {
+			t.Errorf("unexpected request URL %v", r.URL)
+		}
+		var req attestRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		info, err := encoding.DecodeString(req.AttestationInfo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(info, &got); err != nil {
+			t.Fatal(err)
+		}
+		json.NewEncoder(w).Encode(attestResponse{Token: "token"})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	challenge, err := client.CreateChallenge(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(challenge.Nonce) != nonceSize {
+		t.Errorf("got nonce of %d bytes, want %d", len(challenge.Nonce), nonceSize)
+	}
+
+	resp, err := client.VerifyAttestation(ctx, verifier.VerifyAttestationRequest{Challenge: challenge, Attestation: attestation})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.ClaimsToken) != "token" {
+		t.Errorf("got token %q, want %q", resp.ClaimsToken, "token")
+	}
+
+	want := tpmInfo{
+		AikCert:      encoding.EncodeToString([]byte("akcert")),
+		AikPub:       encoding.EncodeToString([]byte("akpub")),
+		PcrQuote:     encoding.EncodeToString([]byte("quote")),
+		PcrSignature: encoding.EncodeToString([]byte("sig")),
+		PcrSet:       []uint32{0, 4},
+		PcrValues:    []string{encoding.EncodeToString([]byte{0}), encoding.EncodeToString([]byte{4})},
+	}
+	if !cmp.Equal(got.TpmInfo, want) {
+		t.Errorf("got TpmInfo %+v, want %+v", got.TpmInfo, want)
+	}
+
+	payloadJSON, err := encoding.DecodeString(got.ClientPayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload clientPayload
+	if err := json.Unmarshal(payloadJSON, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Nonce != encoding.EncodeToString(challenge.Nonce) {
+		t.Errorf("got nonce %q in client payload, want %q", payload.Nonce, encoding.EncodeToString(challenge.Nonce))
+	}
+}
+
+func TestVerifyAttestationErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad quote", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge := &verifier.Challenge{Nonce: []byte("nonce")}
+	sha256Attestation := &attestpb.Attestation{Quotes: []*tpmpb.Quote{{Pcrs: &tpmpb.PCRs{Hash: tpmpb.HashAlgo_SHA256}}}}
+
+	for _, testcase := range []struct {
+		name    string
+		request verifier.VerifyAttestationRequest
+	}{
+		{"NilAttestation", verifier.VerifyAttestationRequest{Challenge: challenge}},
+		{"NoSHA256Quote", verifier.VerifyAttestationRequest{Challenge: challenge, Attestation: &attestpb.Attestation{}}},
+		{"ServerError", verifier.VerifyAttestationRequest{Challenge: challenge, Attestation: sha256Attestation}},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			if _, err := client.VerifyAttestation(context.Background(), testcase.request); err == nil {
+				t.Error("expected VerifyAttestation to fail")
+			}
+		})
+	}
+}
+
+func TestNewClientBadEndpoint(t *testing.T) {
+	for _, endpoint := range []string{"", "sharedeus.eus.attest.azure.net", "ftp://example.com", "://"} {
+		if _, err := NewClient(endpoint, nil); err == nil {
+			t.Errorf("NewClient(%q) should fail", endpoint)
+		}
+	}
+}
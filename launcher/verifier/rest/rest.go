@@ -2,16 +2,23 @@
 package rest
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/google/go-tpm-tools/launcher/verifier"
 
 	v1alpha1 "google.golang.org/api/confidentialcomputing/v1alpha1"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
 )
 
 // BadRegionError indicates that:
@@ -38,7 +45,14 @@ func (e *BadRegionError) Unwrap() error {
 // attestations in a particular project and region. Returns a *BadRegionError
 // if the requested project is valid, but the region is invalid.
 func NewClient(ctx context.Context, projectID string, region string, opts ...option.ClientOption) (verifier.Client, error) {
-	service, err := v1alpha1.NewService(ctx, opts...)
+	// The HTTP client of the service is also used for the requests with
+	// token options, which the generated client does not support.
+	opts = append([]option.ClientOption{option.WithScopes(v1alpha1.CloudPlatformScope)}, opts...)
+	httpClient, _, err := htransport.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("can't create ConfidentialComputing v1alpha1 API client: %w", err)
+	}
+	service, err := v1alpha1.NewService(ctx, append(opts, option.WithHTTPClient(httpClient))...)
 	if err != nil {
 		return nil, fmt.Errorf("can't create ConfidentialComputing v1alpha1 API client: %w", err)
 	}
@@ -48,7 +62,7 @@ func NewClient(ctx context.Context, projectID string, region string, opts ...opt
 
 	location, getErr := service.Projects.Locations.Get(locationName).Do()
 	if getErr == nil {
-		return &restClient{service, location}, nil
+		return &restClient{service, httpClient, location}, nil
 	}
 
 	// If we can't get the location, try to list the locations. This handles
@@ -70,9 +84,84 @@ func NewClient(ctx context.Context, projectID string, region string, opts ...opt
 	}
 }
 
+// NewRegionalClient creates a client performing attestations in the first
+// healthy region of regions, in order of preference, so an outage of one
+// region does not stall token refreshes. See verifier.NewFailoverClient.
+// Returns a *BadRegionError if one of the regions is invalid.
+func NewRegionalClient(ctx context.Context, projectID string, regions []string, failoverOpts verifier.FailoverOptions, opts ...option.ClientOption) (verifier.Client, error) {
+	if len(regions) == 0 {
+		return nil, errors.New("no region")
+	}
+	clients := make([]verifier.Client, len(regions))
+	var lastErr error
+	available := 0
+	for i, region := range regions {
+		client, err := NewClient(ctx, projectID, region, opts...)
+		var badRegion *BadRegionError
+		if errors.As(err, &badRegion) {
+			return nil, err
+		}
+		if err != nil {
+			// The region may be down, retry when it is used.
+			lastErr = err
+			clients[i] = &lazyClient{projectID: projectID, region: region, opts: opts}
+			continue
+		}
+		clients[i] = client
+		available++
+	}
+	if available == 0 {
+		return nil, fmt.Errorf("no region available: %w", lastErr)
+	}
+	return verifier.NewFailoverClient(clients, failoverOpts), nil
+}
+
+// lazyClient creates the client of a region which was down when
+// NewRegionalClient was called, at its first successful use.
+type lazyClient struct {
+	projectID string
+	region    string
+	opts      []option.ClientOption
+
+	mu     sync.Mutex
+	client verifier.Client
+}
+
+func (c *lazyClient) get(ctx context.Context) (verifier.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client == nil {
+		client, err := NewClient(ctx, c.projectID, c.region, c.opts...)
+		if err != nil {
+			return nil, err
+		}
+		c.client = client
+	}
+	return c.client, nil
+}
+
+// CreateChallenge implements verifier.Client
+func (c *lazyClient) CreateChallenge(ctx context.Context) (*verifier.Challenge, error) {
+	client, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.CreateChallenge(ctx)
+}
+
+// VerifyAttestation implements verifier.Client
+func (c *lazyClient) VerifyAttestation(ctx context.Context, request verifier.VerifyAttestationRequest) (*verifier.VerifyAttestationResponse, error) {
+	client, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.VerifyAttestation(ctx, request)
+}
+
 type restClient struct {
-	service  *v1alpha1.Service
-	location *v1alpha1.Location
+	service    *v1alpha1.Service
+	httpClient *http.Client
+	location   *v1alpha1.Location
 }
 
 // CreateChallenge implements verifier.Client
@@ -81,7 +170,7 @@ func (c *restClient) CreateChallenge(ctx context.Context) (*verifier.Challenge,
 	chal, err := c.service.Projects.Locations.Challenges.Create(
 		c.location.Name,
 		&v1alpha1.Challenge{},
-	).Do()
+	).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("calling v1alpha1.CreateChallenge: %w", err)
 	}
@@ -93,16 +182,78 @@ func (c *restClient) VerifyAttestation(ctx context.Context, request verifier.Ver
 	if request.Challenge == nil || request.Attestation == nil {
 		return nil, fmt.Errorf("nil value provided in challenge")
 	}
-	response, err := c.service.Projects.Locations.Challenges.VerifyAttestation(
-		request.Challenge.Name,
-		convertRequestToREST(request),
-	).Do()
+	// The API issues tokens for a single audience.
+	if len(request.TokenAudiences) > 1 {
+		return nil, fmt.Errorf("%w: got %d audiences, at most 1 is allowed", verifier.ErrTokenAudiencesUnsupported, len(request.TokenAudiences))
+	}
+	var response *v1alpha1.VerifyAttestationResponse
+	var err error
+	if len(request.TokenAudiences) > 0 || len(request.TokenNonces) > 0 {
+		options := tokenOptions{Nonce: request.TokenNonces}
+		if len(request.TokenAudiences) > 0 {
+			options.Audience = request.TokenAudiences[0]
+		}
+		response, err = c.verifyAttestationWithOptions(ctx, request.Challenge.Name, convertRequestToREST(request), options)
+	} else {
+		response, err = c.service.Projects.Locations.Challenges.VerifyAttestation(
+			request.Challenge.Name,
+			convertRequestToREST(request),
+		).Context(ctx).Do()
+	}
 	if err != nil {
 		return nil, fmt.Errorf("calling v1alpha1.VerifyAttestation: %w", err)
 	}
 	return convertResponseFromREST(response)
 }
 
+// tokenOptions are the tokenOptions of a VerifyAttestationRequest,
+// customizing the claims token.
+type tokenOptions struct {
+	Audience string   `json:"audience,omitempty"`
+	Nonce    []string `json:"nonce,omitempty"`
+}
+
+// verifyAttestationWithOptions calls VerifyAttestation like the generated
+// client, with the token options added to the request.
+func (c *restClient) verifyAttestationWithOptions(ctx context.Context, challenge string, request *v1alpha1.VerifyAttestationRequest, options tokenOptions) (*v1alpha1.VerifyAttestationResponse, error) {
+	fields := map[string]interface{}{}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	fields["tokenOptions"] = options
+	if body, err = json.Marshal(fields); err != nil {
+		return nil, err
+	}
+
+	urls := googleapi.ResolveRelative(c.service.BasePath, "v1alpha1/{+challenge}:verifyAttestation") + "?alt=json&prettyPrint=false"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, urls, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	googleapi.Expand(req.URL, map[string]string{"challenge": challenge})
+	req.Header.Set("Content-Type", "application/json")
+	if c.service.UserAgent != "" {
+		req.Header.Set("User-Agent", c.service.UserAgent)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := googleapi.CheckResponse(resp); err != nil {
+		return nil, err
+	}
+	response := &v1alpha1.VerifyAttestationResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
 var encoding = base64.StdEncoding
 
 func convertChallengeFromREST(chal *v1alpha1.Challenge) (*verifier.Challenge, error) {
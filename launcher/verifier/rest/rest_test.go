@@ -1,10 +1,28 @@
 package rest
 
 import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math/big"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm-tools/launcher/agent"
 	"github.com/google/go-tpm-tools/launcher/verifier"
+	"github.com/google/go-tpm-tools/server"
+	"github.com/google/go-tpm-tools/server/httpservice"
 	v1alpha1 "google.golang.org/api/confidentialcomputing/v1alpha1"
+	"google.golang.org/api/option"
 )
 
 // Make sure our conversion function can handle empty values.
@@ -17,3 +35,110 @@ func TestConvertEmpty(t *testing.T) {
 		t.Errorf("Converting empty challenge: %v", err)
 	}
 }
+
+// Make sure the client works with a self-hosted verifier.
+func TestClientWithHTTPService(t *testing.T) {
+	test.SkipForRealTPM(t)
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	// The REST API only carries the AK certificate, so certify the AK.
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour)}
+	akFetcher := func(rw io.ReadWriter) (*client.Key, error) {
+		ak, err := client.AttestationKeyECC(rw)
+		if err != nil {
+			return nil, err
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, ak.PublicKey(), caKey)
+		if err != nil {
+			return nil, err
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+		return ak, ak.SetCert(cert)
+	}
+	ak, err := akFetcher(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	akPub := ak.PublicKey()
+	ak.Close()
+
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service, err := httpservice.New(httpservice.Config{
+		Signer:     signer,
+		Issuer:     "https://verifier.example.com",
+		VerifyOpts: server.VerifyOpts{TrustedAKs: []crypto.PublicKey{akPub}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(service)
+	defer ts.Close()
+
+	ctx := context.Background()
+	restClient, err := NewClient(ctx, "test-project", "us-central1",
+		option.WithEndpoint(ts.URL+"/"), option.WithoutAuthentication(), option.WithHTTPClient(ts.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	noPrincipals := func(string) ([][]byte, error) { return nil, nil }
+	token, err := agent.CreateAttestationAgent(tpm, akFetcher, restClient, noPrincipals).Attest(ctx)
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+
+	keyFunc := func(token *jwt.Token) (interface{}, error) { return signer.Public(), nil }
+	claims := &jwt.RegisteredClaims{}
+	if _, err := jwt.ParseWithClaims(string(token), claims, keyFunc); err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+	if !claims.VerifyIssuer("https://verifier.example.com", true) {
+		t.Errorf("got iss %q", claims.Issuer)
+	}
+
+	audienceClient := verifier.WithTokenAudiences(restClient, []string{"https://rp.example.com"})
+	attester := agent.CreateAttestationAgent(tpm, akFetcher, audienceClient, noPrincipals).(agent.NonceAttester)
+	token, err = attester.AttestWithNonces(ctx, []string{"nonce-0001", "nonce-0002"})
+	if err != nil {
+		t.Fatalf("failed to attest with an audience and nonces: %v", err)
+	}
+	nonceClaims := &struct {
+		jwt.RegisteredClaims
+		Nonces []string `json:"eat_nonce"`
+	}{}
+	if _, err := jwt.ParseWithClaims(string(token), nonceClaims, keyFunc); err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+	if !nonceClaims.VerifyAudience("https://rp.example.com", true) {
+		t.Errorf("got aud %q, want the requested audience", nonceClaims.Audience)
+	}
+	if len(nonceClaims.Nonces) != 2 || nonceClaims.Nonces[0] != "nonce-0001" || nonceClaims.Nonces[1] != "nonce-0002" {
+		t.Errorf("got eat_nonce %q, want the requested nonces", nonceClaims.Nonces)
+	}
+
+	token, err = attester.(agent.AudienceAttester).AttestForAudience(ctx, "https://approval.example.com", []string{"nonce-0003"})
+	if err != nil {
+		t.Fatalf("failed to attest for an audience: %v", err)
+	}
+	if _, err := jwt.ParseWithClaims(string(token), nonceClaims, keyFunc); err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+	if len(nonceClaims.Audience) != 1 || nonceClaims.Audience[0] != "https://approval.example.com" {
+		t.Errorf("got aud %q, want only the audience of the request", nonceClaims.Audience)
+	}
+
+	severalAudiences := verifier.WithTokenAudiences(restClient, []string{"https://rp.example.com", "https://rp.example.org"})
+	if _, err := agent.CreateAttestationAgent(tpm, akFetcher, severalAudiences, noPrincipals).Attest(ctx); !errors.Is(err, verifier.ErrTokenAudiencesUnsupported) {
+		t.Errorf("got error %v with several audiences, want ErrTokenAudiencesUnsupported", err)
+	}
+}
@@ -0,0 +1,135 @@
+package tokenvalidate
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/go-tpm-tools/server"
+)
+
+// Values of the Claims.
+const (
+	// ConfidentialSpace is the SWName of the Confidential Space image.
+	ConfidentialSpace = "CONFIDENTIAL_SPACE"
+	// DebugDisabled is the DebugStatus of the production (hardened) images.
+	DebugDisabled = "disabled-since-boot"
+)
+
+// Claims are the claims of a Confidential Space attestation token.
+type Claims struct {
+	jwt.RegisteredClaims
+	// SecureBoot is whether Secure Boot was enabled.
+	SecureBoot bool `json:"secboot"`
+	// HWModel is the Confidential Computing technology of the VM, e.g.
+	// "GCP_AMD_SEV".
+	HWModel string `json:"hwmodel"`
+	// SWName and SWVersion are the name and versions of the OS image.
+	SWName    string   `json:"swname"`
+	SWVersion []string `json:"swversion"`
+	// DebugStatus is DebugDisabled for the hardened images.
+	DebugStatus string `json:"dbgstat"`
+	// OEMID is the IANA Private Enterprise Number of the platform vendor.
+	OEMID uint64 `json:"oemid"`
+	// Submods are the claims of the components of the platform.
+	Submods Submods `json:"submods"`
+	// GoogleServiceAccounts are the service accounts the operator allowed
+	// the workload to impersonate.
+	GoogleServiceAccounts []string `json:"google_service_accounts"`
+	// Container is the workload, for the tokens of the attestation server of
+	// this repository which do not have Submods.
+	Container *server.ContainerClaims `json:"container,omitempty"`
+}
+
+// Submods are the claims of the components of a Confidential Space VM.
+type Submods struct {
+	Container         *server.ContainerClaims  `json:"container,omitempty"`
+	GCE               *GCEClaims               `json:"gce,omitempty"`
+	ConfidentialSpace *ConfidentialSpaceClaims `json:"confidential_space,omitempty"`
+}
+
+// GCEClaims are the claims about the GCE instance.
+type GCEClaims struct {
+	Zone          string `json:"zone"`
+	ProjectID     string `json:"project_id"`
+	ProjectNumber string `json:"project_number"`
+	InstanceName  string `json:"instance_name"`
+	InstanceID    string `json:"instance_id"`
+}
+
+// ConfidentialSpaceClaims are the claims about the Confidential Space image.
+type ConfidentialSpaceClaims struct {
+	// SupportAttributes are the support levels of the image, e.g. "LATEST",
+	// "STABLE" or "USABLE".
+	SupportAttributes []string `json:"support_attributes"`
+}
+
+// Workload returns the container claims, from the Submods or the top-level
+// Container, or nil if the token has none.
+func (c *Claims) Workload() *server.ContainerClaims {
+	if c.Submods.Container != nil {
+		return c.Submods.Container
+	}
+	return c.Container
+}
+
+// Policy is the policy of a relying party on the claims of the tokens. The
+// empty fields are not checked.
+type Policy struct {
+	// ImageDigests are the allowed digests of the container image.
+	ImageDigests []string
+	// HWModels are the allowed Confidential Computing technologies.
+	HWModels []string
+	// RequireSecureBoot requires Secure Boot.
+	RequireSecureBoot bool
+	// RequireDebugDisabled requires the hardened images, see DebugDisabled.
+	RequireDebugDisabled bool
+	// SupportAttributes must all be support attributes of the image, e.g.
+	// "STABLE" to only accept images with security updates.
+	SupportAttributes []string
+	// ProjectIDs are the allowed GCE projects of the VM.
+	ProjectIDs []string
+}
+
+// Check checks the claims against the policy, and returns the first
+// violation.
+func (p Policy) Check(c *Claims) error {
+	if len(p.ImageDigests) > 0 {
+		workload := c.Workload()
+		if workload == nil {
+			return errors.New("token has no container claims")
+		}
+		if !contains(p.ImageDigests, workload.ImageDigest) {
+			return fmt.Errorf("image digest %q is not allowed", workload.ImageDigest)
+		}
+	}
+	if len(p.HWModels) > 0 && !contains(p.HWModels, c.HWModel) {
+		return fmt.Errorf("hardware model %q is not allowed", c.HWModel)
+	}
+	if p.RequireSecureBoot && !c.SecureBoot {
+		return errors.New("Secure Boot is required")
+	}
+	if p.RequireDebugDisabled && c.DebugStatus != DebugDisabled {
+		return fmt.Errorf("debug status %q, want %q", c.DebugStatus, DebugDisabled)
+	}
+	for _, attr := range p.SupportAttributes {
+		if c.Submods.ConfidentialSpace == nil || !contains(c.Submods.ConfidentialSpace.SupportAttributes, attr) {
+			return fmt.Errorf("support attribute %q is required", attr)
+		}
+	}
+	if len(p.ProjectIDs) > 0 {
+		if c.Submods.GCE == nil || !contains(p.ProjectIDs, c.Submods.GCE.ProjectID) {
+			return errors.New("GCE project is not allowed")
+		}
+	}
+	return nil
+}
+
+func contains(strs []string, target string) bool {
+	for _, s := range strs {
+		if s == target {
+			return true
+		}
+	}
+	return false
+}
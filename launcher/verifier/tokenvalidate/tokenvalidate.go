@@ -0,0 +1,263 @@
+// Package tokenvalidate validates the attestation tokens of Confidential Space
+// workloads for relying parties: it fetches the JWKS of the attestation
+// service, checks the signature, issuer, audience and lifetime of a token,
+// and returns its claims as typed Claims, which can be checked with a Policy.
+package tokenvalidate
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// DefaultIssuer is the issuer of the tokens of the Google Cloud Attestation
+// service.
+const DefaultIssuer = "https://confidentialcomputing.googleapis.com"
+
+const (
+	openIDConfigurationPath = "/.well-known/openid-configuration"
+	// maxResponseSize limits the size of the discovery documents and JWKS.
+	maxResponseSize = 1 << 20
+	// minRefetchInterval limits the refetches of the JWKS for tokens signed
+	// by unknown keys.
+	minRefetchInterval = time.Minute
+)
+
+// Validator validates attestation tokens. The JWKS is fetched on first use,
+// and refetched when it is older than RefreshInterval, or when a token is
+// signed by an unknown key after a key rotation.
+type Validator struct {
+	// Issuer is the expected issuer of the tokens, DefaultIssuer if empty.
+	// A trailing slash is ignored.
+	Issuer string
+	// Audience is the expected audience of the tokens. It is required, so
+	// the tokens issued for other relying parties are rejected.
+	Audience string
+	// JWKSURL serves the signing keys of the issuer. If empty, it is
+	// discovered from the OpenID configuration of the Issuer.
+	JWKSURL string
+	// Client fetches the keys, http.DefaultClient if nil.
+	Client *http.Client
+	// RefreshInterval is how long the fetched keys are used, one hour if
+	// zero.
+	RefreshInterval time.Duration
+	// Leeway is the allowed clock skew when checking the lifetime of the
+	// tokens.
+	Leeway time.Duration
+
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+}
+
+// Validate checks the signature, issuer, audience and lifetime of a token,
+// and returns its claims.
+func (v *Validator) Validate(ctx context.Context, token string) (*Claims, error) {
+	if v.Audience == "" {
+		return nil, errors.New("tokenvalidate: the Validator has no Audience")
+	}
+	claims := &Claims{}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "ES256", "ES384"}), jwt.WithoutClaimsValidation())
+	if _, err := parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.key(ctx, kid)
+	}); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	if err := v.checkClaims(claims, jwt.TimeFunc()); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	return claims, nil
+}
+
+func (v *Validator) issuer() string {
+	if v.Issuer == "" {
+		return DefaultIssuer
+	}
+	return strings.TrimSuffix(v.Issuer, "/")
+}
+
+func (v *Validator) checkClaims(claims *Claims, now time.Time) error {
+	if strings.TrimSuffix(claims.Issuer, "/") != v.issuer() {
+		return fmt.Errorf("issuer %q, want %q", claims.Issuer, v.issuer())
+	}
+	if !claims.VerifyAudience(v.Audience, true) {
+		return fmt.Errorf("audience %v, want %q", claims.Audience, v.Audience)
+	}
+	if !claims.VerifyExpiresAt(now.Add(-v.Leeway), true) {
+		return errors.New("token is expired")
+	}
+	if !claims.VerifyNotBefore(now.Add(v.Leeway), false) || !claims.VerifyIssuedAt(now.Add(v.Leeway), false) {
+		return errors.New("token is not valid yet")
+	}
+	return nil
+}
+
+// key returns the public key with the ID kid, fetching the JWKS if needed.
+func (v *Validator) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	interval := v.RefreshInterval
+	if interval == 0 {
+		interval = time.Hour
+	}
+	age := time.Since(v.fetched)
+	_, known := v.keys[kid]
+	if v.keys == nil || age >= interval || !known && age >= minRefetchInterval {
+		keys, err := v.fetchKeys(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch the JWKS: %w", err)
+		}
+		v.keys, v.fetched = keys, time.Now()
+	}
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	// A single key without an ID signs the tokens without a kid.
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (v *Validator) fetchKeys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	jwksURL := v.JWKSURL
+	if jwksURL == "" {
+		var config struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := v.getJSON(ctx, v.issuer()+openIDConfigurationPath, &config); err != nil {
+			return nil, err
+		}
+		if config.JWKSURI == "" {
+			return nil, fmt.Errorf("the OpenID configuration of %s has no jwks_uri", v.issuer())
+		}
+		jwksURL = config.JWKSURI
+	}
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := v.getJSON(ctx, jwksURL, &jwks); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]crypto.PublicKey)
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s has no signing keys", jwksURL)
+	}
+	return keys, nil
+}
+
+func (v *Validator) getJSON(ctx context.Context, url string, out interface{}) error {
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned non-OK status: %v", url, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("invalid response from %s: %w", url, err)
+	}
+	return nil
+}
+
+// jwk is a JSON Web Key, see RFC 7517.
+type jwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid base64url integer %q", s)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,210 @@
+package tokenvalidate
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/go-tpm-tools/server"
+)
+
+const testAudience = "https://relying-party.example.com"
+
+type testIssuer struct {
+	srv     *httptest.Server
+	keys    map[string]interface{}
+	fetches int
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	iss := &testIssuer{keys: make(map[string]interface{})}
+	iss.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case openIDConfigurationPath:
+			json.NewEncoder(w).Encode(map[string]string{"issuer": iss.srv.URL, "jwks_uri": iss.srv.URL + "/jwks"})
+		case "/jwks":
+			iss.fetches++
+			var keys []jwk
+			for kid, key := range iss.keys {
+				keys = append(keys, publicJWK(kid, key))
+			}
+			json.NewEncoder(w).Encode(map[string][]jwk{"keys": keys})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(iss.srv.Close)
+	return iss
+}
+
+func publicJWK(kid string, key interface{}) jwk {
+	enc := base64.RawURLEncoding
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return jwk{Kty: "RSA", Use: "sig", Kid: kid, N: enc.EncodeToString(key.N.Bytes()), E: enc.EncodeToString(big.NewInt(int64(key.E)).Bytes())}
+	case *ecdsa.PrivateKey:
+		return jwk{Kty: "EC", Use: "sig", Kid: kid, Crv: "P-256", X: enc.EncodeToString(key.X.Bytes()), Y: enc.EncodeToString(key.Y.Bytes())}
+	}
+	panic("unsupported key")
+}
+
+func (iss *testIssuer) sign(t *testing.T, kid string, claims *Claims) string {
+	t.Helper()
+	var method jwt.SigningMethod = jwt.SigningMethodRS256
+	if _, ok := iss.keys[kid].(*ecdsa.PrivateKey); ok {
+		method = jwt.SigningMethodES256
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(iss.keys[kid])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func (iss *testIssuer) claims() *Claims {
+	now := time.Now()
+	return &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    iss.srv.URL + "/",
+			Audience:  []string{testAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+		SecureBoot:  true,
+		HWModel:     "GCP_AMD_SEV",
+		SWName:      ConfidentialSpace,
+		DebugStatus: DebugDisabled,
+		Submods: Submods{
+			Container:         &server.ContainerClaims{ImageDigest: "sha256:allowed"},
+			GCE:               &GCEClaims{ProjectID: "project"},
+			ConfidentialSpace: &ConfidentialSpaceClaims{SupportAttributes: []string{"LATEST", "STABLE"}},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	iss := newTestIssuer(t)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iss.keys["rsa"] = rsaKey
+	v := &Validator{Issuer: iss.srv.URL, Audience: testAudience, Client: iss.srv.Client()}
+
+	claims, err := v.Validate(context.Background(), iss.sign(t, "rsa", iss.claims()))
+	if err != nil {
+		t.Fatalf("Validate() failed: %v", err)
+	}
+	if got := claims.Workload().ImageDigest; got != "sha256:allowed" {
+		t.Errorf("Validate() got image digest %q, want sha256:allowed", got)
+	}
+
+	// A rotated key is fetched, at most once per minRefetchInterval.
+	iss.keys["ec"] = ecKey
+	if _, err := v.Validate(context.Background(), iss.sign(t, "ec", iss.claims())); err == nil {
+		t.Fatal("Validate() refetched the JWKS right after fetching it")
+	}
+	v.fetched = v.fetched.Add(-minRefetchInterval)
+	if _, err := v.Validate(context.Background(), iss.sign(t, "ec", iss.claims())); err != nil {
+		t.Fatalf("Validate() failed with a rotated key: %v", err)
+	}
+	if iss.fetches != 2 {
+		t.Errorf("the JWKS was fetched %d times, want 2", iss.fetches)
+	}
+
+	testCases := []struct {
+		name   string
+		modify func(*Claims)
+	}{
+		{"WrongAudience", func(c *Claims) { c.Audience = []string{"https://other.example.com"} }},
+		{"WrongIssuer", func(c *Claims) { c.Issuer = "https://issuer.example.com" }},
+		{"Expired", func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute)) }},
+		{"NotYetValid", func(c *Claims) { c.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Hour)) }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			claims := iss.claims()
+			tc.modify(claims)
+			if _, err := v.Validate(context.Background(), iss.sign(t, "rsa", claims)); err == nil {
+				t.Error("Validate() succeeded, want an error")
+			}
+		})
+	}
+
+	t.Run("BadSignature", func(t *testing.T) {
+		token := iss.sign(t, "rsa", iss.claims())
+		parts := strings.Split(token, ".")
+		other := iss.claims()
+		other.Submods.Container.ImageDigest = "sha256:other"
+		payload, err := json.Marshal(other)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[1] = base64.RawURLEncoding.EncodeToString(payload)
+		if _, err := v.Validate(context.Background(), strings.Join(parts, ".")); err == nil {
+			t.Error("Validate() succeeded with a modified payload")
+		}
+	})
+
+	t.Run("UnsignedToken", func(t *testing.T) {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodNone, iss.claims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := v.Validate(context.Background(), token); err == nil {
+			t.Error("Validate() succeeded with an unsigned token")
+		}
+	})
+}
+
+func TestPolicyCheck(t *testing.T) {
+	iss := &testIssuer{srv: &httptest.Server{URL: "https://issuer.example.com"}}
+	testCases := []struct {
+		name    string
+		policy  Policy
+		modify  func(*Claims)
+		wantErr bool
+	}{
+		{"Empty", Policy{}, func(*Claims) {}, false},
+		{"AllowedImage", Policy{ImageDigests: []string{"sha256:allowed"}}, func(*Claims) {}, false},
+		{"OtherImage", Policy{ImageDigests: []string{"sha256:other"}}, func(*Claims) {}, true},
+		{"TopLevelContainer", Policy{ImageDigests: []string{"sha256:allowed"}}, func(c *Claims) {
+			c.Container, c.Submods.Container = c.Submods.Container, nil
+		}, false},
+		{"NoContainer", Policy{ImageDigests: []string{"sha256:allowed"}}, func(c *Claims) { c.Submods.Container = nil }, true},
+		{"HWModel", Policy{HWModels: []string{"GCP_INTEL_TDX"}}, func(*Claims) {}, true},
+		{"SecureBoot", Policy{RequireSecureBoot: true}, func(c *Claims) { c.SecureBoot = false }, true},
+		{"DebugEnabled", Policy{RequireDebugDisabled: true}, func(c *Claims) { c.DebugStatus = "enabled" }, true},
+		{"SupportAttributes", Policy{SupportAttributes: []string{"STABLE"}}, func(*Claims) {}, false},
+		{"MissingSupportAttribute", Policy{SupportAttributes: []string{"USABLE"}}, func(*Claims) {}, true},
+		{"Project", Policy{ProjectIDs: []string{"other"}}, func(*Claims) {}, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			claims := iss.claims()
+			tc.modify(claims)
+			if err := tc.policy.Check(claims); (err != nil) != tc.wantErr {
+				t.Errorf("Check() = %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package launcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+
+	"github.com/google/go-tpm-tools/cel"
+)
+
+// Version is the semantic version of the launcher, measured in the Launcher
+// event. It is set when building the image with
+// -ldflags "-X github.com/google/go-tpm-tools/launcher.Version=MAJOR.MINOR.PATCH".
+var Version = "0.0.0"
+
+// selfExecutable is the binary of the running process. Unlike os.Executable,
+// it is the binary which was executed, even if its path was replaced since.
+const selfExecutable = "/proc/self/exe"
+
+// launcherEvent returns the Launcher event of the running launcher, with the
+// digest of its binary, as "sha256:" followed by its hex SHA-256.
+func launcherEvent() (cel.CosTlv, error) {
+	f, err := os.Open(selfExecutable)
+	if err != nil {
+		return cel.CosTlv{}, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return cel.CosTlv{}, err
+	}
+	content, err := cel.FormatLauncher(Version, "sha256:"+hex.EncodeToString(h.Sum(nil)))
+	if err != nil {
+		return cel.CosTlv{}, err
+	}
+	return cel.CosTlv{EventType: cel.LauncherType, EventContent: []byte(content)}, nil
+}
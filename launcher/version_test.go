@@ -0,0 +1,28 @@
+package launcher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-tpm-tools/cel"
+)
+
+func TestLauncherEvent(t *testing.T) {
+	event, err := launcherEvent()
+	if err != nil {
+		t.Fatalf("launcherEvent() failed: %v", err)
+	}
+	if event.EventType != cel.LauncherType {
+		t.Errorf("got event type %v, want %v", event.EventType, cel.LauncherType)
+	}
+	version, digest, err := cel.ParseLauncher(string(event.EventContent))
+	if err != nil {
+		t.Fatalf("ParseLauncher(%q) failed: %v", event.EventContent, err)
+	}
+	if version != Version {
+		t.Errorf("got version %q, want %q", version, Version)
+	}
+	if !strings.HasPrefix(digest, "sha256:") || len(digest) != len("sha256:")+64 {
+		t.Errorf("got malformed binary digest %q", digest)
+	}
+}
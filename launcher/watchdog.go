@@ -0,0 +1,170 @@
+package launcher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/launcher/spec"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/tpm2"
+)
+
+// watchdogInterval is how often the token watchdog reads the PCRs.
+const watchdogInterval = 30 * time.Second
+
+// errTokenRevoked is returned when refreshing a token revoked by the token
+// watchdog.
+var errTokenRevoked = errors.New("the attestation token was revoked after the PCRs changed")
+
+// openWatchdogTPM opens the connection of the token watchdog to the TPM. It
+// is separate from the connection of the attestation agent, so their commands
+// are not interleaved: the kernel resource manager serializes them.
+var openWatchdogTPM = func() (io.ReadWriteCloser, error) {
+	return tpm2.OpenTPM("/dev/tpmrm0")
+}
+
+// watchdogPCRs are the SHA-256 PCRs watched by the token watchdog: all of
+// them but cel.CosEventPCR and cel.KernelModulePCR, which the launcher
+// extends itself while the workload runs.
+func watchdogPCRs() tpm2.PCRSelection {
+	sel := tpm2.PCRSelection{Hash: tpm2.AlgSHA256}
+	for pcr := 0; pcr < 24; pcr++ {
+		if pcr != cel.CosEventPCR && pcr != cel.KernelModulePCR {
+			sel.PCRs = append(sel.PCRs, pcr)
+		}
+	}
+	return sel
+}
+
+// pcrWatchdog detects the changes of the PCRs since it last read them.
+type pcrWatchdog struct {
+	read func() (*tpmpb.PCRs, error)
+	last map[uint32][]byte
+}
+
+// newPCRWatchdog reads the PCRs with read, as the baseline of the changes.
+func newPCRWatchdog(read func() (*tpmpb.PCRs, error)) (*pcrWatchdog, error) {
+	pcrs, err := read()
+	if err != nil {
+		return nil, err
+	}
+	return &pcrWatchdog{read: read, last: pcrs.GetPcrs()}, nil
+}
+
+// changed reads the PCRs, and returns the indexes of those which changed
+// since the last read, in increasing order.
+func (w *pcrWatchdog) changed() ([]uint32, error) {
+	pcrs, err := w.read()
+	if err != nil {
+		return nil, err
+	}
+	var changed []uint32
+	for index, digest := range pcrs.GetPcrs() {
+		if !bytes.Equal(w.last[index], digest) {
+			changed = append(changed, index)
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool { return changed[i] < changed[j] })
+	w.last = pcrs.GetPcrs()
+	return changed, nil
+}
+
+// startTokenWatchdog watches the PCRs of the TPM every watchdogInterval
+// until ctx is cancelled, applying the TokenWatchdog of the LaunchSpec when
+// they change.
+func (r *ContainerRunner) startTokenWatchdog(ctx context.Context) error {
+	tpm, err := openWatchdogTPM()
+	if err != nil {
+		return err
+	}
+	sel := watchdogPCRs()
+	watchdog, err := newPCRWatchdog(func() (*tpmpb.PCRs, error) { return client.ReadPCRs(tpm, sel) })
+	if err != nil {
+		tpm.Close()
+		return err
+	}
+	go func() {
+		defer tpm.Close()
+		r.watchPCRs(ctx, watchdog, watchdogInterval)
+	}()
+	r.logger.Printf("token watchdog started: %s the token when the PCRs change\n", r.launchSpec.TokenWatchdog)
+	return nil
+}
+
+// watchPCRs checks the watchdog for changes every interval until ctx is
+// cancelled or the token is revoked.
+func (r *ContainerRunner) watchPCRs(ctx context.Context, watchdog *pcrWatchdog, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Println("token watchdog stopped")
+			return
+		case <-ticker.C:
+		}
+		changed, err := watchdog.changed()
+		if err != nil {
+			r.logger.Printf("token watchdog failed to read the PCRs: %v", err)
+			continue
+		}
+		if len(changed) == 0 {
+			continue
+		}
+		r.logger.Printf("token watchdog: PCRs %v changed", changed)
+		if err := r.applyTokenWatchdog(ctx); err != nil {
+			r.logger.Printf("token watchdog failed to %s the token: %v", r.launchSpec.TokenWatchdog, err)
+		}
+		if r.isTokenRevoked() {
+			return
+		}
+	}
+}
+
+// applyTokenWatchdog reattests or revokes the token file after the PCRs
+// changed, as set by the TokenWatchdog of the LaunchSpec.
+func (r *ContainerRunner) applyTokenWatchdog(ctx context.Context) error {
+	switch r.launchSpec.TokenWatchdog {
+	case spec.WatchdogReattest:
+		_, err := r.refreshToken(ctx)
+		return err
+	case spec.WatchdogRevoke:
+		// A refresh in progress cannot write its token after the removal.
+		r.tokenFiles.mu.Lock()
+		defer r.tokenFiles.mu.Unlock()
+		r.tokenFiles.revoked = true
+		for _, file := range []string{attestationVerifierTokenFile, gcpAccessTokenFile} {
+			if err := os.Remove(path.Join(hostTokenPath, file)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+		r.logger.Println("token watchdog revoked the attestation token")
+		return nil
+	}
+	return fmt.Errorf("unknown token watchdog %q", r.launchSpec.TokenWatchdog)
+}
+
+// tokenFiles is the state of the token files shared by the token refreshes
+// and the token watchdog.
+type tokenFiles struct {
+	mu sync.Mutex
+	// revoked is set once the token watchdog revoked the token.
+	revoked bool
+}
+
+// isTokenRevoked returns whether the token watchdog revoked the token.
+func (r *ContainerRunner) isTokenRevoked() bool {
+	r.tokenFiles.mu.Lock()
+	defer r.tokenFiles.mu.Unlock()
+	return r.tokenFiles.revoked
+}
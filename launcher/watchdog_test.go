@@ -0,0 +1,173 @@
+package launcher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/launcher/spec"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+)
+
+func TestWatchdogPCRs(t *testing.T) {
+	sel := watchdogPCRs()
+	if len(sel.PCRs) != 22 {
+		t.Errorf("got %d watched PCRs, want 22", len(sel.PCRs))
+	}
+	for _, pcr := range sel.PCRs {
+		if pcr == cel.CosEventPCR || pcr == cel.KernelModulePCR {
+			t.Errorf("the CEL PCR %d is watched", pcr)
+		}
+	}
+}
+
+func TestPCRWatchdogChanged(t *testing.T) {
+	pcrs := map[uint32][]byte{0: {0}, 4: {4}, 9: {9}}
+	read := func() (*tpmpb.PCRs, error) {
+		copied := make(map[uint32][]byte)
+		for index, digest := range pcrs {
+			copied[index] = digest
+		}
+		return &tpmpb.PCRs{Pcrs: copied}, nil
+	}
+	watchdog, err := newPCRWatchdog(read)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := watchdog.changed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 0 {
+		t.Errorf("got changed PCRs %v, want none", changed)
+	}
+
+	pcrs[9] = []byte{10}
+	pcrs[0] = []byte{1}
+	changed, err = watchdog.changed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]uint32{0, 9}, changed); diff != "" {
+		t.Errorf("unexpected changed PCRs (-want +got):\n%s", diff)
+	}
+
+	// The changes are relative to the last read.
+	changed, err = watchdog.changed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 0 {
+		t.Errorf("got changed PCRs %v, want none", changed)
+	}
+}
+
+func TestApplyTokenWatchdogReattest(t *testing.T) {
+	token := createJWTWithID(t, "reattested", 5*time.Second)
+	runner := ContainerRunner{
+		attestAgent: &fakeAttestationAgent{
+			attestFunc: func(context.Context) ([]byte, error) { return token, nil },
+		},
+		launchSpec: spec.LaunchSpec{TokenWatchdog: spec.WatchdogReattest},
+		logger:     log.Default(),
+	}
+	if err := os.MkdirAll(hostTokenPath, 0744); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.applyTokenWatchdog(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path.Join(hostTokenPath, attestationVerifierTokenFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, token) {
+		t.Errorf("got token %s, want the reattested token %s", data, token)
+	}
+	if runner.isTokenRevoked() {
+		t.Error("reattesting revoked the token")
+	}
+}
+
+func TestApplyTokenWatchdogRevoke(t *testing.T) {
+	runner := ContainerRunner{
+		attestAgent: &fakeAttestationAgent{
+			attestFunc: func(context.Context) ([]byte, error) { return createJWT(t, 5*time.Second), nil },
+		},
+		launchSpec: spec.LaunchSpec{TokenWatchdog: spec.WatchdogRevoke},
+		logger:     log.Default(),
+	}
+	if err := os.MkdirAll(hostTokenPath, 0744); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runner.refreshToken(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.applyTokenWatchdog(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path.Join(hostTokenPath, attestationVerifierTokenFile)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got %v for the revoked token file, want it removed", err)
+	}
+	if _, err := runner.refreshToken(context.Background()); !errors.Is(err, errTokenRevoked) {
+		t.Errorf("got refreshToken error %v, want %v", err, errTokenRevoked)
+	}
+}
+
+func TestWatchPCRsStopsOnRevoke(t *testing.T) {
+	pcr := []byte{0}
+	watchdog, err := newPCRWatchdog(func() (*tpmpb.PCRs, error) {
+		digest := pcr
+		pcr = []byte{pcr[0] + 1}
+		return &tpmpb.PCRs{Pcrs: map[uint32][]byte{0: digest}}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := ContainerRunner{
+		launchSpec: spec.LaunchSpec{TokenWatchdog: spec.WatchdogRevoke},
+		logger:     log.Default(),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	runner.watchPCRs(ctx, watchdog, time.Millisecond)
+	if ctx.Err() != nil {
+		t.Fatal("watchPCRs did not stop after revoking the token")
+	}
+	if !runner.isTokenRevoked() {
+		t.Error("watchPCRs did not revoke the token")
+	}
+}
+
+func TestRefreshTokenRevokedDuringAttestation(t *testing.T) {
+	runner := &ContainerRunner{
+		launchSpec: spec.LaunchSpec{TokenWatchdog: spec.WatchdogRevoke},
+		logger:     log.Default(),
+	}
+	runner.attestAgent = &fakeAttestationAgent{
+		attestFunc: func(ctx context.Context) ([]byte, error) {
+			// The PCRs change while the token is attested.
+			if err := runner.applyTokenWatchdog(ctx); err != nil {
+				t.Fatal(err)
+			}
+			return createJWT(t, 5*time.Second), nil
+		},
+	}
+	if err := os.MkdirAll(hostTokenPath, 0744); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runner.refreshToken(context.Background()); !errors.Is(err, errTokenRevoked) {
+		t.Errorf("got refreshToken error %v, want %v", err, errTokenRevoked)
+	}
+	if _, err := os.Stat(path.Join(hostTokenPath, attestationVerifierTokenFile)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got %v for the token file, want the token attested before the revocation not written", err)
+	}
+}
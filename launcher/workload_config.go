@@ -0,0 +1,34 @@
+package launcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path"
+)
+
+const (
+	// hostWorkloadConfigPath is the directory in the host storing the
+	// workload configuration of the LaunchSpec.
+	hostWorkloadConfigPath = "/run/container_launcher_config/"
+	// containerWorkloadConfigMountPath is the directory in the container
+	// storing the workload configuration.
+	containerWorkloadConfigMountPath = "/run/container_launcher_config/"
+	workloadConfigFile               = "config"
+)
+
+// workloadConfigDigest returns the digest of the workload configuration
+// measured in the CEL, "sha256:" followed by its hex SHA-256.
+func workloadConfigDigest(config string) string {
+	digest := sha256.Sum256([]byte(config))
+	return "sha256:" + hex.EncodeToString(digest[:])
+}
+
+// writeWorkloadConfig writes the workload configuration to dir, readable by
+// the user of the container.
+func writeWorkloadConfig(config string, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path.Join(dir, workloadConfigFile), []byte(config), 0644)
+}
@@ -0,0 +1,30 @@
+package launcher
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestWorkloadConfigDigest(t *testing.T) {
+	// echo -n foo | sha256sum
+	want := "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+	if got := workloadConfigDigest("foo"); got != want {
+		t.Errorf("workloadConfigDigest() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteWorkloadConfig(t *testing.T) {
+	dir := t.TempDir()
+	config := `{"replicas": 3}`
+	if err := writeWorkloadConfig(config, dir); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path.Join(dir, workloadConfigFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != config {
+		t.Errorf("written workload config is %q, want %q", data, config)
+	}
+}
@@ -0,0 +1,99 @@
+package launcher
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+	"path"
+
+	"github.com/google/go-tpm-tools/launcher/agent"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// hostWorkloadKeyPath is the directory in the host storing the private
+	// workload key. /run is a tmpfs, so the key is never written to disk.
+	hostWorkloadKeyPath = "/run/container_launcher_key/"
+	// containerWorkloadKeyMountPath is the directory in the container storing
+	// the private workload key.
+	containerWorkloadKeyMountPath = "/run/container_launcher_key/"
+	workloadKeyFile               = "workload_key.pem"
+	// workloadPublicKeyFile and workloadKeyQuoteFile are written next to the
+	// attestation tokens, so the workload can give them to relying parties.
+	workloadPublicKeyFile = "workload_key.pub.pem"
+	workloadKeyQuoteFile  = "workload_key.quote"
+)
+
+// newWorkloadKey generates the ephemeral workload key. It only lives in memory
+// and tmpfs, so a new key is generated at every boot.
+func newWorkloadKey() (*ecdsa.PrivateKey, error) {
+	return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+}
+
+// workloadKeyDigest returns the digest of the public key measured in the CEL,
+// "sha256:" followed by the hex SHA-256 of its PKIX encoding.
+func workloadKeyDigest(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(der)
+	return "sha256:" + hex.EncodeToString(digest[:]), nil
+}
+
+// writeWorkloadKey writes the private key in PKCS #8 PEM to dir. It is only
+// readable by root until chownWorkloadKey gives it to the user of the
+// container, which does not exist yet.
+func writeWorkloadKey(key *ecdsa.PrivateKey, dir string) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	keyPath := path.Join(dir, workloadKeyFile)
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0400); err != nil {
+		return err
+	}
+	// WriteFile does not change the mode of an existing file.
+	return os.Chmod(keyPath, 0400)
+}
+
+// chownWorkloadKey gives the private key in dir to the host uid and gid of
+// the user of the container, see workloadOwner.
+func chownWorkloadKey(dir string, uid, gid int) error {
+	return os.Chown(path.Join(dir, workloadKeyFile), uid, gid)
+}
+
+// certifyWorkloadKey writes the public key and a quote of the CEL PCR by the
+// attestation key to dir. The quote binds the PKIX encoding of the public key
+// (see server.VerifyQuoteData), which certifies the key without trusting the
+// verifier.
+func certifyWorkloadKey(signer agent.CELSigner, pub crypto.PublicKey, dir string) error {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return err
+	}
+	quote, err := signer.QuoteCEL(func([]byte, *tpmpb.PCRs) ([]byte, error) {
+		return der, nil
+	})
+	if err != nil {
+		return err
+	}
+	quoteBytes, err := proto.Marshal(quote)
+	if err != nil {
+		return err
+	}
+	if err := writeArtifact(dir, workloadPublicKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})); err != nil {
+		return err
+	}
+	return writeArtifact(dir, workloadKeyQuoteFile, quoteBytes)
+}
@@ -0,0 +1,118 @@
+package launcher
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm-tools/launcher/agent"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm-tools/server"
+	"google.golang.org/protobuf/proto"
+)
+
+func noPrincipals(audience string) ([][]byte, error) {
+	return nil, nil
+}
+
+func TestWriteWorkloadKey(t *testing.T) {
+	dir := t.TempDir()
+	key, err := newWorkloadKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeWorkloadKey(key, dir); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path.Join(dir, workloadKeyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		t.Fatal("workload key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !key.Equal(parsed) {
+		t.Error("written workload key does not match the generated key")
+	}
+	info, err := os.Stat(path.Join(dir, workloadKeyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0400 {
+		t.Errorf("got workload key mode %o, want 0400", mode)
+	}
+	if err := chownWorkloadKey(dir, os.Getuid(), os.Getgid()); err != nil {
+		t.Errorf("chownWorkloadKey() failed: %v", err)
+	}
+}
+
+func TestCertifyWorkloadKey(t *testing.T) {
+	test.SkipForRealTPM(t)
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+
+	key, err := newWorkloadKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := workloadKeyDigest(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	attestAgent := agent.CreateAttestationAgent(tpm, client.AttestationKeyECC, nil, noPrincipals)
+	if err := attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.WorkloadKeyType, EventContent: []byte(digest)}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err := certifyWorkloadKey(attestAgent.(agent.CELSigner), key.Public(), dir); err != nil {
+		t.Fatal(err)
+	}
+
+	pubPEM, err := os.ReadFile(path.Join(dir, workloadPublicKeyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(pubPEM)
+	if block == nil {
+		t.Fatal("workload public key is not PEM encoded")
+	}
+	quoteBytes, err := os.ReadFile(path.Join(dir, workloadKeyQuoteFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	quote := &tpmpb.Quote{}
+	if err := proto.Unmarshal(quoteBytes, quote); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.VerifyQuoteData(quote, ak.PublicKey(), block.Bytes); err != nil {
+		t.Errorf("failed to verify the workload key quote: %v", err)
+	}
+	otherKey, err := newWorkloadKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherDER, err := x509.MarshalPKIXPublicKey(otherKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.VerifyQuoteData(quote, ak.PublicKey(), otherDER); err == nil {
+		t.Error("workload key quote verified for another key")
+	}
+}
@@ -31,6 +31,7 @@ const (
 	GCEConfidentialTechnology_AMD_SEV_ES GCEConfidentialTechnology = 2
 	// Enum value 3 is reserved.
 	GCEConfidentialTechnology_AMD_SEV_SNP GCEConfidentialTechnology = 4
+	GCEConfidentialTechnology_INTEL_TDX   GCEConfidentialTechnology = 5
 )
 
 // Enum value maps for GCEConfidentialTechnology.
@@ -40,12 +41,14 @@ var (
 		1: "AMD_SEV",
 		2: "AMD_SEV_ES",
 		4: "AMD_SEV_SNP",
+		5: "INTEL_TDX",
 	}
 	GCEConfidentialTechnology_value = map[string]int32{
 		"NONE":        0,
 		"AMD_SEV":     1,
 		"AMD_SEV_ES":  2,
 		"AMD_SEV_SNP": 4,
+		"INTEL_TDX":   5,
 	}
 )
 
@@ -76,6 +79,56 @@ func (GCEConfidentialTechnology) EnumDescriptor() ([]byte, []int) {
 	return file_attest_proto_rawDescGZIP(), []int{0}
 }
 
+// The kernel lockdown mode, ordered from least to most restrictive.
+type KernelLockdown int32
+
+const (
+	KernelLockdown_LOCKDOWN_NONE            KernelLockdown = 0
+	KernelLockdown_LOCKDOWN_INTEGRITY       KernelLockdown = 1
+	KernelLockdown_LOCKDOWN_CONFIDENTIALITY KernelLockdown = 2
+)
+
+// Enum value maps for KernelLockdown.
+var (
+	KernelLockdown_name = map[int32]string{
+		0: "LOCKDOWN_NONE",
+		1: "LOCKDOWN_INTEGRITY",
+		2: "LOCKDOWN_CONFIDENTIALITY",
+	}
+	KernelLockdown_value = map[string]int32{
+		"LOCKDOWN_NONE":            0,
+		"LOCKDOWN_INTEGRITY":       1,
+		"LOCKDOWN_CONFIDENTIALITY": 2,
+	}
+)
+
+func (x KernelLockdown) Enum() *KernelLockdown {
+	p := new(KernelLockdown)
+	*p = x
+	return p
+}
+
+func (x KernelLockdown) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (KernelLockdown) Descriptor() protoreflect.EnumDescriptor {
+	return file_attest_proto_enumTypes[1].Descriptor()
+}
+
+func (KernelLockdown) Type() protoreflect.EnumType {
+	return &file_attest_proto_enumTypes[1]
+}
+
+func (x KernelLockdown) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use KernelLockdown.Descriptor instead.
+func (KernelLockdown) EnumDescriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{1}
+}
+
 // Common, publicly-listed certificates by different vendors.
 type WellKnownCertificate int32
 
@@ -113,11 +166,11 @@ func (x WellKnownCertificate) String() string {
 }
 
 func (WellKnownCertificate) Descriptor() protoreflect.EnumDescriptor {
-	return file_attest_proto_enumTypes[1].Descriptor()
+	return file_attest_proto_enumTypes[2].Descriptor()
 }
 
 func (WellKnownCertificate) Type() protoreflect.EnumType {
-	return &file_attest_proto_enumTypes[1]
+	return &file_attest_proto_enumTypes[2]
 }
 
 func (x WellKnownCertificate) Number() protoreflect.EnumNumber {
@@ -126,7 +179,7 @@ func (x WellKnownCertificate) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use WellKnownCertificate.Descriptor instead.
 func (WellKnownCertificate) EnumDescriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{1}
+	return file_attest_proto_rawDescGZIP(), []int{2}
 }
 
 // The container's restart policy.
@@ -168,11 +221,11 @@ func (x RestartPolicy) String() string {
 }
 
 func (RestartPolicy) Descriptor() protoreflect.EnumDescriptor {
-	return file_attest_proto_enumTypes[2].Descriptor()
+	return file_attest_proto_enumTypes[3].Descriptor()
 }
 
 func (RestartPolicy) Type() protoreflect.EnumType {
-	return &file_attest_proto_enumTypes[2]
+	return &file_attest_proto_enumTypes[3]
 }
 
 func (x RestartPolicy) Number() protoreflect.EnumNumber {
@@ -181,7 +234,61 @@ func (x RestartPolicy) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use RestartPolicy.Descriptor instead.
 func (RestartPolicy) EnumDescriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{2}
+	return file_attest_proto_rawDescGZIP(), []int{3}
+}
+
+// The clock the timestamps of the records of a Canonical Event Log are read
+// from.
+type CelTimestampSource int32
+
+const (
+	CelTimestampSource_CEL_TIMESTAMP_SOURCE_UNSPECIFIED CelTimestampSource = 0
+	// The Clock of the TPM, in milliseconds.
+	CelTimestampSource_CEL_TIMESTAMP_SOURCE_TPM_CLOCK CelTimestampSource = 1
+	// The nanoseconds since the first timestamped record of the log, from the
+	// monotonic clock of the host.
+	CelTimestampSource_CEL_TIMESTAMP_SOURCE_MONOTONIC CelTimestampSource = 2
+)
+
+// Enum value maps for CelTimestampSource.
+var (
+	CelTimestampSource_name = map[int32]string{
+		0: "CEL_TIMESTAMP_SOURCE_UNSPECIFIED",
+		1: "CEL_TIMESTAMP_SOURCE_TPM_CLOCK",
+		2: "CEL_TIMESTAMP_SOURCE_MONOTONIC",
+	}
+	CelTimestampSource_value = map[string]int32{
+		"CEL_TIMESTAMP_SOURCE_UNSPECIFIED": 0,
+		"CEL_TIMESTAMP_SOURCE_TPM_CLOCK":   1,
+		"CEL_TIMESTAMP_SOURCE_MONOTONIC":   2,
+	}
+)
+
+func (x CelTimestampSource) Enum() *CelTimestampSource {
+	p := new(CelTimestampSource)
+	*p = x
+	return p
+}
+
+func (x CelTimestampSource) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CelTimestampSource) Descriptor() protoreflect.EnumDescriptor {
+	return file_attest_proto_enumTypes[4].Descriptor()
+}
+
+func (CelTimestampSource) Type() protoreflect.EnumType {
+	return &file_attest_proto_enumTypes[4]
+}
+
+func (x CelTimestampSource) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CelTimestampSource.Descriptor instead.
+func (CelTimestampSource) EnumDescriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{4}
 }
 
 // Information uniquely identifying a GCE instance. Can be used to create an
@@ -198,6 +305,9 @@ type GCEInstanceInfo struct {
 	ProjectNumber uint64 `protobuf:"varint,3,opt,name=project_number,json=projectNumber,proto3" json:"project_number,omitempty"`
 	InstanceName  string `protobuf:"bytes,4,opt,name=instance_name,json=instanceName,proto3" json:"instance_name,omitempty"`
 	InstanceId    uint64 `protobuf:"varint,5,opt,name=instance_id,json=instanceId,proto3" json:"instance_id,omitempty"`
+	// The security version of the vTPM, from the AK certificate. Unset in
+	// Attestation.instance_info.
+	SecurityVersion uint64 `protobuf:"varint,6,opt,name=security_version,json=securityVersion,proto3" json:"security_version,omitempty"`
 }
 
 func (x *GCEInstanceInfo) Reset() {
@@ -267,6 +377,13 @@ func (x *GCEInstanceInfo) GetInstanceId() uint64 {
 	return 0
 }
 
+func (x *GCEInstanceInfo) GetSecurityVersion() uint64 {
+	if x != nil {
+		return x.SecurityVersion
+	}
+	return 0
+}
+
 type Attestation struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -297,6 +414,22 @@ type Attestation struct {
 	//
 	//	*Attestation_SevSnpAttestation
 	TeeAttestation isAttestation_TeeAttestation `protobuf_oneof:"tee_attestation"`
+	// The result of the attester replaying its own event logs against the
+	// quoted PCRs when collecting the attestation. Optional. It is not covered
+	// by the quotes, so verifiers must not rely on it.
+	SelfCheck *AttestationSelfCheck `protobuf:"bytes,9,opt,name=self_check,json=selfCheck,proto3" json:"self_check,omitempty"`
+	// The Linux IMA runtime measurement list, in the
+	// ascii_runtime_measurements format. Optional. It is read after the
+	// quotes, so it can have more measurements than they cover.
+	ImaLog []byte `protobuf:"bytes,10,opt,name=ima_log,json=imaLog,proto3" json:"ima_log,omitempty"`
+	// The identity documents of the GCE instance. Optional. They are not
+	// covered by the quotes, so verifiers must check them on their own.
+	GceIdentity *GceIdentity `protobuf:"bytes,11,opt,name=gce_identity,json=gceIdentity,proto3" json:"gce_identity,omitempty"`
+	// Whether the attester omitted the TCG event log on purpose, e.g. for
+	// frequent heartbeat attestations, rather than failing to collect it.
+	// Verifiers only accept such attestations if configured to, and verify
+	// them from the quotes and the other logs.
+	EventLogOmitted bool `protobuf:"varint,12,opt,name=event_log_omitted,json=eventLogOmitted,proto3" json:"event_log_omitted,omitempty"`
 }
 
 func (x *Attestation) Reset() {
@@ -394,6 +527,34 @@ func (x *Attestation) GetSevSnpAttestation() *sevsnp.Attestation {
 	return nil
 }
 
+func (x *Attestation) GetSelfCheck() *AttestationSelfCheck {
+	if x != nil {
+		return x.SelfCheck
+	}
+	return nil
+}
+
+func (x *Attestation) GetImaLog() []byte {
+	if x != nil {
+		return x.ImaLog
+	}
+	return nil
+}
+
+func (x *Attestation) GetGceIdentity() *GceIdentity {
+	if x != nil {
+		return x.GceIdentity
+	}
+	return nil
+}
+
+func (x *Attestation) GetEventLogOmitted() bool {
+	if x != nil {
+		return x.EventLogOmitted
+	}
+	return false
+}
+
 type isAttestation_TeeAttestation interface {
 	isAttestation_TeeAttestation()
 }
@@ -404,25 +565,24 @@ type Attestation_SevSnpAttestation struct {
 
 func (*Attestation_SevSnpAttestation) isAttestation_TeeAttestation() {}
 
-// The platform/firmware state for this instance
-type PlatformState struct {
+// The identity documents of a GCE instance, fetched by the attester from the
+// metadata server and the Compute Engine API.
+type GceIdentity struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// Types that are assignable to Firmware:
-	//
-	//	*PlatformState_ScrtmVersionId
-	//	*PlatformState_GceVersion
-	Firmware isPlatformState_Firmware `protobuf_oneof:"firmware"`
-	// Set to NONE on non-GCE instances or non-Confidential Shielded GCE instances
-	Technology GCEConfidentialTechnology `protobuf:"varint,3,opt,name=technology,proto3,enum=attest.GCEConfidentialTechnology" json:"technology,omitempty"`
-	// Only set for GCE instances
-	InstanceInfo *GCEInstanceInfo `protobuf:"bytes,4,opt,name=instance_info,json=instanceInfo,proto3" json:"instance_info,omitempty"`
+	// The instance identity token of the default service account, a JWT in the
+	// full format signed by Google. Its audience is the hex SHA-256 of the nonce
+	// of the attestation, binding the token to the attestation.
+	InstanceIdentityToken string `protobuf:"bytes,1,opt,name=instance_identity_token,json=instanceIdentityToken,proto3" json:"instance_identity_token,omitempty"`
+	// The Shielded VM identity of the instance, as returned by the
+	// getShieldedInstanceIdentity method of the Compute Engine API.
+	ShieldedVmIdentity *ShieldedVmIdentity `protobuf:"bytes,2,opt,name=shielded_vm_identity,json=shieldedVmIdentity,proto3" json:"shielded_vm_identity,omitempty"`
 }
 
-func (x *PlatformState) Reset() {
-	*x = PlatformState{}
+func (x *GceIdentity) Reset() {
+	*x = GceIdentity{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_attest_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -430,13 +590,13 @@ func (x *PlatformState) Reset() {
 	}
 }
 
-func (x *PlatformState) String() string {
+func (x *GceIdentity) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PlatformState) ProtoMessage() {}
+func (*GceIdentity) ProtoMessage() {}
 
-func (x *PlatformState) ProtoReflect() protoreflect.Message {
+func (x *GceIdentity) ProtoReflect() protoreflect.Message {
 	mi := &file_attest_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -448,77 +608,42 @@ func (x *PlatformState) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PlatformState.ProtoReflect.Descriptor instead.
-func (*PlatformState) Descriptor() ([]byte, []int) {
+// Deprecated: Use GceIdentity.ProtoReflect.Descriptor instead.
+func (*GceIdentity) Descriptor() ([]byte, []int) {
 	return file_attest_proto_rawDescGZIP(), []int{2}
 }
 
-func (m *PlatformState) GetFirmware() isPlatformState_Firmware {
-	if m != nil {
-		return m.Firmware
-	}
-	return nil
-}
-
-func (x *PlatformState) GetScrtmVersionId() []byte {
-	if x, ok := x.GetFirmware().(*PlatformState_ScrtmVersionId); ok {
-		return x.ScrtmVersionId
-	}
-	return nil
-}
-
-func (x *PlatformState) GetGceVersion() uint32 {
-	if x, ok := x.GetFirmware().(*PlatformState_GceVersion); ok {
-		return x.GceVersion
-	}
-	return 0
-}
-
-func (x *PlatformState) GetTechnology() GCEConfidentialTechnology {
+func (x *GceIdentity) GetInstanceIdentityToken() string {
 	if x != nil {
-		return x.Technology
+		return x.InstanceIdentityToken
 	}
-	return GCEConfidentialTechnology_NONE
+	return ""
 }
 
-func (x *PlatformState) GetInstanceInfo() *GCEInstanceInfo {
+func (x *GceIdentity) GetShieldedVmIdentity() *ShieldedVmIdentity {
 	if x != nil {
-		return x.InstanceInfo
+		return x.ShieldedVmIdentity
 	}
 	return nil
 }
 
-type isPlatformState_Firmware interface {
-	isPlatformState_Firmware()
-}
-
-type PlatformState_ScrtmVersionId struct {
-	// Raw S-CRTM version identifier (EV_S_CRTM_VERSION)
-	ScrtmVersionId []byte `protobuf:"bytes,1,opt,name=scrtm_version_id,json=scrtmVersionId,proto3,oneof"`
-}
-
-type PlatformState_GceVersion struct {
-	// Virtual GCE firmware version (parsed from S-CRTM version id)
-	GceVersion uint32 `protobuf:"varint,2,opt,name=gce_version,json=gceVersion,proto3,oneof"`
-}
-
-func (*PlatformState_ScrtmVersionId) isPlatformState_Firmware() {}
-
-func (*PlatformState_GceVersion) isPlatformState_Firmware() {}
-
-type GrubFile struct {
+// The keys of the vTPM of a Shielded VM, as PEM certificates and public keys.
+// The certificates are empty if the vTPM has none.
+type ShieldedVmIdentity struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// The digest of the file (pulled from the raw event digest).
-	Digest []byte `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
-	// The event data. This is not measured, so it is untrusted.
-	UntrustedFilename []byte `protobuf:"bytes,2,opt,name=untrusted_filename,json=untrustedFilename,proto3" json:"untrusted_filename,omitempty"`
+	// The endorsement key (EK).
+	EncryptionKeyCert string `protobuf:"bytes,1,opt,name=encryption_key_cert,json=encryptionKeyCert,proto3" json:"encryption_key_cert,omitempty"`
+	EncryptionKeyPub  string `protobuf:"bytes,2,opt,name=encryption_key_pub,json=encryptionKeyPub,proto3" json:"encryption_key_pub,omitempty"`
+	// The attestation key (AK).
+	SigningKeyCert string `protobuf:"bytes,3,opt,name=signing_key_cert,json=signingKeyCert,proto3" json:"signing_key_cert,omitempty"`
+	SigningKeyPub  string `protobuf:"bytes,4,opt,name=signing_key_pub,json=signingKeyPub,proto3" json:"signing_key_pub,omitempty"`
 }
 
-func (x *GrubFile) Reset() {
-	*x = GrubFile{}
+func (x *ShieldedVmIdentity) Reset() {
+	*x = ShieldedVmIdentity{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_attest_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -526,13 +651,13 @@ func (x *GrubFile) Reset() {
 	}
 }
 
-func (x *GrubFile) String() string {
+func (x *ShieldedVmIdentity) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GrubFile) ProtoMessage() {}
+func (*ShieldedVmIdentity) ProtoMessage() {}
 
-func (x *GrubFile) ProtoReflect() protoreflect.Message {
+func (x *ShieldedVmIdentity) ProtoReflect() protoreflect.Message {
 	mi := &file_attest_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -544,39 +669,55 @@ func (x *GrubFile) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GrubFile.ProtoReflect.Descriptor instead.
-func (*GrubFile) Descriptor() ([]byte, []int) {
+// Deprecated: Use ShieldedVmIdentity.ProtoReflect.Descriptor instead.
+func (*ShieldedVmIdentity) Descriptor() ([]byte, []int) {
 	return file_attest_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *GrubFile) GetDigest() []byte {
+func (x *ShieldedVmIdentity) GetEncryptionKeyCert() string {
 	if x != nil {
-		return x.Digest
+		return x.EncryptionKeyCert
 	}
-	return nil
+	return ""
 }
 
-func (x *GrubFile) GetUntrustedFilename() []byte {
+func (x *ShieldedVmIdentity) GetEncryptionKeyPub() string {
 	if x != nil {
-		return x.UntrustedFilename
+		return x.EncryptionKeyPub
 	}
-	return nil
+	return ""
 }
 
-type GrubState struct {
+func (x *ShieldedVmIdentity) GetSigningKeyCert() string {
+	if x != nil {
+		return x.SigningKeyCert
+	}
+	return ""
+}
+
+func (x *ShieldedVmIdentity) GetSigningKeyPub() string {
+	if x != nil {
+		return x.SigningKeyPub
+	}
+	return ""
+}
+
+// The result of replaying the event logs of an Attestation against its quotes.
+type AttestationSelfCheck struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// All GRUB-read and measured files, including grub.cfg.
-	Files []*GrubFile `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
-	// A list of executed GRUB commands and command lines passed to the kernel
-	// and kernel modules.
-	Commands []string `protobuf:"bytes,2,rep,name=commands,proto3" json:"commands,omitempty"`
+	// Whether the event logs replayed to the PCRs of at least one of the quotes.
+	Replayed bool `protobuf:"varint,1,opt,name=replayed,proto3" json:"replayed,omitempty"`
+	// The PCRs whose replayed value did not match the quote, if known.
+	MismatchedPcrs []uint32 `protobuf:"varint,2,rep,packed,name=mismatched_pcrs,json=mismatchedPcrs,proto3" json:"mismatched_pcrs,omitempty"`
+	// Why the replay failed. Empty if replayed is true.
+	Error string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
 }
 
-func (x *GrubState) Reset() {
-	*x = GrubState{}
+func (x *AttestationSelfCheck) Reset() {
+	*x = AttestationSelfCheck{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_attest_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -584,13 +725,13 @@ func (x *GrubState) Reset() {
 	}
 }
 
-func (x *GrubState) String() string {
+func (x *AttestationSelfCheck) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GrubState) ProtoMessage() {}
+func (*AttestationSelfCheck) ProtoMessage() {}
 
-func (x *GrubState) ProtoReflect() protoreflect.Message {
+func (x *AttestationSelfCheck) ProtoReflect() protoreflect.Message {
 	mi := &file_attest_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -602,39 +743,45 @@ func (x *GrubState) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GrubState.ProtoReflect.Descriptor instead.
-func (*GrubState) Descriptor() ([]byte, []int) {
+// Deprecated: Use AttestationSelfCheck.ProtoReflect.Descriptor instead.
+func (*AttestationSelfCheck) Descriptor() ([]byte, []int) {
 	return file_attest_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *GrubState) GetFiles() []*GrubFile {
+func (x *AttestationSelfCheck) GetReplayed() bool {
 	if x != nil {
-		return x.Files
+		return x.Replayed
 	}
-	return nil
+	return false
 }
 
-func (x *GrubState) GetCommands() []string {
+func (x *AttestationSelfCheck) GetMismatchedPcrs() []uint32 {
 	if x != nil {
-		return x.Commands
+		return x.MismatchedPcrs
 	}
 	return nil
 }
 
-// The state of the Linux kernel.
-// At the moment, parsing LinuxKernelState relies on parsing the GrubState.
-// To do so, use ParseOpts{Loader: GRUB} when calling ParseMachineState.
-type LinuxKernelState struct {
+func (x *AttestationSelfCheck) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// An Intel TDX quote of the VM.
+type TdxAttestation struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// The kernel command line.
-	CommandLine string `protobuf:"bytes,1,opt,name=command_line,json=commandLine,proto3" json:"command_line,omitempty"`
+	// The raw TDX quote, as returned by the TDX guest driver. The quote embeds
+	// the certification data (e.g. the PCK certificate chain) needed to verify it.
+	Quote []byte `protobuf:"bytes,1,opt,name=quote,proto3" json:"quote,omitempty"`
 }
 
-func (x *LinuxKernelState) Reset() {
-	*x = LinuxKernelState{}
+func (x *TdxAttestation) Reset() {
+	*x = TdxAttestation{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_attest_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -642,13 +789,13 @@ func (x *LinuxKernelState) Reset() {
 	}
 }
 
-func (x *LinuxKernelState) String() string {
+func (x *TdxAttestation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LinuxKernelState) ProtoMessage() {}
+func (*TdxAttestation) ProtoMessage() {}
 
-func (x *LinuxKernelState) ProtoReflect() protoreflect.Message {
+func (x *TdxAttestation) ProtoReflect() protoreflect.Message {
 	mi := &file_attest_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -660,41 +807,40 @@ func (x *LinuxKernelState) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LinuxKernelState.ProtoReflect.Descriptor instead.
-func (*LinuxKernelState) Descriptor() ([]byte, []int) {
+// Deprecated: Use TdxAttestation.ProtoReflect.Descriptor instead.
+func (*TdxAttestation) Descriptor() ([]byte, []int) {
 	return file_attest_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *LinuxKernelState) GetCommandLine() string {
+func (x *TdxAttestation) GetQuote() []byte {
 	if x != nil {
-		return x.CommandLine
+		return x.Quote
 	}
-	return ""
+	return nil
 }
 
-// A parsed event from the TCG event log
-type Event struct {
+// A single piece of evidence from a Trusted Execution Environment.
+type TeeEvidence struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// The Platform Control Register (PCR) this event was extended into.
-	PcrIndex uint32 `protobuf:"varint,1,opt,name=pcr_index,json=pcrIndex,proto3" json:"pcr_index,omitempty"`
-	// The type of this event. Note that this value is not verified, so it should
-	// only be used as a hint during event parsing.
-	UntrustedType uint32 `protobuf:"varint,2,opt,name=untrusted_type,json=untrustedType,proto3" json:"untrusted_type,omitempty"`
-	// The raw data associated to this event. The meaning of this data is
-	// specific to the type of the event.
-	Data []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
-	// The event digest actually extended into the TPM. This is often the hash of
-	// the data field, but in some cases it may have a type-specific calculation.
-	Digest []byte `protobuf:"bytes,4,opt,name=digest,proto3" json:"digest,omitempty"`
-	// This is true if hash(data) == digest.
-	DigestVerified bool `protobuf:"varint,5,opt,name=digest_verified,json=digestVerified,proto3" json:"digest_verified,omitempty"`
+	// Verifiers must reject an AttestationEnvelope containing critical evidence
+	// of a type they do not recognize, and may ignore non-critical evidence of an
+	// unrecognized type. Evidence of an unrecognized type has no evidence set.
+	Critical bool `protobuf:"varint,1,opt,name=critical,proto3" json:"critical,omitempty"`
+	// Additional certificates for verifying the evidence, encoded as ASN.1 DER.
+	// Optional.
+	Certificates [][]byte `protobuf:"bytes,2,rep,name=certificates,proto3" json:"certificates,omitempty"`
+	// Types that are assignable to Evidence:
+	//
+	//	*TeeEvidence_SevSnpAttestation
+	//	*TeeEvidence_TdxAttestation
+	Evidence isTeeEvidence_Evidence `protobuf_oneof:"evidence"`
 }
 
-func (x *Event) Reset() {
-	*x = Event{}
+func (x *TeeEvidence) Reset() {
+	*x = TeeEvidence{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_attest_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -702,13 +848,13 @@ func (x *Event) Reset() {
 	}
 }
 
-func (x *Event) String() string {
+func (x *TeeEvidence) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Event) ProtoMessage() {}
+func (*TeeEvidence) ProtoMessage() {}
 
-func (x *Event) ProtoReflect() protoreflect.Message {
+func (x *TeeEvidence) ProtoReflect() protoreflect.Message {
 	mi := &file_attest_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -720,79 +866,2551 @@ func (x *Event) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Event.ProtoReflect.Descriptor instead.
-func (*Event) Descriptor() ([]byte, []int) {
+// Deprecated: Use TeeEvidence.ProtoReflect.Descriptor instead.
+func (*TeeEvidence) Descriptor() ([]byte, []int) {
 	return file_attest_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *Event) GetPcrIndex() uint32 {
+func (x *TeeEvidence) GetCritical() bool {
 	if x != nil {
-		return x.PcrIndex
+		return x.Critical
 	}
-	return 0
+	return false
 }
 
-func (x *Event) GetUntrustedType() uint32 {
+func (x *TeeEvidence) GetCertificates() [][]byte {
 	if x != nil {
-		return x.UntrustedType
+		return x.Certificates
 	}
-	return 0
+	return nil
 }
 
-func (x *Event) GetData() []byte {
+func (m *TeeEvidence) GetEvidence() isTeeEvidence_Evidence {
+	if m != nil {
+		return m.Evidence
+	}
+	return nil
+}
+
+func (x *TeeEvidence) GetSevSnpAttestation() *sevsnp.Attestation {
+	if x, ok := x.GetEvidence().(*TeeEvidence_SevSnpAttestation); ok {
+		return x.SevSnpAttestation
+	}
+	return nil
+}
+
+func (x *TeeEvidence) GetTdxAttestation() *TdxAttestation {
+	if x, ok := x.GetEvidence().(*TeeEvidence_TdxAttestation); ok {
+		return x.TdxAttestation
+	}
+	return nil
+}
+
+type isTeeEvidence_Evidence interface {
+	isTeeEvidence_Evidence()
+}
+
+type TeeEvidence_SevSnpAttestation struct {
+	SevSnpAttestation *sevsnp.Attestation `protobuf:"bytes,3,opt,name=sev_snp_attestation,json=sevSnpAttestation,proto3,oneof"`
+}
+
+type TeeEvidence_TdxAttestation struct {
+	TdxAttestation *TdxAttestation `protobuf:"bytes,4,opt,name=tdx_attestation,json=tdxAttestation,proto3,oneof"`
+}
+
+func (*TeeEvidence_SevSnpAttestation) isTeeEvidence_Evidence() {}
+
+func (*TeeEvidence_TdxAttestation) isTeeEvidence_Evidence() {}
+
+// An AttestationEnvelope carries a vTPM Attestation together with any number
+// of typed TEE evidence. The compatibility rules are:
+//   - version is only incremented for changes that existing verifiers cannot
+//     safely ignore. Verifiers must reject versions they do not support.
+//   - new evidence types are added to TeeEvidence without changing the
+//     version, and are handled by existing verifiers according to
+//     TeeEvidence.critical.
+type AttestationEnvelope struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The version of the envelope format, currently 1. Must be set.
+	Version uint32 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	// The vTPM attestation, with the quotes, event logs (including the Canonical
+	// Event Log) and AK certificates.
+	Attestation *Attestation `protobuf:"bytes,2,opt,name=attestation,proto3" json:"attestation,omitempty"`
+	// Evidence from Trusted Execution Environments. This replaces
+	// attestation.tee_attestation, which must be unset.
+	TeeEvidence []*TeeEvidence `protobuf:"bytes,3,rep,name=tee_evidence,json=teeEvidence,proto3" json:"tee_evidence,omitempty"`
+}
+
+func (x *AttestationEnvelope) Reset() {
+	*x = AttestationEnvelope{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AttestationEnvelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttestationEnvelope) ProtoMessage() {}
+
+func (x *AttestationEnvelope) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttestationEnvelope.ProtoReflect.Descriptor instead.
+func (*AttestationEnvelope) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AttestationEnvelope) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *AttestationEnvelope) GetAttestation() *Attestation {
+	if x != nil {
+		return x.Attestation
+	}
+	return nil
+}
+
+func (x *AttestationEnvelope) GetTeeEvidence() []*TeeEvidence {
+	if x != nil {
+		return x.TeeEvidence
+	}
+	return nil
+}
+
+// The platform/firmware state for this instance
+type PlatformState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Firmware:
+	//
+	//	*PlatformState_ScrtmVersionId
+	//	*PlatformState_GceVersion
+	Firmware isPlatformState_Firmware `protobuf_oneof:"firmware"`
+	// Set to NONE on non-GCE instances or non-Confidential Shielded GCE instances
+	Technology GCEConfidentialTechnology `protobuf:"varint,3,opt,name=technology,proto3,enum=attest.GCEConfidentialTechnology" json:"technology,omitempty"`
+	// Only set for GCE instances
+	InstanceInfo *GCEInstanceInfo `protobuf:"bytes,4,opt,name=instance_info,json=instanceInfo,proto3" json:"instance_info,omitempty"`
+	// The vendor-specific firmware version of the TPM (TPM_PT_FIRMWARE_VERSION_1
+	// in the high 32 bits, TPM_PT_FIRMWARE_VERSION_2 in the low 32 bits), as
+	// signed in the verified quote. Only set for AKs known to be in the
+	// endorsement hierarchy (GCE AKs certified by the trusted roots, or see
+	// server.VerifyOpts.AKInEndorsementHierarchy), as the TPM obfuscates it for
+	// other AKs.
+	TpmFirmwareVersion uint64 `protobuf:"varint,5,opt,name=tpm_firmware_version,json=tpmFirmwareVersion,proto3" json:"tpm_firmware_version,omitempty"`
+}
+
+func (x *PlatformState) Reset() {
+	*x = PlatformState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PlatformState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlatformState) ProtoMessage() {}
+
+func (x *PlatformState) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlatformState.ProtoReflect.Descriptor instead.
+func (*PlatformState) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{8}
+}
+
+func (m *PlatformState) GetFirmware() isPlatformState_Firmware {
+	if m != nil {
+		return m.Firmware
+	}
+	return nil
+}
+
+func (x *PlatformState) GetScrtmVersionId() []byte {
+	if x, ok := x.GetFirmware().(*PlatformState_ScrtmVersionId); ok {
+		return x.ScrtmVersionId
+	}
+	return nil
+}
+
+func (x *PlatformState) GetGceVersion() uint32 {
+	if x, ok := x.GetFirmware().(*PlatformState_GceVersion); ok {
+		return x.GceVersion
+	}
+	return 0
+}
+
+func (x *PlatformState) GetTechnology() GCEConfidentialTechnology {
+	if x != nil {
+		return x.Technology
+	}
+	return GCEConfidentialTechnology_NONE
+}
+
+func (x *PlatformState) GetInstanceInfo() *GCEInstanceInfo {
+	if x != nil {
+		return x.InstanceInfo
+	}
+	return nil
+}
+
+func (x *PlatformState) GetTpmFirmwareVersion() uint64 {
+	if x != nil {
+		return x.TpmFirmwareVersion
+	}
+	return 0
+}
+
+type isPlatformState_Firmware interface {
+	isPlatformState_Firmware()
+}
+
+type PlatformState_ScrtmVersionId struct {
+	// Raw S-CRTM version identifier (EV_S_CRTM_VERSION)
+	ScrtmVersionId []byte `protobuf:"bytes,1,opt,name=scrtm_version_id,json=scrtmVersionId,proto3,oneof"`
+}
+
+type PlatformState_GceVersion struct {
+	// Virtual GCE firmware version (parsed from S-CRTM version id)
+	GceVersion uint32 `protobuf:"varint,2,opt,name=gce_version,json=gceVersion,proto3,oneof"`
+}
+
+func (*PlatformState_ScrtmVersionId) isPlatformState_Firmware() {}
+
+func (*PlatformState_GceVersion) isPlatformState_Firmware() {}
+
+type GrubFile struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The digest of the file (pulled from the raw event digest).
+	Digest []byte `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	// The event data. This is not measured, so it is untrusted.
+	UntrustedFilename []byte `protobuf:"bytes,2,opt,name=untrusted_filename,json=untrustedFilename,proto3" json:"untrusted_filename,omitempty"`
+}
+
+func (x *GrubFile) Reset() {
+	*x = GrubFile{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GrubFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GrubFile) ProtoMessage() {}
+
+func (x *GrubFile) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GrubFile.ProtoReflect.Descriptor instead.
+func (*GrubFile) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GrubFile) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *GrubFile) GetUntrustedFilename() []byte {
+	if x != nil {
+		return x.UntrustedFilename
+	}
+	return nil
+}
+
+type GrubState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// All GRUB-read and measured files, including grub.cfg.
+	Files []*GrubFile `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	// A list of executed GRUB commands and command lines passed to the kernel
+	// and kernel modules.
+	Commands []string `protobuf:"bytes,2,rep,name=commands,proto3" json:"commands,omitempty"`
+}
+
+func (x *GrubState) Reset() {
+	*x = GrubState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GrubState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GrubState) ProtoMessage() {}
+
+func (x *GrubState) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GrubState.ProtoReflect.Descriptor instead.
+func (*GrubState) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GrubState) GetFiles() []*GrubFile {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *GrubState) GetCommands() []string {
+	if x != nil {
+		return x.Commands
+	}
+	return nil
+}
+
+// The state of the Linux kernel.
+// At the moment, parsing LinuxKernelState relies on parsing the GrubState.
+// To do so, use ParseOpts{Loader: GRUB} when calling ParseMachineState.
+type LinuxKernelState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The kernel command line.
+	CommandLine string `protobuf:"bytes,1,opt,name=command_line,json=commandLine,proto3" json:"command_line,omitempty"`
+	// The security-relevant parameters parsed from the command line. They are
+	// empty if the parameter is not set.
+	// The init= parameter, the program run as the first process.
+	Init string `protobuf:"bytes,2,opt,name=init,proto3" json:"init,omitempty"`
+	// The ima_policy= parameters, in order.
+	ImaPolicies []string `protobuf:"bytes,3,rep,name=ima_policies,json=imaPolicies,proto3" json:"ima_policies,omitempty"`
+	// The lockdown= parameter.
+	Lockdown KernelLockdown `protobuf:"varint,4,opt,name=lockdown,proto3,enum=attest.KernelLockdown" json:"lockdown,omitempty"`
+	// The security= parameter, the major LSM to enable.
+	Security string `protobuf:"bytes,5,opt,name=security,proto3" json:"security,omitempty"`
+	// Whether module.sig_enforce=1 is set, so only signed modules are loaded.
+	ModuleSigEnforce bool `protobuf:"varint,6,opt,name=module_sig_enforce,json=moduleSigEnforce,proto3" json:"module_sig_enforce,omitempty"`
+	// The dm-verity target of the dm= parameter, if any.
+	DmVerity *DmVerityState `protobuf:"bytes,7,opt,name=dm_verity,json=dmVerity,proto3" json:"dm_verity,omitempty"`
+	// Why a parameter could not be parsed, e.g. a malformed dm= parameter, in
+	// which case its field is left empty. Empty if all the parameters parsed.
+	ParseError string `protobuf:"bytes,8,opt,name=parse_error,json=parseError,proto3" json:"parse_error,omitempty"`
+}
+
+func (x *LinuxKernelState) Reset() {
+	*x = LinuxKernelState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LinuxKernelState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LinuxKernelState) ProtoMessage() {}
+
+func (x *LinuxKernelState) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LinuxKernelState.ProtoReflect.Descriptor instead.
+func (*LinuxKernelState) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *LinuxKernelState) GetCommandLine() string {
+	if x != nil {
+		return x.CommandLine
+	}
+	return ""
+}
+
+func (x *LinuxKernelState) GetInit() string {
+	if x != nil {
+		return x.Init
+	}
+	return ""
+}
+
+func (x *LinuxKernelState) GetImaPolicies() []string {
+	if x != nil {
+		return x.ImaPolicies
+	}
+	return nil
+}
+
+func (x *LinuxKernelState) GetLockdown() KernelLockdown {
+	if x != nil {
+		return x.Lockdown
+	}
+	return KernelLockdown_LOCKDOWN_NONE
+}
+
+func (x *LinuxKernelState) GetSecurity() string {
+	if x != nil {
+		return x.Security
+	}
+	return ""
+}
+
+func (x *LinuxKernelState) GetModuleSigEnforce() bool {
+	if x != nil {
+		return x.ModuleSigEnforce
+	}
+	return false
+}
+
+func (x *LinuxKernelState) GetDmVerity() *DmVerityState {
+	if x != nil {
+		return x.DmVerity
+	}
+	return nil
+}
+
+func (x *LinuxKernelState) GetParseError() string {
+	if x != nil {
+		return x.ParseError
+	}
+	return ""
+}
+
+// The arguments of a dm-verity target set up from the kernel command line.
+type DmVerityState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The hash algorithm of the hash tree, e.g. "sha256".
+	Alg string `protobuf:"bytes,1,opt,name=alg,proto3" json:"alg,omitempty"`
+	// The hex-encoded root digest of the hash tree.
+	RootHexdigest string `protobuf:"bytes,2,opt,name=root_hexdigest,json=rootHexdigest,proto3" json:"root_hexdigest,omitempty"`
+	// The hex-encoded salt of the hash tree.
+	Salt string `protobuf:"bytes,3,opt,name=salt,proto3" json:"salt,omitempty"`
+	// The device holding the data.
+	Payload string `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`
+	// The device holding the hash tree.
+	Hashtree string `protobuf:"bytes,5,opt,name=hashtree,proto3" json:"hashtree,omitempty"`
+	// The sector where the hash tree starts on the hashtree device.
+	Hashstart uint64 `protobuf:"varint,6,opt,name=hashstart,proto3" json:"hashstart,omitempty"`
+	// The dm_verity.error_behavior= parameter.
+	ErrorBehavior string `protobuf:"bytes,7,opt,name=error_behavior,json=errorBehavior,proto3" json:"error_behavior,omitempty"`
+}
+
+func (x *DmVerityState) Reset() {
+	*x = DmVerityState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DmVerityState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DmVerityState) ProtoMessage() {}
+
+func (x *DmVerityState) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DmVerityState.ProtoReflect.Descriptor instead.
+func (*DmVerityState) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DmVerityState) GetAlg() string {
+	if x != nil {
+		return x.Alg
+	}
+	return ""
+}
+
+func (x *DmVerityState) GetRootHexdigest() string {
+	if x != nil {
+		return x.RootHexdigest
+	}
+	return ""
+}
+
+func (x *DmVerityState) GetSalt() string {
+	if x != nil {
+		return x.Salt
+	}
+	return ""
+}
+
+func (x *DmVerityState) GetPayload() string {
+	if x != nil {
+		return x.Payload
+	}
+	return ""
+}
+
+func (x *DmVerityState) GetHashtree() string {
+	if x != nil {
+		return x.Hashtree
+	}
+	return ""
+}
+
+func (x *DmVerityState) GetHashstart() uint64 {
+	if x != nil {
+		return x.Hashstart
+	}
+	return 0
+}
+
+func (x *DmVerityState) GetErrorBehavior() string {
+	if x != nil {
+		return x.ErrorBehavior
+	}
+	return ""
+}
+
+// A parsed event from the TCG event log
+type Event struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The Platform Control Register (PCR) this event was extended into.
+	PcrIndex uint32 `protobuf:"varint,1,opt,name=pcr_index,json=pcrIndex,proto3" json:"pcr_index,omitempty"`
+	// The type of this event. Note that this value is not verified, so it should
+	// only be used as a hint during event parsing.
+	UntrustedType uint32 `protobuf:"varint,2,opt,name=untrusted_type,json=untrustedType,proto3" json:"untrusted_type,omitempty"`
+	// The raw data associated to this event. The meaning of this data is
+	// specific to the type of the event.
+	Data []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	// The event digest actually extended into the TPM. This is often the hash of
+	// the data field, but in some cases it may have a type-specific calculation.
+	Digest []byte `protobuf:"bytes,4,opt,name=digest,proto3" json:"digest,omitempty"`
+	// This is true if hash(data) == digest.
+	DigestVerified bool `protobuf:"varint,5,opt,name=digest_verified,json=digestVerified,proto3" json:"digest_verified,omitempty"`
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Event) GetPcrIndex() uint32 {
+	if x != nil {
+		return x.PcrIndex
+	}
+	return 0
+}
+
+func (x *Event) GetUntrustedType() uint32 {
+	if x != nil {
+		return x.UntrustedType
+	}
+	return 0
+}
+
+func (x *Event) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Event) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *Event) GetDigestVerified() bool {
+	if x != nil {
+		return x.DigestVerified
+	}
+	return false
+}
+
+type Certificate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The representation of the certificate. If the certificate matches a
+	// well-known certificate above, representation should contain the value in
+	// the enum. Otherwise, it will contain the raw DER.
+	//
+	// Types that are assignable to Representation:
+	//
+	//	*Certificate_Der
+	//	*Certificate_WellKnown
+	Representation isCertificate_Representation `protobuf_oneof:"representation"`
+}
+
+func (x *Certificate) Reset() {
+	*x = Certificate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Certificate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Certificate) ProtoMessage() {}
+
+func (x *Certificate) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Certificate.ProtoReflect.Descriptor instead.
+func (*Certificate) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{14}
+}
+
+func (m *Certificate) GetRepresentation() isCertificate_Representation {
+	if m != nil {
+		return m.Representation
+	}
+	return nil
+}
+
+func (x *Certificate) GetDer() []byte {
+	if x, ok := x.GetRepresentation().(*Certificate_Der); ok {
+		return x.Der
+	}
+	return nil
+}
+
+func (x *Certificate) GetWellKnown() WellKnownCertificate {
+	if x, ok := x.GetRepresentation().(*Certificate_WellKnown); ok {
+		return x.WellKnown
+	}
+	return WellKnownCertificate_UNKNOWN
+}
+
+type isCertificate_Representation interface {
+	isCertificate_Representation()
+}
+
+type Certificate_Der struct {
+	// DER representation of the certificate.
+	Der []byte `protobuf:"bytes,1,opt,name=der,proto3,oneof"`
+}
+
+type Certificate_WellKnown struct {
+	WellKnown WellKnownCertificate `protobuf:"varint,2,opt,name=well_known,json=wellKnown,proto3,enum=attest.WellKnownCertificate,oneof"`
+}
+
+func (*Certificate_Der) isCertificate_Representation() {}
+
+func (*Certificate_WellKnown) isCertificate_Representation() {}
+
+// A Secure Boot database containing lists of hashes and certificates,
+// as defined by section 32.4.1 Signature Database in the UEFI spec.
+type Database struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Certs  []*Certificate `protobuf:"bytes,1,rep,name=certs,proto3" json:"certs,omitempty"`
+	Hashes [][]byte       `protobuf:"bytes,2,rep,name=hashes,proto3" json:"hashes,omitempty"`
+}
+
+func (x *Database) Reset() {
+	*x = Database{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Database) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Database) ProtoMessage() {}
+
+func (x *Database) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Database.ProtoReflect.Descriptor instead.
+func (*Database) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Database) GetCerts() []*Certificate {
+	if x != nil {
+		return x.Certs
+	}
+	return nil
+}
+
+func (x *Database) GetHashes() [][]byte {
+	if x != nil {
+		return x.Hashes
+	}
+	return nil
+}
+
+// The Secure Boot state for this instance.
+type SecureBootState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Whether Secure Boot is enabled.
+	Enabled bool `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// The Secure Boot signature (allowed) database.
+	Db *Database `protobuf:"bytes,2,opt,name=db,proto3" json:"db,omitempty"`
+	// The Secure Boot revoked signature (forbidden) database.
+	Dbx *Database `protobuf:"bytes,3,opt,name=dbx,proto3" json:"dbx,omitempty"`
+	// Authority events post-separator. Pre-separator authorities
+	// are currently not supported.
+	Authority *Database `protobuf:"bytes,4,opt,name=authority,proto3" json:"authority,omitempty"`
+}
+
+func (x *SecureBootState) Reset() {
+	*x = SecureBootState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SecureBootState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecureBootState) ProtoMessage() {}
+
+func (x *SecureBootState) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecureBootState.ProtoReflect.Descriptor instead.
+func (*SecureBootState) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SecureBootState) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *SecureBootState) GetDb() *Database {
+	if x != nil {
+		return x.Db
+	}
+	return nil
+}
+
+func (x *SecureBootState) GetDbx() *Database {
+	if x != nil {
+		return x.Dbx
+	}
+	return nil
+}
+
+func (x *SecureBootState) GetAuthority() *Database {
+	if x != nil {
+		return x.Authority
+	}
+	return nil
+}
+
+// A checkpoint of the output log of a workload.
+type WorkloadOutput struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The number of bytes of the log covered by the checkpoint.
+	Size uint64 `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	// The SHA-256 digest of the first size bytes of the log.
+	Digest []byte `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+}
+
+func (x *WorkloadOutput) Reset() {
+	*x = WorkloadOutput{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WorkloadOutput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkloadOutput) ProtoMessage() {}
+
+func (x *WorkloadOutput) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkloadOutput.ProtoReflect.Descriptor instead.
+func (*WorkloadOutput) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *WorkloadOutput) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *WorkloadOutput) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+type ContainerState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ImageReference string `protobuf:"bytes,1,opt,name=image_reference,json=imageReference,proto3" json:"image_reference,omitempty"`
+	// Digest of the registry's image manifest, which contains a list of the
+	// layers comprising the image.
+	ImageDigest   string        `protobuf:"bytes,2,opt,name=image_digest,json=imageDigest,proto3" json:"image_digest,omitempty"`
+	RestartPolicy RestartPolicy `protobuf:"varint,3,opt,name=restart_policy,json=restartPolicy,proto3,enum=attest.RestartPolicy" json:"restart_policy,omitempty"`
+	// Digest of the local image configuration object, containing config items
+	// such as local layer digests.
+	ImageId string            `protobuf:"bytes,4,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
+	Args    []string          `protobuf:"bytes,5,rep,name=args,proto3" json:"args,omitempty"`
+	EnvVars map[string]string `protobuf:"bytes,6,rep,name=env_vars,json=envVars,proto3" json:"env_vars,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Record operator input Env Vars and Args, should be subsets of the above
+	// Env Vars and Args.
+	OverriddenArgs    []string          `protobuf:"bytes,7,rep,name=overridden_args,json=overriddenArgs,proto3" json:"overridden_args,omitempty"`
+	OverriddenEnvVars map[string]string `protobuf:"bytes,8,rep,name=overridden_env_vars,json=overriddenEnvVars,proto3" json:"overridden_env_vars,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Hardening settings the container was started with.
+	HostNetwork       bool     `protobuf:"varint,9,opt,name=host_network,json=hostNetwork,proto3" json:"host_network,omitempty"`
+	ReadOnlyRootfs    bool     `protobuf:"varint,10,opt,name=read_only_rootfs,json=readOnlyRootfs,proto3" json:"read_only_rootfs,omitempty"`
+	AddedCapabilities []string `protobuf:"bytes,11,rep,name=added_capabilities,json=addedCapabilities,proto3" json:"added_capabilities,omitempty"`
+	Mounts            []*Mount `protobuf:"bytes,12,rep,name=mounts,proto3" json:"mounts,omitempty"`
+	// Host paths of the devices available to the container.
+	Devices []string `protobuf:"bytes,13,rep,name=devices,proto3" json:"devices,omitempty"`
+	// Digest of the ephemeral public key generated for this boot of the
+	// workload, whose private key is only available to the container.
+	WorkloadKeyDigest string `protobuf:"bytes,14,opt,name=workload_key_digest,json=workloadKeyDigest,proto3" json:"workload_key_digest,omitempty"`
+	// Digests of the image layers from the registry's image manifest, in order.
+	ImageLayerDigests []string `protobuf:"bytes,15,rep,name=image_layer_digests,json=imageLayerDigests,proto3" json:"image_layer_digests,omitempty"`
+	// Digest of the workload configuration provided by the operator and
+	// mounted into the container.
+	WorkloadConfigDigest string `protobuf:"bytes,16,opt,name=workload_config_digest,json=workloadConfigDigest,proto3" json:"workload_config_digest,omitempty"`
+	// The user the container process runs as, "UID" or "UID:GID", if the
+	// operator overrode the user of the image.
+	User string `protobuf:"bytes,17,opt,name=user,proto3" json:"user,omitempty"`
+	// Whether the container runs in a user namespace, its root user being
+	// mapped to an unprivileged user of the host.
+	UserNamespace bool `protobuf:"varint,18,opt,name=user_namespace,json=userNamespace,proto3" json:"user_namespace,omitempty"`
+	// The seccomp profile of the container: "unconfined", "runtime-default",
+	// or "sha256:" followed by the hex SHA-256 of a custom JSON profile.
+	SeccompProfile string `protobuf:"bytes,19,opt,name=seccomp_profile,json=seccompProfile,proto3" json:"seccomp_profile,omitempty"`
+	// The AppArmor profile of the container: "unconfined", "runtime-default",
+	// or the name of a profile of the host.
+	ApparmorProfile string `protobuf:"bytes,20,opt,name=apparmor_profile,json=apparmorProfile,proto3" json:"apparmor_profile,omitempty"`
+	// The digest of the signed launch policy document enforced by the
+	// launcher, as "sha256:" followed by its hex SHA-256.
+	LaunchPolicyDigest string `protobuf:"bytes,21,opt,name=launch_policy_digest,json=launchPolicyDigest,proto3" json:"launch_policy_digest,omitempty"`
+	// The digest of the public key which signed the launch policy document, as
+	// "sha256:" followed by the hex SHA-256 of its DER SubjectPublicKeyInfo.
+	LaunchPolicySigner string `protobuf:"bytes,22,opt,name=launch_policy_signer,json=launchPolicySigner,proto3" json:"launch_policy_signer,omitempty"`
+	// The time the launcher started, in RFC 3339 format in UTC, as read from
+	// the clock of the VM.
+	BootTime string `protobuf:"bytes,23,opt,name=boot_time,json=bootTime,proto3" json:"boot_time,omitempty"`
+	// The clock the launcher checked the clock of the VM against at boot:
+	// "metadata" for the metadata server, "registry" for the image registry, or
+	// "unverified" if neither was available.
+	ClockSource string `protobuf:"bytes,24,opt,name=clock_source,json=clockSource,proto3" json:"clock_source,omitempty"`
+	// The last checkpoint of the output log of the workload, measured while it
+	// runs. Unset if its output is not captured.
+	WorkloadOutput *WorkloadOutput `protobuf:"bytes,25,opt,name=workload_output,json=workloadOutput,proto3" json:"workload_output,omitempty"`
+	// The platform of the image, e.g. "linux/arm64", from its config.
+	ImagePlatform string `protobuf:"bytes,26,opt,name=image_platform,json=imagePlatform,proto3" json:"image_platform,omitempty"`
+	// Digest of the image manifest for the platform. It differs from
+	// image_digest when the image is a multi-arch index.
+	ImageManifestDigest string `protobuf:"bytes,27,opt,name=image_manifest_digest,json=imageManifestDigest,proto3" json:"image_manifest_digest,omitempty"`
+	// The CDI (Container Device Interface) devices injected into the
+	// container, in the order they were requested.
+	CdiDevices []*CDIDevice `protobuf:"bytes,28,rep,name=cdi_devices,json=cdiDevices,proto3" json:"cdi_devices,omitempty"`
+	// Whether the launcher only started the workload once it was attested.
+	AttestBeforeRun bool `protobuf:"varint,29,opt,name=attest_before_run,json=attestBeforeRun,proto3" json:"attest_before_run,omitempty"`
+	// The URL of the relying party which approved the start of the workload,
+	// if an approval was required.
+	ApprovalUrl string `protobuf:"bytes,30,opt,name=approval_url,json=approvalUrl,proto3" json:"approval_url,omitempty"`
+}
+
+func (x *ContainerState) Reset() {
+	*x = ContainerState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ContainerState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerState) ProtoMessage() {}
+
+func (x *ContainerState) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerState.ProtoReflect.Descriptor instead.
+func (*ContainerState) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ContainerState) GetImageReference() string {
+	if x != nil {
+		return x.ImageReference
+	}
+	return ""
+}
+
+func (x *ContainerState) GetImageDigest() string {
+	if x != nil {
+		return x.ImageDigest
+	}
+	return ""
+}
+
+func (x *ContainerState) GetRestartPolicy() RestartPolicy {
+	if x != nil {
+		return x.RestartPolicy
+	}
+	return RestartPolicy_Always
+}
+
+func (x *ContainerState) GetImageId() string {
+	if x != nil {
+		return x.ImageId
+	}
+	return ""
+}
+
+func (x *ContainerState) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *ContainerState) GetEnvVars() map[string]string {
+	if x != nil {
+		return x.EnvVars
+	}
+	return nil
+}
+
+func (x *ContainerState) GetOverriddenArgs() []string {
+	if x != nil {
+		return x.OverriddenArgs
+	}
+	return nil
+}
+
+func (x *ContainerState) GetOverriddenEnvVars() map[string]string {
+	if x != nil {
+		return x.OverriddenEnvVars
+	}
+	return nil
+}
+
+func (x *ContainerState) GetHostNetwork() bool {
+	if x != nil {
+		return x.HostNetwork
+	}
+	return false
+}
+
+func (x *ContainerState) GetReadOnlyRootfs() bool {
+	if x != nil {
+		return x.ReadOnlyRootfs
+	}
+	return false
+}
+
+func (x *ContainerState) GetAddedCapabilities() []string {
+	if x != nil {
+		return x.AddedCapabilities
+	}
+	return nil
+}
+
+func (x *ContainerState) GetMounts() []*Mount {
+	if x != nil {
+		return x.Mounts
+	}
+	return nil
+}
+
+func (x *ContainerState) GetDevices() []string {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+func (x *ContainerState) GetWorkloadKeyDigest() string {
+	if x != nil {
+		return x.WorkloadKeyDigest
+	}
+	return ""
+}
+
+func (x *ContainerState) GetImageLayerDigests() []string {
+	if x != nil {
+		return x.ImageLayerDigests
+	}
+	return nil
+}
+
+func (x *ContainerState) GetWorkloadConfigDigest() string {
+	if x != nil {
+		return x.WorkloadConfigDigest
+	}
+	return ""
+}
+
+func (x *ContainerState) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *ContainerState) GetUserNamespace() bool {
+	if x != nil {
+		return x.UserNamespace
+	}
+	return false
+}
+
+func (x *ContainerState) GetSeccompProfile() string {
+	if x != nil {
+		return x.SeccompProfile
+	}
+	return ""
+}
+
+func (x *ContainerState) GetApparmorProfile() string {
+	if x != nil {
+		return x.ApparmorProfile
+	}
+	return ""
+}
+
+func (x *ContainerState) GetLaunchPolicyDigest() string {
+	if x != nil {
+		return x.LaunchPolicyDigest
+	}
+	return ""
+}
+
+func (x *ContainerState) GetLaunchPolicySigner() string {
+	if x != nil {
+		return x.LaunchPolicySigner
+	}
+	return ""
+}
+
+func (x *ContainerState) GetBootTime() string {
+	if x != nil {
+		return x.BootTime
+	}
+	return ""
+}
+
+func (x *ContainerState) GetClockSource() string {
+	if x != nil {
+		return x.ClockSource
+	}
+	return ""
+}
+
+func (x *ContainerState) GetWorkloadOutput() *WorkloadOutput {
+	if x != nil {
+		return x.WorkloadOutput
+	}
+	return nil
+}
+
+func (x *ContainerState) GetImagePlatform() string {
+	if x != nil {
+		return x.ImagePlatform
+	}
+	return ""
+}
+
+func (x *ContainerState) GetImageManifestDigest() string {
+	if x != nil {
+		return x.ImageManifestDigest
+	}
+	return ""
+}
+
+func (x *ContainerState) GetCdiDevices() []*CDIDevice {
+	if x != nil {
+		return x.CdiDevices
+	}
+	return nil
+}
+
+func (x *ContainerState) GetAttestBeforeRun() bool {
+	if x != nil {
+		return x.AttestBeforeRun
+	}
+	return false
+}
+
+func (x *ContainerState) GetApprovalUrl() string {
+	if x != nil {
+		return x.ApprovalUrl
+	}
+	return ""
+}
+
+// A CDI device injected into the container, resolved from the CDI specs of
+// the host.
+type CDIDevice struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The fully qualified name of the device, e.g. "nvidia.com/gpu=0".
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The host paths of the device nodes of the device.
+	DeviceNodes []string `protobuf:"bytes,2,rep,name=device_nodes,json=deviceNodes,proto3" json:"device_nodes,omitempty"`
+}
+
+func (x *CDIDevice) Reset() {
+	*x = CDIDevice{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CDIDevice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CDIDevice) ProtoMessage() {}
+
+func (x *CDIDevice) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CDIDevice.ProtoReflect.Descriptor instead.
+func (*CDIDevice) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CDIDevice) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CDIDevice) GetDeviceNodes() []string {
+	if x != nil {
+		return x.DeviceNodes
+	}
+	return nil
+}
+
+// A filesystem mounted into the container.
+type Mount struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Either "bind", "tmpfs" or "gcs".
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// Host path of a bind mount, bucket of a gcs mount, empty for tmpfs mounts.
+	Source      string `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Destination string `protobuf:"bytes,3,opt,name=destination,proto3" json:"destination,omitempty"`
+	ReadOnly    bool   `protobuf:"varint,4,opt,name=read_only,json=readOnly,proto3" json:"read_only,omitempty"`
+}
+
+func (x *Mount) Reset() {
+	*x = Mount{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Mount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Mount) ProtoMessage() {}
+
+func (x *Mount) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Mount.ProtoReflect.Descriptor instead.
+func (*Mount) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Mount) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Mount) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Mount) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *Mount) GetReadOnly() bool {
+	if x != nil {
+		return x.ReadOnly
+	}
+	return false
+}
+
+type SemanticVersion struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Major uint32 `protobuf:"varint,1,opt,name=major,proto3" json:"major,omitempty"`
+	Minor uint32 `protobuf:"varint,2,opt,name=minor,proto3" json:"minor,omitempty"`
+	Patch uint32 `protobuf:"varint,3,opt,name=patch,proto3" json:"patch,omitempty"`
+}
+
+func (x *SemanticVersion) Reset() {
+	*x = SemanticVersion{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SemanticVersion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SemanticVersion) ProtoMessage() {}
+
+func (x *SemanticVersion) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SemanticVersion.ProtoReflect.Descriptor instead.
+func (*SemanticVersion) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SemanticVersion) GetMajor() uint32 {
+	if x != nil {
+		return x.Major
+	}
+	return 0
+}
+
+func (x *SemanticVersion) GetMinor() uint32 {
+	if x != nil {
+		return x.Minor
+	}
+	return 0
+}
+
+func (x *SemanticVersion) GetPatch() uint32 {
+	if x != nil {
+		return x.Patch
+	}
+	return 0
+}
+
+// The timestamp of a COS event, recorded in its Canonical Event Log record.
+type CosEventTimestamp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The record number of the event in the Canonical Event Log.
+	RecordNumber uint64 `protobuf:"varint,1,opt,name=record_number,json=recordNumber,proto3" json:"record_number,omitempty"`
+	// The COS event type (cel.CosType) of the event.
+	EventType uint32 `protobuf:"varint,2,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	Timestamp uint64 `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+}
+
+func (x *CosEventTimestamp) Reset() {
+	*x = CosEventTimestamp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CosEventTimestamp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CosEventTimestamp) ProtoMessage() {}
+
+func (x *CosEventTimestamp) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CosEventTimestamp.ProtoReflect.Descriptor instead.
+func (*CosEventTimestamp) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CosEventTimestamp) GetRecordNumber() uint64 {
+	if x != nil {
+		return x.RecordNumber
+	}
+	return 0
+}
+
+func (x *CosEventTimestamp) GetEventType() uint32 {
+	if x != nil {
+		return x.EventType
+	}
+	return 0
+}
+
+func (x *CosEventTimestamp) GetTimestamp() uint64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+// A COS event of the Canonical Event Log, with its raw content.
+type CosEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The COS event type (cel.CosType) of the event.
+	EventType    uint32 `protobuf:"varint,1,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	EventContent []byte `protobuf:"bytes,2,opt,name=event_content,json=eventContent,proto3" json:"event_content,omitempty"`
+}
+
+func (x *CosEvent) Reset() {
+	*x = CosEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CosEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CosEvent) ProtoMessage() {}
+
+func (x *CosEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CosEvent.ProtoReflect.Descriptor instead.
+func (*CosEvent) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CosEvent) GetEventType() uint32 {
+	if x != nil {
+		return x.EventType
+	}
+	return 0
+}
+
+func (x *CosEvent) GetEventContent() []byte {
+	if x != nil {
+		return x.EventContent
+	}
+	return nil
+}
+
+type AttestedCosState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Container       *ContainerState  `protobuf:"bytes,1,opt,name=container,proto3" json:"container,omitempty"`
+	CosVersion      *SemanticVersion `protobuf:"bytes,2,opt,name=cos_version,json=cosVersion,proto3" json:"cos_version,omitempty"`
+	LauncherVersion *SemanticVersion `protobuf:"bytes,3,opt,name=launcher_version,json=launcherVersion,proto3" json:"launcher_version,omitempty"`
+	// The source of the event timestamps, unspecified if the records of the
+	// Canonical Event Log are not timestamped.
+	TimestampSource CelTimestampSource `protobuf:"varint,4,opt,name=timestamp_source,json=timestampSource,proto3,enum=attest.CelTimestampSource" json:"timestamp_source,omitempty"`
+	// The timestamps of the timestamped COS events, in the order of the log.
+	// They never decrease.
+	EventTimestamps []*CosEventTimestamp `protobuf:"bytes,5,rep,name=event_timestamps,json=eventTimestamps,proto3" json:"event_timestamps,omitempty"`
+	// The hash algorithms of the digests of every record of the Canonical Event
+	// Log, empty if the log has no header recording them.
+	CelHashAlgos []tpm.HashAlgo `protobuf:"varint,6,rep,packed,name=cel_hash_algos,json=celHashAlgos,proto3,enum=tpm.HashAlgo" json:"cel_hash_algos,omitempty"`
+	// The version of the COS event schema of the log (cel.CosSchemaVersion),
+	// zero if the log has no SchemaVersion event.
+	SchemaVersion uint32 `protobuf:"varint,7,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	// The events of types unknown to the verifier, from a newer schema version,
+	// in the order of the log. They are only tolerated in logs with a schema
+	// version.
+	UnknownEvents []*CosEvent `protobuf:"bytes,8,rep,name=unknown_events,json=unknownEvents,proto3" json:"unknown_events,omitempty"`
+	// The kernel modules loaded while the launcher ran, recorded by IMA and
+	// measured into PCR 15 (cel.KernelModulePCR), in the order of the log. They
+	// are only checked against the quoted IMA PCR 11 when the attestation has
+	// the IMA log.
+	KernelModules []*KernelModule `protobuf:"bytes,9,rep,name=kernel_modules,json=kernelModules,proto3" json:"kernel_modules,omitempty"`
+	// The workload which is not a container run by the launcher, measured by
+	// an embedder of the attestation agent, if any.
+	Workload *WorkloadState `protobuf:"bytes,10,opt,name=workload,proto3" json:"workload,omitempty"`
+	// The digest of the binary of the launcher (e.g. "sha256:..."), measured
+	// with its launcher_version.
+	LauncherBinaryDigest string `protobuf:"bytes,11,opt,name=launcher_binary_digest,json=launcherBinaryDigest,proto3" json:"launcher_binary_digest,omitempty"`
+	// The kernel log measured by the launcher, if it was measured.
+	KernelLog *KernelLogState `protobuf:"bytes,12,opt,name=kernel_log,json=kernelLog,proto3" json:"kernel_log,omitempty"`
+}
+
+func (x *AttestedCosState) Reset() {
+	*x = AttestedCosState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AttestedCosState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttestedCosState) ProtoMessage() {}
+
+func (x *AttestedCosState) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttestedCosState.ProtoReflect.Descriptor instead.
+func (*AttestedCosState) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *AttestedCosState) GetContainer() *ContainerState {
+	if x != nil {
+		return x.Container
+	}
+	return nil
+}
+
+func (x *AttestedCosState) GetCosVersion() *SemanticVersion {
+	if x != nil {
+		return x.CosVersion
+	}
+	return nil
+}
+
+func (x *AttestedCosState) GetLauncherVersion() *SemanticVersion {
+	if x != nil {
+		return x.LauncherVersion
+	}
+	return nil
+}
+
+func (x *AttestedCosState) GetTimestampSource() CelTimestampSource {
+	if x != nil {
+		return x.TimestampSource
+	}
+	return CelTimestampSource_CEL_TIMESTAMP_SOURCE_UNSPECIFIED
+}
+
+func (x *AttestedCosState) GetEventTimestamps() []*CosEventTimestamp {
+	if x != nil {
+		return x.EventTimestamps
+	}
+	return nil
+}
+
+func (x *AttestedCosState) GetCelHashAlgos() []tpm.HashAlgo {
+	if x != nil {
+		return x.CelHashAlgos
+	}
+	return nil
+}
+
+func (x *AttestedCosState) GetSchemaVersion() uint32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+func (x *AttestedCosState) GetUnknownEvents() []*CosEvent {
+	if x != nil {
+		return x.UnknownEvents
+	}
+	return nil
+}
+
+func (x *AttestedCosState) GetKernelModules() []*KernelModule {
+	if x != nil {
+		return x.KernelModules
+	}
+	return nil
+}
+
+func (x *AttestedCosState) GetWorkload() *WorkloadState {
+	if x != nil {
+		return x.Workload
+	}
+	return nil
+}
+
+func (x *AttestedCosState) GetLauncherBinaryDigest() string {
+	if x != nil {
+		return x.LauncherBinaryDigest
+	}
+	return ""
+}
+
+func (x *AttestedCosState) GetKernelLog() *KernelLogState {
+	if x != nil {
+		return x.KernelLog
+	}
+	return nil
+}
+
+// The kernel log ring buffer (dmesg) of the host when it was measured.
+type KernelLogState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The digest of the kernel log (e.g. "sha256:...").
+	Digest string `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	// The taint flags of the kernel, see
+	// https://docs.kernel.org/admin-guide/tainted-kernels.html.
+	Taint uint64 `protobuf:"varint,2,opt,name=taint,proto3" json:"taint,omitempty"`
+}
+
+func (x *KernelLogState) Reset() {
+	*x = KernelLogState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KernelLogState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KernelLogState) ProtoMessage() {}
+
+func (x *KernelLogState) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KernelLogState.ProtoReflect.Descriptor instead.
+func (*KernelLogState) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *KernelLogState) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *KernelLogState) GetTaint() uint64 {
+	if x != nil {
+		return x.Taint
+	}
+	return 0
+}
+
+// A workload which is not a container, e.g. a systemd service, measured into
+// the Canonical Event Log by an embedder of the attestation agent.
+type WorkloadState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The name of the workload, e.g. the name of its systemd unit.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The executables of the workload, in the order of the log.
+	Binaries []*WorkloadBinary `protobuf:"bytes,2,rep,name=binaries,proto3" json:"binaries,omitempty"`
+}
+
+func (x *WorkloadState) Reset() {
+	*x = WorkloadState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WorkloadState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkloadState) ProtoMessage() {}
+
+func (x *WorkloadState) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkloadState.ProtoReflect.Descriptor instead.
+func (*WorkloadState) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *WorkloadState) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *WorkloadState) GetBinaries() []*WorkloadBinary {
+	if x != nil {
+		return x.Binaries
+	}
+	return nil
+}
+
+// An executable of a workload.
+type WorkloadBinary struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The digest of the file, prefixed by its hash algorithm (e.g.
+	// "sha256:...").
+	FileDigest string `protobuf:"bytes,1,opt,name=file_digest,json=fileDigest,proto3" json:"file_digest,omitempty"`
+	// The path of the file.
+	Path string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+}
+
+func (x *WorkloadBinary) Reset() {
+	*x = WorkloadBinary{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WorkloadBinary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkloadBinary) ProtoMessage() {}
+
+func (x *WorkloadBinary) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkloadBinary.ProtoReflect.Descriptor instead.
+func (*WorkloadBinary) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *WorkloadBinary) GetFileDigest() string {
+	if x != nil {
+		return x.FileDigest
+	}
+	return ""
+}
+
+func (x *WorkloadBinary) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+// A kernel module load, recorded by IMA.
+type KernelModule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The digest of the module file, prefixed by its hash algorithm (e.g.
+	// "sha256:...").
+	FileDigest string `protobuf:"bytes,1,opt,name=file_digest,json=fileDigest,proto3" json:"file_digest,omitempty"`
+	// The path of the module file.
+	FileName string `protobuf:"bytes,2,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+}
+
+func (x *KernelModule) Reset() {
+	*x = KernelModule{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KernelModule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KernelModule) ProtoMessage() {}
+
+func (x *KernelModule) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KernelModule.ProtoReflect.Descriptor instead.
+func (*KernelModule) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *KernelModule) GetFileDigest() string {
+	if x != nil {
+		return x.FileDigest
+	}
+	return ""
+}
+
+func (x *KernelModule) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+// The TPMS_CLOCK_INFO of the quote used to verify an Attestation.
+type TPMClockInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The time in milliseconds during which the TPM has been powered. It is
+	// saved to NV memory periodically, so it never goes backwards unless the
+	// TPM lost power before saving it, in which case safe is false.
+	Clock uint64 `protobuf:"varint,1,opt,name=clock,proto3" json:"clock,omitempty"`
+	// The number of TPM resets (TPM2_Startup(CLEAR)), i.e. reboots, since the
+	// TPM was last cleared.
+	// The TPM obfuscates the counts in the quotes of AKs outside of the
+	// endorsement and platform hierarchies, so they are only set for AKs known
+	// to be in the endorsement hierarchy (GCE AKs certified by the trusted
+	// roots, or see server.VerifyOpts.AKInEndorsementHierarchy), and are 0
+	// otherwise.
+	ResetCount uint32 `protobuf:"varint,2,opt,name=reset_count,json=resetCount,proto3" json:"reset_count,omitempty"`
+	// The number of TPM restarts or resumes (hibernation or suspend) since the
+	// last TPM reset.
+	RestartCount uint32 `protobuf:"varint,3,opt,name=restart_count,json=restartCount,proto3" json:"restart_count,omitempty"`
+	// Whether clock is guaranteed to not have been reported before.
+	Safe bool `protobuf:"varint,4,opt,name=safe,proto3" json:"safe,omitempty"`
+}
+
+func (x *TPMClockInfo) Reset() {
+	*x = TPMClockInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TPMClockInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TPMClockInfo) ProtoMessage() {}
+
+func (x *TPMClockInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TPMClockInfo.ProtoReflect.Descriptor instead.
+func (*TPMClockInfo) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *TPMClockInfo) GetClock() uint64 {
+	if x != nil {
+		return x.Clock
+	}
+	return 0
+}
+
+func (x *TPMClockInfo) GetResetCount() uint32 {
+	if x != nil {
+		return x.ResetCount
+	}
+	return 0
+}
+
+func (x *TPMClockInfo) GetRestartCount() uint32 {
+	if x != nil {
+		return x.RestartCount
+	}
+	return 0
+}
+
+func (x *TPMClockInfo) GetSafe() bool {
+	if x != nil {
+		return x.Safe
+	}
+	return false
+}
+
+// A measurement of the Linux Integrity Measurement Architecture (IMA), e.g.
+// of an executed binary.
+type ImaMeasurement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The PCR the measurement was extended into.
+	Pcr uint32 `protobuf:"varint,1,opt,name=pcr,proto3" json:"pcr,omitempty"`
+	// The SHA-1 digest of the template data, as in the measurement list.
+	TemplateHash []byte `protobuf:"bytes,2,opt,name=template_hash,json=templateHash,proto3" json:"template_hash,omitempty"`
+	// The IMA template, e.g. "ima-ng".
+	TemplateName string `protobuf:"bytes,3,opt,name=template_name,json=templateName,proto3" json:"template_name,omitempty"`
+	// The digest of the file, as in the measurement list, e.g. "sha256:" followed
+	// by its hex digest for the ima-ng template.
+	FileDigest string `protobuf:"bytes,4,opt,name=file_digest,json=fileDigest,proto3" json:"file_digest,omitempty"`
+	FileName   string `protobuf:"bytes,5,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+}
+
+func (x *ImaMeasurement) Reset() {
+	*x = ImaMeasurement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImaMeasurement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImaMeasurement) ProtoMessage() {}
+
+func (x *ImaMeasurement) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImaMeasurement.ProtoReflect.Descriptor instead.
+func (*ImaMeasurement) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ImaMeasurement) GetPcr() uint32 {
+	if x != nil {
+		return x.Pcr
+	}
+	return 0
+}
+
+func (x *ImaMeasurement) GetTemplateHash() []byte {
+	if x != nil {
+		return x.TemplateHash
+	}
+	return nil
+}
+
+func (x *ImaMeasurement) GetTemplateName() string {
+	if x != nil {
+		return x.TemplateName
+	}
+	return ""
+}
+
+func (x *ImaMeasurement) GetFileDigest() string {
+	if x != nil {
+		return x.FileDigest
+	}
+	return ""
+}
+
+func (x *ImaMeasurement) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+// The runtime state of a machine measured by IMA.
+type ImaState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The measurements covered by the quoted PCR 10, or PCR 11 for the kernel
+	// module loads (client.KernelModuleIMAPCR), in order, whose file digest and
+	// file name match their template hash.
+	Measurements []*ImaMeasurement `protobuf:"bytes,1,rep,name=measurements,proto3" json:"measurements,omitempty"`
+	// The measurements covered by the quoted PCRs whose template data cannot
+	// be recomputed: violations, and templates other than ima-ng. Only their
+	// template hashes are verified, not their file digests and names.
+	UnverifiedMeasurements []*ImaMeasurement `protobuf:"bytes,2,rep,name=unverified_measurements,json=unverifiedMeasurements,proto3" json:"unverified_measurements,omitempty"`
+}
+
+func (x *ImaState) Reset() {
+	*x = ImaState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImaState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImaState) ProtoMessage() {}
+
+func (x *ImaState) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImaState.ProtoReflect.Descriptor instead.
+func (*ImaState) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ImaState) GetMeasurements() []*ImaMeasurement {
+	if x != nil {
+		return x.Measurements
+	}
+	return nil
+}
+
+func (x *ImaState) GetUnverifiedMeasurements() []*ImaMeasurement {
+	if x != nil {
+		return x.UnverifiedMeasurements
+	}
+	return nil
+}
+
+// The verified state of a booted machine, obtained from an Attestation
+type MachineState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Platform   *PlatformState   `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`
+	SecureBoot *SecureBootState `protobuf:"bytes,2,opt,name=secure_boot,json=secureBoot,proto3" json:"secure_boot,omitempty"`
+	// The complete parsed TCG Event Log, including those events used to
+	// create the PlatformState.
+	RawEvents []*Event `protobuf:"bytes,3,rep,name=raw_events,json=rawEvents,proto3" json:"raw_events,omitempty"`
+	// The hash algorithm used when verifying the Attestation. This indicates:
+	//   - which PCR bank was used for for quote validation and event log replay
+	//   - the hash algorithm used to calculate event digests
+	Hash                  tpm.HashAlgo                `protobuf:"varint,4,opt,name=hash,proto3,enum=tpm.HashAlgo" json:"hash,omitempty"`
+	Grub                  *GrubState                  `protobuf:"bytes,5,opt,name=grub,proto3" json:"grub,omitempty"`
+	LinuxKernel           *LinuxKernelState           `protobuf:"bytes,6,opt,name=linux_kernel,json=linuxKernel,proto3" json:"linux_kernel,omitempty"`
+	Cos                   *AttestedCosState           `protobuf:"bytes,7,opt,name=cos,proto3" json:"cos,omitempty"`
+	ClockInfo             *TPMClockInfo               `protobuf:"bytes,8,opt,name=clock_info,json=clockInfo,proto3" json:"clock_info,omitempty"`
+	Ima                   *ImaState                   `protobuf:"bytes,9,opt,name=ima,proto3" json:"ima,omitempty"`
+	ConfidentialComputing *ConfidentialComputingState `protobuf:"bytes,10,opt,name=confidential_computing,json=confidentialComputing,proto3" json:"confidential_computing,omitempty"`
+	// Whether the Attestation omitted the TCG event log, in which case the
+	// platform, secure boot, and boot states are empty.
+	EventLogOmitted bool `protobuf:"varint,11,opt,name=event_log_omitted,json=eventLogOmitted,proto3" json:"event_log_omitted,omitempty"`
+}
+
+func (x *MachineState) Reset() {
+	*x = MachineState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MachineState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MachineState) ProtoMessage() {}
+
+func (x *MachineState) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MachineState.ProtoReflect.Descriptor instead.
+func (*MachineState) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *MachineState) GetPlatform() *PlatformState {
+	if x != nil {
+		return x.Platform
+	}
+	return nil
+}
+
+func (x *MachineState) GetSecureBoot() *SecureBootState {
+	if x != nil {
+		return x.SecureBoot
+	}
+	return nil
+}
+
+func (x *MachineState) GetRawEvents() []*Event {
+	if x != nil {
+		return x.RawEvents
+	}
+	return nil
+}
+
+func (x *MachineState) GetHash() tpm.HashAlgo {
+	if x != nil {
+		return x.Hash
+	}
+	return tpm.HashAlgo(0)
+}
+
+func (x *MachineState) GetGrub() *GrubState {
+	if x != nil {
+		return x.Grub
+	}
+	return nil
+}
+
+func (x *MachineState) GetLinuxKernel() *LinuxKernelState {
+	if x != nil {
+		return x.LinuxKernel
+	}
+	return nil
+}
+
+func (x *MachineState) GetCos() *AttestedCosState {
+	if x != nil {
+		return x.Cos
+	}
+	return nil
+}
+
+func (x *MachineState) GetClockInfo() *TPMClockInfo {
+	if x != nil {
+		return x.ClockInfo
+	}
+	return nil
+}
+
+func (x *MachineState) GetIma() *ImaState {
+	if x != nil {
+		return x.Ima
+	}
+	return nil
+}
+
+func (x *MachineState) GetConfidentialComputing() *ConfidentialComputingState {
+	if x != nil {
+		return x.ConfidentialComputing
+	}
+	return nil
+}
+
+func (x *MachineState) GetEventLogOmitted() bool {
+	if x != nil {
+		return x.EventLogOmitted
+	}
+	return false
+}
+
+// The confidential computing technology protecting the instance, classified
+// from all the evidence of the Attestation.
+type ConfidentialComputingState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The technology measured by the firmware in the GCE Non-Host Info event,
+	// once it has been checked against the TEE evidence.
+	Technology GCEConfidentialTechnology `protobuf:"varint,1,opt,name=technology,proto3,enum=attest.GCEConfidentialTechnology" json:"technology,omitempty"`
+	// Whether verified TEE evidence (e.g. a SEV-SNP attestation report) proves
+	// the technology. Otherwise, it is only known from the firmware event log.
+	TeeEvidenceVerified bool `protobuf:"varint,2,opt,name=tee_evidence_verified,json=teeEvidenceVerified,proto3" json:"tee_evidence_verified,omitempty"`
+	// Whether the AK certificate is a production GCE certificate, so the
+	// firmware event log was measured by GCE firmware.
+	GceCertifiedAk bool `protobuf:"varint,3,opt,name=gce_certified_ak,json=gceCertifiedAk,proto3" json:"gce_certified_ak,omitempty"`
+}
+
+func (x *ConfidentialComputingState) Reset() {
+	*x = ConfidentialComputingState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConfidentialComputingState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfidentialComputingState) ProtoMessage() {}
+
+func (x *ConfidentialComputingState) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfidentialComputingState.ProtoReflect.Descriptor instead.
+func (*ConfidentialComputingState) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ConfidentialComputingState) GetTechnology() GCEConfidentialTechnology {
+	if x != nil {
+		return x.Technology
+	}
+	return GCEConfidentialTechnology_NONE
+}
+
+func (x *ConfidentialComputingState) GetTeeEvidenceVerified() bool {
+	if x != nil {
+		return x.TeeEvidenceVerified
+	}
+	return false
+}
+
+func (x *ConfidentialComputingState) GetGceCertifiedAk() bool {
+	if x != nil {
+		return x.GceCertifiedAk
+	}
+	return false
+}
+
+// A check performed while verifying an Attestation.
+type VerificationCheck struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The name of the check, e.g. "ak_trusted" or "quote_signature".
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The PCR bank of the quote the check was performed on, unset for the
+	// checks of the whole Attestation.
+	QuoteHash tpm.HashAlgo `protobuf:"varint,2,opt,name=quote_hash,json=quoteHash,proto3,enum=tpm.HashAlgo" json:"quote_hash,omitempty"`
+	// The SHA-256 digests of the inputs of the check, by input name.
+	InputDigests map[string][]byte `protobuf:"bytes,3,rep,name=input_digests,json=inputDigests,proto3" json:"input_digests,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Passed       bool              `protobuf:"varint,4,opt,name=passed,proto3" json:"passed,omitempty"`
+	// If the check failed, the name of its server.ErrorCode, e.g.
+	// "QUOTE_SIGNATURE", and the error.
+	ErrorCode string `protobuf:"bytes,5,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	Error     string `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *VerificationCheck) Reset() {
+	*x = VerificationCheck{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerificationCheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerificationCheck) ProtoMessage() {}
+
+func (x *VerificationCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerificationCheck.ProtoReflect.Descriptor instead.
+func (*VerificationCheck) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *VerificationCheck) GetName() string {
 	if x != nil {
-		return x.Data
+		return x.Name
 	}
-	return nil
+	return ""
 }
 
-func (x *Event) GetDigest() []byte {
+func (x *VerificationCheck) GetQuoteHash() tpm.HashAlgo {
 	if x != nil {
-		return x.Digest
+		return x.QuoteHash
+	}
+	return tpm.HashAlgo(0)
+}
+
+func (x *VerificationCheck) GetInputDigests() map[string][]byte {
+	if x != nil {
+		return x.InputDigests
 	}
 	return nil
 }
 
-func (x *Event) GetDigestVerified() bool {
+func (x *VerificationCheck) GetPassed() bool {
 	if x != nil {
-		return x.DigestVerified
+		return x.Passed
 	}
 	return false
 }
 
-type Certificate struct {
+func (x *VerificationCheck) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *VerificationCheck) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// The evidence trail of the verification of an Attestation, from which an
+// audit can reconstruct why it was verified or not.
+type VerificationReport struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// The representation of the certificate. If the certificate matches a
-	// well-known certificate above, representation should contain the value in
-	// the enum. Otherwise, it will contain the raw DER.
-	//
-	// Types that are assignable to Representation:
-	//
-	//	*Certificate_Der
-	//	*Certificate_WellKnown
-	Representation isCertificate_Representation `protobuf_oneof:"representation"`
+	// The SHA-256 digest of the deterministically serialized Attestation.
+	AttestationDigest []byte `protobuf:"bytes,1,opt,name=attestation_digest,json=attestationDigest,proto3" json:"attestation_digest,omitempty"`
+	// Every check performed, in order. The checks of a quote are repeated for
+	// each quote tried.
+	Checks []*VerificationCheck `protobuf:"bytes,2,rep,name=checks,proto3" json:"checks,omitempty"`
+	// Whether the Attestation was verified, and a MachineState returned.
+	Verified bool `protobuf:"varint,3,opt,name=verified,proto3" json:"verified,omitempty"`
+	// The PCR bank of the quote the MachineState was verified with.
+	VerifiedQuoteHash tpm.HashAlgo `protobuf:"varint,4,opt,name=verified_quote_hash,json=verifiedQuoteHash,proto3,enum=tpm.HashAlgo" json:"verified_quote_hash,omitempty"`
 }
 
-func (x *Certificate) Reset() {
-	*x = Certificate{}
+func (x *VerificationReport) Reset() {
+	*x = VerificationReport{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[7]
+		mi := &file_attest_proto_msgTypes[35]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Certificate) String() string {
+func (x *VerificationReport) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Certificate) ProtoMessage() {}
+func (*VerificationReport) ProtoMessage() {}
 
-func (x *Certificate) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[7]
+func (x *VerificationReport) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[35]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -803,77 +3421,80 @@ func (x *Certificate) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Certificate.ProtoReflect.Descriptor instead.
-func (*Certificate) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use VerificationReport.ProtoReflect.Descriptor instead.
+func (*VerificationReport) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{35}
 }
 
-func (m *Certificate) GetRepresentation() isCertificate_Representation {
-	if m != nil {
-		return m.Representation
+func (x *VerificationReport) GetAttestationDigest() []byte {
+	if x != nil {
+		return x.AttestationDigest
 	}
 	return nil
 }
 
-func (x *Certificate) GetDer() []byte {
-	if x, ok := x.GetRepresentation().(*Certificate_Der); ok {
-		return x.Der
+func (x *VerificationReport) GetChecks() []*VerificationCheck {
+	if x != nil {
+		return x.Checks
 	}
 	return nil
 }
 
-func (x *Certificate) GetWellKnown() WellKnownCertificate {
-	if x, ok := x.GetRepresentation().(*Certificate_WellKnown); ok {
-		return x.WellKnown
+func (x *VerificationReport) GetVerified() bool {
+	if x != nil {
+		return x.Verified
 	}
-	return WellKnownCertificate_UNKNOWN
-}
-
-type isCertificate_Representation interface {
-	isCertificate_Representation()
-}
-
-type Certificate_Der struct {
-	// DER representation of the certificate.
-	Der []byte `protobuf:"bytes,1,opt,name=der,proto3,oneof"`
+	return false
 }
 
-type Certificate_WellKnown struct {
-	WellKnown WellKnownCertificate `protobuf:"varint,2,opt,name=well_known,json=wellKnown,proto3,enum=attest.WellKnownCertificate,oneof"`
+func (x *VerificationReport) GetVerifiedQuoteHash() tpm.HashAlgo {
+	if x != nil {
+		return x.VerifiedQuoteHash
+	}
+	return tpm.HashAlgo(0)
 }
 
-func (*Certificate_Der) isCertificate_Representation() {}
-
-func (*Certificate_WellKnown) isCertificate_Representation() {}
-
-// A Secure Boot database containing lists of hashes and certificates,
-// as defined by section 32.4.1 Signature Database in the UEFI spec.
-type Database struct {
+// A policy dictating which values of PlatformState to allow
+type PlatformPolicy struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Certs  []*Certificate `protobuf:"bytes,1,rep,name=certs,proto3" json:"certs,omitempty"`
-	Hashes [][]byte       `protobuf:"bytes,2,rep,name=hashes,proto3" json:"hashes,omitempty"`
+	// If PlatformState.firmware contains a scrtm_version_id, it must appear
+	// in this list. For use with a GCE VM, minimum_gce_firmware_version is
+	// often a better alternative.
+	AllowedScrtmVersionIds [][]byte `protobuf:"bytes,1,rep,name=allowed_scrtm_version_ids,json=allowedScrtmVersionIds,proto3" json:"allowed_scrtm_version_ids,omitempty"`
+	// If PlatformState.firmware contains a minimum_gce_firmware_version, it must
+	// be greater than or equal to this value. Currently, the max version is 1.
+	MinimumGceFirmwareVersion uint32 `protobuf:"varint,2,opt,name=minimum_gce_firmware_version,json=minimumGceFirmwareVersion,proto3" json:"minimum_gce_firmware_version,omitempty"`
+	// The PlatformState's technology must be at least as secure as
+	// the specified minimum_technology (i.e. AMD_SEV_ES > AMD_SEV > NONE).
+	MinimumTechnology GCEConfidentialTechnology `protobuf:"varint,3,opt,name=minimum_technology,json=minimumTechnology,proto3,enum=attest.GCEConfidentialTechnology" json:"minimum_technology,omitempty"`
+	// If set, the PlatformState must have the instance_info of the AK
+	// certificate, matching this policy.
+	Instance *GCEInstancePolicy `protobuf:"bytes,4,opt,name=instance,proto3" json:"instance,omitempty"`
+	// PlatformState.tpm_firmware_version must be greater than or equal to this
+	// value, e.g. to require the vTPM security fixes of a given release.
+	MinimumTpmFirmwareVersion uint64 `protobuf:"varint,5,opt,name=minimum_tpm_firmware_version,json=minimumTpmFirmwareVersion,proto3" json:"minimum_tpm_firmware_version,omitempty"`
 }
 
-func (x *Database) Reset() {
-	*x = Database{}
+func (x *PlatformPolicy) Reset() {
+	*x = PlatformPolicy{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[8]
+		mi := &file_attest_proto_msgTypes[36]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Database) String() string {
+func (x *PlatformPolicy) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Database) ProtoMessage() {}
+func (*PlatformPolicy) ProtoMessage() {}
 
-func (x *Database) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[8]
+func (x *PlatformPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[36]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -884,59 +3505,80 @@ func (x *Database) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Database.ProtoReflect.Descriptor instead.
-func (*Database) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use PlatformPolicy.ProtoReflect.Descriptor instead.
+func (*PlatformPolicy) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{36}
 }
 
-func (x *Database) GetCerts() []*Certificate {
+func (x *PlatformPolicy) GetAllowedScrtmVersionIds() [][]byte {
 	if x != nil {
-		return x.Certs
+		return x.AllowedScrtmVersionIds
 	}
 	return nil
 }
 
-func (x *Database) GetHashes() [][]byte {
+func (x *PlatformPolicy) GetMinimumGceFirmwareVersion() uint32 {
 	if x != nil {
-		return x.Hashes
+		return x.MinimumGceFirmwareVersion
+	}
+	return 0
+}
+
+func (x *PlatformPolicy) GetMinimumTechnology() GCEConfidentialTechnology {
+	if x != nil {
+		return x.MinimumTechnology
+	}
+	return GCEConfidentialTechnology_NONE
+}
+
+func (x *PlatformPolicy) GetInstance() *GCEInstancePolicy {
+	if x != nil {
+		return x.Instance
 	}
 	return nil
 }
 
-// The Secure Boot state for this instance.
-type SecureBootState struct {
+func (x *PlatformPolicy) GetMinimumTpmFirmwareVersion() uint64 {
+	if x != nil {
+		return x.MinimumTpmFirmwareVersion
+	}
+	return 0
+}
+
+// A policy dictating which GCE instances to allow, based on the
+// GCEInstanceInfo of the AK certificate, so evidence of an instance cannot be
+// used as the evidence of another. Empty fields allow any value.
+type GCEInstancePolicy struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// Whether Secure Boot is enabled.
-	Enabled bool `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
-	// The Secure Boot signature (allowed) database.
-	Db *Database `protobuf:"bytes,2,opt,name=db,proto3" json:"db,omitempty"`
-	// The Secure Boot revoked signature (forbidden) database.
-	Dbx *Database `protobuf:"bytes,3,opt,name=dbx,proto3" json:"dbx,omitempty"`
-	// Authority events post-separator. Pre-separator authorities
-	// are currently not supported.
-	Authority *Database `protobuf:"bytes,4,opt,name=authority,proto3" json:"authority,omitempty"`
+	AllowedProjectIds     []string `protobuf:"bytes,1,rep,name=allowed_project_ids,json=allowedProjectIds,proto3" json:"allowed_project_ids,omitempty"`
+	AllowedProjectNumbers []uint64 `protobuf:"varint,2,rep,packed,name=allowed_project_numbers,json=allowedProjectNumbers,proto3" json:"allowed_project_numbers,omitempty"`
+	AllowedZones          []string `protobuf:"bytes,3,rep,name=allowed_zones,json=allowedZones,proto3" json:"allowed_zones,omitempty"`
+	AllowedInstanceIds    []uint64 `protobuf:"varint,4,rep,packed,name=allowed_instance_ids,json=allowedInstanceIds,proto3" json:"allowed_instance_ids,omitempty"`
+	AllowedInstanceNames  []string `protobuf:"bytes,5,rep,name=allowed_instance_names,json=allowedInstanceNames,proto3" json:"allowed_instance_names,omitempty"`
+	// The security_version must be greater than or equal to this value.
+	MinimumSecurityVersion uint64 `protobuf:"varint,6,opt,name=minimum_security_version,json=minimumSecurityVersion,proto3" json:"minimum_security_version,omitempty"`
 }
 
-func (x *SecureBootState) Reset() {
-	*x = SecureBootState{}
+func (x *GCEInstancePolicy) Reset() {
+	*x = GCEInstancePolicy{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[9]
+		mi := &file_attest_proto_msgTypes[37]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *SecureBootState) String() string {
+func (x *GCEInstancePolicy) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SecureBootState) ProtoMessage() {}
+func (*GCEInstancePolicy) ProtoMessage() {}
 
-func (x *SecureBootState) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[9]
+func (x *GCEInstancePolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[37]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -947,77 +3589,87 @@ func (x *SecureBootState) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SecureBootState.ProtoReflect.Descriptor instead.
-func (*SecureBootState) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use GCEInstancePolicy.ProtoReflect.Descriptor instead.
+func (*GCEInstancePolicy) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{37}
 }
 
-func (x *SecureBootState) GetEnabled() bool {
+func (x *GCEInstancePolicy) GetAllowedProjectIds() []string {
 	if x != nil {
-		return x.Enabled
+		return x.AllowedProjectIds
 	}
-	return false
+	return nil
 }
 
-func (x *SecureBootState) GetDb() *Database {
+func (x *GCEInstancePolicy) GetAllowedProjectNumbers() []uint64 {
 	if x != nil {
-		return x.Db
+		return x.AllowedProjectNumbers
 	}
 	return nil
 }
 
-func (x *SecureBootState) GetDbx() *Database {
+func (x *GCEInstancePolicy) GetAllowedZones() []string {
 	if x != nil {
-		return x.Dbx
+		return x.AllowedZones
 	}
 	return nil
 }
 
-func (x *SecureBootState) GetAuthority() *Database {
+func (x *GCEInstancePolicy) GetAllowedInstanceIds() []uint64 {
 	if x != nil {
-		return x.Authority
+		return x.AllowedInstanceIds
 	}
 	return nil
 }
 
-type ContainerState struct {
+func (x *GCEInstancePolicy) GetAllowedInstanceNames() []string {
+	if x != nil {
+		return x.AllowedInstanceNames
+	}
+	return nil
+}
+
+func (x *GCEInstancePolicy) GetMinimumSecurityVersion() uint64 {
+	if x != nil {
+		return x.MinimumSecurityVersion
+	}
+	return 0
+}
+
+// A policy dictating which values of TPMClockInfo to allow, to detect TPM
+// resets and state rollback between attestations.
+type ClockPolicy struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ImageReference string `protobuf:"bytes,1,opt,name=image_reference,json=imageReference,proto3" json:"image_reference,omitempty"`
-	// Digest of the registry's image manifest, which contains a list of the
-	// layers comprising the image.
-	ImageDigest   string        `protobuf:"bytes,2,opt,name=image_digest,json=imageDigest,proto3" json:"image_digest,omitempty"`
-	RestartPolicy RestartPolicy `protobuf:"varint,3,opt,name=restart_policy,json=restartPolicy,proto3,enum=attest.RestartPolicy" json:"restart_policy,omitempty"`
-	// Digest of the local image configuration object, containing config items
-	// such as local layer digests.
-	ImageId string            `protobuf:"bytes,4,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
-	Args    []string          `protobuf:"bytes,5,rep,name=args,proto3" json:"args,omitempty"`
-	EnvVars map[string]string `protobuf:"bytes,6,rep,name=env_vars,json=envVars,proto3" json:"env_vars,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	// Record operator input Env Vars and Args, should be subsets of the above
-	// Env Vars and Args.
-	OverriddenArgs    []string          `protobuf:"bytes,7,rep,name=overridden_args,json=overriddenArgs,proto3" json:"overridden_args,omitempty"`
-	OverriddenEnvVars map[string]string `protobuf:"bytes,8,rep,name=overridden_env_vars,json=overriddenEnvVars,proto3" json:"overridden_env_vars,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// If true, TPMClockInfo.safe must be true.
+	RequireSafe bool `protobuf:"varint,1,opt,name=require_safe,json=requireSafe,proto3" json:"require_safe,omitempty"`
+	// The TPMClockInfo of a previous attestation of the same TPM, usually the
+	// one recorded at enrollment. If set, the reset_count must be the same,
+	// and the clock and restart_count must not be lower. The counts are only
+	// known for AKs in the endorsement hierarchy, so only the clock is checked
+	// for the other AKs.
+	Reference *TPMClockInfo `protobuf:"bytes,2,opt,name=reference,proto3" json:"reference,omitempty"`
 }
 
-func (x *ContainerState) Reset() {
-	*x = ContainerState{}
+func (x *ClockPolicy) Reset() {
+	*x = ClockPolicy{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[10]
+		mi := &file_attest_proto_msgTypes[38]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ContainerState) String() string {
+func (x *ClockPolicy) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ContainerState) ProtoMessage() {}
+func (*ClockPolicy) ProtoMessage() {}
 
-func (x *ContainerState) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[10]
+func (x *ClockPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[38]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1028,94 +3680,145 @@ func (x *ContainerState) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ContainerState.ProtoReflect.Descriptor instead.
-func (*ContainerState) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use ClockPolicy.ProtoReflect.Descriptor instead.
+func (*ClockPolicy) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{38}
 }
 
-func (x *ContainerState) GetImageReference() string {
+func (x *ClockPolicy) GetRequireSafe() bool {
 	if x != nil {
-		return x.ImageReference
+		return x.RequireSafe
 	}
-	return ""
+	return false
 }
 
-func (x *ContainerState) GetImageDigest() string {
+func (x *ClockPolicy) GetReference() *TPMClockInfo {
 	if x != nil {
-		return x.ImageDigest
+		return x.Reference
 	}
-	return ""
+	return nil
 }
 
-func (x *ContainerState) GetRestartPolicy() RestartPolicy {
-	if x != nil {
-		return x.RestartPolicy
+// A policy dictating which LinuxKernelStates to allow, based on the parsed
+// kernel command line.
+type KernelPolicy struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// If non-empty, the init= parameter must be one of these.
+	AllowedInit []string `protobuf:"bytes,1,rep,name=allowed_init,json=allowedInit,proto3" json:"allowed_init,omitempty"`
+	// The lockdown mode must be at least this restrictive.
+	MinimumLockdown KernelLockdown `protobuf:"varint,2,opt,name=minimum_lockdown,json=minimumLockdown,proto3,enum=attest.KernelLockdown" json:"minimum_lockdown,omitempty"`
+	// Each of these ima_policy= values must be set.
+	RequiredImaPolicies []string `protobuf:"bytes,3,rep,name=required_ima_policies,json=requiredImaPolicies,proto3" json:"required_ima_policies,omitempty"`
+	// If non-empty, a dm-verity target must be set up, with one of these
+	// hex-encoded root digests.
+	AllowedDmVerityRootHexdigests []string `protobuf:"bytes,4,rep,name=allowed_dm_verity_root_hexdigests,json=allowedDmVerityRootHexdigests,proto3" json:"allowed_dm_verity_root_hexdigests,omitempty"`
+	// If true, module.sig_enforce=1 must be set.
+	RequireModuleSigEnforce bool `protobuf:"varint,5,opt,name=require_module_sig_enforce,json=requireModuleSigEnforce,proto3" json:"require_module_sig_enforce,omitempty"`
+}
+
+func (x *KernelPolicy) Reset() {
+	*x = KernelPolicy{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[39]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return RestartPolicy_Always
 }
 
-func (x *ContainerState) GetImageId() string {
+func (x *KernelPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KernelPolicy) ProtoMessage() {}
+
+func (x *KernelPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[39]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KernelPolicy.ProtoReflect.Descriptor instead.
+func (*KernelPolicy) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *KernelPolicy) GetAllowedInit() []string {
 	if x != nil {
-		return x.ImageId
+		return x.AllowedInit
 	}
-	return ""
+	return nil
 }
 
-func (x *ContainerState) GetArgs() []string {
+func (x *KernelPolicy) GetMinimumLockdown() KernelLockdown {
 	if x != nil {
-		return x.Args
+		return x.MinimumLockdown
 	}
-	return nil
+	return KernelLockdown_LOCKDOWN_NONE
 }
 
-func (x *ContainerState) GetEnvVars() map[string]string {
+func (x *KernelPolicy) GetRequiredImaPolicies() []string {
 	if x != nil {
-		return x.EnvVars
+		return x.RequiredImaPolicies
 	}
 	return nil
 }
 
-func (x *ContainerState) GetOverriddenArgs() []string {
+func (x *KernelPolicy) GetAllowedDmVerityRootHexdigests() []string {
 	if x != nil {
-		return x.OverriddenArgs
+		return x.AllowedDmVerityRootHexdigests
 	}
 	return nil
 }
 
-func (x *ContainerState) GetOverriddenEnvVars() map[string]string {
+func (x *KernelPolicy) GetRequireModuleSigEnforce() bool {
 	if x != nil {
-		return x.OverriddenEnvVars
+		return x.RequireModuleSigEnforce
 	}
-	return nil
+	return false
 }
 
-type SemanticVersion struct {
+// A rule matching IMA measurements by file name and digest. A measurement
+// matches if it matches both the path_glob and the file_digests.
+type ImaRule struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Major uint32 `protobuf:"varint,1,opt,name=major,proto3" json:"major,omitempty"`
-	Minor uint32 `protobuf:"varint,2,opt,name=minor,proto3" json:"minor,omitempty"`
-	Patch uint32 `protobuf:"varint,3,opt,name=patch,proto3" json:"patch,omitempty"`
+	// A path.Match pattern of the file name, e.g. "/usr/bin/*". A pattern ending
+	// with "/**" matches all the files below the directory. Any file name
+	// matches if empty.
+	PathGlob string `protobuf:"bytes,1,opt,name=path_glob,json=pathGlob,proto3" json:"path_glob,omitempty"`
+	// The allowed file digests, as in the measurement list, e.g. "sha256:"
+	// followed by the hex digest. Any digest matches if empty.
+	FileDigests []string `protobuf:"bytes,2,rep,name=file_digests,json=fileDigests,proto3" json:"file_digests,omitempty"`
 }
 
-func (x *SemanticVersion) Reset() {
-	*x = SemanticVersion{}
+func (x *ImaRule) Reset() {
+	*x = ImaRule{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[11]
+		mi := &file_attest_proto_msgTypes[40]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *SemanticVersion) String() string {
+func (x *ImaRule) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SemanticVersion) ProtoMessage() {}
+func (*ImaRule) ProtoMessage() {}
 
-func (x *SemanticVersion) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[11]
+func (x *ImaRule) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[40]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1126,59 +3829,57 @@ func (x *SemanticVersion) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SemanticVersion.ProtoReflect.Descriptor instead.
-func (*SemanticVersion) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{11}
-}
-
-func (x *SemanticVersion) GetMajor() uint32 {
-	if x != nil {
-		return x.Major
-	}
-	return 0
+// Deprecated: Use ImaRule.ProtoReflect.Descriptor instead.
+func (*ImaRule) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{40}
 }
 
-func (x *SemanticVersion) GetMinor() uint32 {
+func (x *ImaRule) GetPathGlob() string {
 	if x != nil {
-		return x.Minor
+		return x.PathGlob
 	}
-	return 0
+	return ""
 }
 
-func (x *SemanticVersion) GetPatch() uint32 {
+func (x *ImaRule) GetFileDigests() []string {
 	if x != nil {
-		return x.Patch
+		return x.FileDigests
 	}
-	return 0
+	return nil
 }
 
-type AttestedCosState struct {
+// A policy dictating which files measured by IMA to allow. The boot_aggregate
+// measurement, the first of the log, is not checked, as it covers the boot
+// PCRs. The unverified measurements always violate the policy, as their file
+// names and digests cannot be trusted.
+type ImaPolicy struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Container       *ContainerState  `protobuf:"bytes,1,opt,name=container,proto3" json:"container,omitempty"`
-	CosVersion      *SemanticVersion `protobuf:"bytes,2,opt,name=cos_version,json=cosVersion,proto3" json:"cos_version,omitempty"`
-	LauncherVersion *SemanticVersion `protobuf:"bytes,3,opt,name=launcher_version,json=launcherVersion,proto3" json:"launcher_version,omitempty"`
+	// If non-empty, each measurement must match one of these rules.
+	Allow []*ImaRule `protobuf:"bytes,1,rep,name=allow,proto3" json:"allow,omitempty"`
+	// No measurement can match one of these rules.
+	Deny []*ImaRule `protobuf:"bytes,2,rep,name=deny,proto3" json:"deny,omitempty"`
 }
 
-func (x *AttestedCosState) Reset() {
-	*x = AttestedCosState{}
+func (x *ImaPolicy) Reset() {
+	*x = ImaPolicy{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[12]
+		mi := &file_attest_proto_msgTypes[41]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *AttestedCosState) String() string {
+func (x *ImaPolicy) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AttestedCosState) ProtoMessage() {}
+func (*ImaPolicy) ProtoMessage() {}
 
-func (x *AttestedCosState) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[12]
+func (x *ImaPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[41]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1189,69 +3890,57 @@ func (x *AttestedCosState) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AttestedCosState.ProtoReflect.Descriptor instead.
-func (*AttestedCosState) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{12}
-}
-
-func (x *AttestedCosState) GetContainer() *ContainerState {
-	if x != nil {
-		return x.Container
-	}
-	return nil
+// Deprecated: Use ImaPolicy.ProtoReflect.Descriptor instead.
+func (*ImaPolicy) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{41}
 }
 
-func (x *AttestedCosState) GetCosVersion() *SemanticVersion {
+func (x *ImaPolicy) GetAllow() []*ImaRule {
 	if x != nil {
-		return x.CosVersion
+		return x.Allow
 	}
 	return nil
 }
 
-func (x *AttestedCosState) GetLauncherVersion() *SemanticVersion {
+func (x *ImaPolicy) GetDeny() []*ImaRule {
 	if x != nil {
-		return x.LauncherVersion
+		return x.Deny
 	}
 	return nil
 }
 
-// The verified state of a booted machine, obtained from an Attestation
-type MachineState struct {
+// A measurement violating an ImaPolicy.
+type ImaViolation struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Platform   *PlatformState   `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`
-	SecureBoot *SecureBootState `protobuf:"bytes,2,opt,name=secure_boot,json=secureBoot,proto3" json:"secure_boot,omitempty"`
-	// The complete parsed TCG Event Log, including those events used to
-	// create the PlatformState.
-	RawEvents []*Event `protobuf:"bytes,3,rep,name=raw_events,json=rawEvents,proto3" json:"raw_events,omitempty"`
-	// The hash algorithm used when verifying the Attestation. This indicates:
-	//   - which PCR bank was used for for quote validation and event log replay
-	//   - the hash algorithm used to calculate event digests
-	Hash        tpm.HashAlgo      `protobuf:"varint,4,opt,name=hash,proto3,enum=tpm.HashAlgo" json:"hash,omitempty"`
-	Grub        *GrubState        `protobuf:"bytes,5,opt,name=grub,proto3" json:"grub,omitempty"`
-	LinuxKernel *LinuxKernelState `protobuf:"bytes,6,opt,name=linux_kernel,json=linuxKernel,proto3" json:"linux_kernel,omitempty"`
-	Cos         *AttestedCosState `protobuf:"bytes,7,opt,name=cos,proto3" json:"cos,omitempty"`
+	// The index of the measurement in ImaState.measurements, or in
+	// ImaState.unverified_measurements if unverified is set.
+	Index       uint32          `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Measurement *ImaMeasurement `protobuf:"bytes,2,opt,name=measurement,proto3" json:"measurement,omitempty"`
+	// Why the measurement violates the policy.
+	Reason     string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	Unverified bool   `protobuf:"varint,4,opt,name=unverified,proto3" json:"unverified,omitempty"`
 }
 
-func (x *MachineState) Reset() {
-	*x = MachineState{}
+func (x *ImaViolation) Reset() {
+	*x = ImaViolation{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[13]
+		mi := &file_attest_proto_msgTypes[42]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *MachineState) String() string {
+func (x *ImaViolation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MachineState) ProtoMessage() {}
+func (*ImaViolation) ProtoMessage() {}
 
-func (x *MachineState) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[13]
+func (x *ImaViolation) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[42]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1262,95 +3951,75 @@ func (x *MachineState) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MachineState.ProtoReflect.Descriptor instead.
-func (*MachineState) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{13}
-}
-
-func (x *MachineState) GetPlatform() *PlatformState {
-	if x != nil {
-		return x.Platform
-	}
-	return nil
-}
-
-func (x *MachineState) GetSecureBoot() *SecureBootState {
-	if x != nil {
-		return x.SecureBoot
-	}
-	return nil
-}
-
-func (x *MachineState) GetRawEvents() []*Event {
-	if x != nil {
-		return x.RawEvents
-	}
-	return nil
+// Deprecated: Use ImaViolation.ProtoReflect.Descriptor instead.
+func (*ImaViolation) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{42}
 }
 
-func (x *MachineState) GetHash() tpm.HashAlgo {
+func (x *ImaViolation) GetIndex() uint32 {
 	if x != nil {
-		return x.Hash
+		return x.Index
 	}
-	return tpm.HashAlgo(0)
+	return 0
 }
 
-func (x *MachineState) GetGrub() *GrubState {
+func (x *ImaViolation) GetMeasurement() *ImaMeasurement {
 	if x != nil {
-		return x.Grub
+		return x.Measurement
 	}
 	return nil
 }
 
-func (x *MachineState) GetLinuxKernel() *LinuxKernelState {
+func (x *ImaViolation) GetReason() string {
 	if x != nil {
-		return x.LinuxKernel
+		return x.Reason
 	}
-	return nil
+	return ""
 }
 
-func (x *MachineState) GetCos() *AttestedCosState {
+func (x *ImaViolation) GetUnverified() bool {
 	if x != nil {
-		return x.Cos
+		return x.Unverified
 	}
-	return nil
+	return false
 }
 
-// A policy dictating which values of PlatformState to allow
-type PlatformPolicy struct {
+// A policy dictating which AttestedCosStates to allow.
+type CosPolicy struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// If PlatformState.firmware contains a scrtm_version_id, it must appear
-	// in this list. For use with a GCE VM, minimum_gce_firmware_version is
-	// often a better alternative.
-	AllowedScrtmVersionIds [][]byte `protobuf:"bytes,1,rep,name=allowed_scrtm_version_ids,json=allowedScrtmVersionIds,proto3" json:"allowed_scrtm_version_ids,omitempty"`
-	// If PlatformState.firmware contains a minimum_gce_firmware_version, it must
-	// be greater than or equal to this value. Currently, the max version is 1.
-	MinimumGceFirmwareVersion uint32 `protobuf:"varint,2,opt,name=minimum_gce_firmware_version,json=minimumGceFirmwareVersion,proto3" json:"minimum_gce_firmware_version,omitempty"`
-	// The PlatformState's technology must be at least as secure as
-	// the specified minimum_technology (i.e. AMD_SEV_ES > AMD_SEV > NONE).
-	MinimumTechnology GCEConfidentialTechnology `protobuf:"varint,3,opt,name=minimum_technology,json=minimumTechnology,proto3,enum=attest.GCEConfidentialTechnology" json:"minimum_technology,omitempty"`
-}
-
-func (x *PlatformPolicy) Reset() {
-	*x = PlatformPolicy{}
+	// If set, the launcher_version must be greater than or equal to this
+	// version, e.g. the first version with a security fix.
+	MinimumLauncherVersion *SemanticVersion `protobuf:"bytes,1,opt,name=minimum_launcher_version,json=minimumLauncherVersion,proto3" json:"minimum_launcher_version,omitempty"`
+	// If non-empty, the launcher_binary_digest must be one of these.
+	AllowedLauncherBinaryDigests []string `protobuf:"bytes,2,rep,name=allowed_launcher_binary_digests,json=allowedLauncherBinaryDigests,proto3" json:"allowed_launcher_binary_digests,omitempty"`
+	// If true, the kernel log must be measured, and its taint flags must only
+	// contain the flags of allowed_kernel_taint.
+	RequireUntaintedKernel bool `protobuf:"varint,3,opt,name=require_untainted_kernel,json=requireUntaintedKernel,proto3" json:"require_untainted_kernel,omitempty"`
+	// The taint flags (see KernelLogState.taint) tolerated on the kernel when
+	// require_untainted_kernel is set. Any other flag fails the policy.
+	AllowedKernelTaint uint64 `protobuf:"varint,4,opt,name=allowed_kernel_taint,json=allowedKernelTaint,proto3" json:"allowed_kernel_taint,omitempty"`
+}
+
+func (x *CosPolicy) Reset() {
+	*x = CosPolicy{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[14]
+		mi := &file_attest_proto_msgTypes[43]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *PlatformPolicy) String() string {
+func (x *CosPolicy) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PlatformPolicy) ProtoMessage() {}
+func (*CosPolicy) ProtoMessage() {}
 
-func (x *PlatformPolicy) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[14]
+func (x *CosPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[43]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1361,30 +4030,37 @@ func (x *PlatformPolicy) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PlatformPolicy.ProtoReflect.Descriptor instead.
-func (*PlatformPolicy) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use CosPolicy.ProtoReflect.Descriptor instead.
+func (*CosPolicy) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{43}
 }
 
-func (x *PlatformPolicy) GetAllowedScrtmVersionIds() [][]byte {
+func (x *CosPolicy) GetMinimumLauncherVersion() *SemanticVersion {
 	if x != nil {
-		return x.AllowedScrtmVersionIds
+		return x.MinimumLauncherVersion
 	}
 	return nil
 }
 
-func (x *PlatformPolicy) GetMinimumGceFirmwareVersion() uint32 {
+func (x *CosPolicy) GetAllowedLauncherBinaryDigests() []string {
 	if x != nil {
-		return x.MinimumGceFirmwareVersion
+		return x.AllowedLauncherBinaryDigests
 	}
-	return 0
+	return nil
 }
 
-func (x *PlatformPolicy) GetMinimumTechnology() GCEConfidentialTechnology {
+func (x *CosPolicy) GetRequireUntaintedKernel() bool {
 	if x != nil {
-		return x.MinimumTechnology
+		return x.RequireUntaintedKernel
 	}
-	return GCEConfidentialTechnology_NONE
+	return false
+}
+
+func (x *CosPolicy) GetAllowedKernelTaint() uint64 {
+	if x != nil {
+		return x.AllowedKernelTaint
+	}
+	return 0
 }
 
 // A policy dictating which type of MachineStates to allow
@@ -1394,12 +4070,16 @@ type Policy struct {
 	unknownFields protoimpl.UnknownFields
 
 	Platform *PlatformPolicy `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`
+	Clock    *ClockPolicy    `protobuf:"bytes,3,opt,name=clock,proto3" json:"clock,omitempty"`
+	Kernel   *KernelPolicy   `protobuf:"bytes,4,opt,name=kernel,proto3" json:"kernel,omitempty"`
+	Ima      *ImaPolicy      `protobuf:"bytes,5,opt,name=ima,proto3" json:"ima,omitempty"`
+	Cos      *CosPolicy      `protobuf:"bytes,6,opt,name=cos,proto3" json:"cos,omitempty"`
 }
 
 func (x *Policy) Reset() {
 	*x = Policy{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[15]
+		mi := &file_attest_proto_msgTypes[44]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1412,7 +4092,7 @@ func (x *Policy) String() string {
 func (*Policy) ProtoMessage() {}
 
 func (x *Policy) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[15]
+	mi := &file_attest_proto_msgTypes[44]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1425,7 +4105,7 @@ func (x *Policy) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Policy.ProtoReflect.Descriptor instead.
 func (*Policy) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{15}
+	return file_attest_proto_rawDescGZIP(), []int{44}
 }
 
 func (x *Policy) GetPlatform() *PlatformPolicy {
@@ -1435,13 +4115,41 @@ func (x *Policy) GetPlatform() *PlatformPolicy {
 	return nil
 }
 
+func (x *Policy) GetClock() *ClockPolicy {
+	if x != nil {
+		return x.Clock
+	}
+	return nil
+}
+
+func (x *Policy) GetKernel() *KernelPolicy {
+	if x != nil {
+		return x.Kernel
+	}
+	return nil
+}
+
+func (x *Policy) GetIma() *ImaPolicy {
+	if x != nil {
+		return x.Ima
+	}
+	return nil
+}
+
+func (x *Policy) GetCos() *CosPolicy {
+	if x != nil {
+		return x.Cos
+	}
+	return nil
+}
+
 var File_attest_proto protoreflect.FileDescriptor
 
 var file_attest_proto_rawDesc = []byte{
 	0x0a, 0x0c, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06,
 	0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x73, 0x65,
 	0x76, 0x73, 0x6e, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x09, 0x74, 0x70, 0x6d, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xb1, 0x01, 0x0a, 0x0f, 0x47, 0x43, 0x45, 0x49, 0x6e, 0x73,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xdc, 0x01, 0x0a, 0x0f, 0x47, 0x43, 0x45, 0x49, 0x6e, 0x73,
 	0x74, 0x61, 0x6e, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x7a, 0x6f, 0x6e,
 	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x12, 0x1d, 0x0a,
 	0x0a, 0x70, 0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
@@ -1452,201 +4160,645 @@ var file_attest_proto_rawDesc = []byte{
 	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x69, 0x6e, 0x73, 0x74,
 	0x61, 0x6e, 0x63, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x6e, 0x73, 0x74,
 	0x61, 0x6e, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x69,
-	0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x49, 0x64, 0x22, 0xf5, 0x02, 0x0a, 0x0b, 0x41, 0x74,
-	0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x15, 0x0a, 0x06, 0x61, 0x6b, 0x5f,
-	0x70, 0x75, 0x62, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x61, 0x6b, 0x50, 0x75, 0x62,
-	0x12, 0x22, 0x0a, 0x06, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
-	0x32, 0x0a, 0x2e, 0x74, 0x70, 0x6d, 0x2e, 0x51, 0x75, 0x6f, 0x74, 0x65, 0x52, 0x06, 0x71, 0x75,
-	0x6f, 0x74, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x6c, 0x6f,
-	0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x4c, 0x6f,
-	0x67, 0x12, 0x3c, 0x0a, 0x0d, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x69, 0x6e,
-	0x66, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73,
-	0x74, 0x2e, 0x47, 0x43, 0x45, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x49, 0x6e, 0x66,
-	0x6f, 0x52, 0x0c, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12,
-	0x2e, 0x0a, 0x13, 0x63, 0x61, 0x6e, 0x6f, 0x6e, 0x69, 0x63, 0x61, 0x6c, 0x5f, 0x65, 0x76, 0x65,
-	0x6e, 0x74, 0x5f, 0x6c, 0x6f, 0x67, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x11, 0x63, 0x61,
-	0x6e, 0x6f, 0x6e, 0x69, 0x63, 0x61, 0x6c, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x4c, 0x6f, 0x67, 0x12,
-	0x17, 0x0a, 0x07, 0x61, 0x6b, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c,
-	0x52, 0x06, 0x61, 0x6b, 0x43, 0x65, 0x72, 0x74, 0x12, 0x2d, 0x0a, 0x12, 0x69, 0x6e, 0x74, 0x65,
-	0x72, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x65, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x73, 0x18, 0x07,
-	0x20, 0x03, 0x28, 0x0c, 0x52, 0x11, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6d, 0x65, 0x64, 0x69, 0x61,
-	0x74, 0x65, 0x43, 0x65, 0x72, 0x74, 0x73, 0x12, 0x45, 0x0a, 0x13, 0x73, 0x65, 0x76, 0x5f, 0x73,
-	0x6e, 0x70, 0x5f, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x08,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x73, 0x65, 0x76, 0x73, 0x6e, 0x70, 0x2e, 0x41, 0x74,
-	0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x11, 0x73, 0x65, 0x76,
-	0x53, 0x6e, 0x70, 0x41, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x11,
-	0x0a, 0x0f, 0x74, 0x65, 0x65, 0x5f, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x22, 0xeb, 0x01, 0x0a, 0x0d, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x53, 0x74,
-	0x61, 0x74, 0x65, 0x12, 0x2a, 0x0a, 0x10, 0x73, 0x63, 0x72, 0x74, 0x6d, 0x5f, 0x76, 0x65, 0x72,
-	0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52,
-	0x0e, 0x73, 0x63, 0x72, 0x74, 0x6d, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12,
-	0x21, 0x0a, 0x0b, 0x67, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0d, 0x48, 0x00, 0x52, 0x0a, 0x67, 0x63, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69,
-	0x6f, 0x6e, 0x12, 0x41, 0x0a, 0x0a, 0x74, 0x65, 0x63, 0x68, 0x6e, 0x6f, 0x6c, 0x6f, 0x67, 0x79,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x21, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e,
-	0x47, 0x43, 0x45, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x54,
-	0x65, 0x63, 0x68, 0x6e, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x52, 0x0a, 0x74, 0x65, 0x63, 0x68, 0x6e,
-	0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x12, 0x3c, 0x0a, 0x0d, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63,
-	0x65, 0x5f, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x61,
-	0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x47, 0x43, 0x45, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63,
-	0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x0c, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x49,
-	0x6e, 0x66, 0x6f, 0x42, 0x0a, 0x0a, 0x08, 0x66, 0x69, 0x72, 0x6d, 0x77, 0x61, 0x72, 0x65, 0x22,
-	0x51, 0x0a, 0x08, 0x47, 0x72, 0x75, 0x62, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x64,
-	0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x64, 0x69, 0x67,
-	0x65, 0x73, 0x74, 0x12, 0x2d, 0x0a, 0x12, 0x75, 0x6e, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64,
-	0x5f, 0x66, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
-	0x11, 0x75, 0x6e, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x6e, 0x61,
-	0x6d, 0x65, 0x22, 0x4f, 0x0a, 0x09, 0x47, 0x72, 0x75, 0x62, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12,
-	0x26, 0x0a, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10,
-	0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x47, 0x72, 0x75, 0x62, 0x46, 0x69, 0x6c, 0x65,
-	0x52, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
-	0x6e, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
-	0x6e, 0x64, 0x73, 0x22, 0x35, 0x0a, 0x10, 0x4c, 0x69, 0x6e, 0x75, 0x78, 0x4b, 0x65, 0x72, 0x6e,
-	0x65, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
-	0x6e, 0x64, 0x5f, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63,
-	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x4c, 0x69, 0x6e, 0x65, 0x22, 0xa0, 0x01, 0x0a, 0x05, 0x45,
-	0x76, 0x65, 0x6e, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x63, 0x72, 0x5f, 0x69, 0x6e, 0x64, 0x65,
-	0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x70, 0x63, 0x72, 0x49, 0x6e, 0x64, 0x65,
-	0x78, 0x12, 0x25, 0x0a, 0x0e, 0x75, 0x6e, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x74,
-	0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x75, 0x6e, 0x74, 0x72, 0x75,
-	0x73, 0x74, 0x65, 0x64, 0x54, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x16, 0x0a, 0x06,
-	0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x64, 0x69,
-	0x67, 0x65, 0x73, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x5f, 0x76,
-	0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x64,
-	0x69, 0x67, 0x65, 0x73, 0x74, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x22, 0x72, 0x0a,
-	0x0b, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x03,
-	0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x03, 0x64, 0x65, 0x72,
-	0x12, 0x3d, 0x0a, 0x0a, 0x77, 0x65, 0x6c, 0x6c, 0x5f, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x57, 0x65,
-	0x6c, 0x6c, 0x4b, 0x6e, 0x6f, 0x77, 0x6e, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61,
-	0x74, 0x65, 0x48, 0x00, 0x52, 0x09, 0x77, 0x65, 0x6c, 0x6c, 0x4b, 0x6e, 0x6f, 0x77, 0x6e, 0x42,
-	0x10, 0x0a, 0x0e, 0x72, 0x65, 0x70, 0x72, 0x65, 0x73, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x22, 0x4d, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x12, 0x29, 0x0a,
-	0x05, 0x63, 0x65, 0x72, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61,
-	0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74,
-	0x65, 0x52, 0x05, 0x63, 0x65, 0x72, 0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x61, 0x73, 0x68,
-	0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73,
-	0x22, 0xa1, 0x01, 0x0a, 0x0f, 0x53, 0x65, 0x63, 0x75, 0x72, 0x65, 0x42, 0x6f, 0x6f, 0x74, 0x53,
-	0x74, 0x61, 0x74, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x12, 0x20,
-	0x0a, 0x02, 0x64, 0x62, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x74, 0x74,
-	0x65, 0x73, 0x74, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x52, 0x02, 0x64, 0x62,
-	0x12, 0x22, 0x0a, 0x03, 0x64, 0x62, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e,
-	0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x52,
-	0x03, 0x64, 0x62, 0x78, 0x12, 0x2e, 0x0a, 0x09, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x74,
-	0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74,
-	0x2e, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x52, 0x09, 0x61, 0x75, 0x74, 0x68, 0x6f,
-	0x72, 0x69, 0x74, 0x79, 0x22, 0x93, 0x04, 0x0a, 0x0e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e,
-	0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x6d, 0x61, 0x67, 0x65,
-	0x5f, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x0e, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65,
-	0x12, 0x21, 0x0a, 0x0c, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x44, 0x69, 0x67,
-	0x65, 0x73, 0x74, 0x12, 0x3c, 0x0a, 0x0e, 0x72, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x70,
-	0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x61, 0x74,
-	0x74, 0x65, 0x73, 0x74, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x50, 0x6f, 0x6c, 0x69,
-	0x63, 0x79, 0x52, 0x0d, 0x72, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63,
-	0x79, 0x12, 0x19, 0x0a, 0x08, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x07, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04,
-	0x61, 0x72, 0x67, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x61, 0x72, 0x67, 0x73,
-	0x12, 0x3e, 0x0a, 0x08, 0x65, 0x6e, 0x76, 0x5f, 0x76, 0x61, 0x72, 0x73, 0x18, 0x06, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x23, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x6f, 0x6e, 0x74,
-	0x61, 0x69, 0x6e, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x45, 0x6e, 0x76, 0x56, 0x61,
-	0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x65, 0x6e, 0x76, 0x56, 0x61, 0x72, 0x73,
-	0x12, 0x27, 0x0a, 0x0f, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x5f, 0x61,
-	0x72, 0x67, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x6f, 0x76, 0x65, 0x72, 0x72,
-	0x69, 0x64, 0x64, 0x65, 0x6e, 0x41, 0x72, 0x67, 0x73, 0x12, 0x5d, 0x0a, 0x13, 0x6f, 0x76, 0x65,
-	0x72, 0x72, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x5f, 0x65, 0x6e, 0x76, 0x5f, 0x76, 0x61, 0x72, 0x73,
-	0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2d, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e,
-	0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x4f,
-	0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x45, 0x6e, 0x76, 0x56, 0x61, 0x72, 0x73,
-	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x11, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x64, 0x65,
-	0x6e, 0x45, 0x6e, 0x76, 0x56, 0x61, 0x72, 0x73, 0x1a, 0x3a, 0x0a, 0x0c, 0x45, 0x6e, 0x76, 0x56,
-	0x61, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
-	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
-	0x3a, 0x02, 0x38, 0x01, 0x1a, 0x44, 0x0a, 0x16, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x64,
-	0x65, 0x6e, 0x45, 0x6e, 0x76, 0x56, 0x61, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10,
-	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
-	0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x53, 0x0a, 0x0f, 0x53, 0x65,
-	0x6d, 0x61, 0x6e, 0x74, 0x69, 0x63, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a,
-	0x05, 0x6d, 0x61, 0x6a, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6d, 0x61,
-	0x6a, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x69, 0x6e, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x0d, 0x52, 0x05, 0x6d, 0x69, 0x6e, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x61, 0x74,
-	0x63, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x70, 0x61, 0x74, 0x63, 0x68, 0x22,
-	0xc6, 0x01, 0x0a, 0x10, 0x41, 0x74, 0x74, 0x65, 0x73, 0x74, 0x65, 0x64, 0x43, 0x6f, 0x73, 0x53,
-	0x74, 0x61, 0x74, 0x65, 0x12, 0x34, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65,
-	0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74,
-	0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52,
-	0x09, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x12, 0x38, 0x0a, 0x0b, 0x63, 0x6f,
-	0x73, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x17, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x65, 0x6d, 0x61, 0x6e, 0x74, 0x69,
-	0x63, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x63, 0x6f, 0x73, 0x56, 0x65, 0x72,
-	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x42, 0x0a, 0x10, 0x6c, 0x61, 0x75, 0x6e, 0x63, 0x68, 0x65, 0x72,
-	0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17,
-	0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x65, 0x6d, 0x61, 0x6e, 0x74, 0x69, 0x63,
-	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0f, 0x6c, 0x61, 0x75, 0x6e, 0x63, 0x68, 0x65,
-	0x72, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0xdc, 0x02, 0x0a, 0x0c, 0x4d, 0x61, 0x63,
-	0x68, 0x69, 0x6e, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x31, 0x0a, 0x08, 0x70, 0x6c, 0x61,
-	0x74, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x74,
-	0x74, 0x65, 0x73, 0x74, 0x2e, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x53, 0x74, 0x61,
-	0x74, 0x65, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x12, 0x38, 0x0a, 0x0b,
-	0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x5f, 0x62, 0x6f, 0x6f, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x17, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x65, 0x63, 0x75, 0x72,
-	0x65, 0x42, 0x6f, 0x6f, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x0a, 0x73, 0x65, 0x63, 0x75,
-	0x72, 0x65, 0x42, 0x6f, 0x6f, 0x74, 0x12, 0x2c, 0x0a, 0x0a, 0x72, 0x61, 0x77, 0x5f, 0x65, 0x76,
-	0x65, 0x6e, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x61, 0x74, 0x74,
-	0x65, 0x73, 0x74, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x09, 0x72, 0x61, 0x77, 0x45, 0x76,
-	0x65, 0x6e, 0x74, 0x73, 0x12, 0x21, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x04, 0x20, 0x01,
+	0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x49, 0x64, 0x12, 0x29, 0x0a, 0x10, 0x73, 0x65, 0x63,
+	0x75, 0x72, 0x69, 0x74, 0x79, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0f, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x56, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x22, 0xaf, 0x04, 0x0a, 0x0b, 0x41, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x15, 0x0a, 0x06, 0x61, 0x6b, 0x5f, 0x70, 0x75, 0x62, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x61, 0x6b, 0x50, 0x75, 0x62, 0x12, 0x22, 0x0a, 0x06, 0x71,
+	0x75, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x74, 0x70,
+	0x6d, 0x2e, 0x51, 0x75, 0x6f, 0x74, 0x65, 0x52, 0x06, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x73, 0x12,
+	0x1b, 0x0a, 0x09, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x6c, 0x6f, 0x67, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x08, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x4c, 0x6f, 0x67, 0x12, 0x3c, 0x0a, 0x0d,
+	0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x47, 0x43, 0x45,
+	0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x0c, 0x69, 0x6e,
+	0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x2e, 0x0a, 0x13, 0x63, 0x61,
+	0x6e, 0x6f, 0x6e, 0x69, 0x63, 0x61, 0x6c, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x6c, 0x6f,
+	0x67, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x11, 0x63, 0x61, 0x6e, 0x6f, 0x6e, 0x69, 0x63,
+	0x61, 0x6c, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x4c, 0x6f, 0x67, 0x12, 0x17, 0x0a, 0x07, 0x61, 0x6b,
+	0x5f, 0x63, 0x65, 0x72, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x61, 0x6b, 0x43,
+	0x65, 0x72, 0x74, 0x12, 0x2d, 0x0a, 0x12, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6d, 0x65, 0x64, 0x69,
+	0x61, 0x74, 0x65, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0c, 0x52,
+	0x11, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x65, 0x43, 0x65, 0x72,
+	0x74, 0x73, 0x12, 0x45, 0x0a, 0x13, 0x73, 0x65, 0x76, 0x5f, 0x73, 0x6e, 0x70, 0x5f, 0x61, 0x74,
+	0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x13, 0x2e, 0x73, 0x65, 0x76, 0x73, 0x6e, 0x70, 0x2e, 0x41, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x11, 0x73, 0x65, 0x76, 0x53, 0x6e, 0x70, 0x41, 0x74,
+	0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3b, 0x0a, 0x0a, 0x73, 0x65, 0x6c,
+	0x66, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e,
+	0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x41, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x53, 0x65, 0x6c, 0x66, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x09, 0x73, 0x65, 0x6c,
+	0x66, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x17, 0x0a, 0x07, 0x69, 0x6d, 0x61, 0x5f, 0x6c, 0x6f,
+	0x67, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x69, 0x6d, 0x61, 0x4c, 0x6f, 0x67, 0x12,
+	0x36, 0x0a, 0x0c, 0x67, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18,
+	0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x47,
+	0x63, 0x65, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x52, 0x0b, 0x67, 0x63, 0x65, 0x49,
+	0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x2a, 0x0a, 0x11, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x5f, 0x6c, 0x6f, 0x67, 0x5f, 0x6f, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x18, 0x0c, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x4c, 0x6f, 0x67, 0x4f, 0x6d, 0x69, 0x74,
+	0x74, 0x65, 0x64, 0x42, 0x11, 0x0a, 0x0f, 0x74, 0x65, 0x65, 0x5f, 0x61, 0x74, 0x74, 0x65, 0x73,
+	0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x93, 0x01, 0x0a, 0x0b, 0x47, 0x63, 0x65, 0x49, 0x64,
+	0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x36, 0x0a, 0x17, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e,
+	0x63, 0x65, 0x5f, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x5f, 0x74, 0x6f, 0x6b, 0x65,
+	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x15, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63,
+	0x65, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x4c,
+	0x0a, 0x14, 0x73, 0x68, 0x69, 0x65, 0x6c, 0x64, 0x65, 0x64, 0x5f, 0x76, 0x6d, 0x5f, 0x69, 0x64,
+	0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x61,
+	0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x68, 0x69, 0x65, 0x6c, 0x64, 0x65, 0x64, 0x56, 0x6d,
+	0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x52, 0x12, 0x73, 0x68, 0x69, 0x65, 0x6c, 0x64,
+	0x65, 0x64, 0x56, 0x6d, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x22, 0xc4, 0x01, 0x0a,
+	0x12, 0x53, 0x68, 0x69, 0x65, 0x6c, 0x64, 0x65, 0x64, 0x56, 0x6d, 0x49, 0x64, 0x65, 0x6e, 0x74,
+	0x69, 0x74, 0x79, 0x12, 0x2e, 0x0a, 0x13, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x5f, 0x6b, 0x65, 0x79, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x11, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x4b, 0x65, 0x79, 0x43,
+	0x65, 0x72, 0x74, 0x12, 0x2c, 0x0a, 0x12, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x5f, 0x6b, 0x65, 0x79, 0x5f, 0x70, 0x75, 0x62, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x10, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x4b, 0x65, 0x79, 0x50, 0x75,
+	0x62, 0x12, 0x28, 0x0a, 0x10, 0x73, 0x69, 0x67, 0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x6b, 0x65, 0x79,
+	0x5f, 0x63, 0x65, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x73, 0x69, 0x67,
+	0x6e, 0x69, 0x6e, 0x67, 0x4b, 0x65, 0x79, 0x43, 0x65, 0x72, 0x74, 0x12, 0x26, 0x0a, 0x0f, 0x73,
+	0x69, 0x67, 0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x6b, 0x65, 0x79, 0x5f, 0x70, 0x75, 0x62, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x73, 0x69, 0x67, 0x6e, 0x69, 0x6e, 0x67, 0x4b, 0x65, 0x79,
+	0x50, 0x75, 0x62, 0x22, 0x71, 0x0a, 0x14, 0x41, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x53, 0x65, 0x6c, 0x66, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x72,
+	0x65, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72,
+	0x65, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x64, 0x12, 0x27, 0x0a, 0x0f, 0x6d, 0x69, 0x73, 0x6d, 0x61,
+	0x74, 0x63, 0x68, 0x65, 0x64, 0x5f, 0x70, 0x63, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0d,
+	0x52, 0x0e, 0x6d, 0x69, 0x73, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x50, 0x63, 0x72, 0x73,
+	0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x26, 0x0a, 0x0e, 0x54, 0x64, 0x78, 0x41, 0x74, 0x74,
+	0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x71, 0x75, 0x6f, 0x74,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x22, 0xe3,
+	0x01, 0x0a, 0x0b, 0x54, 0x65, 0x65, 0x45, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x1a,
+	0x0a, 0x08, 0x63, 0x72, 0x69, 0x74, 0x69, 0x63, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x08, 0x63, 0x72, 0x69, 0x74, 0x69, 0x63, 0x61, 0x6c, 0x12, 0x22, 0x0a, 0x0c, 0x63, 0x65,
+	0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c,
+	0x52, 0x0c, 0x63, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x73, 0x12, 0x45,
+	0x0a, 0x13, 0x73, 0x65, 0x76, 0x5f, 0x73, 0x6e, 0x70, 0x5f, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x73, 0x65,
+	0x76, 0x73, 0x6e, 0x70, 0x2e, 0x41, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x48, 0x00, 0x52, 0x11, 0x73, 0x65, 0x76, 0x53, 0x6e, 0x70, 0x41, 0x74, 0x74, 0x65, 0x73, 0x74,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x41, 0x0a, 0x0f, 0x74, 0x64, 0x78, 0x5f, 0x61, 0x74, 0x74,
+	0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16,
+	0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x54, 0x64, 0x78, 0x41, 0x74, 0x74, 0x65, 0x73,
+	0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x0e, 0x74, 0x64, 0x78, 0x41, 0x74, 0x74,
+	0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x0a, 0x0a, 0x08, 0x65, 0x76, 0x69, 0x64,
+	0x65, 0x6e, 0x63, 0x65, 0x22, 0x9e, 0x01, 0x0a, 0x13, 0x41, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x45, 0x6e, 0x76, 0x65, 0x6c, 0x6f, 0x70, 0x65, 0x12, 0x18, 0x0a, 0x07,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x35, 0x0a, 0x0b, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x74,
+	0x74, 0x65, 0x73, 0x74, 0x2e, 0x41, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x0b, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x36, 0x0a,
+	0x0c, 0x74, 0x65, 0x65, 0x5f, 0x65, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x54, 0x65, 0x65,
+	0x45, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x0b, 0x74, 0x65, 0x65, 0x45, 0x76, 0x69,
+	0x64, 0x65, 0x6e, 0x63, 0x65, 0x22, 0x9d, 0x02, 0x0a, 0x0d, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f,
+	0x72, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x2a, 0x0a, 0x10, 0x73, 0x63, 0x72, 0x74, 0x6d,
+	0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0c, 0x48, 0x00, 0x52, 0x0e, 0x73, 0x63, 0x72, 0x74, 0x6d, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0b, 0x67, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x00, 0x52, 0x0a, 0x67, 0x63, 0x65, 0x56,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x41, 0x0a, 0x0a, 0x74, 0x65, 0x63, 0x68, 0x6e, 0x6f,
+	0x6c, 0x6f, 0x67, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x21, 0x2e, 0x61, 0x74, 0x74,
+	0x65, 0x73, 0x74, 0x2e, 0x47, 0x43, 0x45, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x74,
+	0x69, 0x61, 0x6c, 0x54, 0x65, 0x63, 0x68, 0x6e, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x52, 0x0a, 0x74,
+	0x65, 0x63, 0x68, 0x6e, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x12, 0x3c, 0x0a, 0x0d, 0x69, 0x6e, 0x73,
+	0x74, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x17, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x47, 0x43, 0x45, 0x49, 0x6e, 0x73,
+	0x74, 0x61, 0x6e, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x0c, 0x69, 0x6e, 0x73, 0x74, 0x61,
+	0x6e, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x30, 0x0a, 0x14, 0x74, 0x70, 0x6d, 0x5f, 0x66,
+	0x69, 0x72, 0x6d, 0x77, 0x61, 0x72, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x12, 0x74, 0x70, 0x6d, 0x46, 0x69, 0x72, 0x6d, 0x77, 0x61,
+	0x72, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x42, 0x0a, 0x0a, 0x08, 0x66, 0x69, 0x72,
+	0x6d, 0x77, 0x61, 0x72, 0x65, 0x22, 0x51, 0x0a, 0x08, 0x47, 0x72, 0x75, 0x62, 0x46, 0x69, 0x6c,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x2d, 0x0a, 0x12, 0x75, 0x6e, 0x74,
+	0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x11, 0x75, 0x6e, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64,
+	0x46, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x4f, 0x0a, 0x09, 0x47, 0x72, 0x75, 0x62,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x26, 0x0a, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x47, 0x72,
+	0x75, 0x62, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x1a, 0x0a,
+	0x08, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x08, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x22, 0xbf, 0x02, 0x0a, 0x10, 0x4c, 0x69,
+	0x6e, 0x75, 0x78, 0x4b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x21,
+	0x0a, 0x0c, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x4c, 0x69, 0x6e,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x69, 0x6e, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x69, 0x6e, 0x69, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x69, 0x6d, 0x61, 0x5f, 0x70, 0x6f, 0x6c,
+	0x69, 0x63, 0x69, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x69, 0x6d, 0x61,
+	0x50, 0x6f, 0x6c, 0x69, 0x63, 0x69, 0x65, 0x73, 0x12, 0x32, 0x0a, 0x08, 0x6c, 0x6f, 0x63, 0x6b,
+	0x64, 0x6f, 0x77, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x61, 0x74, 0x74,
+	0x65, 0x73, 0x74, 0x2e, 0x4b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x4c, 0x6f, 0x63, 0x6b, 0x64, 0x6f,
+	0x77, 0x6e, 0x52, 0x08, 0x6c, 0x6f, 0x63, 0x6b, 0x64, 0x6f, 0x77, 0x6e, 0x12, 0x1a, 0x0a, 0x08,
+	0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x12, 0x2c, 0x0a, 0x12, 0x6d, 0x6f, 0x64, 0x75,
+	0x6c, 0x65, 0x5f, 0x73, 0x69, 0x67, 0x5f, 0x65, 0x6e, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x53, 0x69, 0x67, 0x45,
+	0x6e, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x12, 0x32, 0x0a, 0x09, 0x64, 0x6d, 0x5f, 0x76, 0x65, 0x72,
+	0x69, 0x74, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x74, 0x74, 0x65,
+	0x73, 0x74, 0x2e, 0x44, 0x6d, 0x56, 0x65, 0x72, 0x69, 0x74, 0x79, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x52, 0x08, 0x64, 0x6d, 0x56, 0x65, 0x72, 0x69, 0x74, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x61,
+	0x72, 0x73, 0x65, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0a, 0x70, 0x61, 0x72, 0x73, 0x65, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x22, 0xd7, 0x01, 0x0a, 0x0d,
+	0x44, 0x6d, 0x56, 0x65, 0x72, 0x69, 0x74, 0x79, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x10, 0x0a,
+	0x03, 0x61, 0x6c, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x61, 0x6c, 0x67, 0x12,
+	0x25, 0x0a, 0x0e, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x68, 0x65, 0x78, 0x64, 0x69, 0x67, 0x65, 0x73,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x72, 0x6f, 0x6f, 0x74, 0x48, 0x65, 0x78,
+	0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x61, 0x6c, 0x74, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x61, 0x6c, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61,
+	0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x79,
+	0x6c, 0x6f, 0x61, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x68, 0x61, 0x73, 0x68, 0x74, 0x72, 0x65, 0x65,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x68, 0x61, 0x73, 0x68, 0x74, 0x72, 0x65, 0x65,
+	0x12, 0x1c, 0x0a, 0x09, 0x68, 0x61, 0x73, 0x68, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x09, 0x68, 0x61, 0x73, 0x68, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x25,
+	0x0a, 0x0e, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x62, 0x65, 0x68, 0x61, 0x76, 0x69, 0x6f, 0x72,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x42, 0x65, 0x68,
+	0x61, 0x76, 0x69, 0x6f, 0x72, 0x22, 0xa0, 0x01, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12,
+	0x1b, 0x0a, 0x09, 0x70, 0x63, 0x72, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x08, 0x70, 0x63, 0x72, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x25, 0x0a, 0x0e,
+	0x75, 0x6e, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x75, 0x6e, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x54,
+	0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73,
+	0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12,
+	0x27, 0x0a, 0x0f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x5f, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69,
+	0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74,
+	0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x22, 0x72, 0x0a, 0x0b, 0x43, 0x65, 0x72, 0x74,
+	0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x03, 0x64, 0x65, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x03, 0x64, 0x65, 0x72, 0x12, 0x3d, 0x0a, 0x0a, 0x77,
+	0x65, 0x6c, 0x6c, 0x5f, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x1c, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x57, 0x65, 0x6c, 0x6c, 0x4b, 0x6e, 0x6f,
+	0x77, 0x6e, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x48, 0x00, 0x52,
+	0x09, 0x77, 0x65, 0x6c, 0x6c, 0x4b, 0x6e, 0x6f, 0x77, 0x6e, 0x42, 0x10, 0x0a, 0x0e, 0x72, 0x65,
+	0x70, 0x72, 0x65, 0x73, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x4d, 0x0a, 0x08,
+	0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x12, 0x29, 0x0a, 0x05, 0x63, 0x65, 0x72, 0x74,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74,
+	0x2e, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x52, 0x05, 0x63, 0x65,
+	0x72, 0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0c, 0x52, 0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x22, 0xa1, 0x01, 0x0a, 0x0f,
+	0x53, 0x65, 0x63, 0x75, 0x72, 0x65, 0x42, 0x6f, 0x6f, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x12, 0x20, 0x0a, 0x02, 0x64, 0x62, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x44,
+	0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x52, 0x02, 0x64, 0x62, 0x12, 0x22, 0x0a, 0x03, 0x64,
+	0x62, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73,
+	0x74, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x52, 0x03, 0x64, 0x62, 0x78, 0x12,
+	0x2e, 0x0a, 0x09, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x44, 0x61, 0x74, 0x61,
+	0x62, 0x61, 0x73, 0x65, 0x52, 0x09, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x22,
+	0x3c, 0x0a, 0x0e, 0x57, 0x6f, 0x72, 0x6b, 0x6c, 0x6f, 0x61, 0x64, 0x4f, 0x75, 0x74, 0x70, 0x75,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x22, 0xb8, 0x0b,
+	0x0a, 0x0e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x12, 0x27, 0x0a, 0x0f, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x5f, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65,
+	0x6e, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x69, 0x6d, 0x61, 0x67, 0x65,
+	0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x69, 0x6d, 0x61,
+	0x67, 0x65, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x3c, 0x0a, 0x0e,
+	0x72, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x52, 0x65,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x0d, 0x72, 0x65, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x19, 0x0a, 0x08, 0x69, 0x6d,
+	0x61, 0x67, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x69, 0x6d,
+	0x61, 0x67, 0x65, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x72, 0x67, 0x73, 0x18, 0x05, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x04, 0x61, 0x72, 0x67, 0x73, 0x12, 0x3e, 0x0a, 0x08, 0x65, 0x6e, 0x76,
+	0x5f, 0x76, 0x61, 0x72, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x61, 0x74,
+	0x74, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x2e, 0x45, 0x6e, 0x76, 0x56, 0x61, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x52, 0x07, 0x65, 0x6e, 0x76, 0x56, 0x61, 0x72, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x6f, 0x76, 0x65,
+	0x72, 0x72, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x5f, 0x61, 0x72, 0x67, 0x73, 0x18, 0x07, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x0e, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x41, 0x72,
+	0x67, 0x73, 0x12, 0x5d, 0x0a, 0x13, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x64, 0x65, 0x6e,
+	0x5f, 0x65, 0x6e, 0x76, 0x5f, 0x76, 0x61, 0x72, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x2d, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e,
+	0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x64,
+	0x65, 0x6e, 0x45, 0x6e, 0x76, 0x56, 0x61, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x11,
+	0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x45, 0x6e, 0x76, 0x56, 0x61, 0x72,
+	0x73, 0x12, 0x21, 0x0a, 0x0c, 0x68, 0x6f, 0x73, 0x74, 0x5f, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72,
+	0x6b, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x68, 0x6f, 0x73, 0x74, 0x4e, 0x65, 0x74,
+	0x77, 0x6f, 0x72, 0x6b, 0x12, 0x28, 0x0a, 0x10, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x6f, 0x6e, 0x6c,
+	0x79, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x66, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e,
+	0x72, 0x65, 0x61, 0x64, 0x4f, 0x6e, 0x6c, 0x79, 0x52, 0x6f, 0x6f, 0x74, 0x66, 0x73, 0x12, 0x2d,
+	0x0a, 0x12, 0x61, 0x64, 0x64, 0x65, 0x64, 0x5f, 0x63, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69,
+	0x74, 0x69, 0x65, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x09, 0x52, 0x11, 0x61, 0x64, 0x64, 0x65,
+	0x64, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x12, 0x25, 0x0a,
+	0x06, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e,
+	0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x06, 0x6d, 0x6f,
+	0x75, 0x6e, 0x74, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x18,
+	0x0d, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x12, 0x2e,
+	0x0a, 0x13, 0x77, 0x6f, 0x72, 0x6b, 0x6c, 0x6f, 0x61, 0x64, 0x5f, 0x6b, 0x65, 0x79, 0x5f, 0x64,
+	0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x77, 0x6f, 0x72,
+	0x6b, 0x6c, 0x6f, 0x61, 0x64, 0x4b, 0x65, 0x79, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x2e,
+	0x0a, 0x13, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x5f, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x5f, 0x64, 0x69,
+	0x67, 0x65, 0x73, 0x74, 0x73, 0x18, 0x0f, 0x20, 0x03, 0x28, 0x09, 0x52, 0x11, 0x69, 0x6d, 0x61,
+	0x67, 0x65, 0x4c, 0x61, 0x79, 0x65, 0x72, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x73, 0x12, 0x34,
+	0x0a, 0x16, 0x77, 0x6f, 0x72, 0x6b, 0x6c, 0x6f, 0x61, 0x64, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x10, 0x20, 0x01, 0x28, 0x09, 0x52, 0x14,
+	0x77, 0x6f, 0x72, 0x6b, 0x6c, 0x6f, 0x61, 0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x44, 0x69,
+	0x67, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x11, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x25, 0x0a, 0x0e, 0x75, 0x73, 0x65, 0x72,
+	0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x12, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0d, 0x75, 0x73, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12,
+	0x27, 0x0a, 0x0f, 0x73, 0x65, 0x63, 0x63, 0x6f, 0x6d, 0x70, 0x5f, 0x70, 0x72, 0x6f, 0x66, 0x69,
+	0x6c, 0x65, 0x18, 0x13, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x73, 0x65, 0x63, 0x63, 0x6f, 0x6d,
+	0x70, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x61, 0x70, 0x70, 0x61,
+	0x72, 0x6d, 0x6f, 0x72, 0x5f, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x14, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0f, 0x61, 0x70, 0x70, 0x61, 0x72, 0x6d, 0x6f, 0x72, 0x50, 0x72, 0x6f, 0x66,
+	0x69, 0x6c, 0x65, 0x12, 0x30, 0x0a, 0x14, 0x6c, 0x61, 0x75, 0x6e, 0x63, 0x68, 0x5f, 0x70, 0x6f,
+	0x6c, 0x69, 0x63, 0x79, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x15, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x12, 0x6c, 0x61, 0x75, 0x6e, 0x63, 0x68, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x44,
+	0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x30, 0x0a, 0x14, 0x6c, 0x61, 0x75, 0x6e, 0x63, 0x68, 0x5f,
+	0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x5f, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x18, 0x16, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x12, 0x6c, 0x61, 0x75, 0x6e, 0x63, 0x68, 0x50, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x62, 0x6f, 0x6f, 0x74, 0x5f,
+	0x74, 0x69, 0x6d, 0x65, 0x18, 0x17, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x62, 0x6f, 0x6f, 0x74,
+	0x54, 0x69, 0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x18, 0x18, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6c, 0x6f, 0x63,
+	0x6b, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x3f, 0x0a, 0x0f, 0x77, 0x6f, 0x72, 0x6b, 0x6c,
+	0x6f, 0x61, 0x64, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x18, 0x19, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x16, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x6c, 0x6f,
+	0x61, 0x64, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x52, 0x0e, 0x77, 0x6f, 0x72, 0x6b, 0x6c, 0x6f,
+	0x61, 0x64, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x69, 0x6d, 0x61, 0x67,
+	0x65, 0x5f, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x1a, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0d, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x12,
+	0x32, 0x0a, 0x15, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73,
+	0x74, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x1b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x13,
+	0x69, 0x6d, 0x61, 0x67, 0x65, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x44, 0x69, 0x67,
+	0x65, 0x73, 0x74, 0x12, 0x32, 0x0a, 0x0b, 0x63, 0x64, 0x69, 0x5f, 0x64, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x73, 0x18, 0x1c, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73,
+	0x74, 0x2e, 0x43, 0x44, 0x49, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52, 0x0a, 0x63, 0x64, 0x69,
+	0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x61, 0x74, 0x74, 0x65, 0x73,
+	0x74, 0x5f, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x5f, 0x72, 0x75, 0x6e, 0x18, 0x1d, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0f, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65,
+	0x52, 0x75, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x76, 0x61, 0x6c, 0x5f,
+	0x75, 0x72, 0x6c, 0x18, 0x1e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x61, 0x70, 0x70, 0x72, 0x6f,
+	0x76, 0x61, 0x6c, 0x55, 0x72, 0x6c, 0x1a, 0x3a, 0x0a, 0x0c, 0x45, 0x6e, 0x76, 0x56, 0x61, 0x72,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x1a, 0x44, 0x0a, 0x16, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x64, 0x65, 0x6e,
+	0x45, 0x6e, 0x76, 0x56, 0x61, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x42, 0x0a, 0x09, 0x43, 0x44, 0x49, 0x44,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x5f, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x72, 0x0a, 0x05,
+	0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x6f, 0x6e, 0x6c, 0x79,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x61, 0x64, 0x4f, 0x6e, 0x6c, 0x79,
+	0x22, 0x53, 0x0a, 0x0f, 0x53, 0x65, 0x6d, 0x61, 0x6e, 0x74, 0x69, 0x63, 0x56, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x61, 0x6a, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x05, 0x6d, 0x61, 0x6a, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x69, 0x6e,
+	0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6d, 0x69, 0x6e, 0x6f, 0x72, 0x12,
+	0x14, 0x0a, 0x05, 0x70, 0x61, 0x74, 0x63, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05,
+	0x70, 0x61, 0x74, 0x63, 0x68, 0x22, 0x75, 0x0a, 0x11, 0x43, 0x6f, 0x73, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x65,
+	0x63, 0x6f, 0x72, 0x64, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x0c, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12,
+	0x1d, 0x0a, 0x0a, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x09, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1c,
+	0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x22, 0x4e, 0x0a, 0x08,
+	0x43, 0x6f, 0x73, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x76, 0x65, 0x6e,
+	0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x65, 0x76,
+	0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c,
+	0x65, 0x76, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22, 0xc5, 0x05, 0x0a,
+	0x10, 0x41, 0x74, 0x74, 0x65, 0x73, 0x74, 0x65, 0x64, 0x43, 0x6f, 0x73, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x12, 0x34, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x6f,
+	0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x09, 0x63, 0x6f,
+	0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x12, 0x38, 0x0a, 0x0b, 0x63, 0x6f, 0x73, 0x5f, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x61,
+	0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x65, 0x6d, 0x61, 0x6e, 0x74, 0x69, 0x63, 0x56, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x63, 0x6f, 0x73, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x42, 0x0a, 0x10, 0x6c, 0x61, 0x75, 0x6e, 0x63, 0x68, 0x65, 0x72, 0x5f, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x61, 0x74,
+	0x74, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x65, 0x6d, 0x61, 0x6e, 0x74, 0x69, 0x63, 0x56, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0f, 0x6c, 0x61, 0x75, 0x6e, 0x63, 0x68, 0x65, 0x72, 0x56, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x45, 0x0a, 0x10, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x5f, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x1a, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x65, 0x6c, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x0f, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x44, 0x0a, 0x10,
+	0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x73,
+	0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e,
+	0x43, 0x6f, 0x73, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x0f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x73, 0x12, 0x33, 0x0a, 0x0e, 0x63, 0x65, 0x6c, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x5f, 0x61,
+	0x6c, 0x67, 0x6f, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x74, 0x70, 0x6d,
+	0x2e, 0x48, 0x61, 0x73, 0x68, 0x41, 0x6c, 0x67, 0x6f, 0x52, 0x0c, 0x63, 0x65, 0x6c, 0x48, 0x61,
+	0x73, 0x68, 0x41, 0x6c, 0x67, 0x6f, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x73, 0x63, 0x68, 0x65, 0x6d,
+	0x61, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x0d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x37,
+	0x0a, 0x0e, 0x75, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73,
+	0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e,
+	0x43, 0x6f, 0x73, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x0d, 0x75, 0x6e, 0x6b, 0x6e, 0x6f, 0x77,
+	0x6e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x3b, 0x0a, 0x0e, 0x6b, 0x65, 0x72, 0x6e, 0x65,
+	0x6c, 0x5f, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x14, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x4b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x4d,
+	0x6f, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x0d, 0x6b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x4d, 0x6f, 0x64,
+	0x75, 0x6c, 0x65, 0x73, 0x12, 0x31, 0x0a, 0x08, 0x77, 0x6f, 0x72, 0x6b, 0x6c, 0x6f, 0x61, 0x64,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e,
+	0x57, 0x6f, 0x72, 0x6b, 0x6c, 0x6f, 0x61, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x08, 0x77,
+	0x6f, 0x72, 0x6b, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x34, 0x0a, 0x16, 0x6c, 0x61, 0x75, 0x6e, 0x63,
+	0x68, 0x65, 0x72, 0x5f, 0x62, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73,
+	0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x14, 0x6c, 0x61, 0x75, 0x6e, 0x63, 0x68, 0x65,
+	0x72, 0x42, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x35, 0x0a,
+	0x0a, 0x6b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x5f, 0x6c, 0x6f, 0x67, 0x18, 0x0c, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x16, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x4b, 0x65, 0x72, 0x6e, 0x65,
+	0x6c, 0x4c, 0x6f, 0x67, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x09, 0x6b, 0x65, 0x72, 0x6e, 0x65,
+	0x6c, 0x4c, 0x6f, 0x67, 0x22, 0x3e, 0x0a, 0x0e, 0x4b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x4c, 0x6f,
+	0x67, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x14,
+	0x0a, 0x05, 0x74, 0x61, 0x69, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x74,
+	0x61, 0x69, 0x6e, 0x74, 0x22, 0x57, 0x0a, 0x0d, 0x57, 0x6f, 0x72, 0x6b, 0x6c, 0x6f, 0x61, 0x64,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x32, 0x0a, 0x08, 0x62, 0x69, 0x6e,
+	0x61, 0x72, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61, 0x74,
+	0x74, 0x65, 0x73, 0x74, 0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x6c, 0x6f, 0x61, 0x64, 0x42, 0x69, 0x6e,
+	0x61, 0x72, 0x79, 0x52, 0x08, 0x62, 0x69, 0x6e, 0x61, 0x72, 0x69, 0x65, 0x73, 0x22, 0x45, 0x0a,
+	0x0e, 0x57, 0x6f, 0x72, 0x6b, 0x6c, 0x6f, 0x61, 0x64, 0x42, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x12,
+	0x1f, 0x0a, 0x0b, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x66, 0x69, 0x6c, 0x65, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74,
+	0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x70, 0x61, 0x74, 0x68, 0x22, 0x4c, 0x0a, 0x0c, 0x4b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x4d, 0x6f,
+	0x64, 0x75, 0x6c, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x64, 0x69, 0x67,
+	0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x66, 0x69, 0x6c, 0x65, 0x44,
+	0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x4e, 0x61,
+	0x6d, 0x65, 0x22, 0x7e, 0x0a, 0x0c, 0x54, 0x50, 0x4d, 0x43, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x6e,
+	0x66, 0x6f, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x05, 0x63, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x1f, 0x0a, 0x0b, 0x72, 0x65, 0x73, 0x65,
+	0x74, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x72,
+	0x65, 0x73, 0x65, 0x74, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x65, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x0c, 0x72, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x73, 0x61, 0x66, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x73, 0x61,
+	0x66, 0x65, 0x22, 0xaa, 0x01, 0x0a, 0x0e, 0x49, 0x6d, 0x61, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72,
+	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x63, 0x72, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x03, 0x70, 0x63, 0x72, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x65, 0x6d, 0x70, 0x6c,
+	0x61, 0x74, 0x65, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c,
+	0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x48, 0x61, 0x73, 0x68, 0x12, 0x23, 0x0a, 0x0d,
+	0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0c, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d,
+	0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x66, 0x69, 0x6c, 0x65, 0x44, 0x69, 0x67, 0x65,
+	0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x22,
+	0x97, 0x01, 0x0a, 0x08, 0x49, 0x6d, 0x61, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x3a, 0x0a, 0x0c,
+	0x6d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x49, 0x6d, 0x61, 0x4d,
+	0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x0c, 0x6d, 0x65, 0x61, 0x73,
+	0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x4f, 0x0a, 0x17, 0x75, 0x6e, 0x76, 0x65,
+	0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x5f, 0x6d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65,
+	0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61, 0x74, 0x74, 0x65,
+	0x73, 0x74, 0x2e, 0x49, 0x6d, 0x61, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x52, 0x16, 0x75, 0x6e, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x4d, 0x65, 0x61,
+	0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x22, 0xbc, 0x04, 0x0a, 0x0c, 0x4d, 0x61,
+	0x63, 0x68, 0x69, 0x6e, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x31, 0x0a, 0x08, 0x70, 0x6c,
+	0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61,
+	0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x12, 0x38, 0x0a,
+	0x0b, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x5f, 0x62, 0x6f, 0x6f, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x17, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x65, 0x63, 0x75,
+	0x72, 0x65, 0x42, 0x6f, 0x6f, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x0a, 0x73, 0x65, 0x63,
+	0x75, 0x72, 0x65, 0x42, 0x6f, 0x6f, 0x74, 0x12, 0x2c, 0x0a, 0x0a, 0x72, 0x61, 0x77, 0x5f, 0x65,
+	0x76, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x61, 0x74,
+	0x74, 0x65, 0x73, 0x74, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x09, 0x72, 0x61, 0x77, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x21, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x74, 0x70, 0x6d, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x41, 0x6c,
+	0x67, 0x6f, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x25, 0x0a, 0x04, 0x67, 0x72, 0x75, 0x62,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e,
+	0x47, 0x72, 0x75, 0x62, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x04, 0x67, 0x72, 0x75, 0x62, 0x12,
+	0x3b, 0x0a, 0x0c, 0x6c, 0x69, 0x6e, 0x75, 0x78, 0x5f, 0x6b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x4c,
+	0x69, 0x6e, 0x75, 0x78, 0x4b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52,
+	0x0b, 0x6c, 0x69, 0x6e, 0x75, 0x78, 0x4b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x12, 0x2a, 0x0a, 0x03,
+	0x63, 0x6f, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x61, 0x74, 0x74, 0x65,
+	0x73, 0x74, 0x2e, 0x41, 0x74, 0x74, 0x65, 0x73, 0x74, 0x65, 0x64, 0x43, 0x6f, 0x73, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x52, 0x03, 0x63, 0x6f, 0x73, 0x12, 0x33, 0x0a, 0x0a, 0x63, 0x6c, 0x6f, 0x63,
+	0x6b, 0x5f, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61,
+	0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x54, 0x50, 0x4d, 0x43, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x6e,
+	0x66, 0x6f, 0x52, 0x09, 0x63, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x22, 0x0a,
+	0x03, 0x69, 0x6d, 0x61, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x74, 0x74,
+	0x65, 0x73, 0x74, 0x2e, 0x49, 0x6d, 0x61, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x03, 0x69, 0x6d,
+	0x61, 0x12, 0x59, 0x0a, 0x16, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61,
+	0x6c, 0x5f, 0x63, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x22, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69,
+	0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x69, 0x6e, 0x67,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x15, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x74,
+	0x69, 0x61, 0x6c, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x2a, 0x0a, 0x11,
+	0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x6c, 0x6f, 0x67, 0x5f, 0x6f, 0x6d, 0x69, 0x74, 0x74, 0x65,
+	0x64, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x4c, 0x6f,
+	0x67, 0x4f, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x22, 0xbd, 0x01, 0x0a, 0x1a, 0x43, 0x6f, 0x6e,
+	0x66, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x69,
+	0x6e, 0x67, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x41, 0x0a, 0x0a, 0x74, 0x65, 0x63, 0x68, 0x6e,
+	0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x21, 0x2e, 0x61, 0x74,
+	0x74, 0x65, 0x73, 0x74, 0x2e, 0x47, 0x43, 0x45, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e,
+	0x74, 0x69, 0x61, 0x6c, 0x54, 0x65, 0x63, 0x68, 0x6e, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x52, 0x0a,
+	0x74, 0x65, 0x63, 0x68, 0x6e, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x12, 0x32, 0x0a, 0x15, 0x74, 0x65,
+	0x65, 0x5f, 0x65, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x69, 0x66,
+	0x69, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x13, 0x74, 0x65, 0x65, 0x45, 0x76,
+	0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x12, 0x28,
+	0x0a, 0x10, 0x67, 0x63, 0x65, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x65, 0x64, 0x5f,
+	0x61, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x67, 0x63, 0x65, 0x43, 0x65, 0x72,
+	0x74, 0x69, 0x66, 0x69, 0x65, 0x64, 0x41, 0x6b, 0x22, 0xb5, 0x02, 0x0a, 0x11, 0x56, 0x65, 0x72,
+	0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x12,
+	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x12, 0x2c, 0x0a, 0x0a, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x5f, 0x68, 0x61, 0x73, 0x68,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x74, 0x70, 0x6d, 0x2e, 0x48, 0x61, 0x73,
+	0x68, 0x41, 0x6c, 0x67, 0x6f, 0x52, 0x09, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x48, 0x61, 0x73, 0x68,
+	0x12, 0x50, 0x0a, 0x0d, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2b, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74,
+	0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x68, 0x65,
+	0x63, 0x6b, 0x2e, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x0c, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x44, 0x69, 0x67, 0x65, 0x73,
+	0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x73, 0x73, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x06, 0x70, 0x61, 0x73, 0x73, 0x65, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x1a,
+	0x3f, 0x0a, 0x11, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x22, 0xd1, 0x01, 0x0a, 0x12, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x2d, 0x0a, 0x12, 0x61, 0x74, 0x74, 0x65, 0x73,
+	0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x11, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x31, 0x0a, 0x06, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e,
+	0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x68, 0x65, 0x63,
+	0x6b, 0x52, 0x06, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x76, 0x65, 0x72,
+	0x69, 0x66, 0x69, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x76, 0x65, 0x72,
+	0x69, 0x66, 0x69, 0x65, 0x64, 0x12, 0x3d, 0x0a, 0x13, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65,
+	0x64, 0x5f, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x04, 0x20, 0x01,
 	0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x74, 0x70, 0x6d, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x41, 0x6c, 0x67,
-	0x6f, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x25, 0x0a, 0x04, 0x67, 0x72, 0x75, 0x62, 0x18,
-	0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x47,
-	0x72, 0x75, 0x62, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x04, 0x67, 0x72, 0x75, 0x62, 0x12, 0x3b,
-	0x0a, 0x0c, 0x6c, 0x69, 0x6e, 0x75, 0x78, 0x5f, 0x6b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x18, 0x06,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x4c, 0x69,
-	0x6e, 0x75, 0x78, 0x4b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x0b,
-	0x6c, 0x69, 0x6e, 0x75, 0x78, 0x4b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x12, 0x2a, 0x0a, 0x03, 0x63,
-	0x6f, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73,
-	0x74, 0x2e, 0x41, 0x74, 0x74, 0x65, 0x73, 0x74, 0x65, 0x64, 0x43, 0x6f, 0x73, 0x53, 0x74, 0x61,
-	0x74, 0x65, 0x52, 0x03, 0x63, 0x6f, 0x73, 0x22, 0xde, 0x01, 0x0a, 0x0e, 0x50, 0x6c, 0x61, 0x74,
-	0x66, 0x6f, 0x72, 0x6d, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x39, 0x0a, 0x19, 0x61, 0x6c,
-	0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x73, 0x63, 0x72, 0x74, 0x6d, 0x5f, 0x76, 0x65, 0x72, 0x73,
-	0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x16, 0x61,
-	0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x53, 0x63, 0x72, 0x74, 0x6d, 0x56, 0x65, 0x72, 0x73, 0x69,
-	0x6f, 0x6e, 0x49, 0x64, 0x73, 0x12, 0x3f, 0x0a, 0x1c, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d,
-	0x5f, 0x67, 0x63, 0x65, 0x5f, 0x66, 0x69, 0x72, 0x6d, 0x77, 0x61, 0x72, 0x65, 0x5f, 0x76, 0x65,
-	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x19, 0x6d, 0x69, 0x6e,
-	0x69, 0x6d, 0x75, 0x6d, 0x47, 0x63, 0x65, 0x46, 0x69, 0x72, 0x6d, 0x77, 0x61, 0x72, 0x65, 0x56,
-	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x50, 0x0a, 0x12, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75,
-	0x6d, 0x5f, 0x74, 0x65, 0x63, 0x68, 0x6e, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x0e, 0x32, 0x21, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x47, 0x43, 0x45, 0x43,
-	0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x54, 0x65, 0x63, 0x68, 0x6e,
-	0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x52, 0x11, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x54, 0x65,
-	0x63, 0x68, 0x6e, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x22, 0x3c, 0x0a, 0x06, 0x50, 0x6f, 0x6c, 0x69,
-	0x63, 0x79, 0x12, 0x32, 0x0a, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x50, 0x6c,
-	0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x08, 0x70, 0x6c,
-	0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x2a, 0x53, 0x0a, 0x19, 0x47, 0x43, 0x45, 0x43, 0x6f, 0x6e,
-	0x66, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x54, 0x65, 0x63, 0x68, 0x6e, 0x6f, 0x6c,
-	0x6f, 0x67, 0x79, 0x12, 0x08, 0x0a, 0x04, 0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x00, 0x12, 0x0b, 0x0a,
-	0x07, 0x41, 0x4d, 0x44, 0x5f, 0x53, 0x45, 0x56, 0x10, 0x01, 0x12, 0x0e, 0x0a, 0x0a, 0x41, 0x4d,
-	0x44, 0x5f, 0x53, 0x45, 0x56, 0x5f, 0x45, 0x53, 0x10, 0x02, 0x12, 0x0f, 0x0a, 0x0b, 0x41, 0x4d,
-	0x44, 0x5f, 0x53, 0x45, 0x56, 0x5f, 0x53, 0x4e, 0x50, 0x10, 0x04, 0x2a, 0x62, 0x0a, 0x14, 0x57,
-	0x65, 0x6c, 0x6c, 0x4b, 0x6e, 0x6f, 0x77, 0x6e, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63,
-	0x61, 0x74, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00,
-	0x12, 0x1c, 0x0a, 0x18, 0x4d, 0x53, 0x5f, 0x57, 0x49, 0x4e, 0x44, 0x4f, 0x57, 0x53, 0x5f, 0x50,
-	0x52, 0x4f, 0x44, 0x5f, 0x50, 0x43, 0x41, 0x5f, 0x32, 0x30, 0x31, 0x31, 0x10, 0x01, 0x12, 0x1f,
-	0x0a, 0x1b, 0x4d, 0x53, 0x5f, 0x54, 0x48, 0x49, 0x52, 0x44, 0x5f, 0x50, 0x41, 0x52, 0x54, 0x59,
-	0x5f, 0x55, 0x45, 0x46, 0x49, 0x5f, 0x43, 0x41, 0x5f, 0x32, 0x30, 0x31, 0x31, 0x10, 0x02, 0x2a,
-	0x35, 0x0a, 0x0d, 0x52, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
-	0x12, 0x0a, 0x0a, 0x06, 0x41, 0x6c, 0x77, 0x61, 0x79, 0x73, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09,
-	0x4f, 0x6e, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05, 0x4e,
-	0x65, 0x76, 0x65, 0x72, 0x10, 0x02, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
-	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x67, 0x6f, 0x2d, 0x74,
-	0x70, 0x6d, 0x2d, 0x74, 0x6f, 0x6f, 0x6c, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x61,
-	0x74, 0x74, 0x65, 0x73, 0x74, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6f, 0x52, 0x11, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x51, 0x75, 0x6f, 0x74, 0x65,
+	0x48, 0x61, 0x73, 0x68, 0x22, 0xd6, 0x02, 0x0a, 0x0e, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72,
+	0x6d, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x39, 0x0a, 0x19, 0x61, 0x6c, 0x6c, 0x6f, 0x77,
+	0x65, 0x64, 0x5f, 0x73, 0x63, 0x72, 0x74, 0x6d, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x5f, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x16, 0x61, 0x6c, 0x6c, 0x6f,
+	0x77, 0x65, 0x64, 0x53, 0x63, 0x72, 0x74, 0x6d, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x49,
+	0x64, 0x73, 0x12, 0x3f, 0x0a, 0x1c, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x67, 0x63,
+	0x65, 0x5f, 0x66, 0x69, 0x72, 0x6d, 0x77, 0x61, 0x72, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x19, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75,
+	0x6d, 0x47, 0x63, 0x65, 0x46, 0x69, 0x72, 0x6d, 0x77, 0x61, 0x72, 0x65, 0x56, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x50, 0x0a, 0x12, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x74,
+	0x65, 0x63, 0x68, 0x6e, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x21, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x47, 0x43, 0x45, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x54, 0x65, 0x63, 0x68, 0x6e, 0x6f, 0x6c, 0x6f,
+	0x67, 0x79, 0x52, 0x11, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x54, 0x65, 0x63, 0x68, 0x6e,
+	0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x12, 0x35, 0x0a, 0x08, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74,
+	0x2e, 0x47, 0x43, 0x45, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x50, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x52, 0x08, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x3f, 0x0a, 0x1c,
+	0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x74, 0x70, 0x6d, 0x5f, 0x66, 0x69, 0x72, 0x6d,
+	0x77, 0x61, 0x72, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x19, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x54, 0x70, 0x6d, 0x46, 0x69,
+	0x72, 0x6d, 0x77, 0x61, 0x72, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0xc2, 0x02,
+	0x0a, 0x11, 0x47, 0x43, 0x45, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x50, 0x6f, 0x6c,
+	0x69, 0x63, 0x79, 0x12, 0x2e, 0x0a, 0x13, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x70,
+	0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x11, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x50, 0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74,
+	0x49, 0x64, 0x73, 0x12, 0x36, 0x0a, 0x17, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x70,
+	0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x04, 0x52, 0x15, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x50, 0x72, 0x6f,
+	0x6a, 0x65, 0x63, 0x74, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x61,
+	0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x7a, 0x6f, 0x6e, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x0c, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5a, 0x6f, 0x6e, 0x65, 0x73,
+	0x12, 0x30, 0x0a, 0x14, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x69, 0x6e, 0x73, 0x74,
+	0x61, 0x6e, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x04, 0x52, 0x12,
+	0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x49,
+	0x64, 0x73, 0x12, 0x34, 0x0a, 0x16, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x69, 0x6e,
+	0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x14, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x49, 0x6e, 0x73, 0x74, 0x61,
+	0x6e, 0x63, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x12, 0x38, 0x0a, 0x18, 0x6d, 0x69, 0x6e, 0x69,
+	0x6d, 0x75, 0x6d, 0x5f, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x5f, 0x76, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x16, 0x6d, 0x69, 0x6e, 0x69,
+	0x6d, 0x75, 0x6d, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x22, 0x64, 0x0a, 0x0b, 0x43, 0x6c, 0x6f, 0x63, 0x6b, 0x50, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x5f, 0x73, 0x61, 0x66,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65,
+	0x53, 0x61, 0x66, 0x65, 0x12, 0x32, 0x0a, 0x09, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74,
+	0x2e, 0x54, 0x50, 0x4d, 0x43, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x09, 0x72,
+	0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x22, 0xaf, 0x02, 0x0a, 0x0c, 0x4b, 0x65, 0x72,
+	0x6e, 0x65, 0x6c, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x6c, 0x6c,
+	0x6f, 0x77, 0x65, 0x64, 0x5f, 0x69, 0x6e, 0x69, 0x74, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x0b, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x49, 0x6e, 0x69, 0x74, 0x12, 0x41, 0x0a, 0x10,
+	0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x64, 0x6f, 0x77, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e,
+	0x4b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x4c, 0x6f, 0x63, 0x6b, 0x64, 0x6f, 0x77, 0x6e, 0x52, 0x0f,
+	0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x4c, 0x6f, 0x63, 0x6b, 0x64, 0x6f, 0x77, 0x6e, 0x12,
+	0x32, 0x0a, 0x15, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x5f, 0x69, 0x6d, 0x61, 0x5f,
+	0x70, 0x6f, 0x6c, 0x69, 0x63, 0x69, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x13,
+	0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x49, 0x6d, 0x61, 0x50, 0x6f, 0x6c, 0x69, 0x63,
+	0x69, 0x65, 0x73, 0x12, 0x48, 0x0a, 0x21, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x64,
+	0x6d, 0x5f, 0x76, 0x65, 0x72, 0x69, 0x74, 0x79, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x68, 0x65,
+	0x78, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x1d,
+	0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x44, 0x6d, 0x56, 0x65, 0x72, 0x69, 0x74, 0x79, 0x52,
+	0x6f, 0x6f, 0x74, 0x48, 0x65, 0x78, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x73, 0x12, 0x3b, 0x0a,
+	0x1a, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x5f, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x5f,
+	0x73, 0x69, 0x67, 0x5f, 0x65, 0x6e, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x17, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65,
+	0x53, 0x69, 0x67, 0x45, 0x6e, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x22, 0x49, 0x0a, 0x07, 0x49, 0x6d,
+	0x61, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x74, 0x68, 0x5f, 0x67, 0x6c,
+	0x6f, 0x62, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x74, 0x68, 0x47, 0x6c,
+	0x6f, 0x62, 0x12, 0x21, 0x0a, 0x0c, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73,
+	0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x66, 0x69, 0x6c, 0x65, 0x44, 0x69,
+	0x67, 0x65, 0x73, 0x74, 0x73, 0x22, 0x57, 0x0a, 0x09, 0x49, 0x6d, 0x61, 0x50, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x12, 0x25, 0x0a, 0x05, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x0f, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x49, 0x6d, 0x61, 0x52, 0x75,
+	0x6c, 0x65, 0x52, 0x05, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x12, 0x23, 0x0a, 0x04, 0x64, 0x65, 0x6e,
+	0x79, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74,
+	0x2e, 0x49, 0x6d, 0x61, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x04, 0x64, 0x65, 0x6e, 0x79, 0x22, 0x96,
+	0x01, 0x0a, 0x0c, 0x49, 0x6d, 0x61, 0x56, 0x69, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05,
+	0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x38, 0x0a, 0x0b, 0x6d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61, 0x74, 0x74,
+	0x65, 0x73, 0x74, 0x2e, 0x49, 0x6d, 0x61, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65,
+	0x6e, 0x74, 0x52, 0x0b, 0x6d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12,
+	0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x1e, 0x0a, 0x0a, 0x75, 0x6e, 0x76, 0x65, 0x72,
+	0x69, 0x66, 0x69, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x75, 0x6e, 0x76,
+	0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x22, 0x91, 0x02, 0x0a, 0x09, 0x43, 0x6f, 0x73, 0x50,
+	0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x51, 0x0a, 0x18, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d,
+	0x5f, 0x6c, 0x61, 0x75, 0x6e, 0x63, 0x68, 0x65, 0x72, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74,
+	0x2e, 0x53, 0x65, 0x6d, 0x61, 0x6e, 0x74, 0x69, 0x63, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x52, 0x16, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x4c, 0x61, 0x75, 0x6e, 0x63, 0x68, 0x65,
+	0x72, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x45, 0x0a, 0x1f, 0x61, 0x6c, 0x6c, 0x6f,
+	0x77, 0x65, 0x64, 0x5f, 0x6c, 0x61, 0x75, 0x6e, 0x63, 0x68, 0x65, 0x72, 0x5f, 0x62, 0x69, 0x6e,
+	0x61, 0x72, 0x79, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x1c, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x4c, 0x61, 0x75, 0x6e, 0x63, 0x68,
+	0x65, 0x72, 0x42, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x73, 0x12,
+	0x38, 0x0a, 0x18, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x5f, 0x75, 0x6e, 0x74, 0x61, 0x69,
+	0x6e, 0x74, 0x65, 0x64, 0x5f, 0x6b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x16, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x55, 0x6e, 0x74, 0x61, 0x69, 0x6e,
+	0x74, 0x65, 0x64, 0x4b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x12, 0x30, 0x0a, 0x14, 0x61, 0x6c, 0x6c,
+	0x6f, 0x77, 0x65, 0x64, 0x5f, 0x6b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x5f, 0x74, 0x61, 0x69, 0x6e,
+	0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x12, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64,
+	0x4b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x54, 0x61, 0x69, 0x6e, 0x74, 0x22, 0xdf, 0x01, 0x0a, 0x06,
+	0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x32, 0x0a, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f,
+	0x72, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73,
+	0x74, 0x2e, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
+	0x52, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x12, 0x29, 0x0a, 0x05, 0x63, 0x6c,
+	0x6f, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x74, 0x74, 0x65,
+	0x73, 0x74, 0x2e, 0x43, 0x6c, 0x6f, 0x63, 0x6b, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x05,
+	0x63, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x2c, 0x0a, 0x06, 0x6b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x4b,
+	0x65, 0x72, 0x6e, 0x65, 0x6c, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x06, 0x6b, 0x65, 0x72,
+	0x6e, 0x65, 0x6c, 0x12, 0x23, 0x0a, 0x03, 0x69, 0x6d, 0x61, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x11, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x49, 0x6d, 0x61, 0x50, 0x6f, 0x6c,
+	0x69, 0x63, 0x79, 0x52, 0x03, 0x69, 0x6d, 0x61, 0x12, 0x23, 0x0a, 0x03, 0x63, 0x6f, 0x73, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x43,
+	0x6f, 0x73, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x03, 0x63, 0x6f, 0x73, 0x2a, 0x62, 0x0a,
+	0x19, 0x47, 0x43, 0x45, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c,
+	0x54, 0x65, 0x63, 0x68, 0x6e, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x12, 0x08, 0x0a, 0x04, 0x4e, 0x4f,
+	0x4e, 0x45, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x41, 0x4d, 0x44, 0x5f, 0x53, 0x45, 0x56, 0x10,
+	0x01, 0x12, 0x0e, 0x0a, 0x0a, 0x41, 0x4d, 0x44, 0x5f, 0x53, 0x45, 0x56, 0x5f, 0x45, 0x53, 0x10,
+	0x02, 0x12, 0x0f, 0x0a, 0x0b, 0x41, 0x4d, 0x44, 0x5f, 0x53, 0x45, 0x56, 0x5f, 0x53, 0x4e, 0x50,
+	0x10, 0x04, 0x12, 0x0d, 0x0a, 0x09, 0x49, 0x4e, 0x54, 0x45, 0x4c, 0x5f, 0x54, 0x44, 0x58, 0x10,
+	0x05, 0x2a, 0x59, 0x0a, 0x0e, 0x4b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x4c, 0x6f, 0x63, 0x6b, 0x64,
+	0x6f, 0x77, 0x6e, 0x12, 0x11, 0x0a, 0x0d, 0x4c, 0x4f, 0x43, 0x4b, 0x44, 0x4f, 0x57, 0x4e, 0x5f,
+	0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x00, 0x12, 0x16, 0x0a, 0x12, 0x4c, 0x4f, 0x43, 0x4b, 0x44, 0x4f,
+	0x57, 0x4e, 0x5f, 0x49, 0x4e, 0x54, 0x45, 0x47, 0x52, 0x49, 0x54, 0x59, 0x10, 0x01, 0x12, 0x1c,
+	0x0a, 0x18, 0x4c, 0x4f, 0x43, 0x4b, 0x44, 0x4f, 0x57, 0x4e, 0x5f, 0x43, 0x4f, 0x4e, 0x46, 0x49,
+	0x44, 0x45, 0x4e, 0x54, 0x49, 0x41, 0x4c, 0x49, 0x54, 0x59, 0x10, 0x02, 0x2a, 0x62, 0x0a, 0x14,
+	0x57, 0x65, 0x6c, 0x6c, 0x4b, 0x6e, 0x6f, 0x77, 0x6e, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69,
+	0x63, 0x61, 0x74, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10,
+	0x00, 0x12, 0x1c, 0x0a, 0x18, 0x4d, 0x53, 0x5f, 0x57, 0x49, 0x4e, 0x44, 0x4f, 0x57, 0x53, 0x5f,
+	0x50, 0x52, 0x4f, 0x44, 0x5f, 0x50, 0x43, 0x41, 0x5f, 0x32, 0x30, 0x31, 0x31, 0x10, 0x01, 0x12,
+	0x1f, 0x0a, 0x1b, 0x4d, 0x53, 0x5f, 0x54, 0x48, 0x49, 0x52, 0x44, 0x5f, 0x50, 0x41, 0x52, 0x54,
+	0x59, 0x5f, 0x55, 0x45, 0x46, 0x49, 0x5f, 0x43, 0x41, 0x5f, 0x32, 0x30, 0x31, 0x31, 0x10, 0x02,
+	0x2a, 0x35, 0x0a, 0x0d, 0x52, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x12, 0x0a, 0x0a, 0x06, 0x41, 0x6c, 0x77, 0x61, 0x79, 0x73, 0x10, 0x00, 0x12, 0x0d, 0x0a,
+	0x09, 0x4f, 0x6e, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05,
+	0x4e, 0x65, 0x76, 0x65, 0x72, 0x10, 0x02, 0x2a, 0x82, 0x01, 0x0a, 0x12, 0x43, 0x65, 0x6c, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x24,
+	0x0a, 0x20, 0x43, 0x45, 0x4c, 0x5f, 0x54, 0x49, 0x4d, 0x45, 0x53, 0x54, 0x41, 0x4d, 0x50, 0x5f,
+	0x53, 0x4f, 0x55, 0x52, 0x43, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49,
+	0x45, 0x44, 0x10, 0x00, 0x12, 0x22, 0x0a, 0x1e, 0x43, 0x45, 0x4c, 0x5f, 0x54, 0x49, 0x4d, 0x45,
+	0x53, 0x54, 0x41, 0x4d, 0x50, 0x5f, 0x53, 0x4f, 0x55, 0x52, 0x43, 0x45, 0x5f, 0x54, 0x50, 0x4d,
+	0x5f, 0x43, 0x4c, 0x4f, 0x43, 0x4b, 0x10, 0x01, 0x12, 0x22, 0x0a, 0x1e, 0x43, 0x45, 0x4c, 0x5f,
+	0x54, 0x49, 0x4d, 0x45, 0x53, 0x54, 0x41, 0x4d, 0x50, 0x5f, 0x53, 0x4f, 0x55, 0x52, 0x43, 0x45,
+	0x5f, 0x4d, 0x4f, 0x4e, 0x4f, 0x54, 0x4f, 0x4e, 0x49, 0x43, 0x10, 0x02, 0x42, 0x2d, 0x5a, 0x2b,
+	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2f, 0x67, 0x6f, 0x2d, 0x74, 0x70, 0x6d, 0x2d, 0x74, 0x6f, 0x6f, 0x6c, 0x73, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1661,66 +4813,139 @@ func file_attest_proto_rawDescGZIP() []byte {
 	return file_attest_proto_rawDescData
 }
 
-var file_attest_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_attest_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_attest_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_attest_proto_msgTypes = make([]protoimpl.MessageInfo, 48)
 var file_attest_proto_goTypes = []interface{}{
-	(GCEConfidentialTechnology)(0), // 0: attest.GCEConfidentialTechnology
-	(WellKnownCertificate)(0),      // 1: attest.WellKnownCertificate
-	(RestartPolicy)(0),             // 2: attest.RestartPolicy
-	(*GCEInstanceInfo)(nil),        // 3: attest.GCEInstanceInfo
-	(*Attestation)(nil),            // 4: attest.Attestation
-	(*PlatformState)(nil),          // 5: attest.PlatformState
-	(*GrubFile)(nil),               // 6: attest.GrubFile
-	(*GrubState)(nil),              // 7: attest.GrubState
-	(*LinuxKernelState)(nil),       // 8: attest.LinuxKernelState
-	(*Event)(nil),                  // 9: attest.Event
-	(*Certificate)(nil),            // 10: attest.Certificate
-	(*Database)(nil),               // 11: attest.Database
-	(*SecureBootState)(nil),        // 12: attest.SecureBootState
-	(*ContainerState)(nil),         // 13: attest.ContainerState
-	(*SemanticVersion)(nil),        // 14: attest.SemanticVersion
-	(*AttestedCosState)(nil),       // 15: attest.AttestedCosState
-	(*MachineState)(nil),           // 16: attest.MachineState
-	(*PlatformPolicy)(nil),         // 17: attest.PlatformPolicy
-	(*Policy)(nil),                 // 18: attest.Policy
-	nil,                            // 19: attest.ContainerState.EnvVarsEntry
-	nil,                            // 20: attest.ContainerState.OverriddenEnvVarsEntry
-	(*tpm.Quote)(nil),              // 21: tpm.Quote
-	(*sevsnp.Attestation)(nil),     // 22: sevsnp.Attestation
-	(tpm.HashAlgo)(0),              // 23: tpm.HashAlgo
+	(GCEConfidentialTechnology)(0),     // 0: attest.GCEConfidentialTechnology
+	(KernelLockdown)(0),                // 1: attest.KernelLockdown
+	(WellKnownCertificate)(0),          // 2: attest.WellKnownCertificate
+	(RestartPolicy)(0),                 // 3: attest.RestartPolicy
+	(CelTimestampSource)(0),            // 4: attest.CelTimestampSource
+	(*GCEInstanceInfo)(nil),            // 5: attest.GCEInstanceInfo
+	(*Attestation)(nil),                // 6: attest.Attestation
+	(*GceIdentity)(nil),                // 7: attest.GceIdentity
+	(*ShieldedVmIdentity)(nil),         // 8: attest.ShieldedVmIdentity
+	(*AttestationSelfCheck)(nil),       // 9: attest.AttestationSelfCheck
+	(*TdxAttestation)(nil),             // 10: attest.TdxAttestation
+	(*TeeEvidence)(nil),                // 11: attest.TeeEvidence
+	(*AttestationEnvelope)(nil),        // 12: attest.AttestationEnvelope
+	(*PlatformState)(nil),              // 13: attest.PlatformState
+	(*GrubFile)(nil),                   // 14: attest.GrubFile
+	(*GrubState)(nil),                  // 15: attest.GrubState
+	(*LinuxKernelState)(nil),           // 16: attest.LinuxKernelState
+	(*DmVerityState)(nil),              // 17: attest.DmVerityState
+	(*Event)(nil),                      // 18: attest.Event
+	(*Certificate)(nil),                // 19: attest.Certificate
+	(*Database)(nil),                   // 20: attest.Database
+	(*SecureBootState)(nil),            // 21: attest.SecureBootState
+	(*WorkloadOutput)(nil),             // 22: attest.WorkloadOutput
+	(*ContainerState)(nil),             // 23: attest.ContainerState
+	(*CDIDevice)(nil),                  // 24: attest.CDIDevice
+	(*Mount)(nil),                      // 25: attest.Mount
+	(*SemanticVersion)(nil),            // 26: attest.SemanticVersion
+	(*CosEventTimestamp)(nil),          // 27: attest.CosEventTimestamp
+	(*CosEvent)(nil),                   // 28: attest.CosEvent
+	(*AttestedCosState)(nil),           // 29: attest.AttestedCosState
+	(*KernelLogState)(nil),             // 30: attest.KernelLogState
+	(*WorkloadState)(nil),              // 31: attest.WorkloadState
+	(*WorkloadBinary)(nil),             // 32: attest.WorkloadBinary
+	(*KernelModule)(nil),               // 33: attest.KernelModule
+	(*TPMClockInfo)(nil),               // 34: attest.TPMClockInfo
+	(*ImaMeasurement)(nil),             // 35: attest.ImaMeasurement
+	(*ImaState)(nil),                   // 36: attest.ImaState
+	(*MachineState)(nil),               // 37: attest.MachineState
+	(*ConfidentialComputingState)(nil), // 38: attest.ConfidentialComputingState
+	(*VerificationCheck)(nil),          // 39: attest.VerificationCheck
+	(*VerificationReport)(nil),         // 40: attest.VerificationReport
+	(*PlatformPolicy)(nil),             // 41: attest.PlatformPolicy
+	(*GCEInstancePolicy)(nil),          // 42: attest.GCEInstancePolicy
+	(*ClockPolicy)(nil),                // 43: attest.ClockPolicy
+	(*KernelPolicy)(nil),               // 44: attest.KernelPolicy
+	(*ImaRule)(nil),                    // 45: attest.ImaRule
+	(*ImaPolicy)(nil),                  // 46: attest.ImaPolicy
+	(*ImaViolation)(nil),               // 47: attest.ImaViolation
+	(*CosPolicy)(nil),                  // 48: attest.CosPolicy
+	(*Policy)(nil),                     // 49: attest.Policy
+	nil,                                // 50: attest.ContainerState.EnvVarsEntry
+	nil,                                // 51: attest.ContainerState.OverriddenEnvVarsEntry
+	nil,                                // 52: attest.VerificationCheck.InputDigestsEntry
+	(*tpm.Quote)(nil),                  // 53: tpm.Quote
+	(*sevsnp.Attestation)(nil),         // 54: sevsnp.Attestation
+	(tpm.HashAlgo)(0),                  // 55: tpm.HashAlgo
 }
 var file_attest_proto_depIdxs = []int32{
-	21, // 0: attest.Attestation.quotes:type_name -> tpm.Quote
-	3,  // 1: attest.Attestation.instance_info:type_name -> attest.GCEInstanceInfo
-	22, // 2: attest.Attestation.sev_snp_attestation:type_name -> sevsnp.Attestation
-	0,  // 3: attest.PlatformState.technology:type_name -> attest.GCEConfidentialTechnology
-	3,  // 4: attest.PlatformState.instance_info:type_name -> attest.GCEInstanceInfo
-	6,  // 5: attest.GrubState.files:type_name -> attest.GrubFile
-	1,  // 6: attest.Certificate.well_known:type_name -> attest.WellKnownCertificate
-	10, // 7: attest.Database.certs:type_name -> attest.Certificate
-	11, // 8: attest.SecureBootState.db:type_name -> attest.Database
-	11, // 9: attest.SecureBootState.dbx:type_name -> attest.Database
-	11, // 10: attest.SecureBootState.authority:type_name -> attest.Database
-	2,  // 11: attest.ContainerState.restart_policy:type_name -> attest.RestartPolicy
-	19, // 12: attest.ContainerState.env_vars:type_name -> attest.ContainerState.EnvVarsEntry
-	20, // 13: attest.ContainerState.overridden_env_vars:type_name -> attest.ContainerState.OverriddenEnvVarsEntry
-	13, // 14: attest.AttestedCosState.container:type_name -> attest.ContainerState
-	14, // 15: attest.AttestedCosState.cos_version:type_name -> attest.SemanticVersion
-	14, // 16: attest.AttestedCosState.launcher_version:type_name -> attest.SemanticVersion
-	5,  // 17: attest.MachineState.platform:type_name -> attest.PlatformState
-	12, // 18: attest.MachineState.secure_boot:type_name -> attest.SecureBootState
-	9,  // 19: attest.MachineState.raw_events:type_name -> attest.Event
-	23, // 20: attest.MachineState.hash:type_name -> tpm.HashAlgo
-	7,  // 21: attest.MachineState.grub:type_name -> attest.GrubState
-	8,  // 22: attest.MachineState.linux_kernel:type_name -> attest.LinuxKernelState
-	15, // 23: attest.MachineState.cos:type_name -> attest.AttestedCosState
-	0,  // 24: attest.PlatformPolicy.minimum_technology:type_name -> attest.GCEConfidentialTechnology
-	17, // 25: attest.Policy.platform:type_name -> attest.PlatformPolicy
-	26, // [26:26] is the sub-list for method output_type
-	26, // [26:26] is the sub-list for method input_type
-	26, // [26:26] is the sub-list for extension type_name
-	26, // [26:26] is the sub-list for extension extendee
-	0,  // [0:26] is the sub-list for field type_name
+	53, // 0: attest.Attestation.quotes:type_name -> tpm.Quote
+	5,  // 1: attest.Attestation.instance_info:type_name -> attest.GCEInstanceInfo
+	54, // 2: attest.Attestation.sev_snp_attestation:type_name -> sevsnp.Attestation
+	9,  // 3: attest.Attestation.self_check:type_name -> attest.AttestationSelfCheck
+	7,  // 4: attest.Attestation.gce_identity:type_name -> attest.GceIdentity
+	8,  // 5: attest.GceIdentity.shielded_vm_identity:type_name -> attest.ShieldedVmIdentity
+	54, // 6: attest.TeeEvidence.sev_snp_attestation:type_name -> sevsnp.Attestation
+	10, // 7: attest.TeeEvidence.tdx_attestation:type_name -> attest.TdxAttestation
+	6,  // 8: attest.AttestationEnvelope.attestation:type_name -> attest.Attestation
+	11, // 9: attest.AttestationEnvelope.tee_evidence:type_name -> attest.TeeEvidence
+	0,  // 10: attest.PlatformState.technology:type_name -> attest.GCEConfidentialTechnology
+	5,  // 11: attest.PlatformState.instance_info:type_name -> attest.GCEInstanceInfo
+	14, // 12: attest.GrubState.files:type_name -> attest.GrubFile
+	1,  // 13: attest.LinuxKernelState.lockdown:type_name -> attest.KernelLockdown
+	17, // 14: attest.LinuxKernelState.dm_verity:type_name -> attest.DmVerityState
+	2,  // 15: attest.Certificate.well_known:type_name -> attest.WellKnownCertificate
+	19, // 16: attest.Database.certs:type_name -> attest.Certificate
+	20, // 17: attest.SecureBootState.db:type_name -> attest.Database
+	20, // 18: attest.SecureBootState.dbx:type_name -> attest.Database
+	20, // 19: attest.SecureBootState.authority:type_name -> attest.Database
+	3,  // 20: attest.ContainerState.restart_policy:type_name -> attest.RestartPolicy
+	50, // 21: attest.ContainerState.env_vars:type_name -> attest.ContainerState.EnvVarsEntry
+	51, // 22: attest.ContainerState.overridden_env_vars:type_name -> attest.ContainerState.OverriddenEnvVarsEntry
+	25, // 23: attest.ContainerState.mounts:type_name -> attest.Mount
+	22, // 24: attest.ContainerState.workload_output:type_name -> attest.WorkloadOutput
+	24, // 25: attest.ContainerState.cdi_devices:type_name -> attest.CDIDevice
+	23, // 26: attest.AttestedCosState.container:type_name -> attest.ContainerState
+	26, // 27: attest.AttestedCosState.cos_version:type_name -> attest.SemanticVersion
+	26, // 28: attest.AttestedCosState.launcher_version:type_name -> attest.SemanticVersion
+	4,  // 29: attest.AttestedCosState.timestamp_source:type_name -> attest.CelTimestampSource
+	27, // 30: attest.AttestedCosState.event_timestamps:type_name -> attest.CosEventTimestamp
+	55, // 31: attest.AttestedCosState.cel_hash_algos:type_name -> tpm.HashAlgo
+	28, // 32: attest.AttestedCosState.unknown_events:type_name -> attest.CosEvent
+	33, // 33: attest.AttestedCosState.kernel_modules:type_name -> attest.KernelModule
+	31, // 34: attest.AttestedCosState.workload:type_name -> attest.WorkloadState
+	30, // 35: attest.AttestedCosState.kernel_log:type_name -> attest.KernelLogState
+	32, // 36: attest.WorkloadState.binaries:type_name -> attest.WorkloadBinary
+	35, // 37: attest.ImaState.measurements:type_name -> attest.ImaMeasurement
+	35, // 38: attest.ImaState.unverified_measurements:type_name -> attest.ImaMeasurement
+	13, // 39: attest.MachineState.platform:type_name -> attest.PlatformState
+	21, // 40: attest.MachineState.secure_boot:type_name -> attest.SecureBootState
+	18, // 41: attest.MachineState.raw_events:type_name -> attest.Event
+	55, // 42: attest.MachineState.hash:type_name -> tpm.HashAlgo
+	15, // 43: attest.MachineState.grub:type_name -> attest.GrubState
+	16, // 44: attest.MachineState.linux_kernel:type_name -> attest.LinuxKernelState
+	29, // 45: attest.MachineState.cos:type_name -> attest.AttestedCosState
+	34, // 46: attest.MachineState.clock_info:type_name -> attest.TPMClockInfo
+	36, // 47: attest.MachineState.ima:type_name -> attest.ImaState
+	38, // 48: attest.MachineState.confidential_computing:type_name -> attest.ConfidentialComputingState
+	0,  // 49: attest.ConfidentialComputingState.technology:type_name -> attest.GCEConfidentialTechnology
+	55, // 50: attest.VerificationCheck.quote_hash:type_name -> tpm.HashAlgo
+	52, // 51: attest.VerificationCheck.input_digests:type_name -> attest.VerificationCheck.InputDigestsEntry
+	39, // 52: attest.VerificationReport.checks:type_name -> attest.VerificationCheck
+	55, // 53: attest.VerificationReport.verified_quote_hash:type_name -> tpm.HashAlgo
+	0,  // 54: attest.PlatformPolicy.minimum_technology:type_name -> attest.GCEConfidentialTechnology
+	42, // 55: attest.PlatformPolicy.instance:type_name -> attest.GCEInstancePolicy
+	34, // 56: attest.ClockPolicy.reference:type_name -> attest.TPMClockInfo
+	1,  // 57: attest.KernelPolicy.minimum_lockdown:type_name -> attest.KernelLockdown
+	45, // 58: attest.ImaPolicy.allow:type_name -> attest.ImaRule
+	45, // 59: attest.ImaPolicy.deny:type_name -> attest.ImaRule
+	35, // 60: attest.ImaViolation.measurement:type_name -> attest.ImaMeasurement
+	26, // 61: attest.CosPolicy.minimum_launcher_version:type_name -> attest.SemanticVersion
+	41, // 62: attest.Policy.platform:type_name -> attest.PlatformPolicy
+	43, // 63: attest.Policy.clock:type_name -> attest.ClockPolicy
+	44, // 64: attest.Policy.kernel:type_name -> attest.KernelPolicy
+	46, // 65: attest.Policy.ima:type_name -> attest.ImaPolicy
+	48, // 66: attest.Policy.cos:type_name -> attest.CosPolicy
+	67, // [67:67] is the sub-list for method output_type
+	67, // [67:67] is the sub-list for method input_type
+	67, // [67:67] is the sub-list for extension type_name
+	67, // [67:67] is the sub-list for extension extendee
+	0,  // [0:67] is the sub-list for field type_name
 }
 
 func init() { file_attest_proto_init() }
@@ -1741,8 +4966,80 @@ func file_attest_proto_init() {
 				return nil
 			}
 		}
-		file_attest_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Attestation); i {
+		file_attest_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Attestation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GceIdentity); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ShieldedVmIdentity); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AttestationSelfCheck); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TdxAttestation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TeeEvidence); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AttestationEnvelope); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1753,7 +5050,7 @@ func file_attest_proto_init() {
 				return nil
 			}
 		}
-		file_attest_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+		file_attest_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*PlatformState); i {
 			case 0:
 				return &v.state
@@ -1765,7 +5062,7 @@ func file_attest_proto_init() {
 				return nil
 			}
 		}
-		file_attest_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+		file_attest_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GrubFile); i {
 			case 0:
 				return &v.state
@@ -1777,7 +5074,7 @@ func file_attest_proto_init() {
 				return nil
 			}
 		}
-		file_attest_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+		file_attest_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GrubState); i {
 			case 0:
 				return &v.state
@@ -1789,7 +5086,7 @@ func file_attest_proto_init() {
 				return nil
 			}
 		}
-		file_attest_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+		file_attest_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*LinuxKernelState); i {
 			case 0:
 				return &v.state
@@ -1801,7 +5098,19 @@ func file_attest_proto_init() {
 				return nil
 			}
 		}
-		file_attest_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+		file_attest_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DmVerityState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Event); i {
 			case 0:
 				return &v.state
@@ -1813,7 +5122,7 @@ func file_attest_proto_init() {
 				return nil
 			}
 		}
-		file_attest_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+		file_attest_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Certificate); i {
 			case 0:
 				return &v.state
@@ -1825,7 +5134,7 @@ func file_attest_proto_init() {
 				return nil
 			}
 		}
-		file_attest_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+		file_attest_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Database); i {
 			case 0:
 				return &v.state
@@ -1837,7 +5146,7 @@ func file_attest_proto_init() {
 				return nil
 			}
 		}
-		file_attest_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+		file_attest_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*SecureBootState); i {
 			case 0:
 				return &v.state
@@ -1849,7 +5158,19 @@ func file_attest_proto_init() {
 				return nil
 			}
 		}
-		file_attest_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+		file_attest_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WorkloadOutput); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ContainerState); i {
 			case 0:
 				return &v.state
@@ -1861,7 +5182,31 @@ func file_attest_proto_init() {
 				return nil
 			}
 		}
-		file_attest_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+		file_attest_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CDIDevice); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Mount); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*SemanticVersion); i {
 			case 0:
 				return &v.state
@@ -1873,7 +5218,31 @@ func file_attest_proto_init() {
 				return nil
 			}
 		}
-		file_attest_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+		file_attest_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CosEventTimestamp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CosEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*AttestedCosState); i {
 			case 0:
 				return &v.state
@@ -1885,7 +5254,91 @@ func file_attest_proto_init() {
 				return nil
 			}
 		}
-		file_attest_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+		file_attest_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KernelLogState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WorkloadState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WorkloadBinary); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KernelModule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TPMClockInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ImaMeasurement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ImaState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*MachineState); i {
 			case 0:
 				return &v.state
@@ -1897,7 +5350,43 @@ func file_attest_proto_init() {
 				return nil
 			}
 		}
-		file_attest_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+		file_attest_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ConfidentialComputingState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerificationCheck); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerificationReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*PlatformPolicy); i {
 			case 0:
 				return &v.state
@@ -1909,7 +5398,91 @@ func file_attest_proto_init() {
 				return nil
 			}
 		}
-		file_attest_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+		file_attest_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GCEInstancePolicy); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClockPolicy); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KernelPolicy); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[40].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ImaRule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[41].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ImaPolicy); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[42].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ImaViolation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[43].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CosPolicy); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[44].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Policy); i {
 			case 0:
 				return &v.state
@@ -1925,11 +5498,15 @@ func file_attest_proto_init() {
 	file_attest_proto_msgTypes[1].OneofWrappers = []interface{}{
 		(*Attestation_SevSnpAttestation)(nil),
 	}
-	file_attest_proto_msgTypes[2].OneofWrappers = []interface{}{
+	file_attest_proto_msgTypes[6].OneofWrappers = []interface{}{
+		(*TeeEvidence_SevSnpAttestation)(nil),
+		(*TeeEvidence_TdxAttestation)(nil),
+	}
+	file_attest_proto_msgTypes[8].OneofWrappers = []interface{}{
 		(*PlatformState_ScrtmVersionId)(nil),
 		(*PlatformState_GceVersion)(nil),
 	}
-	file_attest_proto_msgTypes[7].OneofWrappers = []interface{}{
+	file_attest_proto_msgTypes[14].OneofWrappers = []interface{}{
 		(*Certificate_Der)(nil),
 		(*Certificate_WellKnown)(nil),
 	}
@@ -1938,8 +5515,8 @@ func file_attest_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_attest_proto_rawDesc,
-			NumEnums:      3,
-			NumMessages:   18,
+			NumEnums:      5,
+			NumMessages:   48,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
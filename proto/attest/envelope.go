@@ -0,0 +1,5 @@
+package attest
+
+// EnvelopeVersion is the version of the AttestationEnvelope format produced
+// and understood by this library.
+const EnvelopeVersion uint32 = 1
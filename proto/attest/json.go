@@ -0,0 +1,21 @@
+package attest
+
+import "github.com/google/go-tpm-tools/proto"
+
+// AttestationFromJSON parses an Attestation encoded with proto.MarshalJSON.
+func AttestationFromJSON(data []byte) (*Attestation, error) {
+	attestation := &Attestation{}
+	if err := proto.UnmarshalJSON(data, attestation); err != nil {
+		return nil, err
+	}
+	return attestation, nil
+}
+
+// MachineStateFromJSON parses a MachineState encoded with proto.MarshalJSON.
+func MachineStateFromJSON(data []byte) (*MachineState, error) {
+	state := &MachineState{}
+	if err := proto.UnmarshalJSON(data, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
@@ -20,6 +20,10 @@
 //
 //	go install google.golang.org/protobuf/cmd/protoc-gen-go
 //
+// The gRPC service of verifier.proto also requires "protoc-gen-go-grpc":
+//
+//	go install google.golang.org/grpc/cmd/protoc-gen-go-grpc
+//
 // If you see a 'protoc-gen-go: program not found or is not executable' error
 // for the 'go generate' command, run the following:
 //
@@ -28,4 +32,5 @@
 package proto
 
 //go:generate ./gen_attest.sh
+//go:generate ./gen_verifier.sh
 //go:generate protoc --go_out=. --go_opt=module=github.com/google/go-tpm-tools/proto tpm.proto
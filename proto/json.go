@@ -0,0 +1,39 @@
+package proto
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// MarshalJSON returns the stable JSON encoding of a message. It is the
+// canonical protojson encoding with all insignificant whitespace removed, so
+// the same message always produces the same bytes. In particular:
+//   - fields use their lowerCamelCase JSON names and appear in field number order
+//   - fields set to their default values are omitted
+//   - bytes fields use standard base64 encoding with padding (RFC 4648 §4)
+//   - enums are encoded by name, e.g. "SHA256" for a tpm.HashAlgo
+//   - 64-bit integers are encoded as decimal strings
+//   - map keys, like the PCR indexes of a tpm.PCRs, are decimal strings
+func MarshalJSON(m proto.Message) ([]byte, error) {
+	data, err := protojson.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	// protojson randomly inserts whitespace to discourage byte comparisons.
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON parses the JSON encoding of a message into m. It accepts any
+// valid protojson input, including the original proto field names and URL-safe
+// base64 for bytes fields. Unknown fields are ignored, so messages produced by
+// newer versions of this library can still be parsed.
+func UnmarshalJSON(data []byte, m proto.Message) error {
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, m)
+}
@@ -0,0 +1,138 @@
+package proto_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/proto"
+	"github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/proto/tpm"
+	gproto "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+var testPCRs = &tpm.PCRs{
+	Hash: tpm.HashAlgo_SHA256,
+	Pcrs: map[uint32][]byte{
+		0:  {0x00, 0x01, 0x02},
+		7:  {0xfb, 0xff},
+		10: {},
+	},
+}
+
+var testQuote = &tpm.Quote{
+	Quote:  []byte("quote"),
+	RawSig: []byte{0xfb, 0xef, 0xff},
+	Pcrs:   testPCRs,
+}
+
+var testAttestation = &attest.Attestation{
+	AkPub:  []byte("ak"),
+	Quotes: []*tpm.Quote{testQuote},
+	InstanceInfo: &attest.GCEInstanceInfo{
+		Zone:       "us-central1-a",
+		ProjectId:  "test-project",
+		InstanceId: 1234567890123456789,
+	},
+	IntermediateCerts: [][]byte{[]byte("cert1"), []byte("cert2")},
+}
+
+var testMachineState = &attest.MachineState{
+	Platform: &attest.PlatformState{
+		Firmware:   &attest.PlatformState_GceVersion{GceVersion: 1},
+		Technology: attest.GCEConfidentialTechnology_AMD_SEV,
+	},
+	SecureBoot: &attest.SecureBootState{Enabled: true},
+	Hash:       tpm.HashAlgo_SHA256,
+	Cos: &attest.AttestedCosState{
+		Container: &attest.ContainerState{
+			ImageReference: "docker.io/library/hello-world:latest",
+			Args:           []string{"/hello"},
+			EnvVars:        map[string]string{"B": "2", "A": "1"},
+		},
+	},
+}
+
+func TestMarshalJSONGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  gproto.Message
+		want string
+	}{
+		{"PCRs", testPCRs, `{"hash":"SHA256","pcrs":{"0":"AAEC","7":"+/8=","10":""}}`},
+		{"Quote", testQuote, `{"quote":"cXVvdGU=","rawSig":"++//","pcrs":{"hash":"SHA256","pcrs":{"0":"AAEC","7":"+/8=","10":""}}}`},
+		{"Attestation", testAttestation, `{"akPub":"YWs=","quotes":[{"quote":"cXVvdGU=","rawSig":"++//","pcrs":{"hash":"SHA256","pcrs":{"0":"AAEC","7":"+/8=","10":""}}}],"instanceInfo":{"zone":"us-central1-a","projectId":"test-project","instanceId":"1234567890123456789"},"intermediateCerts":["Y2VydDE=","Y2VydDI="]}`},
+		{"MachineState", testMachineState, `{"platform":{"gceVersion":1,"technology":"AMD_SEV"},"secureBoot":{"enabled":true},"hash":"SHA256","cos":{"container":{"imageReference":"docker.io/library/hello-world:latest","args":["/hello"],"envVars":{"A":"1","B":"2"}}}}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := proto.MarshalJSON(tc.msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("MarshalJSON() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	data, err := proto.MarshalJSON(testAttestation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attestation, err := attest.AttestationFromJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(testAttestation, attestation, protocmp.Transform()); diff != "" {
+		t.Errorf("Attestation round trip (-want +got):\n%s", diff)
+	}
+
+	data, err = proto.MarshalJSON(testMachineState)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err := attest.MachineStateFromJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(testMachineState, state, protocmp.Transform()); diff != "" {
+		t.Errorf("MachineState round trip (-want +got):\n%s", diff)
+	}
+
+	data, err = proto.MarshalJSON(testQuote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quote, err := tpm.QuoteFromJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(testQuote, quote, protocmp.Transform()); diff != "" {
+		t.Errorf("Quote round trip (-want +got):\n%s", diff)
+	}
+}
+
+func TestUnmarshalJSONAlternateEncodings(t *testing.T) {
+	// Proto field names, URL-safe base64, numeric enums and unknown fields.
+	pcrs, err := tpm.PCRsFromJSON([]byte(`{"hash":11,"pcrs":{"0":"AAEC","7":"-_8","10":""},"unknown_field":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(testPCRs, pcrs, protocmp.Transform()); diff != "" {
+		t.Errorf("PCRsFromJSON (-want +got):\n%s", diff)
+	}
+	quote, err := tpm.QuoteFromJSON([]byte(`{"quote":"cXVvdGU","raw_sig":"--__","pcrs":{"hash":"SHA256","pcrs":{"0":"AAEC","7":"+/8=","10":""}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(testQuote, quote, protocmp.Transform()); diff != "" {
+		t.Errorf("QuoteFromJSON (-want +got):\n%s", diff)
+	}
+
+	if _, err := attest.AttestationFromJSON([]byte(`{"akPub":"not base64!"}`)); err == nil {
+		t.Error("AttestationFromJSON succeeded with invalid base64")
+	}
+}
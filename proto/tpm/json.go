@@ -0,0 +1,21 @@
+package tpm
+
+import "github.com/google/go-tpm-tools/proto"
+
+// QuoteFromJSON parses a Quote encoded with proto.MarshalJSON.
+func QuoteFromJSON(data []byte) (*Quote, error) {
+	quote := &Quote{}
+	if err := proto.UnmarshalJSON(data, quote); err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
+
+// PCRsFromJSON parses PCRs encoded with proto.MarshalJSON.
+func PCRsFromJSON(data []byte) (*PCRs, error) {
+	pcrs := &PCRs{}
+	if err := proto.UnmarshalJSON(data, pcrs); err != nil {
+		return nil, err
+	}
+	return pcrs, nil
+}
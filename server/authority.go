@@ -0,0 +1,106 @@
+package server
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal"
+	pb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/tpm2"
+)
+
+// SignPolicyAuthorization is used by the authority of objects sealed with
+// client.SealOpts.Authority, or of keys created by client.NewAuthorizedKey, to
+// authorize a single use of them. The authorization is only valid for the
+// session of nonceTPM, and for the policyRef of the object. A non-zero expiration limits the lifetime of the authorization to
+// that many seconds after the session started; see TPM2_PolicySigned in Part 3
+// of the spec for negative values.
+//
+// The signer must be the authority key passed to the client.
+func SignPolicyAuthorization(signer crypto.Signer, nonceTPM, policyRef []byte, expiration int32) (*pb.PolicyAuthorization, error) {
+	// aHash = H(nonceTPM || expiration || cpHashA || policyRef), with an empty
+	// cpHashA as the authorization is not bound to a command.
+	hash := client.AuthorityHashAlg.New()
+	hash.Write(nonceTPM)
+	binary.Write(hash, binary.BigEndian, expiration)
+	hash.Write(policyRef)
+	aHash := hash.Sum(nil)
+
+	encoded, err := signTPM(signer, aHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign the authorization: %w", err)
+	}
+	return &pb.PolicyAuthorization{
+		NonceTpm:   nonceTPM,
+		PolicyRef:  policyRef,
+		Expiration: expiration,
+		Signature:  encoded,
+	}, nil
+}
+
+// SignPolicyApproval is used by the authority of objects sealed with
+// client.SealOpts.PolicyAuthority to approve unsealing them while the PCRs
+// have the values of pcrs, e.g. the values expected after an update. The
+// approval is for the policyRef of the sealed object, and does not expire.
+//
+// The signer must be the key passed as client.SealOpts.PolicyAuthority.
+func SignPolicyApproval(signer crypto.Signer, pcrs *pb.PCRs, policyRef []byte) (*pb.PolicyApproval, error) {
+	if len(pcrs.GetPcrs()) == 0 {
+		return nil, errors.New("no PCRs to approve")
+	}
+	// aHash = H(approvedPolicy || policyRef), with the approvedPolicy of
+	// TPM2_PolicyPCR for the PCR values.
+	hash := client.AuthorityHashAlg.New()
+	hash.Write(internal.PCRSessionAuth(pcrs, client.SessionHashAlg))
+	hash.Write(policyRef)
+	encoded, err := signTPM(signer, hash.Sum(nil))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign the approval: %w", err)
+	}
+	return &pb.PolicyApproval{
+		Pcrs:      pcrs,
+		PolicyRef: policyRef,
+		Signature: encoded,
+	}, nil
+}
+
+// signTPM signs the digest of client.AuthorityHashAlg, and returns the
+// TPMT_SIGNATURE.
+func signTPM(signer crypto.Signer, digest []byte) ([]byte, error) {
+	hashAlg, err := tpm2.HashToAlgorithm(client.AuthorityHashAlg)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signer.Sign(rand.Reader, digest, client.AuthorityHashAlg)
+	if err != nil {
+		return nil, err
+	}
+	var tpmSig tpm2.Signature
+	switch signer.Public().(type) {
+	case *rsa.PublicKey:
+		tpmSig = tpm2.Signature{
+			Alg: tpm2.AlgRSASSA,
+			RSA: &tpm2.SignatureRSA{HashAlg: hashAlg, Signature: sig},
+		}
+	case *ecdsa.PublicKey:
+		var ecdsaSig struct{ R, S *big.Int }
+		if _, err := asn1.Unmarshal(sig, &ecdsaSig); err != nil {
+			return nil, fmt.Errorf("failed to parse the ECDSA signature: %w", err)
+		}
+		tpmSig = tpm2.Signature{
+			Alg: tpm2.AlgECDSA,
+			ECC: &tpm2.SignatureECC{HashAlg: hashAlg, R: ecdsaSig.R, S: ecdsaSig.S},
+		}
+	default:
+		return nil, fmt.Errorf("unsupported authority key type %T", signer.Public())
+	}
+	return tpmSig.Encode()
+}
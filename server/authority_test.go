@@ -0,0 +1,68 @@
+package server
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	pb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/tpm2"
+)
+
+func TestSignPolicyAuthorizationAndApproval(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	srk, err := client.StorageRootKeyRSA(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srk.Close()
+	rsaAuthority, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaAuthority, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := srk.Seal([]byte("secret"), client.SealOpts{
+		Authority:          rsaAuthority.Public(),
+		AuthorityPolicyRef: []byte("tenant"),
+		PolicyAuthority:    ecdsaAuthority.Public(),
+		PolicyAuthorityRef: []byte("release"),
+	})
+	if err != nil {
+		t.Fatalf("failed to seal: %v", err)
+	}
+	pcrs, err := client.ReadPCRs(rwc, tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{0, 7}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	approval, err := SignPolicyApproval(ecdsaAuthority, pcrs, []byte("release"))
+	if err != nil {
+		t.Fatalf("SignPolicyApproval() failed: %v", err)
+	}
+	unsealed, err := srk.Unseal(sealed, client.UnsealOpts{
+		Authorize: func(nonceTPM, policyRef []byte) (*pb.PolicyAuthorization, error) {
+			return SignPolicyAuthorization(rsaAuthority, nonceTPM, policyRef, 0)
+		},
+		PolicyApprovals: []*pb.PolicyApproval{approval},
+	})
+	if err != nil {
+		t.Fatalf("failed to unseal: %v", err)
+	}
+	if !bytes.Equal(unsealed, []byte("secret")) {
+		t.Errorf("unsealed (%v) not equal to secret", unsealed)
+	}
+
+	if _, err := SignPolicyApproval(ecdsaAuthority, &pb.PCRs{Hash: pb.HashAlgo_SHA256}, nil); err == nil {
+		t.Error("SignPolicyApproval() succeeded without PCRs")
+	}
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"crypto/x509"
+	"runtime"
+	"sync"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+)
+
+// BatchRequest is an attestation to verify with VerifyAttestations, and the
+// nonce it was made over.
+type BatchRequest struct {
+	Attestation *pb.Attestation
+	Nonce       []byte
+}
+
+// BatchResult is the result of verifying the attestation of a BatchRequest,
+// as returned by Pipeline.VerifyWithReport.
+type BatchResult struct {
+	MachineState *pb.MachineState
+	Report       *pb.VerificationReport
+	Err          error
+}
+
+// BatchSummary aggregates the results of VerifyAttestations.
+type BatchSummary struct {
+	Total    int
+	Verified int
+	// Failures counts the attestations which failed verification by the
+	// ErrorCode of their error, see ErrorCodeOf.
+	Failures map[ErrorCode]int
+}
+
+// BatchOpts configures VerifyAttestations.
+type BatchOpts struct {
+	// Parallelism is the maximum number of attestations verified
+	// concurrently. It defaults to runtime.GOMAXPROCS(0).
+	Parallelism int
+	// Pipeline verifies each attestation, and evaluates its Policy if set.
+	// The zero Pipeline verifies them like VerifyAttestation.
+	Pipeline Pipeline
+}
+
+// VerifyAttestations verifies many attestations concurrently, e.g. for the
+// compliance sweeps of a fleet, and returns their results in the order of the
+// requests with a summary of the results. Each attestation is verified with
+// opts and the nonce of its request. The pool of opts.TrustedRootCerts, and
+// the intermediate certificates of the attestations, which are usually shared
+// by the fleet, are only parsed once.
+//
+// Once ctx is done, the attestations not yet verified fail with ctx.Err().
+func VerifyAttestations(ctx context.Context, requests []BatchRequest, opts VerifyOpts, batchOpts BatchOpts) ([]BatchResult, BatchSummary) {
+	parallelism := batchOpts.Parallelism
+	if parallelism <= 0 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+	opts.certs = newCertCache(opts.TrustedRootCerts)
+
+	results := make([]BatchResult, len(requests))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism && w < len(requests); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					results[i].Err = err
+					continue
+				}
+				requestOpts := opts
+				requestOpts.Nonce = requests[i].Nonce
+				state, report, err := batchOpts.Pipeline.VerifyWithReport(requests[i].Attestation, requestOpts)
+				results[i] = BatchResult{MachineState: state, Report: report, Err: err}
+			}
+		}()
+	}
+	for i := range requests {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results, summarize(results)
+}
+
+func summarize(results []BatchResult) BatchSummary {
+	summary := BatchSummary{Total: len(results), Failures: make(map[ErrorCode]int)}
+	for _, result := range results {
+		if result.Err == nil {
+			summary.Verified++
+		} else {
+			summary.Failures[ErrorCodeOf(result.Err)]++
+		}
+	}
+	return summary
+}
+
+// certCache shares the trusted roots pool and the parsed intermediate
+// certificates between verifications. The methods of a nil *certCache do not
+// cache.
+type certCache struct {
+	roots *x509.CertPool
+
+	mu            sync.Mutex
+	intermediates map[string]*x509.Certificate
+}
+
+func newCertCache(roots []*x509.Certificate) *certCache {
+	return &certCache{roots: makePool(roots), intermediates: make(map[string]*x509.Certificate)}
+}
+
+// rootPool returns the pool of the trusted roots.
+func (c *certCache) rootPool(roots []*x509.Certificate) *x509.CertPool {
+	if c == nil {
+		return makePool(roots)
+	}
+	return c.roots
+}
+
+// parseIntermediates parses the DER certificates, reusing the certificates
+// already parsed.
+func (c *certCache) parseIntermediates(rawCerts [][]byte) ([]*x509.Certificate, error) {
+	if c == nil {
+		return parseCerts(rawCerts)
+	}
+	certs := make([]*x509.Certificate, len(rawCerts))
+	var missing [][]byte
+	var missingIndexes []int
+	c.mu.Lock()
+	for i, raw := range rawCerts {
+		if cert, ok := c.intermediates[string(raw)]; ok {
+			certs[i] = cert
+		} else {
+			missing = append(missing, raw)
+			missingIndexes = append(missingIndexes, i)
+		}
+	}
+	c.mu.Unlock()
+	if len(missing) == 0 {
+		return certs, nil
+	}
+
+	parsed, err := parseCerts(missing)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for j, cert := range parsed {
+		c.intermediates[string(missing[j])] = cert
+		certs[missingIndexes[j]] = cert
+	}
+	return certs, nil
+}
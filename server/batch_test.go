@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-tpm-tools/internal/test"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestVerifyAttestations(t *testing.T) {
+	noNonce := &attestpb.Attestation{}
+	if err := proto.Unmarshal(test.COS85NoNonce, noNonce); err != nil {
+		t.Fatalf("failed to unmarshal attestation: %v", err)
+	}
+	nonce9009 := &attestpb.Attestation{}
+	if err := proto.Unmarshal(test.COS85Nonce9009, nonce9009); err != nil {
+		t.Fatalf("failed to unmarshal attestation: %v", err)
+	}
+	requests := []BatchRequest{
+		{Attestation: noNonce},
+		{Attestation: nonce9009, Nonce: []byte{0x90, 0x09}},
+		{Attestation: nonce9009, Nonce: []byte{0x90, 0x10}},
+		{Attestation: &attestpb.Attestation{AkCert: []byte("not a certificate")}},
+	}
+	opts := VerifyOpts{TrustedRootCerts: GceEKRoots, IntermediateCerts: GceEKIntermediates}
+
+	results, summary := VerifyAttestations(context.Background(), requests, opts, BatchOpts{Parallelism: 2})
+	if len(results) != len(requests) {
+		t.Fatalf("got %d results, want %d", len(results), len(requests))
+	}
+	for i, wantErr := range []error{nil, nil, ErrNonceMismatch, ErrInvalidAK} {
+		if wantErr == nil {
+			if results[i].Err != nil || results[i].MachineState == nil {
+				t.Errorf("attestation %d failed to verify: %v", i, results[i].Err)
+			}
+		} else if !errors.Is(results[i].Err, wantErr) {
+			t.Errorf("attestation %d got error %v, want %v", i, results[i].Err, wantErr)
+		}
+		if results[i].Report == nil {
+			t.Errorf("attestation %d has no report", i)
+		}
+	}
+	if summary.Total != 4 || summary.Verified != 2 || summary.Failures[CodeNonceMismatch] != 1 || summary.Failures[CodeInvalidAK] != 1 {
+		t.Errorf("got summary %+v, want 2 of 4 verified, with a nonce mismatch and an invalid AK", summary)
+	}
+}
+
+func TestVerifyAttestationsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	requests := []BatchRequest{{Attestation: &attestpb.Attestation{}}, {Attestation: &attestpb.Attestation{}}}
+	results, summary := VerifyAttestations(ctx, requests, VerifyOpts{}, BatchOpts{})
+	for i, result := range results {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("attestation %d got error %v, want context.Canceled", i, result.Err)
+		}
+	}
+	if summary.Verified != 0 || summary.Failures[CodeUnknown] != 2 {
+		t.Errorf("got summary %+v, want 2 unknown failures", summary)
+	}
+}
+
+func TestCertCacheParseIntermediates(t *testing.T) {
+	var rawCerts [][]byte
+	for _, cert := range GceEKIntermediates {
+		rawCerts = append(rawCerts, cert.Raw)
+	}
+	cache := newCertCache(GceEKRoots)
+	first, err := cache.parseIntermediates(rawCerts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := cache.parseIntermediates(rawCerts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range rawCerts {
+		if first[i] != second[i] {
+			t.Errorf("intermediate %d was parsed again", i)
+		}
+	}
+	if _, err := cache.parseIntermediates([][]byte{[]byte("not a certificate")}); err == nil {
+		t.Error("parseIntermediates succeeded with an invalid certificate")
+	}
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"github.com/google/go-tpm-tools/cel"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+)
+
+// ContainerClaims are the claims about the workload container measured by
+// the launcher.
+type ContainerClaims struct {
+	ImageReference    string            `json:"image_reference"`
+	ImageDigest       string            `json:"image_digest"`
+	ImageID           string            `json:"image_id"`
+	ImageLayerDigests []string          `json:"image_layer_digests,omitempty"`
+	RestartPolicy     string            `json:"restart_policy"`
+	Args              []string          `json:"args,omitempty"`
+	EnvVars           map[string]string `json:"env,omitempty"`
+	// ImagePlatform and ImageManifestDigest are the platform of the image and
+	// its manifest for the platform, see cel.ImagePlatformType and
+	// cel.ImageManifestDigestType.
+	ImagePlatform       string `json:"image_platform,omitempty"`
+	ImageManifestDigest string `json:"image_manifest_digest,omitempty"`
+	// WorkloadKeyDigest binds the token to the ephemeral key of the workload,
+	// see cel.WorkloadKeyType.
+	WorkloadKeyDigest string `json:"workload_key_digest,omitempty"`
+	// WorkloadConfigDigest binds the token to the configuration of the
+	// workload, see cel.WorkloadConfigType.
+	WorkloadConfigDigest string `json:"workload_config_digest,omitempty"`
+	// SeccompProfile and AppArmorProfile are the confinement of the
+	// container, see cel.SeccompProfileType and cel.AppArmorProfileType.
+	SeccompProfile  string `json:"seccomp_profile,omitempty"`
+	AppArmorProfile string `json:"apparmor_profile,omitempty"`
+	// LaunchPolicyDigest and LaunchPolicySigner identify the signed launch
+	// policy document enforced by the launcher, see
+	// cel.LaunchPolicyDocumentType and cel.LaunchPolicySignerType.
+	LaunchPolicyDigest string `json:"launch_policy_digest,omitempty"`
+	LaunchPolicySigner string `json:"launch_policy_signer,omitempty"`
+	// BootTime and ClockSource are when the launcher started, and the clock
+	// it checked the clock of the VM against, see cel.BootTimeType and
+	// cel.ClockSourceType.
+	BootTime    string `json:"boot_time,omitempty"`
+	ClockSource string `json:"clock_source,omitempty"`
+	// WorkloadOutput is the last checkpoint of the output log of the
+	// workload, formatted by cel.FormatWorkloadOutput, see
+	// cel.WorkloadOutputType.
+	WorkloadOutput string `json:"workload_output,omitempty"`
+	// EnvOverride and CmdOverride are the overrides of the image by the
+	// operator, in the tokens of Confidential Space.
+	EnvOverride map[string]string `json:"env_override,omitempty"`
+	CmdOverride []string          `json:"cmd_override,omitempty"`
+}
+
+// NewContainerClaims returns the claims about the container, or nil if there is
+// no container.
+func NewContainerClaims(container *pb.ContainerState) *ContainerClaims {
+	if container == nil {
+		return nil
+	}
+	return &ContainerClaims{
+		ImageReference:       container.GetImageReference(),
+		ImageDigest:          container.GetImageDigest(),
+		ImageID:              container.GetImageId(),
+		ImageLayerDigests:    container.GetImageLayerDigests(),
+		RestartPolicy:        container.GetRestartPolicy().String(),
+		Args:                 container.GetArgs(),
+		EnvVars:              container.GetEnvVars(),
+		ImagePlatform:        container.GetImagePlatform(),
+		ImageManifestDigest:  container.GetImageManifestDigest(),
+		WorkloadKeyDigest:    container.GetWorkloadKeyDigest(),
+		WorkloadConfigDigest: container.GetWorkloadConfigDigest(),
+		SeccompProfile:       container.GetSeccompProfile(),
+		AppArmorProfile:      container.GetApparmorProfile(),
+		LaunchPolicyDigest:   container.GetLaunchPolicyDigest(),
+		LaunchPolicySigner:   container.GetLaunchPolicySigner(),
+		BootTime:             container.GetBootTime(),
+		ClockSource:          container.GetClockSource(),
+		WorkloadOutput:       workloadOutputClaim(container.GetWorkloadOutput()),
+	}
+}
+
+func workloadOutputClaim(output *pb.WorkloadOutput) string {
+	if output == nil {
+		return ""
+	}
+	// The checkpoint was parsed from the CEL, so it is well formed.
+	claim, _ := cel.FormatWorkloadOutput(cel.WorkloadOutput{Size: output.GetSize(), Digest: output.GetDigest()})
+	return claim
+}
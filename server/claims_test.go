@@ -0,0 +1,49 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+)
+
+func TestNewContainerClaims(t *testing.T) {
+	if claims := NewContainerClaims(nil); claims != nil {
+		t.Errorf("NewContainerClaims(nil) = %v, want nil", claims)
+	}
+
+	container := &pb.ContainerState{
+		ImageReference: "docker.io/library/workload:latest",
+		ImageDigest:    "sha256:digest",
+		RestartPolicy:  pb.RestartPolicy_Never,
+		Args:           []string{"/workload"},
+		EnvVars:        map[string]string{"NAME": "value"},
+		WorkloadOutput: &pb.WorkloadOutput{Size: 10, Digest: bytes.Repeat([]byte{0xab}, 32)},
+	}
+	want := &ContainerClaims{
+		ImageReference: "docker.io/library/workload:latest",
+		ImageDigest:    "sha256:digest",
+		RestartPolicy:  "Never",
+		Args:           []string{"/workload"},
+		EnvVars:        map[string]string{"NAME": "value"},
+		WorkloadOutput: "size=10,digest=sha256:" + string(bytes.Repeat([]byte("ab"), 32)),
+	}
+	got := NewContainerClaims(container)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("NewContainerClaims() (-want +got):\n%s", diff)
+	}
+
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded ContainerClaims
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, &decoded); diff != "" {
+		t.Errorf("claims changed through JSON (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"crypto"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/google/go-tpm/tpm2"
+
+	pb "github.com/google/go-tpm-tools/proto/tpm"
+)
+
+// MakeCredential encrypts the secret to the provided public EK, so that it can
+// only be recovered by the TPM with that EK, and only while the key with the
+// provided name is loaded on it. This is the TPM2_MakeCredential operation of
+// the privacy CA flow: a verifier receiving an AK can check that the AK is
+// resident on the same TPM as a trusted EK.
+//
+// The akName is the encoded name of the AK (a TPMT_HA), which can be computed
+// from the AK public area with tpm2.Public.Name(). The secret must not be
+// longer than the digest size of the EK's name algorithm. The returned
+// credential can be decrypted with the client Key.ActivateCredential() method.
+func MakeCredential(ekPub crypto.PublicKey, akName []byte, secret []byte) (*pb.EncryptedCredential, error) {
+	ek, err := CreateEKPublicAreaFromKey(ekPub)
+	if err != nil {
+		return nil, err
+	}
+	if len(secret) > getHash(ek.NameAlg).Size() {
+		return nil, fmt.Errorf("secret is %d bytes, longer than the %d bytes allowed", len(secret), getHash(ek.NameAlg).Size())
+	}
+	if err := checkName(akName); err != nil {
+		return nil, fmt.Errorf("invalid AK name: %w", err)
+	}
+
+	seed, encryptedSecret, err := createSeed(ek, identityLabel)
+	if err != nil {
+		return nil, err
+	}
+	credentialBlob, err := createIDObject(secret, seed, akName, ek)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.EncryptedCredential{
+		CredentialBlob:  credentialBlob,
+		EncryptedSecret: encryptedSecret,
+	}, nil
+}
+
+// checkName checks that the name is a valid encoded TPMT_HA.
+func checkName(name []byte) error {
+	if len(name) < 2 {
+		return fmt.Errorf("name is too short")
+	}
+	hash, err := tpm2.Algorithm(binary.BigEndian.Uint16(name)).Hash()
+	if err != nil {
+		return err
+	}
+	if len(name)-2 != hash.Size() {
+		return fmt.Errorf("digest is %d bytes, expected %d bytes for %v", len(name)-2, hash.Size(), hash)
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"testing"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+func TestActivateCredential(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	ak, err := client.AttestationKeyECC(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	akName, err := ak.Name().Digest.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := []struct {
+		name     string
+		template tpm2.Public
+	}{
+		{"RSA", client.DefaultEKTemplateRSA()},
+		{"ECC", client.DefaultEKTemplateECC()},
+		{"SRK-RSA", client.SRKTemplateRSA()},
+		{"ECC-P384", client.EKTemplateECCP384()},
+	}
+	for _, k := range keys {
+		t.Run(k.name, func(t *testing.T) {
+			ek, err := client.NewKey(rwc, tpm2.HandleEndorsement, k.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ek.Close()
+			secret := []byte("super secret code")
+			cred, err := MakeCredential(ek.PublicKey(), akName, secret)
+			if err != nil {
+				t.Fatalf("making credential failed: %v", err)
+			}
+
+			output, err := ek.ActivateCredential(ak, cred)
+			if err != nil {
+				t.Fatalf("activating credential failed: %v", err)
+			}
+			if !bytes.Equal(output, secret) {
+				t.Errorf("got %X, expected %X", output, secret)
+			}
+		})
+	}
+}
+
+func TestActivateCredentialWrongAK(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	ek, err := client.EndorsementKeyRSA(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ek.Close()
+	ak, err := client.AttestationKeyECC(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	otherAK, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer otherAK.Close()
+
+	otherName, err := otherAK.Name().Digest.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cred, err := MakeCredential(ek.PublicKey(), otherName, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ek.ActivateCredential(ak, cred); err == nil {
+		t.Error("activating a credential made for another AK succeeded")
+	}
+}
+
+func TestMakeCredentialBadInput(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ek, err := client.EndorsementKeyRSA(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ek.Close()
+	ak, err := client.AttestationKeyECC(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	akName, err := ak.Name().Digest.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := MakeCredential(ek.PublicKey(), akName, make([]byte, 33)); err == nil {
+		t.Error("MakeCredential succeeded with a secret longer than the digest size")
+	}
+	if _, err := MakeCredential(ek.PublicKey(), akName[:10], []byte("secret")); err == nil {
+		t.Error("MakeCredential succeeded with a truncated AK name")
+	}
+}
+
+// The simulator does not support RSA-3072 keys, so the credential is
+// activated like TPM2_ActivateCredential does, with the private EK.
+func TestMakeCredentialRSA3072(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 3072)
+	if err != nil {
+		t.Fatal(err)
+	}
+	akName, err := client.AKTemplateECC().Name()
+	if err != nil {
+		t.Fatal(err)
+	}
+	akNameEncoded, err := akName.Digest.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("super secret code")
+	cred, err := MakeCredential(priv.Public(), akNameEncoded, secret)
+	if err != nil {
+		t.Fatalf("making credential failed: %v", err)
+	}
+
+	seed, err := rsa.DecryptOAEP(sha512.New384(), nil, priv, cred.GetEncryptedSecret(), []byte(identityLabel+"\x00"))
+	if err != nil {
+		t.Fatalf("decrypting the seed failed: %v", err)
+	}
+	if len(seed) != 32 {
+		t.Errorf("got a %d bytes seed, want the 32 bytes of the AES-256 key", len(seed))
+	}
+
+	var idObject tpm2.IDObject
+	if _, err := tpmutil.Unpack(cred.GetCredentialBlob(), &idObject.IntegrityHMAC); err != nil {
+		t.Fatal(err)
+	}
+	idObject.EncIdentity = cred.GetCredentialBlob()[2+len(idObject.IntegrityHMAC):]
+	macKey, err := tpm2.KDFa(tpm2.AlgSHA384, seed, "INTEGRITY", nil, nil, sha512.Size384*8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mac := hmac.New(sha512.New384, macKey)
+	mac.Write(idObject.EncIdentity)
+	mac.Write(akNameEncoded)
+	if !hmac.Equal(mac.Sum(nil), idObject.IntegrityHMAC) {
+		t.Fatal("credential HMAC does not match")
+	}
+	symmetricKey, err := tpm2.KDFa(tpm2.AlgSHA384, seed, "STORAGE", akNameEncoded, nil, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := aes.NewCipher(symmetricKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decrypted := make([]byte, len(idObject.EncIdentity))
+	cipher.NewCFBDecrypter(c, make([]byte, aes.BlockSize)).XORKeyStream(decrypted, idObject.EncIdentity)
+	var output tpmutil.U16Bytes
+	if _, err := tpmutil.Unpack(decrypted, &output); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(output, secret) {
+		t.Errorf("got %X, expected %X", output, secret)
+	}
+}
@@ -0,0 +1,191 @@
+package eat
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// COSE algorithm identifiers, see the IANA COSE Algorithms registry.
+const (
+	algES256 = -7
+	algES384 = -35
+	algPS256 = -37
+)
+
+// COSE header parameters.
+const (
+	headerAlg = 1
+	headerKid = 4
+)
+
+const (
+	tagCOSESign1      = 18
+	sign1SigStructure = "Signature1"
+)
+
+// cose_sign1 without the tag, see RFC 9052 section 4.2.
+type sign1 struct {
+	_           struct{} `cbor:",toarray"`
+	Protected   []byte
+	Unprotected map[int]interface{}
+	Payload     []byte
+	Signature   []byte
+}
+
+// Sig_structure for COSE_Sign1, see RFC 9052 section 4.4.
+type sigStructure struct {
+	_           struct{} `cbor:",toarray"`
+	Context     string
+	Protected   []byte
+	ExternalAAD []byte
+	Payload     []byte
+}
+
+var encMode cbor.EncMode
+
+func init() {
+	var err error
+	if encMode, err = cbor.CoreDetEncOptions().EncMode(); err != nil {
+		panic(err)
+	}
+}
+
+type algorithm struct {
+	id   int
+	hash crypto.Hash
+	size int // size of the ECDSA coordinates
+}
+
+func signingAlgorithm(pub crypto.PublicKey) (algorithm, error) {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return algorithm{algPS256, crypto.SHA256, 0}, nil
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256():
+			return algorithm{algES256, crypto.SHA256, 32}, nil
+		case elliptic.P384():
+			return algorithm{algES384, crypto.SHA384, 48}, nil
+		}
+		return algorithm{}, fmt.Errorf("unsupported ECDSA curve %s", pub.Curve.Params().Name)
+	}
+	return algorithm{}, fmt.Errorf("unsupported key type %T", pub)
+}
+
+// Sign returns the claims as a tagged COSE_Sign1 CWT signed by signer. RSA
+// (PS256) and ECDSA P-256 (ES256) or P-384 (ES384) signers are supported. The
+// keyID is put in the unprotected header if not empty.
+func Sign(claims *Claims, signer crypto.Signer, keyID []byte) ([]byte, error) {
+	alg, err := signingAlgorithm(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	payload, err := encMode.Marshal(claims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode claims: %w", err)
+	}
+	protected, err := encMode.Marshal(map[int]interface{}{headerAlg: alg.id})
+	if err != nil {
+		return nil, err
+	}
+	toBeSigned, err := encMode.Marshal(sigStructure{Context: sign1SigStructure, Protected: protected, ExternalAAD: []byte{}, Payload: payload})
+	if err != nil {
+		return nil, err
+	}
+
+	h := alg.hash.New()
+	h.Write(toBeSigned)
+	var signerOpts crypto.SignerOpts = alg.hash
+	if alg.id == algPS256 {
+		signerOpts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: alg.hash}
+	}
+	sig, err := signer.Sign(rand.Reader, h.Sum(nil), signerOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign claims: %w", err)
+	}
+	if alg.size != 0 {
+		// COSE uses the fixed size R || S encoding instead of ASN.1.
+		var ecSig struct{ R, S *big.Int }
+		if _, err := asn1.Unmarshal(sig, &ecSig); err != nil {
+			return nil, fmt.Errorf("failed to decode ECDSA signature: %w", err)
+		}
+		sig = make([]byte, 2*alg.size)
+		ecSig.R.FillBytes(sig[:alg.size])
+		ecSig.S.FillBytes(sig[alg.size:])
+	}
+
+	unprotected := map[int]interface{}{}
+	if len(keyID) != 0 {
+		unprotected[headerKid] = keyID
+	}
+	return encMode.Marshal(cbor.Tag{
+		Number:  tagCOSESign1,
+		Content: sign1{Protected: protected, Unprotected: unprotected, Payload: payload, Signature: sig},
+	})
+}
+
+// Verify checks that the token is a COSE_Sign1 CWT signed by the trusted
+// public key, and returns its claims. The validity period and other claims are
+// not checked, see Claims.CheckValidity.
+func Verify(token []byte, trustedPub crypto.PublicKey) (*Claims, error) {
+	alg, err := signingAlgorithm(trustedPub)
+	if err != nil {
+		return nil, err
+	}
+	var tag cbor.RawTag
+	if err := cbor.Unmarshal(token, &tag); err != nil {
+		return nil, fmt.Errorf("failed to decode token: %w", err)
+	}
+	if tag.Number != tagCOSESign1 {
+		return nil, fmt.Errorf("got CBOR tag %d, expected COSE_Sign1", tag.Number)
+	}
+	var msg sign1
+	if err := cbor.Unmarshal(tag.Content, &msg); err != nil {
+		return nil, fmt.Errorf("failed to decode COSE_Sign1: %w", err)
+	}
+	var protected map[int]interface{}
+	if err := cbor.Unmarshal(msg.Protected, &protected); err != nil {
+		return nil, fmt.Errorf("failed to decode protected header: %w", err)
+	}
+	if id, ok := protected[headerAlg].(int64); !ok || int(id) != alg.id {
+		return nil, fmt.Errorf("got algorithm %v, expected %d", protected[headerAlg], alg.id)
+	}
+
+	toBeSigned, err := encMode.Marshal(sigStructure{Context: sign1SigStructure, Protected: msg.Protected, ExternalAAD: []byte{}, Payload: msg.Payload})
+	if err != nil {
+		return nil, err
+	}
+	h := alg.hash.New()
+	h.Write(toBeSigned)
+	digest := h.Sum(nil)
+	switch pub := trustedPub.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPSS(pub, alg.hash, digest, msg.Signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}); err != nil {
+			return nil, fmt.Errorf("invalid signature: %w", err)
+		}
+	case *ecdsa.PublicKey:
+		if len(msg.Signature) != 2*alg.size {
+			return nil, errors.New("invalid signature size")
+		}
+		r := new(big.Int).SetBytes(msg.Signature[:alg.size])
+		s := new(big.Int).SetBytes(msg.Signature[alg.size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return nil, errors.New("invalid signature")
+		}
+	}
+
+	claims := &Claims{}
+	if err := cbor.Unmarshal(msg.Payload, claims); err != nil {
+		return nil, fmt.Errorf("failed to decode claims: %w", err)
+	}
+	return claims, nil
+}
@@ -0,0 +1,158 @@
+// Package eat converts attestations into Entity Attestation Tokens (EATs), see
+// RFC 9711. EATs are CBOR Web Tokens (RFC 8392) signed with COSE_Sign1
+// (RFC 9052), so go-tpm-tools evidence and verification results can be
+// consumed by IETF RATS relying parties.
+package eat
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/server"
+	"google.golang.org/protobuf/proto"
+)
+
+// Profile identifies the claims produced by this package, see the eat_profile
+// claim.
+const Profile = "tag:github.com,2023:google/go-tpm-tools/eat"
+
+// ContentFormatOctetStream is the CoAP Content-Format of the protobuf encoded
+// Attestation in the measurements claim of FromAttestation.
+const ContentFormatOctetStream = 42
+
+// The version scheme of the hwversion claim: multipartnumeric, as registered
+// for CoSWID.
+const versionSchemeMultipartNumeric = 1
+
+// ueidTypeRAND is the type of UEIDs derived from the AK.
+const ueidTypeRAND = 0x01
+
+// Claims is a CWT claims set with the EAT claims produced by this package.
+// Claims are encoded with their integer keys, and omitted if unset.
+type Claims struct {
+	Issuer    string `cbor:"1,keyasint,omitempty"`
+	Subject   string `cbor:"2,keyasint,omitempty"`
+	Audience  string `cbor:"3,keyasint,omitempty"`
+	ExpiresAt int64  `cbor:"4,keyasint,omitempty"`
+	NotBefore int64  `cbor:"5,keyasint,omitempty"`
+	IssuedAt  int64  `cbor:"6,keyasint,omitempty"`
+	Nonce     []byte `cbor:"10,keyasint,omitempty"`
+	// UEID is the universal entity ID of the attester.
+	UEID []byte `cbor:"256,keyasint,omitempty"`
+	// HWModel identifies the hardware, here the confidential computing
+	// technology of the VM.
+	HWModel []byte `cbor:"259,keyasint,omitempty"`
+	// HWVersion is the version of the hardware, here the GCE firmware version,
+	// as a [version, version-scheme] array.
+	HWVersion []interface{} `cbor:"260,keyasint,omitempty"`
+	// OEMBoot is true if the boot chain is authorized by the manufacturer,
+	// here if UEFI Secure Boot is enabled.
+	OEMBoot *bool  `cbor:"262,keyasint,omitempty"`
+	Profile string `cbor:"265,keyasint,omitempty"`
+	// Submods has claims about the submodules of the attester, like the
+	// container run by the Container-Optimized OS launcher.
+	Submods map[string]*Claims `cbor:"266,keyasint,omitempty"`
+	// SWName is the name of the software, e.g. a container image reference.
+	SWName string `cbor:"270,keyasint,omitempty"`
+	// Measurements contains [content-format, measurement] arrays.
+	Measurements [][]interface{} `cbor:"273,keyasint,omitempty"`
+}
+
+// Options configures the standard CWT claims of the created Claims.
+type Options struct {
+	Issuer   string
+	Audience string
+	// Nonce is the nonce of the relying party, if any.
+	Nonce []byte
+	// IssuedAt defaults to the current time.
+	IssuedAt time.Time
+	// Lifetime is how long the token is valid for. If zero, the token does
+	// not expire.
+	Lifetime time.Duration
+}
+
+// CheckValidity returns an error if the claims are not valid at the time now,
+// i.e. before their NotBefore time or at or after their ExpiresAt time. Unset
+// times are not checked. Pass the time an archived token was received at to
+// check it reproducibly.
+func (c *Claims) CheckValidity(now time.Time) error {
+	if c.NotBefore != 0 && now.Before(time.Unix(c.NotBefore, 0)) {
+		return fmt.Errorf("token is not valid before %v", time.Unix(c.NotBefore, 0).UTC())
+	}
+	if c.ExpiresAt != 0 && !now.Before(time.Unix(c.ExpiresAt, 0)) {
+		return fmt.Errorf("token expired at %v", time.Unix(c.ExpiresAt, 0).UTC())
+	}
+	return nil
+}
+
+func newClaims(opts Options) *Claims {
+	issuedAt := opts.IssuedAt
+	if issuedAt.IsZero() {
+		issuedAt = time.Now()
+	}
+	claims := &Claims{
+		Issuer:    opts.Issuer,
+		Audience:  opts.Audience,
+		NotBefore: issuedAt.Unix(),
+		IssuedAt:  issuedAt.Unix(),
+		Nonce:     opts.Nonce,
+		Profile:   Profile,
+	}
+	if opts.Lifetime != 0 {
+		claims.ExpiresAt = issuedAt.Add(opts.Lifetime).Unix()
+	}
+	return claims
+}
+
+// FromMachineState returns the claims about a MachineState, which must come
+// from a verified attestation (e.g. server.VerifyAttestation). The subject is
+// the GCE instance URL, if known.
+func FromMachineState(state *pb.MachineState, opts Options) (*Claims, error) {
+	if state == nil {
+		return nil, errors.New("nil machine state")
+	}
+	claims := newClaims(opts)
+	platform := state.GetPlatform()
+	if info := platform.GetInstanceInfo(); info != nil {
+		claims.Subject = server.GCEInstanceURL(info)
+	}
+	if platform != nil {
+		claims.HWModel = []byte(platform.GetTechnology().String())
+	}
+	if version, ok := platform.GetFirmware().(*pb.PlatformState_GceVersion); ok {
+		claims.HWVersion = []interface{}{strconv.FormatUint(uint64(version.GceVersion), 10), versionSchemeMultipartNumeric}
+	}
+	if secureBoot := state.GetSecureBoot(); secureBoot != nil {
+		enabled := secureBoot.GetEnabled()
+		claims.OEMBoot = &enabled
+	}
+	if container := state.GetCos().GetContainer(); container != nil {
+		claims.Submods = map[string]*Claims{
+			"container": {SWName: container.GetImageReference()},
+		}
+	}
+	return claims, nil
+}
+
+// FromAttestation returns the claims about a raw, unverified Attestation, so
+// it can be forwarded to a RATS verifier. The UEID is derived from the AK, and
+// the protobuf encoded Attestation is the only measurement.
+func FromAttestation(attestation *pb.Attestation, opts Options) (*Claims, error) {
+	if len(attestation.GetAkPub()) == 0 {
+		return nil, errors.New("attestation has no AK public area")
+	}
+	evidence, err := proto.Marshal(attestation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode attestation: %w", err)
+	}
+	akDigest := sha256.Sum256(attestation.GetAkPub())
+
+	claims := newClaims(opts)
+	claims.UEID = append([]byte{ueidTypeRAND}, akDigest[:]...)
+	claims.Measurements = [][]interface{}{{ContentFormatOctetStream, evidence}}
+	return claims, nil
+}
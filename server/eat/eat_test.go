@@ -0,0 +1,246 @@
+package eat
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/server"
+	"google.golang.org/protobuf/proto"
+)
+
+var testOpts = Options{
+	Issuer:   "https://verifier.example.com",
+	Audience: "relying-party",
+	Nonce:    []byte("nonce"),
+	IssuedAt: time.Unix(1700000000, 0),
+	Lifetime: time.Hour,
+}
+
+func TestFromMachineState(t *testing.T) {
+	state := &pb.MachineState{
+		Platform: &pb.PlatformState{
+			Firmware:   &pb.PlatformState_GceVersion{GceVersion: 2},
+			Technology: pb.GCEConfidentialTechnology_AMD_SEV,
+			InstanceInfo: &pb.GCEInstanceInfo{
+				Zone:         "us-central1-a",
+				ProjectId:    "test-project",
+				InstanceName: "test-instance",
+			},
+		},
+		SecureBoot: &pb.SecureBootState{Enabled: true},
+		Cos: &pb.AttestedCosState{
+			Container: &pb.ContainerState{ImageReference: "docker.io/library/hello-world:latest"},
+		},
+	}
+	claims, err := FromMachineState(state, testOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enabled := true
+	want := &Claims{
+		Issuer:    testOpts.Issuer,
+		Subject:   "https://www.googleapis.com/compute/v1/projects/test-project/zones/us-central1-a/instances/test-instance",
+		Audience:  testOpts.Audience,
+		ExpiresAt: 1700003600,
+		NotBefore: 1700000000,
+		IssuedAt:  1700000000,
+		Nonce:     testOpts.Nonce,
+		HWModel:   []byte("AMD_SEV"),
+		HWVersion: []interface{}{"2", versionSchemeMultipartNumeric},
+		OEMBoot:   &enabled,
+		Profile:   Profile,
+		Submods: map[string]*Claims{
+			"container": {SWName: "docker.io/library/hello-world:latest"},
+		},
+	}
+	if diff := cmp.Diff(want, claims); diff != "" {
+		t.Errorf("FromMachineState() (-want +got):\n%s", diff)
+	}
+
+	// Claims are encoded with their integer keys.
+	encoded, err := encMode.Marshal(&Claims{Nonce: []byte{1}, OEMBoot: &enabled, SWName: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hex.EncodeToString(encoded), "a30a4101190106f519010e6161"; got != want {
+		t.Errorf("encoded claims = %s, want %s", got, want)
+	}
+}
+
+func TestFromAttestation(t *testing.T) {
+	attestation := &pb.Attestation{AkPub: []byte("ak"), EventLog: []byte("event log")}
+	claims, err := FromAttestation(attestation, testOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	akDigest := sha256.Sum256([]byte("ak"))
+	if want := append([]byte{ueidTypeRAND}, akDigest[:]...); !cmp.Equal(claims.UEID, want) {
+		t.Errorf("got UEID %x, want %x", claims.UEID, want)
+	}
+	if len(claims.Measurements) != 1 || len(claims.Measurements[0]) != 2 || claims.Measurements[0][0] != ContentFormatOctetStream {
+		t.Fatalf("got measurements %v", claims.Measurements)
+	}
+	got := &pb.Attestation{}
+	if err := proto.Unmarshal(claims.Measurements[0][1].([]byte), got); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, attestation) {
+		t.Errorf("got attestation %v, want %v", got, attestation)
+	}
+
+	if _, err := FromAttestation(&pb.Attestation{}, testOpts); err == nil {
+		t.Error("FromAttestation succeeded without an AK")
+	}
+}
+
+func TestCheckValidity(t *testing.T) {
+	claims := newClaims(testOpts)
+	for _, tc := range []struct {
+		name    string
+		now     time.Time
+		wantErr bool
+	}{
+		{"BeforeNotBefore", testOpts.IssuedAt.Add(-time.Second), true},
+		{"AtIssuance", testOpts.IssuedAt, false},
+		{"BeforeExpiry", testOpts.IssuedAt.Add(time.Hour - time.Second), false},
+		{"AtExpiry", testOpts.IssuedAt.Add(time.Hour), true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := claims.CheckValidity(tc.now); (err != nil) != tc.wantErr {
+				t.Errorf("CheckValidity(%v) = %v, want error %v", tc.now, err, tc.wantErr)
+			}
+		})
+	}
+	if err := (&Claims{}).CheckValidity(time.Unix(0, 0)); err != nil {
+		t.Errorf("CheckValidity() of claims without a validity period = %v", err)
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := FromAttestation(&pb.Attestation{AkPub: []byte("ak")}, testOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, signer := range []crypto.Signer{rsaKey, p256Key, p384Key} {
+		token, err := Sign(claims, signer, []byte("key-id"))
+		if err != nil {
+			t.Fatalf("Sign(%T) failed: %v", signer.Public(), err)
+		}
+		got, err := Verify(token, signer.Public())
+		if err != nil {
+			t.Errorf("Verify(%T) failed: %v", signer.Public(), err)
+			continue
+		}
+		if !cmp.Equal(got.UEID, claims.UEID) || got.Issuer != claims.Issuer {
+			t.Errorf("Verify(%T) returned claims %+v, want %+v", signer.Public(), got, claims)
+		}
+	}
+
+	token, err := Sign(claims, p256Key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(token, otherKey.Public()); err == nil {
+		t.Error("Verify succeeded with the wrong key")
+	}
+	if _, err := Verify(token, rsaKey.Public()); err == nil {
+		t.Error("Verify succeeded with the wrong algorithm")
+	}
+
+	// Tamper with the payload.
+	var tag cbor.RawTag
+	if err := cbor.Unmarshal(token, &tag); err != nil {
+		t.Fatal(err)
+	}
+	var msg sign1
+	if err := cbor.Unmarshal(tag.Content, &msg); err != nil {
+		t.Fatal(err)
+	}
+	claims.Issuer = "https://attacker.example.com"
+	if msg.Payload, err = encMode.Marshal(claims); err != nil {
+		t.Fatal(err)
+	}
+	tampered, err := encMode.Marshal(cbor.Tag{Number: tagCOSESign1, Content: msg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(tampered, p256Key.Public()); err == nil {
+		t.Error("Verify succeeded with tampered claims")
+	}
+}
+
+func TestVerifiedAttestationToken(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	ak, err := client.AttestationKeyECC(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+
+	nonce := []byte("super secret nonce")
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+	state, err := server.VerifyAttestation(attestation, server.VerifyOpts{
+		Nonce:      nonce,
+		TrustedAKs: []crypto.PublicKey{ak.PublicKey()},
+	})
+	if err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+	claims, err := FromMachineState(state, Options{Nonce: nonce})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := Sign(claims, signer, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Verify(token, signer.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.OEMBoot == nil || *got.OEMBoot != state.GetSecureBoot().GetEnabled() {
+		t.Errorf("got oemboot %v, want %v", got.OEMBoot, state.GetSecureBoot().GetEnabled())
+	}
+	if got.Profile != Profile || !cmp.Equal(got.Nonce, nonce) {
+		t.Errorf("got eat_profile %q and nonce %q", got.Profile, got.Nonce)
+	}
+}
@@ -0,0 +1,299 @@
+// Package enrollment registers the EK and AK of machines in a pluggable Store,
+// and checks that later attestations come from enrolled machines. It is a
+// building block for fleet attestation services.
+//
+// Enrollment uses credential activation to check that the AK is resident on
+// the same TPM as the EK:
+//  1. The machine sends its EK, AK and EK certificate to Registry.Begin, which
+//     returns a Challenge with a credential encrypted to the EK.
+//  2. The machine recovers the secret of the credential with the client
+//     Key.ActivateCredential method, and attests with the secret as nonce.
+//  3. Registry.Finish verifies the attestation, and stores the Record of the
+//     machine. Its ID is the enrollment ID of the machine.
+//
+// Registry.Verify then verifies attestations of the machine with its enrolled
+// AK.
+package enrollment
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/go-tpm-tools/internal"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm-tools/server"
+	"github.com/google/go-tpm/tpm2"
+)
+
+const (
+	defaultChallengeLifetime = 5 * time.Minute
+	// secretSize is the size of the credential secrets, which must not be
+	// longer than the digest of the EK name algorithm.
+	secretSize = 32
+)
+
+// akAttributes are the attributes required of enrolled AKs: a restricted
+// signing key generated by, and which cannot leave, the TPM.
+const akAttributes = tpm2.FlagRestricted | tpm2.FlagSign | tpm2.FlagFixedTPM |
+	tpm2.FlagFixedParent | tpm2.FlagSensitiveDataOrigin
+
+// ErrPendingEnrollment is returned by Registry.Begin if the challenge of an
+// earlier enrollment of the same EK has not expired.
+var ErrPendingEnrollment = errors.New("enrollment already pending")
+
+// Record is an enrolled machine.
+type Record struct {
+	// ID is the enrollment ID, the hex SHA-256 of EKPub. Enrolling a machine
+	// again replaces its Record.
+	ID string
+	// EKPub is the PKIX encoding of the EK public key.
+	EKPub []byte
+	// EKCert is the DER EK certificate, if any.
+	EKCert []byte
+	// AKPub is the encoded TPMT_PUBLIC of the AK.
+	AKPub []byte
+	// AKCert is the DER AK certificate from the enrollment attestation, if
+	// any.
+	AKCert []byte
+	// ClockInfo is the TPM clock info at enrollment. It can be used as the
+	// Reference of a ClockPolicy to detect TPM resets since the enrollment.
+	ClockInfo *pb.TPMClockInfo
+	// EnrolledAt is the time of the enrollment.
+	EnrolledAt time.Time
+}
+
+// Config configures a Registry.
+type Config struct {
+	// Store persists the Records. Use NewMemoryStore to keep them in memory.
+	Store Store
+	// EKRoots are the trusted roots of EK certificates, with EKIntermediates
+	// the intermediates to build chains to them. If EKRoots is empty, any EK
+	// is accepted, so the Registry only checks that the AK is resident on the
+	// same TPM as the EK.
+	EKRoots         []*x509.Certificate
+	EKIntermediates []*x509.Certificate
+	// VerifyOpts are used to verify the attestations. The nonce and the
+	// trusted AKs and root certificates are replaced by the Registry.
+	VerifyOpts server.VerifyOpts
+	// ChallengeLifetime defaults to five minutes.
+	ChallengeLifetime time.Duration
+}
+
+// Request is the request of a machine to be enrolled.
+type Request struct {
+	EKPub crypto.PublicKey
+	// EKCert is required if Config.EKRoots is not empty.
+	EKCert *x509.Certificate
+	// AKPub is the encoded TPMT_PUBLIC of the AK, as returned by
+	// tpm2.Public.Encode() on the client Key.PublicArea().
+	AKPub []byte
+}
+
+// Challenge is returned by Registry.Begin, for the machine to recover the
+// secret of the Credential with its EK and AK.
+type Challenge struct {
+	// ID is the enrollment ID, to pass to Registry.Finish.
+	ID         string
+	Credential *tpmpb.EncryptedCredential
+	Expires    time.Time
+}
+
+type pendingEnrollment struct {
+	record  *Record
+	secret  []byte
+	expires time.Time
+}
+
+// Registry enrolls machines and verifies their attestations.
+type Registry struct {
+	config Config
+	now    func() time.Time
+
+	mu      sync.Mutex
+	pending map[string]*pendingEnrollment
+}
+
+// New creates a Registry from the config.
+func New(config Config) (*Registry, error) {
+	if config.Store == nil {
+		return nil, errors.New("a store must be configured")
+	}
+	if config.ChallengeLifetime == 0 {
+		config.ChallengeLifetime = defaultChallengeLifetime
+	}
+	return &Registry{
+		config:  config,
+		now:     time.Now,
+		pending: make(map[string]*pendingEnrollment),
+	}, nil
+}
+
+// Begin starts the enrollment of a machine, checking its EK certificate and
+// that its AK is a restricted signing key fixed to the TPM, and returns a
+// Challenge for the machine to complete the enrollment with Finish. Until the
+// Challenge expires or is passed to Finish, Begin fails with
+// ErrPendingEnrollment for the same EK.
+func (r *Registry) Begin(_ context.Context, req Request) (*Challenge, error) {
+	if req.EKPub == nil {
+		return nil, errors.New("missing EK")
+	}
+	ekPub, err := x509.MarshalPKIXPublicKey(req.EKPub)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EK: %w", err)
+	}
+	record := &Record{EKPub: ekPub, AKPub: req.AKPub}
+	if err := r.checkEKCert(req.EKPub, req.EKCert); err != nil {
+		return nil, err
+	}
+	if req.EKCert != nil {
+		record.EKCert = req.EKCert.Raw
+	}
+
+	akPub, err := tpm2.DecodePublic(req.AKPub)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AK: %w", err)
+	}
+	if akPub.Attributes&akAttributes != akAttributes || akPub.Attributes&tpm2.FlagDecrypt != 0 {
+		return nil, fmt.Errorf("invalid AK: attributes %#x are not those of a restricted, signing-only key fixed to the TPM", uint32(akPub.Attributes))
+	}
+	akName, err := akPub.Name()
+	if err != nil {
+		return nil, fmt.Errorf("invalid AK: %w", err)
+	}
+	encodedName, err := akName.Digest.Encode()
+	if err != nil {
+		return nil, fmt.Errorf("invalid AK: %w", err)
+	}
+
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	cred, err := server.MakeCredential(req.EKPub, encodedName, secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make the credential: %w", err)
+	}
+
+	digest := sha256.Sum256(ekPub)
+	record.ID = hex.EncodeToString(digest[:])
+	now := r.now()
+	pending := &pendingEnrollment{record: record, secret: secret, expires: now.Add(r.config.ChallengeLifetime)}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, p := range r.pending {
+		if now.After(p.expires) {
+			delete(r.pending, id)
+		}
+	}
+	if _, ok := r.pending[record.ID]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrPendingEnrollment, record.ID)
+	}
+	r.pending[record.ID] = pending
+
+	return &Challenge{ID: record.ID, Credential: cred, Expires: pending.expires}, nil
+}
+
+func (r *Registry) checkEKCert(ekPub crypto.PublicKey, ekCert *x509.Certificate) error {
+	if ekCert == nil {
+		if len(r.config.EKRoots) > 0 {
+			return errors.New("missing EK certificate")
+		}
+		return nil
+	}
+	if !internal.PubKeysEqual(ekCert.PublicKey, ekPub) {
+		return errors.New("EK certificate does not match the EK")
+	}
+	if len(r.config.EKRoots) == 0 {
+		return nil
+	}
+
+	if _, err := internal.HandleTPMSubjectAltName(ekCert).Verify(x509.VerifyOptions{
+		Roots:         makePool(r.config.EKRoots),
+		Intermediates: makePool(r.config.EKIntermediates),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		CurrentTime:   r.now(),
+	}); err != nil {
+		return fmt.Errorf("EK certificate did not chain to a trusted root: %w", err)
+	}
+	return nil
+}
+
+// Finish completes the enrollment with the ID of the Challenge, verifying
+// the attestation of the machine with the secret of the credential as nonce.
+// The enrolled Record is returned.
+func (r *Registry) Finish(ctx context.Context, id string, attestation *pb.Attestation) (*Record, error) {
+	r.mu.Lock()
+	pending, ok := r.pending[id]
+	delete(r.pending, id)
+	r.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no pending enrollment %s", id)
+	}
+	if r.now().After(pending.expires) {
+		return nil, fmt.Errorf("enrollment %s expired", id)
+	}
+
+	record := pending.record
+	state, err := r.verify(record, attestation, pending.secret)
+	if err != nil {
+		return nil, err
+	}
+	record.AKCert = attestation.GetAkCert()
+	record.ClockInfo = state.GetClockInfo()
+	record.EnrolledAt = r.now()
+	if err := r.config.Store.Put(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to store enrollment %s: %w", id, err)
+	}
+	return record, nil
+}
+
+// Verify verifies an attestation of the enrolled machine with the ID, over
+// the nonce, and returns the verified MachineState with the Record of the
+// machine. The error wraps ErrNotFound if the machine is not enrolled.
+func (r *Registry) Verify(ctx context.Context, id string, attestation *pb.Attestation, nonce []byte) (*pb.MachineState, *Record, error) {
+	record, err := r.config.Store.Get(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get enrollment %s: %w", id, err)
+	}
+	state, err := r.verify(record, attestation, nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+	return state, record, nil
+}
+
+// verify verifies the attestation with the AK of the record.
+func (r *Registry) verify(record *Record, attestation *pb.Attestation, nonce []byte) (*pb.MachineState, error) {
+	akPub, err := tpm2.DecodePublic(record.AKPub)
+	if err != nil {
+		return nil, fmt.Errorf("invalid enrolled AK: %w", err)
+	}
+	akKey, err := akPub.Key()
+	if err != nil {
+		return nil, fmt.Errorf("invalid enrolled AK: %w", err)
+	}
+	opts := r.config.VerifyOpts
+	opts.Nonce = nonce
+	opts.TrustedAKs = []crypto.PublicKey{akKey}
+	opts.TrustedRootCerts = nil
+	opts.IntermediateCerts = nil
+	return server.VerifyAttestation(attestation, opts)
+}
+
+func makePool(certs []*x509.Certificate) *x509.CertPool {
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	return pool
+}
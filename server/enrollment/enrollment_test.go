@@ -0,0 +1,224 @@
+package enrollment
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm-tools/server"
+	"github.com/google/go-tpm/tpm2"
+)
+
+func TestEnrollment(t *testing.T) {
+	ctx := context.Background()
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ek, err := client.EndorsementKeyRSA(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ek.Close()
+	ak, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	akPub, err := ak.PublicArea().Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewMemoryStore()
+	registry, err := New(Config{Store: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge, err := registry.Begin(ctx, Request{EKPub: ek.PublicKey(), AKPub: akPub})
+	if err != nil {
+		t.Fatalf("Begin() failed: %v", err)
+	}
+	secret, err := ek.ActivateCredential(ak, challenge.Credential)
+	if err != nil {
+		t.Fatalf("failed to activate the credential: %v", err)
+	}
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: secret})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+	if _, err := registry.Finish(ctx, challenge.ID, attestation); err != nil {
+		t.Fatalf("Finish() failed: %v", err)
+	}
+	if _, err := registry.Finish(ctx, challenge.ID, attestation); err == nil {
+		t.Error("Finish() succeeded twice with the same challenge")
+	}
+
+	record, err := store.Get(ctx, challenge.ID)
+	if err != nil {
+		t.Fatalf("enrollment was not stored: %v", err)
+	}
+	if record.ClockInfo == nil {
+		t.Error("enrollment is missing the clock info")
+	}
+
+	nonce := []byte("super secret nonce")
+	attestation, err = ak.Attest(client.AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+	if _, _, err := registry.Verify(ctx, challenge.ID, attestation, nonce); err != nil {
+		t.Errorf("Verify() failed: %v", err)
+	}
+	if _, _, err := registry.Verify(ctx, "unknown", attestation, nonce); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Verify() with an unknown ID got error %v, want ErrNotFound", err)
+	}
+
+	otherAK, err := client.AttestationKeyECC(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer otherAK.Close()
+	attestation, err = otherAK.Attest(client.AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+	if _, _, err := registry.Verify(ctx, challenge.ID, attestation, nonce); err == nil {
+		t.Error("Verify() succeeded with an attestation by another AK")
+	}
+}
+
+func TestFinishFailures(t *testing.T) {
+	ctx := context.Background()
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ek, err := client.EndorsementKeyRSA(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ek.Close()
+	ak, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	akPub, err := ak.PublicArea().Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewMemoryStore()
+	registry, err := New(Config{Store: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The attestation must be over the secret of the credential.
+	challenge, err := registry.Begin(ctx, Request{EKPub: ek.PublicKey(), AKPub: akPub})
+	if err != nil {
+		t.Fatal(err)
+	}
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: []byte("wrong secret")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.Finish(ctx, challenge.ID, attestation); err == nil {
+		t.Error("Finish() succeeded without the secret of the credential")
+	}
+
+	// The challenge must not be expired.
+	challenge, err = registry.Begin(ctx, Request{EKPub: ek.PublicKey(), AKPub: akPub})
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := ek.ActivateCredential(ak, challenge.Credential)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attestation, err = ak.Attest(client.AttestOpts{Nonce: secret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry.now = func() time.Time { return time.Now().Add(time.Hour) }
+	if _, err := registry.Finish(ctx, challenge.ID, attestation); err == nil {
+		t.Error("Finish() succeeded with an expired challenge")
+	}
+
+	if records, err := store.List(ctx); err != nil || len(records) != 0 {
+		t.Errorf("got enrollments %v (%v) after failed enrollments, want none", records, err)
+	}
+}
+
+func TestBeginRequiresEKCert(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ek, err := client.EndorsementKeyRSA(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ek.Close()
+	ak, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	akPub, err := ak.PublicArea().Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry, err := New(Config{Store: NewMemoryStore(), EKRoots: server.GceEKRoots})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.Begin(context.Background(), Request{EKPub: ek.PublicKey(), AKPub: akPub}); err == nil {
+		t.Error("Begin() succeeded without an EK certificate")
+	}
+}
+
+func TestBeginFailures(t *testing.T) {
+	ctx := context.Background()
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ek, err := client.EndorsementKeyRSA(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ek.Close()
+
+	registry, err := New(Config{Store: NewMemoryStore()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unrestricted := client.AKTemplateRSA()
+	unrestricted.Attributes &^= tpm2.FlagRestricted
+	decrypt := client.AKTemplateRSA()
+	decrypt.Attributes |= tpm2.FlagDecrypt
+	notFixed := client.AKTemplateRSA()
+	notFixed.Attributes &^= tpm2.FlagFixedTPM | tpm2.FlagFixedParent
+	for _, template := range []tpm2.Public{unrestricted, decrypt, notFixed} {
+		akPub, err := template.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := registry.Begin(ctx, Request{EKPub: ek.PublicKey(), AKPub: akPub}); err == nil {
+			t.Errorf("Begin() succeeded with an AK with attributes %#x", uint32(template.Attributes))
+		}
+	}
+
+	akPub, err := client.AKTemplateRSA().Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.Begin(ctx, Request{EKPub: ek.PublicKey(), AKPub: akPub}); err != nil {
+		t.Fatalf("Begin() failed: %v", err)
+	}
+	if _, err := registry.Begin(ctx, Request{EKPub: ek.PublicKey(), AKPub: akPub}); !errors.Is(err, ErrPendingEnrollment) {
+		t.Errorf("Begin() with a pending enrollment got error %v, want ErrPendingEnrollment", err)
+	}
+	registry.now = func() time.Time { return time.Now().Add(time.Hour) }
+	if _, err := registry.Begin(ctx, Request{EKPub: ek.PublicKey(), AKPub: akPub}); err != nil {
+		t.Errorf("Begin() after the pending enrollment expired failed: %v", err)
+	}
+}
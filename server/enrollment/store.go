@@ -0,0 +1,91 @@
+package enrollment
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+)
+
+// ErrNotFound is returned by a Store when there is no Record with the ID.
+var ErrNotFound = errors.New("enrollment not found")
+
+// Store persists the Records of enrolled machines. It can be backed by any
+// database, and must be safe for concurrent use.
+type Store interface {
+	// Put creates or replaces the Record with the same ID.
+	Put(ctx context.Context, record *Record) error
+	// Get returns the Record with the ID, or ErrNotFound.
+	Get(ctx context.Context, id string) (*Record, error)
+	// Delete removes the Record with the ID, or returns ErrNotFound.
+	Delete(ctx context.Context, id string) error
+	// List returns all the Records, sorted by ID.
+	List(ctx context.Context) ([]*Record, error)
+}
+
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[string]*Record
+}
+
+// NewMemoryStore returns a Store keeping the Records in memory, for tests and
+// services which do not need to persist enrollments.
+func NewMemoryStore() Store {
+	return &memoryStore{records: make(map[string]*Record)}
+}
+
+func (s *memoryStore) Put(_ context.Context, record *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.ID] = record.clone()
+	return nil
+}
+
+func (s *memoryStore) Get(_ context.Context, id string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return record.clone(), nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.records[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.records, id)
+	return nil
+}
+
+func (s *memoryStore) List(_ context.Context) ([]*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records := make([]*Record, 0, len(s.records))
+	for _, record := range s.records {
+		records = append(records, record.clone())
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+	return records, nil
+}
+
+// clone returns a deep copy of the record, so callers of the memoryStore
+// cannot modify the stored records.
+func (r *Record) clone() *Record {
+	c := *r
+	c.EKPub = append([]byte(nil), r.EKPub...)
+	c.EKCert = append([]byte(nil), r.EKCert...)
+	c.AKPub = append([]byte(nil), r.AKPub...)
+	c.AKCert = append([]byte(nil), r.AKCert...)
+	if r.ClockInfo != nil {
+		c.ClockInfo = proto.Clone(r.ClockInfo).(*pb.TPMClockInfo)
+	}
+	return &c
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"google.golang.org/protobuf/proto"
+)
+
+// VerifyAttestationEnvelope checks that the AttestationEnvelope is compatible
+// with this verifier, and then verifies the Attestation and TEE evidence it
+// carries with VerifyAttestation. See VerifyAttestation for the checks
+// performed.
+//
+// Non-critical evidence which cannot be verified is ignored. Currently, TDX
+// quotes cannot be verified.
+func VerifyAttestationEnvelope(envelope *pb.AttestationEnvelope, opts VerifyOpts) (*pb.MachineState, error) {
+	attestation, err := unwrapEnvelope(envelope)
+	if err != nil {
+		return nil, verificationError(CodeInvalidEnvelope, "bad attestation envelope: %w", err)
+	}
+	return VerifyAttestation(attestation, opts)
+}
+
+// unwrapEnvelope returns the Attestation of the envelope, with the TEE
+// evidence which can be verified moved to the Attestation.
+func unwrapEnvelope(envelope *pb.AttestationEnvelope) (*pb.Attestation, error) {
+	if envelope.GetVersion() != pb.EnvelopeVersion {
+		return nil, fmt.Errorf("unsupported version %d, expected %d", envelope.GetVersion(), pb.EnvelopeVersion)
+	}
+	if envelope.GetAttestation() == nil {
+		return nil, errors.New("missing attestation")
+	}
+	if envelope.GetAttestation().GetTeeAttestation() != nil {
+		return nil, errors.New("TEE attestation must be in the envelope's TEE evidence")
+	}
+
+	attestation := proto.Clone(envelope.GetAttestation()).(*pb.Attestation)
+	for i, evidence := range envelope.GetTeeEvidence() {
+		switch e := evidence.GetEvidence().(type) {
+		case *pb.TeeEvidence_SevSnpAttestation:
+			if attestation.GetTeeAttestation() != nil {
+				return nil, errors.New("multiple SEV-SNP attestation reports")
+			}
+			attestation.TeeAttestation = &pb.Attestation_SevSnpAttestation{
+				SevSnpAttestation: e.SevSnpAttestation,
+			}
+		case *pb.TeeEvidence_TdxAttestation:
+			if evidence.GetCritical() {
+				return nil, errors.New("verifying TDX quotes is not supported")
+			}
+		default:
+			// Evidence from a newer version of the library.
+			if evidence.GetCritical() {
+				return nil, fmt.Errorf("unrecognized critical TEE evidence at index %d", i)
+			}
+		}
+	}
+	return attestation, nil
+}
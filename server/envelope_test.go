@@ -0,0 +1,133 @@
+package server
+
+import (
+	"crypto"
+	"testing"
+
+	spb "github.com/google/go-sev-guest/proto/sevsnp"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestVerifyAttestationEnvelope(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	ak, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+
+	nonce := []byte("super secret nonce")
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+	opts := VerifyOpts{
+		Nonce:      nonce,
+		TrustedAKs: []crypto.PublicKey{ak.PublicKey()},
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*pb.AttestationEnvelope)
+		wantErr bool
+	}{
+		{"Valid", func(*pb.AttestationEnvelope) {}, false},
+		{"MissingVersion", func(e *pb.AttestationEnvelope) { e.Version = 0 }, true},
+		{"NewerVersion", func(e *pb.AttestationEnvelope) { e.Version = pb.EnvelopeVersion + 1 }, true},
+		{"MissingAttestation", func(e *pb.AttestationEnvelope) { e.Attestation = nil }, true},
+		{"NonCriticalUnknownEvidence", func(e *pb.AttestationEnvelope) {
+			e.TeeEvidence = append(e.TeeEvidence, &pb.TeeEvidence{})
+		}, false},
+		{"CriticalUnknownEvidence", func(e *pb.AttestationEnvelope) {
+			e.TeeEvidence = append(e.TeeEvidence, &pb.TeeEvidence{Critical: true})
+		}, true},
+		{"NonCriticalTdx", func(e *pb.AttestationEnvelope) {
+			e.TeeEvidence = append(e.TeeEvidence, &pb.TeeEvidence{
+				Evidence: &pb.TeeEvidence_TdxAttestation{TdxAttestation: &pb.TdxAttestation{Quote: []byte("quote")}},
+			})
+		}, false},
+		{"CriticalTdx", func(e *pb.AttestationEnvelope) {
+			e.TeeEvidence = append(e.TeeEvidence, &pb.TeeEvidence{
+				Critical: true,
+				Evidence: &pb.TeeEvidence_TdxAttestation{TdxAttestation: &pb.TdxAttestation{Quote: []byte("quote")}},
+			})
+		}, true},
+		{"TeeAttestationInAttestation", func(e *pb.AttestationEnvelope) {
+			e.Attestation.TeeAttestation = &pb.Attestation_SevSnpAttestation{SevSnpAttestation: &spb.Attestation{}}
+		}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			envelope := client.NewAttestationEnvelope(attestation)
+			tc.modify(envelope)
+			_, err := VerifyAttestationEnvelope(envelope, opts)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("VerifyAttestationEnvelope() returned error %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnwrapEnvelopeMovesSevSnpAttestation(t *testing.T) {
+	report := &spb.Attestation{Report: &spb.Report{Version: 2}}
+	attestation := &pb.Attestation{
+		AkPub:          []byte("ak"),
+		TeeAttestation: &pb.Attestation_SevSnpAttestation{SevSnpAttestation: report},
+	}
+	envelope := client.NewAttestationEnvelope(attestation)
+	if attestation.GetSevSnpAttestation() == nil {
+		t.Error("NewAttestationEnvelope modified the attestation")
+	}
+	if envelope.GetAttestation().GetTeeAttestation() != nil {
+		t.Error("NewAttestationEnvelope left the SEV-SNP report in the attestation")
+	}
+	if len(envelope.GetTeeEvidence()) != 1 || !envelope.GetTeeEvidence()[0].GetCritical() {
+		t.Fatalf("got TEE evidence %v, want one critical SEV-SNP report", envelope.GetTeeEvidence())
+	}
+
+	unwrapped, err := unwrapEnvelope(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(unwrapped, attestation) {
+		t.Errorf("unwrapEnvelope() = %v, want %v", unwrapped, attestation)
+	}
+
+	envelope.TeeEvidence = append(envelope.TeeEvidence, envelope.TeeEvidence[0])
+	if _, err := unwrapEnvelope(envelope); err == nil {
+		t.Error("unwrapEnvelope succeeded with multiple SEV-SNP reports")
+	}
+}
+
+func TestUnknownEvidenceFromNewerVersion(t *testing.T) {
+	// Simulate TEE evidence of a type added in the future.
+	evidence, err := proto.Marshal(&pb.TeeEvidence{Critical: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	evidence = protowire.AppendTag(evidence, 1000, protowire.BytesType)
+	evidence = protowire.AppendBytes(evidence, []byte("future evidence"))
+
+	parsed := &pb.TeeEvidence{}
+	if err := proto.Unmarshal(evidence, parsed); err != nil {
+		t.Fatal(err)
+	}
+	envelope := &pb.AttestationEnvelope{
+		Version:     pb.EnvelopeVersion,
+		Attestation: &pb.Attestation{},
+		TeeEvidence: []*pb.TeeEvidence{parsed},
+	}
+	if _, err := unwrapEnvelope(envelope); err == nil {
+		t.Error("unwrapEnvelope succeeded with unknown critical evidence")
+	}
+	parsed.Critical = false
+	if _, err := unwrapEnvelope(envelope); err != nil {
+		t.Errorf("unwrapEnvelope failed with unknown non-critical evidence: %v", err)
+	}
+}
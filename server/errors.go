@@ -0,0 +1,159 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode is a machine-readable reason for a verification failure, which
+// services can map to metrics or user-facing messages.
+type ErrorCode int
+
+// Reasons for verification failures.
+const (
+	// CodeUnknown is used for errors not returned by a verifier.
+	CodeUnknown ErrorCode = iota
+	// CodeBadOptions means the VerifyOpts are invalid.
+	CodeBadOptions
+	// CodeInvalidAK means the AK public area or certificate is malformed.
+	CodeInvalidAK
+	// CodeUntrustedAK means the AK is not one of the TrustedAKs, or its
+	// certificate does not chain to the TrustedRootCerts.
+	CodeUntrustedAK
+	// CodeNoSupportedQuote means the attestation has no quote with a supported
+	// PCR hash algorithm.
+	CodeNoSupportedQuote
+	// CodeInvalidQuote means a quote is malformed.
+	CodeInvalidQuote
+	// CodeQuoteSignature means the quote is not signed by the AK.
+	CodeQuoteSignature
+	// CodeNonceMismatch means the quote is not over the expected nonce or data.
+	CodeNonceMismatch
+	// CodePCRDigestMismatch means the PCR values do not match the quote.
+	CodePCRDigestMismatch
+	// CodeSHA1NotAllowed means only SHA-1 PCRs could be verified, and
+	// VerifyOpts.AllowSHA1 is not set.
+	CodeSHA1NotAllowed
+	// CodeInvalidEventLog means the TCG event log could not be parsed.
+	CodeInvalidEventLog
+	// CodePCRMismatch means replaying the TCG event log does not result in the
+	// quoted PCR values. VerificationError.PCRs lists the mismatched PCRs.
+	CodePCRMismatch
+	// CodeInvalidCanonicalEventLog means the Canonical Event Log could not be
+	// parsed, or does not replay to the quoted PCR values.
+	CodeInvalidCanonicalEventLog
+	// CodeTEEAttestation means the TEE attestation failed to verify, or does
+	// not match the confidential computing technology in the event log.
+	CodeTEEAttestation
+	// CodeInvalidEnvelope means an AttestationEnvelope is malformed or has
+	// unsupported critical evidence.
+	CodeInvalidEnvelope
+	// CodeInvalidIMALog means the IMA measurement list could not be parsed,
+	// does not replay to the quoted PCRs 10 and 11, or lacks a kernel module
+	// of the Canonical Event Log.
+	CodeInvalidIMALog
+	// CodePolicyViolation means the verified MachineState does not comply
+	// with the Policy of a Pipeline.
+	CodePolicyViolation
+	// CodeEventLogOmitted means the attestation omitted the TCG event log,
+	// and VerifyOpts.AllowOmittedEventLog is not set.
+	CodeEventLogOmitted
+	// CodeRevoked means the verified attestation has an artifact revoked by
+	// the RevocationChecker of a Pipeline, or the revocation list could not
+	// be fetched.
+	CodeRevoked
+)
+
+var codeNames = map[ErrorCode]string{
+	CodeUnknown:                  "UNKNOWN",
+	CodeBadOptions:               "BAD_OPTIONS",
+	CodeInvalidAK:                "INVALID_AK",
+	CodeUntrustedAK:              "UNTRUSTED_AK",
+	CodeNoSupportedQuote:         "NO_SUPPORTED_QUOTE",
+	CodeInvalidQuote:             "INVALID_QUOTE",
+	CodeQuoteSignature:           "QUOTE_SIGNATURE",
+	CodeNonceMismatch:            "NONCE_MISMATCH",
+	CodePCRDigestMismatch:        "PCR_DIGEST_MISMATCH",
+	CodeSHA1NotAllowed:           "SHA1_NOT_ALLOWED",
+	CodeInvalidEventLog:          "INVALID_EVENT_LOG",
+	CodePCRMismatch:              "PCR_MISMATCH",
+	CodeInvalidCanonicalEventLog: "INVALID_CANONICAL_EVENT_LOG",
+	CodeTEEAttestation:           "TEE_ATTESTATION",
+	CodeInvalidEnvelope:          "INVALID_ENVELOPE",
+	CodeInvalidIMALog:            "INVALID_IMA_LOG",
+	CodePolicyViolation:          "POLICY_VIOLATION",
+	CodeEventLogOmitted:          "EVENT_LOG_OMITTED",
+	CodeRevoked:                  "REVOKED",
+}
+
+// String returns a stable name for the code, like "QUOTE_SIGNATURE", which is
+// suitable as a metric label.
+func (c ErrorCode) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("ErrorCode(%d)", int(c))
+}
+
+// VerificationError is returned by VerifyAttestation and the other verifiers.
+// Use errors.As to get its Code, or errors.Is to compare it against one of the
+// Err values below.
+type VerificationError struct {
+	Code ErrorCode
+	// PCRs are the mismatched PCR indexes, for CodePCRMismatch.
+	PCRs []int
+	// Err is the underlying error, describing the failure.
+	Err error
+}
+
+func (e *VerificationError) Error() string {
+	if e.Err == nil {
+		return "verification failed: " + e.Code.String()
+	}
+	return e.Err.Error()
+}
+
+func (e *VerificationError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is a VerificationError with the same Code.
+func (e *VerificationError) Is(target error) bool {
+	t, ok := target.(*VerificationError)
+	return ok && t.Code == e.Code
+}
+
+// Errors to compare verification errors against with errors.Is.
+var (
+	ErrBadOptions               = &VerificationError{Code: CodeBadOptions}
+	ErrInvalidAK                = &VerificationError{Code: CodeInvalidAK}
+	ErrUntrustedAK              = &VerificationError{Code: CodeUntrustedAK}
+	ErrNoSupportedQuote         = &VerificationError{Code: CodeNoSupportedQuote}
+	ErrInvalidQuote             = &VerificationError{Code: CodeInvalidQuote}
+	ErrQuoteSignature           = &VerificationError{Code: CodeQuoteSignature}
+	ErrNonceMismatch            = &VerificationError{Code: CodeNonceMismatch}
+	ErrPCRDigestMismatch        = &VerificationError{Code: CodePCRDigestMismatch}
+	ErrSHA1NotAllowed           = &VerificationError{Code: CodeSHA1NotAllowed}
+	ErrInvalidEventLog          = &VerificationError{Code: CodeInvalidEventLog}
+	ErrPCRMismatch              = &VerificationError{Code: CodePCRMismatch}
+	ErrInvalidCanonicalEventLog = &VerificationError{Code: CodeInvalidCanonicalEventLog}
+	ErrTEEAttestation           = &VerificationError{Code: CodeTEEAttestation}
+	ErrInvalidEnvelope          = &VerificationError{Code: CodeInvalidEnvelope}
+	ErrInvalidIMALog            = &VerificationError{Code: CodeInvalidIMALog}
+	ErrEventLogOmitted          = &VerificationError{Code: CodeEventLogOmitted}
+	ErrRevoked                  = &VerificationError{Code: CodeRevoked}
+)
+
+// ErrorCodeOf returns the Code of the VerificationError in err's chain, or
+// CodeUnknown if there is none.
+func ErrorCodeOf(err error) ErrorCode {
+	var vErr *VerificationError
+	if errors.As(err, &vErr) {
+		return vErr.Code
+	}
+	return CodeUnknown
+}
+
+func verificationError(code ErrorCode, format string, args ...interface{}) error {
+	return &VerificationError{Code: code, Err: fmt.Errorf(format, args...)}
+}
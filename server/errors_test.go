@@ -0,0 +1,139 @@
+package server
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm/tpm2"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestVerifyAttestationErrorCodes(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	ak, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+	otherAK, err := client.AttestationKeyECC(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer otherAK.Close()
+
+	nonce := []byte("super secret nonce")
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+	opts := VerifyOpts{Nonce: nonce, TrustedAKs: []crypto.PublicKey{ak.PublicKey()}}
+
+	modified := func(modify func(*attestpb.Attestation)) *attestpb.Attestation {
+		att := proto.Clone(attestation).(*attestpb.Attestation)
+		modify(att)
+		return att
+	}
+	tests := []struct {
+		name        string
+		attestation *attestpb.Attestation
+		opts        VerifyOpts
+		want        error
+	}{
+		{"BadOptions", attestation, VerifyOpts{Nonce: nonce}, ErrBadOptions},
+		{"InvalidAK", modified(func(a *attestpb.Attestation) { a.AkPub = []byte("bad") }), opts, ErrInvalidAK},
+		{"UntrustedAK", attestation, VerifyOpts{Nonce: nonce, TrustedAKs: []crypto.PublicKey{otherAK.PublicKey()}}, ErrUntrustedAK},
+		{"NoSupportedQuote", modified(func(a *attestpb.Attestation) { a.Quotes = nil }), opts, ErrNoSupportedQuote},
+		{"NonceMismatch", attestation, VerifyOpts{Nonce: []byte("other nonce"), TrustedAKs: opts.TrustedAKs}, ErrNonceMismatch},
+		{"QuoteSignature", modified(func(a *attestpb.Attestation) {
+			for _, q := range a.Quotes {
+				q.Quote = append(q.Quote, 0)
+			}
+		}), opts, ErrQuoteSignature},
+		{"PCRDigestMismatch", modified(func(a *attestpb.Attestation) {
+			for _, q := range a.Quotes {
+				q.Pcrs.Pcrs[0][0] ^= 1
+			}
+		}), opts, ErrPCRDigestMismatch},
+		{"InvalidCanonicalEventLog", modified(func(a *attestpb.Attestation) { a.CanonicalEventLog = []byte("bad") }), opts, ErrInvalidCanonicalEventLog},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := VerifyAttestation(tc.attestation, tc.opts)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("VerifyAttestation() = %v, want %v", err, tc.want)
+			}
+			want := tc.want.(*VerificationError).Code
+			if code := ErrorCodeOf(err); code != want {
+				t.Errorf("ErrorCodeOf() = %v, want %v", code, want)
+			}
+		})
+	}
+}
+
+func TestVerifyAttestationPCRMismatch(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	ak, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+
+	// Extending PCRs without updating the event log breaks the replay.
+	for _, hash := range []tpm2.Algorithm{tpm2.AlgSHA1, tpm2.AlgSHA256} {
+		if err := extendPCRsRandomly(rwc, tpm2.PCRSelection{Hash: hash, PCRs: []int{4}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	nonce := []byte("super secret nonce")
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+
+	_, err = VerifyAttestation(attestation, VerifyOpts{Nonce: nonce, TrustedAKs: []crypto.PublicKey{ak.PublicKey()}})
+	var vErr *VerificationError
+	if !errors.As(err, &vErr) || vErr.Code != CodePCRMismatch {
+		t.Fatalf("VerifyAttestation() = %v, want a PCR mismatch", err)
+	}
+	if !reflect.DeepEqual(vErr.PCRs, []int{4}) {
+		t.Errorf("got mismatched PCRs %v, want [4]", vErr.PCRs)
+	}
+}
+
+func TestVerificationError(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", verificationError(CodeQuoteSignature, "bad signature"))
+	if !errors.Is(err, ErrQuoteSignature) {
+		t.Errorf("errors.Is(%v, ErrQuoteSignature) = false", err)
+	}
+	if errors.Is(err, ErrNonceMismatch) {
+		t.Errorf("errors.Is(%v, ErrNonceMismatch) = true", err)
+	}
+	if err.Error() != "wrapped: bad signature" {
+		t.Errorf("got error string %q", err.Error())
+	}
+	if code := ErrorCodeOf(errors.New("other")); code != CodeUnknown {
+		t.Errorf("ErrorCodeOf() = %v, want %v", code, CodeUnknown)
+	}
+	if CodePCRMismatch.String() != "PCR_MISMATCH" {
+		t.Errorf("got code name %q", CodePCRMismatch.String())
+	}
+
+	// Grouped errors match any of their errors.
+	grouped := createGroupedError("", []error{errors.New("first"), ErrTEEAttestation})
+	if !errors.Is(grouped, ErrTEEAttestation) {
+		t.Errorf("errors.Is(%v, ErrTEEAttestation) = false", grouped)
+	}
+	if code := ErrorCodeOf(grouped); code != CodeTEEAttestation {
+		t.Errorf("ErrorCodeOf() = %v, want %v", code, CodeTEEAttestation)
+	}
+}
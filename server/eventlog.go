@@ -7,6 +7,8 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/google/go-attestation/attest"
 	"github.com/google/go-tpm-tools/cel"
@@ -38,10 +40,12 @@ var (
 // returned. Callers can inspect individual parsing errors by examining
 // `MachineStateError.Errors`.
 //
+// The GCE platform claims of the log are only parsed for the GCETrust mode.
+//
 // It is the caller's responsibility to ensure that the passed PCR values can be
 // trusted. Users can establish trust in PCR values by either calling
 // client.ReadPCRs() themselves or by verifying the values via a PCR quote.
-func parsePCClientEventLog(rawEventLog []byte, pcrs *tpmpb.PCRs, loader Bootloader) (*pb.MachineState, error) {
+func parsePCClientEventLog(rawEventLog []byte, pcrs *tpmpb.PCRs, loader Bootloader, mode TrustMode) (*pb.MachineState, error) {
 	var errors []error
 	events, err := parseReplayHelper(rawEventLog, pcrs)
 	if err != nil {
@@ -51,7 +55,7 @@ func parsePCClientEventLog(rawEventLog []byte, pcrs *tpmpb.PCRs, loader Bootload
 	cryptoHash, _ := tpm2.Algorithm(pcrs.GetHash()).Hash()
 
 	rawEvents := convertToPbEvents(cryptoHash, events)
-	platform, err := getPlatformState(cryptoHash, rawEvents)
+	platform, err := getPlatformState(cryptoHash, rawEvents, mode)
 	if err != nil {
 		errors = append(errors, err)
 	}
@@ -83,11 +87,18 @@ func parsePCClientEventLog(rawEventLog []byte, pcrs *tpmpb.PCRs, loader Bootload
 	}, createGroupedError("failed to fully parse MachineState:", errors)
 }
 
-func parseCanonicalEventLog(rawCanonicalEventLog []byte, pcrs *tpmpb.PCRs) (*pb.MachineState, error) {
+func parseCanonicalEventLog(rawCanonicalEventLog []byte, pcrs *tpmpb.PCRs, allowTimestamps bool) (*pb.MachineState, error) {
 	decodedCEL, err := cel.DecodeToCEL(bytes.NewBuffer(rawCanonicalEventLog))
 	if err != nil {
 		return nil, err
 	}
+	if !allowTimestamps {
+		for _, record := range decodedCEL.Records {
+			if record.Timestamp.Source != cel.NoTimestamps {
+				return nil, fmt.Errorf("record %d is timestamped, but the experimental CEL timestamps are not allowed", record.RecNum)
+			}
+		}
+	}
 	// Validate the COS event log first.
 	if err := decodedCEL.Replay(pcrs); err != nil {
 		return nil, err
@@ -103,6 +114,18 @@ func parseCanonicalEventLog(rawCanonicalEventLog []byte, pcrs *tpmpb.PCRs) (*pb.
 	}, err
 }
 
+// VerifyCanonicalEventLog replays a COS Canonical Event Log against the PCRs,
+// which must come from a verified quote, and returns the COS state recorded
+// in the log. Unlike VerifyAttestation, it accepts timestamped records, as it
+// is meant for the logs of the caller.
+func VerifyCanonicalEventLog(rawCanonicalEventLog []byte, pcrs *tpmpb.PCRs) (*pb.AttestedCosState, error) {
+	state, err := parseCanonicalEventLog(rawCanonicalEventLog, pcrs, true)
+	if err != nil {
+		return nil, &VerificationError{Code: CodeInvalidCanonicalEventLog, Err: err}
+	}
+	return state.GetCos(), nil
+}
+
 func contains(set [][]byte, value []byte) bool {
 	for _, setItem := range set {
 		if bytes.Equal(value, setItem) {
@@ -120,29 +143,42 @@ func getVerifiedCosState(coscel cel.CEL, pcrs *tpmpb.PCRs) (*pb.AttestedCosState
 	cosState.Container.OverriddenEnvVars = make(map[string]string)
 
 	seenSeparator := false
-	for _, record := range coscel.Records {
-		// COS State only comes from the CosEventPCR
-		if record.PCR != cel.CosEventPCR {
+	// hashAlgos are the hash algorithms recorded in the header of the log.
+	var hashAlgos []crypto.Hash
+	for i, record := range coscel.Records {
+		// COS State only comes from the CosEventPCR, and the
+		// KernelModulePCR.
+		if record.PCR != cel.CosEventPCR && record.PCR != cel.KernelModulePCR {
 			return nil, fmt.Errorf("found unexpected PCR %d in CEL log", record.PCR)
 		}
 
 		// The Content.Type is not verified at this point, so we have to fail
 		// if we see any events that we do not understand. This ensures that
 		// we either verify the digest of event event in this PCR, or we fail
-		// to replay the event log.
+		// to replay the event log. COS events of unknown types are still
+		// parsed, with their raw content.
 		// TODO: See if we can fix this to have the Content Type be verified.
 		cosTlv, err := record.Content.ParseToCosTlv()
 		if err != nil {
 			return nil, err
 		}
 
-		// verify digests for the cos cel content
-		if err := cel.VerifyDigests(cosTlv, record.Digests); err != nil {
+		// Events of unknown types are only tolerated in the CosEventPCR.
+		if int(record.PCR) != cosTlv.EventType.PCR() {
+			return nil, fmt.Errorf("found COS Event Type %v in unexpected PCR %d", cosTlv.EventType, record.PCR)
+		}
+
+		// verify digests for the cos cel content and its timestamp
+		if err := record.VerifyDigests(cosTlv); err != nil {
+			return nil, err
+		}
+		if err := addEventTimestamp(cosState, record, cosTlv.EventType); err != nil {
 			return nil, err
 		}
 
-		// TODO: Add support for post-separator container data
-		if seenSeparator {
+		// TODO: Add support for post-separator container data, besides the
+		// checkpoints of the workload output.
+		if seenSeparator && cosTlv.EventType != cel.WorkloadOutputType && cosTlv.EventType != cel.KernelModuleType {
 			return nil, fmt.Errorf("found COS Event Type %v after LaunchSeparator event", cosTlv.EventType)
 		}
 
@@ -191,17 +227,293 @@ func getVerifiedCosState(coscel cel.CEL, pcrs *tpmpb.PCRs) (*pb.AttestedCosState
 				return nil, err
 			}
 			cosState.Container.OverriddenEnvVars[envName] = envVal
+		case cel.HostNetworkType:
+			hostNetwork, err := strconv.ParseBool(string(cosTlv.EventContent))
+			if err != nil {
+				return nil, fmt.Errorf("invalid HostNetwork event: %v", err)
+			}
+			cosState.Container.HostNetwork = hostNetwork
+
+		case cel.ReadOnlyRootfsType:
+			readOnlyRootfs, err := strconv.ParseBool(string(cosTlv.EventContent))
+			if err != nil {
+				return nil, fmt.Errorf("invalid ReadOnlyRootfs event: %v", err)
+			}
+			cosState.Container.ReadOnlyRootfs = readOnlyRootfs
+
+		case cel.AddedCapabilityType:
+			cosState.Container.AddedCapabilities = append(cosState.Container.AddedCapabilities, string(cosTlv.EventContent))
+
+		case cel.MountType:
+			mount, err := cel.ParseMount(string(cosTlv.EventContent))
+			if err != nil {
+				return nil, err
+			}
+			cosState.Container.Mounts = append(cosState.Container.Mounts, &pb.Mount{
+				Type:        mount.Type,
+				Source:      mount.Source,
+				Destination: mount.Destination,
+				ReadOnly:    mount.ReadOnly,
+			})
+
+		case cel.ImageLayerType:
+			cosState.Container.ImageLayerDigests = append(cosState.Container.ImageLayerDigests, string(cosTlv.EventContent))
+
+		case cel.DeviceType:
+			cosState.Container.Devices = append(cosState.Container.Devices, string(cosTlv.EventContent))
+
+		case cel.CDIDeviceType:
+			name, deviceNodes, err := cel.ParseCDIDevice(string(cosTlv.EventContent))
+			if err != nil {
+				return nil, err
+			}
+			cosState.Container.CdiDevices = append(cosState.Container.CdiDevices, &pb.CDIDevice{Name: name, DeviceNodes: deviceNodes})
+
+		case cel.WorkloadKeyType:
+			if cosState.Container.GetWorkloadKeyDigest() != "" {
+				return nil, fmt.Errorf("found more than one WorkloadKey event")
+			}
+			cosState.Container.WorkloadKeyDigest = string(cosTlv.EventContent)
+
+		case cel.WorkloadConfigType:
+			if cosState.Container.GetWorkloadConfigDigest() != "" {
+				return nil, fmt.Errorf("found more than one WorkloadConfig event")
+			}
+			cosState.Container.WorkloadConfigDigest = string(cosTlv.EventContent)
+
+		case cel.UserType:
+			if cosState.Container.GetUser() != "" {
+				return nil, fmt.Errorf("found more than one User event")
+			}
+			cosState.Container.User = string(cosTlv.EventContent)
+
+		case cel.UserNamespaceType:
+			userNamespace, err := strconv.ParseBool(string(cosTlv.EventContent))
+			if err != nil {
+				return nil, fmt.Errorf("invalid UserNamespace event: %v", err)
+			}
+			cosState.Container.UserNamespace = userNamespace
+
+		case cel.SeccompProfileType:
+			if cosState.Container.GetSeccompProfile() != "" {
+				return nil, fmt.Errorf("found more than one SeccompProfile event")
+			}
+			cosState.Container.SeccompProfile = string(cosTlv.EventContent)
+
+		case cel.AppArmorProfileType:
+			if cosState.Container.GetApparmorProfile() != "" {
+				return nil, fmt.Errorf("found more than one AppArmorProfile event")
+			}
+			cosState.Container.ApparmorProfile = string(cosTlv.EventContent)
+
+		case cel.LaunchPolicyDocumentType:
+			if cosState.Container.GetLaunchPolicyDigest() != "" {
+				return nil, fmt.Errorf("found more than one LaunchPolicyDocument event")
+			}
+			cosState.Container.LaunchPolicyDigest = string(cosTlv.EventContent)
+
+		case cel.LaunchPolicySignerType:
+			if cosState.Container.GetLaunchPolicySigner() != "" {
+				return nil, fmt.Errorf("found more than one LaunchPolicySigner event")
+			}
+			cosState.Container.LaunchPolicySigner = string(cosTlv.EventContent)
+
+		case cel.BootTimeType:
+			if cosState.Container.GetBootTime() != "" {
+				return nil, fmt.Errorf("found more than one BootTime event")
+			}
+			if _, err := time.Parse(time.RFC3339, string(cosTlv.EventContent)); err != nil {
+				return nil, fmt.Errorf("invalid BootTime event: %v", err)
+			}
+			cosState.Container.BootTime = string(cosTlv.EventContent)
+
+		case cel.ClockSourceType:
+			if cosState.Container.GetClockSource() != "" {
+				return nil, fmt.Errorf("found more than one ClockSource event")
+			}
+			cosState.Container.ClockSource = string(cosTlv.EventContent)
+		case cel.AttestBeforeRunType:
+			if cosState.Container.GetAttestBeforeRun() {
+				return nil, fmt.Errorf("found more than one AttestBeforeRun event")
+			}
+			attestBeforeRun, err := strconv.ParseBool(string(cosTlv.EventContent))
+			if err != nil {
+				return nil, fmt.Errorf("invalid AttestBeforeRun event: %v", err)
+			}
+			cosState.Container.AttestBeforeRun = attestBeforeRun
+		case cel.ApprovalURLType:
+			if cosState.Container.GetApprovalUrl() != "" {
+				return nil, fmt.Errorf("found more than one ApprovalURL event")
+			}
+			cosState.Container.ApprovalUrl = string(cosTlv.EventContent)
+		case cel.ImagePlatformType:
+			if cosState.Container.GetImagePlatform() != "" {
+				return nil, fmt.Errorf("found more than one ImagePlatform event")
+			}
+			cosState.Container.ImagePlatform = string(cosTlv.EventContent)
+
+		case cel.ImageManifestDigestType:
+			if cosState.Container.GetImageManifestDigest() != "" {
+				return nil, fmt.Errorf("found more than one ImageManifestDigest event")
+			}
+			cosState.Container.ImageManifestDigest = string(cosTlv.EventContent)
+
+		case cel.WorkloadOutputType:
+			if !seenSeparator {
+				return nil, fmt.Errorf("found WorkloadOutput event before LaunchSeparator event")
+			}
+			output, err := cel.ParseWorkloadOutput(string(cosTlv.EventContent))
+			if err != nil {
+				return nil, err
+			}
+			// The log is append-only, so the checkpoints never cover fewer
+			// bytes.
+			if last := cosState.Container.GetWorkloadOutput(); last != nil && output.Size < last.GetSize() {
+				return nil, fmt.Errorf("WorkloadOutput event covers %d bytes, fewer than the %d bytes of the previous one", output.Size, last.GetSize())
+			}
+			cosState.Container.WorkloadOutput = &pb.WorkloadOutput{Size: output.Size, Digest: output.Digest}
+		case cel.KernelModuleType:
+			fileDigest, fileName, err := cel.ParseKernelModule(string(cosTlv.EventContent))
+			if err != nil {
+				return nil, err
+			}
+			cosState.KernelModules = append(cosState.KernelModules, &pb.KernelModule{FileDigest: fileDigest, FileName: fileName})
+		case cel.WorkloadNameType:
+			if cosState.GetWorkload().GetName() != "" {
+				return nil, fmt.Errorf("found more than one WorkloadName event")
+			}
+			if cosState.Workload == nil {
+				cosState.Workload = &pb.WorkloadState{}
+			}
+			cosState.Workload.Name = string(cosTlv.EventContent)
+		case cel.WorkloadBinaryType:
+			fileDigest, path, err := cel.ParseWorkloadBinary(string(cosTlv.EventContent))
+			if err != nil {
+				return nil, err
+			}
+			if cosState.Workload == nil {
+				cosState.Workload = &pb.WorkloadState{}
+			}
+			cosState.Workload.Binaries = append(cosState.Workload.Binaries, &pb.WorkloadBinary{FileDigest: fileDigest, Path: path})
+		case cel.LauncherType:
+			if cosState.GetLauncherVersion() != nil {
+				return nil, fmt.Errorf("found more than one Launcher event")
+			}
+			version, binaryDigest, err := cel.ParseLauncher(string(cosTlv.EventContent))
+			if err != nil {
+				return nil, err
+			}
+			major, minor, patch, err := cel.ParseSemanticVersion(version)
+			if err != nil {
+				return nil, err
+			}
+			cosState.LauncherVersion = &pb.SemanticVersion{Major: major, Minor: minor, Patch: patch}
+			cosState.LauncherBinaryDigest = binaryDigest
+		case cel.KernelLogType:
+			if cosState.GetKernelLog() != nil {
+				return nil, fmt.Errorf("found more than one KernelLog event")
+			}
+			logDigest, taint, err := cel.ParseKernelLog(string(cosTlv.EventContent))
+			if err != nil {
+				return nil, err
+			}
+			cosState.KernelLog = &pb.KernelLogState{Digest: logDigest, Taint: taint}
 		case cel.LaunchSeparatorType:
 			seenSeparator = true
+		case cel.HashAlgorithmsType:
+			if i != 0 {
+				return nil, fmt.Errorf("found HashAlgorithms event after the first record")
+			}
+			if hashAlgos, err = cel.ParseHashAlgorithms(string(cosTlv.EventContent)); err != nil {
+				return nil, err
+			}
+			for _, hashAlgo := range hashAlgos {
+				tpm2Alg, err := tpm2.HashToAlgorithm(hashAlgo)
+				if err != nil {
+					return nil, err
+				}
+				cosState.CelHashAlgos = append(cosState.CelHashAlgos, tpmpb.HashAlgo(tpm2Alg))
+			}
+		case cel.SchemaVersionType:
+			if cosState.GetSchemaVersion() != 0 {
+				return nil, fmt.Errorf("found more than one SchemaVersion event")
+			}
+			if cosState.SchemaVersion, err = cel.ParseSchemaVersion(string(cosTlv.EventContent)); err != nil {
+				return nil, err
+			}
 		default:
-			return nil, fmt.Errorf("found unknown COS Event Type %v", cosTlv.EventType)
+			// Logs with a schema version may have events of a newer schema,
+			// whose digests were verified above.
+			if cosState.GetSchemaVersion() == 0 || cosTlv.EventType.IsKnown() {
+				return nil, fmt.Errorf("found unknown COS Event Type %v", cosTlv.EventType)
+			}
+			cosState.UnknownEvents = append(cosState.UnknownEvents, &pb.CosEvent{
+				EventType:    uint32(cosTlv.EventType),
+				EventContent: cosTlv.EventContent,
+			})
 		}
 
+		// Every record has the digests of the hash algorithms of the header.
+		for _, hashAlgo := range hashAlgos {
+			if _, ok := record.Digests[hashAlgo]; !ok {
+				return nil, fmt.Errorf("CEL record %d has no %v digest recorded in the HashAlgorithms event", record.RecNum, hashAlgo)
+			}
+		}
 	}
 	return cosState, nil
 }
 
-func getPlatformState(hash crypto.Hash, events []*pb.Event) (*pb.PlatformState, error) {
+var celTimestampSources = map[cel.TimestampSource]pb.CelTimestampSource{
+	cel.TPMClockTimestamps:  pb.CelTimestampSource_CEL_TIMESTAMP_SOURCE_TPM_CLOCK,
+	cel.MonotonicTimestamps: pb.CelTimestampSource_CEL_TIMESTAMP_SOURCE_MONOTONIC,
+}
+
+// addEventTimestamp adds the timestamp of a verified record to the COS state,
+// checking that the timestamps of the log all come from the same clock, and
+// never decrease.
+func addEventTimestamp(cosState *pb.AttestedCosState, record cel.Record, eventType cel.CosType) error {
+	if record.Timestamp.Source == cel.NoTimestamps {
+		return nil
+	}
+	source, ok := celTimestampSources[record.Timestamp.Source]
+	if !ok {
+		return fmt.Errorf("unknown timestamp source %v of record %d", record.Timestamp.Source, record.RecNum)
+	}
+	if cosState.GetTimestampSource() == pb.CelTimestampSource_CEL_TIMESTAMP_SOURCE_UNSPECIFIED {
+		cosState.TimestampSource = source
+	} else if cosState.GetTimestampSource() != source {
+		return fmt.Errorf("record %d is timestamped from %v, previous records from %v", record.RecNum, source, cosState.GetTimestampSource())
+	}
+	timestamps := cosState.GetEventTimestamps()
+	if len(timestamps) > 0 {
+		last := timestamps[len(timestamps)-1]
+		if record.Timestamp.Value < last.GetTimestamp() {
+			return fmt.Errorf("timestamp %d of record %d is before timestamp %d of record %d", record.Timestamp.Value, record.RecNum, last.GetTimestamp(), last.GetRecordNumber())
+		}
+	}
+	cosState.EventTimestamps = append(timestamps, &pb.CosEventTimestamp{
+		RecordNumber: record.RecNum,
+		EventType:    uint32(eventType),
+		Timestamp:    record.Timestamp.Value,
+	})
+	return nil
+}
+
+// checkEventTimestamps checks that the events timestamped with the clock of
+// the TPM were not recorded after the quote.
+func checkEventTimestamps(cosState *pb.AttestedCosState, clockInfo *pb.TPMClockInfo) error {
+	if cosState.GetTimestampSource() != pb.CelTimestampSource_CEL_TIMESTAMP_SOURCE_TPM_CLOCK {
+		return nil
+	}
+	for _, timestamp := range cosState.GetEventTimestamps() {
+		if timestamp.GetTimestamp() > clockInfo.GetClock() {
+			return fmt.Errorf("timestamp %d of record %d is after the clock %d of the quote", timestamp.GetTimestamp(), timestamp.GetRecordNumber(), clockInfo.GetClock())
+		}
+	}
+	return nil
+}
+
+func getPlatformState(hash crypto.Hash, events []*pb.Event, mode TrustMode) (*pb.PlatformState, error) {
 	// We pre-compute the separator event hash, and check if the event type has
 	// been modified. We only trust events that come before a valid separator.
 	hasher := hash.New()
@@ -257,6 +569,12 @@ func getPlatformState(hash crypto.Hash, events []*pb.Event) (*pb.PlatformState,
 	}
 
 	state := &pb.PlatformState{}
+	if mode != GCETrust {
+		// Outside of GCE, the version and Non-Host info events are only
+		// claims of the firmware.
+		state.Firmware = &pb.PlatformState_ScrtmVersionId{ScrtmVersionId: versionString}
+		return state, nil
+	}
 	if gceVersion, err := ConvertSCRTMVersionToGCEFirmwareVersion(versionString); err == nil {
 		state.Firmware = &pb.PlatformState_GceVersion{GceVersion: gceVersion}
 	} else {
@@ -288,7 +606,7 @@ func parseReplayHelper(rawEventLog []byte, pcrs *tpmpb.PCRs) ([]attest.Event, er
 	}
 	events, err := eventLog.Verify(attestPcrs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to replay event log: %v", err)
+		return nil, fmt.Errorf("failed to replay event log: %w", err)
 	}
 	return events, nil
 }
@@ -441,7 +759,9 @@ func getLinuxKernelStateFromGRUB(grub *pb.GrubState) (*pb.LinuxKernelState, erro
 		cmdline = command[suffixAt:]
 	}
 
-	return &pb.LinuxKernelState{CommandLine: cmdline}, nil
+	state := &pb.LinuxKernelState{CommandLine: cmdline}
+	parseKernelCmdline(state)
+	return state, nil
 }
 
 func getGrubKernelCmdlineSuffix(grubCmd []byte) int {
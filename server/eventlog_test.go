@@ -382,7 +382,7 @@ func TestParseEventLogs(t *testing.T) {
 			hashName := pb.HashAlgo_name[int32(bank.Hash)]
 			subtestName := fmt.Sprintf("%s-%s", log.name, hashName)
 			t.Run(subtestName, func(t *testing.T) {
-				if _, err := parsePCClientEventLog(rawLog, bank, UnsupportedLoader); err != nil {
+				if _, err := parsePCClientEventLog(rawLog, bank, UnsupportedLoader, GCETrust); err != nil {
 					gErr, ok := err.(*GroupedError)
 					if !ok {
 						t.Errorf("ParseMachineState should return a GroupedError")
@@ -405,7 +405,7 @@ func TestParseMachineStateReplayFail(t *testing.T) {
 	pcrMap[0] = []byte{0, 0, 0, 0}
 	badPcrs.Pcrs = pcrMap
 
-	_, err := parsePCClientEventLog(Debian10GCE.RawLog, &badPcrs, UnsupportedLoader)
+	_, err := parsePCClientEventLog(Debian10GCE.RawLog, &badPcrs, UnsupportedLoader, GCETrust)
 	if err == nil {
 		t.Errorf("ParseMachineState should fail to replay the event log")
 	}
@@ -430,7 +430,7 @@ func TestSystemParseEventLog(t *testing.T) {
 		t.Fatalf("failed to read PCRs: %v", err)
 	}
 
-	if _, err = parsePCClientEventLog(evtLog, pcrs, UnsupportedLoader); err != nil {
+	if _, err = parsePCClientEventLog(evtLog, pcrs, UnsupportedLoader, GCETrust); err != nil {
 		t.Errorf("failed to parse MachineState: %v", err)
 	}
 }
@@ -468,7 +468,7 @@ func TestEmptyEventlog(t *testing.T) {
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			state, err := parsePCClientEventLog(emptyLog, c.pcrs, UnsupportedLoader)
+			state, err := parsePCClientEventLog(emptyLog, c.pcrs, UnsupportedLoader, GCETrust)
 			if err != nil {
 				t.Errorf("parsing empty eventlog: %v", err)
 			}
@@ -481,7 +481,7 @@ func TestEmptyEventlog(t *testing.T) {
 
 func TestParseSecureBootState(t *testing.T) {
 	for _, bank := range UbuntuAmdSevGCE.Banks {
-		msState, err := parsePCClientEventLog(UbuntuAmdSevGCE.RawLog, bank, UnsupportedLoader)
+		msState, err := parsePCClientEventLog(UbuntuAmdSevGCE.RawLog, bank, UnsupportedLoader, GCETrust)
 		if err != nil {
 			t.Errorf("failed to parse and replay log: %v", err)
 		}
@@ -539,7 +539,7 @@ func TestParsingCELEventLog(t *testing.T) {
 
 	for _, bank := range banks {
 		// pcrs can have any value here, since the coscel has no records, the replay should always success.
-		msState, err := parseCanonicalEventLog(buf.Bytes(), bank)
+		msState, err := parseCanonicalEventLog(buf.Bytes(), bank, false)
 		if err != nil {
 			t.Errorf("expecting no error from parseCanonicalEventLog(), but get %v", err)
 		}
@@ -558,6 +558,8 @@ func TestParsingCELEventLog(t *testing.T) {
 		{cel.ImageDigestType, cel.CosEventPCR, []byte("sha256:781d8dfdd92118436bd914442c8339e653b83f6bf3c1a7a98efcfb7c4fed7483")},
 		{cel.RestartPolicyType, cel.CosEventPCR, []byte(attestpb.RestartPolicy_Always.String())},
 		{cel.ImageIDType, cel.CosEventPCR, []byte("sha256:5DF4A1AC347DCF8CF5E9D0ABC04B04DB847D1B88D3B1CC1006F0ACB68E5A1F4B")},
+		{cel.ImageLayerType, cel.CosEventPCR, []byte("sha256:7050e35b49f5e348c4809f5eff915842962cb813f32062d3bbdd35c750dd7d01")},
+		{cel.ImageLayerType, cel.CosEventPCR, []byte("sha256:0da701e3b4d6bd2d5c4d6a0b1a2ad2dc3b0e9c5a1e4f3b2c1d0e9f8a7b6c5d4e")},
 		{cel.EnvVarType, cel.CosEventPCR, []byte("foo=bar")},
 		{cel.EnvVarType, cel.CosEventPCR, []byte("bar=baz")},
 		{cel.EnvVarType, cel.CosEventPCR, []byte("baz=foo=bar")},
@@ -565,6 +567,26 @@ func TestParsingCELEventLog(t *testing.T) {
 		{cel.ArgType, cel.CosEventPCR, []byte("--x")},
 		{cel.ArgType, cel.CosEventPCR, []byte("--y")},
 		{cel.ArgType, cel.CosEventPCR, []byte("")},
+		{cel.HostNetworkType, cel.CosEventPCR, []byte("false")},
+		{cel.ReadOnlyRootfsType, cel.CosEventPCR, []byte("true")},
+		{cel.AddedCapabilityType, cel.CosEventPCR, []byte("CAP_NET_ADMIN")},
+		{cel.MountType, cel.CosEventPCR, []byte("type=tmpfs,source=,destination=/tmp,readonly=false")},
+		{cel.DeviceType, cel.CosEventPCR, []byte("/dev/nvidia0")},
+		{cel.CDIDeviceType, cel.CosEventPCR, []byte("nvidia.com/gpu=1 /dev/nvidia1,/dev/nvidiactl")},
+		{cel.WorkloadKeyType, cel.CosEventPCR, []byte("sha256:8ab3f4d1e5f28d8c3bd3b6cf5a1b6e4b6fbc3b8c4c6e8f7f5b0c0d0a0b0c0d0e")},
+		{cel.WorkloadConfigType, cel.CosEventPCR, []byte("sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae")},
+		{cel.UserType, cel.CosEventPCR, []byte("1000:1000")},
+		{cel.UserNamespaceType, cel.CosEventPCR, []byte("true")},
+		{cel.SeccompProfileType, cel.CosEventPCR, []byte("runtime-default")},
+		{cel.AppArmorProfileType, cel.CosEventPCR, []byte("unconfined")},
+		{cel.LaunchPolicyDocumentType, cel.CosEventPCR, []byte("sha256:fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9")},
+		{cel.LaunchPolicySignerType, cel.CosEventPCR, []byte("sha256:18ac3e7343f016890c510e93f935261169d9e3f565436429830faf0934f4f8e4")},
+		{cel.BootTimeType, cel.CosEventPCR, []byte("2022-10-01T12:00:00Z")},
+		{cel.ClockSourceType, cel.CosEventPCR, []byte("metadata")},
+		{cel.ImagePlatformType, cel.CosEventPCR, []byte("linux/arm64")},
+		{cel.ImageManifestDigestType, cel.CosEventPCR, []byte("sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945")},
+		{cel.AttestBeforeRunType, cel.CosEventPCR, []byte("true")},
+		{cel.ApprovalURLType, cel.CosEventPCR, []byte("https://approver.example.com")},
 	}
 
 	expectedEnvVars := make(map[string]string)
@@ -574,12 +596,33 @@ func TestParsingCELEventLog(t *testing.T) {
 	expectedEnvVars["empty"] = ""
 
 	want := attestpb.ContainerState{
-		ImageReference: string(testCELEvents[0].eventPayload),
-		ImageDigest:    string(testCELEvents[1].eventPayload),
-		RestartPolicy:  attestpb.RestartPolicy_Always,
-		ImageId:        string(testCELEvents[3].eventPayload),
-		EnvVars:        expectedEnvVars,
-		Args:           []string{string(testCELEvents[8].eventPayload), string(testCELEvents[9].eventPayload), string(testCELEvents[10].eventPayload)},
+		ImageReference:       string(testCELEvents[0].eventPayload),
+		ImageDigest:          string(testCELEvents[1].eventPayload),
+		RestartPolicy:        attestpb.RestartPolicy_Always,
+		ImageId:              string(testCELEvents[3].eventPayload),
+		EnvVars:              expectedEnvVars,
+		ImageLayerDigests:    []string{string(testCELEvents[4].eventPayload), string(testCELEvents[5].eventPayload)},
+		Args:                 []string{string(testCELEvents[10].eventPayload), string(testCELEvents[11].eventPayload), string(testCELEvents[12].eventPayload)},
+		HostNetwork:          false,
+		ReadOnlyRootfs:       true,
+		AddedCapabilities:    []string{"CAP_NET_ADMIN"},
+		Mounts:               []*attestpb.Mount{{Type: "tmpfs", Destination: "/tmp"}},
+		Devices:              []string{"/dev/nvidia0"},
+		CdiDevices:           []*attestpb.CDIDevice{{Name: "nvidia.com/gpu=1", DeviceNodes: []string{"/dev/nvidia1", "/dev/nvidiactl"}}},
+		WorkloadKeyDigest:    "sha256:8ab3f4d1e5f28d8c3bd3b6cf5a1b6e4b6fbc3b8c4c6e8f7f5b0c0d0a0b0c0d0e",
+		WorkloadConfigDigest: "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae",
+		User:                 "1000:1000",
+		UserNamespace:        true,
+		SeccompProfile:       "runtime-default",
+		ApparmorProfile:      "unconfined",
+		LaunchPolicyDigest:   "sha256:fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9",
+		LaunchPolicySigner:   "sha256:18ac3e7343f016890c510e93f935261169d9e3f565436429830faf0934f4f8e4",
+		BootTime:             "2022-10-01T12:00:00Z",
+		ClockSource:          "metadata",
+		ImagePlatform:        "linux/arm64",
+		ImageManifestDigest:  "sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945",
+		AttestBeforeRun:      true,
+		ApprovalUrl:          "https://approver.example.com",
 	}
 	for _, testEvent := range testCELEvents {
 		cos := cel.CosTlv{EventType: testEvent.cosNestedEventType, EventContent: testEvent.eventPayload}
@@ -596,7 +639,7 @@ func TestParsingCELEventLog(t *testing.T) {
 		t.Fatal(err)
 	}
 	for _, bank := range banks {
-		if msState, err := parseCanonicalEventLog(buf.Bytes(), bank); err != nil {
+		if msState, err := parseCanonicalEventLog(buf.Bytes(), bank, false); err != nil {
 			t.Errorf("expecting no error from parseCanonicalEventLog(), but get %v", err)
 		} else {
 			if diff := cmp.Diff(msState.Cos.Container, &want, protocmp.Transform()); diff != "" {
@@ -632,7 +675,7 @@ func TestParsingCELEventLog(t *testing.T) {
 		t.Fatal(err)
 	}
 	for _, bank := range banks {
-		_, err := parseCanonicalEventLog(buf.Bytes(), bank)
+		_, err := parseCanonicalEventLog(buf.Bytes(), bank, false)
 		if err == nil {
 			t.Errorf("expected error when parsing event log with unknown content type")
 		}
@@ -662,6 +705,409 @@ func generateNonCosCelEvent(hashAlgoList []crypto.Hash) (cel.Record, error) {
 	return randRecord, nil
 }
 
+func TestParsingWorkloadOutputEvents(t *testing.T) {
+	test.SkipForRealTPM(t)
+	separator := cel.CosTlv{EventType: cel.LaunchSeparatorType}
+	hello := cel.CosTlv{EventType: cel.WorkloadOutputType, EventContent: []byte("size=5,digest=sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824")}
+	helloWorld := cel.CosTlv{EventType: cel.WorkloadOutputType, EventContent: []byte("size=11,digest=sha256:b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9")}
+
+	for _, tc := range []struct {
+		name    string
+		events  []cel.CosTlv
+		want    *attestpb.WorkloadOutput
+		wantErr bool
+	}{
+		{"NoCheckpoint", []cel.CosTlv{separator}, nil, false},
+		{"Checkpoints", []cel.CosTlv{separator, hello, helloWorld}, &attestpb.WorkloadOutput{Size: 11, Digest: decodeHex("b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9")}, false},
+		{"BeforeSeparator", []cel.CosTlv{hello, separator}, nil, true},
+		{"Shrinking", []cel.CosTlv{separator, helloWorld, hello}, nil, true},
+		{"Malformed", []cel.CosTlv{separator, {EventType: cel.WorkloadOutputType, EventContent: []byte("size=5")}}, nil, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tpm := test.GetTPM(t)
+			defer client.CheckedClose(t, tpm)
+
+			coscel := &cel.CEL{}
+			for _, event := range tc.events {
+				if err := coscel.AppendEvent(tpm, cel.CosEventPCR, []crypto.Hash{crypto.SHA256}, event); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var buf bytes.Buffer
+			if err := coscel.EncodeCEL(&buf); err != nil {
+				t.Fatal(err)
+			}
+			pcrs, err := client.ReadPCRs(tpm, tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{cel.CosEventPCR}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			msState, err := parseCanonicalEventLog(buf.Bytes(), pcrs, false)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("parseCanonicalEventLog() got error %v, want error %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(msState.GetCos().GetContainer().GetWorkloadOutput(), tc.want, protocmp.Transform()); diff != "" {
+				t.Errorf("unexpected workload output difference:\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestParsingHashAlgorithmsEvents(t *testing.T) {
+	test.SkipForRealTPM(t)
+	header := cel.CosTlv{EventType: cel.HashAlgorithmsType, EventContent: []byte("sha256,sha384")}
+	imageRef := cel.CosTlv{EventType: cel.ImageRefType, EventContent: []byte("docker.io/bazel/experimental/test:latest")}
+	both := []crypto.Hash{crypto.SHA256, crypto.SHA384}
+	sha256Only := []crypto.Hash{crypto.SHA256}
+
+	type record struct {
+		event     cel.CosTlv
+		hashAlgos []crypto.Hash
+	}
+	for _, tc := range []struct {
+		name    string
+		records []record
+		want    []pb.HashAlgo
+		wantErr bool
+	}{
+		{"NoHeader", []record{{imageRef, sha256Only}}, nil, false},
+		{"Header", []record{{header, both}, {imageRef, both}}, []pb.HashAlgo{pb.HashAlgo_SHA256, pb.HashAlgo_SHA384}, false},
+		{"MissingDigest", []record{{header, both}, {imageRef, sha256Only}}, nil, true},
+		{"HeaderMissingDigest", []record{{header, sha256Only}, {imageRef, both}}, nil, true},
+		{"NotFirst", []record{{imageRef, both}, {header, both}}, nil, true},
+		{"Malformed", []record{{cel.CosTlv{EventType: cel.HashAlgorithmsType, EventContent: []byte("sha256,md5")}, both}}, nil, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tpm := test.GetTPM(t)
+			defer client.CheckedClose(t, tpm)
+
+			coscel := &cel.CEL{}
+			for _, r := range tc.records {
+				if err := coscel.AppendEvent(tpm, cel.CosEventPCR, r.hashAlgos, r.event); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var buf bytes.Buffer
+			if err := coscel.EncodeCEL(&buf); err != nil {
+				t.Fatal(err)
+			}
+			pcrs, err := client.ReadPCRs(tpm, tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{cel.CosEventPCR}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			msState, err := parseCanonicalEventLog(buf.Bytes(), pcrs, false)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("parseCanonicalEventLog() got error %v, want error %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(msState.GetCos().GetCelHashAlgos(), tc.want); diff != "" {
+				t.Errorf("unexpected CEL hash algorithms difference:\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestParsingSchemaVersionEvents(t *testing.T) {
+	test.SkipForRealTPM(t)
+	schemaVersion := cel.CosTlv{EventType: cel.SchemaVersionType, EventContent: []byte("1")}
+	imageRef := cel.CosTlv{EventType: cel.ImageRefType, EventContent: []byte("docker.io/bazel/experimental/test:latest")}
+	unknown := cel.CosTlv{EventType: 250, EventContent: []byte("from a newer launcher")}
+	separator := cel.CosTlv{EventType: cel.LaunchSeparatorType}
+	for _, tc := range []struct {
+		name        string
+		events      []cel.CosTlv
+		wantVersion uint32
+		wantUnknown []*attestpb.CosEvent
+		wantErr     bool
+	}{
+		{"NoSchemaVersion", []cel.CosTlv{imageRef}, 0, nil, false},
+		{"SchemaVersion", []cel.CosTlv{schemaVersion, imageRef}, 1, nil, false},
+		{"UnknownEvent", []cel.CosTlv{schemaVersion, unknown, imageRef}, 1, []*attestpb.CosEvent{{EventType: 250, EventContent: []byte("from a newer launcher")}}, false},
+		{"UnknownEventWithoutSchemaVersion", []cel.CosTlv{unknown, imageRef}, 0, nil, true},
+		{"UnknownEventBeforeSchemaVersion", []cel.CosTlv{unknown, schemaVersion}, 0, nil, true},
+		{"UnknownEventAfterSeparator", []cel.CosTlv{schemaVersion, separator, unknown}, 0, nil, true},
+		{"TwoSchemaVersions", []cel.CosTlv{schemaVersion, schemaVersion}, 0, nil, true},
+		{"Malformed", []cel.CosTlv{{EventType: cel.SchemaVersionType, EventContent: []byte("v1")}}, 0, nil, true},
+		{"Zero", []cel.CosTlv{{EventType: cel.SchemaVersionType, EventContent: []byte("0")}}, 0, nil, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tpm := test.GetTPM(t)
+			defer client.CheckedClose(t, tpm)
+
+			coscel := &cel.CEL{}
+			for _, event := range tc.events {
+				if err := coscel.AppendEvent(tpm, cel.CosEventPCR, []crypto.Hash{crypto.SHA256}, event); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var buf bytes.Buffer
+			if err := coscel.EncodeCEL(&buf); err != nil {
+				t.Fatal(err)
+			}
+			pcrs, err := client.ReadPCRs(tpm, tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{cel.CosEventPCR}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			msState, err := parseCanonicalEventLog(buf.Bytes(), pcrs, false)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("parseCanonicalEventLog() got error %v, want error %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := msState.GetCos().GetSchemaVersion(); got != tc.wantVersion {
+				t.Errorf("got schema version %d, want %d", got, tc.wantVersion)
+			}
+			if diff := cmp.Diff(msState.GetCos().GetUnknownEvents(), tc.wantUnknown, protocmp.Transform()); diff != "" {
+				t.Errorf("unexpected unknown events difference:\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestParsingKernelModuleEvents(t *testing.T) {
+	test.SkipForRealTPM(t)
+	type measured struct {
+		event cel.CosTlv
+		pcr   int
+	}
+	imageRef := measured{cel.CosTlv{EventType: cel.ImageRefType, EventContent: []byte("docker.io/bazel/experimental/test:latest")}, cel.CosEventPCR}
+	separator := measured{cel.CosTlv{EventType: cel.LaunchSeparatorType}, cel.CosEventPCR}
+	fuse := measured{cel.CosTlv{EventType: cel.KernelModuleType, EventContent: []byte("sha256:0123 /lib/modules/6.1.0/kernel/fs/fuse/fuse.ko")}, cel.KernelModulePCR}
+	nbd := measured{cel.CosTlv{EventType: cel.KernelModuleType, EventContent: []byte("sha256:4567 /lib/modules/6.1.0/kernel/drivers/block/nbd.ko")}, cel.KernelModulePCR}
+	for _, tc := range []struct {
+		name        string
+		events      []measured
+		wantModules []*attestpb.KernelModule
+		wantErr     bool
+	}{
+		{"NoModules", []measured{imageRef, separator}, nil, false},
+		{"Modules", []measured{fuse, imageRef, separator, nbd}, []*attestpb.KernelModule{
+			{FileDigest: "sha256:0123", FileName: "/lib/modules/6.1.0/kernel/fs/fuse/fuse.ko"},
+			{FileDigest: "sha256:4567", FileName: "/lib/modules/6.1.0/kernel/drivers/block/nbd.ko"},
+		}, false},
+		{"ModuleInCosEventPCR", []measured{imageRef, {fuse.event, cel.CosEventPCR}}, nil, true},
+		{"CosEventInKernelModulePCR", []measured{{imageRef.event, cel.KernelModulePCR}}, nil, true},
+		{"Malformed", []measured{{cel.CosTlv{EventType: cel.KernelModuleType, EventContent: []byte("fuse.ko")}, cel.KernelModulePCR}}, nil, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tpm := test.GetTPM(t)
+			defer client.CheckedClose(t, tpm)
+
+			coscel := &cel.CEL{}
+			for _, m := range tc.events {
+				if err := coscel.AppendEvent(tpm, m.pcr, []crypto.Hash{crypto.SHA256}, m.event); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var buf bytes.Buffer
+			if err := coscel.EncodeCEL(&buf); err != nil {
+				t.Fatal(err)
+			}
+			pcrs, err := client.ReadPCRs(tpm, tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{cel.CosEventPCR, cel.KernelModulePCR}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			msState, err := parseCanonicalEventLog(buf.Bytes(), pcrs, false)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("parseCanonicalEventLog() got error %v, want error %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(msState.GetCos().GetKernelModules(), tc.wantModules, protocmp.Transform()); diff != "" {
+				t.Errorf("unexpected kernel modules difference:\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestParsingWorkloadEvents(t *testing.T) {
+	test.SkipForRealTPM(t)
+	name := cel.CosTlv{EventType: cel.WorkloadNameType, EventContent: []byte("my-service.service")}
+	binary := cel.CosTlv{EventType: cel.WorkloadBinaryType, EventContent: []byte("sha256:0123 /usr/local/bin/my-service")}
+	separator := cel.CosTlv{EventType: cel.LaunchSeparatorType}
+	for _, tc := range []struct {
+		name         string
+		events       []cel.CosTlv
+		wantWorkload *attestpb.WorkloadState
+		wantErr      bool
+	}{
+		{"NoWorkload", []cel.CosTlv{separator}, nil, false},
+		{"Workload", []cel.CosTlv{name, binary, separator}, &attestpb.WorkloadState{
+			Name:     "my-service.service",
+			Binaries: []*attestpb.WorkloadBinary{{FileDigest: "sha256:0123", Path: "/usr/local/bin/my-service"}},
+		}, false},
+		{"TwoNames", []cel.CosTlv{name, name}, nil, true},
+		{"AfterSeparator", []cel.CosTlv{name, separator, binary}, nil, true},
+		{"Malformed", []cel.CosTlv{{EventType: cel.WorkloadBinaryType, EventContent: []byte("my-service")}}, nil, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tpm := test.GetTPM(t)
+			defer client.CheckedClose(t, tpm)
+
+			coscel := &cel.CEL{}
+			for _, event := range tc.events {
+				if err := coscel.AppendEvent(tpm, cel.CosEventPCR, []crypto.Hash{crypto.SHA256}, event); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var buf bytes.Buffer
+			if err := coscel.EncodeCEL(&buf); err != nil {
+				t.Fatal(err)
+			}
+			pcrs, err := client.ReadPCRs(tpm, tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{cel.CosEventPCR}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			msState, err := parseCanonicalEventLog(buf.Bytes(), pcrs, false)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("parseCanonicalEventLog() got error %v, want error %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(msState.GetCos().GetWorkload(), tc.wantWorkload, protocmp.Transform()); diff != "" {
+				t.Errorf("unexpected workload difference:\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestParsingLauncherEvents(t *testing.T) {
+	test.SkipForRealTPM(t)
+	launcher := cel.CosTlv{EventType: cel.LauncherType, EventContent: []byte("1.2.3 sha256:0123")}
+	for _, tc := range []struct {
+		name        string
+		events      []cel.CosTlv
+		wantVersion *attestpb.SemanticVersion
+		wantDigest  string
+		wantErr     bool
+	}{
+		{"NoLauncher", []cel.CosTlv{{EventType: cel.LaunchSeparatorType}}, nil, "", false},
+		{"Launcher", []cel.CosTlv{launcher}, &attestpb.SemanticVersion{Major: 1, Minor: 2, Patch: 3}, "sha256:0123", false},
+		{"TwoLaunchers", []cel.CosTlv{launcher, launcher}, nil, "", true},
+		{"MalformedVersion", []cel.CosTlv{{EventType: cel.LauncherType, EventContent: []byte("1.2 sha256:0123")}}, nil, "", true},
+		{"MissingDigest", []cel.CosTlv{{EventType: cel.LauncherType, EventContent: []byte("1.2.3")}}, nil, "", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tpm := test.GetTPM(t)
+			defer client.CheckedClose(t, tpm)
+
+			coscel := &cel.CEL{}
+			for _, event := range tc.events {
+				if err := coscel.AppendEvent(tpm, cel.CosEventPCR, []crypto.Hash{crypto.SHA256}, event); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var buf bytes.Buffer
+			if err := coscel.EncodeCEL(&buf); err != nil {
+				t.Fatal(err)
+			}
+			pcrs, err := client.ReadPCRs(tpm, tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{cel.CosEventPCR}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			msState, err := parseCanonicalEventLog(buf.Bytes(), pcrs, false)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("parseCanonicalEventLog() got error %v, want error %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(msState.GetCos().GetLauncherVersion(), tc.wantVersion, protocmp.Transform()); diff != "" {
+				t.Errorf("unexpected launcher version difference:\n%v", diff)
+			}
+			if got := msState.GetCos().GetLauncherBinaryDigest(); got != tc.wantDigest {
+				t.Errorf("got launcher binary digest %q, want %q", got, tc.wantDigest)
+			}
+		})
+	}
+}
+
+func TestParsingKernelLogEvents(t *testing.T) {
+	test.SkipForRealTPM(t)
+	kernelLog := cel.CosTlv{EventType: cel.KernelLogType, EventContent: []byte("sha256:0123 4096")}
+	for _, tc := range []struct {
+		name    string
+		events  []cel.CosTlv
+		want    *attestpb.KernelLogState
+		wantErr bool
+	}{
+		{"NoKernelLog", []cel.CosTlv{{EventType: cel.LaunchSeparatorType}}, nil, false},
+		{"KernelLog", []cel.CosTlv{kernelLog}, &attestpb.KernelLogState{Digest: "sha256:0123", Taint: 4096}, false},
+		{"TwoKernelLogs", []cel.CosTlv{kernelLog, kernelLog}, nil, true},
+		{"Malformed", []cel.CosTlv{{EventType: cel.KernelLogType, EventContent: []byte("sha256:0123")}}, nil, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tpm := test.GetTPM(t)
+			defer client.CheckedClose(t, tpm)
+
+			coscel := &cel.CEL{}
+			for _, event := range tc.events {
+				if err := coscel.AppendEvent(tpm, cel.CosEventPCR, []crypto.Hash{crypto.SHA256}, event); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var buf bytes.Buffer
+			if err := coscel.EncodeCEL(&buf); err != nil {
+				t.Fatal(err)
+			}
+			pcrs, err := client.ReadPCRs(tpm, tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{cel.CosEventPCR}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			msState, err := parseCanonicalEventLog(buf.Bytes(), pcrs, false)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("parseCanonicalEventLog() got error %v, want error %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(msState.GetCos().GetKernelLog(), tc.want, protocmp.Transform()); diff != "" {
+				t.Errorf("unexpected kernel log difference:\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestEventTimestampAnomalies(t *testing.T) {
+	tpmClock := func(recNum uint64, value uint64) cel.Record {
+		return cel.Record{RecNum: recNum, Timestamp: cel.Timestamp{Source: cel.TPMClockTimestamps, Value: value}}
+	}
+	for _, tc := range []struct {
+		name    string
+		records []cel.Record
+		clock   uint64
+		wantErr bool
+	}{
+		{"Ordered", []cel.Record{tpmClock(0, 10), tpmClock(1, 10), {RecNum: 2}, tpmClock(3, 20)}, 20, false},
+		{"Decreasing", []cel.Record{tpmClock(0, 20), tpmClock(1, 10)}, 20, true},
+		{"MixedSources", []cel.Record{tpmClock(0, 10), {RecNum: 1, Timestamp: cel.Timestamp{Source: cel.MonotonicTimestamps, Value: 20}}}, 20, true},
+		{"AfterQuote", []cel.Record{tpmClock(0, 10), tpmClock(1, 30)}, 20, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cosState := &attestpb.AttestedCosState{}
+			var err error
+			for _, record := range tc.records {
+				if err = addEventTimestamp(cosState, record, cel.ImageRefType); err != nil {
+					break
+				}
+			}
+			if err == nil {
+				err = checkEventTimestamps(cosState, &attestpb.TPMClockInfo{Clock: tc.clock})
+			}
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("checking the timestamps: got error %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
 func TestParseLinuxKernelState(t *testing.T) {
 	logs := []struct {
 		eventLog
@@ -677,7 +1123,7 @@ func TestParseLinuxKernelState(t *testing.T) {
 			hashName := pb.HashAlgo_name[int32(bank.Hash)]
 			subtestName := fmt.Sprintf("%s-%s", log.name, hashName)
 			t.Run(subtestName, func(t *testing.T) {
-				msState, err := parsePCClientEventLog(log.RawLog, bank, GRUB)
+				msState, err := parsePCClientEventLog(log.RawLog, bank, GRUB, GCETrust)
 				if err != nil {
 					t.Errorf("failed to parse and replay log: %v", err)
 				}
@@ -711,7 +1157,7 @@ func TestParseGrubState(t *testing.T) {
 			hashName := pb.HashAlgo_name[int32(bank.Hash)]
 			subtestName := fmt.Sprintf("%s-%s", log.name, hashName)
 			t.Run(subtestName, func(t *testing.T) {
-				msState, err := parsePCClientEventLog(log.RawLog, bank, GRUB)
+				msState, err := parsePCClientEventLog(log.RawLog, bank, GRUB, GCETrust)
 				if err != nil {
 					t.Errorf("failed to parse and replay log: %v", err)
 				}
@@ -734,7 +1180,7 @@ func TestParseGrubStateFail(t *testing.T) {
 		hashName := pb.HashAlgo_name[int32(bank.Hash)]
 		subtestName := fmt.Sprintf("GlinuxNoSecureBootLaptop-%s", hashName)
 		t.Run(subtestName, func(t *testing.T) {
-			_, err := parsePCClientEventLog(eventlog.RawLog, bank, GRUB)
+			_, err := parsePCClientEventLog(eventlog.RawLog, bank, GRUB, GCETrust)
 			if err == nil {
 				t.Error("expected error when parsing GRUB state")
 			}
@@ -756,3 +1202,29 @@ func decodeHex(hexStr string) []byte {
 	}
 	return bytes
 }
+
+func TestParsePlatformStateVirtualTPM(t *testing.T) {
+	bank := UbuntuAmdSevGCE.Banks[0]
+	gceState, err := parsePCClientEventLog(UbuntuAmdSevGCE.RawLog, bank, UnsupportedLoader, GCETrust)
+	if err != nil {
+		t.Fatalf("failed to parse and replay log: %v", err)
+	}
+	if gceState.GetPlatform().GetGceVersion() == 0 {
+		t.Fatal("GCE log has no GCE firmware version")
+	}
+	if gceState.GetPlatform().GetTechnology() != attestpb.GCEConfidentialTechnology_AMD_SEV {
+		t.Fatalf("got technology %v, want AMD_SEV", gceState.GetPlatform().GetTechnology())
+	}
+
+	vtpmState, err := parsePCClientEventLog(UbuntuAmdSevGCE.RawLog, bank, UnsupportedLoader, VirtualTPMTrust)
+	if err != nil {
+		t.Fatalf("failed to parse and replay log: %v", err)
+	}
+	wantVersion := ConvertGCEFirmwareVersionToSCRTMVersion(gceState.GetPlatform().GetGceVersion())
+	if got := vtpmState.GetPlatform().GetScrtmVersionId(); !bytes.Equal(got, wantVersion) {
+		t.Errorf("got SCRTM version %q, want %q", got, wantVersion)
+	}
+	if got := vtpmState.GetPlatform().GetTechnology(); got != attestpb.GCEConfidentialTechnology_NONE {
+		t.Errorf("got technology %v outside of GCE, want NONE", got)
+	}
+}
@@ -1,6 +1,9 @@
 package server
 
-import "strings"
+import (
+	"errors"
+	"strings"
+)
 
 var fatalError = "fatal: invalid GroupedError"
 
@@ -24,6 +27,28 @@ func (gErr *GroupedError) Error() string {
 	return gErr.Prefix + sb.String()
 }
 
+// Is reports whether any of the grouped errors matches target, so errors.Is
+// can be used on a GroupedError.
+func (gErr *GroupedError) Is(target error) bool {
+	for _, err := range gErr.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first grouped error matching target, so errors.As can be used
+// on a GroupedError.
+func (gErr *GroupedError) As(target interface{}) bool {
+	for _, err := range gErr.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
 func createGroupedError(prefix string, errors []error) error {
 	if len(errors) == 0 {
 		return nil
@@ -0,0 +1,131 @@
+// Package grpcservice serves the attestation verifier of the httpservice
+// package over gRPC, with the AttestationVerifier service of the verifier
+// proto package. Register it on a grpc.Server with:
+//
+//	vpb.RegisterAttestationVerifierServer(grpcServer, grpcservice.New(service))
+package grpcservice
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	vpb "github.com/google/go-tpm-tools/proto/verifier"
+	"github.com/google/go-tpm-tools/server/httpservice"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxLogSize limits the total size of the event logs of a streamed
+// attestation.
+const maxLogSize = 64 << 20
+
+type service struct {
+	vpb.UnimplementedAttestationVerifierServer
+	service *httpservice.Service
+}
+
+// New returns an AttestationVerifierServer issuing the challenges and tokens
+// of the service. Challenges created over HTTP can be used over gRPC, and
+// the other way around.
+func New(s *httpservice.Service) vpb.AttestationVerifierServer {
+	return &service{service: s}
+}
+
+// CreateChallenge implements vpb.AttestationVerifierServer.
+func (s *service) CreateChallenge(_ context.Context, req *vpb.CreateChallengeRequest) (*vpb.Challenge, error) {
+	chal, err := s.service.CreateChallenge(req.GetParent())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &vpb.Challenge{
+		Name:              chal.Name,
+		Nonce:             chal.Nonce,
+		ExpireTimeSeconds: chal.Expires.Unix(),
+	}, nil
+}
+
+// VerifyAttestation implements vpb.AttestationVerifierServer.
+func (s *service) VerifyAttestation(_ context.Context, req *vpb.VerifyAttestationRequest) (*vpb.VerifyAttestationResponse, error) {
+	if req.GetAttestation() == nil {
+		return nil, status.Error(codes.InvalidArgument, "no attestation")
+	}
+	token, err := s.service.VerifyAttestation(req.GetChallenge(), req.GetAttestation(), tokenOptions(req))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &vpb.VerifyAttestationResponse{ClaimsToken: token}, nil
+}
+
+// VerifyAttestationStream implements vpb.AttestationVerifierServer.
+func (s *service) VerifyAttestationStream(stream vpb.AttestationVerifier_VerifyAttestationStreamServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	req := first.GetRequest()
+	if req == nil {
+		return status.Error(codes.InvalidArgument, "the first message of the stream must be the request")
+	}
+	if req.GetAttestation() == nil {
+		return status.Error(codes.InvalidArgument, "no attestation")
+	}
+	// Do not modify the received message.
+	attestation := proto.Clone(req.GetAttestation()).(*pb.Attestation)
+	size := len(attestation.EventLog) + len(attestation.CanonicalEventLog) + len(attestation.ImaLog)
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		var log *[]byte
+		var data []byte
+		switch c := chunk.GetChunk().(type) {
+		case *vpb.VerifyAttestationChunk_EventLog:
+			log, data = &attestation.EventLog, c.EventLog
+		case *vpb.VerifyAttestationChunk_CanonicalEventLog:
+			log, data = &attestation.CanonicalEventLog, c.CanonicalEventLog
+		case *vpb.VerifyAttestationChunk_ImaLog:
+			log, data = &attestation.ImaLog, c.ImaLog
+		default:
+			return status.Errorf(codes.InvalidArgument, "unexpected chunk %T", c)
+		}
+		if size += len(data); size > maxLogSize {
+			return status.Errorf(codes.ResourceExhausted, "event logs are larger than %d bytes", maxLogSize)
+		}
+		*log = append(*log, data...)
+	}
+
+	token, err := s.service.VerifyAttestation(req.GetChallenge(), attestation, tokenOptions(req))
+	if err != nil {
+		return toStatus(err)
+	}
+	return stream.SendAndClose(&vpb.VerifyAttestationResponse{ClaimsToken: token})
+}
+
+func tokenOptions(req *vpb.VerifyAttestationRequest) httpservice.TokenOptions {
+	return httpservice.TokenOptions{
+		Audience: req.GetTokenOptions().GetAudience(),
+		Nonces:   req.GetTokenOptions().GetNonce(),
+	}
+}
+
+// toStatus converts the errors of the httpservice.Service to gRPC statuses,
+// with the same codes as the REST API.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, httpservice.ErrInvalidRequest):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, httpservice.ErrInvalidChallenge):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, httpservice.ErrAttestationRejected):
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
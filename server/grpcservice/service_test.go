@@ -0,0 +1,137 @@
+package grpcservice
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net"
+	"strings"
+	"testing"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	vpb "github.com/google/go-tpm-tools/proto/verifier"
+	"github.com/google/go-tpm-tools/server/httpservice"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testParent = "projects/test-project/locations/us-central1"
+
+func newTestClient(t *testing.T) vpb.AttestationVerifierClient {
+	t.Helper()
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service, err := httpservice.New(httpservice.Config{Signer: signer, Issuer: "https://verifier.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	vpb.RegisterAttestationVerifierServer(srv, New(service))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return vpb.NewAttestationVerifierClient(conn)
+}
+
+func TestCreateChallenge(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	chal, err := client.CreateChallenge(ctx, &vpb.CreateChallengeRequest{Parent: testParent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := strings.TrimPrefix(chal.GetName(), testParent+"/challenges/")
+	if id == chal.GetName() || strings.Contains(id, "/") {
+		t.Errorf("got challenge name %q", chal.GetName())
+	}
+	if len(chal.GetNonce()) == 0 || chal.GetExpireTimeSeconds() == 0 {
+		t.Errorf("got challenge %v without a nonce or expiration", chal)
+	}
+
+	_, err = client.CreateChallenge(ctx, &vpb.CreateChallengeRequest{Parent: "projects/test-project"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("CreateChallenge() with an invalid parent = %v, want InvalidArgument", err)
+	}
+}
+
+func TestVerifyAttestationErrors(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	chal, err := client.CreateChallenge(ctx, &vpb.CreateChallengeRequest{Parent: testParent})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		req  *vpb.VerifyAttestationRequest
+		code codes.Code
+	}{
+		{"NoAttestation", &vpb.VerifyAttestationRequest{Challenge: chal.GetName()}, codes.InvalidArgument},
+		{"ShortNonce", &vpb.VerifyAttestationRequest{Challenge: chal.GetName(), Attestation: &pb.Attestation{}, TokenOptions: &vpb.TokenOptions{Nonce: []string{"short"}}}, codes.InvalidArgument},
+		{"UnknownChallenge", &vpb.VerifyAttestationRequest{Challenge: testParent + "/challenges/unknown", Attestation: &pb.Attestation{}}, codes.FailedPrecondition},
+		{"InvalidAttestation", &vpb.VerifyAttestationRequest{Challenge: chal.GetName(), Attestation: &pb.Attestation{}}, codes.PermissionDenied},
+		// The challenge was used by the previous request.
+		{"UsedChallenge", &vpb.VerifyAttestationRequest{Challenge: chal.GetName(), Attestation: &pb.Attestation{}}, codes.FailedPrecondition},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := client.VerifyAttestation(ctx, tc.req); status.Code(err) != tc.code {
+				t.Errorf("VerifyAttestation() = %v, want %v", err, tc.code)
+			}
+		})
+	}
+}
+
+func TestVerifyAttestationStreamErrors(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name   string
+		chunks []*vpb.VerifyAttestationChunk
+		code   codes.Code
+	}{
+		{"NoRequest", []*vpb.VerifyAttestationChunk{
+			{Chunk: &vpb.VerifyAttestationChunk_EventLog{EventLog: []byte("log")}},
+		}, codes.InvalidArgument},
+		{"TwoRequests", []*vpb.VerifyAttestationChunk{
+			{Chunk: &vpb.VerifyAttestationChunk_Request{Request: &vpb.VerifyAttestationRequest{Attestation: &pb.Attestation{}}}},
+			{Chunk: &vpb.VerifyAttestationChunk_Request{Request: &vpb.VerifyAttestationRequest{Attestation: &pb.Attestation{}}}},
+		}, codes.InvalidArgument},
+		{"UnknownChallenge", []*vpb.VerifyAttestationChunk{
+			{Chunk: &vpb.VerifyAttestationChunk_Request{Request: &vpb.VerifyAttestationRequest{Challenge: "unknown", Attestation: &pb.Attestation{}}}},
+			{Chunk: &vpb.VerifyAttestationChunk_CanonicalEventLog{CanonicalEventLog: []byte("log")}},
+		}, codes.FailedPrecondition},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			stream, err := client.VerifyAttestationStream(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, chunk := range tc.chunks {
+				// The server can fail before receiving all the chunks.
+				if err := stream.Send(chunk); err != nil {
+					break
+				}
+			}
+			if _, err := stream.CloseAndRecv(); status.Code(err) != tc.code {
+				t.Errorf("VerifyAttestationStream() = %v, want %v", err, tc.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package httpservice
+
+import (
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/server"
+)
+
+// Claims are the claims of the tokens issued by the Service.
+type Claims struct {
+	Issuer    string   `json:"iss"`
+	Subject   string   `json:"sub,omitempty"`
+	Audience  []string `json:"aud,omitempty"`
+	IssuedAt  int64    `json:"iat"`
+	NotBefore int64    `json:"nbf"`
+	ExpiresAt int64    `json:"exp"`
+	// Nonces are the TokenOptions Nonces of the request, as in EAT.
+	Nonces []string `json:"eat_nonce,omitempty"`
+	// SecureBoot is whether Secure Boot was enabled.
+	SecureBoot bool `json:"secboot"`
+	// HWModel is the GCE Confidential Computing technology.
+	HWModel string `json:"hwmodel"`
+	// Container is the workload measured by the launcher, if any.
+	Container *server.ContainerClaims `json:"container,omitempty"`
+}
+
+func (s *Service) claims(state *pb.MachineState, tokenOpts TokenOptions) Claims {
+	now := s.now()
+	claims := Claims{
+		Issuer:     s.config.Issuer,
+		IssuedAt:   now.Unix(),
+		NotBefore:  now.Unix(),
+		ExpiresAt:  now.Add(s.config.TokenLifetime).Unix(),
+		SecureBoot: state.GetSecureBoot().GetEnabled(),
+		HWModel:    state.GetPlatform().GetTechnology().String(),
+		Nonces:     tokenOpts.Nonces,
+		Container:  server.NewContainerClaims(state.GetCos().GetContainer()),
+	}
+	if tokenOpts.Audience != "" {
+		claims.Audience = []string{tokenOpts.Audience}
+	} else if s.config.Audience != "" {
+		claims.Audience = []string{s.config.Audience}
+	}
+	if info := state.GetPlatform().GetInstanceInfo(); info != nil {
+		claims.Subject = server.GCEInstanceURL(info)
+	}
+	return claims
+}
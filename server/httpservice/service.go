@@ -0,0 +1,467 @@
+// Package httpservice implements a self-hostable attestation verifier service.
+// It issues challenges and verifies attestations over HTTP/JSON, using the
+// same API as the Confidential Computing v1alpha1 REST API (so it works with
+// the launcher's REST verifier client), and issues OIDC-style tokens signed by
+// a pluggable crypto.Signer.
+package httpservice
+
+import (
+	"crypto"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm-tools/server"
+)
+
+const (
+	apiPrefix                = "/v1alpha1/"
+	verifyAttestationSuffix  = ":verifyAttestation"
+	openIDConfigurationPath  = "/.well-known/openid-configuration"
+	jwksPath                 = "/jwks"
+	defaultTokenLifetime     = time.Hour
+	defaultChallengeLifetime = 5 * time.Minute
+	nonceSize                = 32
+	// maxRequestSize limits the size of attestation requests.
+	maxRequestSize = 1 << 20
+)
+
+// Config configures a Service.
+type Config struct {
+	// Signer signs the issued tokens. It can be a local key (see LoadSigner),
+	// or any crypto.Signer backed by a KMS. RSA and ECDSA P-256 keys are
+	// supported.
+	Signer crypto.Signer
+	// KeyID is the optional "kid" of the signing key in the token header and
+	// the JWKS.
+	KeyID string
+	// Issuer is the URL the service is reachable at, used as the token "iss"
+	// and to serve the OpenID configuration.
+	Issuer string
+	// Audience is the token "aud".
+	Audience string
+	// TokenLifetime defaults to one hour.
+	TokenLifetime time.Duration
+	// ChallengeLifetime defaults to five minutes.
+	ChallengeLifetime time.Duration
+	// VerifyOpts are used to verify the attestations. The nonce is replaced
+	// with the nonce of the challenge.
+	VerifyOpts server.VerifyOpts
+	// Policy is optionally evaluated against the verified MachineState.
+	Policy *pb.Policy
+	// Revocation optionally checks the verified attestations against revoked
+	// artifacts, after the Policy.
+	Revocation server.RevocationChecker
+}
+
+// Service is an http.Handler serving the verifier API.
+type Service struct {
+	config Config
+	signer *tokenSigner
+	now    func() time.Time
+
+	mu         sync.Mutex
+	challenges map[string]*challenge
+}
+
+type challenge struct {
+	nonce   []byte
+	created time.Time
+	expires time.Time
+	used    bool
+}
+
+// New creates a Service from the config.
+func New(config Config) (*Service, error) {
+	if config.Signer == nil {
+		return nil, errors.New("a token signer must be configured")
+	}
+	if config.Issuer == "" {
+		return nil, errors.New("an issuer must be configured")
+	}
+	signer, err := newTokenSigner(config.Signer, config.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if config.TokenLifetime == 0 {
+		config.TokenLifetime = defaultTokenLifetime
+	}
+	if config.ChallengeLifetime == 0 {
+		config.ChallengeLifetime = defaultChallengeLifetime
+	}
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+	return &Service{
+		config:     config,
+		signer:     signer,
+		now:        time.Now,
+		challenges: make(map[string]*challenge),
+	}, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == openIDConfigurationPath && r.Method == http.MethodGet:
+		s.serveOpenIDConfiguration(w)
+	case r.URL.Path == jwksPath && r.Method == http.MethodGet:
+		writeJSON(w, map[string][]jwk{"keys": {s.signer.jwk()}})
+	case strings.HasPrefix(r.URL.Path, apiPrefix):
+		s.serveAPI(w, r, strings.TrimPrefix(r.URL.Path, apiPrefix))
+	default:
+		writeError(w, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("%s %s not found", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Service) serveAPI(w http.ResponseWriter, r *http.Request, name string) {
+	if r.Method == http.MethodPost && strings.HasSuffix(name, verifyAttestationSuffix) {
+		s.verifyAttestation(w, r, strings.TrimSuffix(name, verifyAttestationSuffix))
+		return
+	}
+	parts := strings.Split(name, "/")
+	if len(parts) < 4 || parts[0] != "projects" || parts[2] != "locations" {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("%s not found", name))
+		return
+	}
+	switch {
+	case len(parts) == 4 && r.Method == http.MethodGet:
+		// Any location is served.
+		writeJSON(w, location{Name: name, LocationID: parts[3]})
+	case len(parts) == 5 && parts[4] == "challenges" && r.Method == http.MethodPost:
+		s.createChallenge(w, strings.Join(parts[:4], "/"))
+	default:
+		writeError(w, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("%s %s not found", r.Method, name))
+	}
+}
+
+func (s *Service) createChallenge(w http.ResponseWriter, parent string) {
+	chal, err := s.CreateChallenge(parent)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL", err.Error())
+		return
+	}
+	writeJSON(w, chal.toREST())
+}
+
+// Errors of the Service, whose transports map them to their status codes.
+var (
+	// ErrInvalidRequest is returned for malformed requests.
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrInvalidChallenge is returned for unknown, used or expired challenges.
+	ErrInvalidChallenge = errors.New("invalid challenge")
+	// ErrAttestationRejected is returned for attestations which fail the
+	// verification or the policy.
+	ErrAttestationRejected = errors.New("attestation rejected")
+)
+
+// serviceError is an error of one of the kinds above, keeping its message.
+type serviceError struct {
+	kind error
+	msg  string
+}
+
+func (e *serviceError) Error() string        { return e.msg }
+func (e *serviceError) Is(target error) bool { return target == e.kind }
+
+// Challenge is a challenge issued by the Service.
+type Challenge struct {
+	// Name is formatted like
+	// projects/{project_id}/locations/{location}/challenges/{id}.
+	Name    string
+	Nonce   []byte
+	Created time.Time
+	Expires time.Time
+}
+
+// CreateChallenge issues a challenge in the parent location, formatted like
+// projects/{project_id}/locations/{location}.
+func (s *Service) CreateChallenge(parent string) (*Challenge, error) {
+	parts := strings.Split(parent, "/")
+	if len(parts) != 4 || parts[0] != "projects" || parts[2] != "locations" || parts[1] == "" || parts[3] == "" {
+		return nil, &serviceError{ErrInvalidRequest, fmt.Sprintf("invalid parent %q", parent)}
+	}
+	id := make([]byte, 16)
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	name := parent + "/challenges/" + hex.EncodeToString(id)
+	now := s.now()
+	chal := &challenge{nonce: nonce, created: now, expires: now.Add(s.config.ChallengeLifetime)}
+
+	s.mu.Lock()
+	for n, c := range s.challenges {
+		if now.After(c.expires) {
+			delete(s.challenges, n)
+		}
+	}
+	s.challenges[name] = chal
+	s.mu.Unlock()
+
+	return &Challenge{Name: name, Nonce: nonce, Created: chal.created, Expires: chal.expires}, nil
+}
+
+// TokenOptions customize the claims token of a VerifyAttestation request.
+type TokenOptions struct {
+	// Audience replaces the Audience of the Config as the token "aud".
+	Audience string
+	// Nonces are included as the token "eat_nonce". There can be at most
+	// MaxTokenNonces, of MinTokenNonceSize to MaxTokenNonceSize bytes.
+	Nonces []string
+}
+
+// The bounds of the TokenOptions Nonces, those of the attestation service.
+const (
+	MaxTokenNonces    = 6
+	MinTokenNonceSize = 8
+	MaxTokenNonceSize = 88
+)
+
+func (o TokenOptions) validate() error {
+	if len(o.Nonces) > MaxTokenNonces {
+		return fmt.Errorf("got %d nonces, at most %d are allowed", len(o.Nonces), MaxTokenNonces)
+	}
+	for _, nonce := range o.Nonces {
+		if len(nonce) < MinTokenNonceSize || len(nonce) > MaxTokenNonceSize {
+			return fmt.Errorf("nonce %q is not between %d and %d bytes", nonce, MinTokenNonceSize, MaxTokenNonceSize)
+		}
+	}
+	return nil
+}
+
+// VerifyAttestation verifies an attestation made for the named challenge,
+// which can only be used once, and returns the signed claims token.
+func (s *Service) VerifyAttestation(challengeName string, attestation *pb.Attestation, tokenOpts TokenOptions) (string, error) {
+	if err := tokenOpts.validate(); err != nil {
+		return "", &serviceError{ErrInvalidRequest, fmt.Sprintf("invalid token options: %v", err)}
+	}
+	nonce, err := s.useChallenge(challengeName)
+	if err != nil {
+		return "", &serviceError{ErrInvalidChallenge, err.Error()}
+	}
+
+	opts := s.config.VerifyOpts
+	opts.Nonce = nonce
+	state, err := server.VerifyAttestation(attestation, opts)
+	if err != nil {
+		return "", &serviceError{ErrAttestationRejected, fmt.Sprintf("failed to verify attestation: %v", err)}
+	}
+	if s.config.Policy != nil {
+		if err := server.EvaluatePolicy(state, s.config.Policy); err != nil {
+			return "", &serviceError{ErrAttestationRejected, fmt.Sprintf("attestation does not comply with the policy: %v", err)}
+		}
+	}
+	if s.config.Revocation != nil {
+		if err := s.config.Revocation.CheckRevocation(attestation, state); err != nil {
+			return "", &serviceError{ErrAttestationRejected, fmt.Sprintf("attestation failed the revocation check: %v", err)}
+		}
+	}
+	return s.signer.sign(s.claims(state, tokenOpts))
+}
+
+// useChallenge returns the nonce of the named challenge, marking it used.
+func (s *Service) useChallenge(name string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	chal, ok := s.challenges[name]
+	if !ok {
+		return nil, fmt.Errorf("challenge %s not found", name)
+	}
+	if chal.used {
+		return nil, fmt.Errorf("challenge %s already used", name)
+	}
+	if s.now().After(chal.expires) {
+		return nil, fmt.Errorf("challenge %s expired", name)
+	}
+	chal.used = true
+	return chal.nonce, nil
+}
+
+func (s *Service) verifyAttestation(w http.ResponseWriter, r *http.Request, name string) {
+	var req verifyAttestationRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", fmt.Sprintf("invalid request: %v", err))
+		return
+	}
+	attestation, err := req.TpmAttestation.toProto()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", fmt.Sprintf("invalid attestation: %v", err))
+		return
+	}
+	token, err := s.VerifyAttestation(name, attestation, req.TokenOptions.toService())
+	switch {
+	case errors.Is(err, ErrInvalidRequest):
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+		return
+	case errors.Is(err, ErrInvalidChallenge):
+		writeError(w, http.StatusBadRequest, "FAILED_PRECONDITION", err.Error())
+		return
+	case errors.Is(err, ErrAttestationRejected):
+		writeError(w, http.StatusForbidden, "PERMISSION_DENIED", err.Error())
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "INTERNAL", err.Error())
+		return
+	}
+	writeJSON(w, verifyAttestationResponse{ClaimsToken: encoding.EncodeToString([]byte(token))})
+}
+
+func (s *Service) serveOpenIDConfiguration(w http.ResponseWriter) {
+	writeJSON(w, openIDConfiguration{
+		Issuer:                 s.config.Issuer,
+		JWKSURI:                s.config.Issuer + jwksPath,
+		ResponseTypes:          []string{"id_token"},
+		SubjectTypes:           []string{"public"},
+		SigningAlgs:            []string{s.signer.alg},
+		ClaimsSupported:        []string{"iss", "aud", "sub", "iat", "nbf", "exp", "eat_nonce", "secboot", "hwmodel", "container"},
+		ScopesSupported:        []string{"openid"},
+		TokenEndpointAuthMeths: []string{"none"},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a Google API style JSON error.
+func writeError(w http.ResponseWriter, code int, status string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]apiError{"error": {Code: code, Message: message, Status: status}})
+}
+
+// encoding is the encoding of bytes fields in the REST API.
+var encoding = base64.StdEncoding
+
+type apiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
+}
+
+type location struct {
+	Name       string `json:"name"`
+	LocationID string `json:"locationId"`
+}
+
+type restChallenge struct {
+	Name       string `json:"name"`
+	CreateTime string `json:"createTime"`
+	ExpireTime string `json:"expireTime"`
+	Used       bool   `json:"used"`
+	Nonce      string `json:"nonce"`
+}
+
+func (c *Challenge) toREST() restChallenge {
+	return restChallenge{
+		Name:       c.Name,
+		CreateTime: c.Created.UTC().Format(time.RFC3339Nano),
+		ExpireTime: c.Expires.UTC().Format(time.RFC3339Nano),
+		Nonce:      encoding.EncodeToString(c.Nonce),
+	}
+}
+
+type verifyAttestationRequest struct {
+	GcpCredentials struct {
+		IDTokens []string `json:"idTokens"`
+	} `json:"gcpCredentials"`
+	TpmAttestation tpmAttestation `json:"tpmAttestation"`
+	TokenOptions   tokenOptions   `json:"tokenOptions"`
+}
+
+type tokenOptions struct {
+	Audience string   `json:"audience"`
+	Nonce    []string `json:"nonce"`
+}
+
+func (o tokenOptions) toService() TokenOptions {
+	return TokenOptions{Audience: o.Audience, Nonces: o.Nonce}
+}
+
+type tpmAttestation struct {
+	Quotes            []quote  `json:"quotes"`
+	TcgEventLog       string   `json:"tcgEventLog"`
+	CanonicalEventLog string   `json:"canonicalEventLog"`
+	AkCert            string   `json:"akCert"`
+	CertChain         []string `json:"certChain"`
+}
+
+type quote struct {
+	HashAlgo     int64             `json:"hashAlgo"`
+	PcrValues    map[string]string `json:"pcrValues"`
+	RawQuote     string            `json:"rawQuote"`
+	RawSignature string            `json:"rawSignature"`
+}
+
+type verifyAttestationResponse struct {
+	ClaimsToken string `json:"claimsToken"`
+}
+
+type openIDConfiguration struct {
+	Issuer                 string   `json:"issuer"`
+	JWKSURI                string   `json:"jwks_uri"`
+	ResponseTypes          []string `json:"response_types_supported"`
+	SubjectTypes           []string `json:"subject_types_supported"`
+	SigningAlgs            []string `json:"id_token_signing_alg_values_supported"`
+	ClaimsSupported        []string `json:"claims_supported"`
+	ScopesSupported        []string `json:"scopes_supported"`
+	TokenEndpointAuthMeths []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+// toProto converts the REST attestation to the form used by the server
+// package.
+func (a tpmAttestation) toProto() (*pb.Attestation, error) {
+	var err error
+	attestation := &pb.Attestation{}
+	if attestation.EventLog, err = encoding.DecodeString(a.TcgEventLog); err != nil {
+		return nil, fmt.Errorf("tcgEventLog: %w", err)
+	}
+	if attestation.CanonicalEventLog, err = encoding.DecodeString(a.CanonicalEventLog); err != nil {
+		return nil, fmt.Errorf("canonicalEventLog: %w", err)
+	}
+	if attestation.AkCert, err = encoding.DecodeString(a.AkCert); err != nil {
+		return nil, fmt.Errorf("akCert: %w", err)
+	}
+	for _, cert := range a.CertChain {
+		der, err := encoding.DecodeString(cert)
+		if err != nil {
+			return nil, fmt.Errorf("certChain: %w", err)
+		}
+		attestation.IntermediateCerts = append(attestation.IntermediateCerts, der)
+	}
+	for _, q := range a.Quotes {
+		pcrs := &tpmpb.PCRs{Hash: tpmpb.HashAlgo(q.HashAlgo), Pcrs: make(map[uint32][]byte)}
+		for idx, val := range q.PcrValues {
+			i, err := strconv.ParseUint(idx, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid PCR index %q: %w", idx, err)
+			}
+			if pcrs.Pcrs[uint32(i)], err = encoding.DecodeString(val); err != nil {
+				return nil, fmt.Errorf("PCR %d: %w", i, err)
+			}
+		}
+		rawQuote, err := encoding.DecodeString(q.RawQuote)
+		if err != nil {
+			return nil, fmt.Errorf("rawQuote: %w", err)
+		}
+		rawSig, err := encoding.DecodeString(q.RawSignature)
+		if err != nil {
+			return nil, fmt.Errorf("rawSignature: %w", err)
+		}
+		attestation.Quotes = append(attestation.Quotes, &tpmpb.Quote{Quote: rawQuote, RawSig: rawSig, Pcrs: pcrs})
+	}
+	return attestation, nil
+}
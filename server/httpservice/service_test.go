@@ -0,0 +1,307 @@
+package httpservice
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/server"
+)
+
+const testParent = "projects/test-project/locations/us-central1"
+
+// Returns a self-signed certificate for the AK.
+func getAKCert(t *testing.T, ak *client.Key) []byte {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, ak.PublicKey(), caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+// Converts the attestation like the launcher's REST client.
+func toREST(attestation *pb.Attestation) verifyAttestationRequest {
+	req := verifyAttestationRequest{TpmAttestation: tpmAttestation{
+		TcgEventLog:       encoding.EncodeToString(attestation.GetEventLog()),
+		CanonicalEventLog: encoding.EncodeToString(attestation.GetCanonicalEventLog()),
+		AkCert:            encoding.EncodeToString(attestation.GetAkCert()),
+	}}
+	for _, q := range attestation.GetQuotes() {
+		pcrValues := map[string]string{}
+		for idx, val := range q.GetPcrs().GetPcrs() {
+			pcrValues[strconv.FormatUint(uint64(idx), 10)] = encoding.EncodeToString(val)
+		}
+		req.TpmAttestation.Quotes = append(req.TpmAttestation.Quotes, quote{
+			HashAlgo:     int64(q.GetPcrs().GetHash()),
+			PcrValues:    pcrValues,
+			RawQuote:     encoding.EncodeToString(q.GetQuote()),
+			RawSignature: encoding.EncodeToString(q.GetRawSig()),
+		})
+	}
+	return req
+}
+
+func post(t *testing.T, url string, body interface{}, resp interface{}) int {
+	t.Helper()
+	b, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := http.Post(url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+	if r.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(r.Body).Decode(resp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return r.StatusCode
+}
+
+// verifyES256 checks the token signature and returns its claims.
+func verifyES256(t *testing.T, token string, pub *ecdsa.PublicKey) Claims {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("malformed token %q", token)
+	}
+	sig, err := jwtEncoding.DecodeString(parts[2])
+	if err != nil || len(sig) != 64 {
+		t.Fatalf("malformed signature: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !ecdsa.Verify(pub, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])) {
+		t.Fatal("invalid token signature")
+	}
+	payload, err := jwtEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatal(err)
+	}
+	return claims
+}
+
+func newTestService(t *testing.T, trustedAK crypto.PublicKey, policy *pb.Policy) (*httptest.Server, *ecdsa.PrivateKey) {
+	t.Helper()
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service, err := New(Config{
+		Signer:     signer,
+		KeyID:      "test-key",
+		Issuer:     "https://verifier.example.com/",
+		Audience:   "test-audience",
+		VerifyOpts: server.VerifyOpts{TrustedAKs: []crypto.PublicKey{trustedAK}},
+		Policy:     policy,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return httptest.NewServer(service), signer
+}
+
+func TestService(t *testing.T) {
+	test.SkipForRealTPM(t)
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ak, err := client.AttestationKeyECC(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+
+	ts, signer := newTestService(t, ak.PublicKey(), nil)
+	defer ts.Close()
+
+	var chal restChallenge
+	if code := post(t, ts.URL+apiPrefix+testParent+"/challenges", struct{}{}, &chal); code != http.StatusOK {
+		t.Fatalf("CreateChallenge returned %d", code)
+	}
+	if !strings.HasPrefix(chal.Name, testParent+"/challenges/") {
+		t.Errorf("got challenge name %q", chal.Name)
+	}
+	nonce, err := encoding.DecodeString(chal.Nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatal(err)
+	}
+	attestation.AkCert = getAKCert(t, ak)
+
+	verifyURL := ts.URL + apiPrefix + chal.Name + verifyAttestationSuffix
+	var resp verifyAttestationResponse
+	if code := post(t, verifyURL, toREST(attestation), &resp); code != http.StatusOK {
+		t.Fatalf("VerifyAttestation returned %d", code)
+	}
+	token, err := encoding.DecodeString(resp.ClaimsToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := verifyES256(t, string(token), &signer.PublicKey)
+	if claims.Issuer != "https://verifier.example.com" || len(claims.Audience) != 1 || claims.Audience[0] != "test-audience" {
+		t.Errorf("got iss %q and aud %v", claims.Issuer, claims.Audience)
+	}
+	if claims.ExpiresAt-claims.IssuedAt != int64(defaultTokenLifetime.Seconds()) {
+		t.Errorf("got token lifetime %ds, want %v", claims.ExpiresAt-claims.IssuedAt, defaultTokenLifetime)
+	}
+
+	// The challenge can only be used once.
+	if code := post(t, verifyURL, toREST(attestation), &resp); code == http.StatusOK {
+		t.Error("VerifyAttestation succeeded when reusing a challenge")
+	}
+}
+
+func TestServicePolicy(t *testing.T) {
+	test.SkipForRealTPM(t)
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ak, err := client.AttestationKeyECC(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+
+	policy := &pb.Policy{Platform: &pb.PlatformPolicy{MinimumGceFirmwareVersion: 1000}}
+	ts, _ := newTestService(t, ak.PublicKey(), policy)
+	defer ts.Close()
+
+	var chal restChallenge
+	if code := post(t, ts.URL+apiPrefix+testParent+"/challenges", struct{}{}, &chal); code != http.StatusOK {
+		t.Fatalf("CreateChallenge returned %d", code)
+	}
+	nonce, err := encoding.DecodeString(chal.Nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatal(err)
+	}
+	attestation.AkCert = getAKCert(t, ak)
+
+	var resp verifyAttestationResponse
+	if code := post(t, ts.URL+apiPrefix+chal.Name+verifyAttestationSuffix, toREST(attestation), &resp); code != http.StatusForbidden {
+		t.Errorf("VerifyAttestation returned %d, want %d", code, http.StatusForbidden)
+	}
+}
+
+func TestServiceDiscovery(t *testing.T) {
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts, _ := newTestService(t, signer.Public(), nil)
+	defer ts.Close()
+
+	var config openIDConfiguration
+	r, err := http.Get(ts.URL + openIDConfigurationPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		t.Fatal(err)
+	}
+	if config.JWKSURI != "https://verifier.example.com/jwks" {
+		t.Errorf("got jwks_uri %q", config.JWKSURI)
+	}
+
+	var jwks struct{ Keys []jwk }
+	r, err = http.Get(ts.URL + jwksPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&jwks); err != nil {
+		t.Fatal(err)
+	}
+	if len(jwks.Keys) != 1 || jwks.Keys[0].Kid != "test-key" || jwks.Keys[0].Kty != "EC" {
+		t.Errorf("got JWKS %+v", jwks)
+	}
+
+	r, err = http.Get(ts.URL + apiPrefix + testParent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		t.Errorf("GetLocation returned %d", r.StatusCode)
+	}
+}
+
+func TestLoadSigner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8DER, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	for _, block := range []*pem.Block{
+		{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)},
+		{Type: "EC PRIVATE KEY", Bytes: ecDER},
+		{Type: "PRIVATE KEY", Bytes: pkcs8DER},
+	} {
+		keyPath := path.Join(dir, "key.pem")
+		if err := os.WriteFile(keyPath, pem.EncodeToMemory(block), 0600); err != nil {
+			t.Fatal(err)
+		}
+		signer, err := LoadSigner(keyPath)
+		if err != nil {
+			t.Errorf("LoadSigner(%s) failed: %v", block.Type, err)
+			continue
+		}
+		if _, err := newTokenSigner(signer, ""); err != nil {
+			t.Errorf("newTokenSigner(%s) failed: %v", block.Type, err)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package httpservice
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+var jwtEncoding = base64.RawURLEncoding
+
+// tokenSigner signs JWTs with a crypto.Signer, so the key can be held in a
+// KMS. Only RSA (RS256) and ECDSA P-256 (ES256) keys are supported.
+type tokenSigner struct {
+	signer crypto.Signer
+	keyID  string
+	alg    string
+}
+
+func newTokenSigner(signer crypto.Signer, keyID string) (*tokenSigner, error) {
+	switch pub := signer.Public().(type) {
+	case *rsa.PublicKey:
+		return &tokenSigner{signer, keyID, "RS256"}, nil
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported ECDSA curve %s", pub.Curve.Params().Name)
+		}
+		return &tokenSigner{signer, keyID, "ES256"}, nil
+	default:
+		return nil, fmt.Errorf("unsupported signing key type %T", pub)
+	}
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// sign returns the compact serialization of a JWT with the claims.
+func (s *tokenSigner) sign(claims interface{}) (string, error) {
+	header, err := json.Marshal(jwtHeader{Alg: s.alg, Typ: "JWT", Kid: s.keyID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := jwtEncoding.EncodeToString(header) + "." + jwtEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := s.signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	if s.alg == "ES256" {
+		// JWS uses the fixed size R || S encoding instead of ASN.1.
+		if sig, err = ecdsaRawSignature(sig); err != nil {
+			return "", err
+		}
+	}
+	return signingInput + "." + jwtEncoding.EncodeToString(sig), nil
+}
+
+func ecdsaRawSignature(der []byte) ([]byte, error) {
+	var sig struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, fmt.Errorf("failed to decode ECDSA signature: %w", err)
+	}
+	raw := make([]byte, 64)
+	sig.R.FillBytes(raw[:32])
+	sig.S.FillBytes(raw[32:])
+	return raw, nil
+}
+
+// jwk is a JSON Web Key, see RFC 7517.
+type jwk struct {
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Kid string `json:"kid,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+func (s *tokenSigner) jwk() jwk {
+	key := jwk{Alg: s.alg, Use: "sig", Kid: s.keyID}
+	switch pub := s.signer.Public().(type) {
+	case *rsa.PublicKey:
+		key.Kty = "RSA"
+		key.N = jwtEncoding.EncodeToString(pub.N.Bytes())
+		key.E = jwtEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		key.Kty = "EC"
+		key.Crv = "P-256"
+		key.X = jwtEncoding.EncodeToString(pub.X.FillBytes(make([]byte, 32)))
+		key.Y = jwtEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, 32)))
+	}
+	return key
+}
+
+// LoadSigner reads a PEM encoded PKCS #8, PKCS #1 (RSA) or SEC 1 (EC) private
+// key from a file, for signing tokens with a local key.
+func LoadSigner(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in %s", path)
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("private key cannot sign")
+		}
+		return signer, nil
+	}
+	return nil, fmt.Errorf("unsupported PEM block type %q in %s", block.Type, path)
+}
@@ -0,0 +1,254 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-tpm-tools/client"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/tpm2"
+)
+
+// imaPCR is the PCR IMA extends its measurements into.
+const imaPCR = 10
+
+// parseIMALog parses an IMA ascii_runtime_measurements log, and replays it
+// against pcrs, which must come from a verified quote: PCR 10, and PCR 11 for
+// the kernel module loads measured by client.KernelModuleIMAPolicy. As the log
+// is read after the quote, it can have more measurements than the quote
+// covers: only the measurements up to the quoted PCR values are returned. The
+// template hash of every ima-ng measurement is recomputed from its file digest
+// and name, as the replay only covers the template hash; the measurements
+// which cannot be recomputed are returned as unverified.
+func parseIMALog(rawLog []byte, pcrs *tpmpb.PCRs) (*pb.ImaState, error) {
+	hash, err := tpm2.Algorithm(pcrs.GetHash()).Hash()
+	if err != nil {
+		return nil, err
+	}
+	measurements, err := parseIMAMeasurements(rawLog)
+	if err != nil {
+		return nil, err
+	}
+
+	covered := make(map[*pb.ImaMeasurement]bool)
+	for _, pcr := range []uint32{imaPCR, client.KernelModuleIMAPCR} {
+		var pcrMeasurements []*pb.ImaMeasurement
+		for _, measurement := range measurements {
+			if measurement.GetPcr() == pcr {
+				pcrMeasurements = append(pcrMeasurements, measurement)
+			}
+		}
+		// The kernel module loads are only measured with
+		// client.KernelModuleIMAPolicy, so their PCR is optional.
+		if pcr != imaPCR && len(pcrMeasurements) == 0 {
+			continue
+		}
+		n, err := replayIMAPCR(pcrMeasurements, pcrs, pcr, hash)
+		if err != nil {
+			return nil, err
+		}
+		for _, measurement := range pcrMeasurements[:n] {
+			covered[measurement] = true
+		}
+	}
+
+	var quoted []*pb.ImaMeasurement
+	for _, measurement := range measurements {
+		if covered[measurement] {
+			quoted = append(quoted, measurement)
+		}
+	}
+	return verifyIMATemplateHashes(quoted)
+}
+
+// replayIMAPCR replays the measurements of a PCR against its quoted value, and
+// returns the number of measurements covered by it.
+func replayIMAPCR(measurements []*pb.ImaMeasurement, pcrs *tpmpb.PCRs, pcr uint32, hash crypto.Hash) (int, error) {
+	want, ok := pcrs.GetPcrs()[pcr]
+	if !ok {
+		return 0, fmt.Errorf("quote does not cover PCR %d", pcr)
+	}
+	// Kernels extend the SHA-1 template hash of the log into the SHA-1 bank.
+	// Older kernels extend it zero padded into the other banks, newer ones
+	// the template hash computed with the hash of the bank.
+	digestFuncs := []func(*pb.ImaMeasurement, crypto.Hash) ([]byte, error){paddedIMATemplateHash}
+	if hash != crypto.SHA1 {
+		digestFuncs = append(digestFuncs, imaNGTemplateHash)
+	}
+	var lastErr error
+	for _, digestFunc := range digestFuncs {
+		n, err := replayIMA(measurements, pcr, hash, want, digestFunc)
+		if err == nil {
+			return n, nil
+		}
+		lastErr = err
+	}
+	return 0, lastErr
+}
+
+// checkKernelModules checks that every kernel module of the Canonical Event
+// Log, copied by the launcher from the IMA log, is a measurement of
+// client.KernelModuleIMAPCR covered by the quote.
+func checkKernelModules(modules []*pb.KernelModule, state *pb.ImaState) error {
+	loads := make(map[string]int)
+	for _, measurements := range [][]*pb.ImaMeasurement{state.GetMeasurements(), state.GetUnverifiedMeasurements()} {
+		for _, measurement := range measurements {
+			if measurement.GetPcr() == client.KernelModuleIMAPCR {
+				loads[measurement.GetFileDigest()]++
+			}
+		}
+	}
+	for i, module := range modules {
+		if loads[module.GetFileDigest()] == 0 {
+			return fmt.Errorf("kernel module %d (%s) is not measured into the quoted PCR %d", i, module.GetFileName(), client.KernelModuleIMAPCR)
+		}
+		loads[module.GetFileDigest()]--
+	}
+	return nil
+}
+
+// verifyIMATemplateHashes checks that the template hash of every ima-ng
+// measurement is the SHA-1 of its template data, and splits the measurements
+// whose template data cannot be recomputed from the verified ones.
+func verifyIMATemplateHashes(measurements []*pb.ImaMeasurement) (*pb.ImaState, error) {
+	state := &pb.ImaState{}
+	for i, measurement := range measurements {
+		if isIMAViolation(measurement) || measurement.GetTemplateName() != "ima-ng" {
+			state.UnverifiedMeasurements = append(state.UnverifiedMeasurements, measurement)
+			continue
+		}
+		templateHash, err := imaNGTemplateHash(measurement, crypto.SHA1)
+		if err != nil {
+			return nil, fmt.Errorf("IMA measurement %d: %v", i, err)
+		}
+		if !bytes.Equal(templateHash, measurement.GetTemplateHash()) {
+			return nil, fmt.Errorf("IMA measurement %d: template hash does not match the file digest and name", i)
+		}
+		state.Measurements = append(state.Measurements, measurement)
+	}
+	return state, nil
+}
+
+// parseIMAMeasurements parses the lines of an ascii_runtime_measurements log:
+// "PCR template-hash template-name file-digest file-name".
+func parseIMAMeasurements(rawLog []byte) ([]*pb.ImaMeasurement, error) {
+	var measurements []*pb.ImaMeasurement
+	scanner := bufio.NewScanner(bytes.NewReader(rawLog))
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if scanner.Text() == "" {
+			continue
+		}
+		fields := strings.SplitN(scanner.Text(), " ", 5)
+		if len(fields) < 4 {
+			return nil, fmt.Errorf("IMA log line %d: got %d fields, want at least 4", line, len(fields))
+		}
+		pcr, err := strconv.ParseUint(fields[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("IMA log line %d: invalid PCR: %v", line, err)
+		}
+		if pcr != imaPCR && pcr != client.KernelModuleIMAPCR {
+			return nil, fmt.Errorf("IMA log line %d: unsupported PCR %d", line, pcr)
+		}
+		templateHash, err := hex.DecodeString(fields[1])
+		if err != nil || len(templateHash) != crypto.SHA1.Size() {
+			return nil, fmt.Errorf("IMA log line %d: invalid template hash %q", line, fields[1])
+		}
+		measurement := &pb.ImaMeasurement{
+			Pcr:          uint32(pcr),
+			TemplateHash: templateHash,
+			TemplateName: fields[2],
+			FileDigest:   fields[3],
+		}
+		if len(fields) == 5 {
+			measurement.FileName = fields[4]
+		}
+		measurements = append(measurements, measurement)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read IMA log: %v", err)
+	}
+	return measurements, nil
+}
+
+// replayIMA extends the digests of the measurements into a PCR, until it has
+// the wanted value. It returns the number of measurements covered by the PCR.
+func replayIMA(measurements []*pb.ImaMeasurement, pcrIndex uint32, hash crypto.Hash, want []byte, digestFunc func(*pb.ImaMeasurement, crypto.Hash) ([]byte, error)) (int, error) {
+	pcr := make([]byte, hash.Size())
+	if bytes.Equal(pcr, want) {
+		return 0, nil
+	}
+	for i, measurement := range measurements {
+		var digest []byte
+		if isIMAViolation(measurement) {
+			// Violations are extended as all ones.
+			digest = bytes.Repeat([]byte{0xff}, hash.Size())
+		} else {
+			var err error
+			if digest, err = digestFunc(measurement, hash); err != nil {
+				return 0, err
+			}
+		}
+		h := hash.New()
+		h.Write(pcr)
+		h.Write(digest)
+		pcr = h.Sum(nil)
+		if bytes.Equal(pcr, want) {
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("IMA log does not replay to the quoted PCR %d", pcrIndex)
+}
+
+// isIMAViolation checks if the measurement records a violation, e.g. a file
+// opened for writing while being measured, whose template hash is all zeros.
+func isIMAViolation(measurement *pb.ImaMeasurement) bool {
+	for _, b := range measurement.GetTemplateHash() {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// paddedIMATemplateHash returns the SHA-1 template hash of the log, zero
+// padded to the size of hash.
+func paddedIMATemplateHash(measurement *pb.ImaMeasurement, hash crypto.Hash) ([]byte, error) {
+	digest := make([]byte, hash.Size())
+	copy(digest, measurement.GetTemplateHash())
+	return digest, nil
+}
+
+// imaNGTemplateHash computes the template hash of an ima-ng measurement with
+// hash. The template data is the length prefixed "d-ng" field, the algorithm,
+// a colon, a NUL and the file digest, and "n-ng" field, the NUL terminated
+// file name.
+func imaNGTemplateHash(measurement *pb.ImaMeasurement, hash crypto.Hash) ([]byte, error) {
+	if measurement.GetTemplateName() != "ima-ng" {
+		return nil, fmt.Errorf("cannot compute the template hash of IMA template %q", measurement.GetTemplateName())
+	}
+	alg, hexDigest, ok := strings.Cut(measurement.GetFileDigest(), ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid ima-ng file digest %q", measurement.GetFileDigest())
+	}
+	fileDigest, err := hex.DecodeString(hexDigest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ima-ng file digest %q: %v", measurement.GetFileDigest(), err)
+	}
+	dng := append([]byte(alg+":\x00"), fileDigest...)
+	nng := []byte(measurement.GetFileName() + "\x00")
+
+	h := hash.New()
+	for _, field := range [][]byte{dng, nng} {
+		binary.Write(h, binary.LittleEndian, uint32(len(field)))
+		h.Write(field)
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,290 @@
+package server
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-tpm-tools/client"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/tpm2"
+)
+
+var imaTestFiles = []struct {
+	name   string
+	digest string
+}{
+	{"boot_aggregate", "sha256:" + strings.Repeat("00", 32)},
+	{"/usr/bin/containerd", "sha256:" + strings.Repeat("ab", 32)},
+	{"/usr/bin/runc", "sha256:" + strings.Repeat("cd", 32)},
+}
+
+// imaTestLog returns an ima-ng log of imaTestFiles, with the SHA-1 template
+// hashes the kernel writes.
+func imaTestLog(t *testing.T) []byte {
+	t.Helper()
+	var log bytes.Buffer
+	for _, file := range imaTestFiles {
+		m := &pb.ImaMeasurement{TemplateName: "ima-ng", FileDigest: file.digest, FileName: file.name}
+		templateHash, err := imaNGTemplateHash(m, crypto.SHA1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(&log, "10 %x ima-ng %s %s\n", templateHash, file.digest, file.name)
+	}
+	return log.Bytes()
+}
+
+// imaTestPCRs replays the first n measurements of the log into PCR 10, or
+// their PCR, of a bank, like the kernel does.
+func imaTestPCRs(t *testing.T, rawLog []byte, n int, hash tpm2.Algorithm, digestFunc func(*pb.ImaMeasurement, crypto.Hash) ([]byte, error)) *tpmpb.PCRs {
+	t.Helper()
+	h, err := hash.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	measurements, err := parseIMAMeasurements(rawLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pcrs := map[uint32][]byte{imaPCR: make([]byte, h.Size())}
+	for _, m := range measurements[:n] {
+		digest, err := digestFunc(m, h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pcr, ok := pcrs[m.GetPcr()]
+		if !ok {
+			pcr = make([]byte, h.Size())
+		}
+		hasher := h.New()
+		hasher.Write(pcr)
+		hasher.Write(digest)
+		pcrs[m.GetPcr()] = hasher.Sum(nil)
+	}
+	return &tpmpb.PCRs{Hash: tpmpb.HashAlgo(hash), Pcrs: pcrs}
+}
+
+// imaTestKernelModuleLog returns imaTestLog with kernel module loads measured
+// into client.KernelModuleIMAPCR between its measurements.
+func imaTestKernelModuleLog(t *testing.T) []byte {
+	t.Helper()
+	var log bytes.Buffer
+	for i, line := range bytes.SplitAfter(imaTestLog(t), []byte("\n")) {
+		log.Write(line)
+		if i >= len(imaTestKernelModules) {
+			continue
+		}
+		module := imaTestKernelModules[i]
+		m := &pb.ImaMeasurement{TemplateName: "ima-ng", FileDigest: module.FileDigest, FileName: module.FileName}
+		templateHash, err := imaNGTemplateHash(m, crypto.SHA1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(&log, "%d %x ima-ng %s %s\n", client.KernelModuleIMAPCR, templateHash, module.FileDigest, module.FileName)
+	}
+	return log.Bytes()
+}
+
+var imaTestKernelModules = []*pb.KernelModule{
+	{FileDigest: "sha256:" + strings.Repeat("01", 32), FileName: "/lib/modules/6.1.0/kernel/drivers/net/tun.ko"},
+	{FileDigest: "sha256:" + strings.Repeat("02", 32), FileName: "/lib/modules/6.1.0/kernel/fs/fuse/fuse.ko"},
+}
+
+func TestParseIMALog(t *testing.T) {
+	rawLog := imaTestLog(t)
+	tests := []struct {
+		name       string
+		hash       tpm2.Algorithm
+		digestFunc func(*pb.ImaMeasurement, crypto.Hash) ([]byte, error)
+		quoted     int
+	}{
+		{"SHA1", tpm2.AlgSHA1, paddedIMATemplateHash, 3},
+		{"SHA256Padded", tpm2.AlgSHA256, paddedIMATemplateHash, 3},
+		{"SHA256TemplateHash", tpm2.AlgSHA256, imaNGTemplateHash, 3},
+		{"LogAfterQuote", tpm2.AlgSHA256, imaNGTemplateHash, 2},
+		{"Empty", tpm2.AlgSHA256, imaNGTemplateHash, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pcrs := imaTestPCRs(t, rawLog, tc.quoted, tc.hash, tc.digestFunc)
+			state, err := parseIMALog(rawLog, pcrs)
+			if err != nil {
+				t.Fatalf("parseIMALog() failed: %v", err)
+			}
+			if got := len(state.GetMeasurements()); got != tc.quoted {
+				t.Fatalf("parseIMALog() returned %d measurements, want %d", got, tc.quoted)
+			}
+			for i, m := range state.GetMeasurements() {
+				if m.GetFileName() != imaTestFiles[i].name || m.GetFileDigest() != imaTestFiles[i].digest {
+					t.Errorf("measurement %d = %q %q, want %q %q", i, m.GetFileName(), m.GetFileDigest(), imaTestFiles[i].name, imaTestFiles[i].digest)
+				}
+			}
+		})
+	}
+}
+
+func TestParseIMALogViolation(t *testing.T) {
+	rawLog := append(imaTestLog(t), []byte(fmt.Sprintf("10 %s ima-ng sha256:%s /var/log/app\n", strings.Repeat("00", sha1.Size), strings.Repeat("00", 32)))...)
+	pcrs := imaTestPCRs(t, rawLog, 3, tpm2.AlgSHA1, paddedIMATemplateHash)
+	pcr := sha1.Sum(append(pcrs.Pcrs[imaPCR], bytes.Repeat([]byte{0xff}, sha1.Size)...))
+	pcrs.Pcrs[imaPCR] = pcr[:]
+
+	state, err := parseIMALog(rawLog, pcrs)
+	if err != nil {
+		t.Fatalf("parseIMALog() failed: %v", err)
+	}
+	if got := len(state.GetMeasurements()); got != 3 {
+		t.Errorf("parseIMALog() returned %d measurements, want 3", got)
+	}
+	if got := len(state.GetUnverifiedMeasurements()); got != 1 {
+		t.Errorf("parseIMALog() returned %d unverified measurements, want the violation", got)
+	}
+}
+
+func TestParseIMALogTemplateHash(t *testing.T) {
+	// The SHA-1 bank only covers the template hashes, so a file name or digest
+	// swapped next to a genuine template hash must be detected.
+	rawLog := imaTestLog(t)
+	pcrs := imaTestPCRs(t, rawLog, 3, tpm2.AlgSHA1, paddedIMATemplateHash)
+	for _, tampered := range [][]byte{
+		bytes.Replace(rawLog, []byte("/usr/bin/runc"), []byte("/usr/bin/evil"), 1),
+		bytes.Replace(rawLog, []byte("sha256:"+strings.Repeat("cd", 32)), []byte("sha256:"+strings.Repeat("ef", 32)), 1),
+	} {
+		if _, err := parseIMALog(tampered, pcrs); err == nil {
+			t.Errorf("parseIMALog() succeeded with a tampered log:\n%s", tampered)
+		}
+	}
+
+	// Other templates are replayed, but not verified.
+	imaSig := bytes.Replace(rawLog, []byte("ima-ng sha256:"+strings.Repeat("cd", 32)), []byte("ima-sig sha256:"+strings.Repeat("cd", 32)), 1)
+	state, err := parseIMALog(imaSig, pcrs)
+	if err != nil {
+		t.Fatalf("parseIMALog() failed: %v", err)
+	}
+	if len(state.GetMeasurements()) != 2 || len(state.GetUnverifiedMeasurements()) != 1 {
+		t.Errorf("got %d measurements and %d unverified, want 2 and 1", len(state.GetMeasurements()), len(state.GetUnverifiedMeasurements()))
+	}
+}
+
+func TestParseIMALogFailures(t *testing.T) {
+	rawLog := imaTestLog(t)
+	pcrs := imaTestPCRs(t, rawLog, 3, tpm2.AlgSHA256, imaNGTemplateHash)
+	wrongPCR := imaTestPCRs(t, rawLog, 3, tpm2.AlgSHA256, imaNGTemplateHash)
+	wrongPCR.Pcrs[imaPCR] = make([]byte, 32)
+	wrongPCR.Pcrs[imaPCR][0] = 1
+
+	tests := []struct {
+		name   string
+		rawLog []byte
+		pcrs   *tpmpb.PCRs
+	}{
+		{"PCRMismatch", rawLog, wrongPCR},
+		{"MissingPCR", rawLog, &tpmpb.PCRs{Hash: tpmpb.HashAlgo_SHA256, Pcrs: map[uint32][]byte{0: make([]byte, 32)}}},
+		{"TamperedFileName", bytes.Replace(rawLog, []byte("/usr/bin/runc"), []byte("/usr/bin/evil"), 1), pcrs},
+		{"UnsupportedPCR", []byte("12 " + hex.EncodeToString(make([]byte, 20)) + " ima-ng sha256:00 /x\n"), pcrs},
+		{"InvalidTemplateHash", []byte("10 abcd ima-ng sha256:00 /x\n"), pcrs},
+		{"TooFewFields", []byte("10 " + hex.EncodeToString(make([]byte, 20)) + "\n"), pcrs},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := parseIMALog(tc.rawLog, tc.pcrs); err == nil {
+				t.Error("parseIMALog() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestParseIMALogKernelModules(t *testing.T) {
+	// boot_aggregate, tun.ko, containerd, fuse.ko, runc.
+	rawLog := imaTestKernelModuleLog(t)
+	tests := []struct {
+		name       string
+		hash       tpm2.Algorithm
+		digestFunc func(*pb.ImaMeasurement, crypto.Hash) ([]byte, error)
+		quoted     int
+		pcr10      int
+		pcr11      int
+	}{
+		{"SHA1", tpm2.AlgSHA1, paddedIMATemplateHash, 5, 3, 2},
+		{"SHA256", tpm2.AlgSHA256, imaNGTemplateHash, 5, 3, 2},
+		{"LogAfterQuote", tpm2.AlgSHA256, imaNGTemplateHash, 3, 2, 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pcrs := imaTestPCRs(t, rawLog, tc.quoted, tc.hash, tc.digestFunc)
+			state, err := parseIMALog(rawLog, pcrs)
+			if err != nil {
+				t.Fatalf("parseIMALog() failed: %v", err)
+			}
+			var pcr10, pcr11 int
+			for _, m := range state.GetMeasurements() {
+				switch m.GetPcr() {
+				case imaPCR:
+					pcr10++
+				case client.KernelModuleIMAPCR:
+					pcr11++
+				}
+			}
+			if pcr10 != tc.pcr10 || pcr11 != tc.pcr11 {
+				t.Errorf("parseIMALog() returned %d PCR 10 and %d PCR 11 measurements, want %d and %d", pcr10, pcr11, tc.pcr10, tc.pcr11)
+			}
+		})
+	}
+
+	pcrs := imaTestPCRs(t, rawLog, 5, tpm2.AlgSHA256, imaNGTemplateHash)
+	wrongPCR := imaTestPCRs(t, rawLog, 5, tpm2.AlgSHA256, imaNGTemplateHash)
+	wrongPCR.Pcrs[client.KernelModuleIMAPCR] = make([]byte, 32)
+	wrongPCR.Pcrs[client.KernelModuleIMAPCR][0] = 1
+	missingPCR := imaTestPCRs(t, rawLog, 5, tpm2.AlgSHA256, imaNGTemplateHash)
+	delete(missingPCR.Pcrs, client.KernelModuleIMAPCR)
+	for name, tc := range map[string]struct {
+		rawLog []byte
+		pcrs   *tpmpb.PCRs
+	}{
+		"PCRMismatch":      {rawLog, wrongPCR},
+		"MissingPCR":       {rawLog, missingPCR},
+		"TamperedFileName": {bytes.Replace(rawLog, []byte("tun.ko"), []byte("evil.ko"), 1), pcrs},
+	} {
+		if _, err := parseIMALog(tc.rawLog, tc.pcrs); err == nil {
+			t.Errorf("%s: parseIMALog() succeeded, want error", name)
+		}
+	}
+}
+
+func TestCheckKernelModules(t *testing.T) {
+	rawLog := imaTestKernelModuleLog(t)
+	state, err := parseIMALog(rawLog, imaTestPCRs(t, rawLog, 5, tpm2.AlgSHA256, imaNGTemplateHash))
+	if err != nil {
+		t.Fatalf("parseIMALog() failed: %v", err)
+	}
+	if err := checkKernelModules(imaTestKernelModules, state); err != nil {
+		t.Errorf("checkKernelModules() failed: %v", err)
+	}
+	if err := checkKernelModules(nil, state); err != nil {
+		t.Errorf("checkKernelModules() failed without kernel modules: %v", err)
+	}
+
+	// A module of the CEL must be a load measured into PCR 11 and quoted.
+	for name, modules := range map[string][]*pb.KernelModule{
+		"Unmeasured": {{FileDigest: "sha256:" + strings.Repeat("03", 32), FileName: "/evil.ko"}},
+		"PCR10":      {{FileDigest: imaTestFiles[1].digest, FileName: imaTestFiles[1].name}},
+		"Duplicated": {imaTestKernelModules[0], imaTestKernelModules[0]},
+	} {
+		if err := checkKernelModules(modules, state); err == nil {
+			t.Errorf("%s: checkKernelModules() succeeded, want error", name)
+		}
+	}
+	quoted, err := parseIMALog(rawLog, imaTestPCRs(t, rawLog, 3, tpm2.AlgSHA256, imaNGTemplateHash))
+	if err != nil {
+		t.Fatalf("parseIMALog() failed: %v", err)
+	}
+	if err := checkKernelModules(imaTestKernelModules, quoted); err == nil {
+		t.Error("checkKernelModules() succeeded with a module loaded after the quote")
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/google/go-tpm/tpm2"
 	"github.com/google/go-tpm/tpmutil"
 
+	"github.com/google/go-tpm-tools/cel"
 	"github.com/google/go-tpm-tools/client"
 	"github.com/google/go-tpm-tools/internal"
 	pb "github.com/google/go-tpm-tools/proto/tpm"
@@ -36,6 +37,44 @@ func CreateImportBlob(ekPub crypto.PublicKey, sensitive []byte, pcrs *pb.PCRs) (
 	return createImportBlobHelper(ek, public, private, pcrs)
 }
 
+// ImageBootPCRs are the PCRs CreateImportBlobForImage requires: the firmware
+// (0), the boot loader (4), Secure Boot (7), and the kernel command line and
+// kernel (8 and 9), which measure the dm-verity root of the image containing
+// the launcher.
+var ImageBootPCRs = []uint32{0, 4, 7, 8, 9}
+
+// CreateImportBlobForImage is like CreateImportBlob, but Import() only
+// succeeds once a workload with the image digest (e.g. "sha256:...") was
+// measured to cel.CosImagePCR. As the image digest is public, any process
+// with root access to the TPM can extend cel.CosImagePCR with it, so the
+// blob is only bound to a workload of the launcher with the values of the
+// boot PCRs: pcrs must contain the ImageBootPCRs of a machine booted with the
+// expected image, e.g. from a verified attestation, and must not contain
+// cel.CosImagePCR.
+func CreateImportBlobForImage(ekPub crypto.PublicKey, sensitive []byte, imageDigest string, pcrs *pb.PCRs) (*pb.ImportBlob, error) {
+	for _, idx := range ImageBootPCRs {
+		if _, ok := pcrs.GetPcrs()[idx]; !ok {
+			return nil, fmt.Errorf("pcrs must contain the boot PCR%d", idx)
+		}
+	}
+	if _, ok := pcrs.GetPcrs()[cel.CosImagePCR]; ok {
+		return nil, fmt.Errorf("pcrs already contain the image PCR%d", cel.CosImagePCR)
+	}
+	imagePCRs := &pb.PCRs{Hash: pcrs.GetHash(), Pcrs: map[uint32][]byte{}}
+	for idx, value := range pcrs.GetPcrs() {
+		imagePCRs.Pcrs[idx] = value
+	}
+	hash, err := tpm2.Algorithm(imagePCRs.GetHash()).Hash()
+	if err != nil {
+		return nil, err
+	}
+	imagePCRs.Pcrs[cel.CosImagePCR], err = cel.ImagePCRValue(hash, imageDigest)
+	if err != nil {
+		return nil, err
+	}
+	return CreateImportBlob(ekPub, sensitive, imagePCRs)
+}
+
 // CreateSigningKeyImportBlob uses the provided public EK to encrypt the signing
 // key into import blob format. The returned import blob can be used to import
 // the signing key into the TPM associated with the provided EK without exposing
@@ -58,21 +97,9 @@ func CreateSigningKeyImportBlob(ekPub crypto.PublicKey, signingKey crypto.Privat
 func createImportBlobHelper(ek, public tpm2.Public, private tpm2.Private, pcrs *pb.PCRs) (*pb.ImportBlob, error) {
 	setPublicAuth(&public, pcrs)
 
-	var seed, encryptedSeed []byte
-	var err error
-	switch ek.Type {
-	case tpm2.AlgRSA:
-		seed, encryptedSeed, err = createRSASeed(ek)
-		if err != nil {
-			return nil, err
-		}
-	case tpm2.AlgECC:
-		seed, encryptedSeed, err = createECCSeed(ek)
-		if err != nil {
-			return nil, err
-		}
-	default:
-		return nil, fmt.Errorf("unsupported EK type: %v", ek.Type)
+	seed, encryptedSeed, err := createSeed(ek, duplicateLabel)
+	if err != nil {
+		return nil, err
 	}
 	duplicate, err := createDuplicate(private, seed, public, ek)
 	if err != nil {
@@ -102,7 +129,26 @@ func setPublicAuth(public *tpm2.Public, pcrs *pb.PCRs) {
 	}
 }
 
-func createRSASeed(ek tpm2.Public) (seed, encryptedSeed []byte, err error) {
+// Labels used when deriving keys from a seed protected by the EK.
+const (
+	duplicateLabel = "DUPLICATE"
+	identityLabel  = "IDENTITY"
+)
+
+// createSeed creates a seed and encrypts it to the EK, using the label to
+// select what the seed is used for.
+func createSeed(ek tpm2.Public, label string) (seed, encryptedSeed []byte, err error) {
+	switch ek.Type {
+	case tpm2.AlgRSA:
+		return createRSASeed(ek, label)
+	case tpm2.AlgECC:
+		return createECCSeed(ek, label)
+	default:
+		return nil, nil, fmt.Errorf("unsupported EK type: %v", ek.Type)
+	}
+}
+
+func createRSASeed(ek tpm2.Public, label string) (seed, encryptedSeed []byte, err error) {
 	seedSize := ek.RSAParameters.Symmetric.KeyBits / 8
 	seed = make([]byte, seedSize)
 	if _, err := io.ReadFull(rand.Reader, seed); err != nil {
@@ -118,7 +164,7 @@ func createRSASeed(ek tpm2.Public) (seed, encryptedSeed []byte, err error) {
 		rand.Reader,
 		ekPub.(*rsa.PublicKey),
 		seed,
-		[]byte("DUPLICATE\x00"))
+		append([]byte(label), 0))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -126,7 +172,7 @@ func createRSASeed(ek tpm2.Public) (seed, encryptedSeed []byte, err error) {
 	return seed, encryptedSeed, err
 }
 
-func createECCSeed(ek tpm2.Public) (seed, encryptedSeed []byte, err error) {
+func createECCSeed(ek tpm2.Public, label string) (seed, encryptedSeed []byte, err error) {
 	curve, err := curveIDToGoCurve(ek.ECCParameters.CurveID)
 	if err != nil {
 		return nil, nil, err
@@ -142,7 +188,7 @@ func createECCSeed(ek tpm2.Public) (seed, encryptedSeed []byte, err error) {
 	seed, err = tpm2.KDFe(
 		ek.NameAlg,
 		eccIntToBytes(curve, z),
-		"DUPLICATE",
+		label,
 		xBytes,
 		eccIntToBytes(curve, ekPoint.X()),
 		getHash(ek.NameAlg).Size()*8)
@@ -162,6 +208,12 @@ func createDuplicate(private tpm2.Private, seed []byte, public, ek tpm2.Public)
 	if err != nil {
 		return nil, err
 	}
+	return createIDObject(secret, seed, nameEncoded, ek)
+}
+
+// createIDObject encrypts the secret with a key derived from the seed and the
+// name of the object, returning an encoded TPMS_ID_OBJECT.
+func createIDObject(secret, seed, nameEncoded []byte, ek tpm2.Public) ([]byte, error) {
 	packedSecret, err := tpmutil.Pack(tpmutil.U16Bytes(secret))
 	if err != nil {
 		return nil, err
@@ -215,7 +267,7 @@ func encryptSecret(secret, seed, nameEncoded []byte, ek tpm2.Public) ([]byte, er
 	}
 	encSecret := make([]byte, len(secret))
 	// The TPM spec requires an all-zero IV.
-	iv := make([]byte, len(symmetricKey))
+	iv := make([]byte, c.BlockSize())
 	cipher.NewCFBEncrypter(c, iv).XORKeyStream(encSecret, secret)
 	return encSecret, nil
 }
@@ -8,10 +8,12 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/google/go-tpm-tools/cel"
 	"github.com/google/go-tpm-tools/client"
 	"github.com/google/go-tpm-tools/internal/test"
 	pb "github.com/google/go-tpm-tools/proto/tpm"
 	"github.com/google/go-tpm/tpm2"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestImport(t *testing.T) {
@@ -27,7 +29,7 @@ func TestImport(t *testing.T) {
 		{"SRK-ECC", client.SRKTemplateECC()},
 		{"ECC-P224", getECCTemplate(tpm2.CurveNISTP224)},
 		{"ECC-P256", getECCTemplate(tpm2.CurveNISTP256)},
-		{"ECC-P384", getECCTemplate(tpm2.CurveNISTP384)},
+		{"ECC-P384", client.EKTemplateECCP384()},
 		{"ECC-P521", getECCTemplate(tpm2.CurveNISTP521)},
 	}
 	for _, k := range keys {
@@ -184,6 +186,76 @@ func TestImportPCRs(t *testing.T) {
 	}
 }
 
+func TestImportForImage(t *testing.T) {
+	test.SkipForRealTPM(t)
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	ek, err := client.EndorsementKeyRSA(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ek.Close()
+	const imageDigest = "sha256:781d8dfdd92118436bd914442c8339e653b83f6bf3c1a7a98efcfb7c4fed7483"
+	secret := []byte("super secret code")
+	bootPCRs, err := client.ReadPCRs(rwc, tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{0, 4, 7, 8, 9}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, err := CreateImportBlobForImage(ek.PublicKey(), secret, imageDigest, bootPCRs)
+	if err != nil {
+		t.Fatalf("creating import blob failed: %v", err)
+	}
+	if _, err := ek.Import(blob); err == nil {
+		t.Error("import succeeded before the image was measured")
+	}
+
+	if err := cel.ExtendImagePCR(rwc, []crypto.Hash{crypto.SHA256}, imageDigest); err != nil {
+		t.Fatal(err)
+	}
+	otherBoot := proto.Clone(bootPCRs).(*pb.PCRs)
+	otherBoot.Pcrs[8] = bytes.Repeat([]byte{0x01}, 32)
+	subtests := []struct {
+		name          string
+		imageDigest   string
+		pcrs          *pb.PCRs
+		expectSuccess bool
+	}{
+		{"Good-Image", imageDigest, bootPCRs, true},
+		{"Bad-Image", "sha256:5df4a1ac347dcf8cf5e9d0abc04b04db847d1b88d3b1cc1006f0acb68e5a1f4b", bootPCRs, false},
+		{"Bad-Boot", imageDigest, otherBoot, false},
+	}
+	for _, subtest := range subtests {
+		t.Run(subtest.name, func(t *testing.T) {
+			blob, err := CreateImportBlobForImage(ek.PublicKey(), secret, subtest.imageDigest, subtest.pcrs)
+			if err != nil {
+				t.Fatalf("creating import blob failed: %v", err)
+			}
+			output, err := ek.Import(blob)
+			if subtest.expectSuccess {
+				if err != nil {
+					t.Fatalf("import failed: %v", err)
+				}
+				if !bytes.Equal(output, secret) {
+					t.Errorf("got %X, expected %X", output, secret)
+				}
+			} else if err == nil {
+				t.Error("expected Import to fail but it did not")
+			}
+		})
+	}
+
+	imagePCR := proto.Clone(bootPCRs).(*pb.PCRs)
+	imagePCR.Pcrs[cel.CosImagePCR] = bootPCRs.Pcrs[0]
+	missingBoot := proto.Clone(bootPCRs).(*pb.PCRs)
+	delete(missingBoot.Pcrs, 7)
+	for _, pcrs := range []*pb.PCRs{nil, {Hash: pb.HashAlgo_SHA256}, missingBoot, imagePCR} {
+		if _, err := CreateImportBlobForImage(ek.PublicKey(), secret, imageDigest, pcrs); err == nil {
+			t.Errorf("CreateImportBlobForImage succeeded with pcrs %v", pcrs)
+		}
+	}
+}
+
 func TestSigningKeyImport(t *testing.T) {
 	rwc := test.GetTPM(t)
 	defer client.CheckedClose(t, rwc)
@@ -1,9 +1,22 @@
 package server
 
 import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/google/go-tpm-tools/internal"
 	pb "github.com/google/go-tpm-tools/proto/attest"
 )
 
@@ -17,3 +30,148 @@ func GCEInstanceURL(i *pb.GCEInstanceInfo) string {
 		url.PathEscape(i.GetInstanceName()), // Can use either the name or id here
 	)
 }
+
+// InstancePolicy returns a GCEInstancePolicy only allowing the GCE instance
+// of info, e.g. the instance an attestation is expected from, for
+// PlatformPolicy.instance.
+func InstancePolicy(info *pb.GCEInstanceInfo) *pb.GCEInstancePolicy {
+	return &pb.GCEInstancePolicy{
+		AllowedProjectIds:     []string{info.GetProjectId()},
+		AllowedProjectNumbers: []uint64{info.GetProjectNumber()},
+		AllowedZones:          []string{info.GetZone()},
+		AllowedInstanceIds:    []uint64{info.GetInstanceId()},
+		AllowedInstanceNames:  []string{info.GetInstanceName()},
+	}
+}
+
+// checkShieldedVMSigningKey checks that the PEM signing key of the Shielded VM
+// identity of a GCE instance is the attestation key.
+func checkShieldedVMSigningKey(signingKeyPub string, ak crypto.PublicKey) error {
+	block, _ := pem.Decode([]byte(signingKeyPub))
+	if block == nil {
+		return errors.New("signing key is not PEM encoded")
+	}
+	signingKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse the signing key: %w", err)
+	}
+	if !internal.PubKeysEqual(signingKey, ak) {
+		return errors.New("signing key is not the attestation key")
+	}
+	return nil
+}
+
+// googleIdentityIssuers are the issuers of the instance identity tokens.
+var googleIdentityIssuers = []string{"https://accounts.google.com", "accounts.google.com"}
+
+// ParseGoogleIdentityCerts parses the certificates of the instance identity
+// tokens of GCE instances, by key ID, in the format served at
+// https://www.googleapis.com/oauth2/v1/certs, for VerifyOpts.GCEIdentityCerts.
+func ParseGoogleIdentityCerts(data []byte) (map[string]*x509.Certificate, error) {
+	var pems map[string]string
+	if err := json.Unmarshal(data, &pems); err != nil {
+		return nil, fmt.Errorf("failed to parse the certificates: %w", err)
+	}
+	certs := make(map[string]*x509.Certificate, len(pems))
+	for kid, certPEM := range pems {
+		block, _ := pem.Decode([]byte(certPEM))
+		if block == nil {
+			return nil, fmt.Errorf("certificate %s is not PEM encoded", kid)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate %s: %w", kid, err)
+		}
+		certs[kid] = cert
+	}
+	return certs, nil
+}
+
+// instanceIdentityClaims are the claims of an instance identity token in the
+// full format.
+type instanceIdentityClaims struct {
+	Issuer    string `json:"iss"`
+	Audience  string `json:"aud"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+	Google    struct {
+		ComputeEngine struct {
+			ProjectID     string `json:"project_id"`
+			ProjectNumber uint64 `json:"project_number"`
+			Zone          string `json:"zone"`
+			InstanceID    string `json:"instance_id"`
+			InstanceName  string `json:"instance_name"`
+		} `json:"compute_engine"`
+	} `json:"google"`
+}
+
+// checkInstanceIdentityToken checks that the instance identity token of a GCE
+// instance is signed with one of certs, valid at now, and bound to the nonce
+// of the attestation by its audience. If instance is not nil, e.g. from the AK
+// certificate, the token must be for the same instance.
+func checkInstanceIdentityToken(token string, certs map[string]*x509.Certificate, nonce []byte, now time.Time, instance *pb.GCEInstanceInfo) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return errors.New("token is not a JWT")
+	}
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return fmt.Errorf("failed to parse the token header: %w", err)
+	}
+	if header.Algorithm != "RS256" {
+		return fmt.Errorf("unsupported token algorithm %q", header.Algorithm)
+	}
+	cert, ok := certs[header.KeyID]
+	if !ok {
+		return fmt.Errorf("unknown token key ID %q", header.KeyID)
+	}
+	if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return fmt.Errorf("certificate of token key ID %q is not valid at %v", header.KeyID, now)
+	}
+	pub, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("certificate of token key ID %q does not have an RSA key", header.KeyID)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("failed to decode the token signature: %w", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig); err != nil {
+		return fmt.Errorf("bad token signature: %w", err)
+	}
+
+	var claims instanceIdentityClaims
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return fmt.Errorf("failed to parse the token claims: %w", err)
+	}
+	if !containsString(googleIdentityIssuers, claims.Issuer) {
+		return fmt.Errorf("unexpected token issuer %q", claims.Issuer)
+	}
+	audience := sha256.Sum256(nonce)
+	if claims.Audience != hex.EncodeToString(audience[:]) {
+		return errors.New("token audience is not the digest of the nonce")
+	}
+	if now.Unix() >= claims.ExpiresAt || now.Unix() < claims.IssuedAt {
+		return fmt.Errorf("token is not valid at %v", now)
+	}
+	if instance != nil {
+		computeEngine := claims.Google.ComputeEngine
+		if computeEngine.InstanceID != strconv.FormatUint(instance.GetInstanceId(), 10) || computeEngine.ProjectID != instance.GetProjectId() {
+			return fmt.Errorf("token is for instance %s of project %s, want instance %d of project %s",
+				computeEngine.InstanceID, computeEngine.ProjectID, instance.GetInstanceId(), instance.GetProjectId())
+		}
+	}
+	return nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
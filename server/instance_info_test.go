@@ -0,0 +1,112 @@
+package server
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+)
+
+// signIdentityToken returns an RS256 JWT of the claims signed with key.
+func signIdentityToken(t *testing.T, key *rsa.PrivateKey, kid string, claims interface{}) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestCheckInstanceIdentityToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certsJSON, err := json.Marshal(map[string]string{"key-1": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	certs, err := ParseGoogleIdentityCerts(certsJSON)
+	if err != nil {
+		t.Fatalf("ParseGoogleIdentityCerts() failed: %v", err)
+	}
+
+	nonce := []byte("super secret nonce")
+	audience := sha256.Sum256(nonce)
+	claims := func(aud string, exp time.Time, instanceID string) map[string]interface{} {
+		return map[string]interface{}{
+			"iss": "https://accounts.google.com",
+			"aud": aud,
+			"iat": now.Add(-time.Minute).Unix(),
+			"exp": exp.Unix(),
+			"google": map[string]interface{}{
+				"compute_engine": map[string]interface{}{
+					"project_id":     "test-project",
+					"project_number": 1234,
+					"zone":           "us-central1-a",
+					"instance_id":    instanceID,
+					"instance_name":  "test-instance",
+				},
+			},
+		}
+	}
+	goodAudience := hex.EncodeToString(audience[:])
+	instance := &pb.GCEInstanceInfo{ProjectId: "test-project", InstanceId: 5678}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		token    string
+		instance *pb.GCEInstanceInfo
+		wantErr  bool
+	}{
+		{"Good", signIdentityToken(t, key, "key-1", claims(goodAudience, now.Add(time.Hour), "5678")), nil, false},
+		{"SameInstance", signIdentityToken(t, key, "key-1", claims(goodAudience, now.Add(time.Hour), "5678")), instance, false},
+		{"OtherInstance", signIdentityToken(t, key, "key-1", claims(goodAudience, now.Add(time.Hour), "9999")), instance, true},
+		{"OtherAudience", signIdentityToken(t, key, "key-1", claims("other", now.Add(time.Hour), "5678")), nil, true},
+		{"Expired", signIdentityToken(t, key, "key-1", claims(goodAudience, now.Add(-time.Second), "5678")), nil, true},
+		{"UnknownKey", signIdentityToken(t, key, "key-2", claims(goodAudience, now.Add(time.Hour), "5678")), nil, true},
+		{"BadSignature", signIdentityToken(t, otherKey, "key-1", claims(goodAudience, now.Add(time.Hour), "5678")), nil, true},
+		{"NotJWT", "token", nil, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkInstanceIdentityToken(tc.token, certs, nonce, now, tc.instance)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("checkInstanceIdentityToken() = %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+// Package inventory flattens verified machine states into the rows of a
+// table, the attested inventory of a fleet, so compliance teams can query the
+// attestation posture of the machines. The rows can be written as CSV, or as
+// newline-delimited JSON for loading into BigQuery with the schema of
+// WriteBigQuerySchema.
+package inventory
+
+import (
+	"fmt"
+	"time"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+)
+
+// Record is a row of the inventory: the verified state of a machine.
+type Record struct {
+	// VerifiedAt is when the attestation was verified.
+	VerifiedAt time.Time
+
+	// The GCE instance, empty if the machine is not a GCE instance.
+	ProjectID     string
+	ProjectNumber uint64
+	Zone          string
+	InstanceName  string
+	InstanceID    uint64
+
+	// Technology is the Confidential Computing technology of the machine,
+	// e.g. "SEV_SNP".
+	Technology          string
+	TEEEvidenceVerified bool
+	GCECertifiedAK      bool
+	SecureBoot          bool
+	// GCEFirmwareVersion is the version of the virtual GCE firmware, zero if
+	// the firmware is not the GCE firmware.
+	GCEFirmwareVersion uint32
+	// TPMFirmwareVersion is zero if it was not signed in the quote.
+	TPMFirmwareVersion uint64
+	KernelCommandLine  string
+	EventLogOmitted    bool
+
+	// The versions of COS and of the launcher, "major.minor.patch".
+	COSVersion      string
+	LauncherVersion string
+	// LauncherBinaryDigest is the digest of the launcher binary, see
+	// cel.LauncherType.
+	LauncherBinaryDigest string
+
+	// The workload container measured by the launcher, if any.
+	ImageReference      string
+	ImageDigest         string
+	ImageManifestDigest string
+	RestartPolicy       string
+	HostNetwork         bool
+	ReadOnlyRootfs      bool
+	User                string
+	UserNamespace       bool
+	SeccompProfile      string
+	AppArmorProfile     string
+	LaunchPolicyDigest  string
+
+	// WorkloadName is the name of the workload which is not a container, if
+	// any, see cel.WorkloadNameType.
+	WorkloadName string
+}
+
+// NewRecord flattens the verified state of a machine into a Record.
+func NewRecord(state *pb.MachineState, verifiedAt time.Time) Record {
+	info := state.GetPlatform().GetInstanceInfo()
+	cos := state.GetCos()
+	container := cos.GetContainer()
+	r := Record{
+		VerifiedAt:           verifiedAt.UTC(),
+		ProjectID:            info.GetProjectId(),
+		ProjectNumber:        info.GetProjectNumber(),
+		Zone:                 info.GetZone(),
+		InstanceName:         info.GetInstanceName(),
+		InstanceID:           info.GetInstanceId(),
+		Technology:           state.GetPlatform().GetTechnology().String(),
+		TEEEvidenceVerified:  state.GetConfidentialComputing().GetTeeEvidenceVerified(),
+		GCECertifiedAK:       state.GetConfidentialComputing().GetGceCertifiedAk(),
+		SecureBoot:           state.GetSecureBoot().GetEnabled(),
+		GCEFirmwareVersion:   state.GetPlatform().GetGceVersion(),
+		TPMFirmwareVersion:   state.GetPlatform().GetTpmFirmwareVersion(),
+		KernelCommandLine:    state.GetLinuxKernel().GetCommandLine(),
+		EventLogOmitted:      state.GetEventLogOmitted(),
+		COSVersion:           formatVersion(cos.GetCosVersion()),
+		LauncherVersion:      formatVersion(cos.GetLauncherVersion()),
+		LauncherBinaryDigest: cos.GetLauncherBinaryDigest(),
+		ImageReference:       container.GetImageReference(),
+		ImageDigest:          container.GetImageDigest(),
+		ImageManifestDigest:  container.GetImageManifestDigest(),
+		HostNetwork:          container.GetHostNetwork(),
+		ReadOnlyRootfs:       container.GetReadOnlyRootfs(),
+		User:                 container.GetUser(),
+		UserNamespace:        container.GetUserNamespace(),
+		SeccompProfile:       container.GetSeccompProfile(),
+		AppArmorProfile:      container.GetApparmorProfile(),
+		LaunchPolicyDigest:   container.GetLaunchPolicyDigest(),
+		WorkloadName:         cos.GetWorkload().GetName(),
+	}
+	if container.GetImageDigest() != "" {
+		r.RestartPolicy = container.GetRestartPolicy().String()
+	}
+	return r
+}
+
+func formatVersion(v *pb.SemanticVersion) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d.%d.%d", v.GetMajor(), v.GetMinor(), v.GetPatch())
+}
+
+// BigQuery column types.
+const (
+	typeString    = "STRING"
+	typeInteger   = "INTEGER"
+	typeBoolean   = "BOOLEAN"
+	typeTimestamp = "TIMESTAMP"
+)
+
+// column is a column of the inventory table.
+type column struct {
+	name        string
+	bqType      string
+	description string
+	// value returns the value of the column for a record, a string, bool,
+	// time.Time, or an integer which fits an int64.
+	value func(r *Record) interface{}
+}
+
+// columns are the columns of the inventory table, in order. The IDs and the
+// TPM firmware version are strings, as they may not fit a BigQuery INTEGER.
+var columns = []column{
+	{"verified_at", typeTimestamp, "When the attestation was verified.", func(r *Record) interface{} { return r.VerifiedAt }},
+	{"project_id", typeString, "The GCE project of the instance.", func(r *Record) interface{} { return r.ProjectID }},
+	{"project_number", typeString, "The number of the GCE project of the instance.", func(r *Record) interface{} { return formatID(r.ProjectNumber) }},
+	{"zone", typeString, "The GCE zone of the instance.", func(r *Record) interface{} { return r.Zone }},
+	{"instance_name", typeString, "The name of the GCE instance.", func(r *Record) interface{} { return r.InstanceName }},
+	{"instance_id", typeString, "The ID of the GCE instance.", func(r *Record) interface{} { return formatID(r.InstanceID) }},
+	{"technology", typeString, "The Confidential Computing technology of the machine.", func(r *Record) interface{} { return r.Technology }},
+	{"tee_evidence_verified", typeBoolean, "Whether TEE evidence proves the technology.", func(r *Record) interface{} { return r.TEEEvidenceVerified }},
+	{"gce_certified_ak", typeBoolean, "Whether the AK certificate is a production GCE certificate.", func(r *Record) interface{} { return r.GCECertifiedAK }},
+	{"secure_boot", typeBoolean, "Whether Secure Boot was enabled.", func(r *Record) interface{} { return r.SecureBoot }},
+	{"gce_firmware_version", typeInteger, "The version of the virtual GCE firmware.", func(r *Record) interface{} { return int64(r.GCEFirmwareVersion) }},
+	{"tpm_firmware_version", typeString, "The firmware version of the TPM, in hexadecimal.", func(r *Record) interface{} { return formatHex(r.TPMFirmwareVersion) }},
+	{"kernel_command_line", typeString, "The command line of the Linux kernel.", func(r *Record) interface{} { return r.KernelCommandLine }},
+	{"event_log_omitted", typeBoolean, "Whether the attestation omitted the TCG event log.", func(r *Record) interface{} { return r.EventLogOmitted }},
+	{"cos_version", typeString, "The version of COS.", func(r *Record) interface{} { return r.COSVersion }},
+	{"launcher_version", typeString, "The version of the launcher.", func(r *Record) interface{} { return r.LauncherVersion }},
+	{"launcher_binary_digest", typeString, "The digest of the launcher binary.", func(r *Record) interface{} { return r.LauncherBinaryDigest }},
+	{"image_reference", typeString, "The reference of the container image.", func(r *Record) interface{} { return r.ImageReference }},
+	{"image_digest", typeString, "The digest of the container image.", func(r *Record) interface{} { return r.ImageDigest }},
+	{"image_manifest_digest", typeString, "The digest of the image manifest for the platform.", func(r *Record) interface{} { return r.ImageManifestDigest }},
+	{"restart_policy", typeString, "The restart policy of the container.", func(r *Record) interface{} { return r.RestartPolicy }},
+	{"host_network", typeBoolean, "Whether the container uses the host network.", func(r *Record) interface{} { return r.HostNetwork }},
+	{"read_only_rootfs", typeBoolean, "Whether the root filesystem of the container is read-only.", func(r *Record) interface{} { return r.ReadOnlyRootfs }},
+	{"user", typeString, "The user overriding the user of the image.", func(r *Record) interface{} { return r.User }},
+	{"user_namespace", typeBoolean, "Whether the container runs in a user namespace.", func(r *Record) interface{} { return r.UserNamespace }},
+	{"seccomp_profile", typeString, "The seccomp profile of the container.", func(r *Record) interface{} { return r.SeccompProfile }},
+	{"apparmor_profile", typeString, "The AppArmor profile of the container.", func(r *Record) interface{} { return r.AppArmorProfile }},
+	{"launch_policy_digest", typeString, "The digest of the enforced launch policy document.", func(r *Record) interface{} { return r.LaunchPolicyDigest }},
+	{"workload_name", typeString, "The name of the workload which is not a container.", func(r *Record) interface{} { return r.WorkloadName }},
+}
+
+func formatID(id uint64) string {
+	if id == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", id)
+}
+
+func formatHex(v uint64) string {
+	if v == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%#x", v)
+}
+
+// Columns returns the names of the columns of the inventory table, in order.
+func Columns() []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	return names
+}
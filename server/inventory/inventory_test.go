@@ -0,0 +1,144 @@
+package inventory
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+)
+
+var verifiedAt = time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func testState() *pb.MachineState {
+	return &pb.MachineState{
+		Platform: &pb.PlatformState{
+			Firmware:           &pb.PlatformState_GceVersion{GceVersion: 20},
+			Technology:         pb.GCEConfidentialTechnology_AMD_SEV_SNP,
+			InstanceInfo:       &pb.GCEInstanceInfo{ProjectId: "project", ProjectNumber: 123, Zone: "us-central1-a", InstanceName: "instance", InstanceId: 1 << 63},
+			TpmFirmwareVersion: 0x2000200000000,
+		},
+		SecureBoot:  &pb.SecureBootState{Enabled: true},
+		LinuxKernel: &pb.LinuxKernelState{CommandLine: "console=ttyS0"},
+		Cos: &pb.AttestedCosState{
+			CosVersion: &pb.SemanticVersion{Major: 101, Minor: 17162, Patch: 40},
+			Container: &pb.ContainerState{
+				ImageReference: "docker.io/library/hello-world:latest",
+				ImageDigest:    "sha256:digest",
+				RestartPolicy:  pb.RestartPolicy_Always,
+				ReadOnlyRootfs: true,
+				SeccompProfile: "runtime-default",
+			},
+		},
+	}
+}
+
+func TestNewRecord(t *testing.T) {
+	r := NewRecord(testState(), verifiedAt.In(time.FixedZone("CET", 3600)))
+	if r.VerifiedAt != verifiedAt || r.VerifiedAt.Location() != time.UTC {
+		t.Errorf("got VerifiedAt %v, want %v", r.VerifiedAt, verifiedAt)
+	}
+	if r.InstanceID != 1<<63 || r.Technology != "AMD_SEV_SNP" || !r.SecureBoot || r.GCEFirmwareVersion != 20 {
+		t.Errorf("got platform %+v", r)
+	}
+	if r.COSVersion != "101.17162.40" || r.LauncherVersion != "" {
+		t.Errorf("got COS version %q and launcher version %q", r.COSVersion, r.LauncherVersion)
+	}
+	if r.ImageDigest != "sha256:digest" || r.RestartPolicy != "Always" || !r.ReadOnlyRootfs {
+		t.Errorf("got container %+v", r)
+	}
+
+	// A machine without a container has no restart policy.
+	if r := NewRecord(&pb.MachineState{}, verifiedAt); r.RestartPolicy != "" || r.Technology != "NONE" {
+		t.Errorf("got restart policy %q and technology %q without a container", r.RestartPolicy, r.Technology)
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf)
+	for _, state := range []*pb.MachineState{testState(), {}} {
+		if err := w.Write(NewRecord(state, verifiedAt)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want the header and 2 records", len(rows))
+	}
+	if got, want := strings.Join(rows[0], ","), strings.Join(Columns(), ","); got != want {
+		t.Errorf("got header %q, want %q", got, want)
+	}
+	row := make(map[string]string)
+	for i, name := range rows[0] {
+		row[name] = rows[1][i]
+	}
+	for name, want := range map[string]string{
+		"verified_at":          "2023-01-02T03:04:05Z",
+		"instance_id":          "9223372036854775808",
+		"secure_boot":          "true",
+		"gce_firmware_version": "20",
+		"tpm_firmware_version": "0x2000200000000",
+		"image_digest":         "sha256:digest",
+	} {
+		if row[name] != want {
+			t.Errorf("got %s %q, want %q", name, row[name], want)
+		}
+	}
+}
+
+func TestBigQueryWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewBigQueryWriter(&buf)
+	if err := w.Write(NewRecord(testState(), verifiedAt)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	var row map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &row); err != nil {
+		t.Fatalf("invalid JSON row: %v", err)
+	}
+	if len(row) != len(Columns()) {
+		t.Errorf("got %d columns, want %d", len(row), len(Columns()))
+	}
+	if row["verified_at"] != "2023-01-02T03:04:05Z" || row["secure_boot"] != true || row["gce_firmware_version"] != float64(20) {
+		t.Errorf("got row %v", row)
+	}
+
+	var schema bytes.Buffer
+	if err := WriteBigQuerySchema(&schema); err != nil {
+		t.Fatal(err)
+	}
+	var fields []bigQueryField
+	if err := json.Unmarshal(schema.Bytes(), &fields); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	for i, field := range fields {
+		value := columns[i].value(&Record{})
+		var want string
+		switch value.(type) {
+		case string:
+			want = typeString
+		case bool:
+			want = typeBoolean
+		case int64:
+			want = typeInteger
+		case time.Time:
+			want = typeTimestamp
+		}
+		if field.Name != Columns()[i] || field.Type != want {
+			t.Errorf("got schema field %+v for a %T column", field, value)
+		}
+	}
+}
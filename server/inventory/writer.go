@@ -0,0 +1,122 @@
+package inventory
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Writer writes the Records of the inventory in a tabular format.
+type Writer interface {
+	Write(r Record) error
+	// Flush writes the buffered records to the underlying io.Writer.
+	Flush() error
+}
+
+// NewCSVWriter returns a Writer of CSV, with a header row of the Columns
+// before the first record. Timestamps are in RFC 3339 format.
+func NewCSVWriter(w io.Writer) Writer {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (w *csvWriter) Write(r Record) error {
+	if !w.headerWritten {
+		if err := w.w.Write(Columns()); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+	row := make([]string, len(columns))
+	for i, c := range columns {
+		switch v := c.value(&r).(type) {
+		case string:
+			row[i] = v
+		case bool:
+			row[i] = strconv.FormatBool(v)
+		case int64:
+			row[i] = strconv.FormatInt(v, 10)
+		case time.Time:
+			row[i] = v.Format(time.RFC3339)
+		default:
+			return fmt.Errorf("column %s has unsupported type %T", c.name, v)
+		}
+	}
+	return w.w.Write(row)
+}
+
+func (w *csvWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// NewBigQueryWriter returns a Writer of newline-delimited JSON, one object
+// per record, for loading into a BigQuery table with the schema of
+// WriteBigQuerySchema:
+//
+//	bq load --source_format=NEWLINE_DELIMITED_JSON DATASET.TABLE inventory.json schema.json
+func NewBigQueryWriter(w io.Writer) Writer {
+	return &bigQueryWriter{w: bufio.NewWriter(w)}
+}
+
+type bigQueryWriter struct {
+	w *bufio.Writer
+}
+
+func (w *bigQueryWriter) Write(r Record) error {
+	row := make(map[string]interface{}, len(columns))
+	for _, c := range columns {
+		v := c.value(&r)
+		if t, ok := v.(time.Time); ok {
+			v = t.Format(time.RFC3339Nano)
+		}
+		row[c.name] = v
+	}
+	data, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	if _, err := w.w.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (w *bigQueryWriter) Flush() error {
+	return w.w.Flush()
+}
+
+// bigQueryField is a field of a BigQuery table schema, in the JSON format of
+// the bq tool.
+type bigQueryField struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Mode        string `json:"mode"`
+	Description string `json:"description"`
+}
+
+// WriteBigQuerySchema writes the JSON schema of the BigQuery inventory table.
+func WriteBigQuerySchema(w io.Writer) error {
+	fields := make([]bigQueryField, len(columns))
+	for i, c := range columns {
+		mode := "NULLABLE"
+		if c.name == "verified_at" {
+			mode = "REQUIRED"
+		}
+		fields[i] = bigQueryField{Name: c.name, Type: c.bqType, Mode: mode, Description: c.description}
+	}
+	data, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
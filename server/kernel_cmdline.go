@@ -0,0 +1,128 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+)
+
+// splitKernelCmdline splits a kernel command line into its parameters, like
+// the kernel does: parameters are separated by spaces, and double quotes
+// allow spaces in a parameter and are removed.
+func splitKernelCmdline(cmdline string) []string {
+	cmdline = strings.TrimRight(cmdline, "\x00")
+	var params []string
+	var param strings.Builder
+	inQuote, inParam := false, false
+	for _, r := range cmdline {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			inParam = true
+		case (r == ' ' || r == '\t' || r == '\n') && !inQuote:
+			if inParam {
+				params = append(params, param.String())
+				param.Reset()
+				inParam = false
+			}
+		default:
+			param.WriteRune(r)
+			inParam = true
+		}
+	}
+	if inParam {
+		params = append(params, param.String())
+	}
+	return params
+}
+
+// parseKernelCmdline fills the parameters of the LinuxKernelState parsed from
+// its command line. As for the kernel, the last of repeated parameters wins,
+// except for ima_policy= which can be repeated. Malformed parameters are
+// recorded in the ParseError of the state, as they do not invalidate the event
+// log.
+func parseKernelCmdline(state *pb.LinuxKernelState) {
+	var dmVerityErrorBehavior string
+	for _, param := range splitKernelCmdline(state.GetCommandLine()) {
+		key, value, _ := strings.Cut(param, "=")
+		switch key {
+		case "init":
+			state.Init = value
+		case "ima_policy":
+			state.ImaPolicies = append(state.ImaPolicies, value)
+		case "lockdown":
+			switch value {
+			case "integrity":
+				state.Lockdown = pb.KernelLockdown_LOCKDOWN_INTEGRITY
+			case "confidentiality":
+				state.Lockdown = pb.KernelLockdown_LOCKDOWN_CONFIDENTIALITY
+			default:
+				// The kernel ignores unknown lockdown modes.
+				state.Lockdown = pb.KernelLockdown_LOCKDOWN_NONE
+			}
+		case "security":
+			state.Security = value
+		case "module.sig_enforce":
+			enforce, err := strconv.ParseBool(value)
+			state.ModuleSigEnforce = err == nil && enforce
+		case "dm":
+			dmVerity, err := parseDmVerity(value)
+			if err != nil {
+				state.ParseError = err.Error()
+			}
+			state.DmVerity = dmVerity
+		case "dm_verity.error_behavior":
+			dmVerityErrorBehavior = value
+		}
+	}
+	if state.DmVerity != nil {
+		state.DmVerity.ErrorBehavior = dmVerityErrorBehavior
+	}
+}
+
+// parseDmVerity parses the verity target of a ChromeOS style dm= parameter,
+// e.g. "1 vroot none ro 1,0 4077568 verity payload=... hashtree=...
+// hashstart=... alg=sha256 root_hexdigest=... salt=...". It returns nil if
+// the parameter has no verity target.
+func parseDmVerity(dm string) (*pb.DmVerityState, error) {
+	fields := strings.Fields(dm)
+	target := -1
+	for i, field := range fields {
+		if field == "verity" {
+			target = i
+			break
+		}
+	}
+	if target == -1 {
+		return nil, nil
+	}
+	state := &pb.DmVerityState{}
+	for _, field := range fields[target+1:] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			// The next target of the device.
+			break
+		}
+		switch key {
+		case "payload":
+			state.Payload = value
+		case "hashtree":
+			state.Hashtree = value
+		case "hashstart":
+			hashstart, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid dm-verity hashstart %q: %v", value, err)
+			}
+			state.Hashstart = hashstart
+		case "alg":
+			state.Alg = value
+		case "root_hexdigest":
+			state.RootHexdigest = value
+		case "salt":
+			state.Salt = value
+		}
+	}
+	return state, nil
+}
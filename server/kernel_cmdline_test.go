@@ -0,0 +1,83 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/internal/test"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestSplitKernelCmdline(t *testing.T) {
+	got := splitKernelCmdline("/vmlinuz  ro \"dm=1 vroot none\" quiet=\"a b\"\x00")
+	want := []string{"/vmlinuz", "ro", "dm=1 vroot none", "quiet=a b"}
+	if !cmp.Equal(got, want) {
+		t.Errorf("splitKernelCmdline() = %q, want %q", got, want)
+	}
+}
+
+func TestParseKernelCmdline(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmdline string
+		want    *pb.LinuxKernelState
+	}{
+		{
+			name:    "COS101",
+			cmdline: test.Cos101AmdSevCmdline,
+			want: &pb.LinuxKernelState{
+				Init:             "/usr/lib/systemd/systemd",
+				Security:         "apparmor",
+				ModuleSigEnforce: true,
+				DmVerity: &pb.DmVerityState{
+					Alg:           "sha256",
+					RootHexdigest: "48d436350a7e83bde985cd3f7e79fa443557743b42243803ce31104ca4719c5d",
+					Salt:          "b323b014b6f463172fca758a1c5a6745a2c8e5872be0e175e2f4b40c8295b2ab",
+					Payload:       "PARTUUID=1D70214B-9AB3-E542-8372-3CCD786534FA",
+					Hashtree:      "PARTUUID=1D70214B-9AB3-E542-8372-3CCD786534FA",
+					Hashstart:     4077568,
+					ErrorBehavior: "3",
+				},
+			},
+		},
+		{
+			name:    "LockdownAndIMA",
+			cmdline: "/vmlinuz init=/bin/sh init=/sbin/init lockdown=confidentiality ima_policy=tcb ima_policy=appraise_tcb module.sig_enforce=0",
+			want: &pb.LinuxKernelState{
+				Init:        "/sbin/init",
+				ImaPolicies: []string{"tcb", "appraise_tcb"},
+				Lockdown:    pb.KernelLockdown_LOCKDOWN_CONFIDENTIALITY,
+			},
+		},
+		{
+			name:    "UnknownLockdown",
+			cmdline: "/vmlinuz lockdown=integrity lockdown=unknown dm_verity.error_behavior=3 \"dm=1 vroot none ro 1,0 100 linear\"",
+			want:    &pb.LinuxKernelState{},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := &pb.LinuxKernelState{CommandLine: tc.cmdline}
+			parseKernelCmdline(got)
+			tc.want.CommandLine = tc.cmdline
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("parseKernelCmdline() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseKernelCmdlineInvalidDmVerity(t *testing.T) {
+	state := &pb.LinuxKernelState{CommandLine: "/vmlinuz \"dm=1 vroot none ro 1,0 100 verity hashstart=abc\""}
+	parseKernelCmdline(state)
+	if state.GetParseError() == "" {
+		t.Error("parseKernelCmdline() did not record the invalid dm-verity hashstart")
+	}
+	if state.GetDmVerity() != nil {
+		t.Errorf("parseKernelCmdline() set the invalid dm-verity target %v", state.GetDmVerity())
+	}
+	if err := evaluateKernelPolicy(state, &pb.KernelPolicy{AllowedDmVerityRootHexdigests: []string{"digest"}}); err == nil {
+		t.Error("evaluateKernelPolicy() allowed an invalid dm-verity target")
+	}
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"fmt"
@@ -15,7 +16,9 @@ import (
 var defaultNameAlg = client.DefaultEKTemplateRSA().NameAlg
 
 // CreateEKPublicAreaFromKey creates a public area from a go interface PublicKey.
-// Supports RSA and ECC keys.
+// Supports RSA and ECC keys. The template is chosen by the key size and curve:
+// client.EKTemplateRSA3072 for RSA-3072 keys, client.EKTemplateECCP384 for
+// P-384 keys, and the default EK templates with the curve of the key otherwise.
 func CreateEKPublicAreaFromKey(k crypto.PublicKey) (tpm2.Public, error) {
 	switch key := k.(type) {
 	case *rsa.PublicKey:
@@ -28,8 +31,13 @@ func CreateEKPublicAreaFromKey(k crypto.PublicKey) (tpm2.Public, error) {
 }
 
 func createEKPublicRSA(rsaKey *rsa.PublicKey) (tpm2.Public, error) {
-	public := client.DefaultEKTemplateRSA()
-	if rsaKey.N.BitLen() != int(public.RSAParameters.KeyBits) {
+	var public tpm2.Public
+	switch rsaKey.N.BitLen() {
+	case 2048:
+		public = client.DefaultEKTemplateRSA()
+	case 3072:
+		public = client.EKTemplateRSA3072()
+	default:
 		return tpm2.Public{}, fmt.Errorf("unexpected RSA modulus size: %d bits", rsaKey.N.BitLen())
 	}
 	if rsaKey.E != int(public.RSAParameters.Exponent()) {
@@ -40,7 +48,11 @@ func createEKPublicRSA(rsaKey *rsa.PublicKey) (tpm2.Public, error) {
 }
 
 func createEKPublicECC(eccKey *ecdsa.PublicKey) (public tpm2.Public, err error) {
-	public = client.DefaultEKTemplateECC()
+	if eccKey.Curve == elliptic.P384() {
+		public = client.EKTemplateECCP384()
+	} else {
+		public = client.DefaultEKTemplateECC()
+	}
 	public.ECCParameters.Point = tpm2.ECPoint{
 		XRaw: eccIntToBytes(eccKey.Curve, eccKey.X),
 		YRaw: eccIntToBytes(eccKey.Curve, eccKey.Y),
@@ -43,7 +43,11 @@ func TestCreateEKPublicAreaFromKeyGeneratedKey(t *testing.T) {
 			priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 			return priv.Public(), err
 		}},
-		{"ECC-P384", getECCTemplate(tpm2.CurveNISTP384), func() (crypto.PublicKey, error) {
+		{"RSA-3072", client.EKTemplateRSA3072(), func() (crypto.PublicKey, error) {
+			priv, err := rsa.GenerateKey(rand.Reader, 3072)
+			return priv.Public(), err
+		}},
+		{"ECC-P384", client.EKTemplateECCP384(), func() (crypto.PublicKey, error) {
 			priv, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
 			return priv.Public(), err
 		}},
@@ -81,7 +85,7 @@ func TestCreateEKPublicAreaFromKeyTPMKey(t *testing.T) {
 		{"ECC", client.DefaultEKTemplateECC()},
 		{"ECC-P224", getECCTemplate(tpm2.CurveNISTP224)},
 		{"ECC-P256", getECCTemplate(tpm2.CurveNISTP256)},
-		{"ECC-P384", getECCTemplate(tpm2.CurveNISTP384)},
+		{"ECC-P384", client.EKTemplateECCP384()},
 		{"ECC-P521", getECCTemplate(tpm2.CurveNISTP521)},
 	}
 	for _, k := range keys {
@@ -0,0 +1,423 @@
+package server
+
+import (
+	"crypto"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/google/go-tpm-tools/internal"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/tpm2"
+	"google.golang.org/protobuf/proto"
+)
+
+// AKTrust is the result of VerifyAKTrust: a trusted AK, and the state of the
+// machine established by trusting it.
+type AKTrust struct {
+	// PublicKey is the public key of the AK, which signs the quotes.
+	PublicKey crypto.PublicKey
+	// MachineState has the instance info of the AK certificate, if any. It
+	// must not be nil.
+	MachineState *pb.MachineState
+	// EndorsementHierarchy is set if the AK is known to be in the endorsement
+	// hierarchy, i.e. its certificate chains to the TrustedRootCerts with
+	// GCETrust, or VerifyOpts.AKInEndorsementHierarchy is set. The TPM firmware
+	// version and the reset and restart counts of the quotes of other AKs may
+	// be obfuscated, so they are only recorded in the MachineState for these
+	// AKs.
+	EndorsementHierarchy bool
+}
+
+// VerifiedQuote is the result of VerifyQuote: a quote signed by a trusted AK
+// over the nonce.
+type VerifiedQuote struct {
+	Quote *tpmpb.Quote
+	// ClockInfo is the clock info of the quote. Its reset and restart counts
+	// are obfuscated unless the AK is in the endorsement or platform
+	// hierarchy.
+	ClockInfo *pb.TPMClockInfo
+	// FirmwareVersion is the firmware version of the TPM in the quote, which
+	// is obfuscated unless the AK is in the endorsement or platform hierarchy.
+	FirmwareVersion uint64
+}
+
+// EventLogStates is the result of ReplayEventLogs: the states parsed from the
+// event logs of an attestation, replayed against the PCRs of a VerifiedQuote.
+type EventLogStates struct {
+	// PCClient is parsed from the TCG event log.
+	PCClient *pb.MachineState
+	// Canonical is parsed from the Canonical Event Log, which may be empty.
+	Canonical *pb.MachineState
+	// Ima is parsed from the IMA log, or nil if the attestation has none.
+	Ima *pb.ImaState
+	// ConfidentialComputing classifies the technology claimed by the TCG
+	// event log, after checking it against the TEE attestation.
+	ConfidentialComputing *pb.ConfidentialComputingState
+}
+
+// Pipeline verifies attestations in stages:
+//
+//	VerifyAKTrust → VerifyQuote → ReplayEventLogs → ParseMachineState → EvaluatePolicy → Revocation
+//
+// VerifyQuote and the stages after it run for each supported quote, in order
+// of hash preference, until one succeeds. Each stage is the function of the
+// same name in this package unless replaced by a field of the Pipeline, so
+// custom verifiers can reuse the other stages. For example, a verifier which
+// establishes trust in the AK out of band can replace VerifyAKTrust by a
+// function returning that AK. VerifyAttestation uses the zero Pipeline.
+//
+// The checks of the replaced stages are recorded in the VerificationReport
+// as one check named after the stage, and their errors are returned as is.
+type Pipeline struct {
+	VerifyAKTrust     func(attestation *pb.Attestation, opts VerifyOpts) (*AKTrust, error)
+	VerifyQuote       func(quote *tpmpb.Quote, akPub crypto.PublicKey, opts VerifyOpts) (*VerifiedQuote, error)
+	ReplayEventLogs   func(attestation *pb.Attestation, quote *VerifiedQuote, opts VerifyOpts) (*EventLogStates, error)
+	ParseMachineState func(trust *AKTrust, quote *VerifiedQuote, logs *EventLogStates, opts VerifyOpts) (*pb.MachineState, error)
+	// Policy is evaluated against the MachineState by EvaluatePolicy, if set.
+	Policy         *pb.Policy
+	EvaluatePolicy func(state *pb.MachineState, policy *pb.Policy) error
+	// Revocation, if set, checks the verified attestation against revoked
+	// artifacts, e.g. a RevocationList or a RevocationFeed.
+	Revocation RevocationChecker
+}
+
+// Verify verifies the attestation with the stages of the Pipeline, and
+// returns the verified MachineState, see VerifyAttestation.
+func (p Pipeline) Verify(attestation *pb.Attestation, opts VerifyOpts) (*pb.MachineState, error) {
+	machineState, _, err := p.VerifyWithReport(attestation, opts)
+	return machineState, err
+}
+
+// VerifyWithReport is like Verify, but also returns the VerificationReport of
+// the checks, see VerifyAttestationWithReport.
+func (p Pipeline) VerifyWithReport(attestation *pb.Attestation, opts VerifyOpts) (*pb.MachineState, *pb.VerificationReport, error) {
+	r, err := newReportBuilder(attestation)
+	if err != nil {
+		return nil, nil, verificationError(CodeUnknown, "failed to digest attestation: %w", err)
+	}
+	machineState, err := p.verify(attestation, opts, r)
+	if err == nil {
+		r.report.Verified = true
+		r.report.VerifiedQuoteHash = machineState.GetHash()
+	}
+	return machineState, r.report, err
+}
+
+func (p Pipeline) verify(attestation *pb.Attestation, opts VerifyOpts, r *reportBuilder) (*pb.MachineState, error) {
+	var trust *AKTrust
+	var err error
+	if p.VerifyAKTrust == nil {
+		trust, err = verifyAKTrust(attestation, opts, r)
+	} else {
+		trust, err = p.VerifyAKTrust(attestation, opts)
+		err = r.stage("ak_trusted", nil, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, quote := range supportedQuotes(attestation.GetQuotes()) {
+		var verified *VerifiedQuote
+		if p.VerifyQuote == nil {
+			verified, err = verifyQuote(quote, trust.PublicKey, opts, r)
+		} else {
+			verified, err = p.VerifyQuote(quote, trust.PublicKey, opts)
+			err = r.stage("quote_signature", quote, err)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		var logs *EventLogStates
+		if p.ReplayEventLogs == nil {
+			logs, err = replayEventLogs(attestation, verified, opts, r)
+		} else {
+			logs, err = p.ReplayEventLogs(attestation, verified, opts)
+			err = r.stage("event_log_replay", quote, err)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		var machineState *pb.MachineState
+		if p.ParseMachineState == nil {
+			machineState, err = parseMachineState(trust, verified, logs, opts, r)
+		} else {
+			machineState, err = p.ParseMachineState(trust, verified, logs, opts)
+			err = r.stage("machine_state", quote, err)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		// The MachineState is verified, so the policy is not evaluated
+		// against the other quotes.
+		if p.Policy != nil {
+			evaluate := p.EvaluatePolicy
+			if evaluate == nil {
+				evaluate = EvaluatePolicy
+			}
+			if err := evaluate(machineState, p.Policy); err != nil {
+				return nil, r.failed("policy", quote, nil, verificationError(CodePolicyViolation, "failed to evaluate the policy: %w", err))
+			}
+			r.passed("policy", quote, nil)
+		}
+		if p.Revocation != nil {
+			if err := p.Revocation.CheckRevocation(attestation, machineState); err != nil {
+				return nil, r.failed("revocation", quote, nil, verificationError(CodeRevoked, "failed the revocation check: %w", err))
+			}
+			r.passed("revocation", quote, nil)
+		}
+		return machineState, nil
+	}
+
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, r.failed("supported_quote", nil, nil, verificationError(CodeNoSupportedQuote, "attestation does not contain a supported quote"))
+}
+
+// VerifyAKTrust checks that the AK of the attestation is trusted: its public
+// area or certificate must be one of opts.TrustedAKs, or its certificate must
+// chain to opts.TrustedRootCerts. It also checks that the Shielded VM
+// identity of the attestation, if any, is for the AK.
+func VerifyAKTrust(attestation *pb.Attestation, opts VerifyOpts) (*AKTrust, error) {
+	return verifyAKTrust(attestation, opts, nil)
+}
+
+func verifyAKTrust(attestation *pb.Attestation, opts VerifyOpts, r *reportBuilder) (*AKTrust, error) {
+	if err := validateOpts(opts); err != nil {
+		return nil, r.failed("options", nil, nil, verificationError(CodeBadOptions, "bad options: %w", err))
+	}
+	r.passed("options", nil, nil)
+
+	var akPubKey crypto.PublicKey
+	var machineState *pb.MachineState
+	endorsementHierarchy := opts.AKInEndorsementHierarchy
+	if len(attestation.GetAkCert()) == 0 {
+		// If the AK Cert is not in the attestation, use the AK Public Area.
+		akInputs := map[string][]byte{"ak_pub": attestation.GetAkPub()}
+		akPubArea, err := tpm2.DecodePublic(attestation.GetAkPub())
+		if err != nil {
+			return nil, r.failed("ak_parse", nil, akInputs, verificationError(CodeInvalidAK, "failed to decode AK public area: %w", err))
+		}
+		akPubKey, err = akPubArea.Key()
+		if err != nil {
+			return nil, r.failed("ak_parse", nil, akInputs, verificationError(CodeInvalidAK, "failed to get AK public key: %w", err))
+		}
+		r.passed("ak_parse", nil, akInputs)
+		machineState, err = validateAKPub(akPubKey, opts)
+		if err != nil {
+			return nil, r.failed("ak_trusted", nil, akInputs, verificationError(CodeUntrustedAK, "failed to validate AK public key: %w", err))
+		}
+		r.passed("ak_trusted", nil, akInputs)
+	} else {
+		// If AK Cert is presented, ignore the AK Public Area.
+		akInputs := map[string][]byte{"ak_cert": attestation.GetAkCert()}
+		for i, cert := range attestation.GetIntermediateCerts() {
+			akInputs[fmt.Sprintf("intermediate_cert_%d", i)] = cert
+		}
+		akCert, err := x509.ParseCertificate(attestation.GetAkCert())
+		if err != nil {
+			return nil, r.failed("ak_parse", nil, akInputs, verificationError(CodeInvalidAK, "failed to parse AK certificate: %w", err))
+		}
+		// Use intermediate certs from the attestation if they exist.
+		certs, err := opts.certs.parseIntermediates(attestation.IntermediateCerts)
+		if err != nil {
+			return nil, r.failed("ak_parse", nil, akInputs, verificationError(CodeInvalidAK, "attestation intermediates: %w", err))
+		}
+		r.passed("ak_parse", nil, akInputs)
+		opts.IntermediateCerts = append(opts.IntermediateCerts, certs...)
+
+		machineState, err = validateAKCert(akCert, opts)
+		if err != nil {
+			return nil, r.failed("ak_trusted", nil, akInputs, verificationError(CodeUntrustedAK, "failed to validate AK certificate: %w", err))
+		}
+		r.passed("ak_trusted", nil, akInputs)
+		akPubKey = akCert.PublicKey.(crypto.PublicKey)
+		// Only the GCE AKs, in the endorsement hierarchy, are certified by
+		// the GCE roots. Without roots, the certificate is not verified.
+		if opts.TrustMode == GCETrust && len(opts.TrustedRootCerts) > 0 {
+			endorsementHierarchy = true
+		}
+	}
+
+	// The GCE identity is not covered by the quotes, but it must not
+	// contradict them. It is only meaningful on GCE.
+	if signingKeyPub := attestation.GetGceIdentity().GetShieldedVmIdentity().GetSigningKeyPub(); signingKeyPub != "" && opts.TrustMode == GCETrust {
+		identityInputs := map[string][]byte{"signing_key_pub": []byte(signingKeyPub)}
+		if err := checkShieldedVMSigningKey(signingKeyPub, akPubKey); err != nil {
+			return nil, r.failed("shielded_vm_identity", nil, identityInputs, verificationError(CodeInvalidAK, "failed to check the Shielded VM identity: %w", err))
+		}
+		r.passed("shielded_vm_identity", nil, identityInputs)
+	}
+	if token := attestation.GetGceIdentity().GetInstanceIdentityToken(); token != "" && opts.TrustMode == GCETrust && opts.GCEIdentityCerts != nil {
+		identityInputs := map[string][]byte{"instance_identity_token": []byte(token)}
+		verifyTime := opts.VerifyTime
+		if verifyTime.IsZero() {
+			verifyTime = time.Now()
+		}
+		if err := checkInstanceIdentityToken(token, opts.GCEIdentityCerts, opts.Nonce, verifyTime, machineState.GetPlatform().GetInstanceInfo()); err != nil {
+			return nil, r.failed("instance_identity_token", nil, identityInputs, verificationError(CodeInvalidAK, "failed to check the instance identity token: %w", err))
+		}
+		r.passed("instance_identity_token", nil, identityInputs)
+	}
+	return &AKTrust{
+		PublicKey:            akPubKey,
+		MachineState:         machineState,
+		EndorsementHierarchy: endorsementHierarchy,
+	}, nil
+}
+
+// VerifyQuote checks that the quote is signed by akPub, over opts.Nonce and
+// its PCRs. The caller must have already established trust in akPub, e.g.
+// with VerifyAKTrust.
+func VerifyQuote(quote *tpmpb.Quote, akPub crypto.PublicKey, opts VerifyOpts) (*VerifiedQuote, error) {
+	return verifyQuote(quote, akPub, opts, nil)
+}
+
+func verifyQuote(quote *tpmpb.Quote, akPub crypto.PublicKey, opts VerifyOpts, r *reportBuilder) (*VerifiedQuote, error) {
+	quoteInputs := map[string][]byte{"quote": quote.GetQuote(), "signature": quote.GetRawSig(), "nonce": opts.Nonce}
+	if err := internal.VerifyQuoteNonce(quote, akPub, opts.Nonce); err != nil {
+		return nil, r.failed("quote_signature", quote, quoteInputs, verificationError(quoteErrorCode(err), "failed to verify quote: %w", err))
+	}
+	r.passed("quote_signature", quote, quoteInputs)
+
+	attestationData, err := tpm2.DecodeAttestationData(quote.GetQuote())
+	if err != nil {
+		return nil, r.failed("clock_info", quote, nil, verificationError(CodeInvalidQuote, "failed to get the clock info: %w", err))
+	}
+	return &VerifiedQuote{
+		Quote:           quote,
+		ClockInfo:       getClockInfo(attestationData),
+		FirmwareVersion: attestationData.FirmwareVersion,
+	}, nil
+}
+
+// ReplayEventLogs parses the TCG event log, the Canonical Event Log and the
+// IMA log of the attestation, replaying them against the PCRs of the quote.
+// The confidential computing technology claimed by the TCG event log is
+// checked against the TEE attestation.
+func ReplayEventLogs(attestation *pb.Attestation, quote *VerifiedQuote, opts VerifyOpts) (*EventLogStates, error) {
+	return replayEventLogs(attestation, quote, opts, nil)
+}
+
+func replayEventLogs(attestation *pb.Attestation, verified *VerifiedQuote, opts VerifyOpts, r *reportBuilder) (*EventLogStates, error) {
+	quote := verified.Quote
+	pcrs := quote.GetPcrs()
+	var state *pb.MachineState
+	var confidentialState *pb.ConfidentialComputingState
+	if attestation.GetEventLogOmitted() {
+		// Without the TCG event log, the technology measured by the firmware
+		// is unknown, so the TEE attestation is not checked against it.
+		if len(attestation.GetEventLog()) != 0 {
+			return nil, r.failed("event_log_omitted", quote, nil, verificationError(CodeInvalidEventLog, "attestation has a TCG event log, but is marked as omitting it"))
+		}
+		if !opts.AllowOmittedEventLog {
+			return nil, r.failed("event_log_omitted", quote, nil, verificationError(CodeEventLogOmitted, "attestation omitted the TCG event log (set VerifyOpts.AllowOmittedEventLog to true to allow)"))
+		}
+		r.passed("event_log_omitted", quote, nil)
+		state = &pb.MachineState{EventLogOmitted: true, Hash: pcrs.GetHash()}
+	} else {
+		eventLogInputs := map[string][]byte{"event_log": attestation.GetEventLog()}
+		var err error
+		state, err = parsePCClientEventLog(attestation.GetEventLog(), pcrs, opts.Loader, opts.TrustMode)
+		if err != nil {
+			return nil, r.failed("event_log_replay", quote, eventLogInputs, eventLogError(err))
+		}
+		r.passed("event_log_replay", quote, eventLogInputs)
+
+		// Outside of GCE, the technology measured by the firmware is not
+		// a GCE claim, so there is no TEE attestation to check it against.
+		if opts.TrustMode == GCETrust {
+			if err := VerifyGceTechnology(attestation, state.Platform.GetTechnology(), &opts); err != nil {
+				return nil, r.failed("tee_technology", quote, nil, verificationError(CodeTEEAttestation, "failed to verify memory encryption technology: %w", err))
+			}
+			// The instance info of the AK certificate is only parsed by
+			// VerifyAKTrust, so it is not known here.
+			confidentialState, err = classifyTechnology(attestation, state.Platform.GetTechnology(), nil)
+			if err != nil {
+				return nil, r.failed("tee_technology", quote, nil, verificationError(CodeTEEAttestation, "failed to classify memory encryption technology: %w", err))
+			}
+			r.passed("tee_technology", quote, nil)
+		}
+	}
+
+	celInputs := map[string][]byte{"canonical_event_log": attestation.GetCanonicalEventLog()}
+	celState, err := parseCanonicalEventLog(attestation.GetCanonicalEventLog(), pcrs, opts.AllowCELTimestamps)
+	if err != nil {
+		return nil, r.failed("canonical_event_log_replay", quote, celInputs, verificationError(CodeInvalidCanonicalEventLog, "failed to validate the Canonical event log: %w", err))
+	}
+	r.passed("canonical_event_log_replay", quote, celInputs)
+
+	if err := checkEventTimestamps(celState.GetCos(), verified.ClockInfo); err != nil {
+		return nil, r.failed("canonical_event_log_timestamps", quote, nil, verificationError(CodeInvalidCanonicalEventLog, "failed to validate the Canonical event log timestamps: %w", err))
+	}
+	r.passed("canonical_event_log_timestamps", quote, nil)
+
+	var imaState *pb.ImaState
+	if len(attestation.GetImaLog()) > 0 {
+		imaInputs := map[string][]byte{"ima_log": attestation.GetImaLog()}
+		if imaState, err = parseIMALog(attestation.GetImaLog(), pcrs); err != nil {
+			return nil, r.failed("ima_log_replay", quote, imaInputs, verificationError(CodeInvalidIMALog, "failed to validate the IMA log: %w", err))
+		}
+		if err := checkKernelModules(celState.GetCos().GetKernelModules(), imaState); err != nil {
+			return nil, r.failed("ima_log_replay", quote, imaInputs, verificationError(CodeInvalidIMALog, "failed to validate the kernel modules against the IMA log: %w", err))
+		}
+		r.passed("ima_log_replay", quote, imaInputs)
+	}
+	return &EventLogStates{
+		PCClient:              state,
+		Canonical:             celState,
+		Ima:                   imaState,
+		ConfidentialComputing: confidentialState,
+	}, nil
+}
+
+// ParseMachineState combines the results of the previous stages into the
+// verified MachineState. It fails for SHA-1 quotes unless opts.AllowSHA1 is
+// set.
+func ParseMachineState(trust *AKTrust, quote *VerifiedQuote, logs *EventLogStates, opts VerifyOpts) (*pb.MachineState, error) {
+	return parseMachineState(trust, quote, logs, opts, nil)
+}
+
+func parseMachineState(trust *AKTrust, verified *VerifiedQuote, logs *EventLogStates, opts VerifyOpts, r *reportBuilder) (*pb.MachineState, error) {
+	// Verify the PCR hash algorithm. We have this check here (instead of
+	// before replaying the logs) so that the user gets a "SHA-1 not
+	// supported" error only if allowing SHA-1 support would actually allow
+	// the log to be verified. This makes debugging failed verifications
+	// easier.
+	if !opts.AllowSHA1 && tpm2.Algorithm(verified.Quote.GetPcrs().GetHash()) == tpm2.AlgSHA1 {
+		return nil, r.failed("sha1_allowed", verified.Quote, nil, verificationError(CodeSHA1NotAllowed, "SHA-1 is not allowed for verification (set VerifyOpts.AllowSHA1 to true to allow)"))
+	}
+
+	machineState := proto.Clone(trust.MachineState).(*pb.MachineState)
+	proto.Merge(machineState, logs.Canonical)
+	proto.Merge(machineState, logs.PCClient)
+	if trust.EndorsementHierarchy {
+		machineState.ClockInfo = verified.ClockInfo
+		if machineState.Platform == nil {
+			machineState.Platform = &pb.PlatformState{}
+		}
+		machineState.Platform.TpmFirmwareVersion = verified.FirmwareVersion
+	} else if verified.ClockInfo != nil {
+		machineState.ClockInfo = &pb.TPMClockInfo{
+			Clock: verified.ClockInfo.GetClock(),
+			Safe:  verified.ClockInfo.GetSafe(),
+		}
+	}
+	machineState.Ima = logs.Ima
+	if logs.ConfidentialComputing != nil {
+		confidentialState := proto.Clone(logs.ConfidentialComputing).(*pb.ConfidentialComputingState)
+		// Only a production GCE AK certificate has instance info.
+		confidentialState.GceCertifiedAk = trust.MachineState.GetPlatform().GetInstanceInfo() != nil
+		machineState.ConfidentialComputing = confidentialState
+	}
+	return machineState, nil
+}
@@ -0,0 +1,262 @@
+package server
+
+import (
+	"crypto"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm/tpm2"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestPipelineStages(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ak, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+	nonce := []byte("super secret nonce")
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+	opts := VerifyOpts{Nonce: nonce, TrustedAKs: []crypto.PublicKey{ak.PublicKey()}}
+
+	want, err := VerifyAttestation(attestation, opts)
+	if err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+
+	// Calling the stages by hand gives the same MachineState.
+	trust, err := VerifyAKTrust(attestation, opts)
+	if err != nil {
+		t.Fatalf("VerifyAKTrust() failed: %v", err)
+	}
+	var got *pb.MachineState
+	for _, quote := range attestation.GetQuotes() {
+		if quote.GetPcrs().GetHash() != want.GetHash() {
+			continue
+		}
+		verified, err := VerifyQuote(quote, trust.PublicKey, opts)
+		if err != nil {
+			t.Fatalf("VerifyQuote() failed: %v", err)
+		}
+		logs, err := ReplayEventLogs(attestation, verified, opts)
+		if err != nil {
+			t.Fatalf("ReplayEventLogs() failed: %v", err)
+		}
+		if got, err = ParseMachineState(trust, verified, logs, opts); err != nil {
+			t.Fatalf("ParseMachineState() failed: %v", err)
+		}
+	}
+	if diff := cmp.Diff(got, want, protocmp.Transform()); diff != "" {
+		t.Errorf("stages returned a different MachineState:\n%v", diff)
+	}
+
+	// The AK can be trusted out of band, without any trust mechanism in opts.
+	outOfBand := Pipeline{VerifyAKTrust: func(*pb.Attestation, VerifyOpts) (*AKTrust, error) {
+		return &AKTrust{PublicKey: ak.PublicKey(), MachineState: &pb.MachineState{}}, nil
+	}}
+	got, report, err := outOfBand.VerifyWithReport(attestation, VerifyOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatalf("failed to verify with an out of band AK: %v", err)
+	}
+	if diff := cmp.Diff(got, want, protocmp.Transform()); diff != "" {
+		t.Errorf("out of band AK gave a different MachineState:\n%v", diff)
+	}
+	if first := report.GetChecks()[0]; first.GetName() != "ak_trusted" || !first.GetPassed() {
+		t.Errorf("first check is %v, want the passed ak_trusted check of the replaced stage", first)
+	}
+
+	errUntrusted := errors.New("untrusted")
+	untrusted := Pipeline{VerifyAKTrust: func(*pb.Attestation, VerifyOpts) (*AKTrust, error) {
+		return nil, errUntrusted
+	}}
+	if _, err := untrusted.Verify(attestation, opts); !errors.Is(err, errUntrusted) {
+		t.Errorf("Verify() = %v, want the error of the replaced stage", err)
+	}
+}
+
+func TestPipelinePolicy(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ak, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+	nonce := []byte("super secret nonce")
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+	opts := VerifyOpts{Nonce: nonce, TrustedAKs: []crypto.PublicKey{ak.PublicKey()}}
+
+	policy := &pb.Policy{Platform: &pb.PlatformPolicy{MinimumTechnology: pb.GCEConfidentialTechnology_AMD_SEV}}
+	_, err = Pipeline{Policy: policy}.Verify(attestation, opts)
+	var verificationErr *VerificationError
+	if !errors.As(err, &verificationErr) || verificationErr.Code != CodePolicyViolation {
+		t.Errorf("Verify() = %v, want a %v error", err, CodePolicyViolation)
+	}
+
+	evaluated := false
+	lenient := Pipeline{Policy: policy, EvaluatePolicy: func(*pb.MachineState, *pb.Policy) error {
+		evaluated = true
+		return nil
+	}}
+	if _, err := lenient.Verify(attestation, opts); err != nil {
+		t.Errorf("Verify() with a replaced EvaluatePolicy failed: %v", err)
+	}
+	if !evaluated {
+		t.Error("replaced EvaluatePolicy was not called")
+	}
+}
+
+func TestPipelineTPMFirmwareVersion(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	info, err := client.GetTPMInfo(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ak, err := client.NewKey(rwc, tpm2.HandleEndorsement, client.AKTemplateRSA())
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+	nonce := []byte("super secret nonce")
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+	opts := VerifyOpts{Nonce: nonce, TrustedAKs: []crypto.PublicKey{ak.PublicKey()}}
+
+	// A trusted AK is not known to be in the endorsement hierarchy, so the
+	// firmware version of its quotes is not trusted to be unobfuscated.
+	state, err := VerifyAttestation(attestation, opts)
+	if err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+	if got := state.GetPlatform().GetTpmFirmwareVersion(); got != 0 {
+		t.Errorf("got TPM firmware version %#x for a trusted AK, want none", got)
+	}
+
+	opts.AKInEndorsementHierarchy = true
+	if state, err = VerifyAttestation(attestation, opts); err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+	if got := state.GetPlatform().GetTpmFirmwareVersion(); got != info.FirmwareVersion {
+		t.Errorf("got TPM firmware version %#x, want %#x", got, info.FirmwareVersion)
+	}
+	policy := &pb.Policy{Platform: &pb.PlatformPolicy{MinimumTpmFirmwareVersion: info.FirmwareVersion + 1}}
+	if _, err := (Pipeline{Policy: policy}).Verify(attestation, opts); !errors.Is(err, &VerificationError{Code: CodePolicyViolation}) {
+		t.Errorf("Verify() = %v, want a %v error for an older TPM firmware", err, CodePolicyViolation)
+	}
+}
+
+func TestPipelineTPMFirmwareVersionOwnerAK(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ak, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+	nonce := []byte("super secret nonce")
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+
+	// The firmware version of the quotes of an owner hierarchy AK is
+	// obfuscated, even with GCETrust.
+	state, err := VerifyAttestation(attestation, VerifyOpts{Nonce: nonce, TrustedAKs: []crypto.PublicKey{ak.PublicKey()}, TrustMode: GCETrust})
+	if err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+	if got := state.GetPlatform().GetTpmFirmwareVersion(); got != 0 {
+		t.Errorf("got TPM firmware version %#x for an owner hierarchy AK, want none", got)
+	}
+}
+
+func TestPipelineClockInfoCounts(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ak, err := client.NewKey(rwc, tpm2.HandleEndorsement, client.AKTemplateRSA())
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+	nonce := []byte("super secret nonce")
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+	// All the quotes of the attestation have the same counts.
+	attestationData, err := tpm2.DecodeAttestationData(attestation.GetQuotes()[0].GetQuote())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetCount := attestationData.ClockInfo.ResetCount
+	if resetCount == 0 {
+		t.Skip("the TPM was never reset, so a reset count of 0 cannot be told apart from an omitted one")
+	}
+	opts := VerifyOpts{Nonce: nonce, TrustedAKs: []crypto.PublicKey{ak.PublicKey()}, AKInEndorsementHierarchy: true}
+
+	state, err := VerifyAttestation(attestation, opts)
+	if err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+	if got := state.GetClockInfo().GetResetCount(); got != resetCount {
+		t.Errorf("got reset count %d for an endorsement hierarchy AK, want %d", got, resetCount)
+	}
+
+	// Outside of GCE, the counts of the quotes may be obfuscated.
+	opts.TrustMode = VirtualTPMTrust
+	opts.AKInEndorsementHierarchy = false
+	if state, err = VerifyAttestation(attestation, opts); err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+	clockInfo := state.GetClockInfo()
+	if clockInfo.GetResetCount() != 0 || clockInfo.GetRestartCount() != 0 {
+		t.Errorf("got reset count %d and restart count %d for a virtual TPM AK, want none", clockInfo.GetResetCount(), clockInfo.GetRestartCount())
+	}
+	if clockInfo.GetClock() == 0 {
+		t.Error("the clock of a virtual TPM AK was not recorded")
+	}
+}
+
+func TestPipelineClockInfoCountsOwnerAK(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ak, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+	nonce := []byte("super secret nonce")
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+
+	// The counts of the quotes of an owner hierarchy AK are obfuscated, even
+	// with GCETrust.
+	state, err := VerifyAttestation(attestation, VerifyOpts{Nonce: nonce, TrustedAKs: []crypto.PublicKey{ak.PublicKey()}, TrustMode: GCETrust})
+	if err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+	clockInfo := state.GetClockInfo()
+	if clockInfo.GetResetCount() != 0 || clockInfo.GetRestartCount() != 0 {
+		t.Errorf("got reset count %d and restart count %d for an owner hierarchy AK, want none", clockInfo.GetResetCount(), clockInfo.GetRestartCount())
+	}
+	if clockInfo.GetClock() == 0 {
+		t.Error("the clock of an owner hierarchy AK was not recorded")
+	}
+}
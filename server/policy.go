@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"path"
+	"strings"
 
 	pb "github.com/google/go-tpm-tools/proto/attest"
 )
@@ -16,6 +18,18 @@ func EvaluatePolicy(state *pb.MachineState, policy *pb.Policy) error {
 	if err := evaluatePlatformPolicy(state.GetPlatform(), policy.GetPlatform()); err != nil {
 		return err
 	}
+	if err := evaluateClockPolicy(state.GetClockInfo(), policy.GetClock()); err != nil {
+		return err
+	}
+	if err := evaluateKernelPolicy(state.GetLinuxKernel(), policy.GetKernel()); err != nil {
+		return err
+	}
+	if err := evaluateIMAPolicy(state.GetIma(), policy.GetIma()); err != nil {
+		return err
+	}
+	if err := evaluateCosPolicy(state.GetCos(), policy.GetCos()); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -32,14 +46,266 @@ func evaluatePlatformPolicy(state *pb.PlatformState, policy *pb.PlatformPolicy)
 	if minGceVersion > gceVersion {
 		return fmt.Errorf("expected GCE Version %d or later, got %d", minGceVersion, gceVersion)
 	}
+	minTPMVersion := policy.GetMinimumTpmFirmwareVersion()
+	tpmVersion := state.GetTpmFirmwareVersion()
+	if minTPMVersion > tpmVersion {
+		return fmt.Errorf("expected TPM firmware version %#x or later, got %#x", minTPMVersion, tpmVersion)
+	}
 	minTech := policy.GetMinimumTechnology()
 	tech := state.GetTechnology()
-	if minTech > tech {
+	cmp, err := compareTechnology(tech, minTech)
+	if err != nil {
+		return err
+	}
+	if cmp < 0 {
 		return fmt.Errorf("expected a GCE Confidential Technology of %d or later, got %d", minTech, tech)
 	}
+	if instancePolicy := policy.GetInstance(); instancePolicy != nil {
+		return evaluateInstancePolicy(state.GetInstanceInfo(), instancePolicy)
+	}
+	return nil
+}
+
+func evaluateInstancePolicy(info *pb.GCEInstanceInfo, policy *pb.GCEInstancePolicy) error {
+	if info == nil {
+		return errors.New("missing GCE instance info in MachineState: the AK certificate has none")
+	}
+	if allowed := policy.GetAllowedProjectIds(); len(allowed) > 0 && !containsString(allowed, info.GetProjectId()) {
+		return fmt.Errorf("GCE project %q not allowed", info.GetProjectId())
+	}
+	if allowed := policy.GetAllowedProjectNumbers(); len(allowed) > 0 && !containsUint64(allowed, info.GetProjectNumber()) {
+		return fmt.Errorf("GCE project number %d not allowed", info.GetProjectNumber())
+	}
+	if allowed := policy.GetAllowedZones(); len(allowed) > 0 && !containsString(allowed, info.GetZone()) {
+		return fmt.Errorf("GCE zone %q not allowed", info.GetZone())
+	}
+	if allowed := policy.GetAllowedInstanceIds(); len(allowed) > 0 && !containsUint64(allowed, info.GetInstanceId()) {
+		return fmt.Errorf("GCE instance ID %d not allowed", info.GetInstanceId())
+	}
+	if allowed := policy.GetAllowedInstanceNames(); len(allowed) > 0 && !containsString(allowed, info.GetInstanceName()) {
+		return fmt.Errorf("GCE instance name %q not allowed", info.GetInstanceName())
+	}
+	if minVersion := policy.GetMinimumSecurityVersion(); info.GetSecurityVersion() < minVersion {
+		return fmt.Errorf("expected a vTPM security version of %d or later, got %d", minVersion, info.GetSecurityVersion())
+	}
+	return nil
+}
+
+func evaluateClockPolicy(state *pb.TPMClockInfo, policy *pb.ClockPolicy) error {
+	reference := policy.GetReference()
+	if !policy.GetRequireSafe() && reference == nil {
+		return nil
+	}
+	if state == nil {
+		return errors.New("missing TPM clock info in MachineState")
+	}
+	if policy.GetRequireSafe() && !state.GetSafe() {
+		return errors.New("TPM clock is not safe, it may have been rolled back")
+	}
+	if reference == nil {
+		return nil
+	}
+	if state.GetResetCount() != reference.GetResetCount() {
+		return fmt.Errorf("TPM reset count is %d, expected %d: the TPM was reset or rolled back", state.GetResetCount(), reference.GetResetCount())
+	}
+	if state.GetRestartCount() < reference.GetRestartCount() {
+		return fmt.Errorf("TPM restart count %d is lower than %d: the TPM state was rolled back", state.GetRestartCount(), reference.GetRestartCount())
+	}
+	if state.GetClock() < reference.GetClock() {
+		return fmt.Errorf("TPM clock %d is lower than %d: the TPM state was rolled back", state.GetClock(), reference.GetClock())
+	}
+	return nil
+}
+
+func evaluateKernelPolicy(state *pb.LinuxKernelState, policy *pb.KernelPolicy) error {
+	if allowed := policy.GetAllowedInit(); len(allowed) > 0 && !containsString(allowed, state.GetInit()) {
+		return fmt.Errorf("kernel init %q not allowed", state.GetInit())
+	}
+	if minLockdown := policy.GetMinimumLockdown(); state.GetLockdown() < minLockdown {
+		return fmt.Errorf("expected kernel lockdown %v or more restrictive, got %v", minLockdown, state.GetLockdown())
+	}
+	for _, required := range policy.GetRequiredImaPolicies() {
+		if !containsString(state.GetImaPolicies(), required) {
+			return fmt.Errorf("missing required kernel IMA policy %q", required)
+		}
+	}
+	if allowed := policy.GetAllowedDmVerityRootHexdigests(); len(allowed) > 0 {
+		dmVerity := state.GetDmVerity()
+		if dmVerity == nil {
+			if parseErr := state.GetParseError(); parseErr != "" {
+				return fmt.Errorf("missing dm-verity target in kernel commandline: %s", parseErr)
+			}
+			return errors.New("missing dm-verity target in kernel commandline")
+		}
+		if !containsString(allowed, dmVerity.GetRootHexdigest()) {
+			return fmt.Errorf("dm-verity root digest %s not allowed", dmVerity.GetRootHexdigest())
+		}
+	}
+	if policy.GetRequireModuleSigEnforce() && !state.GetModuleSigEnforce() {
+		return errors.New("kernel module signature enforcement is not enabled")
+	}
 	return nil
 }
 
+func evaluateCosPolicy(state *pb.AttestedCosState, policy *pb.CosPolicy) error {
+	if minVersion := policy.GetMinimumLauncherVersion(); minVersion != nil {
+		version := state.GetLauncherVersion()
+		if version == nil {
+			return errors.New("missing launcher version")
+		}
+		if compareVersions(version, minVersion) < 0 {
+			return fmt.Errorf("expected launcher version %s or later, got %s", formatVersion(minVersion), formatVersion(version))
+		}
+	}
+	if allowed := policy.GetAllowedLauncherBinaryDigests(); len(allowed) > 0 && !containsString(allowed, state.GetLauncherBinaryDigest()) {
+		return fmt.Errorf("launcher binary digest %q not allowed", state.GetLauncherBinaryDigest())
+	}
+	if policy.GetRequireUntaintedKernel() {
+		kernelLog := state.GetKernelLog()
+		if kernelLog == nil {
+			return errors.New("missing kernel log")
+		}
+		if taint := kernelLog.GetTaint() &^ policy.GetAllowedKernelTaint(); taint != 0 {
+			return fmt.Errorf("kernel tainted with flags %#x not allowed", taint)
+		}
+	}
+	return nil
+}
+
+// compareVersions returns -1, 0 or 1 if the version a is lower than, equal to
+// or greater than b.
+func compareVersions(a, b *pb.SemanticVersion) int {
+	for _, pair := range [][2]uint32{{a.GetMajor(), b.GetMajor()}, {a.GetMinor(), b.GetMinor()}, {a.GetPatch(), b.GetPatch()}} {
+		if pair[0] < pair[1] {
+			return -1
+		}
+		if pair[0] > pair[1] {
+			return 1
+		}
+	}
+	return 0
+}
+
+func formatVersion(v *pb.SemanticVersion) string {
+	return fmt.Sprintf("%d.%d.%d", v.GetMajor(), v.GetMinor(), v.GetPatch())
+}
+
+// IMAPolicyError is returned by EvaluatePolicy if measurements of the IMA log
+// violate the ImaPolicy. Use errors.As to get the violating measurements.
+type IMAPolicyError struct {
+	Violations []*pb.ImaViolation
+}
+
+func (e *IMAPolicyError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d IMA measurements violate the policy", len(e.Violations))
+	for _, v := range e.Violations {
+		fmt.Fprintf(&sb, "\n%d: %s %s: %s", v.GetIndex(), v.GetMeasurement().GetFileName(), v.GetMeasurement().GetFileDigest(), v.GetReason())
+	}
+	return sb.String()
+}
+
+func evaluateIMAPolicy(state *pb.ImaState, policy *pb.ImaPolicy) error {
+	if len(policy.GetAllow()) == 0 && len(policy.GetDeny()) == 0 {
+		return nil
+	}
+	if state == nil {
+		return errors.New("missing IMA log in MachineState")
+	}
+	var violations []*pb.ImaViolation
+	for i, m := range state.GetMeasurements() {
+		// The template hash of the measurements is verified, so only the
+		// first one can be the genuine boot_aggregate.
+		if i == 0 && m.GetFileName() == "boot_aggregate" {
+			continue
+		}
+		reason := ""
+		if rule := matchIMARules(m, policy.GetDeny()); rule != nil {
+			reason = "denied by rule " + formatIMARule(rule)
+		} else if len(policy.GetAllow()) > 0 && matchIMARules(m, policy.GetAllow()) == nil {
+			reason = "not allowed by any rule"
+		}
+		if reason != "" {
+			violations = append(violations, &pb.ImaViolation{Index: uint32(i), Measurement: m, Reason: reason})
+		}
+	}
+	for i, m := range state.GetUnverifiedMeasurements() {
+		violations = append(violations, &pb.ImaViolation{Index: uint32(i), Measurement: m, Reason: "unverified template data", Unverified: true})
+	}
+	if len(violations) > 0 {
+		return &IMAPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// matchIMARules returns the first of the rules the measurement matches, or nil.
+func matchIMARules(m *pb.ImaMeasurement, rules []*pb.ImaRule) *pb.ImaRule {
+	for _, rule := range rules {
+		if !matchIMAPath(rule.GetPathGlob(), m.GetFileName()) {
+			continue
+		}
+		if digests := rule.GetFileDigests(); len(digests) > 0 && !containsFoldString(digests, m.GetFileDigest()) {
+			continue
+		}
+		return rule
+	}
+	return nil
+}
+
+// formatIMARule describes a rule in a violation, by its path glob and its
+// file digests.
+func formatIMARule(rule *pb.ImaRule) string {
+	var parts []string
+	if rule.GetPathGlob() != "" {
+		parts = append(parts, fmt.Sprintf("path %q", rule.GetPathGlob()))
+	}
+	if digests := rule.GetFileDigests(); len(digests) > 0 {
+		parts = append(parts, fmt.Sprintf("file digests [%s]", strings.Join(digests, ", ")))
+	}
+	if len(parts) == 0 {
+		return "matching all files"
+	}
+	return strings.Join(parts, " and ")
+}
+
+func matchIMAPath(glob string, name string) bool {
+	if glob == "" {
+		return true
+	}
+	if strings.HasSuffix(glob, "/**") {
+		return strings.HasPrefix(name, strings.TrimSuffix(glob, "**"))
+	}
+	matched, err := path.Match(glob, name)
+	return err == nil && matched
+}
+
+func containsFoldString(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsUint64(values []uint64, value uint64) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 func hasAllowedVersion(state *pb.PlatformState, allowedVersions [][]byte) error {
 	firmware := state.GetFirmware()
 
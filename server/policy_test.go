@@ -1,8 +1,11 @@
 package server
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	pb "github.com/google/go-tpm-tools/proto/attest"
 )
 
@@ -65,7 +68,7 @@ func TestEvaluatePolicy(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			machineState, err := parsePCClientEventLog(test.log.RawLog, test.log.Banks[0], UnsupportedLoader)
+			machineState, err := parsePCClientEventLog(test.log.RawLog, test.log.Banks[0], UnsupportedLoader, GCETrust)
 			if err != nil {
 				t.Fatalf("failed to get machine state: %v", err)
 			}
@@ -83,7 +86,7 @@ func TestEvaluatePolicySCRTM(t *testing.T) {
 				0x4e, 0xf4, 0xbf, 0x17, 0xb8, 0x3a}},
 		},
 	}
-	machineState, err := parsePCClientEventLog(ArchLinuxWorkstation.RawLog, ArchLinuxWorkstation.Banks[0], UnsupportedLoader)
+	machineState, err := parsePCClientEventLog(ArchLinuxWorkstation.RawLog, ArchLinuxWorkstation.Banks[0], UnsupportedLoader, GCETrust)
 	if err != nil {
 		gErr := err.(*GroupedError)
 		if !gErr.containsOnlySubstring(archLinuxBadSecureBoot) {
@@ -138,7 +141,7 @@ func TestEvaluatePolicyFailure(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			machineState, err := parsePCClientEventLog(test.log.RawLog, test.log.Banks[0], UnsupportedLoader)
+			machineState, err := parsePCClientEventLog(test.log.RawLog, test.log.Banks[0], UnsupportedLoader, GCETrust)
 			if err != nil {
 				gErr := err.(*GroupedError)
 				if test.errorSubstr != "" && !gErr.containsOnlySubstring(test.errorSubstr) {
@@ -151,3 +154,260 @@ func TestEvaluatePolicyFailure(t *testing.T) {
 		})
 	}
 }
+
+func TestEvaluateClockPolicy(t *testing.T) {
+	reference := &pb.TPMClockInfo{Clock: 1000, ResetCount: 3, RestartCount: 1, Safe: true}
+	tests := []struct {
+		name    string
+		state   *pb.TPMClockInfo
+		policy  *pb.ClockPolicy
+		wantErr bool
+	}{
+		{"NoPolicy", nil, nil, false},
+		{"Same", reference, &pb.ClockPolicy{RequireSafe: true, Reference: reference}, false},
+		{"Later", &pb.TPMClockInfo{Clock: 2000, ResetCount: 3, RestartCount: 2, Safe: true}, &pb.ClockPolicy{Reference: reference}, false},
+		{"MissingClockInfo", nil, &pb.ClockPolicy{RequireSafe: true}, true},
+		{"Unsafe", &pb.TPMClockInfo{Clock: 2000, ResetCount: 3}, &pb.ClockPolicy{RequireSafe: true}, true},
+		{"UnsafeAllowed", &pb.TPMClockInfo{Clock: 2000, ResetCount: 3, RestartCount: 1}, &pb.ClockPolicy{Reference: reference}, false},
+		{"Reset", &pb.TPMClockInfo{Clock: 2000, ResetCount: 4, Safe: true}, &pb.ClockPolicy{Reference: reference}, true},
+		{"ResetCountRollback", &pb.TPMClockInfo{Clock: 2000, ResetCount: 2, Safe: true}, &pb.ClockPolicy{Reference: reference}, true},
+		{"RestartCountRollback", &pb.TPMClockInfo{Clock: 2000, ResetCount: 3, Safe: true}, &pb.ClockPolicy{Reference: reference}, true},
+		{"ClockRollback", &pb.TPMClockInfo{Clock: 500, ResetCount: 3, RestartCount: 1, Safe: true}, &pb.ClockPolicy{Reference: reference}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := &pb.MachineState{ClockInfo: test.state}
+			err := EvaluatePolicy(state, &pb.Policy{Clock: test.policy})
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("EvaluatePolicy() got error %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvaluateTPMFirmwarePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		version uint64
+		minimum uint64
+		wantErr bool
+	}{
+		{"NoMinimum", 0x0001000200030004, 0, false},
+		{"Equal", 0x0001000200030004, 0x0001000200030004, false},
+		{"Later", 0x0001000300000000, 0x0001000200030004, false},
+		{"Earlier", 0x0001000200030003, 0x0001000200030004, true},
+		{"Missing", 0, 1, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := &pb.MachineState{Platform: &pb.PlatformState{TpmFirmwareVersion: test.version}}
+			err := EvaluatePolicy(state, &pb.Policy{Platform: &pb.PlatformPolicy{MinimumTpmFirmwareVersion: test.minimum}})
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("EvaluatePolicy() got error %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvaluateInstancePolicy(t *testing.T) {
+	info := &pb.GCEInstanceInfo{
+		Zone:            "us-central1-a",
+		ProjectId:       "my-project",
+		ProjectNumber:   1234,
+		InstanceName:    "my-instance",
+		InstanceId:      5678,
+		SecurityVersion: 2,
+	}
+	other := &pb.GCEInstanceInfo{
+		Zone:          "us-central1-a",
+		ProjectId:     "my-project",
+		ProjectNumber: 1234,
+		InstanceName:  "my-instance",
+		InstanceId:    9012,
+	}
+	tests := []struct {
+		name    string
+		info    *pb.GCEInstanceInfo
+		policy  *pb.GCEInstancePolicy
+		wantErr bool
+	}{
+		{"NoPolicy", nil, nil, false},
+		{"EmptyPolicy", info, &pb.GCEInstancePolicy{}, false},
+		{"MissingInstanceInfo", nil, &pb.GCEInstancePolicy{}, true},
+		{"SameInstance", info, InstancePolicy(info), false},
+		{"RecreatedInstance", other, InstancePolicy(info), true},
+		{"AllowedProject", info, &pb.GCEInstancePolicy{AllowedProjectIds: []string{"other-project", "my-project"}}, false},
+		{"OtherProject", info, &pb.GCEInstancePolicy{AllowedProjectIds: []string{"other-project"}}, true},
+		{"OtherProjectNumber", info, &pb.GCEInstancePolicy{AllowedProjectNumbers: []uint64{4321}}, true},
+		{"OtherZone", info, &pb.GCEInstancePolicy{AllowedZones: []string{"europe-west4-a"}}, true},
+		{"OtherInstanceName", info, &pb.GCEInstancePolicy{AllowedInstanceNames: []string{"other-instance"}}, true},
+		{"SecurityVersion", info, &pb.GCEInstancePolicy{MinimumSecurityVersion: 2}, false},
+		{"OldSecurityVersion", info, &pb.GCEInstancePolicy{MinimumSecurityVersion: 3}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := &pb.MachineState{Platform: &pb.PlatformState{InstanceInfo: test.info}}
+			err := EvaluatePolicy(state, &pb.Policy{Platform: &pb.PlatformPolicy{Instance: test.policy}})
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("EvaluatePolicy() got error %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvaluateKernelPolicy(t *testing.T) {
+	state := &pb.LinuxKernelState{
+		Init:             "/usr/lib/systemd/systemd",
+		ImaPolicies:      []string{"tcb"},
+		Lockdown:         pb.KernelLockdown_LOCKDOWN_INTEGRITY,
+		ModuleSigEnforce: true,
+		DmVerity:         &pb.DmVerityState{Alg: "sha256", RootHexdigest: "48d436350a7e83bd"},
+	}
+	tests := []struct {
+		name    string
+		state   *pb.LinuxKernelState
+		policy  *pb.KernelPolicy
+		wantErr bool
+	}{
+		{"NoPolicy", nil, nil, false},
+		{"AllMatch", state, &pb.KernelPolicy{
+			AllowedInit:                   []string{"/sbin/init", "/usr/lib/systemd/systemd"},
+			MinimumLockdown:               pb.KernelLockdown_LOCKDOWN_INTEGRITY,
+			RequiredImaPolicies:           []string{"tcb"},
+			AllowedDmVerityRootHexdigests: []string{"48d436350a7e83bd"},
+			RequireModuleSigEnforce:       true,
+		}, false},
+		{"InitNotAllowed", state, &pb.KernelPolicy{AllowedInit: []string{"/sbin/init"}}, true},
+		{"LockdownTooWeak", state, &pb.KernelPolicy{MinimumLockdown: pb.KernelLockdown_LOCKDOWN_CONFIDENTIALITY}, true},
+		{"MissingImaPolicy", state, &pb.KernelPolicy{RequiredImaPolicies: []string{"appraise_tcb"}}, true},
+		{"DmVerityRootNotAllowed", state, &pb.KernelPolicy{AllowedDmVerityRootHexdigests: []string{"8db95edb446a7311"}}, true},
+		{"MissingDmVerity", &pb.LinuxKernelState{}, &pb.KernelPolicy{AllowedDmVerityRootHexdigests: []string{"48d436350a7e83bd"}}, true},
+		{"ModuleSigNotEnforced", &pb.LinuxKernelState{}, &pb.KernelPolicy{RequireModuleSigEnforce: true}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := &pb.MachineState{LinuxKernel: test.state}
+			err := EvaluatePolicy(state, &pb.Policy{Kernel: test.policy})
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("EvaluatePolicy() got error %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvaluateCosPolicy(t *testing.T) {
+	state := &pb.AttestedCosState{
+		LauncherVersion:      &pb.SemanticVersion{Major: 1, Minor: 2, Patch: 3},
+		LauncherBinaryDigest: "sha256:0123",
+	}
+	tests := []struct {
+		name    string
+		state   *pb.AttestedCosState
+		policy  *pb.CosPolicy
+		wantErr bool
+	}{
+		{"NoPolicy", nil, nil, false},
+		{"AllMatch", state, &pb.CosPolicy{
+			MinimumLauncherVersion:       &pb.SemanticVersion{Major: 1, Minor: 2, Patch: 3},
+			AllowedLauncherBinaryDigests: []string{"sha256:4567", "sha256:0123"},
+		}, false},
+		{"OlderMajorVersion", state, &pb.CosPolicy{MinimumLauncherVersion: &pb.SemanticVersion{Major: 0, Minor: 9, Patch: 9}}, false},
+		{"PatchTooOld", state, &pb.CosPolicy{MinimumLauncherVersion: &pb.SemanticVersion{Major: 1, Minor: 2, Patch: 4}}, true},
+		{"MinorTooOld", state, &pb.CosPolicy{MinimumLauncherVersion: &pb.SemanticVersion{Major: 1, Minor: 3}}, true},
+		{"MissingVersion", &pb.AttestedCosState{}, &pb.CosPolicy{MinimumLauncherVersion: &pb.SemanticVersion{}}, true},
+		{"DigestNotAllowed", state, &pb.CosPolicy{AllowedLauncherBinaryDigests: []string{"sha256:4567"}}, true},
+		{"UntaintedKernel", &pb.AttestedCosState{KernelLog: &pb.KernelLogState{Digest: "sha256:89ab"}}, &pb.CosPolicy{RequireUntaintedKernel: true}, false},
+		{"AllowedTaint", &pb.AttestedCosState{KernelLog: &pb.KernelLogState{Digest: "sha256:89ab", Taint: 1 << 12}}, &pb.CosPolicy{RequireUntaintedKernel: true, AllowedKernelTaint: 1 << 12}, false},
+		{"TaintedKernel", &pb.AttestedCosState{KernelLog: &pb.KernelLogState{Digest: "sha256:89ab", Taint: 1<<12 | 1<<9}}, &pb.CosPolicy{RequireUntaintedKernel: true, AllowedKernelTaint: 1 << 12}, true},
+		{"MissingKernelLog", state, &pb.CosPolicy{RequireUntaintedKernel: true}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := &pb.MachineState{Cos: test.state}
+			err := EvaluatePolicy(state, &pb.Policy{Cos: test.policy})
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("EvaluatePolicy() got error %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvaluateIMAPolicy(t *testing.T) {
+	containerd := "sha256:" + strings.Repeat("ab", 32)
+	runc := "sha256:" + strings.Repeat("cd", 32)
+	state := &pb.ImaState{Measurements: []*pb.ImaMeasurement{
+		{TemplateName: "ima-ng", FileDigest: "sha256:" + strings.Repeat("00", 32), FileName: "boot_aggregate"},
+		{TemplateName: "ima-ng", FileDigest: containerd, FileName: "/usr/bin/containerd"},
+		{TemplateName: "ima-ng", FileDigest: runc, FileName: "/usr/bin/runc"},
+		{TemplateName: "ima-ng", FileDigest: "sha256:" + strings.Repeat("ef", 32), FileName: "/tmp/payload"},
+	}}
+	tests := []struct {
+		name           string
+		state          *pb.ImaState
+		policy         *pb.ImaPolicy
+		wantViolations []uint32
+		wantErr        bool
+	}{
+		{"NoPolicy", nil, nil, nil, false},
+		{"EmptyPolicy", nil, &pb.ImaPolicy{}, nil, false},
+		{"MissingIMALog", nil, &pb.ImaPolicy{Deny: []*pb.ImaRule{{PathGlob: "/tmp/**"}}}, nil, true},
+		{"AllAllowed", state, &pb.ImaPolicy{Allow: []*pb.ImaRule{{PathGlob: "/usr/bin/*"}, {PathGlob: "/tmp/payload"}}}, nil, false},
+		{"AllowedSubtree", state, &pb.ImaPolicy{Allow: []*pb.ImaRule{{PathGlob: "/usr/**"}}}, []uint32{3}, true},
+		{"AllowedDigests", state, &pb.ImaPolicy{Allow: []*pb.ImaRule{
+			{PathGlob: "/usr/bin/*", FileDigests: []string{strings.ToUpper(containerd)}},
+			{FileDigests: []string{runc}},
+		}}, []uint32{3}, true},
+		{"Denied", state, &pb.ImaPolicy{Deny: []*pb.ImaRule{{PathGlob: "/tmp/**"}}}, []uint32{3}, true},
+		{"DeniedDigest", state, &pb.ImaPolicy{Deny: []*pb.ImaRule{{FileDigests: []string{runc}}}}, []uint32{2}, true},
+		{"DenyOverridesAllow", state, &pb.ImaPolicy{
+			Allow: []*pb.ImaRule{{PathGlob: "/**"}},
+			Deny:  []*pb.ImaRule{{PathGlob: "/usr/bin/runc"}},
+		}, []uint32{2}, true},
+		{"LaterBootAggregate", &pb.ImaState{Measurements: []*pb.ImaMeasurement{
+			state.Measurements[0],
+			{TemplateName: "ima-ng", FileDigest: runc, FileName: "boot_aggregate"},
+		}}, &pb.ImaPolicy{Allow: []*pb.ImaRule{{PathGlob: "/usr/bin/*"}}}, []uint32{1}, true},
+		{"Unverified", &pb.ImaState{
+			Measurements:           state.Measurements[:2],
+			UnverifiedMeasurements: []*pb.ImaMeasurement{{TemplateName: "ima-sig", FileDigest: runc, FileName: "/usr/bin/runc"}},
+		}, &pb.ImaPolicy{Allow: []*pb.ImaRule{{PathGlob: "/usr/bin/*"}}}, []uint32{0}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := EvaluatePolicy(&pb.MachineState{Ima: test.state}, &pb.Policy{Ima: test.policy})
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("EvaluatePolicy() got error %v, want error %v", err, test.wantErr)
+			}
+			var policyErr *IMAPolicyError
+			if !errors.As(err, &policyErr) {
+				if test.wantViolations != nil {
+					t.Fatalf("EvaluatePolicy() got error %v, want an IMAPolicyError", err)
+				}
+				return
+			}
+			var got []uint32
+			for _, v := range policyErr.Violations {
+				measurements := test.state.GetMeasurements()
+				if v.GetUnverified() {
+					measurements = test.state.GetUnverifiedMeasurements()
+				}
+				got = append(got, v.GetIndex())
+				if v.GetMeasurement() != measurements[v.GetIndex()] {
+					t.Errorf("violation %d has measurement %v", v.GetIndex(), v.GetMeasurement())
+				}
+			}
+			if !cmp.Equal(got, test.wantViolations) {
+				t.Errorf("EvaluatePolicy() got violations %v, want %v", got, test.wantViolations)
+			}
+		})
+	}
+}
+
+func TestIMAPolicyErrorMessage(t *testing.T) {
+	runc := "sha256:" + strings.Repeat("cd", 32)
+	state := &pb.ImaState{Measurements: []*pb.ImaMeasurement{{TemplateName: "ima-ng", FileDigest: runc, FileName: "/usr/bin/runc"}}}
+	err := EvaluatePolicy(&pb.MachineState{Ima: state}, &pb.Policy{Ima: &pb.ImaPolicy{Deny: []*pb.ImaRule{{FileDigests: []string{runc}}}}})
+	if err == nil || !strings.Contains(err.Error(), "denied by rule file digests ["+runc+"]") {
+		t.Errorf("EvaluatePolicy() got error %v, want a rule described by its digests", err)
+	}
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"google.golang.org/protobuf/proto"
+)
+
+// reportBuilder records the checks of VerifyAttestationWithReport in a
+// VerificationReport.
+type reportBuilder struct {
+	report *pb.VerificationReport
+}
+
+func newReportBuilder(attestation *pb.Attestation) (*reportBuilder, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(attestation)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(data)
+	return &reportBuilder{&pb.VerificationReport{AttestationDigest: digest[:]}}, nil
+}
+
+// passed records a check which passed. quote is nil for the checks of the
+// whole attestation. Nothing is recorded by a nil reportBuilder.
+func (r *reportBuilder) passed(name string, quote *tpmpb.Quote, inputs map[string][]byte) {
+	if r == nil {
+		return
+	}
+	r.report.Checks = append(r.report.Checks, newCheck(name, quote, inputs))
+}
+
+// failed records a check which failed with err, and returns err.
+func (r *reportBuilder) failed(name string, quote *tpmpb.Quote, inputs map[string][]byte, err error) error {
+	if r == nil {
+		return err
+	}
+	check := newCheck(name, quote, inputs)
+	check.Passed = false
+	check.Error = err.Error()
+	check.ErrorCode = CodeUnknown.String()
+	var verificationErr *VerificationError
+	if errors.As(err, &verificationErr) {
+		check.ErrorCode = verificationErr.Code.String()
+	}
+	r.report.Checks = append(r.report.Checks, check)
+	return err
+}
+
+func newCheck(name string, quote *tpmpb.Quote, inputs map[string][]byte) *pb.VerificationCheck {
+	check := &pb.VerificationCheck{
+		Name:   name,
+		Passed: true,
+	}
+	if quote != nil {
+		check.QuoteHash = quote.GetPcrs().GetHash()
+	}
+	if len(inputs) > 0 {
+		check.InputDigests = make(map[string][]byte, len(inputs))
+		for input, data := range inputs {
+			digest := sha256.Sum256(data)
+			check.InputDigests[input] = digest[:]
+		}
+	}
+	return check
+}
+
+// stage records the check of a stage replaced in a Pipeline, which failed if
+// err is not nil, and returns err.
+func (r *reportBuilder) stage(name string, quote *tpmpb.Quote, err error) error {
+	if err != nil {
+		return r.failed(name, quote, nil, err)
+	}
+	r.passed(name, quote, nil)
+	return nil
+}
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"golang.org/x/sync/singleflight"
+)
+
+// maxRevocationListSize limits the size of the revocation lists fetched by
+// RevocationFeed.
+const maxRevocationListSize = 16 << 20
+
+// RevocationChecker checks a verified attestation against revoked artifacts,
+// as the final stage of a Pipeline. It returns a non-nil error if any of them
+// is revoked.
+type RevocationChecker interface {
+	CheckRevocation(attestation *pb.Attestation, state *pb.MachineState) error
+}
+
+// RevocationList is a list of revoked artifacts, in JSON:
+//
+//	{
+//	  "image_digests": ["sha256:..."],
+//	  "ak_cert_fingerprints": ["<hex SHA-256 of the DER certificate>"],
+//	  "gce_firmware_versions": [20],
+//	  "scrtm_version_ids": ["<hex S-CRTM version identifier>"]
+//	}
+//
+// It is a static RevocationChecker, see LoadRevocationList and
+// RevocationFeed for the dynamic sources.
+type RevocationList struct {
+	// ImageDigests are revoked container image digests, checked against
+	// both the image_digest and the image_manifest_digest of the container.
+	ImageDigests []string `json:"image_digests,omitempty"`
+	// AKCertFingerprints are the hex SHA-256 digests of revoked AK
+	// certificates.
+	AKCertFingerprints []string `json:"ak_cert_fingerprints,omitempty"`
+	// GCEFirmwareVersions are revoked versions of the virtual GCE firmware.
+	GCEFirmwareVersions []uint32 `json:"gce_firmware_versions,omitempty"`
+	// ScrtmVersionIDs are the hex S-CRTM version identifiers of revoked
+	// firmware, for the platforms which are not GCE.
+	ScrtmVersionIDs []string `json:"scrtm_version_ids,omitempty"`
+}
+
+// LoadRevocationList reads the JSON RevocationList of a file.
+func LoadRevocationList(path string) (*RevocationList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseRevocationList(data)
+}
+
+func parseRevocationList(data []byte) (*RevocationList, error) {
+	list := &RevocationList{}
+	if err := json.Unmarshal(data, list); err != nil {
+		return nil, fmt.Errorf("invalid revocation list: %w", err)
+	}
+	for _, ids := range [][]string{list.AKCertFingerprints, list.ScrtmVersionIDs} {
+		for _, id := range ids {
+			if _, err := hex.DecodeString(id); err != nil {
+				return nil, fmt.Errorf("invalid revocation list: %q is not hex", id)
+			}
+		}
+	}
+	return list, nil
+}
+
+// CheckRevocation implements RevocationChecker.
+func (l *RevocationList) CheckRevocation(attestation *pb.Attestation, state *pb.MachineState) error {
+	container := state.GetCos().GetContainer()
+	for _, digest := range l.ImageDigests {
+		if digest == "" {
+			continue
+		}
+		if digest == container.GetImageDigest() || digest == container.GetImageManifestDigest() {
+			return fmt.Errorf("container image %s is revoked", digest)
+		}
+	}
+	if akCert := attestation.GetAkCert(); len(akCert) > 0 {
+		fingerprint := sha256.Sum256(akCert)
+		for _, revoked := range l.AKCertFingerprints {
+			if strings.EqualFold(revoked, hex.EncodeToString(fingerprint[:])) {
+				return fmt.Errorf("AK certificate %s is revoked", revoked)
+			}
+		}
+	}
+	platform := state.GetPlatform()
+	switch firmware := platform.GetFirmware().(type) {
+	case *pb.PlatformState_GceVersion:
+		for _, revoked := range l.GCEFirmwareVersions {
+			if revoked == firmware.GceVersion {
+				return fmt.Errorf("GCE firmware version %d is revoked", revoked)
+			}
+		}
+	case *pb.PlatformState_ScrtmVersionId:
+		for _, revoked := range l.ScrtmVersionIDs {
+			if strings.EqualFold(revoked, hex.EncodeToString(firmware.ScrtmVersionId)) {
+				return fmt.Errorf("S-CRTM version %s is revoked", revoked)
+			}
+		}
+	}
+	return nil
+}
+
+// defaultRevocationClient fetches the revocation lists of the RevocationFeeds
+// without a Client, so a feed which does not respond does not hang the checks.
+var defaultRevocationClient = &http.Client{Timeout: 30 * time.Second}
+
+// RevocationFeed is a RevocationChecker fetching its JSON RevocationList from
+// an HTTP(S) URL. The list is fetched on first use, and refetched when it is
+// older than RefreshInterval. Concurrent checks share a single fetch. If the
+// list cannot be refetched, the last list is used until it is older than
+// MaxStaleness. Without a recent enough list, the check fails, so a feed
+// which cannot be reached does not let revoked artifacts through.
+type RevocationFeed struct {
+	// URL serves the RevocationList.
+	URL string
+	// Client fetches the list, a client with a 30 second timeout if nil.
+	Client *http.Client
+	// RefreshInterval is how long a fetched list is used, one hour if zero.
+	RefreshInterval time.Duration
+	// MaxStaleness is how long a fetched list is used while it cannot be
+	// refetched, one day if zero. It is at least the RefreshInterval.
+	MaxStaleness time.Duration
+
+	group   singleflight.Group
+	mu      sync.Mutex
+	list    *RevocationList
+	fetched time.Time
+}
+
+// CheckRevocation implements RevocationChecker.
+func (f *RevocationFeed) CheckRevocation(attestation *pb.Attestation, state *pb.MachineState) error {
+	list, err := f.current()
+	if err != nil {
+		return fmt.Errorf("failed to fetch the revocation list: %w", err)
+	}
+	return list.CheckRevocation(attestation, state)
+}
+
+func (f *RevocationFeed) current() (*RevocationList, error) {
+	interval := f.RefreshInterval
+	if interval == 0 {
+		interval = time.Hour
+	}
+	maxStaleness := f.MaxStaleness
+	if maxStaleness == 0 {
+		maxStaleness = 24 * time.Hour
+	}
+	if maxStaleness < interval {
+		maxStaleness = interval
+	}
+	f.mu.Lock()
+	list, age := f.list, time.Since(f.fetched)
+	f.mu.Unlock()
+	if list != nil && age < interval {
+		return list, nil
+	}
+
+	// The fetch is not under the lock, so the checks can still use the last
+	// list while it is refetched.
+	fetched, err, _ := f.group.Do(f.URL, func() (interface{}, error) {
+		list, err := f.fetch()
+		if err != nil {
+			return nil, err
+		}
+		f.mu.Lock()
+		f.list, f.fetched = list, time.Now()
+		f.mu.Unlock()
+		return list, nil
+	})
+	if err != nil {
+		if list != nil && age < maxStaleness {
+			return list, nil
+		}
+		return nil, err
+	}
+	return fetched.(*RevocationList), nil
+}
+
+func (f *RevocationFeed) fetch() (*RevocationList, error) {
+	client := f.Client
+	if client == nil {
+		client = defaultRevocationClient
+	}
+	resp, err := client.Get(f.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned non-OK status: %v", f.URL, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRevocationListSize))
+	if err != nil {
+		return nil, err
+	}
+	return parseRevocationList(data)
+}
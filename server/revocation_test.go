@@ -0,0 +1,211 @@
+package server
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+)
+
+func TestRevocationList(t *testing.T) {
+	akCert := []byte("ak certificate")
+	fingerprint := sha256.Sum256(akCert)
+	list := &RevocationList{
+		ImageDigests:        []string{"sha256:revoked"},
+		AKCertFingerprints:  []string{hex.EncodeToString(fingerprint[:])},
+		GCEFirmwareVersions: []uint32{1},
+		ScrtmVersionIDs:     []string{"0A0B"},
+	}
+	container := func(c *pb.ContainerState) *pb.MachineState {
+		return &pb.MachineState{Cos: &pb.AttestedCosState{Container: c}}
+	}
+	platform := func(p *pb.PlatformState) *pb.MachineState {
+		return &pb.MachineState{Platform: p}
+	}
+	testCases := []struct {
+		name        string
+		attestation *pb.Attestation
+		state       *pb.MachineState
+		wantRevoked bool
+	}{
+		{"Empty", &pb.Attestation{}, &pb.MachineState{}, false},
+		{"ImageDigest", &pb.Attestation{}, container(&pb.ContainerState{ImageDigest: "sha256:revoked"}), true},
+		{"ImageManifestDigest", &pb.Attestation{}, container(&pb.ContainerState{ImageDigest: "sha256:index", ImageManifestDigest: "sha256:revoked"}), true},
+		{"OtherImage", &pb.Attestation{}, container(&pb.ContainerState{ImageDigest: "sha256:other"}), false},
+		{"AKCert", &pb.Attestation{AkCert: akCert}, &pb.MachineState{}, true},
+		{"OtherAKCert", &pb.Attestation{AkCert: []byte("other")}, &pb.MachineState{}, false},
+		{"GCEFirmware", &pb.Attestation{}, platform(&pb.PlatformState{Firmware: &pb.PlatformState_GceVersion{GceVersion: 1}}), true},
+		{"OtherGCEFirmware", &pb.Attestation{}, platform(&pb.PlatformState{Firmware: &pb.PlatformState_GceVersion{GceVersion: 2}}), false},
+		{"ScrtmVersion", &pb.Attestation{}, platform(&pb.PlatformState{Firmware: &pb.PlatformState_ScrtmVersionId{ScrtmVersionId: []byte{0x0a, 0x0b}}}), true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := list.CheckRevocation(tc.attestation, tc.state)
+			if gotRevoked := err != nil; gotRevoked != tc.wantRevoked {
+				t.Errorf("CheckRevocation() = %v, want revoked %v", err, tc.wantRevoked)
+			}
+		})
+	}
+}
+
+func TestLoadRevocationList(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "revoked.json")
+	if err := os.WriteFile(path, []byte(`{"image_digests": ["sha256:revoked"], "gce_firmware_versions": [1]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	list, err := LoadRevocationList(path)
+	if err != nil {
+		t.Fatalf("LoadRevocationList() failed: %v", err)
+	}
+	if len(list.ImageDigests) != 1 || len(list.GCEFirmwareVersions) != 1 {
+		t.Errorf("got revocation list %+v", list)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"ak_cert_fingerprints": ["not hex"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRevocationList(path); err == nil {
+		t.Error("LoadRevocationList() succeeded with a fingerprint which is not hex")
+	}
+}
+
+func TestRevocationFeed(t *testing.T) {
+	fetches := 0
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.WriteHeader(status)
+		w.Write([]byte(`{"image_digests": ["sha256:revoked"]}`))
+	}))
+	defer srv.Close()
+
+	feed := &RevocationFeed{URL: srv.URL, Client: srv.Client()}
+	revoked := &pb.MachineState{Cos: &pb.AttestedCosState{Container: &pb.ContainerState{ImageDigest: "sha256:revoked"}}}
+	for i := 0; i < 2; i++ {
+		if err := feed.CheckRevocation(&pb.Attestation{}, revoked); err == nil {
+			t.Error("CheckRevocation() succeeded for a revoked image")
+		}
+		if err := feed.CheckRevocation(&pb.Attestation{}, &pb.MachineState{}); err != nil {
+			t.Errorf("CheckRevocation() failed: %v", err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("the feed was fetched %d times, want once", fetches)
+	}
+
+	// A feed which cannot be fetched fails the check.
+	status = http.StatusInternalServerError
+	unavailable := &RevocationFeed{URL: srv.URL, Client: srv.Client()}
+	if err := unavailable.CheckRevocation(&pb.Attestation{}, &pb.MachineState{}); err == nil {
+		t.Error("CheckRevocation() succeeded without a revocation list")
+	}
+
+	// The last list is used while it cannot be refetched, until it is
+	// too stale.
+	status = http.StatusOK
+	stale := &RevocationFeed{URL: srv.URL, Client: srv.Client(), RefreshInterval: time.Nanosecond, MaxStaleness: time.Hour}
+	if err := stale.CheckRevocation(&pb.Attestation{}, &pb.MachineState{}); err != nil {
+		t.Fatalf("CheckRevocation() failed: %v", err)
+	}
+	status = http.StatusInternalServerError
+	if err := stale.CheckRevocation(&pb.Attestation{}, revoked); err == nil || !strings.Contains(err.Error(), "revoked") {
+		t.Errorf("CheckRevocation() = %v, want the revoked image of the stale list", err)
+	}
+	stale.MaxStaleness = time.Nanosecond
+	if err := stale.CheckRevocation(&pb.Attestation{}, &pb.MachineState{}); err == nil {
+		t.Error("CheckRevocation() succeeded with a too stale revocation list")
+	}
+
+	// The MaxStaleness is at least the RefreshInterval.
+	status = http.StatusOK
+	short := &RevocationFeed{URL: srv.URL, Client: srv.Client(), RefreshInterval: time.Hour, MaxStaleness: time.Nanosecond}
+	if err := short.CheckRevocation(&pb.Attestation{}, &pb.MachineState{}); err != nil {
+		t.Fatalf("CheckRevocation() failed: %v", err)
+	}
+	status = http.StatusInternalServerError
+	if err := short.CheckRevocation(&pb.Attestation{}, &pb.MachineState{}); err != nil {
+		t.Errorf("CheckRevocation() failed within the RefreshInterval: %v", err)
+	}
+}
+
+func TestRevocationFeedConcurrentFetch(t *testing.T) {
+	var fetches int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		<-release
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	feed := &RevocationFeed{URL: srv.URL, Client: srv.Client()}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := feed.CheckRevocation(&pb.Attestation{}, &pb.MachineState{}); err != nil {
+				t.Errorf("CheckRevocation() failed: %v", err)
+			}
+		}()
+	}
+	// Let the checks wait on the first fetch.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if got := atomic.LoadInt32(&fetches); got != 1 {
+		t.Errorf("the feed was fetched %d times, want once", got)
+	}
+}
+
+type revocationFunc func(*pb.Attestation, *pb.MachineState) error
+
+func (f revocationFunc) CheckRevocation(attestation *pb.Attestation, state *pb.MachineState) error {
+	return f(attestation, state)
+}
+
+func TestPipelineRevocation(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ak, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+	nonce := []byte("super secret nonce")
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+	opts := VerifyOpts{Nonce: nonce, TrustedAKs: []crypto.PublicKey{ak.PublicKey()}}
+
+	_, report, err := Pipeline{Revocation: &RevocationList{}}.VerifyWithReport(attestation, opts)
+	if err != nil {
+		t.Fatalf("Verify() failed without revoked artifacts: %v", err)
+	}
+	checks := report.GetChecks()
+	if last := checks[len(checks)-1]; last.GetName() != "revocation" || !last.GetPassed() {
+		t.Errorf("last check is %v, want the passed revocation check", last)
+	}
+
+	revoked := Pipeline{Revocation: revocationFunc(func(*pb.Attestation, *pb.MachineState) error {
+		return errors.New("revoked")
+	})}
+	if _, err := revoked.Verify(attestation, opts); !errors.Is(err, ErrRevoked) {
+		t.Errorf("Verify() = %v, want a %v error", err, CodeRevoked)
+	}
+}
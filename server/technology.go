@@ -0,0 +1,92 @@
+package server
+
+import (
+	"fmt"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+)
+
+// technologyRanks orders the confidential computing technologies by the
+// protection they give the instance. SEV-SNP and TDX are equivalent.
+var technologyRanks = map[pb.GCEConfidentialTechnology]int{
+	pb.GCEConfidentialTechnology_NONE:        0,
+	pb.GCEConfidentialTechnology_AMD_SEV:     1,
+	pb.GCEConfidentialTechnology_AMD_SEV_ES:  2,
+	pb.GCEConfidentialTechnology_AMD_SEV_SNP: 3,
+	pb.GCEConfidentialTechnology_INTEL_TDX:   3,
+}
+
+// classifyTechnology classifies the confidential computing technology of an
+// Attestation from the technology measured in the firmware event log, the TEE
+// evidence, and the instance information of the AK certificate. It must only
+// be called once VerifyGceTechnology has succeeded for tech, so that a SEV-SNP
+// report is known to be verified.
+//
+// TDX quotes cannot be verified yet, so a TDX instance is only known from the
+// firmware event log.
+func classifyTechnology(attestation *pb.Attestation, tech pb.GCEConfidentialTechnology, instanceInfo *pb.GCEInstanceInfo) (*pb.ConfidentialComputingState, error) {
+	state := &pb.ConfidentialComputingState{
+		Technology:     tech,
+		GceCertifiedAk: instanceInfo != nil,
+	}
+	switch attestation.GetTeeAttestation().(type) {
+	case nil:
+	case *pb.Attestation_SevSnpAttestation:
+		if tech != pb.GCEConfidentialTechnology_AMD_SEV_SNP {
+			return nil, fmt.Errorf("SEV-SNP attestation report for a %v instance", tech)
+		}
+		state.TeeEvidenceVerified = true
+	default:
+		return nil, fmt.Errorf("unknown TEE attestation %T", attestation.GetTeeAttestation())
+	}
+	return state, nil
+}
+
+// compareTechnology returns -1, 0, or 1 if the technology a gives less, the
+// same, or more protection than b.
+func compareTechnology(a, b pb.GCEConfidentialTechnology) (int, error) {
+	rankA, ok := technologyRanks[a]
+	if !ok {
+		return 0, fmt.Errorf("unknown GCEConfidentialTechnology: %v", a)
+	}
+	rankB, ok := technologyRanks[b]
+	if !ok {
+		return 0, fmt.Errorf("unknown GCEConfidentialTechnology: %v", b)
+	}
+	switch {
+	case rankA < rankB:
+		return -1, nil
+	case rankA > rankB:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// RequireAtLeast checks that the confidential computing technology of a
+// verified MachineState gives at least the protection of minimum, e.g.
+// RequireAtLeast(state, pb.GCEConfidentialTechnology_AMD_SEV_SNP) accepts
+// SEV-SNP and TDX instances.
+func RequireAtLeast(state *pb.MachineState, minimum pb.GCEConfidentialTechnology) error {
+	tech := state.GetConfidentialComputing().GetTechnology()
+	cmp, err := compareTechnology(tech, minimum)
+	if err != nil {
+		return err
+	}
+	if cmp < 0 {
+		return fmt.Errorf("expected a GCE Confidential Technology of %v or later, got %v", minimum, tech)
+	}
+	return nil
+}
+
+// RequireTEEEvidence checks that the confidential computing technology of a
+// verified MachineState gives at least the protection of minimum, and that it
+// is proven by verified TEE evidence rather than only the firmware event log.
+func RequireTEEEvidence(state *pb.MachineState, minimum pb.GCEConfidentialTechnology) error {
+	if err := RequireAtLeast(state, minimum); err != nil {
+		return err
+	}
+	if !state.GetConfidentialComputing().GetTeeEvidenceVerified() {
+		return fmt.Errorf("%v is not proven by verified TEE evidence", state.GetConfidentialComputing().GetTechnology())
+	}
+	return nil
+}
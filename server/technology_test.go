@@ -0,0 +1,114 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	spb "github.com/google/go-sev-guest/proto/sevsnp"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestParseGCENonHostInfoTDX(t *testing.T) {
+	event := getGceMemoryEncryptionNonhostEvent( /*memoryEncrypted=*/ false)
+	event[16] = byte(pb.GCEConfidentialTechnology_INTEL_TDX)
+	tech, err := ParseGCENonHostInfo(event)
+	if err != nil {
+		t.Fatalf("failed to parse GCE confidential tech: %v", err)
+	}
+	if tech != pb.GCEConfidentialTechnology_INTEL_TDX {
+		t.Errorf("expected ConfidentialTechnology %v, received %v", pb.GCEConfidentialTechnology_INTEL_TDX, tech)
+	}
+}
+
+func TestClassifyTechnology(t *testing.T) {
+	snpReport := &pb.Attestation{
+		TeeAttestation: &pb.Attestation_SevSnpAttestation{SevSnpAttestation: &spb.Attestation{}},
+	}
+	instanceInfo := &pb.GCEInstanceInfo{InstanceName: "instance"}
+	tests := []struct {
+		name         string
+		attestation  *pb.Attestation
+		tech         pb.GCEConfidentialTechnology
+		instanceInfo *pb.GCEInstanceInfo
+		want         *pb.ConfidentialComputingState
+	}{
+		{"None", &pb.Attestation{}, pb.GCEConfidentialTechnology_NONE, nil,
+			&pb.ConfidentialComputingState{}},
+		{"SEV", &pb.Attestation{}, pb.GCEConfidentialTechnology_AMD_SEV, instanceInfo,
+			&pb.ConfidentialComputingState{Technology: pb.GCEConfidentialTechnology_AMD_SEV, GceCertifiedAk: true}},
+		{"SEV-SNP", snpReport, pb.GCEConfidentialTechnology_AMD_SEV_SNP, instanceInfo,
+			&pb.ConfidentialComputingState{Technology: pb.GCEConfidentialTechnology_AMD_SEV_SNP, TeeEvidenceVerified: true, GceCertifiedAk: true}},
+		{"TDX", &pb.Attestation{}, pb.GCEConfidentialTechnology_INTEL_TDX, instanceInfo,
+			&pb.ConfidentialComputingState{Technology: pb.GCEConfidentialTechnology_INTEL_TDX, GceCertifiedAk: true}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := classifyTechnology(test.attestation, test.tech, test.instanceInfo)
+			if err != nil {
+				t.Fatalf("classifyTechnology() failed: %v", err)
+			}
+			if diff := cmp.Diff(got, test.want, protocmp.Transform()); diff != "" {
+				t.Errorf("unexpected ConfidentialComputingState difference:\n%v", diff)
+			}
+		})
+	}
+
+	for _, tech := range []pb.GCEConfidentialTechnology{
+		pb.GCEConfidentialTechnology_NONE,
+		pb.GCEConfidentialTechnology_AMD_SEV_ES,
+		pb.GCEConfidentialTechnology_INTEL_TDX,
+	} {
+		if _, err := classifyTechnology(snpReport, tech, nil); err == nil {
+			t.Errorf("classifyTechnology() accepted a SEV-SNP report for a %v instance", tech)
+		}
+	}
+}
+
+func TestRequireAtLeast(t *testing.T) {
+	stateWith := func(tech pb.GCEConfidentialTechnology, verified bool) *pb.MachineState {
+		return &pb.MachineState{ConfidentialComputing: &pb.ConfidentialComputingState{
+			Technology:          tech,
+			TeeEvidenceVerified: verified,
+		}}
+	}
+	tests := []struct {
+		name        string
+		state       *pb.MachineState
+		minimum     pb.GCEConfidentialTechnology
+		wantAtLeast bool
+		wantTEE     bool
+	}{
+		{"NilState", nil, pb.GCEConfidentialTechnology_NONE, true, false},
+		{"NoneForSEV", stateWith(pb.GCEConfidentialTechnology_NONE, false), pb.GCEConfidentialTechnology_AMD_SEV, false, false},
+		{"SEVForSEVES", stateWith(pb.GCEConfidentialTechnology_AMD_SEV, false), pb.GCEConfidentialTechnology_AMD_SEV_ES, false, false},
+		{"SEVESForSEV", stateWith(pb.GCEConfidentialTechnology_AMD_SEV_ES, false), pb.GCEConfidentialTechnology_AMD_SEV, true, false},
+		{"SNPForSNP", stateWith(pb.GCEConfidentialTechnology_AMD_SEV_SNP, true), pb.GCEConfidentialTechnology_AMD_SEV_SNP, true, true},
+		{"TDXForSNP", stateWith(pb.GCEConfidentialTechnology_INTEL_TDX, false), pb.GCEConfidentialTechnology_AMD_SEV_SNP, true, false},
+		{"SNPForTDX", stateWith(pb.GCEConfidentialTechnology_AMD_SEV_SNP, true), pb.GCEConfidentialTechnology_INTEL_TDX, true, true},
+		{"UnknownMinimum", stateWith(pb.GCEConfidentialTechnology_AMD_SEV_SNP, true), pb.GCEConfidentialTechnology(3), false, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := RequireAtLeast(test.state, test.minimum); (err == nil) != test.wantAtLeast {
+				t.Errorf("RequireAtLeast(%v) = %v, want pass %v", test.minimum, err, test.wantAtLeast)
+			}
+			if err := RequireTEEEvidence(test.state, test.minimum); (err == nil) != test.wantTEE {
+				t.Errorf("RequireTEEEvidence(%v) = %v, want pass %v", test.minimum, err, test.wantTEE)
+			}
+		})
+	}
+}
+
+func TestEvaluatePolicyTechnologyRank(t *testing.T) {
+	state := &pb.MachineState{Platform: &pb.PlatformState{Technology: pb.GCEConfidentialTechnology_INTEL_TDX}}
+	policy := &pb.Policy{Platform: &pb.PlatformPolicy{MinimumTechnology: pb.GCEConfidentialTechnology_AMD_SEV_SNP}}
+	if err := EvaluatePolicy(state, policy); err != nil {
+		t.Errorf("TDX instance failed a SEV-SNP minimum: %v", err)
+	}
+	state.Platform.Technology = pb.GCEConfidentialTechnology_AMD_SEV_SNP
+	policy.Platform.MinimumTechnology = pb.GCEConfidentialTechnology_INTEL_TDX
+	if err := EvaluatePolicy(state, policy); err != nil {
+		t.Errorf("SEV-SNP instance failed a TDX minimum: %v", err)
+	}
+}
@@ -7,12 +7,13 @@ import (
 	"encoding/asn1"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/google/go-attestation/attest"
 	"github.com/google/go-tpm-tools/internal"
 	pb "github.com/google/go-tpm-tools/proto/attest"
 	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
 	"github.com/google/go-tpm/tpm2"
-	"google.golang.org/protobuf/proto"
 )
 
 // We conditinally support SHA-1 for PCR hashes, but at the lowest priority.
@@ -30,6 +31,13 @@ type VerifyOpts struct {
 	// attestation. This option should be used if you already know the AK, as
 	// it provides the highest level of assurance.
 	TrustedAKs []crypto.PublicKey
+	// AKInEndorsementHierarchy asserts that the AK is in the endorsement (or
+	// platform) hierarchy of the TPM, like the GCE AKs. The TPM obfuscates the
+	// firmware version and the reset and restart counts in the quotes of the
+	// other AKs, so they are only recorded in the MachineState for these AKs.
+	// AKs with a certificate verified against the TrustedRootCerts with
+	// GCETrust are known to be GCE AKs, and do not need it.
+	AKInEndorsementHierarchy bool
 	// Allow using SHA-1 PCRs to verify attestations. This defaults to false
 	// because SHA-1 is a weak hash algorithm with known collision attacks.
 	// However, setting this to true may be necessary if the client only
@@ -37,6 +45,15 @@ type VerifyOpts struct {
 	// distributions (such as Debian 10). Note that this will NOT allow
 	// SHA-1 signatures to be used, just SHA-1 PCRs.
 	AllowSHA1 bool
+	// Allow attestations which omitted the TCG event log, see
+	// client.AttestOpts.OmitEventLog. Their MachineState only has the states
+	// of the AK, the quote and the other logs, and EventLogOmitted set, so
+	// policies on the boot state fail for them.
+	AllowOmittedEventLog bool
+	// Allow Canonical Event Logs with timestamped records, see
+	// cel.CEL.TimestampSource. This defaults to false because the timestamp
+	// field is experimental, its TLV type is not reserved in the CEL spec.
+	AllowCELTimestamps bool
 	// A collection of trusted root CAs that are used to sign AK certificates.
 	// The TrustedAKs are used first, followed by TrustRootCerts and
 	// IntermediateCerts.
@@ -44,6 +61,10 @@ type VerifyOpts struct {
 	// TPMs signed by that CA will be trusted.
 	TrustedRootCerts  []*x509.Certificate
 	IntermediateCerts []*x509.Certificate
+	// VerifyTime is the time at which the AK certificate chain must be valid.
+	// It defaults to the current time. Setting it to the time an archived
+	// attestation was made reproduces its verification.
+	VerifyTime time.Time
 	// Which bootloader the instance uses. Pick UNSUPPORTED to skip this
 	// parsing or for unsupported bootloaders (e.g., systemd).
 	Loader Bootloader
@@ -52,6 +73,18 @@ type VerifyOpts struct {
 	// If nil, uses Nonce for ReportData and the TEE's verification library's
 	// embedded root certs for its roots of trust.
 	TEEOpts interface{}
+	// TrustMode is the platform of the attested TPM, which decides the
+	// platform claims that are checked. It defaults to GCETrust.
+	TrustMode TrustMode
+	// GCEIdentityCerts are Google's certificates of the instance identity
+	// tokens, by key ID, see ParseGoogleIdentityCerts. If set, the instance
+	// identity token of the GceIdentity of an attestation is verified with
+	// them. Otherwise, it is ignored.
+	GCEIdentityCerts map[string]*x509.Certificate
+
+	// certs caches the certificates of the verifications of a batch, see
+	// VerifyAttestations. The certificates are not cached if nil.
+	certs *certCache
 }
 
 // Bootloader refers to the second-stage bootloader that loads and transfers
@@ -67,6 +100,35 @@ const (
 	GRUB
 )
 
+// TrustMode refers to the platform providing the attested TPM.
+type TrustMode int
+
+const (
+	// GCETrust is for the vTPMs of GCE instances. The GCE instance info of the
+	// AK certificate, and the GCE firmware version and Confidential VM
+	// technology measured by the firmware, are part of the MachineState.
+	GCETrust TrustMode = iota
+	// VirtualTPMTrust is for virtual TPMs outside of GCE, such as swtpm
+	// with QEMU. The AK must be trusted with TrustedAKs, or with an AK
+	// certificate chaining to the TrustedRootCerts of the private cloud (e.g.
+	// its swtpm-localca CA). The GCE claims of the AK certificate and the
+	// event log are not trusted: the instance info is not parsed, the SCRTM
+	// version is not parsed as a GCE firmware version, the Confidential VM
+	// technology is not checked against a TEE attestation, and the Shielded
+	// VM identity is not checked.
+	VirtualTPMTrust
+)
+
+func (m TrustMode) String() string {
+	switch m {
+	case GCETrust:
+		return "GCE"
+	case VirtualTPMTrust:
+		return "virtual TPM"
+	}
+	return fmt.Sprintf("TrustMode(%d)", int(m))
+}
+
 // TODO: Change int64 fields to uint64 when compatible with ASN1 parsing.
 type gceSecurityProperties struct {
 	SecurityVersion int64 `asn1:"explicit,tag:0,optional"`
@@ -82,6 +144,17 @@ type gceInstanceInfo struct {
 	SecurityProperties gceSecurityProperties `asn1:"explicit,optional"`
 }
 
+// VerifyQuoteData verifies a Quote made with client.Key.QuoteData, checking
+// that it was signed by the trusted AK public key, that the provided PCR values
+// match the quote, and that the quote binds data. As with VerifyAttestation,
+// the caller must have already established trust in trustedPub.
+func VerifyQuoteData(quote *tpmpb.Quote, trustedPub crypto.PublicKey, data []byte) error {
+	if err := internal.VerifyQuote(quote, trustedPub, internal.QuoteDataDigest(data)); err != nil {
+		return &VerificationError{Code: quoteErrorCode(err), Err: err}
+	}
+	return nil
+}
+
 // VerifyAttestation performs the following checks on an Attestation:
 //   - the AK used to generate the attestation is trusted (based on VerifyOpts)
 //   - the provided signature is generated by the trusted AK public key
@@ -92,97 +165,63 @@ type gceInstanceInfo struct {
 //   - the provided PCR values match the quote data internal digest
 //   - the provided opts.Nonce matches that in the quote data
 //   - the provided eventlog matches the provided PCR values
+//   - the provided IMA log, if any, matches the provided PCR 10 value
 //
 // After this, the eventlog is parsed and the corresponding MachineState is
 // returned. This design prevents unverified MachineStates from being used.
+//
+// On failure, the returned error is a *VerificationError, whose Code gives the
+// reason of the failure. Custom verifiers can reuse or replace the stages of
+// these checks with a Pipeline.
 func VerifyAttestation(attestation *pb.Attestation, opts VerifyOpts) (*pb.MachineState, error) {
-	if err := validateOpts(opts); err != nil {
-		return nil, fmt.Errorf("bad options: %w", err)
-	}
+	machineState, _, err := VerifyAttestationWithReport(attestation, opts)
+	return machineState, err
+}
 
-	var akPubKey crypto.PublicKey
-	var machineState *pb.MachineState
-	if len(attestation.GetAkCert()) == 0 {
-		// If the AK Cert is not in the attestation, use the AK Public Area.
-		akPubArea, err := tpm2.DecodePublic(attestation.GetAkPub())
-		if err != nil {
-			return nil, fmt.Errorf("failed to decode AK public area: %w", err)
-		}
-		akPubKey, err = akPubArea.Key()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get AK public key: %w", err)
-		}
-		machineState, err = validateAKPub(akPubKey, opts)
-		if err != nil {
-			return nil, fmt.Errorf("failed to validate AK public key: %w", err)
-		}
-	} else {
-		// If AK Cert is presented, ignore the AK Public Area.
-		akCert, err := x509.ParseCertificate(attestation.GetAkCert())
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse AK certificate: %w", err)
-		}
-		// Use intermediate certs from the attestation if they exist.
-		certs, err := parseCerts(attestation.IntermediateCerts)
-		if err != nil {
-			return nil, fmt.Errorf("attestation intermediates: %w", err)
-		}
-		opts.IntermediateCerts = append(opts.IntermediateCerts, certs...)
+// VerifyAttestationWithReport is like VerifyAttestation, but also returns a
+// VerificationReport of every check performed, the digests of its inputs,
+// and its outcome. The report is returned even if verification fails, so
+// audits can reconstruct why an attestation was accepted or rejected.
+func VerifyAttestationWithReport(attestation *pb.Attestation, opts VerifyOpts) (*pb.MachineState, *pb.VerificationReport, error) {
+	return Pipeline{}.VerifyWithReport(attestation, opts)
+}
 
-		machineState, err = validateAKCert(akCert, opts)
-		if err != nil {
-			return nil, fmt.Errorf("failed to validate AK certificate: %w", err)
-		}
-		akPubKey = akCert.PublicKey.(crypto.PublicKey)
+// getClockInfo returns the clock info of the attestation data of a verified
+// quote.
+func getClockInfo(attestationData *tpm2.AttestationData) *pb.TPMClockInfo {
+	return &pb.TPMClockInfo{
+		Clock:        attestationData.ClockInfo.Clock,
+		ResetCount:   attestationData.ClockInfo.ResetCount,
+		RestartCount: attestationData.ClockInfo.RestartCount,
+		Safe:         attestationData.ClockInfo.Safe != 0,
 	}
+}
 
-	// Attempt to replay the log against our PCRs in order of hash preference
-	var lastErr error
-	for _, quote := range supportedQuotes(attestation.GetQuotes()) {
-		// Verify the Quote
-		if err := internal.VerifyQuote(quote, akPubKey, opts.Nonce); err != nil {
-			lastErr = fmt.Errorf("failed to verify quote: %w", err)
-			continue
-		}
-
-		// Parse event logs and replay the events against the provided PCRs
-		pcrs := quote.GetPcrs()
-		state, err := parsePCClientEventLog(attestation.GetEventLog(), pcrs, opts.Loader)
-		if err != nil {
-			lastErr = fmt.Errorf("failed to validate the PCClient event log: %w", err)
-			continue
-		}
-
-		if err := VerifyGceTechnology(attestation, state.Platform.GetTechnology(), &opts); err != nil {
-			lastErr = fmt.Errorf("failed to verify memory encryption technology: %w", err)
-			continue
-		}
-
-		celState, err := parseCanonicalEventLog(attestation.GetCanonicalEventLog(), pcrs)
-		if err != nil {
-			lastErr = fmt.Errorf("failed to validate the Canonical event log: %w", err)
-			continue
-		}
-
-		// Verify the PCR hash algorithm. We have this check here (instead of at
-		// the start of the loop) so that the user gets a "SHA-1 not supported"
-		// error only if allowing SHA-1 support would actually allow the log
-		// to be verified. This makes debugging failed verifications easier.
-		if !opts.AllowSHA1 && tpm2.Algorithm(pcrs.GetHash()) == tpm2.AlgSHA1 {
-			lastErr = fmt.Errorf("SHA-1 is not allowed for verification (set VerifyOpts.AllowSHA1 to true to allow)")
-			continue
-		}
-
-		proto.Merge(machineState, celState)
-		proto.Merge(machineState, state)
-
-		return machineState, nil
+// quoteErrorCode returns the ErrorCode for an error from internal.VerifyQuote.
+func quoteErrorCode(err error) ErrorCode {
+	switch {
+	case errors.Is(err, internal.ErrQuoteSignature):
+		return CodeQuoteSignature
+	case errors.Is(err, internal.ErrQuoteExtraData):
+		return CodeNonceMismatch
+	case errors.Is(err, internal.ErrQuotePCRs):
+		return CodePCRDigestMismatch
 	}
+	return CodeInvalidQuote
+}
 
-	if lastErr != nil {
-		return nil, lastErr
+// eventLogError wraps an error from parsePCClientEventLog, with the PCRs which
+// failed to replay if any.
+func eventLogError(err error) error {
+	var replayErr attest.ReplayError
+	if errors.As(err, &replayErr) {
+		return &VerificationError{
+			Code: CodePCRMismatch,
+			PCRs: replayErr.InvalidPCRs,
+			Err:  fmt.Errorf("failed to validate the PCClient event log: %w", err),
+		}
 	}
-	return nil, fmt.Errorf("attestation does not contain a supported quote")
+	return verificationError(CodeInvalidEventLog, "failed to validate the PCClient event log: %w", err)
 }
 
 // GetGCEInstanceInfo takes a GCE-issued x509 EK/AK certificate and tries to
@@ -226,11 +265,12 @@ func getInstanceInfoFromExtensions(extensions []pkix.Extension) (*pb.GCEInstance
 	}
 
 	return &pb.GCEInstanceInfo{
-		Zone:          info.Zone,
-		ProjectId:     info.ProjectID,
-		ProjectNumber: uint64(info.ProjectNumber),
-		InstanceName:  info.InstanceName,
-		InstanceId:    uint64(info.InstanceID),
+		Zone:            info.Zone,
+		ProjectId:       info.ProjectID,
+		ProjectNumber:   uint64(info.ProjectNumber),
+		InstanceName:    info.InstanceName,
+		InstanceId:      uint64(info.InstanceID),
+		SecurityVersion: uint64(info.SecurityProperties.SecurityVersion),
 	}, nil
 }
 
@@ -244,6 +284,9 @@ func validateOpts(opts VerifyOpts) error {
 	if checkPub && checkCert {
 		return fmt.Errorf("multiple trust mechanisms provided, only use one of TrustedAKs or TrustedRootCerts")
 	}
+	if opts.TrustMode != GCETrust && opts.TrustMode != VirtualTPMTrust {
+		return fmt.Errorf("unknown trust mode %v", opts.TrustMode)
+	}
 	return nil
 }
 
@@ -274,18 +317,24 @@ func validateAKCert(akCert *x509.Certificate, opts VerifyOpts) (*pb.MachineState
 	akCert.UnhandledCriticalExtensions = exts
 
 	x509Opts := x509.VerifyOptions{
-		Roots:         makePool(opts.TrustedRootCerts),
+		Roots:         opts.certs.rootPool(opts.TrustedRootCerts),
 		Intermediates: makePool(opts.IntermediateCerts),
 		// The default key usage (ExtKeyUsageServerAuth) is not appropriate for
 		// an Attestation Key: ExtKeyUsage of
 		// - https://oidref.com/2.23.133.8.1
 		// - https://oidref.com/2.23.133.8.3
 		// https://pkg.go.dev/crypto/x509#VerifyOptions
-		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsage(x509.ExtKeyUsageAny)},
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsage(x509.ExtKeyUsageAny)},
+		CurrentTime: opts.VerifyTime,
 	}
 	if _, err := akCert.Verify(x509Opts); err != nil {
 		return nil, fmt.Errorf("certificate did not chain to a trusted root: %v", err)
 	}
+	if opts.TrustMode == VirtualTPMTrust {
+		// A private CA may copy any extension, so the GCE instance info is
+		// not trusted.
+		return &pb.MachineState{}, nil
+	}
 
 	instanceInfo, err := getInstanceInfoFromExtensions(akCert.Extensions)
 	if err != nil {
@@ -328,6 +377,8 @@ func VerifyGceTechnology(attestation *pb.Attestation, tech pb.GCEConfidentialTec
 		return nil
 	case pb.GCEConfidentialTechnology_AMD_SEV_ES: // Not verifiable on GCE
 		return nil
+	case pb.GCEConfidentialTechnology_INTEL_TDX: // TDX quotes are not verified yet
+		return nil
 	case pb.GCEConfidentialTechnology_AMD_SEV_SNP:
 		switch tee := attestation.GetTeeAttestation().(type) {
 		case *pb.Attestation_SevSnpAttestation:
@@ -6,12 +6,16 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/asn1"
 	"encoding/binary"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"hash"
 	"io"
+	"math/big"
 	"os"
 	"strings"
 	"testing"
@@ -27,6 +31,7 @@ import (
 	"github.com/google/go-tpm-tools/internal"
 	"github.com/google/go-tpm-tools/internal/test"
 	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
 	"github.com/google/go-tpm/tpm2"
 	"github.com/google/go-tpm/tpmutil"
 	"github.com/google/logger"
@@ -296,6 +301,258 @@ func TestVerifyWithTrustedAK(t *testing.T) {
 	}
 }
 
+func TestVerifyShieldedVMIdentity(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	ak, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+	otherKey, err := client.AttestationKeyECC(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer otherKey.Close()
+
+	nonce := []byte("super secret nonce")
+	opts := VerifyOpts{
+		Nonce:      nonce,
+		TrustedAKs: []crypto.PublicKey{ak.PublicKey()},
+	}
+	for _, tc := range []struct {
+		name          string
+		signingKeyPub crypto.PublicKey
+		wantErr       bool
+	}{
+		{"AK", ak.PublicKey(), false},
+		{"OtherKey", otherKey.PublicKey(), true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce})
+			if err != nil {
+				t.Fatalf("failed to attest: %v", err)
+			}
+			der, err := x509.MarshalPKIXPublicKey(tc.signingKeyPub)
+			if err != nil {
+				t.Fatal(err)
+			}
+			attestation.GceIdentity = &attestpb.GceIdentity{ShieldedVmIdentity: &attestpb.ShieldedVmIdentity{
+				SigningKeyPub: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+			}}
+			_, err = VerifyAttestation(attestation, opts)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("VerifyAttestation() got error %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyAttestationOmittedEventLog(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	ak, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+
+	nonce := []byte("super secret nonce")
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce, OmitEventLog: true})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+	opts := VerifyOpts{Nonce: nonce, TrustedAKs: []crypto.PublicKey{ak.PublicKey()}}
+	if _, err := VerifyAttestation(attestation, opts); !errors.Is(err, ErrEventLogOmitted) {
+		t.Errorf("VerifyAttestation() got error %v, want ErrEventLogOmitted", err)
+	}
+
+	opts.AllowOmittedEventLog = true
+	state, err := VerifyAttestation(attestation, opts)
+	if err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+	if !state.GetEventLogOmitted() || state.GetPlatform() != nil || len(state.GetRawEvents()) != 0 {
+		t.Errorf("got MachineState with event log omitted %v, platform %v and %d events, want only the omission", state.GetEventLogOmitted(), state.GetPlatform(), len(state.GetRawEvents()))
+	}
+	if state.GetHash() == tpmpb.HashAlgo_HASH_INVALID {
+		t.Error("got MachineState without the hash algorithm of the verified quote")
+	}
+
+	withLog, err := ak.Attest(client.AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+	withLog.EventLogOmitted = true
+	if _, err := VerifyAttestation(withLog, opts); !errors.Is(err, ErrInvalidEventLog) {
+		t.Errorf("VerifyAttestation() of an attestation with a log marked as omitted got error %v, want ErrInvalidEventLog", err)
+	}
+}
+
+func TestVerifyAttestationWithReport(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	ak, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+
+	nonce := []byte("super secret nonce")
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+
+	state, report, err := VerifyAttestationWithReport(attestation, VerifyOpts{
+		Nonce:      nonce,
+		TrustedAKs: []crypto.PublicKey{ak.PublicKey()},
+	})
+	if err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+	if !report.GetVerified() || report.GetVerifiedQuoteHash() != state.GetHash() {
+		t.Errorf("report is verified %v with hash %v, want verified with hash %v", report.GetVerified(), report.GetVerifiedQuoteHash(), state.GetHash())
+	}
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(attestation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest := sha256.Sum256(data); !bytes.Equal(report.GetAttestationDigest(), digest[:]) {
+		t.Errorf("report attestation digest is %x, want %x", report.GetAttestationDigest(), digest)
+	}
+	// Quotes of other banks may fail, but all the checks of the attestation
+	// and of the verified quote passed.
+	var names []string
+	var quoteCheck *attestpb.VerificationCheck
+	for _, check := range report.GetChecks() {
+		if check.GetQuoteHash() != tpmpb.HashAlgo_HASH_INVALID && check.GetQuoteHash() != state.GetHash() {
+			continue
+		}
+		if !check.GetPassed() {
+			t.Errorf("check %s failed in a verified report: %s", check.GetName(), check.GetError())
+		}
+		if check.GetName() == "quote_signature" {
+			quoteCheck = check
+		}
+		names = append(names, check.GetName())
+	}
+	wantNames := []string{"options", "ak_parse", "ak_trusted", "quote_signature", "event_log_replay", "tee_technology", "canonical_event_log_replay", "canonical_event_log_timestamps"}
+	if !cmp.Equal(names, wantNames) {
+		t.Errorf("report checks are %v, want %v", names, wantNames)
+	}
+	for _, quote := range attestation.GetQuotes() {
+		if quote.GetPcrs().GetHash() != state.GetHash() {
+			continue
+		}
+		quoteDigest := sha256.Sum256(quote.GetQuote())
+		if !bytes.Equal(quoteCheck.GetInputDigests()["quote"], quoteDigest[:]) {
+			t.Errorf("quote_signature check has quote digest %x, want %x", quoteCheck.GetInputDigests()["quote"], quoteDigest)
+		}
+	}
+
+	_, report, err = VerifyAttestationWithReport(attestation, VerifyOpts{
+		Nonce:      append(nonce, 0),
+		TrustedAKs: []crypto.PublicKey{ak.PublicKey()},
+	})
+	if err == nil {
+		t.Fatal("using the wrong nonce should make verification fail")
+	}
+	if report.GetVerified() {
+		t.Error("report of a failed verification is verified")
+	}
+	// Every quote is tried, and fails on the nonce.
+	var failed int
+	for _, check := range report.GetChecks() {
+		if check.GetPassed() {
+			continue
+		}
+		failed++
+		if check.GetName() != "quote_signature" || check.GetErrorCode() != CodeNonceMismatch.String() {
+			t.Errorf("check %s failed with %s, want quote_signature to fail with %s", check.GetName(), check.GetErrorCode(), CodeNonceMismatch)
+		}
+	}
+	if failed != len(attestation.GetQuotes()) {
+		t.Errorf("report has %d failed checks, want one per quote (%d)", failed, len(attestation.GetQuotes()))
+	}
+}
+
+func TestVerifyClockInfo(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	ak, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+
+	nonce := []byte("super secret nonce")
+	opts := VerifyOpts{
+		Nonce:      nonce,
+		TrustedAKs: []crypto.PublicKey{ak.PublicKey()},
+	}
+	verify := func() *attestpb.MachineState {
+		t.Helper()
+		attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce})
+		if err != nil {
+			t.Fatalf("failed to attest: %v", err)
+		}
+		state, err := VerifyAttestation(attestation, opts)
+		if err != nil {
+			t.Fatalf("failed to verify: %v", err)
+		}
+		if state.GetClockInfo() == nil {
+			t.Fatal("VerifyAttestation() did not return the clock info")
+		}
+		return state
+	}
+
+	enrolled := verify().GetClockInfo()
+	state := verify()
+	if err := EvaluatePolicy(state, &attestpb.Policy{Clock: &attestpb.ClockPolicy{Reference: enrolled}}); err != nil {
+		t.Errorf("failed to apply policy with the enrolled clock info: %v", err)
+	}
+	reset := proto.Clone(enrolled).(*attestpb.TPMClockInfo)
+	reset.ResetCount++
+	if err := EvaluatePolicy(state, &attestpb.Policy{Clock: &attestpb.ClockPolicy{Reference: reset}}); err == nil {
+		t.Error("expected policy failure with a different reset count")
+	}
+}
+
+func TestVerifyQuoteData(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	ak, err := client.AttestationKeyECC(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+
+	// Much larger than the TPM's limit on extraData.
+	data := bytes.Repeat([]byte("a large manifest "), 4096)
+	quote, err := ak.QuoteData(client.FullPcrSel(tpm2.AlgSHA256), data)
+	if err != nil {
+		t.Fatalf("failed to quote: %v", err)
+	}
+	if err := VerifyQuoteData(quote, ak.PublicKey(), data); err != nil {
+		t.Errorf("failed to verify: %v", err)
+	}
+
+	tampered := append([]byte{}, data...)
+	tampered[len(tampered)-1] ^= 1
+	if err := VerifyQuoteData(quote, ak.PublicKey(), tampered); err == nil {
+		t.Error("VerifyQuoteData succeeded with different data")
+	}
+	if err := VerifyQuoteData(quote, ak.PublicKey(), internal.QuoteDataDigest(data)); err == nil {
+		t.Error("VerifyQuoteData succeeded with the digest instead of the data")
+	}
+}
+
 func TestVerifySHA1Attestation(t *testing.T) {
 	rwc := test.GetTPM(t)
 	defer client.CheckedClose(t, rwc)
@@ -482,6 +739,62 @@ func TestVerifyFailWithTamperedCELContent(t *testing.T) {
 	}
 }
 
+func TestVerifyCELTimestamps(t *testing.T) {
+	test.SkipForRealTPM(t)
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	ak, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+
+	c := &cel.CEL{TimestampSource: cel.TPMClockTimestamps}
+	for _, event := range []cel.CosTlv{
+		{EventType: cel.ImageRefType, EventContent: []byte("docker.io/bazel/experimental/test:latest")},
+		{EventType: cel.LaunchSeparatorType},
+	} {
+		if err := c.AppendEvent(rwc, cel.CosEventPCR, measuredHashes, event); err != nil {
+			t.Fatalf("failed to append event: %v", err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := c.EncodeCEL(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	nonce := []byte("super secret nonce")
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce, CanonicalEventLog: buf.Bytes()})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+	opts := VerifyOpts{Nonce: nonce, TrustedAKs: []crypto.PublicKey{ak.PublicKey()}}
+	if _, err := VerifyAttestation(attestation, opts); err == nil {
+		t.Error("verified timestamped records without AllowCELTimestamps")
+	}
+	opts.AllowCELTimestamps = true
+	state, err := VerifyAttestation(attestation, opts)
+	if err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+
+	if got := state.GetCos().GetTimestampSource(); got != attestpb.CelTimestampSource_CEL_TIMESTAMP_SOURCE_TPM_CLOCK {
+		t.Errorf("got timestamp source %v, want TPM clock", got)
+	}
+	var want []*attestpb.CosEventTimestamp
+	for _, record := range c.Records {
+		cosTlv, err := record.Content.ParseToCosTlv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, &attestpb.CosEventTimestamp{RecordNumber: record.RecNum, EventType: uint32(cosTlv.EventType), Timestamp: record.Timestamp.Value})
+	}
+	if diff := cmp.Diff(state.GetCos().GetEventTimestamps(), want, protocmp.Transform()); diff != "" {
+		t.Errorf("unexpected event timestamps difference:\n%v", diff)
+	}
+}
+
 func TestVerifyAttestationWithCerts(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -518,6 +831,34 @@ func TestVerifyAttestationWithCerts(t *testing.T) {
 	}
 }
 
+func TestVerifyAttestationWithCertsAtVerifyTime(t *testing.T) {
+	att := &attestpb.Attestation{}
+	if err := proto.Unmarshal(test.COS85NoNonce, att); err != nil {
+		t.Fatalf("failed to unmarshal attestation: %v", err)
+	}
+	akCert, err := x509.ParseCertificate(att.GetAkCert())
+	if err != nil {
+		t.Fatalf("failed to parse AK certificate: %v", err)
+	}
+
+	opts := VerifyOpts{
+		TrustedRootCerts:  GceEKRoots,
+		IntermediateCerts: GceEKIntermediates,
+		VerifyTime:        akCert.NotBefore.Add(time.Hour),
+	}
+	if _, err := VerifyAttestation(att, opts); err != nil {
+		t.Errorf("failed to VerifyAttestation within the AK certificate validity: %v", err)
+	}
+	opts.VerifyTime = akCert.NotBefore.Add(-time.Hour)
+	if _, err := VerifyAttestation(att, opts); err == nil {
+		t.Error("VerifyAttestation succeeded before the AK certificate validity")
+	}
+	opts.VerifyTime = akCert.NotAfter.Add(time.Hour)
+	if _, err := VerifyAttestation(att, opts); err == nil {
+		t.Error("VerifyAttestation succeeded after the AK certificate validity")
+	}
+}
+
 func TestVerifyAutomaticallyUsesIntermediatesInAttestation(t *testing.T) {
 	attestBytes := test.COS85Nonce9009
 	att := &attestpb.Attestation{}
@@ -668,6 +1009,107 @@ func TestVerifyFailsWithMalformedIntermediatesInAttestation(t *testing.T) {
 	}
 }
 
+// createPrivateCA returns the root certificate and key of a private cloud CA,
+// e.g. the CA of swtpm-localca.
+func createPrivateCA(t *testing.T) (*x509.Certificate, crypto.Signer) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "swtpm-localca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root, key
+}
+
+func TestVerifyVirtualTPMTrust(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	ak, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+
+	nonce := []byte("super secret nonce")
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+
+	// The private CA certifies the AK with the GCE instance info extension,
+	// which must not be trusted outside of GCE.
+	root, caKey := createPrivateCA(t)
+	instanceInfo, err := asn1.Marshal(gceInstanceInfo{
+		Zone:               "us-central1-a",
+		ProjectID:          "forged-project",
+		ProjectNumber:      1,
+		InstanceName:       "forged-instance",
+		InstanceID:         1,
+		SecurityProperties: gceSecurityProperties{IsProduction: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	akTemplate := &x509.Certificate{
+		SerialNumber:    big.NewInt(2),
+		NotBefore:       time.Now().Add(-time.Hour),
+		NotAfter:        time.Now().Add(time.Hour),
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtraExtensions: []pkix.Extension{{Id: cloudComputeInstanceIdentifierOID, Value: instanceInfo}},
+	}
+	attestation.AkCert, err = x509.CreateCertificate(rand.Reader, akTemplate, root, ak.PublicKey(), caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := VerifyOpts{
+		Nonce:            nonce,
+		TrustedRootCerts: []*x509.Certificate{root},
+		TrustMode:        VirtualTPMTrust,
+	}
+	state, err := VerifyAttestation(attestation, opts)
+	if err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+	if info := state.GetPlatform().GetInstanceInfo(); info != nil {
+		t.Errorf("got instance info %v from a private CA, want none", info)
+	}
+	if state.GetConfidentialComputing() != nil {
+		t.Errorf("got confidential computing state %v outside of GCE, want none", state.GetConfidentialComputing())
+	}
+
+	opts.TrustMode = GCETrust
+	state, err = VerifyAttestation(attestation, opts)
+	if err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+	if state.GetPlatform().GetInstanceInfo().GetProjectId() != "forged-project" {
+		t.Errorf("got instance info %v in the GCE trust mode, want the AK certificate's", state.GetPlatform().GetInstanceInfo())
+	}
+
+	opts.TrustMode = VirtualTPMTrust + 1
+	if _, err := VerifyAttestation(attestation, opts); !errors.Is(err, ErrBadOptions) {
+		t.Errorf("got error %v for an unknown trust mode, want %v", err, ErrBadOptions)
+	}
+}
+
 func TestGetInstanceInfo(t *testing.T) {
 	expectedInstanceInfo := &attestpb.GCEInstanceInfo{
 		Zone:          "expected zone",
@@ -0,0 +1,426 @@
+package server
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/google/go-tpm-tools/internal"
+	"github.com/google/go-tpm/tpm2"
+)
+
+// The OIDs of the AK certificate of a WebAuthn "tpm" attestation statement,
+// see https://www.w3.org/TR/webauthn-2/#sctn-tpm-cert-requirements.
+var (
+	oidTCGKpAIKCertificate = asn1.ObjectIdentifier{2, 23, 133, 8, 3}
+	oidTPMManufacturer     = asn1.ObjectIdentifier{2, 23, 133, 2, 1}
+	oidTPMModel            = asn1.ObjectIdentifier{2, 23, 133, 2, 2}
+	oidTPMVersion          = asn1.ObjectIdentifier{2, 23, 133, 2, 3}
+	oidFIDOGenCEAAGUID     = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 45724, 1, 1, 4}
+)
+
+// authDataFlagAttestedCredentialData is the flag of the WebAuthn
+// authenticator data set if it has attested credential data.
+const authDataFlagAttestedCredentialData = 0x40
+
+// COSE key parameters, key types, curves and algorithms, see RFC 9053.
+const (
+	coseKeyKty   = 1
+	coseKeyAlg   = 3
+	coseKeyCrv   = -1
+	coseKeyX     = -2
+	coseKeyY     = -3
+	coseKeyRSAN  = -1
+	coseKeyRSAE  = -2
+	coseKtyEC2   = 2
+	coseKtyRSA   = 3
+	coseCrvP256  = 1
+	coseCrvP384  = 2
+	coseCrvP521  = 3
+	coseAlgES256 = -7
+	coseAlgES384 = -35
+	coseAlgES512 = -36
+	coseAlgRS256 = -257
+	coseAlgRS384 = -258
+	coseAlgRS512 = -259
+)
+
+const (
+	tpmStmtFormat = "tpm"
+	tpmStmtVer    = "2.0"
+)
+
+// WebAuthnTPMStatement is the "tpm" attestation statement of a WebAuthn
+// attestation object, e.g. of Windows Hello, see
+// https://www.w3.org/TR/webauthn-2/#sctn-tpm-attestation.
+type WebAuthnTPMStatement struct {
+	Ver string `cbor:"ver"`
+	// Alg is the COSE algorithm of Sig.
+	Alg int64 `cbor:"alg"`
+	// X5C is the AK certificate followed by its certificate chain, in DER.
+	X5C [][]byte `cbor:"x5c"`
+	// Sig is the TPMT_SIGNATURE of CertInfo by the AK.
+	Sig []byte `cbor:"sig"`
+	// CertInfo is the TPMS_ATTEST of the TPM2_Certify of the credential key.
+	CertInfo []byte `cbor:"certInfo"`
+	// PubArea is the TPMT_PUBLIC of the credential key.
+	PubArea []byte `cbor:"pubArea"`
+}
+
+type webAuthnAttestationObject struct {
+	Fmt      string          `cbor:"fmt"`
+	AttStmt  cbor.RawMessage `cbor:"attStmt"`
+	AuthData []byte          `cbor:"authData"`
+}
+
+// WebAuthnOpts allows for customizing the functionality of
+// VerifyWebAuthnTPMAttestation.
+type WebAuthnOpts struct {
+	// TrustedRootCerts are the root CAs of the AK certificates, e.g. the
+	// Microsoft TPM root CAs for Windows Hello. They are required unless
+	// InsecureSkipChainVerification is set.
+	TrustedRootCerts []*x509.Certificate
+	// IntermediateCerts are used with the certificates of x5c to chain the AK
+	// certificate to the TrustedRootCerts.
+	IntermediateCerts []*x509.Certificate
+	// VerifyTime is the time at which the AK certificate chain must be valid.
+	// It defaults to the current time.
+	VerifyTime time.Time
+	// InsecureSkipChainVerification skips chaining the AK certificate to the
+	// TrustedRootCerts, so the relying party must establish trust in the
+	// returned AKCert itself. The validity period of the AK certificate is
+	// still checked.
+	InsecureSkipChainVerification bool
+}
+
+// WebAuthnTPMAttestation is the result of a verified "tpm" attestation
+// statement: the AttCA attestation of the credential key by a TPM AK.
+type WebAuthnTPMAttestation struct {
+	// AAGUID identifies the model of the authenticator.
+	AAGUID []byte
+	// CredentialID and CredentialPublicKey are the attested credential.
+	CredentialID        []byte
+	CredentialPublicKey crypto.PublicKey
+	// AKCert is the certificate of the AK which certified the credential key.
+	AKCert *x509.Certificate
+	// Chain is the verified chain from AKCert to one of the TrustedRootCerts,
+	// or nil with InsecureSkipChainVerification.
+	Chain []*x509.Certificate
+	// The TPM of the AK, from the subject alternative name of AKCert.
+	TPMManufacturer string
+	TPMModel        string
+	TPMVersion      string
+}
+
+// VerifyWebAuthnTPMAttestation performs the verification procedure of the
+// "tpm" attestation statement format on a WebAuthn attestation object (in
+// CBOR, with its "fmt", "attStmt" and "authData"), for the hash of the
+// client data of the registration:
+//   - the credential public key of the authenticator data is the key of the
+//     TPM public area
+//   - the certInfo is a TPM2_Certify of the public area, signed by the AK of
+//     the AK certificate, over the hash of the authenticator data and the
+//     client data hash
+//   - the AK certificate meets the TPM attestation certificate requirements
+//   - the AK certificate is valid at VerifyTime and, unless
+//     InsecureSkipChainVerification is set, chains to the TrustedRootCerts
+//
+// The relying party must still check the other steps of the registration,
+// e.g. the RP ID hash and the flags of the authenticator data.
+func VerifyWebAuthnTPMAttestation(attestationObject []byte, clientDataHash []byte, opts WebAuthnOpts) (*WebAuthnTPMAttestation, error) {
+	var obj webAuthnAttestationObject
+	if err := cbor.Unmarshal(attestationObject, &obj); err != nil {
+		return nil, fmt.Errorf("malformed attestation object: %v", err)
+	}
+	if obj.Fmt != tpmStmtFormat {
+		return nil, fmt.Errorf("unsupported attestation statement format %q, expected %q", obj.Fmt, tpmStmtFormat)
+	}
+	var stmt WebAuthnTPMStatement
+	if err := cbor.Unmarshal(obj.AttStmt, &stmt); err != nil {
+		return nil, fmt.Errorf("malformed tpm attestation statement: %v", err)
+	}
+	if stmt.Ver != tpmStmtVer {
+		return nil, fmt.Errorf("unsupported tpm attestation statement version %q", stmt.Ver)
+	}
+	result, err := parseAttestedCredential(obj.AuthData)
+	if err != nil {
+		return nil, err
+	}
+
+	pubArea, err := tpm2.DecodePublic(stmt.PubArea)
+	if err != nil {
+		return nil, fmt.Errorf("malformed pubArea: %v", err)
+	}
+	pubAreaKey, err := pubArea.Key()
+	if err != nil {
+		return nil, fmt.Errorf("unsupported pubArea key: %v", err)
+	}
+	if !publicKeysEqual(pubAreaKey, result.CredentialPublicKey) {
+		return nil, errors.New("pubArea key does not match the credential public key")
+	}
+
+	if len(stmt.X5C) == 0 {
+		return nil, errors.New("missing AK certificate in x5c")
+	}
+	certs := make([]*x509.Certificate, len(stmt.X5C))
+	for i, der := range stmt.X5C {
+		if certs[i], err = x509.ParseCertificate(der); err != nil {
+			return nil, fmt.Errorf("malformed certificate %d of x5c: %v", i, err)
+		}
+	}
+	result.AKCert = certs[0]
+
+	hash, sigAlg, err := webAuthnSignatureAlgorithm(stmt.Alg)
+	if err != nil {
+		return nil, err
+	}
+	h := hash.New()
+	h.Write(obj.AuthData)
+	h.Write(clientDataHash)
+	sig, err := internal.VerifyCertify(stmt.CertInfo, stmt.Sig, result.AKCert.PublicKey, pubArea, h.Sum(nil))
+	if err != nil {
+		return nil, fmt.Errorf("invalid certInfo: %w", err)
+	}
+	if sig.Alg != sigAlg || signatureHashAlg(sig) != hash {
+		return nil, fmt.Errorf("signature of certInfo does not match the algorithm %d", stmt.Alg)
+	}
+
+	if err := validateWebAuthnAKCert(result, opts, certs[1:]); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// parseAttestedCredential parses the attested credential data of the
+// authenticator data, see https://www.w3.org/TR/webauthn-2/#sctn-authenticator-data.
+func parseAttestedCredential(authData []byte) (*WebAuthnTPMAttestation, error) {
+	// rpIdHash (32), flags (1), signCount (4), aaguid (16) and the length of
+	// the credential ID (2).
+	const headerSize = 32 + 1 + 4 + 16 + 2
+	if len(authData) < headerSize {
+		return nil, errors.New("authenticator data too short")
+	}
+	if authData[32]&authDataFlagAttestedCredentialData == 0 {
+		return nil, errors.New("authenticator data has no attested credential data")
+	}
+	aaguid := authData[37:53]
+	idLen := int(binary.BigEndian.Uint16(authData[53:55]))
+	if len(authData) < headerSize+idLen {
+		return nil, errors.New("authenticator data too short for the credential ID")
+	}
+	credentialID := authData[headerSize : headerSize+idLen]
+
+	// The COSE key may be followed by extensions.
+	var coseKey map[int]interface{}
+	if err := cbor.NewDecoder(bytes.NewReader(authData[headerSize+idLen:])).Decode(&coseKey); err != nil {
+		return nil, fmt.Errorf("malformed credential public key: %v", err)
+	}
+	pub, err := parseCOSEKey(coseKey)
+	if err != nil {
+		return nil, err
+	}
+	return &WebAuthnTPMAttestation{AAGUID: aaguid, CredentialID: credentialID, CredentialPublicKey: pub}, nil
+}
+
+func parseCOSEKey(key map[int]interface{}) (crypto.PublicKey, error) {
+	kty, _ := key[coseKeyKty].(uint64)
+	switch kty {
+	case coseKtyEC2:
+		crv, _ := key[coseKeyCrv].(uint64)
+		var curve elliptic.Curve
+		switch crv {
+		case coseCrvP256:
+			curve = elliptic.P256()
+		case coseCrvP384:
+			curve = elliptic.P384()
+		case coseCrvP521:
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported COSE key curve %v", key[coseKeyCrv])
+		}
+		x, _ := key[coseKeyX].([]byte)
+		y, _ := key[coseKeyY].([]byte)
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, errors.New("COSE key point is not on its curve")
+		}
+		return pub, nil
+	case coseKtyRSA:
+		n, _ := key[coseKeyRSAN].([]byte)
+		e, _ := key[coseKeyRSAE].([]byte)
+		if len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("malformed COSE RSA key")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	}
+	return nil, fmt.Errorf("unsupported COSE key type %v", key[coseKeyKty])
+}
+
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	switch a := a.(type) {
+	case *rsa.PublicKey:
+		return a.Equal(b)
+	case *ecdsa.PublicKey:
+		return a.Equal(b)
+	}
+	return false
+}
+
+// webAuthnSignatureAlgorithm returns the hash and the TPM signature scheme of
+// a COSE algorithm. As for quotes, SHA-1 (RS1) is not supported.
+func webAuthnSignatureAlgorithm(alg int64) (crypto.Hash, tpm2.Algorithm, error) {
+	switch alg {
+	case coseAlgRS256:
+		return crypto.SHA256, tpm2.AlgRSASSA, nil
+	case coseAlgRS384:
+		return crypto.SHA384, tpm2.AlgRSASSA, nil
+	case coseAlgRS512:
+		return crypto.SHA512, tpm2.AlgRSASSA, nil
+	case coseAlgES256:
+		return crypto.SHA256, tpm2.AlgECDSA, nil
+	case coseAlgES384:
+		return crypto.SHA384, tpm2.AlgECDSA, nil
+	case coseAlgES512:
+		return crypto.SHA512, tpm2.AlgECDSA, nil
+	}
+	return 0, 0, fmt.Errorf("unsupported attestation statement algorithm %d", alg)
+}
+
+func signatureHashAlg(sig *tpm2.Signature) crypto.Hash {
+	var alg tpm2.Algorithm
+	if sig.RSA != nil {
+		alg = sig.RSA.HashAlg
+	} else if sig.ECC != nil {
+		alg = sig.ECC.HashAlg
+	}
+	hash, err := alg.Hash()
+	if err != nil {
+		return 0
+	}
+	return hash
+}
+
+// validateWebAuthnAKCert checks the TPM attestation certificate requirements
+// of the AK certificate, checks its validity period and chains it to the
+// TrustedRootCerts.
+func validateWebAuthnAKCert(result *WebAuthnTPMAttestation, opts WebAuthnOpts, x5cChain []*x509.Certificate) error {
+	akCert := result.AKCert
+	if akCert.Version != 3 {
+		return fmt.Errorf("AK certificate version %d, expected 3", akCert.Version)
+	}
+	if len(akCert.Subject.Names) != 0 {
+		return errors.New("AK certificate subject is not empty")
+	}
+	if !akCert.BasicConstraintsValid || akCert.IsCA {
+		return errors.New("AK certificate is not an end-entity certificate")
+	}
+	hasAIKUsage := false
+	for _, usage := range akCert.UnknownExtKeyUsage {
+		if usage.Equal(oidTCGKpAIKCertificate) {
+			hasAIKUsage = true
+		}
+	}
+	if !hasAIKUsage {
+		return errors.New("AK certificate is missing the tcg-kp-AIKCertificate extended key usage")
+	}
+
+	for _, ext := range akCert.Extensions {
+		switch {
+		case ext.Id.Equal(oidExtensionSubjectAltName):
+			if err := parseTPMSubjectAltName(ext.Value, result); err != nil {
+				return err
+			}
+		case ext.Id.Equal(oidFIDOGenCEAAGUID):
+			var aaguid []byte
+			if rest, err := asn1.Unmarshal(ext.Value, &aaguid); err != nil || len(rest) != 0 {
+				return errors.New("malformed AAGUID extension of the AK certificate")
+			}
+			if ext.Critical || !bytes.Equal(aaguid, result.AAGUID) {
+				return errors.New("AK certificate AAGUID does not match the authenticator data")
+			}
+		}
+	}
+	if result.TPMManufacturer == "" || result.TPMModel == "" || result.TPMVersion == "" {
+		return errors.New("AK certificate subject alternative name is missing the TPM manufacturer, model or version")
+	}
+
+	verifyTime := opts.VerifyTime
+	if verifyTime.IsZero() {
+		verifyTime = time.Now()
+	}
+	if verifyTime.Before(akCert.NotBefore) || verifyTime.After(akCert.NotAfter) {
+		return fmt.Errorf("AK certificate is not valid at %v: valid from %v to %v", verifyTime, akCert.NotBefore, akCert.NotAfter)
+	}
+
+	if opts.InsecureSkipChainVerification {
+		return nil
+	}
+	if len(opts.TrustedRootCerts) == 0 {
+		return errors.New("no trusted root certificates to verify the AK certificate chain, InsecureSkipChainVerification must be set to skip it")
+	}
+	// The critical subject alternative name was parsed above.
+	chains, err := internal.HandleTPMSubjectAltName(akCert).Verify(x509.VerifyOptions{
+		Roots:         makePool(opts.TrustedRootCerts),
+		Intermediates: makePool(append(x5cChain, opts.IntermediateCerts...)),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		CurrentTime:   verifyTime,
+	})
+	if err != nil {
+		return fmt.Errorf("AK certificate did not chain to a trusted root: %v", err)
+	}
+	result.Chain = chains[0]
+	return nil
+}
+
+// parseTPMSubjectAltName parses the TPM manufacturer, model and version of the
+// directoryName of a subject alternative name extension, see the TCG EK
+// Credential Profile section 3.2.9.
+func parseTPMSubjectAltName(value []byte, result *WebAuthnTPMAttestation) error {
+	var names asn1.RawValue
+	if rest, err := asn1.Unmarshal(value, &names); err != nil || len(rest) != 0 || names.Tag != asn1.TagSequence {
+		return errors.New("malformed subject alternative name of the AK certificate")
+	}
+	for rest := names.Bytes; len(rest) > 0; {
+		var name asn1.RawValue
+		var err error
+		if rest, err = asn1.Unmarshal(rest, &name); err != nil {
+			return errors.New("malformed subject alternative name of the AK certificate")
+		}
+		// directoryName [4] Name
+		if name.Class != asn1.ClassContextSpecific || name.Tag != 4 {
+			continue
+		}
+		var rdns pkix.RDNSequence
+		if _, err := asn1.Unmarshal(name.Bytes, &rdns); err != nil {
+			return errors.New("malformed directory name of the AK certificate")
+		}
+		for _, rdn := range rdns {
+			for _, atv := range rdn {
+				value, ok := atv.Value.(string)
+				if !ok {
+					continue
+				}
+				switch {
+				case atv.Type.Equal(oidTPMManufacturer):
+					result.TPMManufacturer = value
+				case atv.Type.Equal(oidTPMModel):
+					result.TPMModel = value
+				case atv.Type.Equal(oidTPMVersion):
+					result.TPMVersion = value
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+package server
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm/tpm2"
+)
+
+var testAAGUID = bytes.Repeat([]byte{0x08}, 16)
+
+// webAuthnTestCA issues AK certificates for the WebAuthn tests.
+type webAuthnTestCA struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+func newWebAuthnTestCA(t *testing.T) *webAuthnTestCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test TPM Root CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &webAuthnTestCA{cert: cert, key: key}
+}
+
+// tpmSubjectAltName returns the critical SAN extension of an AK certificate
+// with the directoryName of a TPM.
+func tpmSubjectAltName(t *testing.T) pkix.Extension {
+	t.Helper()
+	name, err := asn1.Marshal(pkix.RDNSequence{
+		{{Type: oidTPMManufacturer, Value: "id:FFFFF1D0"}},
+		{{Type: oidTPMModel, Value: "FIDO"}},
+		{{Type: oidTPMVersion, Value: "id:00020065"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dirName, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 4, IsCompound: true, Bytes: name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	san, err := asn1.Marshal(asn1.RawValue{Tag: asn1.TagSequence, IsCompound: true, Bytes: dirName})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkix.Extension{Id: oidExtensionSubjectAltName, Critical: true, Value: san}
+}
+
+func (ca *webAuthnTestCA) issueAKCert(t *testing.T, akPub crypto.PublicKey, modify func(*x509.Certificate)) []byte {
+	t.Helper()
+	aaguid, err := asn1.Marshal(testAAGUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		UnknownExtKeyUsage:    []asn1.ObjectIdentifier{oidTCGKpAIKCertificate},
+		BasicConstraintsValid: true,
+		ExtraExtensions: []pkix.Extension{
+			tpmSubjectAltName(t),
+			{Id: oidFIDOGenCEAAGUID, Value: aaguid},
+		},
+	}
+	if modify != nil {
+		modify(template)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, akPub, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+// testAuthData returns authenticator data with the attested credential of
+// the ECC public key.
+func testAuthData(t *testing.T, pub *ecdsa.PublicKey) []byte {
+	t.Helper()
+	coseKey, err := cbor.Marshal(map[int]interface{}{
+		coseKeyKty: coseKtyEC2,
+		coseKeyAlg: coseAlgES256,
+		coseKeyCrv: coseCrvP256,
+		coseKeyX:   pub.X.FillBytes(make([]byte, 32)),
+		coseKeyY:   pub.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentialID := []byte("credential")
+	rpIDHash := sha256.Sum256([]byte("example.com"))
+	authData := append(rpIDHash[:], 0x41, 0, 0, 0, 0)
+	authData = append(authData, testAAGUID...)
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(credentialID)))
+	authData = append(authData, credentialID...)
+	return append(authData, coseKey...)
+}
+
+type webAuthnTestCase struct {
+	authData       []byte
+	clientDataHash []byte
+	stmt           WebAuthnTPMStatement
+}
+
+func (tc webAuthnTestCase) attestationObject(t *testing.T) []byte {
+	t.Helper()
+	stmt, err := cbor.Marshal(tc.stmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj, err := cbor.Marshal(webAuthnAttestationObject{Fmt: tpmStmtFormat, AttStmt: stmt, AuthData: tc.authData})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return obj
+}
+
+// newWebAuthnTestCase certifies a credential key with an AK of the TPM, as
+// Windows Hello does on registration.
+func newWebAuthnTestCase(t *testing.T, ca *webAuthnTestCA, modifyAKCert func(*x509.Certificate)) webAuthnTestCase {
+	t.Helper()
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ak, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	credential, err := client.NewKey(rwc, tpm2.HandleNull, client.AKTemplateECC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer credential.Close()
+
+	tc := webAuthnTestCase{
+		authData:       testAuthData(t, credential.PublicKey().(*ecdsa.PublicKey)),
+		clientDataHash: bytes.Repeat([]byte{0x01}, sha256.Size),
+	}
+	extraData := sha256.Sum256(append(append([]byte{}, tc.authData...), tc.clientDataHash...))
+	certInfo, sig, err := tpm2.Certify(rwc, "", "", credential.Handle(), ak.Handle(), extraData[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubArea, err := credential.PublicArea().Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc.stmt = WebAuthnTPMStatement{
+		Ver:      tpmStmtVer,
+		Alg:      coseAlgRS256,
+		X5C:      [][]byte{ca.issueAKCert(t, ak.PublicKey(), modifyAKCert)},
+		Sig:      sig,
+		CertInfo: certInfo,
+		PubArea:  pubArea,
+	}
+	return tc
+}
+
+func TestVerifyWebAuthnTPMAttestation(t *testing.T) {
+	ca := newWebAuthnTestCA(t)
+	tc := newWebAuthnTestCase(t, ca, nil)
+
+	result, err := VerifyWebAuthnTPMAttestation(tc.attestationObject(t), tc.clientDataHash, WebAuthnOpts{TrustedRootCerts: []*x509.Certificate{ca.cert}})
+	if err != nil {
+		t.Fatalf("VerifyWebAuthnTPMAttestation() failed: %v", err)
+	}
+	if !bytes.Equal(result.AAGUID, testAAGUID) || string(result.CredentialID) != "credential" {
+		t.Errorf("got AAGUID %x and credential ID %q", result.AAGUID, result.CredentialID)
+	}
+	if result.TPMManufacturer != "id:FFFFF1D0" || result.TPMModel != "FIDO" || result.TPMVersion != "id:00020065" {
+		t.Errorf("got TPM %q %q %q", result.TPMManufacturer, result.TPMModel, result.TPMVersion)
+	}
+	if len(result.Chain) != 2 || !result.Chain[1].Equal(ca.cert) {
+		t.Errorf("got a chain of %d certificates, want the AK and root certificates", len(result.Chain))
+	}
+
+	// Without trusted roots, the chain is only skipped when asked to.
+	if _, err := VerifyWebAuthnTPMAttestation(tc.attestationObject(t), tc.clientDataHash, WebAuthnOpts{}); err == nil {
+		t.Error("VerifyWebAuthnTPMAttestation() without roots succeeded")
+	}
+	result, err = VerifyWebAuthnTPMAttestation(tc.attestationObject(t), tc.clientDataHash, WebAuthnOpts{InsecureSkipChainVerification: true})
+	if err != nil {
+		t.Fatalf("VerifyWebAuthnTPMAttestation() skipping the chain failed: %v", err)
+	}
+	if result.Chain != nil {
+		t.Error("got a chain when skipping its verification")
+	}
+	expired := WebAuthnOpts{InsecureSkipChainVerification: true, VerifyTime: time.Now().Add(2 * time.Hour)}
+	if _, err := VerifyWebAuthnTPMAttestation(tc.attestationObject(t), tc.clientDataHash, expired); err == nil {
+		t.Error("VerifyWebAuthnTPMAttestation() skipping the chain succeeded with an expired AK certificate")
+	}
+}
+
+func TestVerifyWebAuthnTPMAttestationFailure(t *testing.T) {
+	ca := newWebAuthnTestCA(t)
+	valid := newWebAuthnTestCase(t, ca, nil)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		tc      func() webAuthnTestCase
+		wantErr string
+	}{
+		{"WrongClientDataHash", func() webAuthnTestCase {
+			tc := valid
+			tc.clientDataHash = bytes.Repeat([]byte{0x02}, sha256.Size)
+			return tc
+		}, "extraData"},
+		{"OtherCredentialKey", func() webAuthnTestCase {
+			tc := valid
+			tc.authData = testAuthData(t, &otherKey.PublicKey)
+			return tc
+		}, "credential public key"},
+		{"WrongAlgorithm", func() webAuthnTestCase {
+			tc := valid
+			tc.stmt.Alg = coseAlgES256
+			return tc
+		}, "algorithm"},
+		{"SHA1Algorithm", func() webAuthnTestCase {
+			tc := valid
+			tc.stmt.Alg = -65535
+			return tc
+		}, "unsupported"},
+		{"WrongVersion", func() webAuthnTestCase {
+			tc := valid
+			tc.stmt.Ver = "1.2"
+			return tc
+		}, "version"},
+		{"MissingX5C", func() webAuthnTestCase {
+			tc := valid
+			tc.stmt.X5C = nil
+			return tc
+		}, "x5c"},
+		{"MissingAIKUsage", func() webAuthnTestCase {
+			return newWebAuthnTestCase(t, ca, func(c *x509.Certificate) { c.UnknownExtKeyUsage = nil })
+		}, "tcg-kp-AIKCertificate"},
+		{"SubjectNotEmpty", func() webAuthnTestCase {
+			return newWebAuthnTestCase(t, ca, func(c *x509.Certificate) { c.Subject = pkix.Name{CommonName: "AK"} })
+		}, "subject"},
+		{"CACertificate", func() webAuthnTestCase {
+			return newWebAuthnTestCase(t, ca, func(c *x509.Certificate) { c.IsCA = true })
+		}, "end-entity"},
+		{"OtherAAGUID", func() webAuthnTestCase {
+			return newWebAuthnTestCase(t, ca, func(c *x509.Certificate) {
+				aaguid, _ := asn1.Marshal(bytes.Repeat([]byte{0x09}, 16))
+				c.ExtraExtensions = []pkix.Extension{tpmSubjectAltName(t), {Id: oidFIDOGenCEAAGUID, Value: aaguid}}
+			})
+		}, "AAGUID"},
+		{"MissingSubjectAltName", func() webAuthnTestCase {
+			return newWebAuthnTestCase(t, ca, func(c *x509.Certificate) { c.ExtraExtensions = nil })
+		}, "TPM manufacturer"},
+		{"UntrustedRoot", func() webAuthnTestCase {
+			return newWebAuthnTestCase(t, newWebAuthnTestCA(t), nil)
+		}, "trusted root"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tc := test.tc()
+			_, err := VerifyWebAuthnTPMAttestation(tc.attestationObject(t), tc.clientDataHash, WebAuthnOpts{TrustedRootCerts: []*x509.Certificate{ca.cert}})
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("VerifyWebAuthnTPMAttestation() got error %v, want an error containing %q", err, test.wantErr)
+			}
+		})
+	}
+
+	if _, err := VerifyWebAuthnTPMAttestation([]byte("not cbor"), valid.clientDataHash, WebAuthnOpts{}); err == nil {
+		t.Error("VerifyWebAuthnTPMAttestation() succeeded with a malformed attestation object")
+	}
+}
@@ -0,0 +1,113 @@
+package simulator
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+// A Fault is injected into the execution of a command by the Simulator, to
+// test how TPM clients handle misbehaving TPMs. It is given the command buffer
+// and a function executing it, and returns the response of the command.
+type Fault func(command []byte, run func() ([]byte, error)) ([]byte, error)
+
+// Sizes of the command and response headers: a tag, a size, and a command or
+// response code.
+const (
+	commandHeaderSize  = 10
+	responseHeaderSize = 10
+)
+
+// InjectFault injects fault into all the subsequent executions of the command
+// with the command code cc, replacing any fault previously injected into it.
+// A nil fault removes the fault of the command.
+func (s *Simulator) InjectFault(cc tpmutil.Command, fault Fault) {
+	s.faultsMu.Lock()
+	defer s.faultsMu.Unlock()
+	if fault == nil {
+		delete(s.faults, cc)
+		return
+	}
+	if s.faults == nil {
+		s.faults = make(map[tpmutil.Command]Fault)
+	}
+	s.faults[cc] = fault
+}
+
+// ClearFaults removes all the faults injected with InjectFault.
+func (s *Simulator) ClearFaults() {
+	s.faultsMu.Lock()
+	defer s.faultsMu.Unlock()
+	s.faults = nil
+}
+
+// fault returns the fault injected into the command, if any.
+func (s *Simulator) fault(command []byte) Fault {
+	s.faultsMu.Lock()
+	defer s.faultsMu.Unlock()
+	if len(s.faults) == 0 || len(command) < commandHeaderSize {
+		return nil
+	}
+	return s.faults[tpmutil.Command(binary.BigEndian.Uint32(command[6:]))]
+}
+
+// FaultResponseCode returns a Fault responding to the command with the
+// response code rc, without executing it.
+func FaultResponseCode(rc tpmutil.ResponseCode) Fault {
+	return func([]byte, func() ([]byte, error)) ([]byte, error) {
+		resp := make([]byte, responseHeaderSize)
+		binary.BigEndian.PutUint16(resp[0:], uint16(tpm2.TagNoSessions))
+		binary.BigEndian.PutUint32(resp[2:], responseHeaderSize)
+		binary.BigEndian.PutUint32(resp[6:], uint32(rc))
+		return resp, nil
+	}
+}
+
+// FaultRetry returns a Fault responding to the command with TPM_RC_RETRY,
+// as a busy TPM does.
+func FaultRetry() Fault {
+	return FaultResponseCode(tpmutil.RCRetry)
+}
+
+// FaultCorruptResponse returns a Fault executing the command, and replacing
+// its response with the result of corrupt.
+func FaultCorruptResponse(corrupt func(response []byte) []byte) Fault {
+	return func(_ []byte, run func() ([]byte, error)) ([]byte, error) {
+		resp, err := run()
+		if err != nil {
+			return nil, err
+		}
+		return corrupt(resp), nil
+	}
+}
+
+// FaultLatency returns a Fault executing the command after waiting for d, as a
+// slow TPM does.
+func FaultLatency(d time.Duration) Fault {
+	return func(_ []byte, run func() ([]byte, error)) ([]byte, error) {
+		time.Sleep(d)
+		return run()
+	}
+}
+
+// FaultTimes returns a Fault injecting fault into the next n executions of
+// the command only, e.g. to test that a client retries a command until it
+// succeeds.
+func FaultTimes(n int, fault Fault) Fault {
+	var mu sync.Mutex
+	return func(command []byte, run func() ([]byte, error)) ([]byte, error) {
+		mu.Lock()
+		inject := n > 0
+		if inject {
+			n--
+		}
+		mu.Unlock()
+		if !inject {
+			return run()
+		}
+		return fault(command, run)
+	}
+}
@@ -0,0 +1,128 @@
+package simulator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm/tpm2"
+)
+
+// countExecutions returns a Fault counting the executions of the command.
+func countExecutions(count *int) Fault {
+	return func(_ []byte, run func() ([]byte, error)) ([]byte, error) {
+		*count++
+		return run()
+	}
+}
+
+func TestFaultRetry(t *testing.T) {
+	s := getSimulator(t)
+	defer client.CheckedClose(t, s)
+
+	attempts := 0
+	retry := FaultTimes(2, FaultRetry())
+	s.InjectFault(tpm2.CmdGetRandom, func(command []byte, run func() ([]byte, error)) ([]byte, error) {
+		attempts++
+		return retry(command, run)
+	})
+	// tpmutil.RunCommand retries the command while the TPM is busy.
+	if _, err := tpm2.GetRandom(s, 16); err != nil {
+		t.Fatalf("GetRandom: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("GetRandom was attempted %d times, want 3", attempts)
+	}
+}
+
+func TestFaultResponseCode(t *testing.T) {
+	s := getSimulator(t)
+	defer client.CheckedClose(t, s)
+
+	s.InjectFault(tpm2.CmdGetRandom, FaultResponseCode(0x101))
+	_, err := tpm2.GetRandom(s, 16)
+	var tpmErr tpm2.Error
+	if !errors.As(err, &tpmErr) || tpmErr.Code != tpm2.RCFailure {
+		t.Errorf("got GetRandom error %v, want TPM_RC_FAILURE", err)
+	}
+}
+
+func TestFaultCorruptResponse(t *testing.T) {
+	s := getSimulator(t)
+	defer client.CheckedClose(t, s)
+
+	executed := 0
+	s.InjectFault(tpm2.CmdGetRandom, FaultCorruptResponse(func(response []byte) []byte {
+		executed++
+		return response[:len(response)-1]
+	}))
+	if _, err := tpm2.GetRandom(s, 16); err == nil {
+		t.Error("GetRandom succeeded with a truncated response")
+	}
+	if executed != 1 {
+		t.Errorf("GetRandom was executed %d times, want 1", executed)
+	}
+}
+
+func TestFaultLatency(t *testing.T) {
+	s := getSimulator(t)
+	defer client.CheckedClose(t, s)
+
+	const latency = 50 * time.Millisecond
+	s.InjectFault(tpm2.CmdGetRandom, FaultLatency(latency))
+	start := time.Now()
+	if _, err := tpm2.GetRandom(s, 16); err != nil {
+		t.Fatalf("GetRandom: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < latency {
+		t.Errorf("GetRandom took %v, want at least %v", elapsed, latency)
+	}
+}
+
+func TestRemoveFaults(t *testing.T) {
+	s := getSimulator(t)
+	defer client.CheckedClose(t, s)
+
+	executions := 0
+	s.InjectFault(tpm2.CmdGetRandom, FaultResponseCode(0x101))
+	s.InjectFault(tpm2.CmdGetRandom, countExecutions(&executions))
+	s.InjectFault(tpm2.CmdQuote, FaultResponseCode(0x101))
+	if _, err := tpm2.GetRandom(s, 16); err != nil {
+		t.Fatalf("GetRandom: %v", err)
+	}
+	if executions != 1 {
+		t.Errorf("the replacing fault was injected %d times, want 1", executions)
+	}
+
+	s.InjectFault(tpm2.CmdGetRandom, nil)
+	if _, err := tpm2.GetRandom(s, 16); err != nil {
+		t.Fatalf("GetRandom: %v", err)
+	}
+	if executions != 1 {
+		t.Error("the removed fault is still injected")
+	}
+
+	s.InjectFault(tpm2.CmdGetRandom, FaultRetry())
+	s.ClearFaults()
+	if _, err := tpm2.GetRandom(s, 16); err != nil {
+		t.Fatalf("GetRandom after ClearFaults: %v", err)
+	}
+}
+
+func TestFaultTimes(t *testing.T) {
+	injected := 0
+	fault := FaultTimes(2, func(_ []byte, run func() ([]byte, error)) ([]byte, error) {
+		injected++
+		return run()
+	})
+	run := func() ([]byte, error) { return nil, nil }
+	for i := 0; i < 4; i++ {
+		if _, err := fault(nil, run); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if injected != 2 {
+		t.Errorf("the fault was injected %d times, want 2", injected)
+	}
+}
@@ -205,3 +205,100 @@
 #include "support/ResponseCodeProcessing.c"
 #include "support/TpmFail.c"
 #include "support/TpmSizeChecks.c"
+
+// The simulator keeps all of its state in the global variables below, which
+// are only visible in this file. Copying them in and out of a buffer lets us
+// snapshot and restore the TPM, and swap between multiple TPM instances.
+// Pointers into the state (s_usedSessions, s_cachedNvRamRef) are not saved, as
+// they are only valid while processing a single command.
+#if CLOCK_STOPS
+#define CLOCK_STATE(X) X(g_timeEpoch)
+#else
+#define CLOCK_STATE(X)
+#endif
+#ifdef TPM_CC_GetCommandAuditDigest
+#define AUDIT_STATE(X) X(s_cpHashForCommandAudit)
+#else
+#define AUDIT_STATE(X)
+#endif
+#if !ACCUMULATE_SELF_HEAL_TIMER
+#define SELF_HEAL_STATE(X) X(s_selfHealTimer) X(s_lockoutTimer)
+#else
+#define SELF_HEAL_STATE(X)
+#endif
+
+#define SIMULATOR_STATE(X)                                                    \
+  X(s_NV)                                                                     \
+  X(s_adjustRate)                                                             \
+  X(s_timerReset)                                                             \
+  X(s_realTimePrevious)                                                       \
+  X(s_tpmTime)                                                                \
+  X(s_lastSystemTime)                                                         \
+  X(s_lastReportedTime)                                                       \
+  X(g_implementedAlgorithms)                                                  \
+  X(g_toTest)                                                                 \
+  X(g_exclusiveAuditSession)                                                  \
+  X(g_time)                                                                   \
+  CLOCK_STATE(X)                                                              \
+  X(g_phEnable)                                                               \
+  X(g_pcrReConfig)                                                            \
+  X(g_DRTMHandle)                                                             \
+  X(g_DrtmPreStartup)                                                         \
+  X(g_StartupLocality3)                                                       \
+  X(g_updateNV)                                                               \
+  X(g_powerWasLost)                                                           \
+  X(g_clearOrderly)                                                           \
+  X(g_prevOrderlyState)                                                       \
+  X(g_nvOk)                                                                   \
+  X(g_NvStatus)                                                               \
+  X(gp)                                                                       \
+  X(go)                                                                       \
+  X(gc)                                                                       \
+  X(gr)                                                                       \
+  X(g_cryptoSelfTestState)                                                    \
+  X(g_manufactured)                                                           \
+  X(g_initialized)                                                            \
+  X(s_sessionHandles)                                                         \
+  X(s_attributes)                                                             \
+  X(s_associatedHandles)                                                      \
+  X(s_nonceCaller)                                                            \
+  X(s_inputAuthValues)                                                        \
+  X(s_encryptSessionIndex)                                                    \
+  X(s_decryptSessionIndex)                                                    \
+  X(s_auditSessionIndex)                                                      \
+  AUDIT_STATE(X)                                                              \
+  X(s_DAPendingOnNV)                                                          \
+  SELF_HEAL_STATE(X)                                                          \
+  X(s_evictNvEnd)                                                             \
+  X(s_indexOrderlyRam)                                                        \
+  X(s_maxCounter)                                                             \
+  X(s_objects)                                                                \
+  X(s_pcrs)                                                                   \
+  X(s_sessions)                                                               \
+  X(s_oldestSavedSession)                                                     \
+  X(s_freeSessionSlots)                                                       \
+  X(s_actionIoBuffer)                                                         \
+  X(s_actionIoAllocation)                                                     \
+  X(g_inFailureMode)                                                          \
+  X(s_failFunction)                                                           \
+  X(s_failLine)                                                               \
+  X(s_failCode)
+
+#define STATE_SIZE(v) +sizeof(v)
+#define STATE_SAVE(v)                                                         \
+  memcpy(buf, &(v), sizeof(v));                                               \
+  buf += sizeof(v);
+#define STATE_RESTORE(v)                                                      \
+  memcpy(&(v), buf, sizeof(v));                                               \
+  buf += sizeof(v);
+
+size_t _go_state_size(void) { return 0 SIMULATOR_STATE(STATE_SIZE); }
+
+void _go_state_save(uint8_t *buf) { SIMULATOR_STATE(STATE_SAVE) }
+
+void _go_state_restore(const uint8_t *buf) {
+  SIMULATOR_STATE(STATE_RESTORE)
+  // The cached NV Index refers to the NV memory before the restore.
+  s_cachedNvRef = NV_REF_INIT;
+  s_cachedNvRamRef = NULL;
+}
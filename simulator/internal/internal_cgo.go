@@ -49,6 +49,16 @@ package internal
 //     NV_SYNC_PERSISTENT(SPSeed);
 //     NV_SYNC_PERSISTENT(PPSeed);
 // }
+//
+// // The orderly state "go" cannot be referenced from Go.
+// uint8_t *drbg_seed() {
+//     return go.drbgState.seed.bytes;
+// }
+//
+// // Defined in include.c, where the simulator's global state is visible.
+// size_t _go_state_size(void);
+// void _go_state_save(uint8_t *buf);
+// void _go_state_restore(const uint8_t *buf);
 import "C"
 import (
 	"errors"
@@ -56,12 +66,37 @@ import (
 	"unsafe"
 )
 
-// SetSeeds uses the output of r to reset the 3 TPM simulator seeds.
+// SetSeeds uses the output of r to reset the 3 TPM simulator seeds and the seed
+// of the simulator's random number generator.
 func SetSeeds(r io.Reader) {
 	// The first two bytes of the seed encode the size (so we don't overwrite)
 	r.Read(C.gp.EPSeed[2:])
 	r.Read(C.gp.SPSeed[2:])
 	r.Read(C.gp.PPSeed[2:])
+	C.sync_seeds()
+	seed := (*[C.DRBG_SEED_SIZE_BYTES]byte)(unsafe.Pointer(C.drbg_seed()))
+	r.Read(seed[:])
+}
+
+// StateSize returns the size of the state returned by SaveState.
+func StateSize() int {
+	return int(C._go_state_size())
+}
+
+// SaveState returns a copy of the full state of the simulator.
+func SaveState() []byte {
+	state := make([]byte, StateSize())
+	C._go_state_save((*C.uint8_t)(&state[0]))
+	return state
+}
+
+// RestoreState replaces the state of the simulator with a state previously
+// returned by SaveState. The state must have a length of StateSize().
+func RestoreState(state []byte) {
+	if len(state) != StateSize() {
+		panic("simulator state has the wrong size")
+	}
+	C._go_state_restore((*C.uint8_t)(&state[0]))
 }
 
 // Reset simulates toggling the power the TPM. If forceManufacture is true,
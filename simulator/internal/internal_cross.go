@@ -11,6 +11,15 @@ import (
 // SetSeeds does nothing
 func SetSeeds(r io.Reader) {}
 
+// StateSize returns zero
+func StateSize() int { return 0 }
+
+// SaveState returns nil
+func SaveState() []byte { return nil }
+
+// RestoreState does nothing
+func RestoreState(state []byte) {}
+
 // Reset does nothing
 func Reset(forceManufacture bool) {}
 
@@ -0,0 +1,241 @@
+// Package remote connects to TPM simulators running in another process over
+// TCP, so they can be used like any other TPM.
+//
+// Two protocols are supported: the protocol of the Microsoft TPM 2.0 reference
+// simulator (mssim), also implemented by the IBM TPM simulator (tpm_server),
+// and the protocol of swtpm started in socket mode with a TCP server.
+package remote
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/google/go-tpm/tpm2"
+)
+
+// Protocol is the protocol spoken by the TPM simulator.
+type Protocol string
+
+// Supported protocols.
+const (
+	// MSSIM is the protocol of the Microsoft TPM 2.0 reference simulator.
+	// Commands are framed with a header, and a separate platform port is used
+	// to power the TPM on and off.
+	MSSIM Protocol = "mssim"
+	// SWTPM is the protocol of "swtpm socket --server type=tcp". Commands are
+	// sent unframed, and a separate control port (--ctrl type=tcp) is used to
+	// initialize the TPM.
+	SWTPM Protocol = "swtpm"
+)
+
+// Default ports of the command and control channels of both simulators.
+const (
+	DefaultAddress        = "localhost:2321"
+	DefaultControlAddress = "localhost:2322"
+)
+
+// Constants from the TPM 2.0 Library Part 4, "TpmTcpProtocol.h".
+const (
+	mssimSignalPowerOn  uint32 = 1
+	mssimSignalPowerOff uint32 = 2
+	mssimSendCommand    uint32 = 8
+	mssimSignalNVOn     uint32 = 11
+	mssimSessionEnd     uint32 = 20
+)
+
+// Constants from swtpm's "tpm_ioctl.h".
+const (
+	swtpmCmdInit uint32 = 2
+)
+
+// Responses start with a tag, the size of the response and a response code.
+const responseHeaderSize = 10
+
+// maxResponseSize bounds the size of responses, well above the response size
+// of any TPM.
+const maxResponseSize = 1 << 16
+
+// Config describes how to connect to a TPM simulator.
+type Config struct {
+	// Protocol spoken by the simulator, defaults to MSSIM.
+	Protocol Protocol
+	// Address of the command (or data) channel, defaults to DefaultAddress.
+	Address string
+	// ControlAddress of the platform (MSSIM) or control (SWTPM) channel. If
+	// set, Open power cycles the TPM, as if the host computer had rebooted.
+	// Otherwise, the state of the TPM is left untouched.
+	ControlAddress string
+}
+
+// Conn is a connection to a TPM simulator. It can be used with all the TPM
+// APIs taking an io.ReadWriter or io.ReadWriteCloser. Like the in-process
+// simulator, each command must be sent with a single call to Write, and its
+// response is then available to Read.
+type Conn struct {
+	conn     net.Conn
+	protocol Protocol
+	buf      bytes.Buffer
+}
+
+// Open connects to the TPM simulator described by config, and starts the TPM
+// with TPM2_Startup if it has not been started yet.
+func Open(config Config) (*Conn, error) {
+	if config.Protocol == "" {
+		config.Protocol = MSSIM
+	}
+	if config.Protocol != MSSIM && config.Protocol != SWTPM {
+		return nil, fmt.Errorf("unsupported simulator protocol %q", config.Protocol)
+	}
+	if config.Address == "" {
+		config.Address = DefaultAddress
+	}
+	if config.ControlAddress != "" {
+		if err := powerCycle(config.Protocol, config.ControlAddress); err != nil {
+			return nil, err
+		}
+	}
+
+	conn, err := net.Dial("tcp", config.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to simulator: %w", err)
+	}
+	c := &Conn{conn: conn, protocol: config.Protocol}
+	// TPM2_Startup fails with TPM_RC_INITIALIZE if the TPM is already started.
+	if err := tpm2.Startup(c, tpm2.StartupClear); err != nil && !isInitialized(err) {
+		c.Close()
+		return nil, fmt.Errorf("startup: %w", err)
+	}
+	return c, nil
+}
+
+func isInitialized(err error) bool {
+	var tpmErr tpm2.Error
+	return errors.As(err, &tpmErr) && tpmErr.Code == tpm2.RCInitialize
+}
+
+// powerCycle turns the TPM off and on again with the simulator's control
+// channel. The TPM then needs to be started with TPM2_Startup.
+func powerCycle(protocol Protocol, address string) error {
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to simulator control channel: %w", err)
+	}
+	defer conn.Close()
+
+	if protocol == SWTPM {
+		// CMD_INIT takes flags, and returns a TPM_RESULT.
+		return controlCommand(conn, swtpmCmdInit, 0)
+	}
+	for _, cmd := range []uint32{mssimSignalPowerOff, mssimSignalPowerOn, mssimSignalNVOn} {
+		if err := controlCommand(conn, cmd); err != nil {
+			return err
+		}
+	}
+	return binary.Write(conn, binary.BigEndian, mssimSessionEnd)
+}
+
+// controlCommand sends a command with its arguments, and checks the returned
+// result code.
+func controlCommand(conn net.Conn, cmd uint32, args ...uint32) error {
+	if err := binary.Write(conn, binary.BigEndian, append([]uint32{cmd}, args...)); err != nil {
+		return fmt.Errorf("failed to send control command %d: %w", cmd, err)
+	}
+	var rc uint32
+	if err := binary.Read(conn, binary.BigEndian, &rc); err != nil {
+		return fmt.Errorf("failed to read control command %d result: %w", cmd, err)
+	}
+	if rc != 0 {
+		return fmt.Errorf("control command %d failed: 0x%x", cmd, rc)
+	}
+	return nil
+}
+
+// Write sends the command to the simulator and receives its response, which
+// can then be retrieved with Read.
+func (c *Conn) Write(command []byte) (int, error) {
+	var req bytes.Buffer
+	if c.protocol == MSSIM {
+		// Commands are sent with the locality and their size.
+		binary.Write(&req, binary.BigEndian, mssimSendCommand)
+		req.WriteByte(0)
+		binary.Write(&req, binary.BigEndian, uint32(len(command)))
+	}
+	req.Write(command)
+	if _, err := req.WriteTo(c.conn); err != nil {
+		return 0, fmt.Errorf("failed to send command: %w", err)
+	}
+
+	var resp []byte
+	var err error
+	if c.protocol == MSSIM {
+		resp, err = c.readMSSIMResponse()
+	} else {
+		resp, err = c.readResponse()
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to receive response: %w", err)
+	}
+	c.buf.Reset()
+	c.buf.Write(resp)
+	return len(command), nil
+}
+
+// readMSSIMResponse reads a response framed with its size, and followed by a
+// zero acknowledgement.
+func (c *Conn) readMSSIMResponse() ([]byte, error) {
+	var size uint32
+	if err := binary.Read(c.conn, binary.BigEndian, &size); err != nil {
+		return nil, err
+	}
+	if size > maxResponseSize {
+		return nil, fmt.Errorf("response of %d bytes is too large", size)
+	}
+	resp := make([]byte, size)
+	if _, err := io.ReadFull(c.conn, resp); err != nil {
+		return nil, err
+	}
+	var ack uint32
+	if err := binary.Read(c.conn, binary.BigEndian, &ack); err != nil {
+		return nil, err
+	}
+	if ack != 0 {
+		return nil, fmt.Errorf("unexpected acknowledgement 0x%x", ack)
+	}
+	return resp, nil
+}
+
+// readResponse reads an unframed response, using the size in its header.
+func (c *Conn) readResponse() ([]byte, error) {
+	header := make([]byte, responseHeaderSize)
+	if _, err := io.ReadFull(c.conn, header); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[2:])
+	if size < responseHeaderSize || size > maxResponseSize {
+		return nil, fmt.Errorf("invalid response size %d", size)
+	}
+	resp := make([]byte, size)
+	copy(resp, header)
+	if _, err := io.ReadFull(c.conn, resp[responseHeaderSize:]); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Read gets the response of the command previously sent with Write.
+func (c *Conn) Read(response []byte) (int, error) {
+	return c.buf.Read(response)
+}
+
+// Close closes the connection to the simulator, leaving the simulator running.
+func (c *Conn) Close() error {
+	if c.protocol == MSSIM {
+		// Ask the simulator to end the session, so it waits for the next one.
+		binary.Write(c.conn, binary.BigEndian, mssimSessionEnd)
+	}
+	return c.conn.Close()
+}
@@ -0,0 +1,220 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/simulator"
+	"github.com/google/go-tpm/tpm2"
+)
+
+// server exposes an in-process simulator over the protocol.
+type server struct {
+	t        *testing.T
+	protocol Protocol
+	sim      *simulator.Simulator
+	cmd      net.Listener
+	ctrl     net.Listener
+}
+
+func newServer(t *testing.T, protocol Protocol) *server {
+	t.Helper()
+	sim, err := simulator.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &server{t: t, protocol: protocol, sim: sim}
+	if s.cmd, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	if s.ctrl, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	go s.accept(s.cmd, s.serveCommands)
+	go s.accept(s.ctrl, s.serveControl)
+	t.Cleanup(func() {
+		s.cmd.Close()
+		s.ctrl.Close()
+		client.CheckedClose(t, sim)
+	})
+	return s
+}
+
+func (s *server) config(powerCycle bool) Config {
+	config := Config{Protocol: s.protocol, Address: s.cmd.Addr().String()}
+	if powerCycle {
+		config.ControlAddress = s.ctrl.Addr().String()
+	}
+	return config
+}
+
+// accept serves one connection at a time, like the simulators.
+func (s *server) accept(l net.Listener, serve func(net.Conn) error) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		if err := serve(conn); err != nil && err != io.EOF {
+			s.t.Errorf("server failed: %v", err)
+		}
+		conn.Close()
+	}
+}
+
+func (s *server) serveCommands(conn net.Conn) error {
+	for {
+		var cmd []byte
+		if s.protocol == MSSIM {
+			var code, size uint32
+			var locality uint8
+			if err := binary.Read(conn, binary.BigEndian, &code); err != nil {
+				return err
+			}
+			if code == mssimSessionEnd {
+				return nil
+			}
+			if err := binary.Read(conn, binary.BigEndian, &locality); err != nil {
+				return err
+			}
+			if err := binary.Read(conn, binary.BigEndian, &size); err != nil {
+				return err
+			}
+			cmd = make([]byte, size)
+			if _, err := io.ReadFull(conn, cmd); err != nil {
+				return err
+			}
+		} else {
+			// Commands have the same header layout as responses.
+			header := make([]byte, responseHeaderSize)
+			if _, err := io.ReadFull(conn, header); err != nil {
+				return err
+			}
+			cmd = make([]byte, binary.BigEndian.Uint32(header[2:]))
+			copy(cmd, header)
+			if _, err := io.ReadFull(conn, cmd[responseHeaderSize:]); err != nil {
+				return err
+			}
+		}
+
+		if _, err := s.sim.Write(cmd); err != nil {
+			return err
+		}
+		resp := make([]byte, maxResponseSize)
+		n, err := s.sim.Read(resp)
+		if err != nil {
+			return err
+		}
+		var out bytes.Buffer
+		if s.protocol == MSSIM {
+			binary.Write(&out, binary.BigEndian, uint32(n))
+		}
+		out.Write(resp[:n])
+		if s.protocol == MSSIM {
+			binary.Write(&out, binary.BigEndian, uint32(0))
+		}
+		if _, err := out.WriteTo(conn); err != nil {
+			return err
+		}
+	}
+}
+
+// serveControl power cycles the simulator on CMD_INIT or on the MSSIM power
+// off signal.
+func (s *server) serveControl(conn net.Conn) error {
+	for {
+		var cmd uint32
+		if err := binary.Read(conn, binary.BigEndian, &cmd); err != nil {
+			return err
+		}
+		switch {
+		case s.protocol == MSSIM && cmd == mssimSessionEnd:
+			return nil
+		case s.protocol == SWTPM && cmd == swtpmCmdInit:
+			var flags uint32
+			if err := binary.Read(conn, binary.BigEndian, &flags); err != nil {
+				return err
+			}
+			fallthrough
+		case s.protocol == MSSIM && cmd == mssimSignalPowerOff:
+			if err := s.sim.Reset(); err != nil {
+				return err
+			}
+		}
+		if err := binary.Write(conn, binary.BigEndian, uint32(0)); err != nil {
+			return err
+		}
+	}
+}
+
+func readPCR(t *testing.T, rw io.ReadWriter) []byte {
+	t.Helper()
+	pcr, err := tpm2.ReadPCR(rw, 16, tpm2.AlgSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pcr
+}
+
+func TestRemote(t *testing.T) {
+	for _, protocol := range []Protocol{MSSIM, SWTPM} {
+		t.Run(string(protocol), func(t *testing.T) {
+			s := newServer(t, protocol)
+
+			conn, err := Open(s.config(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ek, err := client.EndorsementKeyRSA(conn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ek.Close()
+			initial := readPCR(t, conn)
+			if err := tpm2.PCRExtend(conn, 16, tpm2.AlgSHA256, make([]byte, 32), ""); err != nil {
+				t.Fatal(err)
+			}
+			extended := readPCR(t, conn)
+			client.CheckedClose(t, conn)
+
+			// Reconnecting keeps the state of the TPM.
+			conn, err = Open(s.config(false))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pcr := readPCR(t, conn); !bytes.Equal(pcr, extended) {
+				t.Errorf("got PCR %x after reconnecting, want %x", pcr, extended)
+			}
+			client.CheckedClose(t, conn)
+
+			// Power cycling resets the PCRs.
+			conn, err = Open(s.config(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pcr := readPCR(t, conn); !bytes.Equal(pcr, initial) {
+				t.Errorf("got PCR %x after power cycle, want %x", pcr, initial)
+			}
+			client.CheckedClose(t, conn)
+		})
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	if _, err := Open(Config{Protocol: "tcti"}); err == nil {
+		t.Error("Open succeeded with an unknown protocol")
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	if _, err := Open(Config{Address: addr}); err == nil {
+		t.Error("Open succeeded without a simulator")
+	}
+}
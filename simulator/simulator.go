@@ -19,6 +19,7 @@ package simulator
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"math/rand"
@@ -26,50 +27,90 @@ import (
 
 	"github.com/google/go-tpm-tools/simulator/internal"
 	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
 )
 
 // Simulator represents a go-tpm compatible interface to the IBM TPM2 simulator.
 // Similar to the file-based (for linux) or syscall-based (for Windows) TPM
 // handles, no synchronization is provided; the same simulator handle should not
-// be used from multiple threads.
+// be used from multiple threads. Different Simulators are independent TPMs, and
+// can be used concurrently.
 type Simulator struct {
 	buf    bytes.Buffer
 	closed bool
+	// state holds the TPM state of the Simulator while it is not active.
+	state []byte
+	// faults are injected into the commands by InjectFault. Unlike the
+	// commands, they may be changed while another goroutine uses the
+	// Simulator.
+	faultsMu sync.Mutex
+	faults   map[tpmutil.Command]Fault
 }
 
+// Snapshots start with a version and the size of the state that follows.
+const (
+	snapshotVersion    = 1
+	snapshotHeaderSize = 8
+)
+
 // ErrUsingClosedSimulator is returned if any operation on a Simulator is
 // attempted after it is closed.
 var ErrUsingClosedSimulator = errors.New("attempting to use a closed simulator")
 
-// The simulator is a global resource, so we use the variables below to make
-// sure we only ever have one open reference to the Simulator at a time.
-var lock sync.Mutex
+// The simulator code keeps its state in global variables, so only one
+// Simulator can be active at a time. The lock protects the global state, and
+// the state of the active Simulator is swapped out when another one is used.
+var (
+	lock   sync.Mutex
+	active *Simulator
+)
 
-// Get the pointer to an initialized, powered on, and started simulator. As only
-// one simulator may be running at a time, a second call to Get() block until
-// the first Simulator is Closed.
-func Get() (*Simulator, error) {
-	lock.Lock()
+// activate makes s the active Simulator. The lock must be held.
+func (s *Simulator) activate() {
+	if active == s {
+		return
+	}
+	if active != nil {
+		active.state = internal.SaveState()
+	}
+	internal.RestoreState(s.state)
+	s.state = nil
+	active = s
+}
 
+// Get the pointer to an initialized, powered on, and started simulator. Each
+// call to Get() returns a new, independent simulator.
+func Get() (*Simulator, error) {
 	simulator := &Simulator{}
+
+	lock.Lock()
+	if active != nil {
+		active.state = internal.SaveState()
+	}
 	internal.Reset(true)
+	active = simulator
+	lock.Unlock()
+
 	if err := simulator.on(true); err != nil {
-		lock.Unlock()
+		simulator.release()
 		return nil, err
 	}
-	simulator.closed = false
 	return simulator, nil
 }
 
 // GetWithFixedSeedInsecure behaves like Get() expect that all of the internal
-// hierarchy seeds are derived from the input seed. Note that this function
-// compromises the security of the keys/seeds and should only be used for tests.
+// hierarchy seeds, and the seed of the random number generator, are derived
+// from the input seed. Note that this function compromises the security of the
+// keys/seeds and should only be used for tests.
 func GetWithFixedSeedInsecure(seed int64) (*Simulator, error) {
 	s, err := Get()
 	if err != nil {
 		return nil, err
 	}
 
+	lock.Lock()
+	defer lock.Unlock()
+	s.activate()
 	internal.SetSeeds(rand.New(rand.NewSource(seed)))
 	return s, nil
 }
@@ -82,7 +123,7 @@ func (s *Simulator) Reset() error {
 	if err := s.off(); err != nil {
 		return err
 	}
-	internal.Reset(false)
+	s.reset(false)
 	return s.on(false)
 }
 
@@ -95,17 +136,30 @@ func (s *Simulator) ManufactureReset() error {
 	if err := s.off(); err != nil {
 		return err
 	}
-	internal.Reset(true)
+	s.reset(true)
 	return s.on(true)
 }
 
-// Write executes the command specified by commandBuffer. The command response
-// can be retrieved with a subsequent call to Read().
+// Write executes the command specified by commandBuffer, with the fault
+// injected into it by InjectFault if any. The command response can be
+// retrieved with a subsequent call to Read().
 func (s *Simulator) Write(commandBuffer []byte) (int, error) {
 	if s.IsClosed() {
 		return 0, ErrUsingClosedSimulator
 	}
-	resp, err := internal.RunCommand(commandBuffer)
+	run := func() ([]byte, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		s.activate()
+		return internal.RunCommand(commandBuffer)
+	}
+	var resp []byte
+	var err error
+	if fault := s.fault(commandBuffer); fault != nil {
+		resp, err = fault(commandBuffer, run)
+	} else {
+		resp, err = run()
+	}
 	if err != nil {
 		return 0, err
 	}
@@ -120,15 +174,59 @@ func (s *Simulator) Read(responseBuffer []byte) (int, error) {
 	return s.buf.Read(responseBuffer)
 }
 
+// Snapshot returns the full state of the simulator, which can be restored with
+// Restore(). The snapshot includes the NV data, the hierarchy seeds, the loaded
+// objects and sessions, and the PCRs. It can only be restored by a simulator
+// built from the same version of go-tpm-tools.
+func (s *Simulator) Snapshot() ([]byte, error) {
+	if s.IsClosed() {
+		return nil, ErrUsingClosedSimulator
+	}
+	lock.Lock()
+	s.activate()
+	state := internal.SaveState()
+	lock.Unlock()
+
+	snapshot := make([]byte, snapshotHeaderSize, snapshotHeaderSize+len(state))
+	binary.BigEndian.PutUint32(snapshot[0:], snapshotVersion)
+	binary.BigEndian.PutUint32(snapshot[4:], uint32(len(state)))
+	return append(snapshot, state...), nil
+}
+
+// Restore replaces the state of the simulator with a snapshot previously
+// returned by Snapshot(), from this or another Simulator. Any response not yet
+// read is discarded.
+func (s *Simulator) Restore(snapshot []byte) error {
+	if s.IsClosed() {
+		return ErrUsingClosedSimulator
+	}
+	if len(snapshot) < snapshotHeaderSize {
+		return errors.New("simulator snapshot is too short")
+	}
+	if version := binary.BigEndian.Uint32(snapshot[0:]); version != snapshotVersion {
+		return fmt.Errorf("unsupported simulator snapshot version %d", version)
+	}
+	state := snapshot[snapshotHeaderSize:]
+	if size := binary.BigEndian.Uint32(snapshot[4:]); int(size) != len(state) || len(state) != internal.StateSize() {
+		return errors.New("simulator snapshot does not match this simulator build")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	s.activate()
+	internal.RestoreState(state)
+	s.buf.Reset()
+	return nil
+}
+
 // Close cleans up and stops the simulator, Close() should always be called when
-// the Simulator is no longer needed, freeing up other callers to use Get().
+// the Simulator is no longer needed.
 func (s *Simulator) Close() error {
 	if s.IsClosed() {
 		return ErrUsingClosedSimulator
 	}
 	err := s.off()
-	s.closed = true
-	lock.Unlock()
+	s.release()
 	return err
 }
 
@@ -137,6 +235,24 @@ func (s *Simulator) IsClosed() bool {
 	return s.closed
 }
 
+func (s *Simulator) reset(forceManufacture bool) {
+	lock.Lock()
+	defer lock.Unlock()
+	s.activate()
+	internal.Reset(forceManufacture)
+}
+
+// release marks the simulator as closed and drops its state.
+func (s *Simulator) release() {
+	lock.Lock()
+	defer lock.Unlock()
+	if active == s {
+		active = nil
+	}
+	s.state = nil
+	s.closed = true
+}
+
 func (s *Simulator) on(manufactureReset bool) error {
 	// TPM2_Startup must be the first command the TPM receives.
 	if err := tpm2.Startup(s, tpm2.StartupClear); err != nil {
@@ -17,9 +17,12 @@
 package simulator
 
 import (
+	"bytes"
 	"crypto/rsa"
+	"crypto/sha256"
 	"io"
 	"math/big"
+	"sync"
 	"testing"
 
 	"github.com/google/go-tpm-tools/client"
@@ -117,3 +120,166 @@ func TestDifferentSeedDifferentModulus(t *testing.T) {
 		t.Fatalf("Moduli should not be equal when using different seeds")
 	}
 }
+
+func TestFixedSeedRandom(t *testing.T) {
+	var results [2][]byte
+	for i := range results {
+		s, err := GetWithFixedSeedInsecure(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if results[i], err = tpm2.GetRandom(s, 16); err != nil {
+			t.Fatalf("GetRandom: %v", err)
+		}
+		client.CheckedClose(t, s)
+	}
+	if !bytes.Equal(results[0], results[1]) {
+		t.Errorf("GetRandom() returned %x and %x with the same seed", results[0], results[1])
+	}
+}
+
+func TestFixedSeedSurvivesReset(t *testing.T) {
+	s, err := GetWithFixedSeedInsecure(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.CheckedClose(t, s)
+
+	if err := s.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if modulus := getEKModulus(t, s); modulus.Cmp(zeroSeedModulus()) != 0 {
+		t.Fatalf("getEKModulus() = %v, want %v", modulus, zeroSeedModulus())
+	}
+}
+
+func TestIndependentSimulators(t *testing.T) {
+	s1 := getSimulator(t)
+	defer client.CheckedClose(t, s1)
+	s2 := getSimulator(t)
+	defer client.CheckedClose(t, s2)
+
+	if err := tpm2.PCRExtend(s1, 16, tpm2.AlgSHA256, make([]byte, 32), ""); err != nil {
+		t.Fatal(err)
+	}
+	pcr1, err := tpm2.ReadPCR(s1, 16, tpm2.AlgSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pcr2, err := tpm2.ReadPCR(s2, 16, tpm2.AlgSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(pcr1, pcr2) {
+		t.Error("extending a PCR of one simulator changed the other")
+	}
+	if getEKModulus(t, s1).Cmp(getEKModulus(t, s2)) == 0 {
+		t.Error("independent simulators have the same EK")
+	}
+}
+
+func TestConcurrentSimulators(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s, err := Get()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer client.CheckedClose(t, s)
+			for j := 0; j < 10; j++ {
+				if err := tpm2.PCRExtend(s, 16, tpm2.AlgSHA256, make([]byte, 32), ""); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+			pcr, err := tpm2.ReadPCR(s, 16, tpm2.AlgSHA256)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			want := make([]byte, 32)
+			for j := 0; j < 10; j++ {
+				digest := sha256.Sum256(append(want, make([]byte, 32)...))
+				want = digest[:]
+			}
+			if !bytes.Equal(pcr, want) {
+				t.Errorf("got PCR value %x, want %x", pcr, want)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	s := getSimulator(t)
+	defer client.CheckedClose(t, s)
+
+	srk, err := client.StorageRootKeyECC(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srk.Close()
+	modulus := getEKModulus(t, s)
+	if err := tpm2.PCRExtend(s, 16, tpm2.AlgSHA256, make([]byte, 32), ""); err != nil {
+		t.Fatal(err)
+	}
+	pcr, err := tpm2.ReadPCR(s, 16, tpm2.AlgSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := s.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Change the state, then restore it in the same and in another simulator.
+	if err := s.ManufactureReset(); err != nil {
+		t.Fatal(err)
+	}
+	other := getSimulator(t)
+	defer client.CheckedClose(t, other)
+	for _, sim := range []*Simulator{s, other} {
+		if err := sim.Restore(snapshot); err != nil {
+			t.Fatal(err)
+		}
+		got, err := tpm2.ReadPCR(sim, 16, tpm2.AlgSHA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, pcr) {
+			t.Errorf("got PCR value %x after restore, want %x", got, pcr)
+		}
+		if getEKModulus(t, sim).Cmp(modulus) != 0 {
+			t.Error("restored simulator has a different EK")
+		}
+		// The SRK handle is still loaded.
+		if _, _, _, err := tpm2.ReadPublic(sim, srk.Handle()); err != nil {
+			t.Errorf("SRK not loaded after restore: %v", err)
+		}
+	}
+}
+
+func TestRestoreInvalidSnapshot(t *testing.T) {
+	s := getSimulator(t)
+	defer client.CheckedClose(t, s)
+
+	snapshot, err := s.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	badVersion := append([]byte{}, snapshot...)
+	badVersion[3]++
+	for _, bad := range [][]byte{nil, snapshot[:len(snapshot)-1], badVersion} {
+		if err := s.Restore(bad); err == nil {
+			t.Errorf("Restore() succeeded with an invalid snapshot of %d bytes", len(bad))
+		}
+	}
+	// The simulator still works.
+	if _, err := tpm2.GetRandom(s, 10); err != nil {
+		t.Fatalf("GetRandom: %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package spire
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/go-tpm-tools/client"
+	"google.golang.org/protobuf/proto"
+)
+
+// AgentAttestor is the agent side of the node attestor.
+type AgentAttestor struct {
+	tpm       io.ReadWriter
+	akFetcher func(io.ReadWriter) (*client.Key, error)
+}
+
+// NewAgentAttestor creates an agent attestor attesting with the TPM and the
+// AK returned by akFetcher, e.g. client.GceAttestationKeyECC.
+func NewAgentAttestor(tpm io.ReadWriter, akFetcher func(io.ReadWriter) (*client.Key, error)) *AgentAttestor {
+	return &AgentAttestor{tpm, akFetcher}
+}
+
+// Payload returns the initial attestation payload, identifying the AK.
+func (a *AgentAttestor) Payload() ([]byte, error) {
+	ak, err := a.akFetcher(a.tpm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AK: %w", err)
+	}
+	defer ak.Close()
+
+	akPub, err := ak.PublicArea().Encode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode AK public area: %w", err)
+	}
+	return json.Marshal(payload{AKPub: akPub, AKCert: ak.CertDERBytes()})
+}
+
+// ChallengeResponse answers a challenge of the server with an attestation over
+// its nonce.
+func (a *AgentAttestor) ChallengeResponse(challengeBytes []byte) ([]byte, error) {
+	var chal challenge
+	if err := json.Unmarshal(challengeBytes, &chal); err != nil {
+		return nil, fmt.Errorf("failed to decode challenge: %w", err)
+	}
+	if len(chal.Nonce) == 0 {
+		return nil, errors.New("challenge has no nonce")
+	}
+
+	ak, err := a.akFetcher(a.tpm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AK: %w", err)
+	}
+	defer ak.Close()
+
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: chal.Nonce})
+	if err != nil {
+		return nil, fmt.Errorf("failed to attest: %w", err)
+	}
+	return proto.Marshal(attestation)
+}
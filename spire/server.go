@@ -0,0 +1,115 @@
+package spire
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/server"
+	"google.golang.org/protobuf/proto"
+)
+
+const nonceSize = 32
+
+// ServerConfig configures the server side of the node attestor.
+type ServerConfig struct {
+	// TrustDomain is the SPIFFE trust domain of the agent IDs, e.g.
+	// "example.org".
+	TrustDomain string
+	// VerifyOpts are the options used to verify attestations, in particular
+	// the trusted AKs or AK certificate roots. The nonce is replaced with the
+	// nonce of each challenge.
+	VerifyOpts server.VerifyOpts
+	// Policy, if not nil, is evaluated against the verified machine state.
+	Policy *pb.Policy
+}
+
+// ServerAttestor is the server side of the node attestor.
+type ServerAttestor struct {
+	config ServerConfig
+}
+
+// AttestationResult is the outcome of a successful node attestation.
+type AttestationResult struct {
+	// AgentID is the SPIFFE ID of the agent, like
+	// spiffe://example.org/spire/agent/go_tpm_tools/<AK digest>, where the AK
+	// digest is the hex encoded SHA-256 digest of the AK public area.
+	AgentID string
+	// Selectors describe the verified machine state:
+	//   - ak_pub:<AK digest>
+	//   - secure_boot:enabled or secure_boot:disabled
+	//   - technology:<GCEConfidentialTechnology>
+	//   - gce_version:<version>, if known
+	//   - gce:project-id:<id>, gce:zone:<zone> and gce:instance-name:<name>,
+	//     if the AK certificate has GCE instance information
+	Selectors []string
+	// MachineState is the verified machine state.
+	MachineState *pb.MachineState
+}
+
+// NewServerAttestor creates a server attestor from the config.
+func NewServerAttestor(config ServerConfig) (*ServerAttestor, error) {
+	if config.TrustDomain == "" {
+		return nil, errors.New("trust domain is required")
+	}
+	if u, err := url.Parse("spiffe://" + config.TrustDomain); err != nil || u.Host != config.TrustDomain {
+		return nil, fmt.Errorf("invalid trust domain %q", config.TrustDomain)
+	}
+	return &ServerAttestor{config}, nil
+}
+
+// Attest verifies the agent with the initial payload of its attestation
+// request. The challenge function must send a challenge to the agent and
+// return its response, as done over the SPIRE node attestation stream.
+func (s *ServerAttestor) Attest(payloadBytes []byte, challengeAgent func([]byte) ([]byte, error)) (*AttestationResult, error) {
+	var p payload
+	if err := json.Unmarshal(payloadBytes, &p); err != nil {
+		return nil, fmt.Errorf("failed to decode payload: %w", err)
+	}
+	if len(p.AKPub) == 0 {
+		return nil, errors.New("payload has no AK public area")
+	}
+
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	chal, err := json.Marshal(challenge{Nonce: nonce})
+	if err != nil {
+		return nil, err
+	}
+	response, err := challengeAgent(chal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to challenge agent: %w", err)
+	}
+	attestation := &pb.Attestation{}
+	if err := proto.Unmarshal(response, attestation); err != nil {
+		return nil, fmt.Errorf("failed to decode attestation: %w", err)
+	}
+	// The agent ID is derived from the payload, so it must use the same AK.
+	if !bytes.Equal(attestation.GetAkPub(), p.AKPub) || !bytes.Equal(attestation.GetAkCert(), p.AKCert) {
+		return nil, errors.New("attestation AK does not match the payload")
+	}
+
+	opts := s.config.VerifyOpts
+	opts.Nonce = nonce
+	state, err := server.VerifyAttestation(attestation, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify attestation: %w", err)
+	}
+	if s.config.Policy != nil {
+		if err := server.EvaluatePolicy(state, s.config.Policy); err != nil {
+			return nil, fmt.Errorf("machine state does not satisfy policy: %w", err)
+		}
+	}
+
+	return &AttestationResult{
+		AgentID:      fmt.Sprintf("spiffe://%s/spire/agent/%s/%s", s.config.TrustDomain, PluginName, akID(p.AKPub)),
+		Selectors:    selectors(p.AKPub, state),
+		MachineState: state,
+	}, nil
+}
@@ -0,0 +1,71 @@
+// Package spire implements a SPIRE node attestor rooted in vTPM attestation,
+// so SPIFFE identities can be issued to machines that prove their boot state
+// with client.Attest and server.VerifyAttestation.
+//
+// SPIRE node attestation is a challenge/response exchange between the agent
+// and server plugins:
+//  1. the agent sends the payload from AgentAttestor.Payload
+//  2. the server creates a challenge with a fresh nonce
+//  3. the agent answers it with AgentAttestor.ChallengeResponse
+//  4. the server verifies the attestation, and returns the agent's SPIFFE ID
+//     and selectors
+//
+// ServerAttestor.Attest drives steps 2 to 4. This package does not depend on
+// the SPIRE plugin SDK: its NodeAttestor plugins only need to forward the
+// payload, challenges and responses over their streams.
+package spire
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+)
+
+// PluginName is the name of the node attestor plugins, used in agent SPIFFE
+// IDs and as the type of the selectors.
+const PluginName = "go_tpm_tools"
+
+// payload is the initial attestation payload sent by the agent.
+type payload struct {
+	// AKPub is the AK public area, encoded as a TPMT_PUBLIC.
+	AKPub []byte `json:"ak_pub"`
+	// AKCert is the AK certificate, encoded as ASN.1 DER. Optional.
+	AKCert []byte `json:"ak_cert,omitempty"`
+}
+
+// challenge is sent by the server to the agent.
+type challenge struct {
+	Nonce []byte `json:"nonce"`
+}
+
+// akID identifies an agent by the digest of its AK public area.
+func akID(akPub []byte) string {
+	digest := sha256.Sum256(akPub)
+	return hex.EncodeToString(digest[:])
+}
+
+// selectors returns the selector values describing the verified machine
+// state, see ServerAttestor.Attest.
+func selectors(akPub []byte, state *pb.MachineState) []string {
+	values := []string{"ak_pub:" + akID(akPub)}
+	if state.GetSecureBoot().GetEnabled() {
+		values = append(values, "secure_boot:enabled")
+	} else {
+		values = append(values, "secure_boot:disabled")
+	}
+	platform := state.GetPlatform()
+	values = append(values, "technology:"+platform.GetTechnology().String())
+	if version, ok := platform.GetFirmware().(*pb.PlatformState_GceVersion); ok {
+		values = append(values, fmt.Sprintf("gce_version:%d", version.GceVersion))
+	}
+	if info := platform.GetInstanceInfo(); info != nil {
+		values = append(values,
+			"gce:project-id:"+info.GetProjectId(),
+			"gce:zone:"+info.GetZone(),
+			"gce:instance-name:"+info.GetInstanceName(),
+		)
+	}
+	return values
+}
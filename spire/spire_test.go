@@ -0,0 +1,177 @@
+package spire
+
+import (
+	"crypto"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/server"
+)
+
+func TestNodeAttestation(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	ak, err := client.AttestationKeyECC(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+
+	agent := NewAgentAttestor(rwc, client.AttestationKeyECC)
+	attestor, err := NewServerAttestor(ServerConfig{
+		TrustDomain: "example.org",
+		VerifyOpts:  server.VerifyOpts{TrustedAKs: []crypto.PublicKey{ak.PublicKey()}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err := agent.Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := attestor.Attest(payload, agent.ChallengeResponse)
+	if err != nil {
+		t.Fatalf("node attestation failed: %v", err)
+	}
+
+	akPub, err := ak.PublicArea().Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "spiffe://example.org/spire/agent/go_tpm_tools/" + akID(akPub); result.AgentID != want {
+		t.Errorf("got agent ID %q, want %q", result.AgentID, want)
+	}
+	if len(result.Selectors) == 0 || result.Selectors[0] != "ak_pub:"+akID(akPub) {
+		t.Errorf("got selectors %v", result.Selectors)
+	}
+
+	// The AgentID is stable across attestations.
+	again, err := attestor.Attest(payload, agent.ChallengeResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.AgentID != result.AgentID {
+		t.Errorf("got agent ID %q, then %q", result.AgentID, again.AgentID)
+	}
+}
+
+func TestNodeAttestationFailures(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	ak, err := client.AttestationKeyECC(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+	otherAK, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer otherAK.Close()
+
+	agent := NewAgentAttestor(rwc, client.AttestationKeyECC)
+	payload, err := agent.Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	trustedOpts := server.VerifyOpts{TrustedAKs: []crypto.PublicKey{ak.PublicKey()}}
+
+	tests := []struct {
+		name     string
+		config   ServerConfig
+		payload  []byte
+		response func([]byte) ([]byte, error)
+	}{
+		{
+			name:     "UntrustedAK",
+			config:   ServerConfig{TrustDomain: "example.org", VerifyOpts: server.VerifyOpts{TrustedAKs: []crypto.PublicKey{otherAK.PublicKey()}}},
+			payload:  payload,
+			response: agent.ChallengeResponse,
+		},
+		{
+			name:     "AKMismatch",
+			config:   ServerConfig{TrustDomain: "example.org", VerifyOpts: trustedOpts},
+			payload:  payload,
+			response: NewAgentAttestor(rwc, client.AttestationKeyRSA).ChallengeResponse,
+		},
+		{
+			name:    "StaleNonce",
+			config:  ServerConfig{TrustDomain: "example.org", VerifyOpts: trustedOpts},
+			payload: payload,
+			response: func([]byte) ([]byte, error) {
+				chal, _ := json.Marshal(challenge{Nonce: []byte("an old nonce")})
+				return agent.ChallengeResponse(chal)
+			},
+		},
+		{
+			name: "Policy",
+			config: ServerConfig{
+				TrustDomain: "example.org",
+				VerifyOpts:  trustedOpts,
+				Policy:      &pb.Policy{Platform: &pb.PlatformPolicy{MinimumGceFirmwareVersion: 1000}},
+			},
+			payload:  payload,
+			response: agent.ChallengeResponse,
+		},
+		{
+			name:     "BadPayload",
+			config:   ServerConfig{TrustDomain: "example.org", VerifyOpts: trustedOpts},
+			payload:  []byte("{}"),
+			response: agent.ChallengeResponse,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			attestor, err := NewServerAttestor(tc.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := attestor.Attest(tc.payload, tc.response); err == nil {
+				t.Error("node attestation succeeded")
+			}
+		})
+	}
+}
+
+func TestNewServerAttestorTrustDomain(t *testing.T) {
+	for _, td := range []string{"", "spiffe://example.org", "example.org/path", strings.Repeat("a", 10) + " b"} {
+		if _, err := NewServerAttestor(ServerConfig{TrustDomain: td}); err == nil {
+			t.Errorf("NewServerAttestor(%q) succeeded", td)
+		}
+	}
+}
+
+func TestSelectors(t *testing.T) {
+	state := &pb.MachineState{
+		Platform: &pb.PlatformState{
+			Firmware:   &pb.PlatformState_GceVersion{GceVersion: 2},
+			Technology: pb.GCEConfidentialTechnology_AMD_SEV_SNP,
+			InstanceInfo: &pb.GCEInstanceInfo{
+				ProjectId:    "test-project",
+				Zone:         "us-central1-a",
+				InstanceName: "test-instance",
+			},
+		},
+		SecureBoot: &pb.SecureBootState{Enabled: true},
+	}
+	got := selectors([]byte("ak"), state)
+	want := []string{
+		"ak_pub:" + akID([]byte("ak")),
+		"secure_boot:enabled",
+		"technology:AMD_SEV_SNP",
+		"gce_version:2",
+		"gce:project-id:test-project",
+		"gce:zone:us-central1-a",
+		"gce:instance-name:test-instance",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("selectors() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,99 @@
+package tpmtls
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/google/go-tpm-tools/client"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/server"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// ExporterLabel is the label of the keying material exported from the
+	// TLS session and used as the attestation nonce.
+	ExporterLabel = "EXPORTER-go-tpm-tools-attested-tls"
+	nonceSize     = 32
+	// maxAttestationSize limits the size of received attestations, which
+	// include the TCG and Canonical Event Logs.
+	maxAttestationSize = 4 << 20
+)
+
+// Nonce returns the attestation nonce bound to the TLS connection, exported
+// from its keying material. Both peers compute the same nonce. The TLS session
+// must use TLS 1.3, or TLS 1.2 with the Extended Master Secret extension.
+func Nonce(conn *tls.Conn) ([]byte, error) {
+	if err := conn.Handshake(); err != nil {
+		return nil, err
+	}
+	state := conn.ConnectionState()
+	nonce, err := state.ExportKeyingMaterial(ExporterLabel, nil, nonceSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export keying material: %w", err)
+	}
+	return nonce, nil
+}
+
+// SendAttestation attests with the AK over the connection's Nonce, and sends
+// the attestation to the peer, which can check it with VerifyPeer. The nonce
+// of opts is ignored.
+func SendAttestation(conn *tls.Conn, ak *client.Key, opts client.AttestOpts) error {
+	nonce, err := Nonce(conn)
+	if err != nil {
+		return err
+	}
+	opts.Nonce = nonce
+	attestation, err := ak.Attest(opts)
+	if err != nil {
+		return fmt.Errorf("failed to attest: %w", err)
+	}
+	return writeAttestation(conn, attestation)
+}
+
+func writeAttestation(w io.Writer, attestation *pb.Attestation) error {
+	data, err := proto.Marshal(attestation)
+	if err != nil {
+		return err
+	}
+
+	// Attestations are sent with a 4-byte big-endian length prefix.
+	msg := make([]byte, 4, 4+len(data))
+	binary.BigEndian.PutUint32(msg, uint32(len(data)))
+	if _, err := w.Write(append(msg, data...)); err != nil {
+		return fmt.Errorf("failed to send attestation: %w", err)
+	}
+	return nil
+}
+
+// VerifyPeer receives the attestation sent by the peer with SendAttestation,
+// and verifies it with server.VerifyAttestation using the connection's Nonce.
+// The nonce of opts is ignored. As the nonce is unique to the TLS session, a
+// successful verification means the attested machine is the peer of conn.
+func VerifyPeer(conn *tls.Conn, opts server.VerifyOpts) (*pb.MachineState, error) {
+	nonce, err := Nonce(conn)
+	if err != nil {
+		return nil, err
+	}
+	var size [4]byte
+	if _, err := io.ReadFull(conn, size[:]); err != nil {
+		return nil, fmt.Errorf("failed to receive attestation: %w", err)
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > maxAttestationSize {
+		return nil, fmt.Errorf("attestation of %d bytes is too large", n)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(conn, data); err != nil {
+		return nil, fmt.Errorf("failed to receive attestation: %w", err)
+	}
+	attestation := &pb.Attestation{}
+	if err := proto.Unmarshal(data, attestation); err != nil {
+		return nil, fmt.Errorf("failed to decode attestation: %w", err)
+	}
+
+	opts.Nonce = nonce
+	return server.VerifyAttestation(attestation, opts)
+}
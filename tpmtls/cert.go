@@ -0,0 +1,144 @@
+// Package tpmtls integrates TPM keys and attestations with crypto/tls.
+//
+// Certificate and SelfSignedCertificate create a tls.Certificate whose private
+// key stays in the TPM. SendAttestation and VerifyPeer implement attested TLS:
+// after the handshake, one peer attests with a nonce exported from the TLS
+// session (see RFC 5705 and RFC 8446 section 7.5), so the attestation is bound
+// to the connection and cannot be replayed on another one.
+package tpmtls
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm/tpm2"
+)
+
+// Certificate returns a tls.Certificate signing with the TPM key. The chain is
+// the ASN.1 DER encoded certificate chain, starting with the certificate of
+// the key.
+//
+// The key must be an unrestricted signing key. ECDSA keys on P-256 with SHA-256
+// or P-384 with SHA-384 work with all TLS versions. RSASSA keys only work with
+// TLS 1.2, as TLS 1.3 requires RSA-PSS with a salt length which the TPM does
+// not support, so set tls.Config.MaxVersion accordingly.
+func Certificate(key *client.Key, chain ...[]byte) (tls.Certificate, error) {
+	if len(chain) == 0 {
+		return tls.Certificate{}, errors.New("no certificate provided")
+	}
+	leaf, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	if !reflect.DeepEqual(leaf.PublicKey, key.PublicKey()) {
+		return tls.Certificate{}, errors.New("certificate does not match the key")
+	}
+	schemes, err := signatureSchemes(key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	signer, err := key.GetSigner()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{
+		Certificate:                  chain,
+		PrivateKey:                   signer,
+		SupportedSignatureAlgorithms: schemes,
+		Leaf:                         leaf,
+	}, nil
+}
+
+// SelfSignedCertificate creates a certificate from the template, self-signed
+// by the TPM key, and returns it as a tls.Certificate. See Certificate for the
+// supported keys.
+func SelfSignedCertificate(key *client.Key, template *x509.Certificate) (tls.Certificate, error) {
+	signer, err := key.GetSigner()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	// Certificates must be signed with the hash algorithm of the key.
+	template.SignatureAlgorithm, err = x509SignatureAlgorithm(key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.PublicKey(), signer)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to create certificate: %w", err)
+	}
+	return Certificate(key, der)
+}
+
+func signingHash(key *client.Key) (crypto.Hash, tpm2.Algorithm, error) {
+	pub := key.PublicArea()
+	var sigAlg, hashAlg tpm2.Algorithm
+	switch {
+	case pub.RSAParameters != nil && pub.RSAParameters.Sign != nil:
+		sigAlg, hashAlg = pub.RSAParameters.Sign.Alg, pub.RSAParameters.Sign.Hash
+	case pub.ECCParameters != nil && pub.ECCParameters.Sign != nil:
+		sigAlg, hashAlg = pub.ECCParameters.Sign.Alg, pub.ECCParameters.Sign.Hash
+	default:
+		return 0, 0, errors.New("key has no signing scheme")
+	}
+	hash, err := hashAlg.Hash()
+	if err != nil {
+		return 0, 0, err
+	}
+	return hash, sigAlg, nil
+}
+
+func signatureSchemes(key *client.Key) ([]tls.SignatureScheme, error) {
+	hash, sigAlg, err := signingHash(key)
+	if err != nil {
+		return nil, err
+	}
+	switch sigAlg {
+	case tpm2.AlgECDSA:
+		pub := key.PublicKey().(*ecdsa.PublicKey)
+		switch {
+		case pub.Curve == elliptic.P256() && hash == crypto.SHA256:
+			return []tls.SignatureScheme{tls.ECDSAWithP256AndSHA256}, nil
+		case pub.Curve == elliptic.P384() && hash == crypto.SHA384:
+			return []tls.SignatureScheme{tls.ECDSAWithP384AndSHA384}, nil
+		}
+		return nil, fmt.Errorf("unsupported ECDSA curve %s with %v", pub.Curve.Params().Name, hash)
+	case tpm2.AlgRSASSA:
+		switch hash {
+		case crypto.SHA256:
+			return []tls.SignatureScheme{tls.PKCS1WithSHA256}, nil
+		case crypto.SHA384:
+			return []tls.SignatureScheme{tls.PKCS1WithSHA384}, nil
+		case crypto.SHA512:
+			return []tls.SignatureScheme{tls.PKCS1WithSHA512}, nil
+		}
+		return nil, fmt.Errorf("unsupported RSASSA hash %v", hash)
+	}
+	return nil, fmt.Errorf("unsupported signature scheme %v", sigAlg)
+}
+
+func x509SignatureAlgorithm(key *client.Key) (x509.SignatureAlgorithm, error) {
+	schemes, err := signatureSchemes(key)
+	if err != nil {
+		return x509.UnknownSignatureAlgorithm, err
+	}
+	switch schemes[0] {
+	case tls.ECDSAWithP256AndSHA256:
+		return x509.ECDSAWithSHA256, nil
+	case tls.ECDSAWithP384AndSHA384:
+		return x509.ECDSAWithSHA384, nil
+	case tls.PKCS1WithSHA256:
+		return x509.SHA256WithRSA, nil
+	case tls.PKCS1WithSHA384:
+		return x509.SHA384WithRSA, nil
+	default:
+		return x509.SHA512WithRSA, nil
+	}
+}
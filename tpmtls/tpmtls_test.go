@@ -0,0 +1,195 @@
+package tpmtls
+
+import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/server"
+	"github.com/google/go-tpm/tpm2"
+)
+
+func templateECC() tpm2.Public {
+	template := client.AKTemplateECC()
+	// Can't sign arbitrary data if restricted.
+	template.Attributes &= ^tpm2.FlagRestricted
+	return template
+}
+
+func templateSSA() tpm2.Public {
+	template := client.AKTemplateRSA()
+	template.Attributes &= ^tpm2.FlagRestricted
+	return template
+}
+
+func certTemplate() *x509.Certificate {
+	return &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     []string{"attested.example.com"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+}
+
+// handshake connects a TLS client to a TLS server using cert, and runs
+// serverFn and clientFn on both ends.
+func handshake(t *testing.T, cert tls.Certificate, maxVersion uint16, serverFn func(*tls.Conn) error, clientFn func(*tls.Conn) error) {
+	t.Helper()
+	roots := x509.NewCertPool()
+	roots.AddCert(cert.Leaf)
+
+	serverConn, clientConn := net.Pipe()
+	tlsServer := tls.Server(serverConn, &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MaxVersion:   maxVersion,
+	})
+	tlsClient := tls.Client(clientConn, &tls.Config{
+		RootCAs:    roots,
+		ServerName: "attested.example.com",
+		MaxVersion: maxVersion,
+	})
+	// Close the pipe directly, as nothing reads the TLS close_notify alerts.
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	serverErr := make(chan error, 1)
+	go func() { serverErr <- serverFn(tlsServer) }()
+	if err := clientFn(tlsClient); err != nil {
+		t.Errorf("client failed: %v", err)
+	}
+	if err := <-serverErr; err != nil {
+		t.Errorf("server failed: %v", err)
+	}
+}
+
+func TestCertificate(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	tests := []struct {
+		name       string
+		template   tpm2.Public
+		maxVersion uint16
+	}{
+		{"ECC-TLS1.3", templateECC(), tls.VersionTLS13},
+		{"ECC-TLS1.2", templateECC(), tls.VersionTLS12},
+		{"RSA-TLS1.2", templateSSA(), tls.VersionTLS12},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := client.NewKey(rwc, tpm2.HandleEndorsement, tc.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer key.Close()
+			cert, err := SelfSignedCertificate(key, certTemplate())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			handshake(t, cert, tc.maxVersion,
+				func(conn *tls.Conn) error { return conn.Handshake() },
+				func(conn *tls.Conn) error { return conn.Handshake() })
+		})
+	}
+}
+
+func TestCertificateMismatch(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	key, err := client.NewKey(rwc, tpm2.HandleEndorsement, templateECC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer key.Close()
+	other, err := client.NewKey(rwc, tpm2.HandleOwner, templateSSA())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+
+	cert, err := SelfSignedCertificate(key, certTemplate())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Certificate(other, cert.Certificate...); err == nil {
+		t.Error("Certificate succeeded with the wrong key")
+	}
+	ak, err := client.AttestationKeyECC(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	if _, err := SelfSignedCertificate(ak, certTemplate()); err == nil {
+		t.Error("SelfSignedCertificate succeeded with a restricted key")
+	}
+}
+
+func TestAttestedTLS(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+
+	key, err := client.NewKey(rwc, tpm2.HandleEndorsement, templateECC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer key.Close()
+	ak, err := client.AttestationKeyECC(rwc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	cert, err := SelfSignedCertificate(key, certTemplate())
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := server.VerifyOpts{TrustedAKs: []crypto.PublicKey{ak.PublicKey()}}
+
+	for _, maxVersion := range []uint16{tls.VersionTLS12, tls.VersionTLS13} {
+		handshake(t, cert, maxVersion,
+			func(conn *tls.Conn) error { return SendAttestation(conn, ak, client.AttestOpts{}) },
+			func(conn *tls.Conn) error {
+				_, err := VerifyPeer(conn, opts)
+				return err
+			})
+	}
+
+	// An attestation for another session is rejected.
+	var replayed *pb.Attestation
+	handshake(t, cert, tls.VersionTLS13,
+		func(conn *tls.Conn) error {
+			nonce, err := Nonce(conn)
+			if err != nil {
+				return err
+			}
+			replayed, err = ak.Attest(client.AttestOpts{Nonce: nonce})
+			return err
+		},
+		func(conn *tls.Conn) error {
+			_, err := Nonce(conn)
+			return err
+		})
+	handshake(t, cert, tls.VersionTLS13,
+		func(conn *tls.Conn) error {
+			if err := conn.Handshake(); err != nil {
+				return err
+			}
+			return writeAttestation(conn, replayed)
+		},
+		func(conn *tls.Conn) error {
+			if _, err := VerifyPeer(conn, opts); err == nil {
+				t.Error("VerifyPeer succeeded with an attestation from another session")
+			}
+			return nil
+		})
+}